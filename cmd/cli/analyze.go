@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runAnalyze implements "ai-devops analyze".
+func runAnalyze(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "Usage: ai-devops analyze [flags] [file]\n\nAnalyzes the log in file, --file or stdin.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	var pf pipelineFlags
+	pf.register(fs)
+	file := fs.String("file", "", "read the log from `path` instead of stdin (\"-\" for stdin)")
+	explain := fs.Bool("explain", false, "include stage timings and rule matches")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	switch {
+	case fs.NArg() == 1 && *file == "":
+		*file = fs.Arg(0)
+	case fs.NArg() > 0:
+		fmt.Fprintln(stderr, "ai-devops: analyze takes at most one file")
+		return exitUsage
+	}
+
+	render, err := pf.validate()
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitUsage
+	}
+
+	log, err := readLog(*file, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitUsage
+	}
+
+	analyzer, logger, err := pf.pipeline(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitUsage
+	}
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	req := pf.request(log)
+	req.Explain = *explain
+	resp, err := analyzer.Analyze(ctx, req)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: analysis failed: %v\n", err)
+		return exitFailed
+	}
+
+	if err := render(stdout, resp); err != nil {
+		fmt.Fprintf(stderr, "ai-devops: writing output: %v\n", err)
+		return exitFailed
+	}
+	if !resp.Success {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// readLog reads the log from path, or from stdin if path is empty or "-".
+func readLog(path string, stdin io.Reader) (string, error) {
+	if path == "" || path == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("reading stdin: %w", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/service"
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+)
+
+// pipelineFlags are the flags of the commands that analyze logs.
+type pipelineFlags struct {
+	format   string
+	offline  bool
+	detail   string
+	mode     string
+	language string
+	verbose  bool
+}
+
+func (p *pipelineFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&p.format, "format", formatPretty, "output `format`: json, pretty or markdown")
+	fs.BoolVar(&p.offline, "offline", false, "answer from the rules only; the AI is never called and no API key is needed")
+	fs.StringVar(&p.detail, "detail", "", "analysis `level`: brief, standard or deep")
+	fs.StringVar(&p.mode, "mode", "", "specialist `mode`: iac for Terraform, Pulumi and CloudFormation logs, k8s for Kubernetes workloads, docker for Docker builds, ci for CI pipelines")
+	fs.StringVar(&p.language, "language", "", "output `language` of the analysis text")
+	fs.BoolVar(&p.verbose, "verbose", false, "log pipeline activity to stderr")
+}
+
+// validate checks the flags and returns the renderer of the output format.
+func (p *pipelineFlags) validate() (renderFunc, error) {
+	render, ok := renderers[p.format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (want json, pretty or markdown)", p.format)
+	}
+	if level := domain.DetailLevel(p.detail); level != "" && !level.IsValid() {
+		return nil, fmt.Errorf("unknown detail level %q (want brief, standard or deep)", p.detail)
+	}
+	if mode := domain.AnalysisMode(p.mode); mode != "" && !mode.IsValid() {
+		return nil, fmt.Errorf("unknown mode %q (want iac, k8s, docker or ci)", p.mode)
+	}
+	return render, nil
+}
+
+// request returns an analysis request for log with the flags' options.
+func (p *pipelineFlags) request(log string) *domain.AnalysisRequest {
+	return &domain.AnalysisRequest{
+		Log:      log,
+		Language: p.language,
+		Detail:   domain.DetailLevel(p.detail),
+		Mode:     domain.AnalysisMode(p.mode),
+	}
+}
+
+// pipeline loads the configuration like the server and builds the analysis
+// pipeline. The logger writes to stderr.
+func (p *pipelineFlags) pipeline(stderr io.Writer) (*service.Analyzer, *zap.Logger, error) {
+	// Load .env file if it exists, like the server
+	_ = godotenv.Load()
+	if p.offline {
+		os.Setenv("RULES_ONLY", "true")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger, err := newLogger(stderr, p.verbose)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	analyzer, err := newAnalyzer(cfg, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	return analyzer, logger, nil
+}
@@ -0,0 +1,20 @@
+package main
+
+import "github.com/ai-devops/internal/render"
+
+// Output formats.
+const (
+	formatJSON     = "json"
+	formatPretty   = "pretty"
+	formatMarkdown = "markdown"
+)
+
+// renderFunc writes an analysis response.
+type renderFunc = render.Func
+
+// renderers write an analysis response in each output format.
+var renderers = map[string]renderFunc{
+	formatJSON:     render.JSON,
+	formatPretty:   render.Text,
+	formatMarkdown: render.Markdown,
+}
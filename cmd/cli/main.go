@@ -0,0 +1,70 @@
+// AI DevOps Assistant - Command Line Interface
+//
+// The ai-devops command analyzes logs without running the server, e.g. in CI
+// scripts:
+//
+//	ai-devops analyze < build.log
+//	ai-devops analyze --file pod.log --format markdown
+//	ai-devops analyze --offline build.log
+//	ai-devops run -- npm ci
+//	ai-devops watch /var/log/app.log
+//
+// It reads the same environment variables (and .env file) as the server.
+// Build it with:
+//
+//	go build -o ai-devops ./cmd/cli
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes.
+const (
+	exitOK     = 0 // the log was analyzed
+	exitFailed = 1 // the analysis failed
+	exitUsage  = 2 // invalid arguments or configuration
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "analyze":
+		return runAnalyze(args[1:], stdin, stdout, stderr)
+	case "run":
+		return runRun(args[1:], stdin, stdout, stderr)
+	case "watch":
+		return runWatch(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "ai-devops: unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: ai-devops <command> [flags]
+
+Commands:
+  analyze   analyze a log from stdin or a file
+  run       run a command and analyze its output when it fails
+  watch     tail a log file and analyze it when an error line appears
+
+Run "ai-devops analyze -h" for the flags of a command. Configuration is read
+from the environment and .env like the server; see .env.example.
+`)
+}
@@ -0,0 +1,90 @@
+// Package main provides unit tests for the command line interface.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestRun_AnalyzeOffline(t *testing.T) {
+	t.Setenv("AI_API_KEY", "")
+	t.Setenv("RULES_ONLY", "") // restored after --offline sets it
+	t.Setenv("AI_MOCK_MODE", "false")
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantCode int
+		wantOut  string
+	}{
+		{
+			name:     "pretty from stdin",
+			args:     []string{"analyze", "--offline"},
+			stdin:    "java.lang.OutOfMemoryError: Java heap space",
+			wantCode: exitOK,
+			wantOut:  "Source:     rules:out_of_memory",
+		},
+		{
+			name:     "markdown",
+			args:     []string{"analyze", "--offline", "--format", "markdown"},
+			stdin:    "java.lang.OutOfMemoryError: Java heap space",
+			wantCode: exitOK,
+			wantOut:  "### Suggested actions",
+		},
+		{
+			name:     "no rule matched",
+			args:     []string{"analyze", "--offline"},
+			stdin:    "INFO: Application started successfully",
+			wantCode: exitFailed,
+			wantOut:  "Analysis failed: AI_UNAVAILABLE",
+		},
+		{
+			name:     "unknown format",
+			args:     []string{"analyze", "--offline", "--format", "yaml"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"analyse"},
+			wantCode: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("output %q does not contain %q", stdout.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestRun_AnalyzeJSON(t *testing.T) {
+	t.Setenv("AI_API_KEY", "")
+	t.Setenv("RULES_ONLY", "") // restored after --offline sets it
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"analyze", "--offline", "--format", "json"},
+		strings.NewReader("npm ERR! code ENOENT\nnpm ERR! syscall open"), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	var resp domain.AnalysisResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if !resp.Success || resp.Source != "rules:npm_install_failure" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/classifier"
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/fewshot"
+	"github.com/ai-devops/internal/policy"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/internal/service"
+	"github.com/ai-devops/pkg/sanitizer"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newLogger logs warnings (everything with verbose) to w, so that stdout
+// carries only the analysis. LOG_LEVEL overrides the level as for the
+// server.
+func newLogger(w io.Writer, verbose bool) (*zap.Logger, error) {
+	level := zapcore.WarnLevel
+	if verbose {
+		level = zapcore.DebugLevel
+	}
+	if env := os.Getenv("LOG_LEVEL"); env != "" {
+		if err := level.UnmarshalText([]byte(env)); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+		}
+	}
+
+	encoderCfg := zap.NewDevelopmentEncoderConfig()
+	encoderCfg.TimeKey = ""
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(encoderCfg), zapcore.AddSync(w), level)
+	return zap.New(core), nil
+}
+
+// newProviderClient creates the client for the configured provider.
+func newProviderClient(cfg *config.AIConfig, prompter ai.PromptBuilder, validator ai.ResponseValidator, logger *zap.Logger) ai.Client {
+	switch cfg.Provider {
+	case config.AIProviderGemini:
+		return ai.NewGeminiClient(cfg, prompter, validator, logger)
+	default:
+		return ai.NewOpenAIClient(cfg, prompter, validator, logger)
+	}
+}
+
+// newAnalyzer builds the analysis pipeline from the server configuration.
+// Process-wide features (cache, store, notifications, usage metering and
+// request pacing) are left out: the CLI analyzes a single log.
+func newAnalyzer(cfg *config.Config, logger *zap.Logger) (*service.Analyzer, error) {
+	var aiClient ai.Client
+	var promptVersion string
+	switch {
+	case cfg.Processing.RulesOnly:
+		// The AI is never called
+	case cfg.AI.MockMode:
+		logger.Warn("running in mock mode - AI responses are simulated")
+		aiClient = ai.NewMockClient(logger)
+	case cfg.Plugins.AIClient != "":
+		// Plugins are long-running processes started by the server
+		return nil, fmt.Errorf("%w: AI_PLUGIN is only supported by the server; use --offline or a provider", domain.ErrInvalidConfig)
+	default:
+		promptBuilder, err := ai.NewDefaultPromptBuilder()
+		if err != nil {
+			return nil, fmt.Errorf("create prompt builder: %w", err)
+		}
+		aiCfg := cfg.AI
+		if len(aiCfg.BaseURLs) > 0 {
+			aiCfg.BaseURL = aiCfg.BaseURLs[0]
+		}
+		validator := ai.NewDefaultValidator().WithReferenceDomains(cfg.AI.ReferenceDomains...)
+		aiClient = newProviderClient(&aiCfg, promptBuilder, validator, logger)
+		if aiCfg.CheapModel != "" {
+			cheapCfg := aiCfg
+			cheapCfg.Model = aiCfg.CheapModel
+			aiClient = ai.NewModelSelector(
+				newProviderClient(&cheapCfg, promptBuilder, validator, logger), aiClient,
+				aiCfg.CheapMaxBytes, aiCfg.CheapMaxErrorLines, logger,
+			)
+		}
+		if cfg.Consensus.Enabled() {
+			consensusCfg := cfg.ConsensusAI()
+			aiClient = ai.NewConsensusClient(
+				aiClient, aiCfg.Model,
+				newProviderClient(&consensusCfg, promptBuilder, validator, logger), consensusCfg.Model,
+				cfg.Consensus.Mode == config.ConsensusHighSeverity, logger,
+			)
+		}
+		promptVersion = ai.PromptVersion(promptBuilder)
+	}
+
+	ruleSet, err := rules.FilterCategories(
+		rules.DefaultRules(),
+		cfg.Processing.EnabledRuleCategories,
+		cfg.Processing.DisabledRuleCategories,
+	)
+	if err != nil {
+		return nil, err
+	}
+	ruleEngine := rules.NewEngine(ruleSet, cfg.Processing.RuleConfidenceThreshold, logger)
+
+	logSanitizer := sanitizer.New(cfg.Processing.MaxLogSize)
+	if cfg.Processing.SanitizerConfigPath != "" {
+		sanitizerCfg, err := sanitizer.LoadConfig(cfg.Processing.SanitizerConfigPath)
+		if err == nil {
+			logSanitizer, err = sanitizer.NewFromConfig(cfg.Processing.MaxLogSize, sanitizerCfg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("configure sanitizer: %w", err)
+		}
+	}
+	if !cfg.Processing.PreprocessLogs {
+		logSanitizer = logSanitizer.WithoutPreprocessing()
+	}
+	if cfg.Processing.EntropyDetection {
+		logSanitizer = logSanitizer.WithEntropyDetection(sanitizer.EntropyConfig{
+			MinLength:    cfg.Processing.EntropyMinLength,
+			Threshold:    cfg.Processing.EntropyThreshold,
+			HexThreshold: cfg.Processing.EntropyHexThreshold,
+		})
+	}
+	logSanitizer = logSanitizer.WithMaskStrategy(sanitizer.MaskStrategy(cfg.Processing.MaskStrategy))
+	if cfg.Processing.MaskHashKey != "" {
+		logSanitizer = logSanitizer.WithHashKey([]byte(cfg.Processing.MaskHashKey))
+	}
+
+	var classifierStage *classifier.Stage
+	if cfg.Processing.ClassifierModelPath != "" {
+		model, err := classifier.Load(cfg.Processing.ClassifierModelPath)
+		if err != nil {
+			return nil, fmt.Errorf("load classifier model: %w", err)
+		}
+		classifierStage = classifier.NewStage(model, cfg.Processing.ClassifierSkipThreshold, cfg.Processing.ClassifierHintThreshold)
+	}
+
+	var severityPolicy *policy.SeverityPolicy
+	if cfg.Processing.SeverityPolicyPath != "" {
+		policyCfg, err := policy.LoadConfig(cfg.Processing.SeverityPolicyPath)
+		if err == nil {
+			severityPolicy, err = policy.NewSeverityPolicy(policyCfg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load severity policy: %w", err)
+		}
+	}
+
+	var exampleStore *fewshot.Store
+	if cfg.FewShot.Enabled {
+		exampleStore, err = fewshot.NewStore(fewshot.Config{
+			Path:           cfg.FewShot.Path,
+			MaxPerCategory: cfg.FewShot.MaxPerCategory,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("load few-shot examples: %w", err)
+		}
+	}
+
+	analyzer, err := service.NewAnalyzer(
+		aiClient,
+		ruleEngine,
+		logSanitizer,
+		service.AnalyzerConfig{
+			EnableRules:          cfg.Processing.EnableRules,
+			HybridMerge:          cfg.Processing.HybridMerge,
+			RulesOnly:            cfg.Processing.RulesOnly,
+			BestEffort:           cfg.Processing.BestEffort,
+			MaxRequestTimeout:    cfg.Server.MaxRequestTimeout,
+			ReversibleSanitize:   cfg.Processing.ReversibleSanitization,
+			RejectPrivateKeys:    cfg.Processing.RejectPrivateKeys,
+			CompactLogs:          cfg.Processing.CompactLogs,
+			BlockDestructive:     cfg.Response.BlockDestructiveCommands,
+			PromptRouting:        cfg.Processing.PromptRouting,
+			Classifier:           classifierStage,
+			DefaultLanguage:      cfg.Processing.DefaultLanguage,
+			PromptVersion:        promptVersion,
+			SeverityPolicy:       severityPolicy,
+			Examples:             exampleStore,
+			ExampleCount:         cfg.FewShot.Count,
+			ExampleMinSimilarity: cfg.FewShot.MinSimilarity,
+			PostProcessOrder:     cfg.Processing.PostProcessors,
+		},
+		logger,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("POST_PROCESSORS: %w", err)
+	}
+	return analyzer, nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/ai-devops/internal/service"
+)
+
+const (
+	// lineBuffer is the number of output lines queued while an analysis runs.
+	lineBuffer = 4096
+
+	// defaultRunLines bounds the command output kept for analysis.
+	defaultRunLines = 2000
+
+	// exitCannotRun is returned when the command cannot be started, as
+	// shells do for unknown commands.
+	exitCannotRun = 127
+)
+
+// runRun implements "ai-devops run".
+func runRun(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "Usage: ai-devops run [flags] -- command [args...]\n\n"+
+			"Runs command and analyzes its output when it exits non-zero or, with\n"+
+			"--pattern, when an error line appears. The exit status is the command's.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	var pf pipelineFlags
+	pf.register(fs)
+	pattern := fs.String("pattern", "", "also analyze while running when a line matches `regexp` (e.g. \"ERROR\")")
+	settle := fs.Duration("settle", defaultSettle, "how long to collect lines after a --pattern match before analyzing")
+	contextLines := fs.Int("context", defaultRunLines, "maximum output `lines` analyzed")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	cfg, err := newTriggerConfig(*pattern, *settle, *contextLines)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitUsage
+	}
+	render, err := pf.validate()
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitUsage
+	}
+
+	analyzer, logger, err := pf.pipeline(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitUsage
+	}
+	defer logger.Sync()
+
+	lines := make(chan string, lineBuffer)
+	outLines := &lineWriter{lines: lines}
+	errLines := &lineWriter{lines: lines}
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	cmd.Stdin = stdin
+	cmd.Stdout = io.MultiWriter(stdout, outLines)
+	cmd.Stderr = io.MultiWriter(stderr, errLines)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The terminal delivers Ctrl-C to the command as well; let it decide how
+	// to exit. Once it has, a signal cancels the pending analysis.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	exited := make(chan struct{})
+
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitCannotRun
+	}
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				select {
+				case <-exited:
+					cancel()
+				default:
+					if sig == syscall.SIGTERM {
+						_ = cmd.Process.Signal(sig)
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	type watched struct {
+		analyses int
+		rest     []string
+	}
+	done := make(chan watched, 1)
+	go func() {
+		analyses, rest := watchLines(ctx, lines, cfg, func(log string) {
+			fmt.Fprintf(stderr, "\n=== ai-devops: error in the output of %s ===\n", fs.Arg(0))
+			analyzeInline(ctx, analyzer, &pf, render, log, stderr, stderr)
+		})
+		done <- watched{analyses, rest}
+	}()
+
+	waitErr := cmd.Wait()
+	close(exited)
+	outLines.Flush()
+	errLines.Flush()
+	close(lines)
+	result := <-done
+
+	status := exitStatus(waitErr)
+	if status < 0 {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", waitErr)
+		return exitFailed
+	}
+	if status != 0 && result.analyses == 0 {
+		fmt.Fprintf(stderr, "\n=== ai-devops: %s exited with status %d ===\n", strings.Join(fs.Args(), " "), status)
+		analyzeInline(ctx, analyzer, &pf, render, strings.Join(result.rest, "\n"), stderr, stderr)
+	}
+	return status
+}
+
+// exitStatus returns the exit status of a finished command, 128+signal if
+// it was killed, or -1 if it could not be waited for.
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return -1
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
+
+// analyzeInline analyzes log and writes the result to out. Failures are
+// reported on stderr; the watched command or file is unaffected.
+func analyzeInline(ctx context.Context, analyzer *service.Analyzer, pf *pipelineFlags, render renderFunc, log string, out, stderr io.Writer) {
+	resp, err := analyzer.Analyze(ctx, pf.request(log))
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: analysis failed: %v\n", err)
+		return
+	}
+	if err := render(out, resp); err != nil {
+		fmt.Fprintf(stderr, "ai-devops: writing output: %v\n", err)
+	}
+}
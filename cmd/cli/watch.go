@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ai-devops/internal/ingest"
+)
+
+// Watch defaults.
+const (
+	defaultSettle       = 2 * time.Second
+	defaultContextLines = 200
+	tailPollInterval    = 250 * time.Millisecond
+)
+
+// triggerConfig controls when watched lines are analyzed.
+type triggerConfig struct {
+	// pattern starts an analysis; nil analyzes only what remains at the end.
+	pattern *regexp.Regexp
+
+	// settle is how long to keep collecting lines after a match, so that the
+	// stack trace or summary following the error line is part of the log.
+	settle time.Duration
+
+	// maxLines bounds the lines kept: the context before the match and the
+	// lines collected while settling.
+	maxLines int
+}
+
+// watchLines reads lines until the channel is closed or ctx is done and
+// calls analyze with the recent lines each time the pattern matched and the
+// output settled. The lines are cleared after each analysis. It returns the
+// number of analyses and the lines not analyzed.
+func watchLines(ctx context.Context, lines <-chan string, cfg triggerConfig, analyze func(log string)) (int, []string) {
+	var window []string
+	var settled <-chan time.Time
+	analyses := 0
+
+	flush := func() {
+		analyze(strings.Join(window, "\n"))
+		analyses++
+		window = window[:0]
+		settled = nil
+	}
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if settled != nil {
+					flush()
+				}
+				return analyses, window
+			}
+			window = append(window, line)
+			if len(window) > cfg.maxLines {
+				window = window[len(window)-cfg.maxLines:]
+			}
+			if settled == nil && cfg.pattern != nil && cfg.pattern.MatchString(line) {
+				settled = time.After(cfg.settle)
+			}
+		case <-settled:
+			flush()
+		case <-ctx.Done():
+			return analyses, window
+		}
+	}
+}
+
+// lineWriter sends the lines written to it to a channel.
+type lineWriter struct {
+	lines   chan<- string
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	data := append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		w.lines <- strings.TrimRight(string(data[:i]), "\r")
+		data = data[i+1:]
+	}
+	w.partial = append(w.partial[:0], data...)
+	return len(p), nil
+}
+
+// Flush sends the last, unterminated line.
+func (w *lineWriter) Flush() {
+	if len(w.partial) > 0 {
+		w.lines <- strings.TrimRight(string(w.partial), "\r")
+		w.partial = w.partial[:0]
+	}
+}
+
+// tailFile sends the lines appended to path until ctx is done. It starts at
+// the end of the file unless fromStart, rereads a truncated file from the
+// start and reopens the file when it is replaced (log rotation).
+func tailFile(ctx context.Context, path string, fromStart bool, poll time.Duration, lines chan<- string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	if !fromStart {
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+	current, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	var partial string
+	for {
+		chunk, err := reader.ReadString('\n')
+		partial += chunk
+		if err == nil {
+			select {
+			case lines <- strings.TrimRight(partial, "\r\n"):
+			case <-ctx.Done():
+				return nil
+			}
+			partial = ""
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		select {
+		case <-time.After(poll):
+		case <-ctx.Done():
+			return nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			// Rotated away and not recreated yet
+			continue
+		}
+		if !os.SameFile(info, current) {
+			replaced, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			f.Close()
+			f, current = replaced, info
+			reader.Reset(f)
+			partial = ""
+			continue
+		}
+		if offset, err := f.Seek(0, io.SeekCurrent); err == nil && info.Size() < offset {
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			reader.Reset(f)
+			partial = ""
+		}
+	}
+}
+
+// runWatch implements "ai-devops watch".
+func runWatch(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "Usage: ai-devops watch [flags] file\n\nTails file and analyzes the recent lines whenever an error line appears.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	var pf pipelineFlags
+	pf.register(fs)
+	pattern := fs.String("pattern", ingest.DefaultErrorPattern, "`regexp` of the lines that trigger an analysis")
+	settle := fs.Duration("settle", defaultSettle, "how long to collect lines after an error line before analyzing")
+	contextLines := fs.Int("context", defaultContextLines, "maximum `lines` analyzed, including those before the error line")
+	fromStart := fs.Bool("from-start", false, "read the file from the start instead of only new lines")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	path := fs.Arg(0)
+
+	cfg, err := newTriggerConfig(*pattern, *settle, *contextLines)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitUsage
+	}
+	if cfg.pattern == nil {
+		fmt.Fprintln(stderr, "ai-devops: watch needs a --pattern")
+		return exitUsage
+	}
+	render, err := pf.validate()
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitUsage
+	}
+
+	analyzer, logger, err := pf.pipeline(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitUsage
+	}
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	lines := make(chan string, lineBuffer)
+	tailErr := make(chan error, 1)
+	go func() {
+		tailErr <- tailFile(ctx, path, *fromStart, tailPollInterval, lines)
+		close(lines)
+	}()
+
+	fmt.Fprintf(stderr, "ai-devops: watching %s (Ctrl-C to stop)\n", path)
+	watchLines(ctx, lines, cfg, func(log string) {
+		fmt.Fprintf(stdout, "\n=== ai-devops: error in %s at %s ===\n", path, time.Now().Format(time.TimeOnly))
+		analyzeInline(ctx, analyzer, &pf, render, log, stdout, stderr)
+	})
+
+	if err := <-tailErr; err != nil {
+		fmt.Fprintf(stderr, "ai-devops: %v\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// newTriggerConfig validates the trigger flags. An empty pattern disables
+// pattern triggers.
+func newTriggerConfig(pattern string, settle time.Duration, maxLines int) (triggerConfig, error) {
+	cfg := triggerConfig{settle: settle, maxLines: maxLines}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid pattern: %w", err)
+		}
+		cfg.pattern = re
+	}
+	if settle < 0 {
+		return cfg, fmt.Errorf("settle must not be negative, got %s", settle)
+	}
+	if maxLines < 1 {
+		return cfg, fmt.Errorf("context must be at least 1 line, got %d", maxLines)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,181 @@
+// Package main provides unit tests for watch and run mode.
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		input    []string
+		wantLogs []string
+		wantRest []string
+	}{
+		{
+			name:     "error with its trace",
+			pattern:  "ERROR",
+			input:    []string{"INFO start", "ERROR boom", "\tat main.go:3"},
+			wantLogs: []string{"INFO start\nERROR boom\n\tat main.go:3"},
+		},
+		{
+			name:     "context is bounded",
+			pattern:  "ERROR",
+			input:    []string{"a", "b", "c", "ERROR boom"},
+			wantLogs: []string{"b\nc\nERROR boom"},
+		},
+		{
+			name:     "no pattern keeps the rest",
+			input:    []string{"a", "b", "c", "d"},
+			wantRest: []string{"b", "c", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := triggerConfig{settle: time.Hour, maxLines: 3}
+			if tt.pattern != "" {
+				cfg.pattern = regexp.MustCompile(tt.pattern)
+			}
+			lines := make(chan string, len(tt.input))
+			for _, line := range tt.input {
+				lines <- line
+			}
+			close(lines)
+
+			var logs []string
+			n, rest := watchLines(context.Background(), lines, cfg, func(log string) {
+				logs = append(logs, log)
+			})
+			if n != len(tt.wantLogs) || strings.Join(logs, "|") != strings.Join(tt.wantLogs, "|") {
+				t.Errorf("analyzed %q, want %q", logs, tt.wantLogs)
+			}
+			if strings.Join(rest, "|") != strings.Join(tt.wantRest, "|") {
+				t.Errorf("rest = %q, want %q", rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestWatchLines_Settle(t *testing.T) {
+	cfg := triggerConfig{pattern: regexp.MustCompile("ERROR"), settle: 10 * time.Millisecond, maxLines: 100}
+	lines := make(chan string)
+	analyzed := make(chan string, 2)
+	go watchLines(context.Background(), lines, cfg, func(log string) { analyzed <- log })
+
+	lines <- "ERROR first"
+	if got := <-analyzed; got != "ERROR first" {
+		t.Errorf("first analysis = %q", got)
+	}
+	lines <- "recovered"
+	lines <- "ERROR second"
+	if got := <-analyzed; got != "recovered\nERROR second" {
+		t.Errorf("second analysis = %q", got)
+	}
+	close(lines)
+}
+
+func TestLineWriter(t *testing.T) {
+	lines := make(chan string, 10)
+	w := &lineWriter{lines: lines}
+	w.Write([]byte("one\r\ntw"))
+	w.Write([]byte("o\nthree"))
+	w.Flush()
+	close(lines)
+
+	var got []string
+	for line := range lines {
+		got = append(got, line)
+	}
+	if strings.Join(got, "|") != "one|two|three" {
+		t.Errorf("lines = %q", got)
+	}
+}
+
+func TestTailFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old line\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := make(chan string, 10)
+	go tailFile(ctx, path, false, 5*time.Millisecond, lines)
+
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for a line")
+			return ""
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	appendFile(t, path, "new line\n")
+	if got := next(); got != "new line" {
+		t.Errorf("line = %q, want new line", got)
+	}
+
+	// Rotation: the file is replaced by a new one
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "after rotation\n")
+	if got := next(); got != "after rotation" {
+		t.Errorf("line = %q, want after rotation", got)
+	}
+}
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_Command(t *testing.T) {
+	t.Setenv("AI_API_KEY", "")
+	t.Setenv("RULES_ONLY", "") // restored after --offline sets it
+
+	tests := []struct {
+		name       string
+		script     string
+		wantStatus int
+		wantStderr string
+	}{
+		{"success is not analyzed", "echo ok", 0, ""},
+		{"failure is analyzed", "echo 'npm ERR! code ENOENT' >&2; exit 3", 3, "Source:     rules:npm_install_failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run([]string{"run", "--offline", "--", "sh", "-c", tt.script}, strings.NewReader(""), &stdout, &stderr)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (stderr: %s)", status, tt.wantStatus, stderr.String())
+			}
+			if tt.wantStderr == "" && strings.Contains(stderr.String(), "ai-devops") {
+				t.Errorf("unexpected analysis: %s", stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr %q does not contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
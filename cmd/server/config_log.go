@@ -0,0 +1,67 @@
+package main
+
+import (
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/rules"
+	"go.uber.org/zap"
+)
+
+// logEffectiveConfig logs every setting with its source so operators can see
+// which environment variables took effect. Secrets are redacted.
+func logEffectiveConfig(logger *zap.Logger, cfg *config.Config) {
+	settings := cfg.Settings()
+
+	overridden := 0
+	for _, s := range settings {
+		source := "default"
+		if s.Overridden {
+			source = "env"
+			overridden++
+		}
+		logger.Info("config",
+			zap.String("key", s.Key),
+			zap.String("value", s.Value),
+			zap.String("default", s.Default),
+			zap.String("source", source),
+		)
+	}
+
+	logger.Info("configuration loaded",
+		zap.Int("settings", len(settings)),
+		zap.Int("overridden", overridden),
+		zap.String("ai_provider", string(cfg.AI.Provider)),
+		zap.String("ai_model", cfg.AI.Model),
+		zap.Bool("mock_mode", cfg.AI.MockMode),
+	)
+}
+
+// applyReload logs the differences between the previously loaded and the
+// reloaded configuration, so each change is reported once, and applies the
+// settings that can change at runtime. Everything else is reported as
+// requiring a restart.
+func applyReload(logger *zap.Logger, previous, reloaded *config.Config, ruleEngine *rules.Engine, adaptive bool) {
+	changes := config.DiffSettings(previous, reloaded)
+	if len(changes) == 0 {
+		logger.Info("configuration reloaded, no changes")
+		return
+	}
+
+	for _, change := range changes {
+		applied := false
+		switch change.Key {
+		case "RULE_CONFIDENCE_THRESHOLD":
+			if !adaptive {
+				ruleEngine.SetConfidenceThreshold(reloaded.Processing.RuleConfidenceThreshold)
+				applied = true
+			}
+		}
+
+		logger.Info("config changed",
+			zap.String("key", change.Key),
+			zap.String("old", change.Old),
+			zap.String("new", change.New),
+			zap.Bool("applied", applied),
+			zap.Bool("restart_required", !applied),
+		)
+	}
+}
@@ -6,19 +6,44 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/cache"
+	"github.com/ai-devops/internal/callback"
+	"github.com/ai-devops/internal/capture"
+	"github.com/ai-devops/internal/classifier"
 	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/escalate"
+	"github.com/ai-devops/internal/examples"
+	"github.com/ai-devops/internal/experiment"
+	"github.com/ai-devops/internal/export"
+	"github.com/ai-devops/internal/fewshot"
 	"github.com/ai-devops/internal/handler"
+	"github.com/ai-devops/internal/ingest"
+	"github.com/ai-devops/internal/knowledge"
 	"github.com/ai-devops/internal/logger"
+	"github.com/ai-devops/internal/loki"
+	"github.com/ai-devops/internal/notify"
+	"github.com/ai-devops/internal/policy"
+	"github.com/ai-devops/internal/redis"
 	"github.com/ai-devops/internal/rules"
 	"github.com/ai-devops/internal/service"
+	"github.com/ai-devops/internal/store"
+	"github.com/ai-devops/internal/tenant"
+	"github.com/ai-devops/internal/tickets"
+	"github.com/ai-devops/internal/usage"
+	"github.com/ai-devops/internal/vectorindex"
+	"github.com/ai-devops/internal/version"
+	"github.com/ai-devops/internal/webhook"
 	"github.com/ai-devops/pkg/sanitizer"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -26,6 +51,8 @@ import (
 )
 
 func main() {
+	started := time.Now()
+
 	// Load .env file if it exists (development)
 	_ = godotenv.Load()
 
@@ -39,7 +66,10 @@ func main() {
 	}
 	defer zapLogger.Sync()
 
+	build := version.Get()
 	zapLogger.Info("starting AI DevOps Assistant",
+		zap.String("version", build.Version),
+		zap.String("commit", build.Commit),
 		zap.Bool("development", isDev),
 	)
 
@@ -49,64 +79,613 @@ func main() {
 		zapLogger.Fatal("failed to load configuration", zap.Error(err))
 	}
 
-	zapLogger.Info("configuration loaded",
-		zap.String("port", cfg.Server.Port),
-		zap.String("ai_provider", string(cfg.AI.Provider)),
-		zap.String("ai_model", cfg.AI.Model),
-		zap.Bool("mock_mode", cfg.AI.MockMode),
-		zap.Bool("rules_enabled", cfg.Processing.EnableRules),
-	)
+	logEffectiveConfig(zapLogger, cfg)
+
+	// Connect to Redis for state shared between replicas
+	var redisClient *redis.Client
+	if cfg.Redis.URL != "" {
+		redisClient, err = redis.New(redis.Config{
+			URL:      cfg.Redis.URL,
+			PoolSize: cfg.Redis.PoolSize,
+			Timeout:  cfg.Redis.Timeout,
+		})
+		if err != nil {
+			zapLogger.Fatal("failed to configure redis", zap.Error(err))
+		}
+		pingCtx, cancel := context.WithTimeout(context.Background(), cfg.Redis.Timeout)
+		if err := redisClient.Ping(pingCtx); err != nil {
+			// Every shared component falls back to local state while
+			// Redis is unreachable
+			zapLogger.Warn("redis unreachable at startup", zap.Error(err))
+		}
+		cancel()
+		zapLogger.Info("sharing cache, pacing and endpoint health through redis",
+			zap.String("key_prefix", cfg.Redis.KeyPrefix),
+		)
+	}
+
+	// Start plugins providing extra rules and AI clients
+	plugins, err := startPlugins(&cfg.Plugins, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("failed to start plugins", zap.Error(err))
+	}
 
 	// Initialize dependencies
-	var aiClient ai.Client
+	var aiClient, terraformClient ai.Client
+	var aiRouter, terraformRouter, cheapRouter *ai.Router
+	var modelSelector *ai.ModelSelector
+	var aiSwitches []*ai.SwitchableClient
+	var promptVersion string
+	var variantClient experiment.VariantClient
+	var tenantClient ai.ClientFactory
+	if cfg.Processing.RulesOnly {
+		zapLogger.Warn("running in rules-only mode - the AI is never called")
+	}
 	if cfg.AI.MockMode {
 		zapLogger.Warn("running in mock mode - AI responses are simulated")
 		aiClient = ai.NewMockClient(zapLogger)
+		terraformClient = aiClient
+	} else if cfg.Plugins.AIClient != "" {
+		zapLogger.Info("using AI plugin", zap.String("plugin", cfg.Plugins.AIClient))
+		aiClient, err = pluginClient(plugins, cfg.Plugins.AIClient)
+		if err != nil {
+			zapLogger.Fatal("failed to use AI plugin", zap.Error(err))
+		}
+		terraformClient = aiClient
 	} else {
-		// Create prompt builder
+		// Create prompt builders
 		promptBuilder, err := ai.NewDefaultPromptBuilder()
 		if err != nil {
 			zapLogger.Fatal("failed to create prompt builder", zap.Error(err))
 		}
+		terraformPromptBuilder, err := ai.NewTerraformPromptBuilder()
+		if err != nil {
+			zapLogger.Fatal("failed to create terraform prompt builder", zap.Error(err))
+		}
 
 		// Create validator
-		validator := ai.NewDefaultValidator()
+		validator := ai.NewDefaultValidator().WithReferenceDomains(cfg.AI.ReferenceDomains...)
 
-		// Create AI client based on provider
 		switch cfg.AI.Provider {
 		case config.AIProviderGemini:
 			zapLogger.Info("using Gemini AI provider")
-			aiClient = ai.NewGeminiClient(&cfg.AI, promptBuilder, validator, zapLogger)
 		default:
 			zapLogger.Info("using OpenAI-compatible AI provider")
-			aiClient = ai.NewOpenAIClient(&cfg.AI, promptBuilder, validator, zapLogger)
+		}
+		aiClient, aiRouter = newAIClient(&cfg.AI, promptBuilder, validator, zapLogger)
+		promptVersion = ai.PromptVersion(promptBuilder)
+		zapLogger.Info("prompt version", zap.String("version", promptVersion))
+		terraformClient, terraformRouter = newAIClient(&cfg.AI, terraformPromptBuilder, validator, zapLogger)
+
+		// Admins may switch the provider and model at runtime
+		clientFactory := func(prompter ai.PromptBuilder) ai.ClientFactory {
+			return func(aiCfg *config.AIConfig) ai.Client {
+				client, router := newAIClient(aiCfg, prompter, validator, zapLogger)
+				if router != nil && redisClient != nil {
+					router.SetSharedHealth(redis.NewEndpointHealth(redisClient, cfg.Redis.KeyPrefix))
+				}
+				return client
+			}
+		}
+		aiSwitch := ai.NewSwitchableClient(aiClient, cfg.AI, clientFactory(promptBuilder))
+		terraformSwitch := ai.NewSwitchableClient(terraformClient, cfg.AI, clientFactory(terraformPromptBuilder))
+		aiClient, terraformClient = aiSwitch, terraformSwitch
+		tenantClient = clientFactory(promptBuilder)
+		if !cfg.Processing.RulesOnly {
+			aiSwitches = []*ai.SwitchableClient{aiSwitch, terraformSwitch}
+		}
+
+		// Experiment variants overriding the prompt, model or temperature
+		variantClient = func(v experiment.VariantConfig) (ai.Client, string, error) {
+			var prompter ai.PromptBuilder = promptBuilder
+			if v.SystemPromptFile != "" {
+				systemPrompt, err := os.ReadFile(v.SystemPromptFile)
+				if err != nil {
+					return nil, "", fmt.Errorf("read system prompt: %w", err)
+				}
+				if prompter, err = ai.NewSystemPromptBuilder(string(systemPrompt)); err != nil {
+					return nil, "", err
+				}
+			}
+			variantCfg := cfg.AI
+			if v.Model != "" {
+				variantCfg.Model = v.Model
+			}
+			if v.Temperature != nil {
+				variantCfg.Temperature = *v.Temperature
+			}
+			return clientFactory(prompter)(&variantCfg), ai.PromptVersion(prompter), nil
+		}
+
+		if cfg.AI.CheapModel != "" {
+			cheapCfg := cfg.AI
+			cheapCfg.Model = cfg.AI.CheapModel
+			var cheapClient ai.Client
+			cheapClient, cheapRouter = newAIClient(&cheapCfg, promptBuilder, validator, zapLogger)
+			modelSelector = ai.NewModelSelector(cheapClient, aiClient, cfg.AI.CheapMaxBytes, cfg.AI.CheapMaxErrorLines, zapLogger)
+			aiClient = modelSelector
+			zapLogger.Info("selecting the model by log complexity",
+				zap.String("cheap_model", cfg.AI.CheapModel),
+				zap.Int("max_bytes", cfg.AI.CheapMaxBytes),
+				zap.Int("max_error_lines", cfg.AI.CheapMaxErrorLines),
+			)
+		}
+		if cfg.Consensus.Enabled() {
+			consensusCfg := cfg.ConsensusAI()
+			aiClient = ai.NewConsensusClient(
+				aiClient, cfg.AI.Model,
+				newProviderClient(&consensusCfg, promptBuilder, validator, zapLogger), consensusCfg.Model,
+				cfg.Consensus.Mode == config.ConsensusHighSeverity, zapLogger,
+			)
+			zapLogger.Info("cross-checking analyses with a second model",
+				zap.String("mode", cfg.Consensus.Mode),
+				zap.String("model", consensusCfg.Model),
+			)
+		}
+		if aiRouter != nil {
+			zapLogger.Info("routing AI requests across endpoints",
+				zap.Strings("endpoints", cfg.AI.BaseURLs),
+				zap.String("strategy", cfg.AI.Routing),
+			)
+		}
+	}
+
+	if redisClient != nil {
+		endpointHealth := redis.NewEndpointHealth(redisClient, cfg.Redis.KeyPrefix)
+		for _, router := range []*ai.Router{aiRouter, terraformRouter, cheapRouter} {
+			if router != nil {
+				router.SetSharedHealth(endpointHealth)
+			}
+		}
+	}
+
+	// Pace outbound provider requests; all clients share the provider limits
+	var pacer *ai.Pacer
+	if cfg.AI.RequestsPerMinute > 0 || cfg.AI.TokensPerMinute > 0 {
+		pacer = ai.NewPacer(cfg.AI.RequestsPerMinute, cfg.AI.TokensPerMinute)
+		if redisClient != nil {
+			pacer.SetSharedBuckets(redis.NewBuckets(redisClient, cfg.Redis.KeyPrefix))
+		}
+		aiClient = ai.NewPacedClient(aiClient, pacer, zapLogger)
+		terraformClient = ai.NewPacedClient(terraformClient, pacer, zapLogger)
+	}
+
+	// Initialize A/B experiment
+	var abExperiment *experiment.Experiment
+	if cfg.Processing.ExperimentPath != "" {
+		if variantClient == nil {
+			zapLogger.Warn("ignoring the experiment without an AI provider (mock mode or AI_PLUGIN)")
+		} else {
+			experimentCfg, err := experiment.LoadConfig(cfg.Processing.ExperimentPath)
+			if err == nil {
+				abExperiment, err = experiment.New(experimentCfg, func(v experiment.VariantConfig) (ai.Client, string, error) {
+					// The control variant keeps the service's client
+					if !v.Overrides() {
+						return aiClient, promptVersion, nil
+					}
+					client, version, err := variantClient(v)
+					if err == nil && pacer != nil {
+						client = ai.NewPacedClient(client, pacer, zapLogger)
+					}
+					return client, version, err
+				})
+			}
+			if err != nil {
+				zapLogger.Fatal("failed to load experiment", zap.Error(err))
+			}
+			zapLogger.Info("experiment loaded",
+				zap.String("name", experimentCfg.Name),
+				zap.Int("variants", len(experimentCfg.Variants)),
+			)
 		}
 	}
 
 	// Initialize rule engine
+	extraRules, err := pluginRules(plugins)
+	if err != nil {
+		zapLogger.Fatal("failed to load plugin rules", zap.Error(err))
+	}
+	ruleSet, err := rules.FilterCategories(
+		append(rules.DefaultRules(), extraRules...),
+		cfg.Processing.EnabledRuleCategories,
+		cfg.Processing.DisabledRuleCategories,
+	)
+	if err != nil {
+		zapLogger.Fatal("failed to select rule categories", zap.Error(err))
+	}
 	ruleEngine := rules.NewEngine(
-		rules.DefaultRules(),
+		ruleSet,
 		cfg.Processing.RuleConfidenceThreshold,
 		zapLogger,
 	)
 
+	// Initialize adaptive threshold controller
+	var thresholdCtl *rules.AdaptiveController
+	if cfg.Processing.AdaptiveThreshold {
+		adaptiveCfg := rules.DefaultAdaptiveConfig()
+		adaptiveCfg.MinThreshold = cfg.Processing.AdaptiveThresholdMin
+		adaptiveCfg.MaxThreshold = cfg.Processing.AdaptiveThresholdMax
+		thresholdCtl = rules.NewAdaptiveController(ruleEngine, adaptiveCfg, zapLogger)
+	}
+
 	// Initialize sanitizer
 	logSanitizer := sanitizer.New(cfg.Processing.MaxLogSize)
+	if cfg.Processing.SanitizerConfigPath != "" {
+		sanitizerCfg, err := sanitizer.LoadConfig(cfg.Processing.SanitizerConfigPath)
+		if err == nil {
+			logSanitizer, err = sanitizer.NewFromConfig(cfg.Processing.MaxLogSize, sanitizerCfg)
+		}
+		if err != nil {
+			zapLogger.Fatal("failed to configure sanitizer", zap.Error(err))
+		}
+		zapLogger.Info("sanitizer configured",
+			zap.Int("extra_patterns", len(sanitizerCfg.Patterns)),
+			zap.Int("allowlist", len(sanitizerCfg.Allowlist)),
+			zap.Bool("default_patterns", !sanitizerCfg.DisableDefaultPatterns),
+		)
+	}
+	logSanitizer = withSanitizerOptions(logSanitizer, &cfg.Processing)
+
+	// Initialize token usage metering
+	pricing := usage.DefaultPricing()
+	if cfg.AI.PriceInputPerMTok > 0 || cfg.AI.PriceOutputPerMTok > 0 {
+		pricing[strings.ToLower(cfg.AI.Model)] = usage.ModelPrice{
+			InputPerMillion:  cfg.AI.PriceInputPerMTok,
+			OutputPerMillion: cfg.AI.PriceOutputPerMTok,
+		}
+	}
+	tokenMeter := usage.NewMeter(
+		usage.NewBudget(cfg.AI.DailyTokenBudget, cfg.AI.MonthlyTokenBudget),
+		pricing,
+		cfg.AI.Model,
+	)
+
+	// Initialize result cache
+	var resultCache cache.Cache
+	var lruCache *cache.LRU
+	switch {
+	case cfg.Processing.CacheMaxBytes <= 0:
+	case redisClient != nil:
+		resultCache = cache.NewRedisCache(redisClient, cfg.Redis.KeyPrefix, cfg.Redis.CacheTTL, zapLogger)
+	default:
+		lruCache = cache.NewLRU(cfg.Processing.CacheMaxBytes)
+		resultCache = lruCache
+		if cfg.Processing.CacheSnapshotPath != "" {
+			n, err := lruCache.LoadSnapshot(cfg.Processing.CacheSnapshotPath)
+			if err != nil {
+				zapLogger.Warn("failed to restore cache snapshot", zap.Error(err))
+			} else {
+				zapLogger.Info("cache snapshot restored", zap.Int("entries", n))
+			}
+		}
+	}
+
+	// Initialize AI concurrency limiter
+	var aiLimiter *service.ConcurrencyLimiter
+	if cfg.AI.MaxConcurrency > 0 {
+		aiLimiter = service.NewConcurrencyLimiter(cfg.AI.MaxConcurrency, cfg.AI.QueueSize, cfg.AI.QueueTimeout, cfg.AI.TenantWeights)
+	}
+
+	// Initialize tenants with their own policies
+	var tenants *tenant.Registry
+	var tenantPolicies map[string]service.TenantPolicy
+	if cfg.Server.TenantsPath != "" {
+		tenantsCfg, err := tenant.LoadConfig(cfg.Server.TenantsPath)
+		if err == nil {
+			tenants, err = tenant.NewRegistry(tenantsCfg)
+		}
+		if err == nil {
+			if tenantClient != nil && pacer != nil {
+				unpaced := tenantClient
+				tenantClient = func(aiCfg *config.AIConfig) ai.Client {
+					return ai.NewPacedClient(unpaced(aiCfg), pacer, zapLogger)
+				}
+			}
+			tenantPolicies, err = newTenantPolicies(cfg, tenants.Tenants(), tenantClient, zapLogger)
+		}
+		if err != nil {
+			zapLogger.Fatal("failed to load tenants", zap.Error(err))
+		}
+		zapLogger.Info("tenants loaded",
+			zap.Int("tenants", len(tenantsCfg.Tenants)),
+			zap.Bool("require_api_key", tenantsCfg.RequireAPIKey),
+		)
+	}
+
+	// Track analyses and token spend per tenant against their quotas
+	tenantMeter := usage.NewTenantMeter(newTenantQuotas(tenants.Tenants()))
+
+	// Initialize analysis store
+	newStore := func(string) store.Store {
+		switch cfg.Store.Backend {
+		default:
+			return store.NewMemoryStore(cfg.Store.MaxRecords)
+		}
+	}
+	var analysisStore store.Store
+	if tenants != nil {
+		// Each tenant's history is kept apart
+		var ids []string
+		for _, t := range tenants.Tenants() {
+			ids = append(ids, t.ID)
+		}
+		analysisStore = store.NewTenantStore(ids, newStore)
+	} else {
+		analysisStore = newStore(domain.DefaultTenant)
+	}
+
+	// Initialize notifications
+	var notifier *notify.Notifier
+	if cfg.Notify.SlackBotToken != "" {
+		notifier = notify.NewNotifier(
+			notify.NewSlackSender(cfg.Notify.SlackBotToken, cfg.Notify.SlackChannel, ""),
+			cfg.Notify.DedupWindow,
+			cfg.Notify.MinSeverity,
+			zapLogger,
+		)
+	}
+
+	// Initialize issue tracker tickets
+	var ticketFiler *tickets.Filer
+	if tracker := newTicketTracker(&cfg.Tickets); tracker != nil {
+		ticketFiler = tickets.NewFiler(tracker, cfg.Tickets.Auto, cfg.Tickets.MinSeverity, cfg.Tickets.Labels, zapLogger)
+		zapLogger.Info("issue tracker tickets enabled",
+			zap.String("tracker", tracker.Name()),
+			zap.Bool("auto", cfg.Tickets.Auto),
+		)
+	}
+
+	// Initialize on-call escalation
+	var escalator *escalate.Escalator
+	if cfg.Escalation.Enabled() {
+		escalator = escalate.NewEscalator(newEscalationSinks(&cfg.Escalation), escalate.Conditions{
+			MinSeverity:   cfg.Escalation.MinSeverity,
+			ErrorTypes:    cfg.Escalation.ErrorTypes,
+			Sources:       cfg.Escalation.Sources,
+			MinConfidence: cfg.Escalation.MinConfidence,
+		}, zapLogger)
+		zapLogger.Info("on-call escalation enabled",
+			zap.String("min_severity", string(cfg.Escalation.MinSeverity)),
+			zap.Strings("error_types", cfg.Escalation.ErrorTypes),
+		)
+	}
+
+	// Initialize classifier front-stage
+	var classifierStage *classifier.Stage
+	if cfg.Processing.ClassifierModelPath != "" {
+		model, err := classifier.Load(cfg.Processing.ClassifierModelPath)
+		if err != nil {
+			zapLogger.Fatal("failed to load classifier model", zap.Error(err))
+		}
+		classifierStage = classifier.NewStage(model, cfg.Processing.ClassifierSkipThreshold, cfg.Processing.ClassifierHintThreshold)
+		zapLogger.Info("classifier model loaded", zap.Int("classes", len(model.Classes)))
+	}
+
+	// Initialize severity policy
+	var severityPolicy *policy.SeverityPolicy
+	if cfg.Processing.SeverityPolicyPath != "" {
+		policyCfg, err := policy.LoadConfig(cfg.Processing.SeverityPolicyPath)
+		if err == nil {
+			severityPolicy, err = policy.NewSeverityPolicy(policyCfg)
+		}
+		if err != nil {
+			zapLogger.Fatal("failed to load severity policy", zap.Error(err))
+		}
+		zapLogger.Info("severity policy loaded", zap.Int("overrides", severityPolicy.Len()))
+	}
+
+	// Initialize Loki log context
+	var contextFetcher service.ContextFetcher
+	if cfg.Loki.URL != "" {
+		contextFetcher = loki.NewClient(loki.Config{
+			URL:          cfg.Loki.URL,
+			TenantID:     cfg.Loki.TenantID,
+			Username:     cfg.Loki.Username,
+			Password:     cfg.Loki.Password,
+			Token:        cfg.Loki.Token,
+			Timeout:      cfg.Loki.Timeout,
+			DefaultRange: cfg.Loki.DefaultRange,
+			MaxRange:     cfg.Loki.MaxRange,
+			MaxLines:     cfg.Loki.MaxLines,
+		})
+		zapLogger.Info("loki log context enabled", zap.String("url", cfg.Loki.URL))
+	}
+
+	// Initialize runbook knowledge sources
+	var knowledgeFinder *knowledge.Finder
+	if cfg.Knowledge.Enabled() {
+		knowledgeFinder, err = newKnowledgeFinder(&cfg.Knowledge, zapLogger)
+		if err != nil {
+			zapLogger.Fatal("failed to load runbooks", zap.Error(err))
+		}
+	}
+
+	// Initialize few-shot example store
+	var exampleStore *fewshot.Store
+	if cfg.FewShot.Enabled {
+		exampleStore, err = fewshot.NewStore(fewshot.Config{
+			Path:           cfg.FewShot.Path,
+			MaxPerCategory: cfg.FewShot.MaxPerCategory,
+		}, zapLogger)
+		if err != nil {
+			zapLogger.Fatal("failed to load few-shot examples", zap.Error(err))
+		}
+		zapLogger.Info("few-shot examples enabled", zap.Int("examples", exampleStore.Len()))
+	}
+
+	// Initialize similar-incident retrieval over stored analyses
+	var embedder ai.Embedder
+	var incidentIndex *vectorindex.Index
+	if cfg.Embeddings.Enabled {
+		if cfg.AI.MockMode {
+			embedder = ai.NewMockEmbedder()
+		} else {
+			embedder = ai.NewEmbedder(&cfg.Embeddings)
+		}
+		incidentIndex = vectorindex.New(cfg.Store.MaxRecords)
+		zapLogger.Info("similar incidents enabled", zap.String("embedding_model", cfg.Embeddings.Model))
+	}
+
+	// Initialize debug capture of AI prompts and raw responses
+	var captureRecorder *capture.Recorder
+	if cfg.Capture.Enabled() {
+		captureRecorder, err = capture.NewRecorder(capture.Config{
+			SampleRate: cfg.Capture.SampleRate,
+			Rejected:   cfg.Capture.Rejected,
+			MaxEntries: cfg.Capture.MaxEntries,
+			Path:       cfg.Capture.Path,
+		}, logSanitizer.Mask)
+		if err != nil {
+			zapLogger.Fatal("failed to initialize debug capture", zap.Error(err))
+		}
+		zapLogger.Info("debug capture enabled",
+			zap.Float64("sample_rate", cfg.Capture.SampleRate),
+			zap.Bool("rejected", cfg.Capture.Rejected),
+		)
+	}
+
+	// Successful AI calls, for the health endpoint
+	aiActivity := &ai.Activity{}
 
 	// Initialize analyzer service
-	analyzerSvc := service.NewAnalyzer(
+	analyzerSvc, err := service.NewAnalyzer(
 		aiClient,
 		ruleEngine,
 		logSanitizer,
 		service.AnalyzerConfig{
-			EnableRules: cfg.Processing.EnableRules,
+			EnableRules:                  cfg.Processing.EnableRules,
+			HybridMerge:                  cfg.Processing.HybridMerge,
+			RulesOnly:                    cfg.Processing.RulesOnly,
+			BestEffort:                   cfg.Processing.BestEffort,
+			MaxRequestTimeout:            cfg.Server.MaxRequestTimeout,
+			ReversibleSanitize:           cfg.Processing.ReversibleSanitization,
+			RejectPrivateKeys:            cfg.Processing.RejectPrivateKeys,
+			CompactLogs:                  cfg.Processing.CompactLogs,
+			BlockDestructive:             cfg.Response.BlockDestructiveCommands,
+			PromptRouting:                cfg.Processing.PromptRouting,
+			ShadowSampleRate:             cfg.Processing.ShadowSampleRate,
+			ThresholdController:          thresholdCtl,
+			Meter:                        tokenMeter,
+			TenantMeter:                  tenantMeter,
+			Cache:                        resultCache,
+			Limiter:                      aiLimiter,
+			Store:                        analysisStore,
+			Notifier:                     notifier,
+			Tickets:                      ticketFiler,
+			Escalator:                    escalator,
+			Classifier:                   classifierStage,
+			DefaultLanguage:              cfg.Processing.DefaultLanguage,
+			PromptVersion:                promptVersion,
+			Experiment:                   abExperiment,
+			ContextFetcher:               contextFetcher,
+			SeverityPolicy:               severityPolicy,
+			Examples:                     exampleStore,
+			ExampleCount:                 cfg.FewShot.Count,
+			ExampleMinSimilarity:         cfg.FewShot.MinSimilarity,
+			Embedder:                     embedder,
+			Incidents:                    incidentIndex,
+			SimilarIncidentCount:         cfg.Embeddings.Count,
+			SimilarIncidentMinSimilarity: cfg.Embeddings.MinSimilarity,
+			IncidentLinkBase:             cfg.Embeddings.LinkBaseURL,
+			Knowledge:                    knowledgeFinder,
+			Tenants:                      tenantPolicies,
+			Capture:                      captureRecorder,
+			Activity:                     aiActivity,
+			PostProcessOrder:             cfg.Processing.PostProcessors,
+		},
+		zapLogger,
+	)
+	if err != nil {
+		zapLogger.Fatal("invalid POST_PROCESSORS", zap.Error(err))
+	}
+
+	terraformSvc := service.NewTerraformAnalyzer(
+		terraformClient,
+		logSanitizer,
+		service.TerraformAnalyzerConfig{
+			Meter:            tokenMeter,
+			TenantMeter:      tenantMeter,
+			Limiter:          aiLimiter,
+			DefaultLanguage:  cfg.Processing.DefaultLanguage,
+			BlockDestructive: cfg.Response.BlockDestructiveCommands,
+			Capture:          captureRecorder,
+			Activity:         aiActivity,
 		},
 		zapLogger,
 	)
 
+	// Initialize async analysis callbacks
+	var callbacks *callback.Sender
+	if cfg.Callback.Secret != "" {
+		callbacks = callback.NewSender(callback.Config{
+			Secret:               cfg.Callback.Secret,
+			MaxAttempts:          cfg.Callback.MaxAttempts,
+			Backoff:              cfg.Callback.Backoff,
+			Timeout:              cfg.Callback.Timeout,
+			AllowedHosts:         cfg.Callback.AllowedHosts,
+			AllowPrivateNetworks: cfg.Callback.AllowPrivateNetworks,
+			MaxPending:           cfg.Callback.MaxPending,
+		}, zapLogger)
+	}
+
 	// Initialize handlers
-	analyzeHandler := handler.NewAnalyzeHandler(analyzerSvc, zapLogger)
-	healthHandler := handler.NewHealthHandler(zapLogger)
+	analyzeHandler := handler.NewAnalyzeHandler(analyzerSvc, callbacks, cfg.Server.MaxLogBytes, zapLogger)
+	terraformHandler := handler.NewTerraformHandler(terraformSvc, cfg.Server.MaxLogBytes, zapLogger)
+	kubernetesHandler := handler.NewKubernetesHandler(analyzerSvc, cfg.Server.MaxLogBytes, zapLogger)
+	dockerfileHandler := handler.NewDockerfileHandler(analyzerSvc, cfg.Server.MaxLogBytes, zapLogger)
+	pipelineHandler := handler.NewPipelineHandler(analyzerSvc, cfg.Server.MaxLogBytes, zapLogger)
+	thresholdHandler := handler.NewThresholdHandler(ruleEngine, thresholdCtl, zapLogger)
+	rulesHandler := handler.NewRulesHandler(ruleEngine, logSanitizer, zapLogger)
+	cacheStatsHandler := handler.NewCacheStatsHandler(resultCache, zapLogger)
+	pacerStatsHandler := handler.NewPacerStatsHandler(pacer, zapLogger)
+	modelSelectionHandler := handler.NewModelSelectionStatsHandler(modelSelector, zapLogger)
+	experimentHandler := handler.NewExperimentHandler(abExperiment, analysisStore, zapLogger)
+	aiConfigHandler := handler.NewAIConfigHandler(aiSwitches, zapLogger)
+	limiterStatsHandler := handler.NewLimiterStatsHandler(aiLimiter, zapLogger)
+	usageHandler := handler.NewUsageHandler(tokenMeter, tenantMeter, zapLogger)
+	captureHandler := handler.NewCaptureHandler(captureRecorder, zapLogger)
+	endpointStatsHandler := handler.NewEndpointStatsHandler(map[string]*ai.Router{
+		"analyze":   aiRouter,
+		"terraform": terraformRouter,
+		"cheap":     cheapRouter,
+	}, zapLogger)
+	feedbackExamples := exampleStore
+	if !cfg.FewShot.FromFeedback {
+		feedbackExamples = nil
+	}
+	historyHandler := handler.NewHistoryHandler(analysisStore, feedbackExamples, zapLogger)
+	fewShotHandler := handler.NewFewShotHandler(exampleStore, zapLogger)
+	invalidateHandler := handler.NewInvalidateHandler(resultCache, analysisStore, zapLogger)
+	exportPrompter, err := ai.NewDefaultPromptBuilder()
+	if err != nil {
+		zapLogger.Fatal("failed to create export prompt builder", zap.Error(err))
+	}
+	exportHandler := handler.NewExportHandler(export.NewFineTuneExporter(analysisStore, exportPrompter), zapLogger)
+	sampleExamples, err := examples.Load()
+	if err != nil {
+		zapLogger.Fatal("failed to load examples", zap.Error(err))
+	}
+	examplesHandler := handler.NewExamplesHandler(sampleExamples, zapLogger)
+	taxonomyHandler := handler.NewTaxonomyHandler(zapLogger)
+	ingester, err := ingest.New(analyzerSvc, ingest.Config{
+		WindowLines:  cfg.Ingest.WindowLines,
+		Settle:       cfg.Ingest.Settle,
+		MinErrors:    cfg.Ingest.MinErrors,
+		Cooldown:     cfg.Ingest.Cooldown,
+		MaxStreams:   cfg.Ingest.MaxStreams,
+		ErrorPattern: cfg.Ingest.ErrorPattern,
+	}, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("failed to configure ingestion", zap.Error(err))
+	}
+	ingestHandler := handler.NewIngestHandler(ingester, zapLogger)
+	healthHandler := handler.NewHealthHandler(handler.HealthInfo{
+		Started:    started,
+		AIMode:     healthAIMode(cfg),
+		AIProvider: healthAIProvider(cfg, aiSwitches),
+		Rules:      ruleEngine,
+		Activity:   aiActivity,
+	}, zapLogger)
 	readyHandler := handler.NewReadyHandler(zapLogger)
 
 	// Setup Gin router
@@ -119,19 +698,72 @@ func main() {
 	// Apply middleware
 	router.Use(handler.RecoveryMiddleware(zapLogger))
 	router.Use(handler.RequestIDMiddleware())
+	router.Use(handler.BodyLimitMiddleware(cfg.Server.MaxBodyBytes))
 	router.Use(handler.LoggingMiddleware(zapLogger))
 	router.Use(handler.CORSMiddleware())
+	router.Use(handler.ResponsePolicyMiddleware(cfg.Response.RedactProvenanceKeys, cfg.Response.ProvenanceMode))
 
 	// Register routes
 	router.GET("/health", healthHandler.Handle)
 	router.GET("/ready", readyHandler.Handle)
+	router.GET("/ui", handler.NewUIHandler().Handle)
 
 	// API v1 routes
-	v1 := router.Group("/api/v1")
+	v1 := router.Group("/api/v1",
+		handler.TenantMiddleware(tenants),
+		handler.TenantRateLimitMiddleware(tenants),
+	)
 	{
-		v1.POST("/analyze", analyzeHandler.Handle)
+		// CI runners may gzip large logs
+		gunzip := handler.GzipBodyMiddleware(cfg.Server.MaxBodyBytes)
+		v1.POST("/analyze", gunzip, analyzeHandler.Handle)
 		// Alias for the README spec
-		v1.POST("/ai/analyze-log", analyzeHandler.Handle)
+		v1.POST("/ai/analyze-log", gunzip, analyzeHandler.Handle)
+		v1.POST("/analyze/terraform", gunzip, terraformHandler.Handle)
+		v1.POST("/analyze/k8s", gunzip, kubernetesHandler.Handle)
+		v1.POST("/analyze/dockerfile", gunzip, dockerfileHandler.Handle)
+		v1.POST("/analyze/pipeline", gunzip, pipelineHandler.Handle)
+		v1.GET("/rules", rulesHandler.List)
+		v1.POST("/rules/test", rulesHandler.Test)
+		v1.GET("/rules/threshold", thresholdHandler.Handle)
+		v1.GET("/cache/stats", cacheStatsHandler.Handle)
+		v1.GET("/pacer/stats", pacerStatsHandler.Handle)
+		v1.GET("/ai/model-selection", modelSelectionHandler.Handle)
+		v1.GET("/ai/experiment", experimentHandler.Handle)
+		v1.GET("/limiter/stats", limiterStatsHandler.Handle)
+		v1.GET("/ai/endpoints", endpointStatsHandler.Handle)
+		v1.GET("/examples", examplesHandler.List)
+		v1.GET("/examples/:id", examplesHandler.Get)
+		v1.GET("/taxonomy", taxonomyHandler.Handle)
+		v1.GET("/fewshot", fewShotHandler.List)
+		v1.GET("/analyses", historyHandler.List)
+		v1.GET("/analyses/stats", historyHandler.Stats)
+		v1.GET("/analyses/:id", historyHandler.Get)
+		v1.GET("/analyses/:id/report", historyHandler.Report)
+		v1.GET("/analyses/:id/diff/:otherId", historyHandler.Diff)
+		v1.POST("/analyses/:id/feedback", historyHandler.Feedback)
+		v1.POST("/ingest/fluent", ingestHandler.Fluent)
+		v1.POST("/ingest/fluent/:tag", ingestHandler.Fluent)
+		v1.GET("/ingest/streams", ingestHandler.Streams)
+	}
+
+	// Webhook deliveries are authenticated per integration rather than as a
+	// tenant, as the senders cannot set tenant headers
+	webhookAuth := handler.WebhookRouteMiddleware(webhook.Configured(cfg.Webhooks), webhook.NewReplayGuard(cfg.Webhooks.ReplayWindow), zapLogger)
+	router.POST("/api/v1/ingest/webhooks/:integration", webhookAuth, ingestHandler.Webhook)
+
+	// Admin routes, authenticated with ADMIN_TOKEN rather than as a tenant
+	admin := router.Group("/api/v1/admin", handler.AdminAuthMiddleware(cfg.Admin.Token, zapLogger))
+	{
+		admin.GET("/config/ai", aiConfigHandler.Get)
+		admin.PUT("/config/ai", aiConfigHandler.Update)
+		admin.GET("/usage", usageHandler.Handle)
+		admin.GET("/captures", captureHandler.List)
+		admin.GET("/captures/:id", captureHandler.Get)
+		admin.GET("/analyses/export", exportHandler.FineTune)
+		admin.POST("/analyses/invalidate", invalidateHandler.Handle)
+		admin.POST("/fewshot", fewShotHandler.Add)
+		admin.DELETE("/fewshot/:id", fewShotHandler.Delete)
 	}
 
 	// Create HTTP server
@@ -150,10 +782,30 @@ func main() {
 		}
 	}()
 
-	// Wait for interrupt signal
+	// Wait for interrupt signal; SIGHUP reloads configuration
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	loaded := cfg
+
+waitLoop:
+	for {
+		select {
+		case <-reload:
+			// .env values take precedence on reload so edits to the file take effect
+			_ = godotenv.Overload()
+			reloaded, err := config.Load()
+			if err != nil {
+				zapLogger.Error("configuration reload failed", zap.Error(err))
+				continue
+			}
+			applyReload(zapLogger, loaded, reloaded, ruleEngine, thresholdCtl != nil)
+			loaded = reloaded
+		case <-quit:
+			break waitLoop
+		}
+	}
 
 	zapLogger.Info("shutting down server...")
 
@@ -165,5 +817,146 @@ func main() {
 		zapLogger.Error("server forced to shutdown", zap.Error(err))
 	}
 
+	// Finish running burst analyses so their results are stored
+	ingester.Close()
+
+	// Finish pending async analyses and their callback deliveries
+	if callbacks != nil {
+		callbacks.Close()
+	}
+
+	// Persist the cache so a restart does not trigger a burst of AI calls
+	if lruCache != nil && cfg.Processing.CacheSnapshotPath != "" {
+		if err := lruCache.SaveSnapshot(cfg.Processing.CacheSnapshotPath); err != nil {
+			zapLogger.Error("failed to save cache snapshot", zap.Error(err))
+		} else {
+			zapLogger.Info("cache snapshot saved", zap.Any("stats", lruCache.Stats()))
+		}
+	}
+
+	if err := captureRecorder.Close(); err != nil {
+		zapLogger.Error("failed to close capture file", zap.Error(err))
+	}
+
+	closePlugins(plugins, zapLogger)
+
+	if redisClient != nil {
+		redisClient.Close()
+	}
+
 	zapLogger.Info("server stopped")
 }
+
+// healthAIMode returns how the service answers analyses, for GET /health.
+func healthAIMode(cfg *config.Config) string {
+	switch {
+	case cfg.Processing.RulesOnly:
+		return handler.AIModeRulesOnly
+	case cfg.AI.MockMode:
+		return handler.AIModeMock
+	default:
+		return handler.AIModeLive
+	}
+}
+
+// healthAIProvider returns the provider and model reported by GET /health:
+// those of the analyze client, which admins may switch at runtime.
+func healthAIProvider(cfg *config.Config, switches []*ai.SwitchableClient) func() (string, string) {
+	return func() (string, string) {
+		if cfg.Plugins.AIClient != "" {
+			return "plugin", cfg.Plugins.AIClient
+		}
+		aiCfg := cfg.AI
+		if len(switches) > 0 {
+			aiCfg = switches[0].Config()
+		}
+		return string(aiCfg.Provider), aiCfg.Model
+	}
+}
+
+// newKnowledgeFinder creates a runbook finder over the configured
+// knowledge sources.
+func newKnowledgeFinder(cfg *config.KnowledgeConfig, logger *zap.Logger) (*knowledge.Finder, error) {
+	var sources []knowledge.Source
+	if cfg.MarkdownDir != "" {
+		markdown, err := knowledge.LoadMarkdown(cfg.MarkdownDir, cfg.MarkdownBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, markdown)
+		logger.Info("markdown runbooks loaded", zap.String("dir", cfg.MarkdownDir), zap.Int("runbooks", markdown.Len()))
+	}
+	httpClient := &http.Client{Timeout: cfg.Timeout}
+	if cfg.ConfluenceURL != "" {
+		sources = append(sources, knowledge.NewConfluenceSource(cfg.ConfluenceURL, cfg.ConfluenceEmail, cfg.ConfluenceToken, cfg.ConfluenceSpace, httpClient))
+		logger.Info("confluence runbooks enabled", zap.String("url", cfg.ConfluenceURL))
+	}
+	if cfg.NotionToken != "" {
+		sources = append(sources, knowledge.NewNotionSource(knowledge.DefaultNotionURL, cfg.NotionToken, cfg.NotionDatabaseID, httpClient))
+		logger.Info("notion runbooks enabled")
+	}
+	return knowledge.NewFinder(sources, cfg.MaxRunbooks, cfg.Timeout, cfg.CacheTTL, logger), nil
+}
+
+// newTicketTracker returns the configured issue tracker, or nil when tickets
+// are disabled.
+func newTicketTracker(cfg *config.TicketsConfig) tickets.Tracker {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	switch cfg.Tracker {
+	case "jira":
+		return tickets.NewJiraTracker(cfg.JiraURL, cfg.JiraEmail, cfg.JiraToken, cfg.JiraProject, cfg.JiraIssueType, httpClient)
+	case "github":
+		return tickets.NewGitHubTracker(cfg.GitHubURL, cfg.GitHubToken, cfg.GitHubRepo, httpClient)
+	default:
+		return nil
+	}
+}
+
+// newEscalationSinks returns the configured on-call sinks.
+func newEscalationSinks(cfg *config.EscalationConfig) []escalate.Sink {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	var sinks []escalate.Sink
+	if cfg.PagerDutyRoutingKey != "" {
+		sinks = append(sinks, escalate.NewPagerDutySink(cfg.PagerDutyURL, cfg.PagerDutyRoutingKey, httpClient))
+	}
+	if cfg.OpsgenieAPIKey != "" {
+		sinks = append(sinks, escalate.NewOpsgenieSink(cfg.OpsgenieURL, cfg.OpsgenieAPIKey, httpClient))
+	}
+	return sinks
+}
+
+// newAIClient creates the AI client for the configured provider. With several
+// base URLs it returns a Router over one client per endpoint, and the router
+// itself for metrics.
+func newAIClient(cfg *config.AIConfig, prompter ai.PromptBuilder, validator ai.ResponseValidator, logger *zap.Logger) (ai.Client, *ai.Router) {
+	if len(cfg.BaseURLs) == 0 {
+		return newProviderClient(cfg, prompter, validator, logger), nil
+	}
+	if len(cfg.BaseURLs) == 1 {
+		single := *cfg
+		single.BaseURL = cfg.BaseURLs[0]
+		return newProviderClient(&single, prompter, validator, logger), nil
+	}
+
+	endpoints := make([]ai.Endpoint, len(cfg.BaseURLs))
+	for i, baseURL := range cfg.BaseURLs {
+		endpointCfg := *cfg
+		endpointCfg.BaseURL = baseURL
+		endpoints[i] = ai.Endpoint{
+			URL:    baseURL,
+			Client: newProviderClient(&endpointCfg, prompter, validator, logger),
+		}
+	}
+	router := ai.NewRouter(endpoints, ai.RoutingStrategy(cfg.Routing), cfg.EndpointCooldown, logger)
+	return router, router
+}
+
+// newProviderClient creates the client for the configured provider and base URL.
+func newProviderClient(cfg *config.AIConfig, prompter ai.PromptBuilder, validator ai.ResponseValidator, logger *zap.Logger) ai.Client {
+	switch cfg.Provider {
+	case config.AIProviderGemini:
+		return ai.NewGeminiClient(cfg, prompter, validator, logger)
+	default:
+		return ai.NewOpenAIClient(cfg, prompter, validator, logger)
+	}
+}
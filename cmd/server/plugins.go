@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// startPlugins launches the configured plugins. Their standard error is
+// logged. On error, the plugins already started are closed.
+func startPlugins(cfg *config.PluginConfig, logger *zap.Logger) ([]*plugin.Host, error) {
+	var hosts []*plugin.Host
+	for _, path := range cfg.Paths {
+		host, err := plugin.Launch(plugin.Config{
+			Path:         path,
+			StartTimeout: cfg.StartTimeout,
+			Stderr:       zap.NewStdLog(logger.With(zap.String("plugin", path))).Writer(),
+		})
+		if err != nil {
+			closePlugins(hosts, logger)
+			return nil, err
+		}
+		hosts = append(hosts, host)
+		logger.Info("plugin started",
+			zap.String("plugin", host.Name()),
+			zap.String("path", path),
+			zap.Bool("ai_client", host.Client() != nil),
+		)
+	}
+	return hosts, nil
+}
+
+// pluginRules compiles the rules of every plugin.
+func pluginRules(hosts []*plugin.Host) ([]*rules.Rule, error) {
+	var ruleSet []*rules.Rule
+	for _, host := range hosts {
+		hostRules, err := host.Rules()
+		if err != nil {
+			return nil, err
+		}
+		ruleSet = append(ruleSet, hostRules...)
+	}
+	return ruleSet, nil
+}
+
+// pluginClient returns the AI client of the plugin named name.
+func pluginClient(hosts []*plugin.Host, name string) (ai.Client, error) {
+	for _, host := range hosts {
+		if host.Name() != name {
+			continue
+		}
+		if client := host.Client(); client != nil {
+			return client, nil
+		}
+		return nil, fmt.Errorf("%w: plugin %q provides no AI client", domain.ErrInvalidConfig, name)
+	}
+	return nil, fmt.Errorf("%w: AI_PLUGIN names unknown plugin %q", domain.ErrInvalidConfig, name)
+}
+
+// closePlugins stops the plugins.
+func closePlugins(hosts []*plugin.Host, logger *zap.Logger) {
+	for _, host := range hosts {
+		if err := host.Close(); err != nil {
+			logger.Warn("failed to stop plugin", zap.String("plugin", host.Name()), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/internal/service"
+	"github.com/ai-devops/internal/tenant"
+	"github.com/ai-devops/internal/usage"
+	"github.com/ai-devops/pkg/sanitizer"
+	"go.uber.org/zap"
+)
+
+// newTenantPolicies builds the analyzer overrides of tenants from their AI,
+// rules and sanitizer sections. newClient is nil in mock mode, where every
+// tenant shares the mock client.
+func newTenantPolicies(cfg *config.Config, tenants []*tenant.Tenant, newClient ai.ClientFactory, logger *zap.Logger) (map[string]service.TenantPolicy, error) {
+	policies := make(map[string]service.TenantPolicy, len(tenants))
+	for _, t := range tenants {
+		var policy service.TenantPolicy
+
+		if t.AI != nil && newClient != nil {
+			aiCfg, err := tenantAIConfig(cfg.AI, t.AI)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %q: %w", t.ID, err)
+			}
+			policy.AIClient = newClient(&aiCfg)
+			logger.Info("tenant AI configured",
+				zap.String("tenant", t.ID),
+				zap.String("provider", string(aiCfg.Provider)),
+				zap.String("model", aiCfg.Model),
+			)
+		}
+
+		if t.Rules != nil {
+			enabled, disabled := cfg.Processing.EnabledRuleCategories, cfg.Processing.DisabledRuleCategories
+			if t.Rules.EnabledCategories != nil || t.Rules.DisabledCategories != nil {
+				enabled, disabled = t.Rules.EnabledCategories, t.Rules.DisabledCategories
+			}
+			ruleSet, err := rules.FilterCategories(rules.DefaultRules(), enabled, disabled)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %q: %w", t.ID, err)
+			}
+			threshold := cfg.Processing.RuleConfidenceThreshold
+			if t.Rules.ConfidenceThreshold > 0 {
+				threshold = t.Rules.ConfidenceThreshold
+			}
+			policy.RuleEngine = rules.NewEngine(ruleSet, threshold, logger)
+		}
+
+		if t.Sanitizer != nil {
+			logSanitizer, err := sanitizer.NewFromConfig(cfg.Processing.MaxLogSize, t.Sanitizer)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %q: %w", t.ID, err)
+			}
+			policy.Sanitizer = withSanitizerOptions(logSanitizer, &cfg.Processing)
+		}
+
+		if policy.AIClient != nil || policy.RuleEngine != nil || policy.Sanitizer != nil {
+			policies[t.ID] = policy
+		}
+	}
+	return policies, nil
+}
+
+// tenantAIConfig returns the service's AI settings with a tenant's
+// overrides. A tenant switching provider starts from that provider's
+// defaults, as the admin AI config endpoint does.
+func tenantAIConfig(base config.AIConfig, override *tenant.AIConfig) (config.AIConfig, error) {
+	aiCfg := base
+	if override.Provider != "" {
+		aiCfg = base.ForProvider(config.AIProvider(override.Provider))
+	}
+	if override.Model != "" {
+		aiCfg.Model = override.Model
+	}
+	if override.BaseURL != "" {
+		aiCfg.BaseURL = override.BaseURL
+		aiCfg.BaseURLs = nil
+	}
+	if override.APIKeyEnv != "" {
+		aiCfg.APIKey = os.Getenv(override.APIKeyEnv)
+		if aiCfg.APIKey == "" {
+			return aiCfg, fmt.Errorf("%w: %s is not set", domain.ErrInvalidConfig, override.APIKeyEnv)
+		}
+	}
+	return aiCfg, aiCfg.ValidateModel()
+}
+
+// withSanitizerOptions applies the preprocessing, entropy detection and
+// masking settings shared by every sanitizer of the service.
+func withSanitizerOptions(s *sanitizer.Sanitizer, cfg *config.ProcessingConfig) *sanitizer.Sanitizer {
+	if !cfg.PreprocessLogs {
+		s = s.WithoutPreprocessing()
+	}
+	if cfg.EntropyDetection {
+		s = s.WithEntropyDetection(sanitizer.EntropyConfig{
+			MinLength:    cfg.EntropyMinLength,
+			Threshold:    cfg.EntropyThreshold,
+			HexThreshold: cfg.EntropyHexThreshold,
+		})
+	}
+	s = s.WithMaskStrategy(sanitizer.MaskStrategy(cfg.MaskStrategy))
+	if cfg.MaskHashKey != "" {
+		s = s.WithHashKey([]byte(cfg.MaskHashKey))
+	}
+	return s
+}
+
+// newTenantQuotas returns the quotas of tenants by tenant ID.
+func newTenantQuotas(tenants []*tenant.Tenant) map[string]usage.Quota {
+	quotas := make(map[string]usage.Quota)
+	for _, t := range tenants {
+		if t.Quota == nil {
+			continue
+		}
+		quotas[t.ID] = usage.Quota{
+			MonthlyAnalyses: t.Quota.MonthlyAnalyses,
+			MonthlyTokens:   t.Quota.MonthlyTokens,
+			Block:           t.Quota.OnExhausted == tenant.QuotaBlock,
+		}
+	}
+	return quotas
+}
@@ -0,0 +1,35 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Activity records when the AI last answered successfully, for health
+// reporting. It is safe for concurrent use; a nil *Activity records
+// nothing.
+type Activity struct {
+	lastSuccess atomic.Int64 // Unix nanoseconds
+}
+
+// RecordSuccess records a successful AI call now.
+func (a *Activity) RecordSuccess() {
+	if a == nil {
+		return
+	}
+	a.lastSuccess.Store(time.Now().UnixNano())
+}
+
+// LastSuccess returns the time of the last successful AI call, or the zero
+// time if there was none.
+func (a *Activity) LastSuccess() time.Time {
+	if a == nil {
+		return time.Time{}
+	}
+	nanos := a.lastSuccess.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,25 @@
+// Package ai provides unit tests for AI activity tracking.
+package ai
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActivity(t *testing.T) {
+	var nilActivity *Activity
+	nilActivity.RecordSuccess()
+	if !nilActivity.LastSuccess().IsZero() {
+		t.Error("nil Activity should record nothing")
+	}
+
+	activity := &Activity{}
+	if !activity.LastSuccess().IsZero() {
+		t.Error("expected no success before one is recorded")
+	}
+	before := time.Now()
+	activity.RecordSuccess()
+	if last := activity.LastSuccess(); last.Before(before) || last.After(time.Now()) {
+		t.Errorf("LastSuccess() = %v, want the time of RecordSuccess", last)
+	}
+}
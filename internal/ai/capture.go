@@ -0,0 +1,129 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+type captureKey struct{}
+
+// Capture records the full prompts and raw responses of the AI requests
+// made with a context, for debugging rejected responses. A nil Capture
+// records nothing. It is safe for concurrent use.
+type Capture struct {
+	mu    sync.Mutex
+	calls []*CaptureCall
+}
+
+// CaptureCall is one analysis request to a model: its prompts and the raw
+// answer of every attempt.
+type CaptureCall struct {
+	Provider     string           `json:"provider"`
+	Model        string           `json:"model"`
+	SystemPrompt string           `json:"system_prompt"`
+	UserPrompt   string           `json:"user_prompt"`
+	Attempts     []CaptureAttempt `json:"attempts"`
+}
+
+// CaptureAttempt is one provider response.
+type CaptureAttempt struct {
+	Time time.Time `json:"time"`
+
+	// Response is the answer text before JSON extraction; empty when the
+	// request failed.
+	Response string `json:"response"`
+
+	// Error is why the attempt failed, and Rejected whether the answer
+	// could not be parsed or failed validation.
+	Error    string `json:"error,omitempty"`
+	Rejected bool   `json:"rejected,omitempty"`
+}
+
+// WithCapture returns a context whose AI requests are recorded in capture.
+func WithCapture(ctx context.Context, capture *Capture) context.Context {
+	return context.WithValue(ctx, captureKey{}, capture)
+}
+
+// CaptureFromContext returns the Capture carried by ctx, or nil.
+func CaptureFromContext(ctx context.Context) *Capture {
+	capture, _ := ctx.Value(captureKey{}).(*Capture)
+	return capture
+}
+
+// recordCall starts recording a request with the prompts. It returns nil
+// when c is nil.
+func (c *Capture) recordCall(provider, model, systemPrompt, userPrompt string) *captureCall {
+	if c == nil {
+		return nil
+	}
+	call := &CaptureCall{
+		Provider:     provider,
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, call)
+	return &captureCall{capture: c, call: call}
+}
+
+// Calls returns copies of the recorded requests in order.
+func (c *Capture) Calls() []CaptureCall {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	calls := make([]CaptureCall, len(c.calls))
+	for i, call := range c.calls {
+		calls[i] = *call
+		calls[i].Attempts = append([]CaptureAttempt(nil), call.Attempts...)
+	}
+	return calls
+}
+
+// Rejected reports whether any recorded response was rejected.
+func (c *Capture) Rejected() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, call := range c.calls {
+		for _, attempt := range call.Attempts {
+			if attempt.Rejected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// captureCall records the attempts of one request.
+type captureCall struct {
+	capture *Capture
+	call    *CaptureCall
+}
+
+// recordAttempt records a provider response and the error it led to. It
+// does nothing when c is nil.
+func (c *captureCall) recordAttempt(response string, err error) {
+	if c == nil {
+		return
+	}
+	attempt := CaptureAttempt{Time: time.Now(), Response: response}
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.Rejected = errors.Is(err, domain.ErrInvalidAIResponse)
+	}
+
+	c.capture.mu.Lock()
+	defer c.capture.mu.Unlock()
+	c.call.Attempts = append(c.call.Attempts, attempt)
+}
@@ -0,0 +1,60 @@
+// Package ai provides unit tests for capturing prompts and raw responses.
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+	"go.uber.org/zap"
+)
+
+func TestOpenAIClient_CapturesRejectedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"I cannot analyze this log."},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	prompter, _ := NewDefaultPromptBuilder()
+	client := NewOpenAIClient(&config.AIConfig{
+		APIKey:    "test-api-key",
+		BaseURL:   server.URL,
+		Model:     "gpt-4o-mini",
+		Timeout:   5 * time.Second,
+		MaxTokens: 512,
+	}, prompter, NewDefaultValidator(), zap.NewNop())
+
+	capture := &Capture{}
+	if _, err := client.Analyze(WithCapture(context.Background(), capture), "disk full on /var"); err == nil {
+		t.Fatal("expected the unparsable response to be rejected")
+	}
+
+	calls := capture.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("calls = %d, want 1", len(calls))
+	}
+	if calls[0].Model != "gpt-4o-mini" || calls[0].SystemPrompt == "" {
+		t.Errorf("call = %+v, want the model and system prompt", calls[0])
+	}
+	if len(calls[0].Attempts) == 0 || calls[0].Attempts[0].Response != "I cannot analyze this log." {
+		t.Errorf("attempts = %+v, want the raw response", calls[0].Attempts)
+	}
+	if !capture.Rejected() {
+		t.Error("Rejected() = false, want true")
+	}
+}
+
+func TestCapture_Nil(t *testing.T) {
+	var capture *Capture
+	capture.recordCall("openai", "gpt-4o-mini", "system", "user").recordAttempt("{}", nil)
+	if capture.Calls() != nil || capture.Rejected() {
+		t.Error("nil Capture should record nothing")
+	}
+	if CaptureFromContext(context.Background()) != nil {
+		t.Error("expected no capture in an empty context")
+	}
+}
@@ -0,0 +1,71 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// chatSystemPrompt sets up follow-up questions about analyzed logs.
+const chatSystemPrompt = `You are an expert DevOps engineer helping a developer troubleshoot a CI/CD or application failure step by step.
+The logs analyzed so far and their analyses follow. Answer the developer's latest message using them and the conversation so far.
+Be concise and specific: name the file, command, package or setting to change. Say so when the logs do not show enough to be sure, and which output would settle it.
+Answer in plain text or Markdown, not JSON. Secrets in the logs are masked; never ask for them.`
+
+// maxChatLogBytes caps each log in the chat prompt. Longer logs keep their
+// end, where failures usually are.
+const maxChatLogBytes = 8000
+
+// buildChatSystemPrompt builds the system prompt of a conversation turn:
+// the instructions followed by the conversation's logs and analyses.
+func buildChatSystemPrompt(ctx context.Context, conversation *domain.Conversation) string {
+	var b strings.Builder
+	b.WriteString(chatSystemPrompt)
+
+	for i, log := range conversation.Logs {
+		fmt.Fprintf(&b, "\n\n--- Log %d ---\n%s", i+1, tailBytes(log, maxChatLogBytes))
+		if i >= len(conversation.Results) || conversation.Results[i] == nil {
+			continue
+		}
+		if analysis, err := json.Marshal(conversation.Results[i]); err == nil {
+			fmt.Fprintf(&b, "\n\n--- Analysis of log %d ---\n%s", i+1, analysis)
+		}
+	}
+
+	if language := LanguageFromContext(ctx); language != "" {
+		fmt.Fprintf(&b, "\n\nAnswer in %s.", language)
+	}
+	return b.String()
+}
+
+// chatMessages returns the conversation's messages followed by message.
+func chatMessages(conversation *domain.Conversation, message string) []domain.ChatMessage {
+	messages := make([]domain.ChatMessage, 0, len(conversation.Messages)+1)
+	messages = append(messages, conversation.Messages...)
+	return append(messages, domain.ChatMessage{Role: domain.ChatRoleUser, Content: message})
+}
+
+// chatBytes returns the total size of the messages' content.
+func chatBytes(messages []domain.ChatMessage) int {
+	n := 0
+	for _, message := range messages {
+		n += len(message.Content)
+	}
+	return n
+}
+
+// tailBytes returns the last lines of s that fit in maxBytes.
+func tailBytes(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	tail := s[len(s)-maxBytes:]
+	if i := strings.IndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return "[... earlier lines omitted ...]\n" + tail
+}
@@ -0,0 +1,128 @@
+// Package ai provides unit tests for conversation turns.
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// newTestConversation returns a conversation with one analyzed log and a
+// first exchange.
+func newTestConversation() *domain.Conversation {
+	conversation := &domain.Conversation{}
+	conversation.AddAnalysis("npm ERR! code ERESOLVE\nnpm ERR! peer react@\"^17\" from legacy-ui@2.1.0",
+		&domain.AnalysisResult{ErrorType: "dependency_conflict", Severity: domain.SeverityMedium, RootCause: "legacy-ui needs react 17"})
+	conversation.AddMessage(domain.ChatRoleUser, "Can I just use --legacy-peer-deps?")
+	conversation.AddMessage(domain.ChatRoleAssistant, "Yes, as a stopgap.")
+	return conversation
+}
+
+func TestProviderClient_Chat(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider config.AIProvider
+		response string
+		// messages extracts the roles and texts of the request's turns.
+		messages func(body []byte) (system string, roles, texts []string)
+	}{
+		{
+			name:     "openai",
+			provider: config.AIProviderOpenAI,
+			response: `{"choices":[{"message":{"content":"Upgrade legacy-ui to 3.x."},"finish_reason":"stop"}],"usage":{"total_tokens":42}}`,
+			messages: func(body []byte) (string, []string, []string) {
+				var req chatRequest
+				_ = json.Unmarshal(body, &req)
+				var roles, texts []string
+				for _, m := range req.Messages[1:] {
+					roles = append(roles, m.Role)
+					texts = append(texts, m.Content)
+				}
+				return req.Messages[0].Content, roles, texts
+			},
+		},
+		{
+			name:     "gemini",
+			provider: config.AIProviderGemini,
+			response: `{"candidates":[{"content":{"role":"model","parts":[{"text":"Upgrade legacy-ui to 3.x."}]},"finishReason":"STOP"}],"usageMetadata":{"totalTokenCount":42}}`,
+			messages: func(body []byte) (string, []string, []string) {
+				var req geminiRequest
+				_ = json.Unmarshal(body, &req)
+				var roles, texts []string
+				for _, c := range req.Contents {
+					roles = append(roles, c.Role)
+					texts = append(texts, c.Parts[0].Text)
+				}
+				return req.SystemInstruction.Parts[0].Text, roles, texts
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			cfg := &config.AIConfig{APIKey: "test-api-key", BaseURL: server.URL, Model: "test-model", MaxTokens: 512, Timeout: 5 * time.Second}
+			var client Client = NewOpenAIClient(cfg, nil, nil, zap.NewNop())
+			if tt.provider == config.AIProviderGemini {
+				client = NewGeminiClient(cfg, nil, nil, zap.NewNop())
+			}
+
+			conversation := newTestConversation()
+			ctx := WithLanguage(context.Background(), "Vietnamese")
+			reply, err := client.Chat(ctx, conversation, "What is the proper fix?")
+			if err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			if reply.Content != "Upgrade legacy-ui to 3.x." || reply.Usage == nil || reply.Usage.TotalTokens != 42 || reply.Usage.Model != "test-model" {
+				t.Errorf("Chat() = %+v, want the answer and its usage", reply)
+			}
+			if len(conversation.Messages) != 2 {
+				t.Errorf("Chat() modified the conversation: %d messages", len(conversation.Messages))
+			}
+
+			system, roles, texts := tt.messages(body)
+			for _, want := range []string{"npm ERR! code ERESOLVE", "dependency_conflict", "Answer in Vietnamese."} {
+				if !strings.Contains(system, want) {
+					t.Errorf("system prompt does not contain %q", want)
+				}
+			}
+			assistant := "assistant"
+			if tt.provider == config.AIProviderGemini {
+				assistant = "model"
+			}
+			wantRoles := []string{"user", assistant, "user"}
+			if strings.Join(roles, ",") != strings.Join(wantRoles, ",") {
+				t.Errorf("roles = %v, want %v", roles, wantRoles)
+			}
+			if len(texts) != 3 || texts[2] != "What is the proper fix?" {
+				t.Errorf("texts = %q, want the new message last", texts)
+			}
+		})
+	}
+}
+
+func TestTailBytes(t *testing.T) {
+	log := "line 1\nline 2\nline 3\n"
+	if got := tailBytes(log, 100); got != log {
+		t.Errorf("tailBytes(short) = %q, want it unchanged", got)
+	}
+	if got := tailBytes(log, 10); got != "[... earlier lines omitted ...]\nline 3\n" {
+		t.Errorf("tailBytes(long) = %q, want the last whole lines", got)
+	}
+}
@@ -2,13 +2,10 @@
 package ai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"time"
 
 	"github.com/ai-devops/internal/config"
 	"github.com/ai-devops/internal/domain"
@@ -17,11 +14,8 @@ import (
 
 // OpenAIClient implements the Client interface using OpenAI-compatible API.
 type OpenAIClient struct {
-	config       *config.AIConfig
-	httpClient   *http.Client
-	prompter     PromptBuilder
-	validator    ResponseValidator
-	logger       *zap.Logger
+	*providerClient
+	config *config.AIConfig
 }
 
 // OpenAI API request/response structures
@@ -30,6 +24,8 @@ type chatRequest struct {
 	Messages    []chatMessage `json:"messages"`
 	MaxTokens   int           `json:"max_tokens"`
 	Temperature float64       `json:"temperature"`
+
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
 }
 
 type chatMessage struct {
@@ -45,6 +41,11 @@ type chatResponse struct {
 		} `json:"message"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage,omitempty"`
 	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
@@ -53,192 +54,100 @@ type chatResponse struct {
 
 // NewOpenAIClient creates a new OpenAI-compatible AI client.
 func NewOpenAIClient(cfg *config.AIConfig, prompter PromptBuilder, validator ResponseValidator, logger *zap.Logger) *OpenAIClient {
-	return &OpenAIClient{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: cfg.Timeout,
-		},
-		prompter:  prompter,
-		validator: validator,
-		logger:    logger.Named("ai_client"),
-	}
+	c := &OpenAIClient{config: cfg}
+	c.providerClient = newProviderClient(config.AIProviderOpenAI, cfg, c, prompter, validator, logger.Named("ai_client"))
+	return c
 }
 
-// Analyze sends a log to the AI service and returns a structured analysis.
-func (c *OpenAIClient) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
-	startTime := time.Now()
-	c.logger.Debug("starting AI analysis", zap.Int("log_length", len(log)))
-
-	// Build the request
+// encodeRequest implements providerTransport.
+func (c *OpenAIClient) encodeRequest(ctx context.Context, systemPrompt, userPrompt string) (*providerRequest, error) {
+	detail := DetailFromContext(ctx)
 	reqBody := chatRequest{
 		Model: c.config.Model,
 		Messages: []chatMessage{
-			{Role: "system", Content: c.prompter.BuildSystemPrompt()},
-			{Role: "user", Content: c.prompter.BuildUserPrompt(log)},
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
 		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: 0.1, // Low temperature for deterministic output
-	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, domain.WrapError("marshal_request", err, false)
+		MaxTokens:   detailMaxTokens(detail, c.config.MaxTokens),
+		Temperature: c.config.Temperature,
 	}
-
-	// Create HTTP request with context
-	url := fmt.Sprintf("%s/chat/completions", c.config.BaseURL)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
-	if err != nil {
-		return nil, domain.WrapError("create_request", err, false)
+	if c.config.StructuredOutput {
+		reqBody.ResponseFormat = newOpenAIResponseFormat(detail)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
-
-	// Execute request with retry logic
-	var result *domain.AnalysisResult
-	var lastErr error
-
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff
-			backoff := time.Duration(attempt*attempt) * time.Second
-			c.logger.Debug("retrying AI request",
-				zap.Int("attempt", attempt),
-				zap.Duration("backoff", backoff),
-			)
-			select {
-			case <-ctx.Done():
-				return nil, domain.WrapError("context_cancelled", ctx.Err(), false)
-			case <-time.After(backoff):
-			}
-		}
-
-		result, lastErr = c.executeRequest(ctx, req)
-		if lastErr == nil {
-			break
-		}
+	return c.newRequest(reqBody)
+}
 
-		// Check if error is retryable
-		if !domain.IsRetryable(lastErr) {
-			break
-		}
+// encodeChat implements providerTransport.
+func (c *OpenAIClient) encodeChat(ctx context.Context, systemPrompt string, messages []domain.ChatMessage) (*providerRequest, error) {
+	reqBody := chatRequest{
+		Model:       c.config.Model,
+		Messages:    []chatMessage{{Role: "system", Content: systemPrompt}},
+		MaxTokens:   c.config.MaxTokens,
+		Temperature: c.config.Temperature,
 	}
-
-	if lastErr != nil {
-		return nil, lastErr
+	for _, message := range messages {
+		reqBody.Messages = append(reqBody.Messages, chatMessage{Role: string(message.Role), Content: message.Content})
 	}
-
-	c.logger.Debug("AI analysis completed",
-		zap.Duration("duration", time.Since(startTime)),
-		zap.String("error_type", result.ErrorType),
-	)
-
-	return result, nil
+	return c.newRequest(reqBody)
 }
 
-// executeRequest performs a single HTTP request to the AI service.
-func (c *OpenAIClient) executeRequest(ctx context.Context, req *http.Request) (*domain.AnalysisResult, error) {
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		if ctx.Err() != nil {
-			return nil, domain.WrapError("ai_timeout", domain.ErrAITimeout, true)
-		}
-		return nil, domain.WrapError("http_request", err, true)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+// newRequest encodes an authenticated chat completions request.
+func (c *OpenAIClient) newRequest(reqBody chatRequest) (*providerRequest, error) {
+	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, domain.WrapError("read_response", err, true)
-	}
-
-	// Handle HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return nil, domain.WrapError("rate_limit", domain.ErrRateLimited, true)
-		}
-		if resp.StatusCode >= 500 {
-			return nil, domain.WrapError("ai_unavailable", domain.ErrAIUnavailable, true)
-		}
-		return nil, domain.WrapError("ai_error",
-			fmt.Errorf("AI API returned status %d: %s", resp.StatusCode, string(body)), false)
+		return nil, err
 	}
+	return &providerRequest{
+		URL:    fmt.Sprintf("%s/chat/completions", c.config.BaseURL),
+		Header: http.Header{"Authorization": {"Bearer " + c.config.APIKey}},
+		Body:   jsonBody,
+	}, nil
+}
 
-	// Parse the response
+// decodeResponse implements providerTransport.
+func (c *OpenAIClient) decodeResponse(statusCode int, body []byte) (string, *domain.TokenUsage, error) {
 	var chatResp chatResponse
 	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return nil, domain.WrapError("parse_response", err, false)
+		return "", nil, domain.WrapError("parse_response", err, false)
 	}
 
 	if chatResp.Error != nil {
-		return nil, domain.WrapError("ai_api_error",
-			fmt.Errorf("%s: %s", chatResp.Error.Type, chatResp.Error.Message), false)
+		return "", nil, classifyOpenAIError(statusCode, body)
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return nil, domain.WrapError("empty_response", domain.ErrInvalidAIResponse, false)
+		return "", nil, domain.WrapError("empty_response", domain.ErrInvalidAIResponse, false)
 	}
 
-	// Extract and parse the JSON content from the response
-	content := chatResp.Choices[0].Message.Content
-	result, err := c.parseAnalysisResult(content)
-	if err != nil {
-		return nil, err
+	if chatResp.Choices[0].FinishReason == "content_filter" {
+		return "", nil, contentFiltered("openai", "content_filter")
 	}
 
-	// Validate the result
-	if err := c.validator.Validate(result); err != nil {
-		return nil, err
+	var usage *domain.TokenUsage
+	if chatResp.Usage != nil {
+		usage = &domain.TokenUsage{
+			PromptTokens:     chatResp.Usage.PromptTokens,
+			CompletionTokens: chatResp.Usage.CompletionTokens,
+			TotalTokens:      chatResp.Usage.TotalTokens,
+		}
 	}
-
-	return result, nil
+	return chatResp.Choices[0].Message.Content, usage, nil
 }
 
-// parseAnalysisResult extracts the AnalysisResult from the AI response content.
-func (c *OpenAIClient) parseAnalysisResult(content string) (*domain.AnalysisResult, error) {
-	var result domain.AnalysisResult
-
-	// Try to find JSON in the content (AI might include markdown code blocks)
-	jsonContent := extractJSON(content)
-	if jsonContent == "" {
-		c.logger.Warn("could not extract JSON from AI response",
-			zap.String("content_preview", truncate(content, 200)),
-		)
-		return nil, domain.WrapError("extract_json", domain.ErrInvalidAIResponse, false)
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		c.logger.Warn("failed to unmarshal AI response",
-			zap.Error(err),
-			zap.String("json_content", truncate(jsonContent, 200)),
-		)
-		return nil, domain.WrapError("unmarshal_result", domain.ErrInvalidAIResponse, false)
-	}
-
-	return &result, nil
+// decodeError implements providerTransport.
+func (c *OpenAIClient) decodeError(statusCode int, body []byte) error {
+	return classifyOpenAIError(statusCode, body)
 }
 
-// HealthCheck verifies the AI service is reachable.
-func (c *OpenAIClient) HealthCheck(ctx context.Context) error {
-	url := fmt.Sprintf("%s/models", c.config.BaseURL)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// healthRequest implements providerTransport.
+func (c *OpenAIClient) healthRequest(ctx context.Context) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/models", c.config.BaseURL), nil)
 	if err != nil {
-		return err
-	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return domain.WrapError("health_check", domain.ErrAIUnavailable, true)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return domain.WrapError("health_check", domain.ErrAIUnavailable, true)
+		return nil, err
 	}
-
-	return nil
+	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	return req, nil
 }
 
 // Helper functions
@@ -0,0 +1,227 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Consensus fields compared between two analyses.
+const (
+	consensusErrorType = "error_type"
+	consensusSeverity  = "severity"
+	consensusRootCause = "root_cause"
+)
+
+// minRootCauseOverlap is the fraction of significant words two root causes
+// must share to be considered the same explanation.
+const minRootCauseOverlap = 0.3
+
+// ConsensusClient wraps two clients and cross-checks the primary model's
+// analysis with the secondary's. Fields the models agree on are kept,
+// disagreements are reported in the result's Consensus.
+type ConsensusClient struct {
+	primary          Client
+	secondary        Client
+	models           [2]string
+	highSeverityOnly bool
+	logger           *zap.Logger
+}
+
+// NewConsensusClient creates a consensus client. With highSeverityOnly the
+// secondary model is only asked after the primary rates a failure High;
+// otherwise both are queried in parallel.
+func NewConsensusClient(primary Client, primaryModel string, secondary Client, secondaryModel string, highSeverityOnly bool, logger *zap.Logger) *ConsensusClient {
+	return &ConsensusClient{
+		primary:          primary,
+		secondary:        secondary,
+		models:           [2]string{primaryModel, secondaryModel},
+		highSeverityOnly: highSeverityOnly,
+		logger:           logger.Named("consensus"),
+	}
+}
+
+// secondOpinion is the outcome of the secondary model's analysis.
+type secondOpinion struct {
+	result *domain.AnalysisResult
+	err    error
+}
+
+// Analyze implements Client.
+func (c *ConsensusClient) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
+	if c.highSeverityOnly {
+		result, err := c.primary.Analyze(ctx, log)
+		if err != nil || result.Severity != domain.SeverityHigh {
+			return result, err
+		}
+		second, err := c.secondary.Analyze(ctx, log)
+		return c.combine(result, nil, second, err), nil
+	}
+
+	opinion := make(chan secondOpinion, 1)
+	go func() {
+		result, err := c.secondary.Analyze(ctx, log)
+		opinion <- secondOpinion{result: result, err: err}
+	}()
+
+	result, err := c.primary.Analyze(ctx, log)
+	second := <-opinion
+	if err != nil && second.err != nil {
+		return nil, err
+	}
+	return c.combine(result, err, second.result, second.err), nil
+}
+
+// combine merges the analyses of which at least one succeeded.
+func (c *ConsensusClient) combine(primary *domain.AnalysisResult, primaryErr error, secondary *domain.AnalysisResult, secondaryErr error) *domain.AnalysisResult {
+	if primaryErr != nil {
+		c.logger.Warn("primary model failed, using the second opinion unverified",
+			zap.String("model", c.models[0]),
+			zap.Error(primaryErr),
+		)
+		secondary.Consensus = &domain.Consensus{Models: []string{c.models[1]}}
+		return secondary
+	}
+	if secondaryErr != nil {
+		if !errors.Is(secondaryErr, context.Canceled) {
+			c.logger.Warn("second opinion failed, returning the primary analysis unverified",
+				zap.String("model", c.models[1]),
+				zap.Error(secondaryErr),
+			)
+		}
+		primary.Consensus = &domain.Consensus{Models: []string{c.models[0]}}
+		return primary
+	}
+
+	merged := mergeConsensus(primary, secondary)
+	merged.Consensus.Models = c.models[:]
+	if merged.Consensus.Agreement < 1 {
+		c.logger.Info("models disagree",
+			zap.Float64("agreement", merged.Consensus.Agreement),
+			zap.String("primary_error_type", primary.ErrorType),
+			zap.String("secondary_error_type", secondary.ErrorType),
+		)
+	}
+	return merged
+}
+
+// mergeConsensus keeps the primary analysis, adds the secondary's distinct
+// suggestions and records which fields the two agree on.
+func mergeConsensus(primary, secondary *domain.AnalysisResult) *domain.AnalysisResult {
+	merged := *primary
+	merged.SuggestedActions = unionStrings(primary.SuggestedActions, secondary.SuggestedActions)
+	merged.Commands = domain.NormalizeCommands(append(append([]domain.Command(nil), primary.Commands...), secondary.Commands...))
+	merged.PreventionTips = unionStrings(primary.PreventionTips, secondary.PreventionTips)
+	merged.Usage = addUsage(primary.Usage, secondary.Usage)
+
+	consensus := &domain.Consensus{Verified: true}
+	compare := func(field, a, b string, agree bool) {
+		if agree {
+			consensus.Agreed = append(consensus.Agreed, field)
+			return
+		}
+		consensus.Disagreements = append(consensus.Disagreements, domain.ConsensusDisagreement{
+			Field: field, Primary: a, Secondary: b,
+		})
+	}
+	compare(consensusErrorType, primary.ErrorType, secondary.ErrorType,
+		strings.EqualFold(primary.ErrorType, secondary.ErrorType))
+	compare(consensusSeverity, string(primary.Severity), string(secondary.Severity),
+		primary.Severity == secondary.Severity)
+	compare(consensusRootCause, primary.RootCause, secondary.RootCause,
+		wordOverlap(primary.RootCause, secondary.RootCause) >= minRootCauseOverlap)
+
+	consensus.Agreement = float64(len(consensus.Agreed)) / float64(len(consensus.Agreed)+len(consensus.Disagreements))
+	merged.Consensus = consensus
+	return &merged
+}
+
+// unionStrings returns a followed by the entries of b not already in a,
+// ignoring case and surrounding whitespace.
+func unionStrings(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	out := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, s := range list {
+			key := strings.ToLower(strings.TrimSpace(s))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// wordOverlap returns the Jaccard index of the words longer than three
+// letters of a and b.
+func wordOverlap(a, b string) float64 {
+	words := func(s string) map[string]bool {
+		set := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+		}) {
+			if len(w) > 3 {
+				set[w] = true
+			}
+		}
+		return set
+	}
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wa)+len(wb)-shared)
+}
+
+// addUsage sums the token usage of two calls, keeping each call so the
+// meter prices it at its own model.
+func addUsage(a, b *domain.TokenUsage) *domain.TokenUsage {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	sum := &domain.TokenUsage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+		Calls:            append(append([]domain.TokenUsage(nil), usageCalls(a)...), usageCalls(b)...),
+	}
+	if a.Model == b.Model {
+		sum.Model = a.Model
+	}
+	return sum
+}
+
+// usageCalls returns the calls u sums, or u itself.
+func usageCalls(u *domain.TokenUsage) []domain.TokenUsage {
+	if len(u.Calls) > 0 {
+		return u.Calls
+	}
+	return []domain.TokenUsage{*u}
+}
+
+// Chat implements Client. Follow-up answers are free text that cannot be
+// cross-checked field by field, so only the primary model is asked.
+func (c *ConsensusClient) Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error) {
+	return c.primary.Chat(ctx, conversation, message)
+}
+
+// HealthCheck implements Client. The primary model must be healthy; an
+// unavailable second opinion only degrades results to unverified.
+func (c *ConsensusClient) HealthCheck(ctx context.Context) error {
+	return c.primary.HealthCheck(ctx)
+}
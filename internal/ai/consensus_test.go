@@ -0,0 +1,180 @@
+// Package ai provides unit tests for multi-model consensus.
+package ai
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// opinionClient returns a copy of a fixed result or error.
+type opinionClient struct {
+	result *domain.AnalysisResult
+	err    error
+	calls  atomic.Int32
+}
+
+func (c *opinionClient) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
+	c.calls.Add(1)
+	if c.err != nil {
+		return nil, c.err
+	}
+	result := *c.result
+	return &result, nil
+}
+
+func (c *opinionClient) Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error) {
+	c.calls.Add(1)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &domain.ChatReply{Content: c.result.RootCause}, nil
+}
+
+func (c *opinionClient) HealthCheck(ctx context.Context) error { return c.err }
+
+func TestConsensusClient_Analyze(t *testing.T) {
+	dockerAuth := &domain.AnalysisResult{
+		ErrorType:        "docker_auth_error",
+		Severity:         domain.SeverityHigh,
+		RootCause:        "Docker registry authentication failed because the credentials expired.",
+		SuggestedActions: []string{"Rotate the registry token", "Re-run the job"},
+		Usage:            &domain.TokenUsage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120},
+	}
+	sameDiagnosis := &domain.AnalysisResult{
+		ErrorType:        "DOCKER_AUTH_ERROR",
+		Severity:         domain.SeverityHigh,
+		RootCause:        "The registry credentials expired, so Docker authentication failed.",
+		SuggestedActions: []string{"re-run the job", "Check the registry status page"},
+		Usage:            &domain.TokenUsage{PromptTokens: 90, CompletionTokens: 30, TotalTokens: 120},
+	}
+	network := &domain.AnalysisResult{
+		ErrorType: "network_timeout",
+		Severity:  domain.SeverityMedium,
+		RootCause: "A network timeout while pulling the base image.",
+	}
+	unavailable := domain.WrapError("ai_request", domain.ErrAIUnavailable, true)
+
+	tests := []struct {
+		name             string
+		primary          *opinionClient
+		secondary        *opinionClient
+		highSeverityOnly bool
+		wantErr          bool
+		wantConsensus    *domain.Consensus
+		wantActions      []string
+		wantTokens       int
+		wantSecondCalls  int32
+	}{
+		{
+			name:      "models agree",
+			primary:   &opinionClient{result: dockerAuth},
+			secondary: &opinionClient{result: sameDiagnosis},
+			wantConsensus: &domain.Consensus{
+				Models:    []string{"model-a", "model-b"},
+				Verified:  true,
+				Agreement: 1,
+				Agreed:    []string{"error_type", "severity", "root_cause"},
+			},
+			wantActions:     []string{"Rotate the registry token", "Re-run the job", "Check the registry status page"},
+			wantTokens:      240,
+			wantSecondCalls: 1,
+		},
+		{
+			name:      "models disagree",
+			primary:   &opinionClient{result: dockerAuth},
+			secondary: &opinionClient{result: network},
+			wantConsensus: &domain.Consensus{
+				Models:   []string{"model-a", "model-b"},
+				Verified: true,
+				Disagreements: []domain.ConsensusDisagreement{
+					{Field: "error_type", Primary: "docker_auth_error", Secondary: "network_timeout"},
+					{Field: "severity", Primary: "High", Secondary: "Medium"},
+					{Field: "root_cause", Primary: dockerAuth.RootCause, Secondary: network.RootCause},
+				},
+			},
+			wantActions:     dockerAuth.SuggestedActions,
+			wantTokens:      120,
+			wantSecondCalls: 1,
+		},
+		{
+			name:            "second opinion unavailable",
+			primary:         &opinionClient{result: dockerAuth},
+			secondary:       &opinionClient{err: unavailable},
+			wantConsensus:   &domain.Consensus{Models: []string{"model-a"}},
+			wantActions:     dockerAuth.SuggestedActions,
+			wantTokens:      120,
+			wantSecondCalls: 1,
+		},
+		{
+			name:            "primary unavailable",
+			primary:         &opinionClient{err: unavailable},
+			secondary:       &opinionClient{result: network},
+			wantConsensus:   &domain.Consensus{Models: []string{"model-b"}},
+			wantSecondCalls: 1,
+		},
+		{
+			name:            "both unavailable",
+			primary:         &opinionClient{err: unavailable},
+			secondary:       &opinionClient{err: unavailable},
+			wantErr:         true,
+			wantSecondCalls: 1,
+		},
+		{
+			name:             "high severity only skips lower severities",
+			primary:          &opinionClient{result: network},
+			secondary:        &opinionClient{result: network},
+			highSeverityOnly: true,
+			wantSecondCalls:  0,
+		},
+		{
+			name:             "high severity only asks for a second opinion",
+			primary:          &opinionClient{result: dockerAuth},
+			secondary:        &opinionClient{result: sameDiagnosis},
+			highSeverityOnly: true,
+			wantConsensus: &domain.Consensus{
+				Models:    []string{"model-a", "model-b"},
+				Verified:  true,
+				Agreement: 1,
+				Agreed:    []string{"error_type", "severity", "root_cause"},
+			},
+			wantActions:     []string{"Rotate the registry token", "Re-run the job", "Check the registry status page"},
+			wantTokens:      240,
+			wantSecondCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewConsensusClient(tt.primary, "model-a", tt.secondary, "model-b", tt.highSeverityOnly, zap.NewNop())
+			result, err := client.Analyze(context.Background(), "log")
+
+			if tt.secondary.calls.Load() != tt.wantSecondCalls {
+				t.Errorf("secondary calls = %d, want %d", tt.secondary.calls.Load(), tt.wantSecondCalls)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrAIUnavailable) {
+					t.Errorf("Analyze() error = %v, want ErrAIUnavailable", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Consensus, tt.wantConsensus) {
+				t.Errorf("Consensus = %+v, want %+v", result.Consensus, tt.wantConsensus)
+			}
+			if tt.wantActions != nil && !reflect.DeepEqual(result.SuggestedActions, tt.wantActions) {
+				t.Errorf("SuggestedActions = %q, want %q", result.SuggestedActions, tt.wantActions)
+			}
+			if tt.wantTokens != 0 && (result.Usage == nil || result.Usage.TotalTokens != tt.wantTokens) {
+				t.Errorf("Usage = %+v, want %d total tokens", result.Usage, tt.wantTokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// briefMaxTokens caps the response of brief analyses.
+const briefMaxTokens = 256
+
+type detailKey struct{}
+
+// WithDetail returns a context asking AI clients for an analysis at level.
+func WithDetail(ctx context.Context, level domain.DetailLevel) context.Context {
+	return context.WithValue(ctx, detailKey{}, level)
+}
+
+// DetailFromContext returns the detail level carried by ctx, or
+// domain.DetailStandard.
+func DetailFromContext(ctx context.Context) domain.DetailLevel {
+	if level, ok := ctx.Value(detailKey{}).(domain.DetailLevel); ok && level != "" {
+		return level
+	}
+	return domain.DetailStandard
+}
+
+// detailMaxTokens scales the configured response token limit to level.
+func detailMaxTokens(level domain.DetailLevel, maxTokens int) int {
+	switch level {
+	case domain.DetailBrief:
+		return min(maxTokens, briefMaxTokens)
+	case domain.DetailDeep:
+		return maxTokens * 2
+	default:
+		return maxTokens
+	}
+}
+
+// detailInstruction returns the prompt instruction for level, or "".
+func detailInstruction(level domain.DetailLevel) string {
+	switch level {
+	case domain.DetailBrief:
+		return "Be brief: root_cause must be a single sentence, give at most 2 suggested_actions " +
+			"and at most 1 prevention tip."
+	case domain.DetailDeep:
+		return "Be thorough: also include \"explanation\", a detailed walk-through of how the failure " +
+			"happened and how the log shows it."
+	default:
+		return ""
+	}
+}
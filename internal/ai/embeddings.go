@@ -0,0 +1,204 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+)
+
+// maxEmbedChars bounds the text sent for embedding. Failures are usually
+// reported at the end of a log, so longer texts keep their tail.
+const maxEmbedChars = 8000
+
+// Embedder turns text into a vector whose cosine similarity to other vectors
+// reflects semantic similarity.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// NewEmbedder creates the embedder for cfg.Provider.
+func NewEmbedder(cfg *config.EmbeddingsConfig) Embedder {
+	client := &http.Client{Timeout: cfg.Timeout}
+	if cfg.Provider == config.AIProviderGemini {
+		return &GeminiEmbedder{config: cfg, httpClient: client}
+	}
+	return &OpenAIEmbedder{config: cfg, httpClient: client}
+}
+
+// embedText returns the part of text that is embedded.
+func embedText(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) > maxEmbedChars {
+		text = text[len(text)-maxEmbedChars:]
+	}
+	return text
+}
+
+// OpenAIEmbedder uses the OpenAI-compatible /embeddings endpoint.
+type OpenAIEmbedder struct {
+	config     *config.EmbeddingsConfig
+	httpClient *http.Client
+}
+
+type openAIEmbeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed implements Embedder.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(openAIEmbeddingRequest{Model: e.config.Model, Input: embedText(text)})
+	if err != nil {
+		return nil, domain.WrapError("marshal_embedding_request", err, false)
+	}
+	url := strings.TrimSuffix(e.config.BaseURL, "/") + "/embeddings"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, domain.WrapError("create_embedding_request", err, false)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.config.APIKey)
+
+	status, respBody, err := doEmbeddingRequest(ctx, e.httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, classifyOpenAIError(status, respBody)
+	}
+
+	var resp openAIEmbeddingResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, domain.WrapError("parse_embedding_response", err, false)
+	}
+	if len(resp.Data) == 0 || len(resp.Data[0].Embedding) == 0 {
+		return nil, domain.WrapError("empty_embedding", domain.ErrInvalidAIResponse, false)
+	}
+	return resp.Data[0].Embedding, nil
+}
+
+// GeminiEmbedder uses the Gemini embedContent endpoint.
+type GeminiEmbedder struct {
+	config     *config.EmbeddingsConfig
+	httpClient *http.Client
+}
+
+type geminiEmbeddingRequest struct {
+	Content geminiEmbeddingContent `json:"content"`
+}
+
+type geminiEmbeddingContent struct {
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiEmbeddingResponse struct {
+	Embedding *struct {
+		Values []float32 `json:"values"`
+	} `json:"embedding"`
+	Error *geminiError `json:"error,omitempty"`
+}
+
+// Embed implements Embedder.
+func (e *GeminiEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(geminiEmbeddingRequest{
+		Content: geminiEmbeddingContent{Parts: []geminiPart{{Text: embedText(text)}}},
+	})
+	if err != nil {
+		return nil, domain.WrapError("marshal_embedding_request", err, false)
+	}
+	url := fmt.Sprintf("%s/v1beta/models/%s:embedContent?key=%s", strings.TrimSuffix(e.config.BaseURL, "/"), e.config.Model, e.config.APIKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, domain.WrapError("create_embedding_request", err, false)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	status, respBody, err := doEmbeddingRequest(ctx, e.httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp geminiEmbeddingResponse
+	if jsonErr := json.Unmarshal(respBody, &resp); jsonErr != nil && status == http.StatusOK {
+		return nil, domain.WrapError("parse_embedding_response", jsonErr, false)
+	}
+	if status != http.StatusOK || resp.Error != nil {
+		return nil, classifyGeminiError(status, resp.Error, respBody)
+	}
+	if resp.Embedding == nil || len(resp.Embedding.Values) == 0 {
+		return nil, domain.WrapError("empty_embedding", domain.ErrInvalidAIResponse, false)
+	}
+	return resp.Embedding.Values, nil
+}
+
+// doEmbeddingRequest sends req and returns the status code and body.
+func doEmbeddingRequest(ctx context.Context, client *http.Client, req *http.Request) (int, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, nil, domain.WrapError("embedding_timeout", domain.ErrAITimeout, true)
+		}
+		return 0, nil, domain.WrapError("embedding_request", err, true)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, domain.WrapError("read_embedding_response", err, true)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// mockEmbeddingDims is the vector size of the mock embedder.
+const mockEmbeddingDims = 256
+
+// MockEmbedder hashes words into a fixed-size vector, so texts sharing
+// words are similar. It needs no provider and is used in mock mode and
+// tests.
+type MockEmbedder struct{}
+
+// NewMockEmbedder creates a mock embedder.
+func NewMockEmbedder() *MockEmbedder {
+	return &MockEmbedder{}
+}
+
+// Embed implements Embedder.
+func (MockEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	vector := make([]float32, mockEmbeddingDims)
+	for _, word := range strings.FieldsFunc(strings.ToLower(embedText(text)), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vector[h.Sum32()%mockEmbeddingDims]++
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] = float32(float64(vector[i]) / norm)
+		}
+	}
+	return vector, nil
+}
@@ -0,0 +1,116 @@
+// Package ai provides unit tests for the embedding clients.
+package ai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestEmbedder(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider config.AIProvider
+		status   int
+		body     string
+		wantPath string
+		want     []float32
+		wantErr  error
+	}{
+		{
+			name:     "openai",
+			provider: config.AIProviderOpenAI,
+			status:   http.StatusOK,
+			body:     `{"data":[{"embedding":[0.1,0.2]}]}`,
+			wantPath: "/embeddings",
+			want:     []float32{0.1, 0.2},
+		},
+		{
+			name:     "openai rate limited",
+			provider: config.AIProviderOpenAI,
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":{"message":"slow down","type":"rate_limit_error"}}`,
+			wantPath: "/embeddings",
+			wantErr:  domain.ErrRateLimited,
+		},
+		{
+			name:     "gemini",
+			provider: config.AIProviderGemini,
+			status:   http.StatusOK,
+			body:     `{"embedding":{"values":[0.3,0.4]}}`,
+			wantPath: "/v1beta/models/text-embedding-004:embedContent",
+			want:     []float32{0.3, 0.4},
+		},
+		{
+			name:     "gemini empty",
+			provider: config.AIProviderGemini,
+			status:   http.StatusOK,
+			body:     `{}`,
+			wantPath: "/v1beta/models/text-embedding-004:embedContent",
+			wantErr:  domain.ErrInvalidAIResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("path = %s, want %s", r.URL.Path, tt.wantPath)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if !strings.Contains(string(body), "connection refused") {
+					t.Errorf("request body %s does not contain the text", body)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			embedder := NewEmbedder(&config.EmbeddingsConfig{
+				Provider: tt.provider,
+				BaseURL:  server.URL,
+				Model:    "text-embedding-004",
+				Timeout:  time.Second,
+			})
+			got, err := embedder.Embed(context.Background(), "dial tcp: connection refused")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Embed() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Embed() error = %v", err)
+			}
+			if len(got) != len(tt.want) || got[0] != tt.want[0] {
+				t.Errorf("Embed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockEmbedder(t *testing.T) {
+	e := NewMockEmbedder()
+	a, _ := e.Embed(context.Background(), "npm ERR! code ERESOLVE unable to resolve dependency tree")
+	b, _ := e.Embed(context.Background(), "npm ERR! code ERESOLVE could not resolve dependency tree")
+	c, _ := e.Embed(context.Background(), "kernel: Out of memory: Killed process 4312")
+
+	cosine := func(x, y []float32) float64 {
+		var sum float64
+		for i := range x {
+			sum += float64(x[i]) * float64(y[i])
+		}
+		return sum
+	}
+	if cosine(a, b) <= cosine(a, c) {
+		t.Errorf("similar logs should be closer: sim(a,b) = %.2f, sim(a,c) = %.2f", cosine(a, b), cosine(a, c))
+	}
+}
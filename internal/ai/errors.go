@@ -0,0 +1,147 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// maxErrorMessageLength bounds provider messages kept in ProviderError.
+const maxErrorMessageLength = 300
+
+// openAIErrorBody is the error envelope returned by OpenAI-compatible APIs.
+type openAIErrorBody struct {
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    any    `json:"code"`
+	} `json:"error"`
+}
+
+// classifyOpenAIError maps an OpenAI-compatible error response to a
+// domain.ProviderError wrapped with the appropriate retryability.
+func classifyOpenAIError(statusCode int, body []byte) error {
+	pe := &domain.ProviderError{Provider: "openai", StatusCode: statusCode}
+
+	var errBody openAIErrorBody
+	if err := json.Unmarshal(body, &errBody); err == nil && errBody.Error != nil {
+		pe.Message = truncate(errBody.Error.Message, maxErrorMessageLength)
+		// code is a string on OpenAI but a number on some compatible backends.
+		if code, ok := errBody.Error.Code.(string); ok && code != "" {
+			pe.Code = code
+		} else {
+			pe.Code = errBody.Error.Type
+		}
+	} else {
+		pe.Message = truncate(string(body), maxErrorMessageLength)
+	}
+
+	code := strings.ToLower(pe.Code)
+	msg := strings.ToLower(pe.Message)
+
+	switch {
+	case code == "insufficient_quota" || code == "billing_hard_limit_reached":
+		pe.Kind = domain.ErrQuotaExceeded
+	case statusCode == http.StatusTooManyRequests:
+		pe.Kind = domain.ErrRateLimited
+	case code == "context_length_exceeded" || strings.Contains(msg, "maximum context length"):
+		pe.Kind = domain.ErrContextLengthExceeded
+	case code == "content_filter" || code == "content_policy_violation":
+		pe.Kind = domain.ErrContentFiltered
+	case code == "model_not_found" || code == "deploymentnotfound" ||
+		(statusCode == http.StatusNotFound && strings.Contains(msg, "model")):
+		pe.Kind = domain.ErrInvalidModel
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden || code == "invalid_api_key":
+		pe.Kind = domain.ErrAIAuth
+	case statusCode >= 500:
+		pe.Kind = domain.ErrAIUnavailable
+	default:
+		pe.Kind = domain.ErrAIRequestRejected
+	}
+
+	return domain.WrapError(opForKind(pe.Kind), pe, retryableKind(pe.Kind))
+}
+
+// classifyGeminiError maps a Gemini error (HTTP or in-body) to a
+// domain.ProviderError wrapped with the appropriate retryability.
+func classifyGeminiError(statusCode int, apiErr *geminiError, body []byte) error {
+	if apiErr != nil && (statusCode == 0 || statusCode == http.StatusOK) {
+		statusCode = apiErr.Code
+	}
+
+	pe := &domain.ProviderError{Provider: "gemini", StatusCode: statusCode}
+	if apiErr != nil {
+		pe.Code = apiErr.Status
+		pe.Message = truncate(apiErr.Message, maxErrorMessageLength)
+	} else {
+		pe.Message = truncate(string(body), maxErrorMessageLength)
+	}
+
+	msg := strings.ToLower(pe.Message)
+
+	switch {
+	case statusCode == http.StatusTooManyRequests || pe.Code == "RESOURCE_EXHAUSTED":
+		// Gemini reports both per-minute limits and exhausted daily/billing
+		// quota as RESOURCE_EXHAUSTED; only the message tells them apart.
+		if strings.Contains(msg, "per day") || strings.Contains(msg, "perday") || strings.Contains(msg, "billing") {
+			pe.Kind = domain.ErrQuotaExceeded
+		} else {
+			pe.Kind = domain.ErrRateLimited
+		}
+	case strings.Contains(msg, "token count") || strings.Contains(msg, "exceeds the maximum number of tokens"):
+		pe.Kind = domain.ErrContextLengthExceeded
+	case statusCode == http.StatusNotFound || pe.Code == "NOT_FOUND":
+		pe.Kind = domain.ErrInvalidModel
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden ||
+		pe.Code == "UNAUTHENTICATED" || pe.Code == "PERMISSION_DENIED":
+		pe.Kind = domain.ErrAIAuth
+	case strings.Contains(msg, "api key not valid"):
+		pe.Kind = domain.ErrAIAuth
+	case statusCode >= 500:
+		pe.Kind = domain.ErrAIUnavailable
+	default:
+		pe.Kind = domain.ErrAIRequestRejected
+	}
+
+	return domain.WrapError(opForKind(pe.Kind), pe, retryableKind(pe.Kind))
+}
+
+// contentFiltered builds the error for a prompt or response blocked by a
+// provider's safety filter.
+func contentFiltered(provider, reason string) error {
+	return domain.WrapError("content_filtered", &domain.ProviderError{
+		Provider: provider,
+		Code:     reason,
+		Kind:     domain.ErrContentFiltered,
+	}, false)
+}
+
+// retryableKind reports whether a failure kind is worth retrying.
+func retryableKind(kind error) bool {
+	return kind == domain.ErrRateLimited || kind == domain.ErrAIUnavailable
+}
+
+// opForKind returns the AnalysisError op label for a failure kind.
+func opForKind(kind error) string {
+	switch kind {
+	case domain.ErrQuotaExceeded:
+		return "quota_exceeded"
+	case domain.ErrRateLimited:
+		return "rate_limit"
+	case domain.ErrContextLengthExceeded:
+		return "context_length_exceeded"
+	case domain.ErrContentFiltered:
+		return "content_filtered"
+	case domain.ErrInvalidModel:
+		return "invalid_model"
+	case domain.ErrAIAuth:
+		return "auth_error"
+	case domain.ErrAIRequestRejected:
+		return "ai_error"
+	default:
+		return "ai_unavailable"
+	}
+}
@@ -0,0 +1,142 @@
+// Package ai provides unit tests for provider error classification.
+package ai
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestClassifyOpenAIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		want      error
+		retryable bool
+	}{
+		{
+			name:   "insufficient quota",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			want:   domain.ErrQuotaExceeded,
+		},
+		{
+			name:      "rate limited",
+			status:    http.StatusTooManyRequests,
+			body:      `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`,
+			want:      domain.ErrRateLimited,
+			retryable: true,
+		},
+		{
+			name:   "context length",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"message":"This model's maximum context length is 8192 tokens","type":"invalid_request_error","code":"context_length_exceeded"}}`,
+			want:   domain.ErrContextLengthExceeded,
+		},
+		{
+			name:   "model not found",
+			status: http.StatusNotFound,
+			body:   `{"error":{"message":"The model gpt-9 does not exist","type":"invalid_request_error","code":"model_not_found"}}`,
+			want:   domain.ErrInvalidModel,
+		},
+		{
+			name:   "content policy",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"message":"blocked","type":"invalid_request_error","code":"content_policy_violation"}}`,
+			want:   domain.ErrContentFiltered,
+		},
+		{
+			name:   "invalid key",
+			status: http.StatusUnauthorized,
+			body:   `{"error":{"message":"Incorrect API key","type":"invalid_request_error","code":"invalid_api_key"}}`,
+			want:   domain.ErrAIAuth,
+		},
+		{
+			name:      "server error with plain body",
+			status:    http.StatusBadGateway,
+			body:      `bad gateway`,
+			want:      domain.ErrAIUnavailable,
+			retryable: true,
+		},
+		{
+			name:   "numeric code from compatible backend",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"message":"bad","type":"invalid_request_error","code":400}}`,
+			want:   domain.ErrAIRequestRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyOpenAIError(tt.status, []byte(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("classifyOpenAIError() = %v, want %v", err, tt.want)
+			}
+			if domain.IsRetryable(err) != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", domain.IsRetryable(err), tt.retryable)
+			}
+			var pe *domain.ProviderError
+			if !errors.As(err, &pe) || pe.Provider != "openai" {
+				t.Errorf("expected openai ProviderError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestClassifyGeminiError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		apiErr *geminiError
+		want   error
+	}{
+		{
+			name:   "per-minute limit",
+			status: http.StatusTooManyRequests,
+			apiErr: &geminiError{Code: 429, Status: "RESOURCE_EXHAUSTED", Message: "Resource has been exhausted (e.g. check quota)."},
+			want:   domain.ErrRateLimited,
+		},
+		{
+			name:   "daily quota",
+			status: http.StatusTooManyRequests,
+			apiErr: &geminiError{Code: 429, Status: "RESOURCE_EXHAUSTED", Message: "Quota exceeded for metric: generate_content requests per day"},
+			want:   domain.ErrQuotaExceeded,
+		},
+		{
+			name:   "input too long",
+			status: http.StatusBadRequest,
+			apiErr: &geminiError{Code: 400, Status: "INVALID_ARGUMENT", Message: "The input token count (2000000) exceeds the maximum number of tokens allowed (1048576)."},
+			want:   domain.ErrContextLengthExceeded,
+		},
+		{
+			name:   "unknown model",
+			status: http.StatusNotFound,
+			apiErr: &geminiError{Code: 404, Status: "NOT_FOUND", Message: "models/gemini-9 is not found"},
+			want:   domain.ErrInvalidModel,
+		},
+		{
+			name:   "invalid key",
+			status: http.StatusBadRequest,
+			apiErr: &geminiError{Code: 400, Status: "INVALID_ARGUMENT", Message: "API key not valid. Please pass a valid API key."},
+			want:   domain.ErrAIAuth,
+		},
+		{
+			name:   "error in 200 body uses embedded code",
+			status: http.StatusOK,
+			apiErr: &geminiError{Code: 503, Status: "UNAVAILABLE", Message: "overloaded"},
+			want:   domain.ErrAIUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyGeminiError(tt.status, tt.apiErr, nil)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("classifyGeminiError() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,11 @@
 package ai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/ai-devops/internal/config"
 	"github.com/ai-devops/internal/domain"
@@ -18,11 +15,10 @@ import (
 
 // GeminiClient implements the Client interface using Google's Gemini API.
 type GeminiClient struct {
-	config     *config.AIConfig
-	httpClient *http.Client
-	prompter   PromptBuilder
-	validator  ResponseValidator
-	logger     *zap.Logger
+	*providerClient
+	config *config.AIConfig
+	tokens *googleTokenSource // nil unless authenticating with ADC
+	logger *zap.Logger
 }
 
 // Gemini API request/response structures
@@ -59,6 +55,10 @@ type geminiGenerationConfig struct {
 	MaxOutputTokens int     `json:"maxOutputTokens"`
 	TopP            float64 `json:"topP,omitempty"`
 	TopK            int     `json:"topK,omitempty"`
+
+	// ResponseMimeType and ResponseSchema enable Gemini's JSON mode.
+	ResponseMimeType string         `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]any `json:"responseSchema,omitempty"`
 }
 
 // geminiSafetySetting represents a safety setting for content filtering.
@@ -113,42 +113,37 @@ type geminiError struct {
 
 // NewGeminiClient creates a new Gemini AI client.
 func NewGeminiClient(cfg *config.AIConfig, prompter PromptBuilder, validator ResponseValidator, logger *zap.Logger) *GeminiClient {
-	return &GeminiClient{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: cfg.Timeout,
-		},
-		prompter:  prompter,
-		validator: validator,
-		logger:    logger.Named("gemini_client"),
+	c := &GeminiClient{config: cfg, logger: logger.Named("gemini_client")}
+	c.providerClient = newProviderClient(config.AIProviderGemini, cfg, c, prompter, validator, c.logger)
+	if cfg.UsesADC() {
+		c.tokens = newGoogleTokenSource(cfg.GoogleCredentialsFile, c.httpClient)
 	}
+	return c
 }
 
-// Analyze sends a log to the Gemini API and returns a structured analysis.
-func (c *GeminiClient) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
-	startTime := time.Now()
-	c.logger.Debug("starting Gemini analysis", zap.Int("log_length", len(log)))
+// authHeader returns the request headers that authenticate with the API
+// key or, with ADC, an OAuth access token. Keys are never put in the URL,
+// where proxies would log them.
+func (c *GeminiClient) authHeader(ctx context.Context) (http.Header, error) {
+	if c.tokens == nil {
+		return http.Header{"X-Goog-Api-Key": {c.config.APIKey}}, nil
+	}
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return http.Header{"Authorization": {"Bearer " + token}}, nil
+}
 
-	// Build the user prompt with system context embedded
-	// Combine system prompt and user prompt for better compatibility
-	systemPrompt := c.prompter.BuildSystemPrompt()
-	userPrompt := c.prompter.BuildUserPrompt(log)
+// encodeRequest implements providerTransport. The system prompt is sent as
+// part of the user content, which every Gemini model version accepts.
+func (c *GeminiClient) encodeRequest(ctx context.Context, systemPrompt, userPrompt string) (*providerRequest, error) {
 	combinedPrompt := fmt.Sprintf("%s\n\n---\n\n%s", systemPrompt, userPrompt)
 
 	// Calculate max tokens - thinking models (2.5+) need more tokens
 	// since thinking tokens count against the output limit
-	maxTokens := c.config.MaxTokens
-	if isThinkingModel(c.config.Model) {
-		// Thinking models need ~4x more tokens to account for reasoning
-		maxTokens = c.config.MaxTokens * 4
-		if maxTokens < 4096 {
-			maxTokens = 4096
-		}
-		c.logger.Debug("using increased token limit for thinking model",
-			zap.String("model", c.config.Model),
-			zap.Int("max_tokens", maxTokens),
-		)
-	}
+	detail := DetailFromContext(ctx)
+	maxTokens := c.outputTokens(detailMaxTokens(detail, c.config.MaxTokens))
 
 	// Build the request using the contents array (more compatible approach)
 	reqBody := geminiRequest{
@@ -161,118 +156,114 @@ func (c *GeminiClient) Analyze(ctx context.Context, log string) (*domain.Analysi
 			},
 		},
 		GenerationConfig: geminiGenerationConfig{
-			Temperature:     0.1, // Low temperature for deterministic output
+			Temperature:     c.config.Temperature,
 			MaxOutputTokens: maxTokens,
 			TopP:            0.95,
 			TopK:            40,
 		},
-		SafetySettings: []geminiSafetySetting{
-			{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_NONE"},
-			{Category: "HARM_CATEGORY_HATE_SPEECH", Threshold: "BLOCK_NONE"},
-			{Category: "HARM_CATEGORY_SEXUALLY_EXPLICIT", Threshold: "BLOCK_NONE"},
-			{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Threshold: "BLOCK_NONE"},
-		},
+		SafetySettings: geminiSafetySettings,
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, domain.WrapError("marshal_request", err, false)
+	if c.config.StructuredOutput {
+		reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		reqBody.GenerationConfig.ResponseSchema = newGeminiResponseSchema(detail)
 	}
 
-	// Build the URL with API key as query parameter
-	url := c.buildURL()
-
-	// Execute request with retry logic
-	var result *domain.AnalysisResult
-	var lastErr error
-
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff
-			backoff := time.Duration(attempt*attempt) * time.Second
-			c.logger.Debug("retrying Gemini request",
-				zap.Int("attempt", attempt),
-				zap.Duration("backoff", backoff),
-			)
-			select {
-			case <-ctx.Done():
-				return nil, domain.WrapError("context_cancelled", ctx.Err(), false)
-			case <-time.After(backoff):
-			}
-		}
-
-		result, lastErr = c.executeRequest(ctx, url, jsonBody)
-		if lastErr == nil {
-			break
-		}
+	return c.newRequest(ctx, reqBody)
+}
 
-		// Check if error is retryable
-		if !domain.IsRetryable(lastErr) {
-			break
+// encodeChat implements providerTransport. The system prompt is sent as a
+// system instruction and assistant turns as the "model" role.
+func (c *GeminiClient) encodeChat(ctx context.Context, systemPrompt string, messages []domain.ChatMessage) (*providerRequest, error) {
+	contents := make([]geminiContent, len(messages))
+	for i, message := range messages {
+		role := "user"
+		if message.Role == domain.ChatRoleAssistant {
+			role = "model"
 		}
+		contents[i] = geminiContent{Role: role, Parts: []geminiPart{{Text: message.Content}}}
 	}
 
-	if lastErr != nil {
-		return nil, lastErr
+	reqBody := geminiRequest{
+		Contents:          contents,
+		SystemInstruction: &geminiSystemInstruction{Parts: []geminiPart{{Text: systemPrompt}}},
+		GenerationConfig: geminiGenerationConfig{
+			Temperature:     c.config.Temperature,
+			MaxOutputTokens: c.outputTokens(c.config.MaxTokens),
+			TopP:            0.95,
+			TopK:            40,
+		},
+		SafetySettings: geminiSafetySettings,
 	}
+	return c.newRequest(ctx, reqBody)
+}
+
+// geminiSafetySettings disables content blocking, which trips on the
+// vocabulary of ordinary failure logs ("kill", "abort", "fatal").
+var geminiSafetySettings = []geminiSafetySetting{
+	{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_NONE"},
+	{Category: "HARM_CATEGORY_HATE_SPEECH", Threshold: "BLOCK_NONE"},
+	{Category: "HARM_CATEGORY_SEXUALLY_EXPLICIT", Threshold: "BLOCK_NONE"},
+	{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Threshold: "BLOCK_NONE"},
+}
 
-	c.logger.Debug("Gemini analysis completed",
-		zap.Duration("duration", time.Since(startTime)),
-		zap.String("error_type", result.ErrorType),
+// outputTokens returns the output token limit for maxTokens. Thinking
+// models (2.5+) need more, since thinking tokens count against the limit.
+func (c *GeminiClient) outputTokens(maxTokens int) int {
+	if !isThinkingModel(c.config.Model) {
+		return maxTokens
+	}
+	// Thinking models need ~4x more tokens to account for reasoning
+	maxTokens *= 4
+	if maxTokens < 4096 {
+		maxTokens = 4096
+	}
+	c.logger.Debug("using increased token limit for thinking model",
+		zap.String("model", c.config.Model),
+		zap.Int("max_tokens", maxTokens),
 	)
+	return maxTokens
+}
 
-	return result, nil
+// newRequest encodes an authenticated generateContent request.
+func (c *GeminiClient) newRequest(ctx context.Context, reqBody geminiRequest) (*providerRequest, error) {
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+	header, err := c.authHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &providerRequest{URL: c.buildURL(), Header: header, Body: jsonBody}, nil
 }
 
 // buildURL constructs the Gemini API URL.
 func (c *GeminiClient) buildURL() string {
 	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
 
+	if c.config.UsesVertex() {
+		return fmt.Sprintf("%s/v1/%s:generateContent", baseURL, c.vertexModel())
+	}
+
 	// Support both full URL and just the base
 	if strings.Contains(baseURL, "/v1") || strings.Contains(baseURL, "/v1beta") {
 		// URL already contains version, append model and action
-		return fmt.Sprintf("%s/models/%s:generateContent?key=%s", baseURL, c.config.Model, c.config.APIKey)
+		return fmt.Sprintf("%s/models/%s:generateContent", baseURL, c.config.Model)
 	}
 
 	// Default Gemini API URL format
-	return fmt.Sprintf("%s/v1beta/models/%s:generateContent?key=%s", baseURL, c.config.Model, c.config.APIKey)
+	return fmt.Sprintf("%s/v1beta/models/%s:generateContent", baseURL, c.config.Model)
 }
 
-// executeRequest performs a single HTTP request to the Gemini API.
-func (c *GeminiClient) executeRequest(ctx context.Context, url string, jsonBody []byte) (*domain.AnalysisResult, error) {
-	// Log request details (mask API key)
-	maskedURL := maskAPIKey(url)
-	c.logger.Debug("sending Gemini request",
-		zap.String("url", maskedURL),
-		zap.Int("body_size", len(jsonBody)),
-	)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
-	if err != nil {
-		return nil, domain.WrapError("create_request", err, false)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		if ctx.Err() != nil {
-			return nil, domain.WrapError("gemini_timeout", domain.ErrAITimeout, true)
-		}
-		return nil, domain.WrapError("http_request", err, true)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, domain.WrapError("read_response", err, true)
-	}
-
-	// Handle HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		return c.handleHTTPError(resp.StatusCode, body)
-	}
+// vertexModel returns the Vertex AI resource name of the model.
+func (c *GeminiClient) vertexModel() string {
+	return fmt.Sprintf("projects/%s/locations/%s/publishers/google/models/%s",
+		c.config.VertexProject, c.config.VertexLocation, c.config.Model)
+}
 
+// decodeResponse implements providerTransport.
+func (c *GeminiClient) decodeResponse(statusCode int, body []byte) (string, *domain.TokenUsage, error) {
 	// Log raw response for debugging
 	c.logger.Debug("raw Gemini response",
 		zap.String("body", truncate(string(body), 2000)),
@@ -285,19 +276,17 @@ func (c *GeminiClient) executeRequest(ctx context.Context, url string, jsonBody
 			zap.Error(err),
 			zap.String("body_preview", truncate(string(body), 500)),
 		)
-		return nil, domain.WrapError("parse_response", err, false)
+		return "", nil, domain.WrapError("parse_response", err, false)
 	}
 
 	// Check for API-level errors
 	if geminiResp.Error != nil {
-		return nil, domain.WrapError("gemini_api_error",
-			fmt.Errorf("[%d] %s: %s", geminiResp.Error.Code, geminiResp.Error.Status, geminiResp.Error.Message), false)
+		return "", nil, classifyGeminiError(statusCode, geminiResp.Error, body)
 	}
 
 	// Check for blocked content
 	if geminiResp.PromptFeedback != nil && geminiResp.PromptFeedback.BlockReason != "" {
-		return nil, domain.WrapError("content_blocked",
-			fmt.Errorf("prompt blocked: %s", geminiResp.PromptFeedback.BlockReason), false)
+		return "", nil, contentFiltered("gemini", geminiResp.PromptFeedback.BlockReason)
 	}
 
 	// Extract the response content
@@ -305,7 +294,7 @@ func (c *GeminiClient) executeRequest(ctx context.Context, url string, jsonBody
 		c.logger.Warn("no candidates in response",
 			zap.String("body", truncate(string(body), 1000)),
 		)
-		return nil, domain.WrapError("empty_response", domain.ErrInvalidAIResponse, false)
+		return "", nil, domain.WrapError("empty_response", domain.ErrInvalidAIResponse, false)
 	}
 
 	candidate := geminiResp.Candidates[0]
@@ -317,9 +306,9 @@ func (c *GeminiClient) executeRequest(ctx context.Context, url string, jsonBody
 	)
 
 	// Check finish reason
-	if candidate.FinishReason == "SAFETY" {
-		return nil, domain.WrapError("safety_filter",
-			fmt.Errorf("response blocked by safety filter"), false)
+	switch candidate.FinishReason {
+	case "SAFETY", "PROHIBITED_CONTENT", "BLOCKLIST", "SPII":
+		return "", nil, contentFiltered("gemini", candidate.FinishReason)
 	}
 
 	if len(candidate.Content.Parts) == 0 {
@@ -328,7 +317,7 @@ func (c *GeminiClient) executeRequest(ctx context.Context, url string, jsonBody
 			zap.String("finish_reason", candidate.FinishReason),
 			zap.Any("candidate", candidate),
 		)
-		return nil, domain.WrapError("empty_content", domain.ErrInvalidAIResponse, false)
+		return "", nil, domain.WrapError("empty_content", domain.ErrInvalidAIResponse, false)
 	}
 
 	// Extract text from parts
@@ -341,21 +330,24 @@ func (c *GeminiClient) executeRequest(ctx context.Context, url string, jsonBody
 
 	content := textContent.String()
 	if content == "" {
-		return nil, domain.WrapError("empty_text", domain.ErrInvalidAIResponse, false)
-	}
-
-	// Extract and parse the JSON content from the response
-	result, err := c.parseAnalysisResult(content)
-	if err != nil {
-		return nil, err
+		return "", nil, domain.WrapError("empty_text", domain.ErrInvalidAIResponse, false)
 	}
 
-	// Validate the result
-	if err := c.validator.Validate(result); err != nil {
-		return nil, err
+	var usage *domain.TokenUsage
+	if geminiResp.UsageMetadata != nil {
+		usage = &domain.TokenUsage{
+			PromptTokens:     geminiResp.UsageMetadata.PromptTokenCount,
+			CompletionTokens: geminiResp.UsageMetadata.TotalTokenCount - geminiResp.UsageMetadata.PromptTokenCount,
+			TotalTokens:      geminiResp.UsageMetadata.TotalTokenCount,
+		}
 	}
+	return content, usage, nil
+}
 
-	return result, nil
+// decodeError implements providerTransport.
+func (c *GeminiClient) decodeError(statusCode int, body []byte) error {
+	_, err := c.handleHTTPError(statusCode, body)
+	return err
 }
 
 // handleHTTPError processes HTTP error responses.
@@ -370,72 +362,26 @@ func (c *GeminiClient) handleHTTPError(statusCode int, body []byte) (*domain.Ana
 		)
 	}
 
-	switch statusCode {
-	case http.StatusTooManyRequests:
-		return nil, domain.WrapError("rate_limit", domain.ErrRateLimited, true)
-	case http.StatusUnauthorized, http.StatusForbidden:
-		return nil, domain.WrapError("auth_error",
-			fmt.Errorf("authentication failed (status %d): check your API key", statusCode), false)
-	case http.StatusBadRequest:
-		return nil, domain.WrapError("bad_request",
-			fmt.Errorf("bad request: %s", truncate(string(body), 200)), false)
-	case http.StatusNotFound:
-		return nil, domain.WrapError("model_not_found",
-			fmt.Errorf("model not found: check model name in configuration"), false)
-	default:
-		if statusCode >= 500 {
-			return nil, domain.WrapError("gemini_unavailable", domain.ErrAIUnavailable, true)
-		}
-		return nil, domain.WrapError("gemini_error",
-			fmt.Errorf("Gemini API returned status %d: %s", statusCode, truncate(string(body), 200)), false)
-	}
+	return nil, classifyGeminiError(statusCode, errResp.Error, body)
 }
 
-// parseAnalysisResult extracts the AnalysisResult from the Gemini response content.
-func (c *GeminiClient) parseAnalysisResult(content string) (*domain.AnalysisResult, error) {
-	var result domain.AnalysisResult
-
-	// Try to find JSON in the content (Gemini might include markdown code blocks)
-	jsonContent := extractJSON(content)
-	if jsonContent == "" {
-		c.logger.Warn("could not extract JSON from Gemini response",
-			zap.String("content_preview", truncate(content, 200)),
-		)
-		return nil, domain.WrapError("extract_json", domain.ErrInvalidAIResponse, false)
+// healthRequest implements providerTransport. It lists the models.
+func (c *GeminiClient) healthRequest(ctx context.Context) (*http.Request, error) {
+	header, err := c.authHeader(ctx)
+	if err != nil {
+		return nil, err
 	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		c.logger.Warn("failed to unmarshal Gemini response",
-			zap.Error(err),
-			zap.String("json_content", truncate(jsonContent, 200)),
-		)
-		return nil, domain.WrapError("unmarshal_result", domain.ErrInvalidAIResponse, false)
+	url := fmt.Sprintf("%s/v1beta/models", strings.TrimSuffix(c.config.BaseURL, "/"))
+	if c.config.UsesVertex() {
+		// Vertex has no model list; fetch the configured model instead
+		url = fmt.Sprintf("%s/v1beta1/publishers/google/models/%s", strings.TrimSuffix(c.config.BaseURL, "/"), c.config.Model)
 	}
-
-	return &result, nil
-}
-
-// HealthCheck verifies the Gemini API is reachable.
-func (c *GeminiClient) HealthCheck(ctx context.Context) error {
-	// Use the models.list endpoint to check connectivity
-	url := fmt.Sprintf("%s/v1beta/models?key=%s", strings.TrimSuffix(c.config.BaseURL, "/"), c.config.APIKey)
-
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return err
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return domain.WrapError("health_check", domain.ErrAIUnavailable, true)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return domain.WrapError("health_check", domain.ErrAIUnavailable, true)
+		return nil, err
 	}
-
-	return nil
+	req.Header = header
+	return req, nil
 }
 
 // maskAPIKey masks the API key in a URL for safe logging.
@@ -135,6 +135,12 @@ func TestGeminiClient_Analyze(t *testing.T) {
 				if r.Header.Get("Content-Type") != "application/json" {
 					t.Errorf("expected Content-Type application/json")
 				}
+				if r.Header.Get("x-goog-api-key") != "test-api-key" {
+					t.Errorf("expected the API key in the x-goog-api-key header")
+				}
+				if r.URL.Query().Has("key") {
+					t.Errorf("API key must not be sent in the URL")
+				}
 
 				w.WriteHeader(tt.statusCode)
 				json.NewEncoder(w).Encode(tt.response)
@@ -244,21 +250,21 @@ func TestGeminiClient_BuildURL(t *testing.T) {
 			baseURL:  "https://generativelanguage.googleapis.com",
 			model:    "gemini-2.0-flash",
 			apiKey:   "test-key",
-			expected: "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent?key=test-key",
+			expected: "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent",
 		},
 		{
 			name:     "base URL with version",
 			baseURL:  "https://generativelanguage.googleapis.com/v1",
 			model:    "gemini-1.5-pro",
 			apiKey:   "test-key",
-			expected: "https://generativelanguage.googleapis.com/v1/models/gemini-1.5-pro:generateContent?key=test-key",
+			expected: "https://generativelanguage.googleapis.com/v1/models/gemini-1.5-pro:generateContent",
 		},
 		{
 			name:     "trailing slash removed",
 			baseURL:  "https://generativelanguage.googleapis.com/",
 			model:    "gemini-2.0-flash",
 			apiKey:   "my-key",
-			expected: "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent?key=my-key",
+			expected: "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent",
 		},
 	}
 
@@ -412,3 +418,62 @@ func TestGeminiClient_HandleHTTPError(t *testing.T) {
 		})
 	}
 }
+
+func TestGeminiClient_StructuredOutput(t *testing.T) {
+	logger := zap.NewNop()
+	prompter, _ := NewDefaultPromptBuilder()
+	validator := NewDefaultValidator()
+
+	var gotReq geminiRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&gotReq); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(geminiResponse{
+			Candidates: []geminiCandidate{
+				{
+					Content: geminiContent{
+						Role: "model",
+						Parts: []geminiPart{
+							{Text: `{"error_type":"test","severity":"Low","root_cause":"cause","suggested_actions":["fix"],"prevention_tips":[]}`},
+						},
+					},
+					FinishReason: "STOP",
+				},
+			},
+			UsageMetadata: &geminiUsageMetadata{
+				PromptTokenCount:     120,
+				CandidatesTokenCount: 30,
+				TotalTokenCount:      150,
+			},
+		})
+	}))
+	defer server.Close()
+
+	cfg := &config.AIConfig{
+		Provider:         config.AIProviderGemini,
+		APIKey:           "test-api-key",
+		BaseURL:          server.URL,
+		Model:            "gemini-2.0-flash",
+		Timeout:          5 * time.Second,
+		MaxTokens:        512,
+		StructuredOutput: true,
+	}
+
+	client := NewGeminiClient(cfg, prompter, validator, logger)
+	result, err := client.Analyze(context.Background(), "test log content")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Usage == nil || result.Usage.TotalTokens != 150 || result.Usage.PromptTokens != 120 || result.Usage.Model != "gemini-2.0-flash" {
+		t.Errorf("Usage = %+v, want prompt 120, total 150 by gemini-2.0-flash", result.Usage)
+	}
+
+	if gotReq.GenerationConfig.ResponseMimeType != "application/json" {
+		t.Errorf("responseMimeType = %q, want application/json", gotReq.GenerationConfig.ResponseMimeType)
+	}
+	if gotReq.GenerationConfig.ResponseSchema == nil {
+		t.Error("expected responseSchema to be set")
+	}
+}
@@ -0,0 +1,254 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// googleScope is the OAuth scope of Gemini and Vertex AI requests.
+const googleScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// googleTokenURL is the default OAuth token endpoint.
+const googleTokenURL = "https://oauth2.googleapis.com/token"
+
+// tokenRefreshMargin is how long before expiry an access token is renewed.
+const tokenRefreshMargin = time.Minute
+
+// googleCredentials is a Google credentials JSON file: a service account
+// key or the gcloud user credentials.
+type googleCredentials struct {
+	Type string `json:"type"`
+
+	// service_account
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+
+	// authorized_user
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// googleTokenResponse is an OAuth token response.
+type googleTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// googleTokenSource supplies OAuth access tokens from Google Application
+// Default Credentials, caching each until shortly before it expires.
+type googleTokenSource struct {
+	credentialsFile string
+	httpClient      *http.Client
+	now             func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// newGoogleTokenSource creates a token source. credentialsFile may be
+// empty to look up the default credentials.
+func newGoogleTokenSource(credentialsFile string, httpClient *http.Client) *googleTokenSource {
+	return &googleTokenSource{
+		credentialsFile: credentialsFile,
+		httpClient:      httpClient,
+		now:             time.Now,
+	}
+}
+
+// Token returns a valid access token.
+func (s *googleTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Before(s.expiry.Add(-tokenRefreshMargin)) {
+		return s.token, nil
+	}
+
+	resp, err := s.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%w: google credentials: %v", domain.ErrAIAuth, err)
+	}
+	s.token = resp.AccessToken
+	s.expiry = s.now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// fetch obtains a new token from the first available credentials: the
+// configured file, GOOGLE_APPLICATION_CREDENTIALS, the gcloud default
+// credentials, then the metadata server.
+func (s *googleTokenSource) fetch(ctx context.Context) (*googleTokenResponse, error) {
+	path := s.credentialsFile
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if path == "" {
+		if wellKnown := gcloudCredentialsPath(); wellKnown != "" {
+			if _, err := os.Stat(wellKnown); err == nil {
+				path = wellKnown
+			}
+		}
+	}
+	if path == "" {
+		return s.metadataToken(ctx)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var creds googleCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	tokenURI := creds.TokenURI
+	if tokenURI == "" {
+		tokenURI = googleTokenURL
+	}
+
+	form := url.Values{}
+	switch creds.Type {
+	case "service_account":
+		assertion, err := s.signJWT(&creds, tokenURI)
+		if err != nil {
+			return nil, err
+		}
+		form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+		form.Set("assertion", assertion)
+	case "authorized_user":
+		form.Set("grant_type", "refresh_token")
+		form.Set("client_id", creds.ClientID)
+		form.Set("client_secret", creds.ClientSecret)
+		form.Set("refresh_token", creds.RefreshToken)
+	default:
+		return nil, fmt.Errorf("unsupported credentials type %q in %s", creds.Type, path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return s.doTokenRequest(s.httpClient, req)
+}
+
+// signJWT builds the signed assertion of the service account JWT grant.
+func (s *googleTokenSource) signJWT(creds *googleCredentials, audience string) (string, error) {
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("service account private key is not PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("parse service account private key: %w", err)
+		}
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("service account private key is not RSA")
+	}
+
+	now := s.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": creds.PrivateKeyID})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   creds.ClientEmail,
+		"scope": googleScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// metadataToken obtains the token of the instance's service account from
+// the GCE/GKE metadata server. The metadata server is never proxied.
+func (s *googleTokenSource) metadataToken(ctx context.Context) (*googleTokenResponse, error) {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = "metadata.google.internal"
+	}
+	tokenURL := fmt.Sprintf("http://%s/computeMetadata/v1/instance/service-accounts/default/token?scopes=%s",
+		host, url.QueryEscape(googleScope))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{Proxy: nil},
+	}
+	return s.doTokenRequest(client, req)
+}
+
+// doTokenRequest sends a token request and decodes the response.
+func (s *googleTokenSource) doTokenRequest(client *http.Client, req *http.Request) (*googleTokenResponse, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request to %s returned %d: %s", req.URL.Host, resp.StatusCode, truncate(string(body), 200))
+	}
+
+	var token googleTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+	return &token, nil
+}
+
+// gcloudCredentialsPath returns the path of the credentials written by
+// `gcloud auth application-default login`.
+func gcloudCredentialsPath() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return filepath.Join(dir, "application_default_credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
@@ -0,0 +1,236 @@
+// Package ai provides unit tests for Google Application Default Credentials.
+package ai
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// writeCredentials writes a credentials JSON file and returns its path.
+func writeCredentials(t *testing.T, creds map[string]string) string {
+	t.Helper()
+	data, err := json.Marshal(creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGoogleTokenSource_ServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("grant_type = %q", got)
+		}
+
+		parts := strings.Split(r.PostForm.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("assertion has %d parts, want 3", len(parts))
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			t.Errorf("assertion signature: %v", err)
+		}
+		var claims map[string]any
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		_ = json.Unmarshal(payload, &claims)
+		if claims["iss"] != "svc@project.iam.gserviceaccount.com" || claims["scope"] != googleScope {
+			t.Errorf("claims = %v", claims)
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "sa-token", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	path := writeCredentials(t, map[string]string{
+		"type":           "service_account",
+		"client_email":   "svc@project.iam.gserviceaccount.com",
+		"private_key":    string(keyPEM),
+		"private_key_id": "key-1",
+		"token_uri":      server.URL,
+	})
+
+	source := newGoogleTokenSource(path, server.Client())
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if token != "sa-token" {
+			t.Errorf("Token() = %q, want sa-token", token)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("token requests = %d, want 1 (cached)", requests.Load())
+	}
+
+	// Near expiry the token is renewed.
+	now = now.Add(time.Hour - 30*time.Second)
+	if _, err := source.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("token requests = %d, want 2 after expiry", requests.Load())
+	}
+}
+
+func TestGoogleTokenSource_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		creds map[string]string
+	}{
+		{
+			name:  "token endpoint rejects refresh token",
+			creds: map[string]string{"type": "authorized_user", "refresh_token": "expired", "token_uri": server.URL},
+		},
+		{
+			name:  "unsupported type",
+			creds: map[string]string{"type": "external_account"},
+		},
+		{
+			name:  "invalid private key",
+			creds: map[string]string{"type": "service_account", "private_key": "not a key", "token_uri": server.URL},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newGoogleTokenSource(writeCredentials(t, tt.creds), server.Client())
+			_, err := source.Token(context.Background())
+			if !errors.Is(err, domain.ErrAIAuth) {
+				t.Errorf("Token() error = %v, want ErrAIAuth", err)
+			}
+		})
+	}
+}
+
+func TestGeminiClient_ADCAuth(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "user-token", "expires_in": 3600})
+	}))
+	defer tokenServer.Close()
+
+	var authorization, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		apiKey = r.Header.Get("x-goog-api-key")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewGeminiClient(&config.AIConfig{
+		Provider:   config.AIProviderGemini,
+		GeminiAuth: config.GeminiAuthADC,
+		GoogleCredentialsFile: writeCredentials(t, map[string]string{
+			"type":          "authorized_user",
+			"client_id":     "id",
+			"client_secret": "secret",
+			"refresh_token": "refresh",
+			"token_uri":     tokenServer.URL,
+		}),
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, nil, nil, zap.NewNop())
+
+	if err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	if authorization != "Bearer user-token" {
+		t.Errorf("Authorization = %q, want the access token", authorization)
+	}
+	if apiKey != "" {
+		t.Errorf("x-goog-api-key = %q, want none with ADC", apiKey)
+	}
+}
+
+func TestGeminiClient_Vertex(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "vertex-token", "expires_in": 3600})
+	}))
+	defer tokenServer.Close()
+
+	var path, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	prompter, _ := NewDefaultPromptBuilder()
+	client := NewGeminiClient(&config.AIConfig{
+		Provider: config.AIProviderGemini,
+		// Vertex authenticates with ADC even when api_key is configured
+		GeminiAuth:     config.GeminiAuthAPIKey,
+		APIKey:         "unused",
+		VertexProject:  "my-project",
+		VertexLocation: "europe-west4",
+		GoogleCredentialsFile: writeCredentials(t, map[string]string{
+			"type":          "authorized_user",
+			"refresh_token": "refresh",
+			"token_uri":     tokenServer.URL,
+		}),
+		BaseURL:   server.URL,
+		Model:     "gemini-2.0-flash",
+		Timeout:   5 * time.Second,
+		MaxTokens: 512,
+	}, prompter, NewDefaultValidator(), zap.NewNop())
+
+	_, err := client.Analyze(context.Background(), "test log content")
+	if !errors.Is(err, domain.ErrRateLimited) {
+		t.Fatalf("Analyze() error = %v, want the provider's rate limit", err)
+	}
+
+	wantPath := "/v1/projects/my-project/locations/europe-west4/publishers/google/models/gemini-2.0-flash:generateContent"
+	if path != wantPath {
+		t.Errorf("path = %q, want %q", path, wantPath)
+	}
+	if authorization != "Bearer vertex-token" {
+		t.Errorf("Authorization = %q, want the access token", authorization)
+	}
+}
@@ -14,6 +14,11 @@ type Client interface {
 	// The context should carry timeout and cancellation signals.
 	Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error)
 
+	// Chat answers a follow-up message in the context of a conversation:
+	// its prior logs, their results and the messages so far. The
+	// conversation is not modified.
+	Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error)
+
 	// HealthCheck verifies the AI service is reachable.
 	HealthCheck(ctx context.Context) error
 }
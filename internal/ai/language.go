@@ -0,0 +1,40 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"fmt"
+)
+
+type languageKey struct{}
+
+// WithLanguage returns a context asking AI clients to write the analysis
+// text in language (e.g. "Vietnamese", "ja"). An empty language means the
+// default, English.
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// LanguageFromContext returns the output language carried by ctx, or "".
+func LanguageFromContext(ctx context.Context) string {
+	language, _ := ctx.Value(languageKey{}).(string)
+	return language
+}
+
+// buildUserPrompt builds the user prompt and injects the detail level and
+// output language requested in ctx. Machine-readable fields stay in English
+// so validation, rules and severity handling are unaffected.
+func buildUserPrompt(ctx context.Context, prompter PromptBuilder, log string) string {
+	prompt := prompter.BuildUserPrompt(log)
+
+	if instruction := detailInstruction(DetailFromContext(ctx)); instruction != "" {
+		prompt += "\n\n" + instruction
+	}
+
+	language := LanguageFromContext(ctx)
+	if language == "" {
+		return prompt
+	}
+	return prompt + fmt.Sprintf("\n\nWrite root_cause, suggested_actions, command descriptions and prevention_tips in %s. "+
+		"Keep error_type in English snake_case, severity exactly Low, Medium or High, and commands unchanged.", language)
+}
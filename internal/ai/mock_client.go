@@ -3,6 +3,7 @@ package ai
 
 import (
 	"context"
+	"time"
 
 	"github.com/ai-devops/internal/domain"
 	"go.uber.org/zap"
@@ -23,6 +24,9 @@ func NewMockClient(logger *zap.Logger) *MockClient {
 // Analyze returns a mock analysis result.
 func (c *MockClient) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
 	c.logger.Debug("mock AI analysis", zap.Int("log_length", len(log)))
+	trace := TraceFromContext(ctx)
+	trace.recordPrompt(len(log))
+	trace.recordAttempt("mock", "", "", time.Now(), nil)
 
 	// Return a generic mock response
 	return &domain.AnalysisResult{
@@ -39,6 +43,21 @@ func (c *MockClient) Analyze(ctx context.Context, log string) (*domain.AnalysisR
 	}, nil
 }
 
+// Chat returns a mock reply.
+func (c *MockClient) Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error) {
+	c.logger.Debug("mock AI chat",
+		zap.Int("logs", len(conversation.Logs)),
+		zap.Int("messages", len(conversation.Messages)),
+	)
+	trace := TraceFromContext(ctx)
+	trace.recordPrompt(len(message))
+	trace.recordAttempt("mock", "", "", time.Now(), nil)
+
+	return &domain.ChatReply{
+		Content: "This is a mock reply. Enable real AI by setting AI_MOCK_MODE=false",
+	}, nil
+}
+
 // HealthCheck always returns success for mock client.
 func (c *MockClient) HealthCheck(ctx context.Context) error {
 	return nil
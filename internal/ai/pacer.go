@@ -0,0 +1,282 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// paceBurstWindow is how much of a minute's allowance may be spent at once.
+// Bursts beyond it are spread out at the steady refill rate.
+const paceBurstWindow = 10 * time.Second
+
+// promptOverheadTokens approximates the system prompt and schema tokens
+// added to every request.
+const promptOverheadTokens = 600
+
+// tokenBucket is a reservation-based token bucket. Reservations may drive the
+// balance negative; the caller then waits until the deficit has refilled,
+// which serves waiters in arrival order.
+type tokenBucket struct {
+	rate     float64 // tokens per second
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	rate := float64(perMinute) / 60
+	capacity := rate * paceBurstWindow.Seconds()
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &tokenBucket{rate: rate, capacity: capacity, tokens: capacity, last: now}
+}
+
+// reserve takes n tokens and returns how long the caller must wait.
+func (b *tokenBucket) reserve(n float64, now time.Time) time.Duration {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// PacerStats reports how much outbound pacing has delayed requests.
+type PacerStats struct {
+	Requests     uint64        `json:"requests"`
+	Delayed      uint64        `json:"delayed"`
+	TotalDelay   time.Duration `json:"total_delay_ns"`
+	MaxDelay     time.Duration `json:"max_delay_ns"`
+	AverageDelay time.Duration `json:"average_delay_ns"`
+}
+
+// SharedBuckets stores token buckets outside the process so that several
+// replicas pace against the same provider limits. Reserve takes n tokens
+// (negative refunds) from the named bucket and returns the wait.
+type SharedBuckets interface {
+	Reserve(ctx context.Context, name string, n, ratePerSecond, capacity float64) (time.Duration, error)
+}
+
+// Shared bucket names.
+const (
+	sharedRequestsBucket = "requests"
+	sharedTokensBucket   = "tokens"
+)
+
+// Pacer proactively limits outbound provider requests to stay under
+// requests-per-minute and tokens-per-minute limits.
+type Pacer struct {
+	now    func() time.Time
+	shared SharedBuckets
+
+	mu       sync.Mutex
+	requests *tokenBucket
+	tokens   *tokenBucket
+	stats    PacerStats
+}
+
+// NewPacer creates a pacer for the given limits. A zero limit disables
+// that dimension.
+func NewPacer(rpm, tpm int) *Pacer {
+	p := &Pacer{now: time.Now}
+	now := p.now()
+	if rpm > 0 {
+		p.requests = newTokenBucket(rpm, now)
+	}
+	if tpm > 0 {
+		p.tokens = newTokenBucket(tpm, now)
+	}
+	return p
+}
+
+// SetSharedBuckets makes the pacer reserve from shared buckets. When the
+// shared store fails, the pacer falls back to its local buckets. It must be
+// called before the pacer is used.
+func (p *Pacer) SetSharedBuckets(b SharedBuckets) {
+	p.shared = b
+}
+
+// Wait blocks until a request of estimatedTokens may be sent or ctx is done.
+// It returns the time spent waiting.
+func (p *Pacer) Wait(ctx context.Context, estimatedTokens int) (time.Duration, error) {
+	delay, ok := p.reserveShared(ctx, estimatedTokens)
+
+	p.mu.Lock()
+	if !ok {
+		now := p.now()
+		if p.requests != nil {
+			delay = p.requests.reserve(1, now)
+		}
+		if p.tokens != nil {
+			if d := p.tokens.reserve(float64(estimatedTokens), now); d > delay {
+				delay = d
+			}
+		}
+	}
+	p.stats.Requests++
+	if delay > 0 {
+		p.stats.Delayed++
+		p.stats.TotalDelay += delay
+		if delay > p.stats.MaxDelay {
+			p.stats.MaxDelay = delay
+		}
+	}
+	p.mu.Unlock()
+
+	if delay == 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return delay, ctx.Err()
+	case <-timer.C:
+		return delay, nil
+	}
+}
+
+// Adjust corrects the token bucket once the actual usage of a request is
+// known. A positive delta charges more tokens, a negative delta refunds.
+func (p *Pacer) Adjust(delta int) {
+	if p.tokens == nil || delta == 0 {
+		return
+	}
+	if p.shared != nil {
+		_, err := p.shared.Reserve(context.Background(), sharedTokensBucket, float64(delta), p.tokens.rate, p.tokens.capacity)
+		if err == nil {
+			return
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tokens.tokens -= float64(delta)
+	if p.tokens.tokens > p.tokens.capacity {
+		p.tokens.tokens = p.tokens.capacity
+	}
+}
+
+// reserveShared reserves a request from the shared buckets. It reports
+// false when there are none or the shared store failed.
+func (p *Pacer) reserveShared(ctx context.Context, estimatedTokens int) (time.Duration, bool) {
+	if p.shared == nil {
+		return 0, false
+	}
+	var delay time.Duration
+	if p.requests != nil {
+		d, err := p.shared.Reserve(ctx, sharedRequestsBucket, 1, p.requests.rate, p.requests.capacity)
+		if err != nil {
+			return 0, false
+		}
+		delay = d
+	}
+	if p.tokens != nil {
+		d, err := p.shared.Reserve(ctx, sharedTokensBucket, float64(estimatedTokens), p.tokens.rate, p.tokens.capacity)
+		if err != nil {
+			return 0, false
+		}
+		if d > delay {
+			delay = d
+		}
+	}
+	return delay, true
+}
+
+// Stats returns a snapshot of pacing metrics.
+func (p *Pacer) Stats() PacerStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	if stats.Delayed > 0 {
+		stats.AverageDelay = stats.TotalDelay / time.Duration(stats.Delayed)
+	}
+	return stats
+}
+
+// PacedClient wraps a Client and paces its Analyze calls through a Pacer.
+type PacedClient struct {
+	next   Client
+	pacer  *Pacer
+	logger *zap.Logger
+}
+
+// NewPacedClient creates a Client that waits on pacer before each call.
+func NewPacedClient(next Client, pacer *Pacer, logger *zap.Logger) *PacedClient {
+	return &PacedClient{
+		next:   next,
+		pacer:  pacer,
+		logger: logger.Named("paced_client"),
+	}
+}
+
+// Analyze waits for pacing capacity and then delegates to the wrapped client.
+func (c *PacedClient) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
+	estimate := estimateTokens(log)
+
+	delay, err := c.pacer.Wait(ctx, estimate)
+	if err != nil {
+		return nil, domain.WrapError("pace_wait", domain.ErrRateLimited, false)
+	}
+	if delay > 0 {
+		c.logger.Debug("request paced",
+			zap.Duration("queue_delay", delay),
+			zap.Int("estimated_tokens", estimate),
+		)
+	}
+
+	result, err := c.next.Analyze(ctx, log)
+	if err == nil && result.Usage != nil {
+		c.pacer.Adjust(result.Usage.TotalTokens - estimate)
+	}
+	return result, err
+}
+
+// Chat waits for pacing capacity and then delegates to the wrapped client.
+func (c *PacedClient) Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error) {
+	size := len(message) + chatBytes(conversation.Messages)
+	for _, log := range conversation.Logs {
+		size += min(len(log), maxChatLogBytes)
+	}
+	estimate := size/4 + promptOverheadTokens
+
+	delay, err := c.pacer.Wait(ctx, estimate)
+	if err != nil {
+		return nil, domain.WrapError("pace_wait", domain.ErrRateLimited, false)
+	}
+	if delay > 0 {
+		c.logger.Debug("chat paced",
+			zap.Duration("queue_delay", delay),
+			zap.Int("estimated_tokens", estimate),
+		)
+	}
+
+	reply, err := c.next.Chat(ctx, conversation, message)
+	if err == nil && reply.Usage != nil {
+		c.pacer.Adjust(reply.Usage.TotalTokens - estimate)
+	}
+	return reply, err
+}
+
+// HealthCheck delegates to the wrapped client without pacing.
+func (c *PacedClient) HealthCheck(ctx context.Context) error {
+	return c.next.HealthCheck(ctx)
+}
+
+// estimateTokens approximates the tokens a request for log will consume,
+// using the common ~4 characters per token heuristic.
+func estimateTokens(log string) int {
+	return len(log)/4 + promptOverheadTokens
+}
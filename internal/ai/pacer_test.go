@@ -0,0 +1,110 @@
+// Package ai provides unit tests for outbound request pacing.
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPacer_SpreadsBursts(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 60 RPM: 1 request per second with a 10 request burst.
+	p := NewPacer(60, 0)
+	p.now = func() time.Time { return now }
+	p.requests.last = now
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 10; i++ {
+		if delay, _ := p.Wait(ctx, 0); delay != 0 {
+			t.Fatalf("request %d delayed by %v, want no delay within burst", i, delay)
+		}
+	}
+
+	delay, err := p.Wait(ctx, 0)
+	if delay != time.Second {
+		t.Errorf("delay = %v, want 1s", delay)
+	}
+	if err == nil {
+		t.Error("expected context error for delayed request with cancelled context")
+	}
+
+	stats := p.Stats()
+	if stats.Requests != 11 || stats.Delayed != 1 {
+		t.Errorf("stats = %+v, want 11 requests, 1 delayed", stats)
+	}
+}
+
+func TestPacer_TokensPerMinute(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 6000 TPM: 100 tokens per second, 1000 token burst.
+	p := NewPacer(0, 6000)
+	p.now = func() time.Time { return now }
+	p.tokens.last = now
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if delay, _ := p.Wait(ctx, 1000); delay != 0 {
+		t.Fatalf("first request delayed by %v", delay)
+	}
+	if delay, _ := p.Wait(ctx, 500); delay != 5*time.Second {
+		t.Errorf("delay = %v, want 5s", delay)
+	}
+
+	// Refund the over-estimate: the next request fits sooner.
+	p.Adjust(-500)
+	if delay, _ := p.Wait(ctx, 100); delay != time.Second {
+		t.Errorf("delay after refund = %v, want 1s", delay)
+	}
+}
+
+type fakeBuckets struct {
+	delay    time.Duration
+	err      error
+	reserved map[string]float64
+}
+
+func (b *fakeBuckets) Reserve(ctx context.Context, name string, n, ratePerSecond, capacity float64) (time.Duration, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.reserved == nil {
+		b.reserved = make(map[string]float64)
+	}
+	b.reserved[name] += n
+	return b.delay, nil
+}
+
+func TestPacer_SharedBuckets(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	shared := &fakeBuckets{delay: 3 * time.Second}
+	p := NewPacer(60, 6000)
+	p.SetSharedBuckets(shared)
+
+	if delay, _ := p.Wait(ctx, 200); delay != 3*time.Second {
+		t.Errorf("delay = %v, want shared delay 3s", delay)
+	}
+	p.Adjust(-50)
+	if shared.reserved["requests"] != 1 || shared.reserved["tokens"] != 150 {
+		t.Errorf("shared reservations = %v, want 1 request and 150 tokens", shared.reserved)
+	}
+	if p.tokens.tokens != p.tokens.capacity {
+		t.Error("local buckets should be untouched while the shared store works")
+	}
+
+	// An unavailable store falls back to the local buckets.
+	shared.err = context.DeadlineExceeded
+	if delay, _ := p.Wait(ctx, 200); delay != 0 {
+		t.Errorf("delay = %v, want local bucket with no delay", delay)
+	}
+	if p.tokens.tokens >= p.tokens.capacity {
+		t.Error("local token bucket should be charged on fallback")
+	}
+}
@@ -3,7 +3,15 @@ package ai
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 	"text/template"
+
+	"github.com/ai-devops/internal/domain"
 )
 
 // DefaultPromptBuilder implements PromptBuilder with templated prompts.
@@ -28,6 +36,7 @@ Guidelines:
 - Focus on actionable insights, not general advice
 - Consider common DevOps patterns and anti-patterns
 - Reference specific technologies when applicable
+- When the log contains several labeled sections from the same incident, correlate them: the root cause often appears in a different section than the visible failure
 - Severity levels:
   - High: Production outages, security vulnerabilities, data loss risks
   - Medium: Performance degradation, partial failures, deprecated usage
@@ -35,17 +44,44 @@ Guidelines:
 
 CRITICAL: You MUST respond with ONLY valid JSON matching the exact schema provided. No markdown, no explanations, just the JSON object.`
 
+// terraformSystemPromptText specializes the assistant for Terraform diagnostics
+// reconstructed from `terraform plan/apply -json` output.
+const terraformSystemPromptText = `You are a senior infrastructure engineer diagnosing Terraform plan and apply failures.
+
+Your responsibilities:
+1. Identify the failing provider operation and categorize the error (e.g., 'terraform_resource_conflict', 'terraform_provider_auth', 'terraform_invalid_reference')
+2. Determine the severity (Low, Medium, High) based on impact on the infrastructure
+3. Explain the root cause in terms of the affected resources and their configuration
+4. Suggest specific remediation steps (configuration changes, imports, state operations, provider credentials)
+5. Recommend prevention strategies (validation, policy checks, state locking, module versioning)
+
+Guidelines:
+- Refer to resources by their full Terraform address
+- Distinguish configuration errors from provider/API errors and state drift
+- Prefer safe remediation; call out destructive commands such as 'terraform state rm' or '-replace' explicitly
+- Severity levels:
+  - High: Failed apply leaving infrastructure partially changed, state corruption, destroyed resources
+  - Medium: Plan failures, provider errors blocking a change
+  - Low: Warnings, deprecated arguments
+
+CRITICAL: You MUST respond with ONLY valid JSON matching the exact schema provided. No markdown, no explanations, just the JSON object.`
+
 // userPromptTemplate defines how log content is presented to the AI.
 const userPromptTemplate = `Analyze the following log and return valid JSON exactly matching this schema:
 
 {
-  "error_type": "string - category of the error (e.g., 'docker_build_failure', 'permission_denied', 'connection_timeout')",
+  "error_type": "string - snake_case category of the error (e.g., 'docker_build_failure', 'permission_denied', 'connection_timeout')",
   "severity": "Low|Medium|High",
   "root_cause": "string - concise explanation of why this error occurred",
+  "confidence": "number from 0 to 1 - how sure you are of the root cause given the evidence in the log",
   "suggested_actions": ["string array - specific steps to fix the issue"],
-  "prevention_tips": ["string array - how to prevent this in the future"]
+  "commands": [{"command": "string - one copy-pasteable shell command, without a prompt; write <placeholders> for values the log does not show", "description": "string - what the command does"}],
+  "prevention_tips": ["string array - how to prevent this in the future"],
+  "references": [{"title": "string - page title", "url": "string - official documentation URL relevant to the fix"}]
 }
 
+Use an empty commands array when no shell command helps. Only include references to official documentation you are confident exists; use an empty references array otherwise.
+
 Log content:
 ---
 {{.Log}}
@@ -88,6 +124,192 @@ func (p *DefaultPromptBuilder) BuildUserPrompt(log string) string {
 	return buf.String()
 }
 
+// PromptVersion identifies the prompts built by p. It changes whenever the
+// system prompt, a specialized domain prompt or the user template changes,
+// so results produced by an older prompt can be recognized and invalidated.
+func PromptVersion(p PromptBuilder) string {
+	prompts := p.BuildSystemPrompt() + "\x00" + p.BuildUserPrompt("")
+	if builder, ok := p.(DomainPromptBuilder); ok {
+		for _, name := range builder.PromptDomains() {
+			prompt, _ := builder.BuildDomainSystemPrompt(name)
+			prompts += "\x00" + prompt
+		}
+	}
+	sum := sha256.Sum256([]byte(prompts))
+	return hex.EncodeToString(sum[:6])
+}
+
+// ComposeLogSections combines named logs from one incident into a single
+// log with labeled sections, so the model can correlate them.
+func ComposeLogSections(sections []domain.LogSection) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The following %d log sections were captured from the same incident. Correlate them to find the root cause.\n", len(sections))
+	for i, section := range sections {
+		fmt.Fprintf(&b, "\n=== Section %d/%d: %s ===\n", i+1, len(sections), section.Name)
+		b.WriteString(strings.TrimRight(section.Content, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// WithLogMetadata prefixes log with a context block describing where it came
+// from, so the model does not have to guess the platform or toolchain.
+// Returns log unchanged when meta has no fields set.
+func WithLogMetadata(log string, meta *domain.LogMetadata) string {
+	if meta == nil {
+		return log
+	}
+
+	var lines []string
+	add := func(label, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", label, value))
+		}
+	}
+	add("Pipeline", meta.Pipeline)
+	add("Stage", meta.Stage)
+	add("Repository", meta.Repository)
+	add("Branch", meta.Branch)
+	add("Namespace", meta.Namespace)
+	add("Runner OS", meta.RunnerOS)
+	add("Architecture", meta.Arch)
+
+	tools := make([]string, 0, len(meta.ToolVersions))
+	for tool := range meta.ToolVersions {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		add(tool+" version", meta.ToolVersions[tool])
+	}
+
+	if len(lines) == 0 {
+		return log
+	}
+	return "Log context:\n" + strings.Join(lines, "\n") + "\n\n" + log
+}
+
+// WithFailureContext prefixes log with the failure details extracted from it
+// (exception, stack trace runtime, exit code; see domain.Extracted*), so the
+// model starts from the most relevant part of a long log. Returns log
+// unchanged when nothing was extracted.
+func WithFailureContext(log string, extracted map[string]string) string {
+	var lines []string
+	if exception := extracted[domain.ExtractedException]; exception != "" {
+		lines = append(lines, "- Exception: "+exception)
+	}
+	if language := extracted[domain.ExtractedStackLanguage]; language != "" {
+		lines = append(lines, fmt.Sprintf("- Stack trace: %s (%s found)", language, extracted[domain.ExtractedStackTraceCount]))
+	}
+	if code := extracted[domain.ExtractedExitCode]; code != "" {
+		lines = append(lines, "- Exit code: "+code)
+	}
+	if resources := extracted[domain.ExtractedResources]; resources != "" {
+		lines = append(lines, "- Resources: "+resources)
+	}
+
+	if len(lines) == 0 {
+		return log
+	}
+	return "Extracted failure details:\n" + strings.Join(lines, "\n") + "\n\n" + log
+}
+
+// WithRuleResult prefixes log with a confident rule classification that the
+// model should keep while explaining this specific log.
+func WithRuleResult(log string, result *domain.AnalysisResult) string {
+	return fmt.Sprintf("Known pattern: this log was classified as '%s' (severity %s) by a high-confidence rule. "+
+		"Keep that error_type and severity; explain the root cause and give fix steps specific to this log, "+
+		"citing the relevant lines rather than generic advice.\n\n%s", result.ErrorType, result.Severity, log)
+}
+
+// maxRuleHints bounds the number of rule matches passed as hints.
+const maxRuleHints = 3
+
+// WithRuleHints prefixes log with the rule matches that fell below the
+// confidence threshold, strongest first, so the model can confirm or refute
+// them instead of starting from scratch. Returns log unchanged without matches.
+func WithRuleHints(log string, matches []domain.RuleMatch) string {
+	if len(matches) == 0 {
+		return log
+	}
+
+	sorted := make([]domain.RuleMatch, len(matches))
+	copy(sorted, matches)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Confidence > sorted[j].Confidence })
+
+	var lines []string
+	seen := make(map[string]bool)
+	for _, match := range sorted {
+		if match.Result == nil || seen[match.Result.ErrorType] {
+			continue
+		}
+		seen[match.Result.ErrorType] = true
+		lines = append(lines, fmt.Sprintf("- pattern suggests: %s (confidence %.2f)", match.Result.ErrorType, match.Confidence))
+		if len(lines) == maxRuleHints {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return log
+	}
+	return "Pattern hints (low-confidence rule matches; confirm or refute them):\n" +
+		strings.Join(lines, "\n") + "\n\n" + log
+}
+
+// maxExampleLogChars bounds the log of each worked example in the prompt.
+const maxExampleLogChars = 1500
+
+// WorkedExample is a solved log shown to the model before the log to analyze.
+type WorkedExample struct {
+	Log    string
+	Result *domain.AnalysisResult
+}
+
+// WithWorkedExamples prefixes log with solved examples of similar logs, so
+// the model can match their format, depth and classification. Example logs
+// are truncated to maxExampleLogChars. Returns log unchanged without examples.
+func WithWorkedExamples(log string, examples []WorkedExample) string {
+	var b strings.Builder
+	for _, example := range examples {
+		if example.Result == nil {
+			continue
+		}
+		answer, err := json.Marshal(struct {
+			ErrorType        string           `json:"error_type"`
+			Severity         domain.Severity  `json:"severity"`
+			RootCause        string           `json:"root_cause"`
+			SuggestedActions []string         `json:"suggested_actions"`
+			Commands         []domain.Command `json:"commands"`
+			PreventionTips   []string         `json:"prevention_tips"`
+		}{example.Result.ErrorType, example.Result.Severity, example.Result.RootCause, example.Result.SuggestedActions, example.Result.Commands, example.Result.PreventionTips})
+		if err != nil {
+			continue
+		}
+		exampleLog := strings.TrimSpace(example.Log)
+		if len(exampleLog) > maxExampleLogChars {
+			exampleLog = exampleLog[:maxExampleLogChars] + "\n[truncated]"
+		}
+		fmt.Fprintf(&b, "Example log:\n---\n%s\n---\nExample answer: %s\n\n", exampleLog, answer)
+	}
+	if b.Len() == 0 {
+		return log
+	}
+	return "Solved examples of similar logs (follow their format and depth; analyze the log below on its own evidence):\n\n" +
+		b.String() + log
+}
+
+// NewTerraformPromptBuilder creates a prompt builder specialized for Terraform
+// diagnostics. It shares the default user template and output schema.
+func NewTerraformPromptBuilder() (*CustomPromptBuilder, error) {
+	return NewCustomPromptBuilder(terraformSystemPromptText, userPromptTemplate)
+}
+
+// NewSystemPromptBuilder creates a prompt builder with a replacement system
+// prompt. It shares the default user template and output schema.
+func NewSystemPromptBuilder(systemPrompt string) (*CustomPromptBuilder, error) {
+	return NewCustomPromptBuilder(systemPrompt, userPromptTemplate)
+}
+
 // CustomPromptBuilder allows for custom prompt configurations.
 type CustomPromptBuilder struct {
 	systemPrompt string
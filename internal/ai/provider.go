@@ -0,0 +1,298 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/logger"
+	"github.com/ai-devops/internal/retry"
+	"go.uber.org/zap"
+)
+
+// providerTransport adapts a provider's HTTP API to providerClient, which
+// supplies prompts, retries, JSON extraction, validation and logging.
+type providerTransport interface {
+	// encodeRequest builds the provider request for the prompts. It is
+	// called once per analysis; the body is re-sent on retries.
+	encodeRequest(ctx context.Context, systemPrompt, userPrompt string) (*providerRequest, error)
+
+	// encodeChat builds the provider request for a conversation turn;
+	// messages end with the message to answer.
+	encodeChat(ctx context.Context, systemPrompt string, messages []domain.ChatMessage) (*providerRequest, error)
+
+	// decodeResponse extracts the answer text and token usage from a
+	// 200 response.
+	decodeResponse(statusCode int, body []byte) (string, *domain.TokenUsage, error)
+
+	// decodeError classifies an error response.
+	decodeError(statusCode int, body []byte) error
+
+	// healthRequest builds a cheap request that succeeds when the provider
+	// is reachable and the credentials are accepted.
+	healthRequest(ctx context.Context) (*http.Request, error)
+}
+
+// providerRequest is an encoded provider request.
+type providerRequest struct {
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// providerClient is the HTTP plumbing shared by the provider clients.
+type providerClient struct {
+	provider   config.AIProvider
+	config     *config.AIConfig
+	transport  providerTransport
+	httpClient *http.Client
+	retry      retry.Policy
+	prompter   PromptBuilder
+	validator  ResponseValidator
+	logger     *zap.Logger
+}
+
+// newProviderClient creates the shared client for a provider transport.
+func newProviderClient(provider config.AIProvider, cfg *config.AIConfig, transport providerTransport, prompter PromptBuilder, validator ResponseValidator, logger *zap.Logger) *providerClient {
+	return &providerClient{
+		provider:   provider,
+		config:     cfg,
+		transport:  transport,
+		httpClient: newHTTPClient(cfg),
+		retry:      newRetryPolicy(cfg, logger),
+		prompter:   prompter,
+		validator:  validator,
+		logger:     logger,
+	}
+}
+
+// Analyze sends a log to the provider and returns a structured analysis.
+func (c *providerClient) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
+	startTime := time.Now()
+	c.loggerFor(ctx).Debug("starting AI analysis", zap.Int("log_length", len(log)))
+
+	systemPrompt := buildSystemPrompt(ctx, c.prompter)
+	userPrompt := buildUserPrompt(ctx, c.prompter, log)
+	trace := TraceFromContext(ctx)
+	trace.recordPrompt(len(systemPrompt) + len(userPrompt))
+	capture := CaptureFromContext(ctx).recordCall(string(c.provider), c.config.Model, systemPrompt, userPrompt)
+
+	req, err := c.transport.encodeRequest(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, domain.WrapError("encode_request", err, false)
+	}
+
+	// Execute request with retry logic
+	var result *domain.AnalysisResult
+	err = c.retry.Do(ctx, func(ctx context.Context) error {
+		attemptStart := time.Now()
+		var err error
+		result, err = c.execute(ctx, req, capture)
+		trace.recordAttempt(string(c.provider), c.config.Model, c.config.BaseURL, attemptStart, err)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			return nil, domain.WrapError("context_cancelled", err, false)
+		}
+		return nil, err
+	}
+
+	fields := []zap.Field{
+		zap.Duration("duration", time.Since(startTime)),
+		zap.String("error_type", result.ErrorType),
+	}
+	if result.Usage != nil {
+		fields = append(fields,
+			zap.Int("prompt_tokens", result.Usage.PromptTokens),
+			zap.Int("completion_tokens", result.Usage.CompletionTokens),
+		)
+	}
+	c.loggerFor(ctx).Debug("AI analysis completed", fields...)
+
+	return result, nil
+}
+
+// Chat answers message in the context of conversation. The answer is free
+// text, not an analysis, so it is not validated.
+func (c *providerClient) Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error) {
+	systemPrompt := buildChatSystemPrompt(ctx, conversation)
+	messages := chatMessages(conversation, message)
+	trace := TraceFromContext(ctx)
+	trace.recordPrompt(len(systemPrompt) + chatBytes(messages))
+
+	req, err := c.transport.encodeChat(ctx, systemPrompt, messages)
+	if err != nil {
+		return nil, domain.WrapError("encode_request", err, false)
+	}
+
+	var reply *domain.ChatReply
+	err = c.retry.Do(ctx, func(ctx context.Context) error {
+		attemptStart := time.Now()
+		content, usage, err := c.send(ctx, req)
+		if err == nil && strings.TrimSpace(content) == "" {
+			err = domain.WrapError("empty_text", domain.ErrInvalidAIResponse, false)
+		}
+		trace.recordAttempt(string(c.provider), c.config.Model, c.config.BaseURL, attemptStart, err)
+		if err != nil {
+			return err
+		}
+		reply = &domain.ChatReply{Content: strings.TrimSpace(content), Usage: usage}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			return nil, domain.WrapError("context_cancelled", err, false)
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+// execute performs a single HTTP request to the provider and parses the
+// analysis. The raw answer is recorded in capture, which may be nil.
+func (c *providerClient) execute(ctx context.Context, preq *providerRequest, capture *captureCall) (*domain.AnalysisResult, error) {
+	content, usage, err := c.send(ctx, preq)
+	if err != nil {
+		capture.recordAttempt("", err)
+		return nil, err
+	}
+
+	// Extract and parse the JSON content from the response
+	result, err := c.parseAnalysisResult(content)
+	if err == nil {
+		// Validate the result
+		err = c.validator.Validate(result)
+	}
+	capture.recordAttempt(content, err)
+	if err != nil {
+		// The tokens were spent even though the answer is unusable
+		return nil, &usageError{err: err, usage: usage}
+	}
+
+	result.Usage = usage
+	return result, nil
+}
+
+// send performs a single HTTP request to the provider and returns the
+// answer text.
+func (c *providerClient) send(ctx context.Context, preq *providerRequest) (string, *domain.TokenUsage, error) {
+	c.loggerFor(ctx).Debug("sending AI request",
+		zap.String("url", maskAPIKey(preq.URL)),
+		zap.Int("body_size", len(preq.Body)),
+	)
+
+	// A request body can only be sent once
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, preq.URL, bytes.NewReader(preq.Body))
+	if err != nil {
+		return "", nil, domain.WrapError("create_request", err, false)
+	}
+	for key, values := range preq.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if requestID := domain.RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", nil, domain.WrapError("ai_timeout", domain.ErrAITimeout, true)
+		}
+		return "", nil, domain.WrapError("http_request", err, true)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, domain.WrapError("read_response", err, true)
+	}
+
+	// Handle HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, withRetryAfter(c.transport.decodeError(resp.StatusCode, body), resp.Header)
+	}
+
+	content, usage, err := c.transport.decodeResponse(resp.StatusCode, body)
+	if usage != nil {
+		usage.Model = c.config.Model
+	}
+	return content, usage, err
+}
+
+// usageError is an error of a call that returned an answer, carrying the
+// tokens the answer cost.
+type usageError struct {
+	err   error
+	usage *domain.TokenUsage
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// errorUsage returns the token usage carried by err, or nil.
+func errorUsage(err error) *domain.TokenUsage {
+	var ue *usageError
+	if errors.As(err, &ue) {
+		return ue.usage
+	}
+	return nil
+}
+
+// loggerFor returns the client's logger with the request ID in ctx.
+func (c *providerClient) loggerFor(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, c.logger)
+}
+
+// parseAnalysisResult extracts the AnalysisResult from the response content.
+func (c *providerClient) parseAnalysisResult(content string) (*domain.AnalysisResult, error) {
+	var result domain.AnalysisResult
+
+	// Try to find JSON in the content (AI might include markdown code blocks)
+	jsonContent := extractJSON(content)
+	if jsonContent == "" {
+		c.logger.Warn("could not extract JSON from AI response",
+			zap.String("content_preview", truncate(content, 200)),
+		)
+		return nil, domain.WrapError("extract_json", domain.ErrInvalidAIResponse, false)
+	}
+
+	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
+		c.logger.Warn("failed to unmarshal AI response",
+			zap.Error(err),
+			zap.String("json_content", truncate(jsonContent, 200)),
+		)
+		return nil, domain.WrapError("unmarshal_result", domain.ErrInvalidAIResponse, false)
+	}
+
+	return &result, nil
+}
+
+// HealthCheck verifies the provider is reachable.
+func (c *providerClient) HealthCheck(ctx context.Context) error {
+	req, err := c.transport.healthRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return domain.WrapError("health_check", domain.ErrAIUnavailable, true)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return domain.WrapError("health_check", domain.ErrAIUnavailable, true)
+	}
+
+	return nil
+}
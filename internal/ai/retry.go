@@ -0,0 +1,53 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/retry"
+	"go.uber.org/zap"
+)
+
+// newRetryPolicy returns the retry policy for provider requests: transient
+// errors are retried with jittered backoff, honoring Retry-After.
+func newRetryPolicy(cfg *config.AIConfig, logger *zap.Logger) retry.Policy {
+	return retry.Policy{
+		MaxRetries: cfg.MaxRetries,
+		BaseDelay:  cfg.RetryBaseDelay,
+		MaxDelay:   cfg.RetryMaxDelay,
+		MaxElapsed: cfg.RetryMaxElapsed,
+		Jitter:     cfg.RetryJitter,
+		Retryable:  domain.IsRetryable,
+		RetryAfter: providerRetryAfter,
+		OnRetry: func(n int, delay time.Duration, err error) {
+			logger.Debug("retrying AI request",
+				zap.Int("attempt", n),
+				zap.Duration("backoff", delay),
+				zap.Error(err),
+			)
+		},
+	}
+}
+
+// providerRetryAfter returns the Retry-After delay of a provider error.
+func providerRetryAfter(err error) time.Duration {
+	var pe *domain.ProviderError
+	if errors.As(err, &pe) {
+		return pe.RetryAfter
+	}
+	return 0
+}
+
+// withRetryAfter records the Retry-After header of an error response on the
+// provider error it was classified as.
+func withRetryAfter(err error, header http.Header) error {
+	var pe *domain.ProviderError
+	if errors.As(err, &pe) {
+		pe.RetryAfter = retry.ParseRetryAfter(header.Get("Retry-After"))
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+// Package ai provides unit tests for provider request retries.
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestOpenAIClient_SkipsRetriesPastDeadline(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	prompter, _ := NewDefaultPromptBuilder()
+	client := NewOpenAIClient(&config.AIConfig{
+		APIKey:     "test-api-key",
+		BaseURL:    server.URL,
+		Model:      "gpt-4o-mini",
+		Timeout:    5 * time.Second,
+		MaxTokens:  512,
+		MaxRetries: 3,
+	}, prompter, NewDefaultValidator(), zap.NewNop())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.Analyze(ctx, "test log content")
+
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1 (no retry fits in the deadline)", calls.Load())
+	}
+	if !errors.Is(err, domain.ErrAIUnavailable) {
+		t.Errorf("error = %v, want the provider error rather than a deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Analyze took %v, want it to return without waiting for the deadline", elapsed)
+	}
+}
+
+func TestOpenAIClient_HonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	prompter, _ := NewDefaultPromptBuilder()
+	client := NewOpenAIClient(&config.AIConfig{
+		APIKey:         "test-api-key",
+		BaseURL:        server.URL,
+		Model:          "gpt-4o-mini",
+		Timeout:        5 * time.Second,
+		MaxTokens:      512,
+		MaxRetries:     2,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  time.Millisecond,
+	}, prompter, NewDefaultValidator(), zap.NewNop())
+
+	start := time.Now()
+	_, err := client.Analyze(context.Background(), "test log content")
+
+	// The 400 is not retried; the wait before it came from Retry-After.
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	if !errors.Is(err, domain.ErrAIRequestRejected) {
+		t.Errorf("error = %v, want the rejected second attempt", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want the 1s Retry-After", elapsed)
+	}
+}
+
+func TestOpenAIClient_SendsRequestID(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Request-ID")
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	prompter, _ := NewDefaultPromptBuilder()
+	client := NewOpenAIClient(&config.AIConfig{
+		APIKey:    "test-api-key",
+		BaseURL:   server.URL,
+		Model:     "gpt-4o-mini",
+		Timeout:   5 * time.Second,
+		MaxTokens: 512,
+	}, prompter, NewDefaultValidator(), zap.NewNop())
+
+	client.Analyze(domain.WithRequestID(context.Background(), "req-42"), "test log content")
+	if got != "req-42" {
+		t.Errorf("X-Request-ID = %q, want req-42", got)
+	}
+}
@@ -0,0 +1,325 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// RoutingStrategy selects the order in which a Router tries its endpoints.
+type RoutingStrategy string
+
+const (
+	// RoutingRoundRobin rotates through healthy endpoints.
+	RoutingRoundRobin RoutingStrategy = "round_robin"
+
+	// RoutingLatency prefers the healthy endpoint with the lowest average
+	// latency.
+	RoutingLatency RoutingStrategy = "latency"
+)
+
+const (
+	// endpointFailureThreshold is the number of consecutive failures after
+	// which an endpoint is taken out of rotation.
+	endpointFailureThreshold = 3
+
+	// latencySmoothing is the weight of the newest sample in the latency
+	// moving average.
+	latencySmoothing = 0.2
+)
+
+// IsValid checks if the strategy is one of the allowed values.
+func (s RoutingStrategy) IsValid() bool {
+	return s == RoutingRoundRobin || s == RoutingLatency
+}
+
+// Endpoint is one provider endpoint (e.g. a region or replica) and the
+// client bound to it.
+type Endpoint struct {
+	URL    string
+	Client Client
+}
+
+// EndpointStats reports the health and performance of one endpoint.
+type EndpointStats struct {
+	URL                 string    `json:"url"`
+	Healthy             bool      `json:"healthy"`
+	Requests            uint64    `json:"requests"`
+	Failures            uint64    `json:"failures"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	AverageLatencyMS    float64   `json:"average_latency_ms"`
+	DownUntil           time.Time `json:"down_until,omitempty"`
+}
+
+type endpointState struct {
+	Endpoint
+
+	requests    uint64
+	failures    uint64
+	consecutive int
+	latency     time.Duration
+	downUntil   time.Time
+}
+
+// SharedEndpointHealth stores the times until which endpoints are out of
+// rotation outside the process, so replicas skip an endpoint another
+// replica found failing. A zero time returns the endpoint to rotation.
+type SharedEndpointHealth interface {
+	MarkDown(ctx context.Context, url string, until time.Time) error
+	DownUntil(ctx context.Context, urls []string) (map[string]time.Time, error)
+}
+
+// Router is a Client that spreads requests over several endpoints of the
+// same provider. Endpoints that fail endpointFailureThreshold times in a row
+// are skipped for a cooldown period; requests that fail with a transient
+// error are retried on the next endpoint. It is safe for concurrent use.
+type Router struct {
+	strategy RoutingStrategy
+	cooldown time.Duration
+	logger   *zap.Logger
+	shared   SharedEndpointHealth
+
+	mu        sync.Mutex
+	endpoints []*endpointState
+	next      int
+	now       func() time.Time
+}
+
+// NewRouter creates a Router over endpoints.
+func NewRouter(endpoints []Endpoint, strategy RoutingStrategy, cooldown time.Duration, logger *zap.Logger) *Router {
+	states := make([]*endpointState, len(endpoints))
+	for i, ep := range endpoints {
+		states[i] = &endpointState{Endpoint: ep}
+	}
+	return &Router{
+		strategy:  strategy,
+		cooldown:  cooldown,
+		logger:    logger.Named("ai_router"),
+		endpoints: states,
+		now:       time.Now,
+	}
+}
+
+// SetSharedHealth makes the router share endpoint cooldowns through h. It
+// must be called before the router is used.
+func (r *Router) SetSharedHealth(h SharedEndpointHealth) {
+	r.shared = h
+}
+
+// Analyze sends log to the preferred endpoint, failing over to the others on
+// transient errors.
+func (r *Router) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
+	var result *domain.AnalysisResult
+	err := r.do(ctx, func(client Client) error {
+		var err error
+		result, err = client.Analyze(ctx, log)
+		return err
+	})
+	return result, err
+}
+
+// Chat sends the conversation turn to the preferred endpoint, failing over
+// to the others on transient errors.
+func (r *Router) Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error) {
+	var reply *domain.ChatReply
+	err := r.do(ctx, func(client Client) error {
+		var err error
+		reply, err = client.Chat(ctx, conversation, message)
+		return err
+	})
+	return reply, err
+}
+
+// do calls call with the client of each endpoint in order until one
+// succeeds or fails with an error that does not warrant failover.
+func (r *Router) do(ctx context.Context, call func(Client) error) error {
+	r.syncShared(ctx)
+
+	var lastErr error
+	for _, ep := range r.order() {
+		start := time.Now()
+		err := call(ep.Client)
+		if until, changed := r.record(ep, time.Since(start), err); changed {
+			r.publish(ctx, ep.URL, until)
+		}
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !failover(err) {
+			return err
+		}
+
+		r.logger.Warn("AI endpoint failed, trying next",
+			zap.String("endpoint", ep.URL),
+			zap.Error(err),
+		)
+		lastErr = err
+	}
+	return lastErr
+}
+
+// HealthCheck succeeds if any endpoint is reachable. Reachable endpoints are
+// returned to rotation.
+func (r *Router) HealthCheck(ctx context.Context) error {
+	var errs []error
+	for _, ep := range r.endpoints {
+		err := ep.Client.HealthCheck(ctx)
+		if err == nil {
+			r.mu.Lock()
+			ep.consecutive = 0
+			wasDown := !ep.downUntil.IsZero()
+			ep.downUntil = time.Time{}
+			r.mu.Unlock()
+			if wasDown {
+				r.publish(ctx, ep.URL, time.Time{})
+			}
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns per-endpoint metrics in configuration order.
+func (r *Router) Stats() []EndpointStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	stats := make([]EndpointStats, len(r.endpoints))
+	for i, ep := range r.endpoints {
+		stats[i] = EndpointStats{
+			URL:                 ep.URL,
+			Healthy:             !now.Before(ep.downUntil),
+			Requests:            ep.requests,
+			Failures:            ep.failures,
+			ConsecutiveFailures: ep.consecutive,
+			AverageLatencyMS:    float64(ep.latency) / float64(time.Millisecond),
+		}
+		if now.Before(ep.downUntil) {
+			stats[i].DownUntil = ep.downUntil
+		}
+	}
+	return stats
+}
+
+// order returns the endpoints in the order they should be tried: healthy
+// endpoints by strategy, then endpoints in cooldown, soonest available
+// first, as a last resort.
+func (r *Router) order() []*endpointState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var healthy, down []*endpointState
+	for _, ep := range r.endpoints {
+		if now.Before(ep.downUntil) {
+			down = append(down, ep)
+		} else {
+			healthy = append(healthy, ep)
+		}
+	}
+
+	switch r.strategy {
+	case RoutingLatency:
+		// Unmeasured endpoints (zero latency) sort first so they get sampled.
+		sort.SliceStable(healthy, func(i, j int) bool { return healthy[i].latency < healthy[j].latency })
+	default:
+		if len(healthy) > 0 {
+			start := r.next % len(healthy)
+			healthy = append(healthy[start:], healthy[:start]...)
+			r.next++
+		}
+	}
+	sort.SliceStable(down, func(i, j int) bool { return down[i].downUntil.Before(down[j].downUntil) })
+
+	return append(healthy, down...)
+}
+
+// record updates endpoint metrics after a call. It returns the endpoint's
+// new down time when the call took it out of or returned it to rotation.
+func (r *Router) record(ep *endpointState, latency time.Duration, err error) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ep.requests++
+	if err == nil || !failover(err) {
+		// Request-specific errors (content filtered, context length, ...)
+		// say nothing about the endpoint's health.
+		wasDown := !ep.downUntil.IsZero()
+		ep.consecutive = 0
+		ep.downUntil = time.Time{}
+		if ep.latency == 0 {
+			ep.latency = latency
+		} else {
+			ep.latency += time.Duration(latencySmoothing * float64(latency-ep.latency))
+		}
+		return time.Time{}, wasDown
+	}
+
+	ep.failures++
+	ep.consecutive++
+	if ep.consecutive >= endpointFailureThreshold {
+		ep.downUntil = r.now().Add(r.cooldown)
+		r.logger.Warn("AI endpoint taken out of rotation",
+			zap.String("endpoint", ep.URL),
+			zap.Int("consecutive_failures", ep.consecutive),
+			zap.Duration("cooldown", r.cooldown),
+		)
+		return ep.downUntil, true
+	}
+	return time.Time{}, false
+}
+
+// syncShared adopts cooldowns set by other replicas that outlast the local
+// ones. Errors leave the local state in charge.
+func (r *Router) syncShared(ctx context.Context) {
+	if r.shared == nil {
+		return
+	}
+	urls := make([]string, len(r.endpoints))
+	for i, ep := range r.endpoints {
+		urls[i] = ep.URL
+	}
+	down, err := r.shared.DownUntil(ctx, urls)
+	if err != nil {
+		r.logger.Debug("failed to read shared endpoint health", zap.Error(err))
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ep := range r.endpoints {
+		if until, ok := down[ep.URL]; ok && until.After(ep.downUntil) {
+			ep.downUntil = until
+		}
+	}
+}
+
+// publish shares an endpoint's new down time with other replicas.
+func (r *Router) publish(ctx context.Context, url string, until time.Time) {
+	if r.shared == nil {
+		return
+	}
+	if err := r.shared.MarkDown(ctx, url, until); err != nil {
+		r.logger.Warn("failed to share endpoint health",
+			zap.String("endpoint", url),
+			zap.Error(err),
+		)
+	}
+}
+
+// failover reports whether err indicates an endpoint problem worth trying
+// another endpoint for.
+func failover(err error) bool {
+	return domain.IsRetryable(err) ||
+		errors.Is(err, domain.ErrAIUnavailable) ||
+		errors.Is(err, domain.ErrAITimeout) ||
+		errors.Is(err, domain.ErrRateLimited)
+}
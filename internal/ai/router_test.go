@@ -0,0 +1,173 @@
+// Package ai provides unit tests for endpoint routing.
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+type endpointClient struct {
+	err   error
+	calls int
+}
+
+func (c *endpointClient) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &domain.AnalysisResult{ErrorType: "ok"}, nil
+}
+
+func (c *endpointClient) Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &domain.ChatReply{Content: "ok"}, nil
+}
+
+func (c *endpointClient) HealthCheck(ctx context.Context) error { return c.err }
+
+func TestRouter_RoundRobinAndFailover(t *testing.T) {
+	a := &endpointClient{}
+	b := &endpointClient{err: domain.WrapError("ai_request", domain.ErrAIUnavailable, true)}
+	r := NewRouter([]Endpoint{{URL: "a", Client: a}, {URL: "b", Client: b}}, RoutingRoundRobin, time.Minute, zap.NewNop())
+
+	for i := 0; i < 6; i++ {
+		if _, err := r.Analyze(context.Background(), "log"); err != nil {
+			t.Fatalf("Analyze() error = %v", err)
+		}
+	}
+
+	// b fails over to a each time it is first, until it is taken out of rotation.
+	if b.calls != endpointFailureThreshold {
+		t.Errorf("b calls = %d, want %d", b.calls, endpointFailureThreshold)
+	}
+	if a.calls != 6 {
+		t.Errorf("a calls = %d, want 6", a.calls)
+	}
+
+	stats := r.Stats()
+	if !stats[0].Healthy || stats[1].Healthy {
+		t.Errorf("health = %v/%v, want true/false", stats[0].Healthy, stats[1].Healthy)
+	}
+	if stats[1].Failures != endpointFailureThreshold {
+		t.Errorf("b failures = %d, want %d", stats[1].Failures, endpointFailureThreshold)
+	}
+}
+
+func TestRouter_ChatFailover(t *testing.T) {
+	a := &endpointClient{err: domain.WrapError("ai_request", domain.ErrAIUnavailable, true)}
+	b := &endpointClient{}
+	r := NewRouter([]Endpoint{{URL: "a", Client: a}, {URL: "b", Client: b}}, RoutingRoundRobin, time.Minute, zap.NewNop())
+
+	reply, err := r.Chat(context.Background(), &domain.Conversation{}, "why?")
+	if err != nil || reply.Content != "ok" {
+		t.Fatalf("Chat() = %+v, %v, want the reply of b", reply, err)
+	}
+	if a.calls != 1 || b.calls != 1 {
+		t.Errorf("calls = %d/%d, want 1/1", a.calls, b.calls)
+	}
+	if stats := r.Stats(); stats[0].Failures != 1 {
+		t.Errorf("a failures = %d, want 1", stats[0].Failures)
+	}
+}
+
+func TestRouter_NoFailoverOnRequestErrors(t *testing.T) {
+	filtered := &domain.ProviderError{Provider: "openai", Kind: domain.ErrContentFiltered}
+	a := &endpointClient{err: filtered}
+	b := &endpointClient{}
+	r := NewRouter([]Endpoint{{URL: "a", Client: a}, {URL: "b", Client: b}}, RoutingRoundRobin, time.Minute, zap.NewNop())
+
+	if _, err := r.Analyze(context.Background(), "log"); !errors.Is(err, domain.ErrContentFiltered) {
+		t.Errorf("Analyze() error = %v, want content filtered", err)
+	}
+	if b.calls != 0 {
+		t.Error("request-specific errors must not fail over")
+	}
+	if stats := r.Stats(); !stats[0].Healthy || stats[0].Failures != 0 {
+		t.Errorf("endpoint a should stay healthy: %+v", stats[0])
+	}
+}
+
+func TestRouter_LatencyAndCooldown(t *testing.T) {
+	slow, fast := &endpointClient{}, &endpointClient{}
+	r := NewRouter([]Endpoint{{URL: "slow", Client: slow}, {URL: "fast", Client: fast}}, RoutingLatency, time.Minute, zap.NewNop())
+	r.endpoints[0].latency = 200 * time.Millisecond
+	r.endpoints[1].latency = 50 * time.Millisecond
+
+	if order := r.order(); order[0].URL != "fast" {
+		t.Errorf("first endpoint = %s, want fast", order[0].URL)
+	}
+
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	r.endpoints[1].downUntil = now.Add(time.Second)
+	if order := r.order(); order[0].URL != "slow" || order[1].URL != "fast" {
+		t.Errorf("endpoint in cooldown should be tried last, got %s, %s", order[0].URL, order[1].URL)
+	}
+
+	r.now = func() time.Time { return now.Add(2 * time.Second) }
+	if order := r.order(); order[0].URL != "fast" {
+		t.Error("endpoint should return to rotation after cooldown")
+	}
+}
+
+type fakeEndpointHealth struct {
+	down map[string]time.Time
+}
+
+func (h *fakeEndpointHealth) MarkDown(ctx context.Context, url string, until time.Time) error {
+	if until.IsZero() {
+		delete(h.down, url)
+	} else {
+		h.down[url] = until
+	}
+	return nil
+}
+
+func (h *fakeEndpointHealth) DownUntil(ctx context.Context, urls []string) (map[string]time.Time, error) {
+	return h.down, nil
+}
+
+func TestRouter_SharedHealth(t *testing.T) {
+	shared := &fakeEndpointHealth{down: make(map[string]time.Time)}
+	failing := &endpointClient{err: domain.WrapError("ai_request", domain.ErrAIUnavailable, true)}
+	healthy := &endpointClient{}
+
+	// One replica trips the failing endpoint and publishes its cooldown.
+	r1 := NewRouter([]Endpoint{{URL: "a", Client: failing}, {URL: "b", Client: healthy}}, RoutingLatency, time.Minute, zap.NewNop())
+	r1.SetSharedHealth(shared)
+	for i := 0; i < endpointFailureThreshold; i++ {
+		r1.Analyze(context.Background(), "log")
+	}
+	if _, ok := shared.down["a"]; !ok {
+		t.Fatal("tripped endpoint should be published")
+	}
+
+	// Another replica skips it without failing first.
+	other := &endpointClient{}
+	r2 := NewRouter([]Endpoint{{URL: "a", Client: other}, {URL: "b", Client: &endpointClient{}}}, RoutingLatency, time.Minute, zap.NewNop())
+	r2.SetSharedHealth(shared)
+	if _, err := r2.Analyze(context.Background(), "log"); err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if other.calls != 0 {
+		t.Errorf("endpoint down on another replica was called %d times", other.calls)
+	}
+
+	// A successful health check returns it to rotation everywhere.
+	failing.err = nil
+	if err := r1.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	if _, ok := shared.down["a"]; ok {
+		t.Error("recovered endpoint should be cleared from shared health")
+	}
+}
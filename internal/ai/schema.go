@@ -0,0 +1,154 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import "github.com/ai-devops/internal/domain"
+
+// analysisSchemaName is the schema name reported to providers that require one.
+const analysisSchemaName = "analysis_result"
+
+// openAIResponseFormat is the response_format payload for OpenAI's
+// structured output mode. It forces the model to emit an AnalysisResult.
+type openAIResponseFormat struct {
+	Type       string           `json:"type"`
+	JSONSchema openAIJSONSchema `json:"json_schema"`
+}
+
+// openAIJSONSchema describes a named JSON schema for OpenAI.
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Strict bool           `json:"strict"`
+	Schema map[string]any `json:"schema"`
+}
+
+// newOpenAIResponseFormat builds the json_schema response format for AnalysisResult.
+// Strict mode requires every property to be listed as required and
+// additionalProperties to be false. Deep analyses add explanation.
+func newOpenAIResponseFormat(level domain.DetailLevel) *openAIResponseFormat {
+	stringArray := map[string]any{
+		"type":  "array",
+		"items": map[string]any{"type": "string"},
+	}
+
+	commands := map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"command":     map[string]any{"type": "string"},
+				"description": map[string]any{"type": "string"},
+			},
+			"required":             []string{"command", "description"},
+			"additionalProperties": false,
+		},
+	}
+
+	references := map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"title": map[string]any{"type": "string"},
+				"url":   map[string]any{"type": "string"},
+			},
+			"required":             []string{"title", "url"},
+			"additionalProperties": false,
+		},
+	}
+
+	format := &openAIResponseFormat{
+		Type: "json_schema",
+		JSONSchema: openAIJSONSchema{
+			Name:   analysisSchemaName,
+			Strict: true,
+			Schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"error_type": map[string]any{"type": "string"},
+					"severity": map[string]any{
+						"type": "string",
+						"enum": []string{"Low", "Medium", "High"},
+					},
+					"root_cause":        map[string]any{"type": "string"},
+					"confidence":        map[string]any{"type": "number"},
+					"suggested_actions": stringArray,
+					"commands":          commands,
+					"prevention_tips":   stringArray,
+					"references":        references,
+				},
+				"required": []string{
+					"error_type", "severity", "root_cause", "confidence", "suggested_actions", "commands", "prevention_tips", "references",
+				},
+				"additionalProperties": false,
+			},
+		},
+	}
+	if level == domain.DetailDeep {
+		schema := format.JSONSchema.Schema
+		schema["properties"].(map[string]any)["explanation"] = map[string]any{"type": "string"}
+		schema["required"] = append(schema["required"].([]string), "explanation")
+	}
+	return format
+}
+
+// newGeminiResponseSchema builds the responseSchema for Gemini's JSON mode.
+// Gemini uses an OpenAPI subset with upper-case type names. Deep analyses
+// add explanation.
+func newGeminiResponseSchema(level domain.DetailLevel) map[string]any {
+	stringArray := map[string]any{
+		"type":  "ARRAY",
+		"items": map[string]any{"type": "STRING"},
+	}
+
+	commands := map[string]any{
+		"type": "ARRAY",
+		"items": map[string]any{
+			"type": "OBJECT",
+			"properties": map[string]any{
+				"command":     map[string]any{"type": "STRING"},
+				"description": map[string]any{"type": "STRING"},
+			},
+			"required": []string{"command", "description"},
+		},
+	}
+
+	references := map[string]any{
+		"type": "ARRAY",
+		"items": map[string]any{
+			"type": "OBJECT",
+			"properties": map[string]any{
+				"title": map[string]any{"type": "STRING"},
+				"url":   map[string]any{"type": "STRING"},
+			},
+			"required": []string{"title", "url"},
+		},
+	}
+
+	schema := map[string]any{
+		"type": "OBJECT",
+		"properties": map[string]any{
+			"error_type": map[string]any{"type": "STRING"},
+			"severity": map[string]any{
+				"type": "STRING",
+				"enum": []string{"Low", "Medium", "High"},
+			},
+			"root_cause":        map[string]any{"type": "STRING"},
+			"confidence":        map[string]any{"type": "NUMBER"},
+			"suggested_actions": stringArray,
+			"commands":          commands,
+			"prevention_tips":   stringArray,
+			"references":        references,
+		},
+		"required": []string{
+			"error_type", "severity", "root_cause", "confidence", "suggested_actions", "commands", "prevention_tips", "references",
+		},
+		"propertyOrdering": []string{
+			"error_type", "severity", "root_cause", "confidence", "suggested_actions", "commands", "prevention_tips", "references",
+		},
+	}
+	if level == domain.DetailDeep {
+		schema["properties"].(map[string]any)["explanation"] = map[string]any{"type": "STRING"}
+		schema["required"] = append(schema["required"].([]string), "explanation")
+		schema["propertyOrdering"] = append(schema["propertyOrdering"].([]string), "explanation")
+	}
+	return schema
+}
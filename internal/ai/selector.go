@@ -0,0 +1,113 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// complexErrorPattern matches the lines counted towards a log's complexity.
+var complexErrorPattern = regexp.MustCompile(`(?i)\b(error|err|fail(ed|ure)?|fatal|panic|exception|traceback)\b`)
+
+// ModelSelectorStats counts the analyses sent to each model.
+type ModelSelectorStats struct {
+	Cheap     uint64 `json:"cheap"`
+	Strong    uint64 `json:"strong"`
+	Escalated uint64 `json:"escalated"`
+}
+
+// ModelSelector sends short, simple logs to a cheap model and the rest to a
+// stronger one. A log is simple when it is at most maxBytes long and has at
+// most maxErrorLines error lines.
+type ModelSelector struct {
+	cheap         Client
+	strong        Client
+	maxBytes      int
+	maxErrorLines int
+	logger        *zap.Logger
+
+	cheapCount     atomic.Uint64
+	strongCount    atomic.Uint64
+	escalatedCount atomic.Uint64
+}
+
+// NewModelSelector creates a model selector.
+func NewModelSelector(cheap, strong Client, maxBytes, maxErrorLines int, logger *zap.Logger) *ModelSelector {
+	return &ModelSelector{
+		cheap:         cheap,
+		strong:        strong,
+		maxBytes:      maxBytes,
+		maxErrorLines: maxErrorLines,
+		logger:        logger.Named("model_selector"),
+	}
+}
+
+// Analyze implements Client. When the cheap model cannot produce a valid
+// analysis or the log exceeds its context, the strong model is asked.
+func (s *ModelSelector) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
+	if !s.simple(log) {
+		s.strongCount.Add(1)
+		return s.strong.Analyze(ctx, log)
+	}
+
+	s.cheapCount.Add(1)
+	result, err := s.cheap.Analyze(ctx, log)
+	if err == nil || !(errors.Is(err, domain.ErrInvalidAIResponse) || errors.Is(err, domain.ErrContextLengthExceeded)) {
+		return result, err
+	}
+
+	s.logger.Debug("cheap model failed, escalating to the strong model", zap.Error(err))
+	s.escalatedCount.Add(1)
+	failed := errorUsage(err)
+	result, err = s.strong.Analyze(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+	// Charge the tokens of the failed cheap attempt along with the escalation
+	result.Usage = addUsage(failed, result.Usage)
+	return result, nil
+}
+
+// Chat implements Client. Conversations carry whole logs and their
+// analyses, so they always go to the strong model.
+func (s *ModelSelector) Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error) {
+	s.strongCount.Add(1)
+	return s.strong.Chat(ctx, conversation, message)
+}
+
+// simple reports whether log is short and simple enough for the cheap model.
+func (s *ModelSelector) simple(log string) bool {
+	if len(log) > s.maxBytes {
+		return false
+	}
+	errorLines := 0
+	for _, line := range strings.Split(log, "\n") {
+		if complexErrorPattern.MatchString(line) {
+			errorLines++
+			if errorLines > s.maxErrorLines {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Stats returns how many analyses went to each model.
+func (s *ModelSelector) Stats() ModelSelectorStats {
+	return ModelSelectorStats{
+		Cheap:     s.cheapCount.Load(),
+		Strong:    s.strongCount.Load(),
+		Escalated: s.escalatedCount.Load(),
+	}
+}
+
+// HealthCheck implements Client.
+func (s *ModelSelector) HealthCheck(ctx context.Context) error {
+	return s.strong.HealthCheck(ctx)
+}
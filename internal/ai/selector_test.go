@@ -0,0 +1,105 @@
+// Package ai provides unit tests for model selection by log complexity.
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestModelSelector_Analyze(t *testing.T) {
+	cheapResult := &domain.AnalysisResult{ErrorType: "cheap"}
+	strongResult := &domain.AnalysisResult{ErrorType: "strong"}
+	simpleLog := "npm ERR! code E404\nnpm ERR! 404 Not Found - GET https://registry.npmjs.org/left-pad-x"
+
+	tests := []struct {
+		name          string
+		log           string
+		cheapErr      error
+		wantErrorType string
+		wantErr       error
+		wantStats     ModelSelectorStats
+	}{
+		{
+			name:          "short simple log",
+			log:           simpleLog,
+			wantErrorType: "cheap",
+			wantStats:     ModelSelectorStats{Cheap: 1},
+		},
+		{
+			name:          "long log",
+			log:           simpleLog + strings.Repeat("\nstep output", 100),
+			wantErrorType: "strong",
+			wantStats:     ModelSelectorStats{Strong: 1},
+		},
+		{
+			name:          "several errors",
+			log:           "error: a\nerror: b\nFATAL: c",
+			wantErrorType: "strong",
+			wantStats:     ModelSelectorStats{Strong: 1},
+		},
+		{
+			name:          "invalid cheap answer escalates",
+			log:           simpleLog,
+			cheapErr:      domain.WrapError("extract_json", domain.ErrInvalidAIResponse, false),
+			wantErrorType: "strong",
+			wantStats:     ModelSelectorStats{Cheap: 1, Escalated: 1},
+		},
+		{
+			name:      "provider errors are returned",
+			log:       simpleLog,
+			cheapErr:  domain.WrapError("ai_request", domain.ErrRateLimited, true),
+			wantErr:   domain.ErrRateLimited,
+			wantStats: ModelSelectorStats{Cheap: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cheap := &opinionClient{result: cheapResult, err: tt.cheapErr}
+			strong := &opinionClient{result: strongResult}
+			selector := NewModelSelector(cheap, strong, 500, 2, zap.NewNop())
+
+			result, err := selector.Analyze(context.Background(), tt.log)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Analyze() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			} else if result.ErrorType != tt.wantErrorType {
+				t.Errorf("answered by %q, want %q", result.ErrorType, tt.wantErrorType)
+			}
+			if got := selector.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestModelSelector_EscalationUsage(t *testing.T) {
+	cheap := &opinionClient{err: &usageError{
+		err:   domain.WrapError("validate", domain.ErrInvalidAIResponse, false),
+		usage: &domain.TokenUsage{Model: "gpt-4o-mini", PromptTokens: 800, CompletionTokens: 50, TotalTokens: 850},
+	}}
+	strong := &opinionClient{result: &domain.AnalysisResult{
+		ErrorType: "strong",
+		Usage:     &domain.TokenUsage{Model: "gpt-4o", PromptTokens: 900, CompletionTokens: 200, TotalTokens: 1100},
+	}}
+	selector := NewModelSelector(cheap, strong, 500, 2, zap.NewNop())
+
+	result, err := selector.Analyze(context.Background(), "npm ERR! code E404")
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 1950 || len(result.Usage.Calls) != 2 {
+		t.Fatalf("Usage = %+v, want both calls", result.Usage)
+	}
+	if result.Usage.Calls[0].Model != "gpt-4o-mini" || result.Usage.Calls[1].Model != "gpt-4o" {
+		t.Errorf("call models = %q, %q", result.Usage.Calls[0].Model, result.Usage.Calls[1].Model)
+	}
+}
@@ -0,0 +1,191 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Prompt domains with specialized system prompts.
+const (
+	PromptDomainKubernetes = "kubernetes"
+	PromptDomainTerraform  = "terraform"
+	PromptDomainIaC        = "iac"
+	PromptDomainNPM        = "npm"
+	PromptDomainDocker     = "docker"
+	PromptDomainCI         = "ci"
+)
+
+// DomainPromptBuilder is implemented by prompt builders that have
+// specialized system prompts for some domains.
+type DomainPromptBuilder interface {
+	// BuildDomainSystemPrompt returns the system prompt for name, or false
+	// when the builder has no prompt specialized for it.
+	BuildDomainSystemPrompt(name string) (string, bool)
+
+	// PromptDomains returns the names of the specialized prompts.
+	PromptDomains() []string
+}
+
+type promptDomainKey struct{}
+
+// WithPromptDomain returns a context asking AI clients to use the system
+// prompt specialized for name, if their prompt builder has one.
+func WithPromptDomain(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, promptDomainKey{}, name)
+}
+
+// PromptDomainFromContext returns the prompt domain carried by ctx, or "".
+func PromptDomainFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(promptDomainKey{}).(string)
+	return name
+}
+
+// buildSystemPrompt returns the system prompt for the domain requested in
+// ctx, falling back to the generic prompt.
+func buildSystemPrompt(ctx context.Context, prompter PromptBuilder) string {
+	if name := PromptDomainFromContext(ctx); name != "" {
+		if builder, ok := prompter.(DomainPromptBuilder); ok {
+			if prompt, ok := builder.BuildDomainSystemPrompt(name); ok {
+				return prompt
+			}
+		}
+	}
+	return prompter.BuildSystemPrompt()
+}
+
+// RoutePromptDomain returns the prompt domain indicated by the strongest
+// rule match whose error type belongs to one, or "" for the generic prompt.
+func RoutePromptDomain(matches []domain.RuleMatch) string {
+	sorted := make([]domain.RuleMatch, len(matches))
+	copy(sorted, matches)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Confidence > sorted[j].Confidence })
+
+	for _, match := range sorted {
+		if match.Result == nil {
+			continue
+		}
+		if name := promptDomainFor(match.Result.ErrorType); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// promptDomainFor maps a canonical error type to its prompt domain.
+func promptDomainFor(errorType string) string {
+	info, ok := domain.LookupErrorType(errorType)
+	if !ok {
+		return ""
+	}
+	switch {
+	case info.Category == domain.ErrorCategoryKubernetes:
+		return PromptDomainKubernetes
+	case info.Subcategory == "terraform":
+		return PromptDomainTerraform
+	case info.Subcategory == "pulumi" || info.Subcategory == "cloudformation" || info.Subcategory == "iac":
+		return PromptDomainIaC
+	case info.Subcategory == "npm" || info.Subcategory == "yarn" || info.Subcategory == "pnpm":
+		return PromptDomainNPM
+	case info.Category == domain.ErrorCategoryContainer:
+		return PromptDomainDocker
+	case info.Subcategory == "ci":
+		return PromptDomainCI
+	}
+	return ""
+}
+
+// domainPromptTexts extend the generic system prompt with deeper domain
+// instructions and worked examples.
+var domainPromptTexts = map[string]string{
+	PromptDomainKubernetes: `Domain focus: Kubernetes workloads.
+
+- Read pod status reasons (CrashLoopBackOff, ImagePullBackOff, OOMKilled, Evicted, FailedScheduling) and container exit codes (137 = SIGKILL/OOM, 143 = SIGTERM, 1 = application error) before the application output.
+- Distinguish the failing layer: image (registry, tag, pull secret), scheduling (requests, node selectors, taints, quotas), probes (liveness/readiness timing versus a genuinely unhealthy app) and the application itself.
+- Give kubectl commands that confirm the diagnosis (kubectl describe pod, kubectl logs --previous, kubectl get events --sort-by=.lastTimestamp) before changing manifests.
+- Prefer manifest fixes (resources, probe thresholds, imagePullSecrets) over manual pod deletion.
+
+Example:
+Log: "Last State: Terminated Reason: OOMKilled Exit Code: 137 ... Restart Count: 6"
+Answer: {"error_type": "out_of_memory", "severity": "High", "root_cause": "The container exceeded its memory limit and was killed by the kernel (exit code 137), causing repeated restarts.", "suggested_actions": ["Check usage with 'kubectl top pod' and 'kubectl describe pod' to compare against resources.limits.memory", "Raise the memory limit or fix the leak shown by the application's memory profile"], "commands": [{"command": "kubectl describe pod <pod>", "description": "Show the last state, exit code and memory limit"}, {"command": "kubectl top pod <pod>", "description": "Show the current memory usage"}], "prevention_tips": ["Set requests and limits from observed usage and alert on container_memory_working_set_bytes near the limit"]}`,
+
+	PromptDomainTerraform: `Domain focus: Terraform.
+
+- Identify whether the failure comes from configuration (validation, unknown arguments), the provider/API (quota, permissions, conflicts), state (locks, drift, missing resources) or provider installation.
+- Refer to resources by their full address (module.x.aws_instance.y) and name the backend when state is involved.
+- Call out destructive commands such as 'terraform state rm', 'force-unlock' or '-replace' explicitly and only suggest them with the condition that makes them safe.
+
+Example:
+Log: "Error: Error acquiring the state lock ... Lock Info: ID: 9a1c... Operation: OperationTypeApply Who: runner@ci-42"
+Answer: {"error_type": "terraform_state_locked", "severity": "Medium", "root_cause": "Another apply (runner@ci-42) holds the state lock, or a cancelled run left it behind.", "suggested_actions": ["Check whether the run on ci-42 is still active and wait for it", "If it is gone, release the lock with 'terraform force-unlock 9a1c...' after confirming no other apply is running"], "commands": [{"command": "terraform force-unlock 9a1c...", "description": "Release the stale lock once no other apply is running"}], "prevention_tips": ["Serialize applies per workspace in CI and avoid cancelling jobs mid-apply"]}`,
+
+	PromptDomainIaC: `Domain focus: infrastructure as code (Terraform, Pulumi, CloudFormation).
+
+- Name the tool and the phase that failed: init/provider installation, plan or preview (configuration, references, validation), apply or update (provider/API errors such as quotas, permissions, conflicts), or state (locks, drift, pending operations, stacks stuck in UPDATE_ROLLBACK_FAILED or ROLLBACK_COMPLETE).
+- Identify every offending resource by its address: the Terraform address (module.x.aws_instance.y[0]), the Pulumi URN or "<type> (<name>)", or the CloudFormation logical ID. Add them to the answer as an "evidence" array of strings, in addition to the schema fields, and name them in the root cause.
+- Use "Extracted failure details: Resources" when present, but only keep the resources the error is about.
+- For provider authentication errors, name the provider and the credential source it tried (environment, profile, OIDC role, service principal); for drift, name the changed attributes and whether to import, refresh or revert them.
+- Call out destructive or state-changing commands ('terraform state rm', 'force-unlock', '-replace', 'pulumi cancel', 'pulumi state delete', 'continue-update-rollback --resources-to-skip') explicitly and only suggest them with the condition that makes them safe.
+
+Example:
+Log: "aws:s3:Bucket (assets):\n    error: creating urn:pulumi:dev::infra::aws:s3/bucket:Bucket::assets: BucketAlreadyExists"
+Answer: {"error_type": "pulumi_update_failure", "severity": "Medium", "root_cause": "The bucket 'assets' (urn:pulumi:dev::infra::aws:s3/bucket:Bucket::assets) could not be created because the name is already taken; S3 bucket names are global.", "suggested_actions": ["Let Pulumi auto-name the bucket or choose a unique name", "If the bucket is yours, import it instead of creating it"], "commands": [{"command": "pulumi import aws:s3/bucket:Bucket assets <bucket-name>", "description": "Adopt the existing bucket into the stack"}], "prevention_tips": ["Use auto-naming or a stack-specific prefix for globally unique names"], "evidence": ["urn:pulumi:dev::infra::aws:s3/bucket:Bucket::assets"]}`,
+
+	PromptDomainNPM: `Domain focus: Node.js package installs (npm, yarn, pnpm).
+
+- Read the npm error code (ERESOLVE, ENOTFOUND, E401/E403, EINTEGRITY, ETARGET, EACCES) and the package that caused it before anything else.
+- Distinguish dependency resolution conflicts, registry/network/auth problems, lockfile mismatches and native build failures (node-gyp, missing compilers, Node version).
+- Prefer reproducible fixes (updating the lockfile, pinning versions, .npmrc registry auth, engines field) over '--force' or '--legacy-peer-deps'; when suggesting those flags, say what they hide.
+
+Example:
+Log: "npm ERR! code ERESOLVE ... Could not resolve dependency: peer react@\"^17.0.0\" from react-dom@17.0.2 ... Found: react@18.2.0"
+Answer: {"error_type": "npm_install_failure", "severity": "Medium", "root_cause": "react-dom@17.0.2 requires react 17 as a peer dependency but react 18.2.0 is installed, so npm cannot resolve the tree.", "suggested_actions": ["Upgrade react-dom to 18.x to match react 18", "Run 'npm ls react react-dom' to find other packages pinning the old version"], "commands": [{"command": "npm ls react react-dom", "description": "Show which packages depend on each version"}, {"command": "npm install react-dom@18", "description": "Upgrade react-dom to match react 18"}], "prevention_tips": ["Upgrade peer-coupled packages together and commit the lockfile"]}`,
+
+	PromptDomainDocker: `Domain focus: Docker builds, daemons and registries.
+
+- Identify the failing Dockerfile step (line number, instruction) and whether the failure is in the build context, a RUN command, the base image, the daemon or the registry.
+- For registry errors, distinguish missing tags, authentication and rate limits; for daemon errors, distinguish socket permissions from a stopped daemon.
+- Suggest fixes in the Dockerfile or CI configuration (pinned base image tags, registry login, cache mounts) and the docker command that verifies them.
+- When the log includes the Dockerfile (numbered lines) and static findings, cite the failing instruction by line as evidence; without a build log, report the finding most likely to break the build or the running container.
+
+Example:
+Log: "ERROR: failed to solve: node:18-alpinee: docker.io/library/node:18-alpinee: not found"
+Answer: {"error_type": "docker_image_not_found", "severity": "Medium", "root_cause": "The base image tag 'node:18-alpinee' does not exist on Docker Hub (typo in the FROM line).", "suggested_actions": ["Fix the FROM line to 'node:18-alpine'", "Verify tags with 'docker manifest inspect node:18-alpine'"], "commands": [{"command": "docker manifest inspect node:18-alpine", "description": "Check that the corrected tag exists"}], "prevention_tips": ["Pin base images by digest and lint Dockerfiles in CI"]}`,
+
+	PromptDomainCI: `Domain focus: CI pipelines (GitHub Actions, GitLab CI).
+
+- Identify the failing job and step, and whether the failure is in the command the step runs, the pipeline configuration (undefined variables, secrets, stages, needs) or the job token's permissions.
+- For GitHub Actions permission errors ("Resource not accessible by integration", "denied to github-actions[bot]"), name the GITHUB_TOKEN scope the call needs and the permissions block to change; remember that a permissions block denies every scope it leaves out and that pull requests from forks get a read-only token and no secrets.
+- For variables, say where the variable should be defined (step, job or workflow env, GitLab variables, repository secrets) and whether it is empty rather than undefined.
+- When the log includes the pipeline config (numbered lines) and findings, cite the failing step and the offending config line as evidence, and prefer fixes in the config over re-running the job.
+
+Example:
+Log: "Run gh pr comment 42 --body done\nGraphQL: Resource not accessible by integration (addComment)\n##[error]Process completed with exit code 1."
+Answer: {"error_type": "ci_token_permission", "severity": "High", "root_cause": "The job's GITHUB_TOKEN cannot comment on the pull request: the workflow's permissions block grants contents: read only, so pull-requests is none.", "suggested_actions": ["Add 'pull-requests: write' to the job's permissions block", "Keep the other scopes at the minimum the job needs"], "commands": [{"command": "gh api repos/<owner>/<repo>/actions/permissions/workflow", "description": "Show the repository's default token permissions"}], "prevention_tips": ["Declare least-privilege permissions per job and update them with the steps"]}`,
+}
+
+// BuildDomainSystemPrompt implements DomainPromptBuilder.
+func (p *DefaultPromptBuilder) BuildDomainSystemPrompt(name string) (string, bool) {
+	text, ok := domainPromptTexts[name]
+	if !ok {
+		return "", false
+	}
+	// The domain section goes before the closing output format rule
+	if i := strings.LastIndex(p.systemPrompt, "CRITICAL:"); i >= 0 {
+		return p.systemPrompt[:i] + text + "\n\n" + p.systemPrompt[i:], true
+	}
+	return p.systemPrompt + "\n\n" + text, true
+}
+
+// PromptDomains implements DomainPromptBuilder.
+func (p *DefaultPromptBuilder) PromptDomains() []string {
+	names := make([]string, 0, len(domainPromptTexts))
+	for name := range domainPromptTexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,83 @@
+// Package ai provides unit tests for domain-specialized prompts.
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestRoutePromptDomain(t *testing.T) {
+	match := func(errorType string, confidence float64) domain.RuleMatch {
+		return domain.RuleMatch{Result: &domain.AnalysisResult{ErrorType: errorType}, Confidence: confidence}
+	}
+
+	tests := []struct {
+		name    string
+		matches []domain.RuleMatch
+		want    string
+	}{
+		{"no matches", nil, ""},
+		{"kubernetes", []domain.RuleMatch{match(domain.ErrorTypeCrashLoopBackoff, 0.5)}, PromptDomainKubernetes},
+		{"terraform", []domain.RuleMatch{match(domain.ErrorTypeTerraformStateLocked, 0.5)}, PromptDomainTerraform},
+		{"pulumi", []domain.RuleMatch{match(domain.ErrorTypePulumiStackLocked, 0.5)}, PromptDomainIaC},
+		{"cloudformation", []domain.RuleMatch{match(domain.ErrorTypeCloudFormationStack, 0.5)}, PromptDomainIaC},
+		{"iac", []domain.RuleMatch{match(domain.ErrorTypeIaCProviderAuth, 0.5)}, PromptDomainIaC},
+		{"npm", []domain.RuleMatch{match(domain.ErrorTypeNPMInstall, 0.5)}, PromptDomainNPM},
+		{"docker", []domain.RuleMatch{match(domain.ErrorTypeDockerImageNotFound, 0.5)}, PromptDomainDocker},
+		{"ci", []domain.RuleMatch{match(domain.ErrorTypeCITokenPermission, 0.5)}, PromptDomainCI},
+		{"no domain", []domain.RuleMatch{match(domain.ErrorTypeConnectionTimeout, 0.5)}, ""},
+		{"unknown type", []domain.RuleMatch{match("something_odd", 0.5)}, ""},
+		{"strongest wins", []domain.RuleMatch{
+			match(domain.ErrorTypeNPMInstall, 0.4),
+			match(domain.ErrorTypeTerraformApply, 0.6),
+		}, PromptDomainTerraform},
+		{"skips matches without a domain", []domain.RuleMatch{
+			match(domain.ErrorTypeConnectionTimeout, 0.6),
+			match(domain.ErrorTypeDockerBuild, 0.4),
+		}, PromptDomainDocker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoutePromptDomain(tt.matches); got != tt.want {
+				t.Errorf("RoutePromptDomain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSystemPrompt(t *testing.T) {
+	prompter, err := NewDefaultPromptBuilder()
+	if err != nil {
+		t.Fatalf("NewDefaultPromptBuilder() error = %v", err)
+	}
+	generic := prompter.BuildSystemPrompt()
+
+	if got := buildSystemPrompt(context.Background(), prompter); got != generic {
+		t.Error("buildSystemPrompt() without a domain should return the generic prompt")
+	}
+	if got := buildSystemPrompt(WithPromptDomain(context.Background(), "cobol"), prompter); got != generic {
+		t.Error("buildSystemPrompt() with an unknown domain should return the generic prompt")
+	}
+
+	for _, name := range prompter.PromptDomains() {
+		got := buildSystemPrompt(WithPromptDomain(context.Background(), name), prompter)
+		focus := strings.Index(got, "Domain focus:")
+		critical := strings.LastIndex(got, "CRITICAL:")
+		if focus < 0 || critical < focus {
+			t.Errorf("%s prompt should contain the domain section before the output rule", name)
+		}
+	}
+
+	// Builders without specialized prompts always use their own prompt
+	custom, err := NewCustomPromptBuilder("custom", "{{.Log}}")
+	if err != nil {
+		t.Fatalf("NewCustomPromptBuilder() error = %v", err)
+	}
+	if got := buildSystemPrompt(WithPromptDomain(context.Background(), PromptDomainKubernetes), custom); got != "custom" {
+		t.Errorf("buildSystemPrompt() = %q, want custom prompt", got)
+	}
+}
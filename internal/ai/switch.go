@@ -0,0 +1,84 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+)
+
+// ClientFactory builds the client for AI settings.
+type ClientFactory func(cfg *config.AIConfig) Client
+
+// SwitchableClient delegates to a client that can be replaced at runtime,
+// e.g. to fail over to a backup provider or model without a restart.
+// Requests in flight finish on the client they started with.
+type SwitchableClient struct {
+	build   ClientFactory
+	mu      sync.Mutex // serializes Switch
+	current atomic.Pointer[switchedClient]
+}
+
+// switchedClient is a client with the settings it was built from.
+type switchedClient struct {
+	client Client
+	config config.AIConfig
+}
+
+// NewSwitchableClient creates a switchable client starting with client,
+// built from cfg. build creates the clients switched to.
+func NewSwitchableClient(client Client, cfg config.AIConfig, build ClientFactory) *SwitchableClient {
+	s := &SwitchableClient{build: build}
+	s.current.Store(&switchedClient{client: client, config: cfg})
+	return s
+}
+
+// Config returns the settings of the current client.
+func (s *SwitchableClient) Config() config.AIConfig {
+	return s.current.Load().config
+}
+
+// Switch validates cfg and replaces the current client with one built from
+// it.
+func (s *SwitchableClient) Switch(cfg config.AIConfig) error {
+	return SwitchAll([]*SwitchableClient{s}, func(config.AIConfig) config.AIConfig { return cfg })
+}
+
+// SwitchAll switches every client to the settings apply derives from its
+// current ones. All new clients are validated and built before any is
+// switched, so an invalid change leaves every client as it was.
+func SwitchAll(clients []*SwitchableClient, apply func(config.AIConfig) config.AIConfig) error {
+	next := make([]*switchedClient, len(clients))
+	for i, s := range clients {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		cfg := apply(s.current.Load().config)
+		if err := cfg.ValidateModel(); err != nil {
+			return err
+		}
+		next[i] = &switchedClient{client: s.build(&cfg), config: cfg}
+	}
+	for i, s := range clients {
+		s.current.Store(next[i])
+	}
+	return nil
+}
+
+// Analyze implements Client.
+func (s *SwitchableClient) Analyze(ctx context.Context, log string) (*domain.AnalysisResult, error) {
+	return s.current.Load().client.Analyze(ctx, log)
+}
+
+// Chat implements Client.
+func (s *SwitchableClient) Chat(ctx context.Context, conversation *domain.Conversation, message string) (*domain.ChatReply, error) {
+	return s.current.Load().client.Chat(ctx, conversation, message)
+}
+
+// HealthCheck implements Client.
+func (s *SwitchableClient) HealthCheck(ctx context.Context) error {
+	return s.current.Load().client.HealthCheck(ctx)
+}
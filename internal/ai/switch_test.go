@@ -0,0 +1,128 @@
+// Package ai provides unit tests for runtime client switching.
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestSwitchableClient_Switch(t *testing.T) {
+	initial := config.AIConfig{
+		Provider:    config.AIProviderOpenAI,
+		APIKey:      "primary-key",
+		BaseURL:     "https://api.openai.com/v1",
+		Model:       "gpt-4o",
+		MaxTokens:   1024,
+		Temperature: 0.1,
+	}
+	factory := func(cfg *config.AIConfig) Client {
+		return &opinionClient{result: &domain.AnalysisResult{ErrorType: cfg.Model}}
+	}
+	client := NewSwitchableClient(factory(&initial), initial, factory)
+
+	backup := initial.ForProvider(config.AIProviderGemini)
+	backup.Model = "gemini-2.0-flash"
+	backup.APIKey = "backup-key"
+
+	tests := []struct {
+		name      string
+		cfg       config.AIConfig
+		wantErr   bool
+		wantModel string
+	}{
+		{name: "switch to backup provider", cfg: backup, wantModel: "gemini-2.0-flash"},
+		{
+			name: "provider change without API key",
+			cfg: func() config.AIConfig {
+				cfg := initial.ForProvider(config.AIProviderGemini)
+				cfg.Model = "gemini-2.0-flash"
+				return cfg
+			}(),
+			wantErr:   true,
+			wantModel: "gemini-2.0-flash",
+		},
+		{
+			name: "temperature out of range",
+			cfg: func() config.AIConfig {
+				cfg := initial
+				cfg.Temperature = 3
+				return cfg
+			}(),
+			wantErr:   true,
+			wantModel: "gemini-2.0-flash",
+		},
+		{name: "switch back", cfg: initial, wantModel: "gpt-4o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.Switch(tt.cfg)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Switch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidConfig) {
+				t.Errorf("Switch() error = %v, want ErrInvalidConfig", err)
+			}
+
+			result, err := client.Analyze(context.Background(), "log")
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if result.ErrorType != tt.wantModel || client.Config().Model != tt.wantModel {
+				t.Errorf("answered by %q with config model %q, want %q", result.ErrorType, client.Config().Model, tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestSwitchAll(t *testing.T) {
+	primary := config.AIConfig{
+		Provider:    config.AIProviderOpenAI,
+		APIKey:      "primary-key",
+		BaseURL:     "https://api.openai.com/v1",
+		Model:       "gpt-4o",
+		MaxTokens:   1024,
+		Temperature: 0.1,
+	}
+	terraform := primary
+	terraform.Temperature = 2
+	factory := func(cfg *config.AIConfig) Client {
+		return &opinionClient{result: &domain.AnalysisResult{ErrorType: cfg.Model}}
+	}
+	clients := []*SwitchableClient{
+		NewSwitchableClient(factory(&primary), primary, factory),
+		NewSwitchableClient(factory(&terraform), terraform, factory),
+	}
+
+	// The second client ends up out of range: neither is switched
+	err := SwitchAll(clients, func(cfg config.AIConfig) config.AIConfig {
+		cfg.Model = "gpt-4o-mini"
+		cfg.Temperature += 0.5
+		return cfg
+	})
+	if !errors.Is(err, domain.ErrInvalidConfig) {
+		t.Fatalf("SwitchAll() error = %v, want ErrInvalidConfig", err)
+	}
+	for i, client := range clients {
+		if got := client.Config().Model; got != "gpt-4o" {
+			t.Errorf("client %d switched to %q despite the error", i, got)
+		}
+	}
+
+	if err := SwitchAll(clients, func(cfg config.AIConfig) config.AIConfig {
+		cfg.Model = "gpt-4o-mini"
+		return cfg
+	}); err != nil {
+		t.Fatalf("SwitchAll() error = %v", err)
+	}
+	for i, client := range clients {
+		result, err := client.Analyze(context.Background(), "log")
+		if err != nil || result.ErrorType != "gpt-4o-mini" {
+			t.Errorf("client %d answered %+v, %v, want gpt-4o-mini", i, result, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+type traceKey struct{}
+
+// Trace records the AI requests made with a context, for explaining how an
+// analysis was produced. A nil Trace records nothing. It is safe for
+// concurrent use.
+type Trace struct {
+	mu          sync.Mutex
+	attempts    []domain.AIAttempt
+	promptBytes int
+}
+
+// WithTrace returns a context whose AI requests are recorded in trace.
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// TraceFromContext returns the Trace carried by ctx, or nil.
+func TraceFromContext(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	return trace
+}
+
+// recordPrompt records the size of a prompt about to be sent.
+func (t *Trace) recordPrompt(bytes int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.promptBytes = bytes
+}
+
+// recordAttempt records one provider request that started at start.
+func (t *Trace) recordAttempt(provider, model, endpoint string, start time.Time, err error) {
+	if t == nil {
+		return
+	}
+	attempt := domain.AIAttempt{
+		Provider:   provider,
+		Model:      model,
+		Endpoint:   endpoint,
+		DurationMS: milliseconds(time.Since(start)),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.attempts = append(t.attempts, attempt)
+}
+
+// Attempts returns the recorded requests in order.
+func (t *Trace) Attempts() []domain.AIAttempt {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]domain.AIAttempt(nil), t.attempts...)
+}
+
+// PromptBytes returns the size of the last recorded prompt.
+func (t *Trace) PromptBytes() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.promptBytes
+}
+
+// milliseconds converts d to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,47 @@
+// Package ai provides the AI client interface and implementations.
+package ai
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+)
+
+// newHTTPClient creates the HTTP client for AI requests with the configured
+// proxy and TLS settings.
+func newHTTPClient(cfg *config.AIConfig) *http.Client {
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: newTransport(cfg),
+	}
+}
+
+// newTransport creates the transport for cfg. Invalid settings are rejected
+// by Config.Validate; a client built from unvalidated settings fails every
+// request with the error rather than ignoring the settings.
+func newTransport(cfg *config.AIConfig) http.RoundTripper {
+	proxy, err := cfg.Proxy()
+	if err != nil {
+		return errTransport{err: err}
+	}
+	tlsConfig, err := cfg.TLSConfig()
+	if err != nil {
+		return errTransport{err: err}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	transport.TLSClientConfig = tlsConfig
+	return transport
+}
+
+// errTransport fails every request with a configuration error.
+type errTransport struct {
+	err error
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, domain.WrapError("ai_transport", t.err, false)
+}
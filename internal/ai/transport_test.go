@@ -0,0 +1,90 @@
+// Package ai provides unit tests for the AI HTTP transport settings.
+package ai
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+	"go.uber.org/zap"
+)
+
+func TestProviderClient_CACertFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		caCertFile string
+		wantErr    bool
+	}{
+		{name: "private CA trusted", caCertFile: caFile},
+		{name: "system roots only", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewOpenAIClient(&config.AIConfig{
+				APIKey:     "test-api-key",
+				BaseURL:    server.URL,
+				Timeout:    5 * time.Second,
+				CACertFile: tt.caCertFile,
+			}, nil, nil, zap.NewNop())
+
+			err := client.HealthCheck(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HealthCheck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviderClient_ProxyURL(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	client := NewOpenAIClient(&config.AIConfig{
+		APIKey:   "test-api-key",
+		BaseURL:  "http://ai.internal.invalid/v1",
+		Timeout:  5 * time.Second,
+		ProxyURL: proxy.URL,
+	}, nil, nil, zap.NewNop())
+
+	if err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	if proxied != "http://ai.internal.invalid/v1/models" {
+		t.Errorf("proxy received %q, want the models URL", proxied)
+	}
+}
+
+func TestProviderClient_InvalidTLSSettings(t *testing.T) {
+	client := NewOpenAIClient(&config.AIConfig{
+		APIKey:        "test-api-key",
+		BaseURL:       "http://127.0.0.1:1",
+		Timeout:       5 * time.Second,
+		TLSMinVersion: "1.4",
+	}, nil, nil, zap.NewNop())
+
+	if err := client.HealthCheck(context.Background()); err == nil {
+		t.Error("HealthCheck() succeeded, want the configuration error")
+	}
+}
@@ -3,16 +3,29 @@ package ai
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/ai-devops/internal/domain"
 )
 
 // DefaultValidator implements ResponseValidator with strict schema checks.
-type DefaultValidator struct{}
+type DefaultValidator struct {
+	referenceDomains []string
+}
 
-// NewDefaultValidator creates a new response validator.
+// NewDefaultValidator creates a new response validator that keeps
+// references to domain.DefaultReferenceDomains.
 func NewDefaultValidator() *DefaultValidator {
-	return &DefaultValidator{}
+	return &DefaultValidator{referenceDomains: domain.DefaultReferenceDomains}
+}
+
+// WithReferenceDomains returns v restricted to references on domains (and
+// their subdomains) instead of the defaults. No domains keeps the defaults.
+func (v *DefaultValidator) WithReferenceDomains(domains ...string) *DefaultValidator {
+	if len(domains) > 0 {
+		v.referenceDomains = domains
+	}
+	return v
 }
 
 // Validate checks if the AI response conforms to the expected schema.
@@ -28,6 +41,14 @@ func (v *DefaultValidator) Validate(result *domain.AnalysisResult) error {
 			fmt.Errorf("%w: error_type is required", domain.ErrInvalidAIResponse), false)
 	}
 
+	// Map the model's spelling ("DockerBuildError") onto the canonical
+	// taxonomy so results can be aggregated by error_type.
+	result.ErrorType, _ = domain.NormalizeErrorType(result.ErrorType)
+
+	// Accept severity in any letter case; models answering in another
+	// language sometimes lowercase or uppercase it.
+	result.Severity = domain.NormalizeSeverity(result.Severity)
+
 	// Validate severity is one of the allowed values
 	if !result.Severity.IsValid() {
 		return domain.WrapError("validate_severity",
@@ -55,6 +76,25 @@ func (v *DefaultValidator) Validate(result *domain.AnalysisResult) error {
 		}
 	}
 
+	// Confidence is optional; keep it within 0-1 so thresholds compare
+	// sensibly, reading answers such as 85 as a percentage
+	switch {
+	case math.IsNaN(result.Confidence) || result.Confidence < 0:
+		result.Confidence = 0
+	case result.Confidence > 1 && result.Confidence <= 100:
+		result.Confidence /= 100
+	case result.Confidence > 100:
+		result.Confidence = 1
+	}
+
+	// Commands are optional; clean up prompts and backticks models copy
+	// from documentation so they can be pasted as is
+	result.Commands = domain.NormalizeCommands(result.Commands)
+
+	// References are optional; drop links outside the allowlisted
+	// documentation sites, which are likely made up
+	result.References = domain.FilterReferences(result.References, v.referenceDomains)
+
 	// Validate each prevention_tip is not empty (if present)
 	for i, tip := range result.PreventionTips {
 		if tip == "" {
@@ -2,6 +2,8 @@
 package ai
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/ai-devops/internal/domain"
@@ -26,6 +28,17 @@ func TestDefaultValidator_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "non-English text with lowercase severity",
+			result: &domain.AnalysisResult{
+				ErrorType:        "out_of_memory",
+				Severity:         "high",
+				RootCause:        "Container bị hết bộ nhớ",
+				SuggestedActions: []string{"Tăng giới hạn bộ nhớ"},
+				PreventionTips:   []string{"メモリ使用量を監視する"},
+			},
+			wantErr: false,
+		},
 		{
 			name:    "nil result",
 			result:  nil,
@@ -93,6 +106,96 @@ func TestDefaultValidator_Validate(t *testing.T) {
 	}
 }
 
+func TestDefaultValidator_NormalizesErrorType(t *testing.T) {
+	result := &domain.AnalysisResult{
+		ErrorType:        "DockerBuildError",
+		Severity:         domain.SeverityHigh,
+		RootCause:        "Base image missing",
+		SuggestedActions: []string{"Fix the FROM line"},
+	}
+	if err := NewDefaultValidator().Validate(result); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if result.ErrorType != domain.ErrorTypeDockerBuild {
+		t.Errorf("ErrorType = %q, want %q", result.ErrorType, domain.ErrorTypeDockerBuild)
+	}
+}
+
+func TestDefaultValidator_NormalizesCommands(t *testing.T) {
+	result := &domain.AnalysisResult{
+		ErrorType:        "npm_install_failure",
+		Severity:         domain.SeverityMedium,
+		RootCause:        "Peer dependency conflict",
+		SuggestedActions: []string{"Upgrade react-dom"},
+		Commands:         []domain.Command{{Command: "$ npm install react-dom@18"}, {Command: ""}},
+	}
+	if err := NewDefaultValidator().Validate(result); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(result.Commands) != 1 || result.Commands[0].Command != "npm install react-dom@18" {
+		t.Errorf("Commands = %+v, want one command without the prompt", result.Commands)
+	}
+}
+
+func TestDefaultValidator_FiltersReferences(t *testing.T) {
+	newResult := func() *domain.AnalysisResult {
+		return &domain.AnalysisResult{
+			ErrorType:        "k8s_crash_loop",
+			Severity:         domain.SeverityHigh,
+			RootCause:        "Container exits on start",
+			SuggestedActions: []string{"Check the previous logs"},
+			References: []domain.Reference{
+				{Title: "Debug Pods", URL: "https://kubernetes.io/docs/tasks/debug/debug-application/debug-pods/"},
+				{Title: "Fix CrashLoopBackOff", URL: "https://k8s-fixes.example.com/crashloop"},
+			},
+		}
+	}
+
+	result := newResult()
+	if err := NewDefaultValidator().Validate(result); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(result.References) != 1 || result.References[0].Title != "Debug Pods" {
+		t.Errorf("References = %+v, want only the kubernetes.io link", result.References)
+	}
+
+	result = newResult()
+	if err := NewDefaultValidator().WithReferenceDomains("example.com").Validate(result); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(result.References) != 1 || result.References[0].Title != "Fix CrashLoopBackOff" {
+		t.Errorf("References = %+v, want only the example.com link", result.References)
+	}
+}
+
+func TestDefaultValidator_ClampsConfidence(t *testing.T) {
+	tests := []struct {
+		confidence float64
+		want       float64
+	}{
+		{0.8, 0.8},
+		{-1, 0},
+		{85, 0.85},
+		{250, 1},
+	}
+
+	for _, tt := range tests {
+		result := &domain.AnalysisResult{
+			ErrorType:        "k8s_crash_loop",
+			Severity:         domain.SeverityHigh,
+			RootCause:        "Container exits on start",
+			SuggestedActions: []string{"Check the previous logs"},
+			Confidence:       tt.confidence,
+		}
+		if err := NewDefaultValidator().Validate(result); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		if result.Confidence != tt.want {
+			t.Errorf("Confidence %v -> %v, want %v", tt.confidence, result.Confidence, tt.want)
+		}
+	}
+}
+
 func TestExtractJSON(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -160,6 +263,102 @@ func TestDefaultPromptBuilder(t *testing.T) {
 	}
 }
 
+func TestComposeLogSections(t *testing.T) {
+	composed := ComposeLogSections([]domain.LogSection{
+		{Name: "deploy", Content: "rollout stuck\n"},
+		{Name: "kubelet_events", Content: "Back-off pulling image"},
+	})
+
+	for _, want := range []string{
+		"2 log sections",
+		"=== Section 1/2: deploy ===\nrollout stuck\n",
+		"=== Section 2/2: kubelet_events ===\nBack-off pulling image",
+	} {
+		if !contains(composed, want) {
+			t.Errorf("composed log missing %q:\n%s", want, composed)
+		}
+	}
+}
+
+func TestWithLogMetadata(t *testing.T) {
+	if got := WithLogMetadata("log", nil); got != "log" {
+		t.Errorf("nil metadata should not change the log, got %q", got)
+	}
+
+	got := WithLogMetadata("build failed", &domain.LogMetadata{
+		RunnerOS:     "windows",
+		Arch:         "arm64",
+		ToolVersions: map[string]string{"node": "20.1.0"},
+	})
+	for _, want := range []string{"- Runner OS: windows", "- Architecture: arm64", "- node version: 20.1.0", "\n\nbuild failed"} {
+		if !contains(got, want) {
+			t.Errorf("prompt context missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWithRuleHints(t *testing.T) {
+	if got := WithRuleHints("log", nil); got != "log" {
+		t.Errorf("no matches should not change the log, got %q", got)
+	}
+
+	got := WithRuleHints("Killed", []domain.RuleMatch{
+		{RuleID: "a", Confidence: 0.5, Result: &domain.AnalysisResult{ErrorType: "disk_full"}},
+		{RuleID: "b", Confidence: 0.7, Result: &domain.AnalysisResult{ErrorType: "out_of_memory"}},
+		{RuleID: "c", Confidence: 0.6, Result: &domain.AnalysisResult{ErrorType: "out_of_memory"}},
+	})
+	want := "Pattern hints (low-confidence rule matches; confirm or refute them):\n" +
+		"- pattern suggests: out_of_memory (confidence 0.70)\n" +
+		"- pattern suggests: disk_full (confidence 0.50)\n\nKilled"
+	if got != want {
+		t.Errorf("WithRuleHints() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWithWorkedExamples(t *testing.T) {
+	if got := WithWorkedExamples("log", nil); got != "log" {
+		t.Errorf("no examples should not change the log, got %q", got)
+	}
+
+	got := WithWorkedExamples("npm ERR! code E404", []WorkedExample{
+		{Log: "npm ERR! code ETARGET", Result: &domain.AnalysisResult{ErrorType: "npm_install_failure", Severity: domain.SeverityMedium, RootCause: "missing version"}},
+		{Log: "ignored without a result"},
+		{Log: strings.Repeat("x", maxExampleLogChars+10), Result: &domain.AnalysisResult{ErrorType: "other"}},
+	})
+	for _, want := range []string{
+		"Example log:\n---\nnpm ERR! code ETARGET\n---\n",
+		`Example answer: {"error_type":"npm_install_failure","severity":"Medium","root_cause":"missing version"`,
+		"[truncated]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WithWorkedExamples() missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "ignored without a result") {
+		t.Error("examples without a result should be skipped")
+	}
+	if !strings.HasSuffix(got, "\n\nnpm ERR! code E404") {
+		t.Error("the analyzed log should follow the examples")
+	}
+}
+
+func TestBuildUserPrompt_Language(t *testing.T) {
+	builder, err := NewDefaultPromptBuilder()
+	if err != nil {
+		t.Fatalf("failed to create prompt builder: %v", err)
+	}
+
+	plain := buildUserPrompt(context.Background(), builder, "log")
+	if contains(plain, "Write root_cause") {
+		t.Error("default prompt should not request a language")
+	}
+
+	localized := buildUserPrompt(WithLanguage(context.Background(), "Vietnamese"), builder, "log")
+	if !contains(localized, "prevention_tips in Vietnamese") {
+		t.Errorf("localized prompt missing language instruction:\n%s", localized)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -0,0 +1,32 @@
+// Package cache provides a size-bounded cache for analysis results keyed by
+// log fingerprints.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// volatilePatterns match log fragments that differ between otherwise
+// identical failures (timestamps, hex IDs, numbers).
+var volatilePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`),
+	regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`),
+	regexp.MustCompile(`\b[0-9a-f]{12,}\b`),
+	regexp.MustCompile(`\d+`),
+}
+
+// Fingerprint returns a stable key for a sanitized log. Volatile fragments
+// are normalized so that repeated occurrences of the same failure share a key.
+func Fingerprint(log string) string {
+	normalized := strings.TrimSpace(log)
+	for _, pattern := range volatilePatterns {
+		normalized = pattern.ReplaceAllString(normalized, "#")
+	}
+	normalized = strings.Join(strings.Fields(normalized), " ")
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,262 @@
+// Package cache provides a size-bounded cache for analysis results keyed by
+// log fingerprints.
+package cache
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// entryOverhead approximates the per-entry bookkeeping cost in bytes
+// (list element, map bucket, pointers).
+const entryOverhead = 128
+
+// Cache stores analysis results by fingerprint. Implementations must be
+// safe for concurrent use.
+type Cache interface {
+	// Get returns the cached result for key.
+	Get(key string) (*domain.AnalysisResult, bool)
+
+	// Put stores result under key with invalidation tags.
+	Put(key string, result *domain.AnalysisResult, tags ...string)
+
+	// Invalidate removes every entry carrying tag and returns how many
+	// were removed.
+	Invalidate(tag string) int
+
+	// Stats returns a snapshot of cache metrics.
+	Stats() Stats
+}
+
+// Stats reports cache effectiveness and memory use.
+type Stats struct {
+	Backend       string `json:"backend"`
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Evictions     uint64 `json:"evictions"`
+	Invalidations uint64 `json:"invalidations"`
+	Entries       int    `json:"entries"`
+	Bytes         int    `json:"bytes"`
+	MaxBytes      int    `json:"max_bytes"`
+}
+
+type entry struct {
+	key    string
+	result *domain.AnalysisResult
+	tags   []string
+	size   int
+}
+
+// LRU is a least-recently-used cache bounded by the approximate memory size
+// of its entries rather than their count. It is safe for concurrent use.
+type LRU struct {
+	maxBytes int
+
+	mu            sync.Mutex
+	ll            *list.List
+	items         map[string]*list.Element
+	bytes         int
+	hits          uint64
+	misses        uint64
+	evictions     uint64
+	invalidations uint64
+}
+
+// NewLRU creates a cache holding at most maxBytes of entries.
+func NewLRU(maxBytes int) *LRU {
+	return &LRU{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached result for key and marks it most recently used.
+func (c *LRU) Get(key string) (*domain.AnalysisResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	c.ll.MoveToFront(el)
+	return el.Value.(*entry).result, true
+}
+
+// Put stores result under key, evicting least recently used entries until
+// the cache fits within its byte limit. Results larger than the whole cache
+// are not stored. Tags (e.g. "rule:oom", "prompt:1a2b") let Invalidate drop
+// every entry derived from a rule or prompt version.
+func (c *LRU) Put(key string, result *domain.AnalysisResult, tags ...string) {
+	size := entrySize(key, result)
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry)
+		c.bytes += size - e.size
+		e.result = result
+		e.tags = tags
+		e.size = size
+		c.ll.MoveToFront(el)
+	} else {
+		c.items[key] = c.ll.PushFront(&entry{key: key, result: result, tags: tags, size: size})
+		c.bytes += size
+	}
+
+	for c.bytes > c.maxBytes {
+		c.removeOldest()
+	}
+}
+
+// RuleTag is the invalidation tag for results shaped by the rule with id.
+func RuleTag(id string) string { return "rule:" + id }
+
+// PromptTag is the invalidation tag for results of a prompt version.
+func PromptTag(version string) string { return "prompt:" + version }
+
+// Invalidate removes every entry carrying tag and returns how many were
+// removed, so identical requests are analyzed again.
+func (c *LRU) Invalidate(tag string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for el := c.ll.Front(); el != nil; {
+		next := el.Next()
+		e := el.Value.(*entry)
+		for _, t := range e.tags {
+			if t == tag {
+				c.ll.Remove(el)
+				delete(c.items, e.key)
+				c.bytes -= e.size
+				removed++
+				break
+			}
+		}
+		el = next
+	}
+	c.invalidations += uint64(removed)
+	return removed
+}
+
+// removeOldest evicts the least recently used entry. Caller holds mu.
+func (c *LRU) removeOldest() {
+	el := c.ll.Back()
+	if el == nil {
+		return
+	}
+	e := el.Value.(*entry)
+	c.ll.Remove(el)
+	delete(c.items, e.key)
+	c.bytes -= e.size
+	c.evictions++
+}
+
+// Stats returns a snapshot of cache metrics.
+func (c *LRU) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return Stats{
+		Backend:       "memory",
+		Hits:          c.hits,
+		Misses:        c.misses,
+		Evictions:     c.evictions,
+		Invalidations: c.invalidations,
+		Entries:       c.ll.Len(),
+		Bytes:         c.bytes,
+		MaxBytes:      c.maxBytes,
+	}
+}
+
+// snapshotEntry is the on-disk form of a cache entry.
+type snapshotEntry struct {
+	Key    string                 `json:"key"`
+	Result *domain.AnalysisResult `json:"result"`
+	Tags   []string               `json:"tags,omitempty"`
+}
+
+// SaveSnapshot writes all entries to path, most recently used first.
+// The file is written atomically via a temporary file and rename.
+func (c *LRU) SaveSnapshot(path string) error {
+	c.mu.Lock()
+	entries := make([]snapshotEntry, 0, c.ll.Len())
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*entry)
+		entries = append(entries, snapshotEntry{Key: e.key, Result: e.result, Tags: e.tags})
+	}
+	c.mu.Unlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("marshal cache snapshot: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write cache snapshot: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadSnapshot restores entries written by SaveSnapshot. A missing file is
+// not an error. Returns the number of entries loaded.
+func (c *LRU) LoadSnapshot(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read cache snapshot: %w", err)
+	}
+
+	var entries []snapshotEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return 0, fmt.Errorf("parse cache snapshot: %w", err)
+	}
+
+	// Insert oldest first so the most recently used entry ends up at the front.
+	for i := len(entries) - 1; i >= 0; i-- {
+		c.Put(entries[i].Key, entries[i].Result, entries[i].Tags...)
+	}
+	return c.Stats().Entries, nil
+}
+
+// entrySize approximates the memory held by an entry.
+func entrySize(key string, r *domain.AnalysisResult) int {
+	size := entryOverhead + len(key)
+	if r == nil {
+		return size
+	}
+	size += len(r.ErrorType) + len(r.Severity) + len(r.RootCause)
+	for _, s := range r.SuggestedActions {
+		size += len(s) + 16
+	}
+	for _, c := range r.Commands {
+		size += len(c.Command) + len(c.Description) + 32
+	}
+	for _, s := range r.PreventionTips {
+		size += len(s) + 16
+	}
+	for _, ref := range r.References {
+		size += len(ref.Title) + len(ref.URL) + 32
+	}
+	for _, s := range r.Evidence {
+		size += len(s) + 16
+	}
+	return size
+}
@@ -0,0 +1,108 @@
+// Package cache provides unit tests for the LRU cache.
+package cache
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func testResult(errorType string) *domain.AnalysisResult {
+	return &domain.AnalysisResult{
+		ErrorType:        errorType,
+		Severity:         domain.SeverityMedium,
+		RootCause:        strings.Repeat("x", 100),
+		SuggestedActions: []string{"fix"},
+	}
+}
+
+func TestLRU_EvictsBySize(t *testing.T) {
+	size := entrySize("a", testResult("a"))
+	c := NewLRU(size * 2)
+
+	c.Put("a", testResult("a"))
+	c.Put("b", testResult("b"))
+	c.Get("a") // a is now most recently used
+	c.Put("c", testResult("c"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a to be retained")
+	}
+
+	stats := c.Stats()
+	if stats.Evictions != 1 {
+		t.Errorf("evictions = %d, want 1", stats.Evictions)
+	}
+	if stats.Bytes > stats.MaxBytes {
+		t.Errorf("bytes %d exceed max %d", stats.Bytes, stats.MaxBytes)
+	}
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("hits/misses = %d/%d, want 2/1", stats.Hits, stats.Misses)
+	}
+}
+
+func TestLRU_Snapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c := NewLRU(1 << 20)
+	c.Put("a", testResult("a"))
+	c.Put("b", testResult("b"))
+	if err := c.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot() error: %v", err)
+	}
+
+	restored := NewLRU(1 << 20)
+	n, err := restored.LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("loaded %d entries, want 2", n)
+	}
+	if r, ok := restored.Get("b"); !ok || r.ErrorType != "b" {
+		t.Error("expected b to be restored")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint("2024-01-02T10:00:00Z ERROR pod web-7f9c8d6b5-x2k4z restarted 3 times")
+	b := Fingerprint("2024-03-09T22:15:41Z ERROR pod web-7f9c8d6b5-x2k4z restarted 5 times")
+	c := Fingerprint("ERROR connection refused")
+
+	if a != b {
+		t.Error("expected logs differing only in volatile fields to share a fingerprint")
+	}
+	if a == c {
+		t.Error("expected different logs to have different fingerprints")
+	}
+}
+
+func TestLRU_Invalidate(t *testing.T) {
+	c := NewLRU(1 << 20)
+	c.Put("a", testResult("a"), PromptTag("v1"), RuleTag("oom"))
+	c.Put("b", testResult("b"), PromptTag("v1"))
+	c.Put("c", testResult("c"), PromptTag("v2"))
+
+	if n := c.Invalidate(RuleTag("oom")); n != 1 {
+		t.Errorf("Invalidate(rule) = %d, want 1", n)
+	}
+	if n := c.Invalidate(PromptTag("v1")); n != 1 {
+		t.Errorf("Invalidate(prompt) = %d, want 1", n)
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("expected c to be retained")
+	}
+
+	stats := c.Stats()
+	if stats.Entries != 1 || stats.Invalidations != 2 {
+		t.Errorf("entries/invalidations = %d/%d, want 1/2", stats.Entries, stats.Invalidations)
+	}
+	if stats.Bytes != entrySize("c", testResult("c")) {
+		t.Errorf("bytes = %d, want size of c", stats.Bytes)
+	}
+}
@@ -0,0 +1,134 @@
+// Package cache provides a size-bounded cache for analysis results keyed by
+// log fingerprints.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/redis"
+	"go.uber.org/zap"
+)
+
+// redisEntry is the stored form of a cache entry.
+type redisEntry struct {
+	Result *domain.AnalysisResult `json:"result"`
+	Tags   []string               `json:"tags,omitempty"`
+}
+
+// RedisCache shares results between replicas through Redis. Entries expire
+// after a TTL instead of being evicted by size; tags are Redis sets of the
+// keys carrying them. Redis errors are logged and treated as misses, so an
+// unavailable Redis only costs cache hits.
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+	logger *zap.Logger
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// NewRedisCache creates a cache storing entries under prefix for ttl.
+func NewRedisCache(client *redis.Client, prefix string, ttl time.Duration, logger *zap.Logger) *RedisCache {
+	return &RedisCache{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+		logger: logger.Named("redis_cache"),
+	}
+}
+
+// Get implements Cache.
+func (c *RedisCache) Get(key string) (*domain.AnalysisResult, bool) {
+	data, err := c.client.Get(context.Background(), c.entryKey(key))
+	if err != nil {
+		if !errors.Is(err, redis.ErrNil) {
+			c.logger.Warn("cache get failed", zap.Error(err))
+		}
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	var e redisEntry
+	if err := json.Unmarshal([]byte(data), &e); err != nil || e.Result == nil {
+		c.logger.Warn("dropping unreadable cache entry", zap.String("key", key))
+		c.client.Del(context.Background(), c.entryKey(key))
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return e.Result, true
+}
+
+// Put implements Cache.
+func (c *RedisCache) Put(key string, result *domain.AnalysisResult, tags ...string) {
+	data, err := json.Marshal(redisEntry{Result: result, Tags: tags})
+	if err != nil {
+		c.logger.Warn("cache put failed", zap.Error(err))
+		return
+	}
+
+	ctx := context.Background()
+	if err := c.client.Set(ctx, c.entryKey(key), string(data), c.ttl); err != nil {
+		c.logger.Warn("cache put failed", zap.Error(err))
+		return
+	}
+	for _, tag := range tags {
+		if _, err := c.client.Do(ctx, "SADD", c.tagKey(tag), key); err != nil {
+			c.logger.Warn("cache tag failed", zap.String("tag", tag), zap.Error(err))
+			continue
+		}
+		// Tag sets outlive their entries by at most one TTL
+		if c.ttl > 0 {
+			c.client.Do(ctx, "PEXPIRE", c.tagKey(tag), c.ttl.Milliseconds())
+		}
+	}
+}
+
+// Invalidate implements Cache.
+func (c *RedisCache) Invalidate(tag string) int {
+	ctx := context.Background()
+	reply, err := c.client.Do(ctx, "SMEMBERS", c.tagKey(tag))
+	if err != nil {
+		c.logger.Warn("cache invalidate failed", zap.String("tag", tag), zap.Error(err))
+		return 0
+	}
+	members, _ := reply.([]any)
+
+	keys := make([]string, 0, len(members))
+	for _, m := range members {
+		if key, ok := m.(string); ok {
+			keys = append(keys, c.entryKey(key))
+		}
+	}
+	removed, err := c.client.Del(ctx, keys...)
+	if err != nil {
+		c.logger.Warn("cache invalidate failed", zap.String("tag", tag), zap.Error(err))
+	}
+	c.client.Del(ctx, c.tagKey(tag))
+
+	c.invalidations.Add(uint64(removed))
+	return int(removed)
+}
+
+// Stats implements Cache. Hits, misses and invalidations are counted by
+// this replica; entries and memory live in Redis and are not reported.
+func (c *RedisCache) Stats() Stats {
+	return Stats{
+		Backend:       "redis",
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+	}
+}
+
+func (c *RedisCache) entryKey(key string) string { return c.prefix + "cache:" + key }
+
+func (c *RedisCache) tagKey(tag string) string { return c.prefix + "cache_tag:" + tag }
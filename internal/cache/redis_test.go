@@ -0,0 +1,73 @@
+// Package cache provides unit tests for the Redis-backed cache.
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/redis"
+	"github.com/ai-devops/internal/redis/redistest"
+	"go.uber.org/zap"
+)
+
+func newTestRedisCache(t *testing.T, url string) *RedisCache {
+	t.Helper()
+	client, err := redis.New(redis.Config{URL: url, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("redis.New() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return NewRedisCache(client, "app:", time.Hour, zap.NewNop())
+}
+
+func TestRedisCache_GetPutInvalidate(t *testing.T) {
+	srv := redistest.NewServer(t)
+	c := newTestRedisCache(t, srv.URL())
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+	c.Put("a", testResult("a"), "rule:oom")
+	c.Put("b", testResult("b"), "rule:oom")
+	c.Put("c", testResult("c"))
+
+	got, ok := c.Get("a")
+	if !ok || got.ErrorType != "a" {
+		t.Fatalf("Get(a) = %+v, %v", got, ok)
+	}
+	if ttl := srv.TTL("app:cache:a"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("entry TTL = %v, want up to 1h", ttl)
+	}
+
+	if n := c.Invalidate("rule:oom"); n != 2 {
+		t.Errorf("Invalidate() = %d, want 2", n)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be invalidated")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("expected untagged c to be retained")
+	}
+
+	stats := c.Stats()
+	if stats.Backend != "redis" || stats.Hits != 2 || stats.Misses != 2 || stats.Invalidations != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestRedisCache_UnavailableIsMiss(t *testing.T) {
+	srv := redistest.NewServer(t)
+	c := newTestRedisCache(t, srv.URL())
+	srv.Handle("GET", func(args []string) any {
+		return errors.New("LOADING Redis is loading the dataset in memory")
+	})
+
+	c.Put("a", testResult("a"))
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a Redis error to count as a miss")
+	}
+	if c.Stats().Misses != 1 {
+		t.Errorf("misses = %d, want 1", c.Stats().Misses)
+	}
+}
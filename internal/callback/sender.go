@@ -0,0 +1,264 @@
+// Package callback runs analyses in the background and delivers the results
+// to a per-request callback URL, signed with HMAC-SHA256.
+package callback
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Delivery headers. The signature is "sha256=" followed by the hex HMAC of
+// "<timestamp>.<body>", so a receiver can reject replayed deliveries.
+const (
+	SignatureHeader = "X-AI-DevOps-Signature"
+	TimestampHeader = "X-AI-DevOps-Timestamp"
+	AttemptHeader   = "X-AI-DevOps-Attempt"
+)
+
+// maxBackoff caps the delay between delivery attempts.
+const maxBackoff = 5 * time.Minute
+
+// ErrClosed is returned when a job is submitted after Close.
+var ErrClosed = errors.New("callback sender closed")
+
+// ErrBusy is returned when MaxPending jobs are already running or waiting
+// to be delivered.
+var ErrBusy = fmt.Errorf("%w: too many callbacks pending", domain.ErrAIQueueFull)
+
+// errPrivateAddress is returned when a delivery is dialed to an address
+// that is not publicly routable.
+var errPrivateAddress = errors.New("callback address is not publicly routable")
+
+// Config contains callback delivery settings.
+type Config struct {
+	// Secret signs deliveries.
+	Secret string
+
+	// MaxAttempts bounds delivery attempts per callback.
+	MaxAttempts int
+
+	// Backoff is the delay before the second attempt; it doubles after
+	// each failed attempt.
+	Backoff time.Duration
+
+	// Timeout bounds a single delivery attempt.
+	Timeout time.Duration
+
+	// AllowedHosts restricts callback URLs to these hosts (exact match or
+	// "*.example.com" subdomains). Empty allows any host.
+	AllowedHosts []string
+
+	// AllowPrivateNetworks allows deliveries to loopback, private and
+	// link-local addresses. Without it they are refused when dialed, so a
+	// callback URL cannot reach internal services or cloud metadata
+	// endpoints, whatever its host resolves to.
+	AllowPrivateNetworks bool
+
+	// MaxPending bounds the jobs running or waiting to be delivered.
+	MaxPending int
+}
+
+// Sender runs submitted analyses and delivers their results.
+type Sender struct {
+	cfg        Config
+	httpClient *http.Client
+	logger     *zap.Logger
+	slots      chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	pending sync.WaitGroup
+}
+
+// NewSender creates a Sender. Deliveries are dialed directly, not through
+// an environment proxy, so that the address check applies to the receiver,
+// and redirects are not followed.
+func NewSender(cfg Config, logger *zap.Logger) *Sender {
+	if cfg.MaxPending < 1 {
+		cfg.MaxPending = 1
+	}
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = publicAddressOnly
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: logger.Named("callback"),
+		slots:  make(chan struct{}, cfg.MaxPending),
+	}
+}
+
+// publicAddressOnly is a net.Dialer Control function refusing connections
+// to addresses that are not publicly routable.
+func publicAddressOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", errPrivateAddress, host)
+	}
+	return nil
+}
+
+// ValidateURL checks that raw is an absolute http(s) URL to an allowed host.
+// Invalid URLs wrap domain.ErrInvalidRequest.
+func (s *Sender) ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: callback_url must be an absolute http(s) URL", domain.ErrInvalidRequest)
+	}
+	if len(s.cfg.AllowedHosts) == 0 {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range s.cfg.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: callback_url host %q is not allowed", domain.ErrInvalidRequest, host)
+}
+
+// Submit runs analyze in the background and delivers its response to
+// callbackURL. requestID identifies the delivery to the receiver and is
+// carried by the context analyze is given. It returns ErrBusy when
+// MaxPending jobs are already pending.
+func (s *Sender) Submit(callbackURL, requestID string, analyze func(ctx context.Context) *domain.AnalysisResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		return ErrBusy
+	}
+
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		defer func() { <-s.slots }()
+		logger := s.logger.With(zap.String("request_id", requestID))
+
+		response := analyze(domain.WithRequestID(context.Background(), requestID))
+		if err := s.Deliver(context.Background(), callbackURL, requestID, response); err != nil {
+			logger.Error("callback delivery failed", zap.Error(err))
+			return
+		}
+		logger.Info("callback delivered", zap.Bool("success", response.Success))
+	}()
+	return nil
+}
+
+// Deliver posts response to callbackURL, retrying network errors, 429 and
+// 5xx responses with exponential backoff until MaxAttempts is reached.
+func (s *Sender) Deliver(ctx context.Context, callbackURL, requestID string, response *domain.AnalysisResponse) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("marshal callback payload: %w", err)
+	}
+
+	backoff := s.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(ctx, callbackURL, requestID, attempt, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.cfg.MaxAttempts {
+			return fmt.Errorf("after %d attempts: %w", attempt, err)
+		}
+		s.logger.Warn("callback attempt failed, retrying",
+			zap.String("request_id", requestID),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is
+// retryable.
+func (s *Sender) post(ctx context.Context, callbackURL, requestID string, attempt int, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create callback request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", requestID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(s.cfg.Secret, timestamp, body))
+	req.Header.Set(AttemptHeader, strconv.Itoa(attempt))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return !errors.Is(err, errPrivateAddress), fmt.Errorf("post callback: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("callback returned status %d", resp.StatusCode)
+}
+
+// Close stops accepting jobs and waits for pending jobs to finish.
+func (s *Sender) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.pending.Wait()
+}
+
+// Sign returns the signature header value for a delivery body sent at
+// timestamp (Unix seconds).
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
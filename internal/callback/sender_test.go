@@ -0,0 +1,236 @@
+// Package callback provides unit tests for callback delivery.
+package callback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// newTestSender returns a sender allowed to deliver to httptest servers on
+// the loopback address.
+func newTestSender(hosts ...string) *Sender {
+	return NewSender(Config{
+		Secret:               "s3cret",
+		MaxAttempts:          3,
+		Backoff:              time.Millisecond,
+		Timeout:              time.Second,
+		AllowedHosts:         hosts,
+		AllowPrivateNetworks: true,
+		MaxPending:           10,
+	}, zap.NewNop())
+}
+
+func TestSender_Deliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{"first attempt", []int{http.StatusOK}, 1, false},
+		{"retries server errors", []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusNoContent}, 3, false},
+		{"gives up after max attempts", []int{500, 500, 500, 500}, 3, true},
+		{"client error is not retried", []int{http.StatusNotFound, http.StatusOK}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := attempts.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				want := Sign("s3cret", r.Header.Get(TimestampHeader), body)
+				if got := r.Header.Get(SignatureHeader); got != want {
+					t.Errorf("signature = %s, want %s", got, want)
+				}
+				if r.Header.Get("X-Request-ID") != "req-1" {
+					t.Errorf("X-Request-ID = %q", r.Header.Get("X-Request-ID"))
+				}
+				var resp domain.AnalysisResponse
+				if err := json.Unmarshal(body, &resp); err != nil || resp.Source != "rules:test" {
+					t.Errorf("payload = %s", body)
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer srv.Close()
+
+			err := newTestSender().Deliver(context.Background(), srv.URL, "req-1",
+				&domain.AnalysisResponse{Success: true, Source: "rules:test"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Deliver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestSender_ValidateURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   []string
+		url     string
+		wantErr bool
+	}{
+		{"any host", nil, "https://ci.example.com/hook", false},
+		{"relative", nil, "/hook", true},
+		{"unsupported scheme", nil, "file:///etc/passwd", true},
+		{"allowed host", []string{"ci.example.com"}, "https://CI.example.com:8443/hook", false},
+		{"allowed subdomain", []string{"*.example.com"}, "https://a.b.example.com/hook", false},
+		{"wildcard excludes apex lookalike", []string{"*.example.com"}, "https://badexample.com/hook", true},
+		{"host not allowed", []string{"ci.example.com"}, "http://169.254.169.254/latest", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestSender(tt.hosts...).ValidateURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidRequest) {
+				t.Errorf("error = %v, want ErrInvalidRequest", err)
+			}
+		})
+	}
+}
+
+func TestSender_SubmitAndClose(t *testing.T) {
+	delivered := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- r.Header.Get("X-Request-ID")
+	}))
+	defer srv.Close()
+
+	s := newTestSender()
+	err := s.Submit(srv.URL, "req-2", func(context.Context) *domain.AnalysisResponse {
+		return &domain.AnalysisResponse{Success: true}
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	s.Close()
+
+	select {
+	case id := <-delivered:
+		if id != "req-2" {
+			t.Errorf("delivered %q, want req-2", id)
+		}
+	default:
+		t.Fatal("Close returned before the callback was delivered")
+	}
+	if err := s.Submit(srv.URL, "req-3", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit() after Close error = %v, want ErrClosed", err)
+	}
+}
+
+func TestSender_RefusesPrivateAddresses(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+	}))
+	defer srv.Close()
+
+	s := NewSender(Config{Secret: "s3cret", MaxAttempts: 3, Backoff: time.Millisecond, Timeout: time.Second}, zap.NewNop())
+	err := s.Deliver(context.Background(), srv.URL, "req-1", &domain.AnalysisResponse{Success: true})
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("Deliver() error = %v, want errPrivateAddress", err)
+	}
+	if !strings.Contains(err.Error(), "after 1 attempts") {
+		t.Errorf("Deliver() error = %v, want no retries", err)
+	}
+	if got := attempts.Load(); got != 0 {
+		t.Errorf("server reached %d times", got)
+	}
+}
+
+func TestPublicAddressOnly(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{"93.184.216.34:443", false},
+		{"[2606:4700::1111]:443", false},
+		{"127.0.0.1:80", true},
+		{"[::1]:80", true},
+		{"10.1.2.3:80", true},
+		{"172.16.0.1:80", true},
+		{"192.168.1.1:80", true},
+		{"169.254.169.254:80", true},
+		{"[fe80::1]:80", true},
+		{"[fd00::1]:80", true},
+		{"0.0.0.0:80", true},
+		{"[::ffff:127.0.0.1]:80", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := publicAddressOnly("tcp", tt.address, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("publicAddressOnly() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSender_DoesNotFollowRedirects(t *testing.T) {
+	var redirected atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected.Add(1)
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	err := newTestSender().Deliver(context.Background(), srv.URL, "req-1", &domain.AnalysisResponse{Success: true})
+	if err == nil {
+		t.Error("Deliver() to a redirect succeeded")
+	}
+	if got := redirected.Load(); got != 0 {
+		t.Errorf("redirect followed %d times", got)
+	}
+}
+
+func TestSender_SubmitBusy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	s := NewSender(Config{
+		Secret:               "s3cret",
+		MaxAttempts:          1,
+		Backoff:              time.Millisecond,
+		Timeout:              time.Second,
+		AllowPrivateNetworks: true,
+		MaxPending:           1,
+	}, zap.NewNop())
+	release := make(chan struct{})
+	analyze := func(context.Context) *domain.AnalysisResponse {
+		<-release
+		return &domain.AnalysisResponse{Success: true}
+	}
+
+	if err := s.Submit(srv.URL, "req-1", analyze); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if err := s.Submit(srv.URL, "req-2", analyze); !errors.Is(err, ErrBusy) {
+		t.Errorf("Submit() over MaxPending error = %v, want ErrBusy", err)
+	}
+	if code := domain.CodeFor(ErrBusy); code.HTTPStatus() != http.StatusTooManyRequests {
+		t.Errorf("ErrBusy status = %d, want 429", code.HTTPStatus())
+	}
+	close(release)
+	s.Close()
+}
@@ -0,0 +1,203 @@
+// Package capture keeps sampled AI prompts and raw AI responses for
+// debugging: a ring buffer served by the admin API and, optionally, a JSON
+// lines file.
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+)
+
+// Config controls what is captured.
+type Config struct {
+	// SampleRate is the fraction (0.0-1.0) of AI analyses captured.
+	SampleRate float64
+
+	// Rejected also captures every analysis with a response that could not
+	// be parsed or failed validation, sampled or not.
+	Rejected bool
+
+	// MaxEntries bounds the ring buffer.
+	MaxEntries int
+
+	// Path, if set, is a file every entry is appended to as a JSON line.
+	Path string
+}
+
+// Entry is one captured analysis.
+type Entry struct {
+	ID       string           `json:"id"`
+	Time     time.Time        `json:"time"`
+	Tenant   string           `json:"tenant"`
+	Rejected bool             `json:"rejected"`
+	Calls    []ai.CaptureCall `json:"calls"`
+}
+
+// Recorder decides which analyses are captured and keeps the newest
+// MaxEntries of them. Captured user prompts and responses are redacted
+// before they are kept.
+//
+// A nil *Recorder is valid and captures nothing.
+type Recorder struct {
+	cfg    Config
+	redact func(string) string
+	file   *os.File
+
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	seq     int
+}
+
+// NewRecorder creates a recorder. redact masks sensitive values in the
+// captured text; it may be nil.
+func NewRecorder(cfg Config, redact func(string) string) (*Recorder, error) {
+	if cfg.MaxEntries <= 0 {
+		return nil, fmt.Errorf("%w: capture size must be positive", domain.ErrInvalidConfig)
+	}
+	r := &Recorder{cfg: cfg, redact: redact}
+	if cfg.Path != "" {
+		file, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open capture file: %w", err)
+		}
+		r.file = file
+	}
+	return r, nil
+}
+
+// Session is the capture of one analysis. A nil *Session is valid.
+type Session struct {
+	recorder *Recorder
+	capture  *ai.Capture
+	sampled  bool
+	tenant   string
+}
+
+// Start returns a context whose AI requests are captured, unless the
+// analysis is neither sampled nor needed for rejected responses.
+func (r *Recorder) Start(ctx context.Context) (context.Context, *Session) {
+	if r == nil {
+		return ctx, nil
+	}
+	sampled := rand.Float64() < r.cfg.SampleRate
+	if !sampled && !r.cfg.Rejected {
+		return ctx, nil
+	}
+	s := &Session{
+		recorder: r,
+		capture:  &ai.Capture{},
+		sampled:  sampled,
+		tenant:   domain.TenantFromContext(ctx),
+	}
+	return ai.WithCapture(ctx, s.capture), s
+}
+
+// Finish keeps the capture if the analysis was sampled or a response was
+// rejected.
+func (s *Session) Finish() error {
+	if s == nil {
+		return nil
+	}
+	calls := s.capture.Calls()
+	rejected := s.capture.Rejected()
+	if len(calls) == 0 || (!s.sampled && !rejected) {
+		return nil
+	}
+	return s.recorder.add(Entry{
+		Time:     time.Now(),
+		Tenant:   s.tenant,
+		Rejected: rejected,
+		Calls:    calls,
+	})
+}
+
+// add redacts and stores entry.
+func (r *Recorder) add(entry Entry) error {
+	if r.redact != nil {
+		for i := range entry.Calls {
+			call := &entry.Calls[i]
+			call.UserPrompt = r.redact(call.UserPrompt)
+			for j := range call.Attempts {
+				call.Attempts[j].Response = r.redact(call.Attempts[j].Response)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	entry.ID = fmt.Sprintf("cap_%d", r.seq)
+	if len(r.entries) < r.cfg.MaxEntries {
+		r.entries = append(r.entries, entry)
+	} else {
+		r.entries[r.next] = entry
+	}
+	r.next = (r.next + 1) % r.cfg.MaxEntries
+
+	if r.file == nil {
+		return nil
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode capture: %w", err)
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write capture: %w", err)
+	}
+	return nil
+}
+
+// List returns up to limit entries, newest first; only those with a
+// rejected response if rejectedOnly. A limit of zero returns all.
+func (r *Recorder) List(rejectedOnly bool, limit int) []Entry {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []Entry
+	for i := 1; i <= len(r.entries); i++ {
+		entry := r.entries[(r.next-i+len(r.entries))%len(r.entries)]
+		if rejectedOnly && !entry.Rejected {
+			continue
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+	}
+	return entries
+}
+
+// Get returns the entry with id, if it is still kept.
+func (r *Recorder) Get(id string) (Entry, bool) {
+	if r == nil {
+		return Entry{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, entry := range r.entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Close closes the capture file.
+func (r *Recorder) Close() error {
+	if r == nil || r.file == nil {
+		return nil
+	}
+	return r.file.Close()
+}
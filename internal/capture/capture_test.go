@@ -0,0 +1,172 @@
+// Package capture provides unit tests for debug capture.
+package capture
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/config"
+	"go.uber.org/zap"
+)
+
+// newTestClient returns an OpenAI client whose every answer is content.
+func newTestClient(t *testing.T, content string) ai.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := json.Marshal(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": content}, "finish_reason": "stop"}},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	prompter, _ := ai.NewDefaultPromptBuilder()
+	return ai.NewOpenAIClient(&config.AIConfig{
+		APIKey:    "test-api-key",
+		BaseURL:   server.URL,
+		Model:     "gpt-4o-mini",
+		Timeout:   5 * time.Second,
+		MaxTokens: 512,
+	}, prompter, ai.NewDefaultValidator(), zap.NewNop())
+}
+
+const validResponse = `{"error_type":"disk_full","severity":"High","root_cause":"The /var volume is out of space","suggested_actions":["Free space on /var"],"prevention_tips":["Alert on disk usage"]}`
+
+func TestRecorder_SamplesAndKeepsRejected(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		response  string
+		wantCount int
+	}{
+		{name: "sampled", cfg: Config{SampleRate: 1, MaxEntries: 10}, response: validResponse, wantCount: 1},
+		{name: "not sampled", cfg: Config{Rejected: true, MaxEntries: 10}, response: validResponse, wantCount: 0},
+		{name: "rejected, not sampled", cfg: Config{Rejected: true, MaxEntries: 10}, response: "not json", wantCount: 1},
+		{name: "rejected not kept", cfg: Config{MaxEntries: 10}, response: "not json", wantCount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRecorder(tt.cfg, nil)
+			if err != nil {
+				t.Fatalf("NewRecorder() error = %v", err)
+			}
+			ctx, session := r.Start(context.Background())
+			newTestClient(t, tt.response).Analyze(ctx, "disk full on /var")
+			if err := session.Finish(); err != nil {
+				t.Fatalf("Finish() error = %v", err)
+			}
+
+			entries := r.List(false, 0)
+			if len(entries) != tt.wantCount {
+				t.Fatalf("entries = %d, want %d", len(entries), tt.wantCount)
+			}
+			if tt.wantCount == 1 && entries[0].Calls[0].Attempts[0].Response != tt.response {
+				t.Errorf("response = %q, want the raw response", entries[0].Calls[0].Attempts[0].Response)
+			}
+		})
+	}
+}
+
+func TestRecorder_Redacts(t *testing.T) {
+	r, _ := NewRecorder(Config{SampleRate: 1, MaxEntries: 10}, func(s string) string {
+		return strings.ReplaceAll(s, "hunter2", "[REDACTED]")
+	})
+	ctx, session := r.Start(context.Background())
+	newTestClient(t, "password hunter2 rejected").Analyze(ctx, "login failed for password hunter2")
+	session.Finish()
+
+	entry := r.List(false, 0)[0]
+	call := entry.Calls[0]
+	if strings.Contains(call.UserPrompt, "hunter2") || strings.Contains(call.Attempts[0].Response, "hunter2") {
+		t.Errorf("capture = %+v, want the secret redacted", call)
+	}
+	if !entry.Rejected {
+		t.Error("expected the entry to be marked rejected")
+	}
+}
+
+func TestRecorder_RingBuffer(t *testing.T) {
+	r, _ := NewRecorder(Config{MaxEntries: 2}, nil)
+	for i := 0; i < 3; i++ {
+		r.add(Entry{Rejected: i == 1, Calls: []ai.CaptureCall{{}}})
+	}
+
+	entries := r.List(false, 0)
+	if len(entries) != 2 || entries[0].ID != "cap_3" || entries[1].ID != "cap_2" {
+		t.Fatalf("entries = %+v, want cap_3 and cap_2, newest first", entries)
+	}
+	if got := r.List(true, 0); len(got) != 1 || got[0].ID != "cap_2" {
+		t.Errorf("rejected entries = %+v, want cap_2", got)
+	}
+	if got := r.List(false, 1); len(got) != 1 || got[0].ID != "cap_3" {
+		t.Errorf("limited entries = %+v, want cap_3", got)
+	}
+	if _, ok := r.Get("cap_1"); ok {
+		t.Error("expected the oldest entry to be evicted")
+	}
+	if _, ok := r.Get("cap_2"); !ok {
+		t.Error("expected cap_2 to be kept")
+	}
+}
+
+func TestRecorder_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "captures.jsonl")
+	r, err := NewRecorder(Config{MaxEntries: 10, Path: path}, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	r.add(Entry{Tenant: "payments"})
+	r.add(Entry{Tenant: "search"})
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open capture file: %v", err)
+	}
+	defer file.Close()
+	var tenants []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("decode line %q: %v", scanner.Text(), err)
+		}
+		tenants = append(tenants, entry.Tenant)
+	}
+	if strings.Join(tenants, ",") != "payments,search" {
+		t.Errorf("tenants = %v, want payments,search", tenants)
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+	ctx, session := r.Start(context.Background())
+	if ai.CaptureFromContext(ctx) != nil {
+		t.Error("nil Recorder should not capture")
+	}
+	if err := session.Finish(); err != nil {
+		t.Errorf("Finish() error = %v", err)
+	}
+	if r.List(false, 0) != nil || r.Close() != nil {
+		t.Error("nil Recorder should hold nothing")
+	}
+}
+
+func TestNewRecorder_InvalidSize(t *testing.T) {
+	if _, err := NewRecorder(Config{}, nil); err == nil {
+		t.Error("expected an error for a zero size")
+	}
+}
@@ -0,0 +1,303 @@
+// Package ciconfig analyzes CI pipeline configurations, GitHub Actions
+// workflows and GitLab CI files, together with the log of a failed run.
+package ciconfig
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+var (
+	// notAccessiblePattern matches GitHub's answer to a GITHUB_TOKEN
+	// without the scope an API call needs.
+	notAccessiblePattern = regexp.MustCompile(`(?i)Resource not accessible by integration`)
+
+	// apiPathPattern matches the REST API path or documentation link of a
+	// failed call (api.github.com/repos/o/r/issues/1/comments,
+	// docs.github.com/rest/issues/comments#...).
+	apiPathPattern = regexp.MustCompile(`(?:api\.github\.com/repos/[^/\s]+/[^/\s]+|docs\.github\.com/(?:[a-z-]+/)?rest)/([a-z-]+)`)
+
+	// ghCommandPattern matches the gh command of a step.
+	ghCommandPattern = regexp.MustCompile(`\bgh\s+(pr|issue|release|label|api|workflow|run)\b`)
+
+	// pushDeniedPattern matches a git push with a GITHUB_TOKEN without
+	// contents: write.
+	pushDeniedPattern = regexp.MustCompile(`Permission to \S+ denied to github-actions\[bot\]`)
+
+	// packageDeniedPattern matches a push to GitHub Packages without
+	// packages: write.
+	packageDeniedPattern = regexp.MustCompile(`(?i)installation not allowed to (?:Write|Create) organization package|permission_denied: write_package`)
+
+	// idTokenPattern matches an OIDC token request without id-token: write.
+	idTokenPattern = regexp.MustCompile(`Unable to get ACTIONS_ID_TOKEN_REQUEST_URL`)
+
+	// workflowUpdatePattern matches a push of workflow files, which
+	// GITHUB_TOKEN can never make.
+	workflowUpdatePattern = regexp.MustCompile(`refusing to allow a GitHub App to create or update workflow`)
+
+	// jobTokenPushPattern matches a git push with GitLab's CI_JOB_TOKEN.
+	jobTokenPushPattern = regexp.MustCompile(`You are not allowed to push code to this project`)
+
+	// missingVariablePatterns match errors naming an unset environment
+	// variable: bash's set -u, Python's os.environ and common messages.
+	missingVariablePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*): unbound variable`),
+		regexp.MustCompile(`KeyError: '([A-Z][A-Z0-9_]+)'`),
+		regexp.MustCompile(`(?i)(?:environment variable|env var)\s+["'` + "`" + `]?([A-Z][A-Z0-9_]+)["'` + "`" + `]?\s+(?:is\s+)?(?:not set|not defined|required|missing|empty)`),
+		regexp.MustCompile(`\b([A-Z][A-Z0-9]*_[A-Z0-9_]+)["'` + "`" + `]?\s+(?:is not set|is not defined|must be set|is required|is empty)`),
+	}
+
+	// missingInputPattern matches an action input that was not passed.
+	missingInputPattern = regexp.MustCompile(`Input required and not supplied: ([\w-]+)`)
+
+	// secretExpressionPattern matches a secret in an expression.
+	secretExpressionPattern = regexp.MustCompile(`\$\{\{\s*secrets\.(\w+)\s*\}\}`)
+
+	// predefinedPrefixes are the prefixes of the variables the CI systems
+	// set themselves.
+	predefinedPrefixes = []string{"GITHUB_", "RUNNER_", "ACTIONS_", "CI_", "GITLAB_"}
+)
+
+// apiScopes maps the first segment of a REST API path, and gh
+// subcommands, to the GITHUB_TOKEN scope they need.
+var apiScopes = map[string]string{
+	"issues": "issues", "issue": "issues", "label": "issues", "labels": "issues",
+	"pulls": "pull-requests", "pr": "pull-requests",
+	"contents": "contents", "git": "contents", "releases": "contents", "release": "contents", "commits": "contents",
+	"checks": "checks", "check-runs": "checks",
+	"statuses": "statuses", "deployments": "deployments", "packages": "packages",
+	"actions": "actions", "workflow": "actions", "run": "actions",
+	"pages": "pages", "code-scanning": "security-events",
+}
+
+// Correlate checks the pipeline against the errors of a failure log: a
+// token missing a permission, an environment variable or input that is not
+// defined where the failing step runs. job and step are the failing job
+// and step, or nil when unknown; variables are then looked up in the whole
+// pipeline.
+func Correlate(p *Pipeline, log string, job *Job, step *Step) []domain.PipelineFinding {
+	c := &correlator{pipeline: p, job: job, step: step}
+	c.permissions(log)
+	c.variables(log)
+	return c.findings
+}
+
+type correlator struct {
+	pipeline *Pipeline
+	job      *Job
+	step     *Step
+	findings []domain.PipelineFinding
+}
+
+func (c *correlator) add(line int, rule, message string) {
+	finding := domain.PipelineFinding{Rule: rule, Severity: domain.SeverityHigh, Line: line, Message: message}
+	if c.job != nil {
+		finding.Job = c.job.ID
+	}
+	c.findings = append(c.findings, finding)
+}
+
+// permissions reports the token permission the log shows missing.
+func (c *correlator) permissions(log string) {
+	switch {
+	case notAccessiblePattern.MatchString(log):
+		scope := ""
+		if m := apiPathPattern.FindStringSubmatch(log); m != nil {
+			scope = apiScopes[m[1]]
+		}
+		if scope == "" && c.step != nil {
+			if m := ghCommandPattern.FindStringSubmatch(c.step.Run); m != nil {
+				scope = apiScopes[m[1]]
+			}
+		}
+		c.missingScope(scope, "the API call")
+	case pushDeniedPattern.MatchString(log):
+		c.missingScope("contents", "git push")
+	case packageDeniedPattern.MatchString(log):
+		c.missingScope("packages", "the package push")
+	case idTokenPattern.MatchString(log):
+		c.missingScope("id-token", "the OIDC token request")
+	case workflowUpdatePattern.MatchString(log):
+		c.add(c.stepLine(), "token_cannot_update_workflows", "the push changes files in .github/workflows, which GITHUB_TOKEN cannot do with any permissions; use a GitHub App or personal access token with the workflows permission")
+	case c.pipeline.Provider == ProviderGitLabCI && jobTokenPushPattern.MatchString(log):
+		c.add(c.stepLine(), "job_token_push", "CI_JOB_TOKEN cannot push to repositories; push with a project access token or deploy key stored as a masked CI/CD variable")
+	}
+}
+
+// missingScope reports the permissions block that lacks write access to
+// scope, or the scope to grant when there is none.
+func (c *correlator) missingScope(scope, call string) {
+	if c.pipeline.Provider != ProviderGitHubActions {
+		return
+	}
+	perms, owner := c.pipeline.Permissions, "the workflow"
+	if c.job != nil && c.job.Permissions != nil {
+		perms, owner = c.job.Permissions, "job "+c.job.ID
+	}
+	line := 0
+	if c.job != nil {
+		line = c.job.Line
+	}
+
+	need := "the scope " + call + " needs"
+	if scope != "" {
+		need = scope + ": write"
+	}
+	switch {
+	case perms == nil:
+		c.add(line, "missing_permission", "GITHUB_TOKEN was denied "+call+"; the workflow sets no permissions, so the repository default applies, which is read-only in repositories created since 2023; grant "+need+" in the job's permissions")
+	case scope == "":
+		c.add(perms.Line, "missing_permission", "GITHUB_TOKEN was denied "+call+"; "+owner+" grants only "+perms.String()+", and scopes left out are none; grant "+need)
+	case perms.Level(scope) != "write":
+		c.add(perms.Line, "missing_permission", "GITHUB_TOKEN was denied "+call+"; "+owner+" grants "+scope+": "+perms.Level(scope)+" and "+call+" needs "+need)
+	default:
+		message := "GITHUB_TOKEN was denied " + call + " although " + owner + " grants " + need + "; organization settings can cap token permissions"
+		if c.triggeredBy("pull_request") {
+			message += ", and pull requests from forks always get a read-only token"
+		}
+		c.add(perms.Line, "missing_permission", message)
+	}
+}
+
+// variables reports the variables and inputs the log shows missing.
+func (c *correlator) variables(log string) {
+	// Report the variables in the order the log names them
+	first := map[string]int{}
+	for _, pattern := range missingVariablePatterns {
+		for _, m := range pattern.FindAllStringSubmatchIndex(log, -1) {
+			name := log[m[2]:m[3]]
+			if at, ok := first[name]; !predefined(name) && (!ok || m[2] < at) {
+				first[name] = m[2]
+			}
+		}
+	}
+	names := make([]string, 0, len(first))
+	for name := range first {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return first[names[i]] < first[names[j]] })
+	for _, name := range names {
+		c.variable(name)
+	}
+
+	if c.step == nil || c.step.Uses == "" {
+		return
+	}
+	for _, m := range missingInputPattern.FindAllStringSubmatch(log, -1) {
+		input, ok := lookup(c.step.With, m[1])
+		switch {
+		case !ok:
+			c.add(c.step.Line, "missing_input", "step "+c.step.Title()+" does not pass the required input "+m[1]+" to "+c.step.Uses)
+		case secretExpressionPattern.MatchString(input.Value):
+			c.emptySecret(input)
+		}
+	}
+}
+
+// variable reports where name is missing or empty.
+func (c *correlator) variable(name string) {
+	for _, vars := range c.scopes() {
+		if v, ok := lookup(vars, name); ok {
+			switch {
+			case secretExpressionPattern.MatchString(v.Value):
+				c.emptySecret(v)
+			case strings.TrimSpace(v.Value) == "":
+				c.add(v.Line, "empty_env", name+" is defined but empty")
+			}
+			return
+		}
+	}
+	for _, other := range c.pipeline.Jobs {
+		if c.job != nil && other.ID == c.job.ID {
+			continue
+		}
+		vars := append([]Variable(nil), other.Env...)
+		for _, step := range other.Steps {
+			vars = append(vars, step.Env...)
+		}
+		if v, ok := lookup(vars, name); ok {
+			c.add(v.Line, "env_scope", name+" is only defined for job "+other.ID+"; variables do not carry over to other jobs, define it for the failing job or the whole pipeline")
+			return
+		}
+	}
+
+	line := 0
+	if c.step != nil {
+		line = c.step.Line
+	}
+	if c.pipeline.Provider == ProviderGitLabCI {
+		c.add(line, "undefined_env", name+" is not defined in the job or global variables; if it is a CI/CD variable of the project, check that it exists and is not protected while the pipeline runs on an unprotected branch")
+		return
+	}
+	c.add(line, "undefined_env", name+" is not defined in the step, job or workflow env; secrets are not exposed as variables, map them with "+name+": ${{ secrets."+name+" }}")
+}
+
+// emptySecret reports a variable or input set from a secret that was
+// empty.
+func (c *correlator) emptySecret(v Variable) {
+	secret := secretExpressionPattern.FindStringSubmatch(v.Value)[1]
+	message := v.Name + " is set from secrets." + secret + ", which is empty when the secret is not configured for the repository or environment"
+	if c.triggeredBy("pull_request") {
+		message += ", and in runs from forks"
+	}
+	c.add(v.Line, "empty_secret", message)
+}
+
+// scopes returns the variables visible where the failure happened, nearest
+// first: the step's env, the job's and the pipeline's. Without a failing
+// job, every job's variables are in scope.
+func (c *correlator) scopes() [][]Variable {
+	var scopes [][]Variable
+	if c.step != nil {
+		scopes = append(scopes, c.step.Env)
+	}
+	if c.job != nil {
+		scopes = append(scopes, c.job.Env)
+	} else {
+		for _, job := range c.pipeline.Jobs {
+			scopes = append(scopes, job.Env)
+			for _, step := range job.Steps {
+				scopes = append(scopes, step.Env)
+			}
+		}
+	}
+	return append(scopes, c.pipeline.Env)
+}
+
+// stepLine returns the line of the failing step or job, or 0.
+func (c *correlator) stepLine() int {
+	switch {
+	case c.step != nil:
+		return c.step.Line
+	case c.job != nil:
+		return c.job.Line
+	}
+	return 0
+}
+
+// triggeredBy reports whether the workflow runs on event.
+func (c *correlator) triggeredBy(event string) bool {
+	return contains(c.pipeline.Events, event)
+}
+
+// lookup returns the last variable named name; later definitions win.
+func lookup(vars []Variable, name string) (Variable, bool) {
+	for i := len(vars) - 1; i >= 0; i-- {
+		if vars[i].Name == name {
+			return vars[i], true
+		}
+	}
+	return Variable{}, false
+}
+
+func predefined(name string) bool {
+	for _, prefix := range predefinedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+// Package ciconfig provides unit tests for pipeline config parsing, linting, failing step detection and log correlation.
+package ciconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCorrelate(t *testing.T) {
+	const deployWorkflow = "on: [push, pull_request]\n" +
+		"permissions:\n" +
+		"  contents: read\n" +
+		"env:\n" +
+		"  REGION: eu-west-1\n" +
+		"jobs:\n" +
+		"  release:\n" +
+		"    permissions:\n" +
+		"      contents: write\n" +
+		"    env:\n" +
+		"      DEPLOY_KEY: ${{ secrets.DEPLOY_KEY }}\n" +
+		"    steps:\n" +
+		"      - run: git push origin HEAD:release\n" +
+		"      - run: ./deploy.sh\n" +
+		"      - uses: slackapi/slack-github-action@v1\n" +
+		"        with:\n" +
+		"          webhook: ${{ secrets.SLACK_WEBHOOK }}\n" +
+		"  notify:\n" +
+		"    env:\n" +
+		"      SLACK_CHANNEL: ops\n" +
+		"    steps:\n" +
+		"      - run: gh issue comment 1 --body done\n" +
+		"      - uses: aws-actions/configure-aws-credentials@v4\n" +
+		"      - run: ./notify.sh \"$EMPTY\"\n" +
+		"        env:\n" +
+		"          EMPTY: ''\n"
+
+	tests := []struct {
+		name   string
+		config string
+		log    string
+		want   []string // rule@line
+		text   string   // in the first finding's message
+	}{
+		{
+			name:   "scope missing from the workflow permissions",
+			config: deployWorkflow,
+			log:    "Run gh issue comment 1 --body done\nHttpError: Resource not accessible by integration\n  url: https://api.github.com/repos/acme/app/issues/1/comments\n##[error]Process completed with exit code 1.",
+			want:   []string{"missing_permission@2"},
+			text:   "the workflow grants issues: none and the API call needs issues: write",
+		},
+		{
+			name:   "scope from the gh command",
+			config: deployWorkflow,
+			log:    "Run gh issue comment 1 --body done\nGraphQL: Resource not accessible by integration (addComment)\n##[error]Process completed with exit code 1.",
+			want:   []string{"missing_permission@2"},
+			text:   "issues: write",
+		},
+		{
+			name:   "id token",
+			config: deployWorkflow,
+			log:    "Run aws-actions/configure-aws-credentials@v4\nError: Credentials could not be loaded, please check your action inputs: Unable to get ACTIONS_ID_TOKEN_REQUEST_URL env variable",
+			want:   []string{"missing_permission@2"},
+			text:   "id-token: write",
+		},
+		{
+			name:   "granted but denied",
+			config: deployWorkflow,
+			log:    "Run git push origin HEAD:release\nremote: Permission to acme/app.git denied to github-actions[bot].\n##[error]Process completed with exit code 128.",
+			want:   []string{"missing_permission@8"},
+			text:   "pull requests from forks always get a read-only token",
+		},
+		{
+			name:   "no permissions block",
+			config: "on: push\njobs:\n  publish:\n    steps:\n      - run: docker push ghcr.io/acme/app\n",
+			log:    "Run docker push ghcr.io/acme/app\ndenied: installation not allowed to Write organization package",
+			want:   []string{"missing_permission@3"},
+			text:   "the workflow sets no permissions",
+		},
+		{
+			name:   "empty secret and unbound variable",
+			config: deployWorkflow,
+			log:    "Run ./deploy.sh\n./deploy.sh: line 3: DEPLOY_KEY: unbound variable\n./deploy.sh: line 4: TARGET_HOST: unbound variable\n##[error]Process completed with exit code 1.",
+			want:   []string{"empty_secret@11", "undefined_env@14"},
+			text:   "secrets.DEPLOY_KEY",
+		},
+		{
+			name:   "variable of another job",
+			config: deployWorkflow,
+			log:    "Run ./deploy.sh\nError: environment variable SLACK_CHANNEL is not set\n##[error]Process completed with exit code 1.",
+			want:   []string{"env_scope@20"},
+		},
+		{
+			name:   "empty variable and predefined ones",
+			config: deployWorkflow,
+			log:    "Run ./notify.sh \"$EMPTY\"\nEMPTY_VALUE is required\nGITHUB_TOKEN is not set\nKeyError: 'EMPTY'\n##[error]Process completed with exit code 1.",
+			want:   []string{"undefined_env@24", "empty_env@26"},
+		},
+		{
+			name:   "action input from an empty secret",
+			config: deployWorkflow,
+			log:    "Run slackapi/slack-github-action@v1\n##[error]Input required and not supplied: webhook",
+			want:   []string{"empty_secret@17"},
+		},
+		{
+			name:   "gitlab job token push",
+			config: gitlabPipeline,
+			log:    "$ go build ./...\n$ git push origin HEAD:main\nremote: You are not allowed to push code to this project.\nERROR: Job failed: exit code 1",
+			want:   []string{"job_token_push@0"},
+		},
+		{
+			name:   "gitlab protected variable",
+			config: gitlabPipeline,
+			log:    "$ go test ./...\npanic: DATABASE_URL must be set\nERROR: Job failed: exit code 1",
+			want:   []string{"undefined_env@28"},
+			text:   "not protected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse("", tt.config)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var job *Job
+			var step *Step
+			if j, s, ok := FailingStep(p, tt.log, ""); ok {
+				job, step = &j, &s
+			}
+			findings := Correlate(p, tt.log, job, step)
+
+			var got []string
+			for _, f := range findings {
+				got = append(got, fmt.Sprintf("%s@%d", f.Rule, f.Line))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Correlate() = %q, want %q", got, tt.want)
+			}
+			if tt.text != "" && !strings.Contains(findings[0].Message, tt.text) {
+				t.Errorf("message = %q, want it to contain %q", findings[0].Message, tt.text)
+			}
+		})
+	}
+}
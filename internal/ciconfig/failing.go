@@ -0,0 +1,105 @@
+// Package ciconfig analyzes CI pipeline configurations, GitHub Actions
+// workflows and GitLab CI files, together with the log of a failed run.
+package ciconfig
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// githubStepPattern matches the header GitHub Actions logs for a step
+	// ("##[group]Run npm test", or "Run npm test" when copied from the web
+	// view), after the optional timestamp of downloaded logs. Post steps
+	// ("Post Run actions/checkout@v4") do not match.
+	githubStepPattern = regexp.MustCompile(`(?m)^(?:\d{4}-\d\d-\d\dT\S+Z\s+)?(?:##\[group\])?Run (.+?)\s*$`)
+
+	// githubErrorPattern matches the first error of a GitHub Actions log.
+	githubErrorPattern = regexp.MustCompile(`##\[error\]|(?m)^(?:\d{4}-\d\d-\d\dT\S+Z\s+)?Error: Process completed with exit code`)
+
+	// gitlabCommandPattern matches a command GitLab echoes before running
+	// it ("$ npm test"), without the note on multi-line commands.
+	gitlabCommandPattern = regexp.MustCompile(`(?m)^(?:\S+\s+\d+[OE]\+?\s+)?(?:\x1b\[[0-9;]*m)*\$ (.+?)(?:\s+# collapsed multi-line command)?(?:\x1b\[[0-9;]*m)*\s*$`)
+
+	// gitlabEndPattern matches the end of the script sections GitLab runs
+	// before the job fails: after_script runs after a failure and must not
+	// be mistaken for the failing command.
+	gitlabEndPattern = regexp.MustCompile(`Running after_script|Cleaning up project directory|Cleaning up file based variables|ERROR: Job failed`)
+
+	// expressionPattern matches a GitHub Actions expression, which the log
+	// shows evaluated.
+	expressionPattern = regexp.MustCompile(`\\\$\\\{\\\{.*?\\\}\\\}`)
+)
+
+// FailingStep returns the job and step a failure log shows failing, or
+// false. The last step header (GitHub Actions) or echoed command (GitLab
+// CI) before the failure is matched against the steps' commands; job
+// restricts the search to the job with that ID or name.
+func FailingStep(p *Pipeline, log string, job string) (Job, Step, bool) {
+	var header string
+	switch p.Provider {
+	case ProviderGitHubActions:
+		if loc := githubErrorPattern.FindStringIndex(log); loc != nil {
+			log = log[:loc[0]]
+		}
+		if m := githubStepPattern.FindAllStringSubmatch(log, -1); len(m) > 0 {
+			header = m[len(m)-1][1]
+		}
+	case ProviderGitLabCI:
+		if loc := gitlabEndPattern.FindStringIndex(log); loc != nil {
+			log = log[:loc[0]]
+		}
+		if m := gitlabCommandPattern.FindAllStringSubmatch(log, -1); len(m) > 0 {
+			header = m[len(m)-1][1]
+		}
+	}
+	if header == "" {
+		return Job{}, Step{}, false
+	}
+
+	header = normalizeSpace(header)
+	for _, j := range p.Jobs {
+		if j.Hidden || (job != "" && j.ID != job && j.Name != job) {
+			continue
+		}
+		for _, step := range j.Steps {
+			if matchCommand(step.Command(), header) {
+				return j, step, true
+			}
+		}
+	}
+	return Job{}, Step{}, false
+}
+
+// FindJob returns the job with the given ID or name.
+func (p *Pipeline) FindJob(job string) (Job, bool) {
+	for _, j := range p.Jobs {
+		if j.ID == job || j.Name == job {
+			return j, true
+		}
+	}
+	return Job{}, false
+}
+
+// matchCommand reports whether a command of the config is the one a log
+// shows. Expressions in the command match any text.
+func matchCommand(command, shown string) bool {
+	command = normalizeSpace(command)
+	if command == "" {
+		return false
+	}
+	if command == shown {
+		return true
+	}
+	if !strings.Contains(command, "${{") {
+		return false
+	}
+	pattern := expressionPattern.ReplaceAllString(regexp.QuoteMeta(command), `.*`)
+	matched, err := regexp.MatchString(`^`+pattern+`$`, shown)
+	return err == nil && matched
+}
+
+// normalizeSpace collapses runs of whitespace to one space.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
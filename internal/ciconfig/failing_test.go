@@ -0,0 +1,93 @@
+// Package ciconfig provides unit tests for pipeline config parsing, linting, failing step detection and log correlation.
+package ciconfig
+
+import "testing"
+
+func TestFailingStep(t *testing.T) {
+	github, err := Parse("", githubWorkflow)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	gitlab, err := Parse("", gitlabPipeline)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		pipeline *Pipeline
+		log      string
+		job      string
+		wantJob  string
+		wantLine int
+	}{
+		{
+			name:     "github downloaded log",
+			pipeline: github,
+			log: "2024-05-01T10:00:01.0000000Z ##[group]Run actions/checkout@v4\n" +
+				"2024-05-01T10:00:03.0000000Z ##[endgroup]\n" +
+				"2024-05-01T10:00:04.0000000Z ##[group]Run npm test\n" +
+				"2024-05-01T10:00:04.0000000Z npm test\n" +
+				"2024-05-01T10:00:09.0000000Z FAIL src/app.test.js\n" +
+				"2024-05-01T10:00:09.0000000Z ##[error]Process completed with exit code 1.\n" +
+				"2024-05-01T10:00:10.0000000Z Post job cleanup.\n" +
+				"2024-05-01T10:00:10.0000000Z ##[group]Run actions/checkout@v4\n",
+			wantJob:  "build",
+			wantLine: 20,
+		},
+		{
+			name:     "github web view with an expression",
+			pipeline: github,
+			log:      "Run gh pr comment 42 --body \"Tests passed\"\nGraphQL: Resource not accessible by integration (addComment)\nError: Process completed with exit code 1.",
+			wantJob:  "comment",
+			wantLine: 29,
+		},
+		{
+			name:     "github job filter",
+			pipeline: github,
+			log:      "##[group]Run actions/checkout@v4\n##[error]fatal: repository not found",
+			job:      "comment",
+		},
+		{
+			name:     "gitlab after_script skipped",
+			pipeline: gitlab,
+			log: "\x1b[32;1m$ go mod download\x1b[0;m\n" +
+				"\x1b[32;1m$ go vet ./...\x1b[0;m\n" +
+				"\x1b[32;1m$ go test ./...\x1b[0;m\n" +
+				"--- FAIL: TestServer (0.01s)\n" +
+				"\x1b[32;1mRunning after_script\x1b[0;m\n" +
+				"\x1b[32;1m$ echo done\x1b[0;m\n" +
+				"\x1b[31;1mERROR: Job failed: exit code 1\n",
+			wantJob:  "test",
+			wantLine: 28,
+		},
+		{
+			name:     "gitlab template before_script",
+			pipeline: gitlab,
+			log:      "$ go mod download\ngo: github.com/acme/lib@v1.2.0: reading https://proxy.golang.org: 404 Not Found\nERROR: Job failed: exit code 1",
+			job:      "build",
+			wantJob:  "build",
+			wantLine: 13,
+		},
+		{
+			name:     "no step in the log",
+			pipeline: gitlab,
+			log:      "ERROR: Job failed: execution took longer than 1h0m0s seconds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, step, ok := FailingStep(tt.pipeline, tt.log, tt.job)
+			if tt.wantJob == "" {
+				if ok {
+					t.Errorf("FailingStep() = %s line %d, want none", job.ID, step.Line)
+				}
+				return
+			}
+			if !ok || job.ID != tt.wantJob || step.Line != tt.wantLine {
+				t.Errorf("FailingStep() = %s line %d (%v), want %s line %d", job.ID, step.Line, ok, tt.wantJob, tt.wantLine)
+			}
+		})
+	}
+}
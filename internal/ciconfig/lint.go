@@ -0,0 +1,195 @@
+// Package ciconfig analyzes CI pipeline configurations, GitHub Actions
+// workflows and GitLab CI files, together with the log of a failed run.
+package ciconfig
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+var (
+	// untrustedExpressionPattern matches expressions of event fields an
+	// attacker controls (titles, bodies, branch names) which, expanded in a
+	// run script, allow script injection. Numbers and IDs are safe.
+	untrustedExpressionPattern = regexp.MustCompile(`\$\{\{\s*(github\.head_ref|github\.event\.(?:issue|pull_request|comment|review|review_comment|discussion|discussion_comment|head_commit|commits|pages|workflow_run)\b[\w.\[\]*]*)\s*\}\}`)
+
+	// safeFieldPattern matches event fields that cannot carry a payload.
+	safeFieldPattern = regexp.MustCompile(`\.(?:number|id|sha|merged|draft|state|created_at|updated_at)$`)
+
+	// secretNamePattern matches variable names that hold credentials.
+	secretNamePattern = regexp.MustCompile(`(?i)(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|ACCESS_?KEY)$`)
+)
+
+// defaultStages are the GitLab stages of a file that declares none.
+var defaultStages = []string{".pre", "build", "test", "deploy", ".post"}
+
+// Lint checks a pipeline against CI best practices and returns the
+// findings in config order.
+func Lint(p *Pipeline) []domain.PipelineFinding {
+	l := &linter{reported: map[string]bool{}}
+	switch p.Provider {
+	case ProviderGitHubActions:
+		l.github(p)
+	case ProviderGitLabCI:
+		l.gitlab(p)
+	}
+
+	l.secrets("", p.Env)
+	for _, job := range p.Jobs {
+		l.secrets(job.ID, job.Env)
+		for _, step := range job.Steps {
+			l.secrets(job.ID, step.Env)
+			l.secrets(job.ID, step.With)
+		}
+	}
+
+	sort.SliceStable(l.findings, func(i, j int) bool { return l.findings[i].Line < l.findings[j].Line })
+	return l.findings
+}
+
+type linter struct {
+	findings []domain.PipelineFinding
+
+	// reported holds the rule and line of each finding: GitLab jobs repeat
+	// the settings of the templates they extend and of the defaults.
+	reported map[string]bool
+}
+
+func (l *linter) add(line int, job, rule string, severity domain.Severity, message string) {
+	key := fmt.Sprintf("%s@%d", rule, line)
+	if line > 0 && l.reported[key] {
+		return
+	}
+	l.reported[key] = true
+	l.findings = append(l.findings, domain.PipelineFinding{
+		Rule:     rule,
+		Severity: severity,
+		Line:     line,
+		Job:      job,
+		Message:  message,
+	})
+}
+
+// github checks a GitHub Actions workflow.
+func (l *linter) github(p *Pipeline) {
+	prTarget := false
+	for _, event := range p.Events {
+		if event == "pull_request_target" {
+			prTarget = true
+		}
+	}
+
+	if p.Permissions == nil {
+		for _, job := range p.Jobs {
+			if job.Permissions == nil {
+				l.add(job.Line, job.ID, "no_permissions", domain.SeverityLow, "job "+job.ID+" has no permissions block; its GITHUB_TOKEN gets the repository default, which may be read-write for every scope")
+			}
+		}
+	}
+
+	for _, job := range p.Jobs {
+		for _, step := range job.Steps {
+			if step.Uses != "" {
+				l.action(job, step)
+				if prTarget && strings.HasPrefix(step.Uses, "actions/checkout@") {
+					for _, input := range step.With {
+						if input.Name == "ref" && (strings.Contains(input.Value, "pull_request.head") || strings.Contains(input.Value, "head_ref")) {
+							l.add(step.Line, job.ID, "pull_request_target_checkout", domain.SeverityHigh, "pull_request_target workflow checks out the pull request head; code from forks runs with a write token and the repository secrets")
+						}
+					}
+				}
+			}
+			for _, m := range untrustedExpressionPattern.FindAllStringSubmatch(step.Run, -1) {
+				if safeFieldPattern.MatchString(m[1]) {
+					continue
+				}
+				l.add(step.Line, job.ID, "script_injection", domain.SeverityHigh, "run script expands ${{ "+m[1]+" }}, which the event author controls; pass it through env and quote the variable")
+				break
+			}
+		}
+	}
+}
+
+// action checks the reference of an action.
+func (l *linter) action(job Job, step Step) {
+	if strings.HasPrefix(step.Uses, "./") || strings.HasPrefix(step.Uses, "docker://") {
+		return
+	}
+	name, ref, ok := strings.Cut(step.Uses, "@")
+	switch {
+	case !ok:
+		l.add(step.Line, job.ID, "unpinned_action", domain.SeverityMedium, step.Uses+" has no version; pin a release tag or commit SHA")
+	case ref == "main" || ref == "master":
+		l.add(step.Line, job.ID, "unpinned_action", domain.SeverityMedium, name+" follows the "+ref+" branch, which can change under the workflow; pin a release tag or commit SHA")
+	}
+}
+
+// gitlab checks a GitLab CI file.
+func (l *linter) gitlab(p *Pipeline) {
+	stages := p.Stages
+	if stages == nil {
+		stages = defaultStages
+	}
+	known := map[string]bool{}
+	for _, job := range p.Jobs {
+		known[job.ID] = true
+	}
+
+	for _, job := range p.Jobs {
+		for _, name := range job.Extends {
+			if !known[name] {
+				l.add(job.Line, job.ID, "undefined_extends", domain.SeverityHigh, "job "+job.ID+" extends "+name+", which is not defined in this file")
+			}
+		}
+		if job.Hidden {
+			continue
+		}
+		if !contains(stages, job.Stage) {
+			l.add(job.Line, job.ID, "undefined_stage", domain.SeverityHigh, "job "+job.ID+" uses stage "+job.Stage+", which is not in stages ("+strings.Join(stages, ", ")+")")
+		}
+		for _, need := range job.Needs {
+			if !known[need] {
+				l.add(job.Line, job.ID, "undefined_needs", domain.SeverityHigh, "job "+job.ID+" needs "+need+", which is not defined in this file")
+			}
+		}
+		if job.Image != "" && !strings.Contains(job.Image, "$") && unpinnedImage(job.Image) {
+			l.add(job.ImageLine, job.ID, "latest_image", domain.SeverityLow, "job "+job.ID+" runs in "+job.Image+" without a version tag; pin a tag or digest")
+		}
+		if job.OnlyLine > 0 {
+			l.add(job.OnlyLine, job.ID, "deprecated_only_except", domain.SeverityLow, "job "+job.ID+" uses only/except, which are deprecated; use rules")
+		}
+	}
+}
+
+// secrets reports credentials written into the config.
+func (l *linter) secrets(job string, vars []Variable) {
+	for _, v := range vars {
+		if secretNamePattern.MatchString(v.Name) && v.Value != "" && !strings.Contains(v.Value, "$") {
+			l.add(v.Line, job, "hardcoded_secret", domain.SeverityHigh, v.Name+" is written into the config; store it as a secret or CI/CD variable")
+		}
+	}
+}
+
+// unpinnedImage reports whether an image reference has no tag or digest,
+// or the latest tag.
+func unpinnedImage(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, ok := strings.Cut(name, ":")
+	return !ok || tag == "latest"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
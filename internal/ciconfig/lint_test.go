@@ -0,0 +1,86 @@
+// Package ciconfig provides unit tests for pipeline config parsing, linting, failing step detection and log correlation.
+package ciconfig
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string // rule@line
+	}{
+		{
+			name:   "clean github workflow",
+			config: githubWorkflow,
+		},
+		{
+			name:   "clean gitlab pipeline",
+			config: gitlabPipeline,
+		},
+		{
+			name: "github security",
+			config: "on: pull_request_target\n" +
+				"jobs:\n" +
+				"  greet:\n" +
+				"    runs-on: ubuntu-latest\n" +
+				"    env:\n" +
+				"      NPM_TOKEN: npm_abc123\n" +
+				"    steps:\n" +
+				"      - uses: actions/checkout@v4\n" +
+				"        with:\n" +
+				"          ref: ${{ github.event.pull_request.head.sha }}\n" +
+				"      - uses: acme/deploy@main\n" +
+				"      - uses: ./.github/actions/local\n" +
+				"      - run: echo \"${{ github.event.pull_request.title }}\" #${{ github.event.pull_request.number }}\n",
+			want: []string{"no_permissions@3", "hardcoded_secret@6", "pull_request_target_checkout@8", "unpinned_action@11", "script_injection@13"},
+		},
+		{
+			name: "safe expressions",
+			config: "on: pull_request\npermissions: read-all\njobs:\n  a:\n    steps:\n" +
+				"      - run: echo ${{ github.event.pull_request.number }} ${{ github.sha }}\n" +
+				"      - run: echo \"$TITLE\"\n        env:\n          TITLE: ${{ github.event.pull_request.title }}\n",
+		},
+		{
+			name: "gitlab references",
+			config: "stages: [build, test]\n" +
+				"image: node:latest\n" +
+				"lint:\n" +
+				"  stage: verify\n" +
+				"  extends: .missing\n" +
+				"  needs: [compile]\n" +
+				"  script: npm run lint\n" +
+				"  only:\n" +
+				"    - main\n" +
+				"deploy:\n" +
+				"  stage: test\n" +
+				"  variables:\n" +
+				"    DEPLOY_PASSWORD: hunter2\n" +
+				"    DEPLOY_TOKEN: $VAULT_TOKEN\n" +
+				"  script: ./deploy.sh\n",
+			want: []string{
+				"latest_image@2", "undefined_extends@3", "undefined_stage@3", "undefined_needs@3",
+				"deprecated_only_except@8", "hardcoded_secret@13",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse("", tt.config)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var got []string
+			for _, f := range Lint(p) {
+				got = append(got, fmt.Sprintf("%s@%d", f.Rule, f.Line))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,472 @@
+// Package ciconfig analyzes CI pipeline configurations, GitHub Actions
+// workflows and GitLab CI files, together with the log of a failed run.
+// Users correlate the two by hand today: which step failed, whether the
+// variable the script missed is defined anywhere, whether the job's token
+// has the permission the API call needed. Parse reads the configuration,
+// FailingStep finds the step the log shows failing, Lint checks the
+// configuration and Correlate checks it against the errors in the log.
+package ciconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// Provider is a CI system.
+type Provider string
+
+const (
+	ProviderGitHubActions Provider = "github_actions"
+	ProviderGitLabCI      Provider = "gitlab_ci"
+)
+
+// ErrEmptyConfig indicates a request without a pipeline configuration.
+var ErrEmptyConfig = fmt.Errorf("%w: request has no pipeline config", domain.ErrEmptyLog)
+
+// Pipeline is a parsed pipeline configuration.
+type Pipeline struct {
+	Provider Provider
+	Name     string
+
+	// Events are the events that trigger a GitHub Actions workflow.
+	Events []string
+
+	// Env holds the workflow env (GitHub) or the global variables (GitLab).
+	Env []Variable
+
+	// Permissions are the workflow's GITHUB_TOKEN permissions, or nil if
+	// the workflow sets none.
+	Permissions *Permissions
+
+	// Stages are the GitLab stages, or nil if the file declares none.
+	Stages []string
+
+	Jobs []Job
+}
+
+// Variable is an environment variable, GitLab variable or step input.
+type Variable struct {
+	Name  string
+	Value string
+	Line  int
+}
+
+// Permissions are GITHUB_TOKEN permissions: read-all, write-all or
+// per-scope levels (read, write, none).
+type Permissions struct {
+	Line   int
+	All    string
+	Scopes map[string]string
+}
+
+// Level returns the permission level the token has for scope.
+func (p *Permissions) Level(scope string) string {
+	switch p.All {
+	case "read-all":
+		return "read"
+	case "write-all":
+		return "write"
+	}
+	if level, ok := p.Scopes[scope]; ok {
+		return level
+	}
+	// Scopes left out of a permissions block get no access
+	return "none"
+}
+
+// String describes the permissions as configured.
+func (p *Permissions) String() string {
+	if p.All != "" {
+		return p.All
+	}
+	if len(p.Scopes) == 0 {
+		return "{}"
+	}
+	scopes := make([]string, 0, len(p.Scopes))
+	for scope, level := range p.Scopes {
+		scopes = append(scopes, scope+": "+level)
+	}
+	sort.Strings(scopes)
+	return strings.Join(scopes, ", ")
+}
+
+// Job is a job of the pipeline.
+type Job struct {
+	// ID is the job's key; Name its display name, or the ID.
+	ID   string
+	Name string
+	Line int
+
+	Env         []Variable
+	Permissions *Permissions
+
+	Steps []Step
+
+	// GitLab job settings. Extends and default settings are resolved
+	// into the job; Hidden marks templates (".name").
+	Stage     string
+	Image     string
+	ImageLine int
+	Needs     []string
+	Extends   []string
+	Hidden    bool
+	OnlyLine  int
+}
+
+// Step is a step of a GitHub Actions job or a script line of a GitLab
+// job.
+type Step struct {
+	// Name is the step's name (GitHub) or the script section (GitLab:
+	// before_script, script, after_script).
+	Name string
+	Line int
+
+	// Uses is the action a GitHub step runs; Run the shell command.
+	Uses string
+	Run  string
+
+	With []Variable
+	Env  []Variable
+}
+
+// Title describes a GitHub Actions step as the run log does: its name, or
+// "Run " and the action or the first line of the command.
+func (s Step) Title() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.Uses != "":
+		return "Run " + s.Uses
+	}
+	return "Run " + firstLine(s.Run)
+}
+
+// Command returns the action or the first line of the command the step
+// runs.
+func (s Step) Command() string {
+	if s.Uses != "" {
+		return s.Uses
+	}
+	return firstLine(s.Run)
+}
+
+// Parse reads a pipeline configuration. An empty provider is detected
+// from the document: GitHub Actions workflows have "on" and "jobs", GitLab
+// CI files have neither.
+func Parse(provider Provider, content string) (*Pipeline, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, ErrEmptyConfig
+	}
+	root, err := parseYAML(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid pipeline config: %v", domain.ErrInvalidRequest, err)
+	}
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: invalid pipeline config: the document is not a mapping", domain.ErrInvalidRequest)
+	}
+
+	if provider == "" {
+		provider = ProviderGitLabCI
+		// YAML 1.1 parsers read an unquoted on as true
+		if get(root, "jobs") != nil && (get(root, "on") != nil || get(root, "true") != nil) {
+			provider = ProviderGitHubActions
+		}
+	}
+	switch provider {
+	case ProviderGitHubActions:
+		return parseGitHub(root), nil
+	case ProviderGitLabCI:
+		p, err := parseGitLab(root)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid pipeline config: %v", domain.ErrInvalidRequest, err)
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("%w: unknown provider %q (want github_actions or gitlab_ci)", domain.ErrInvalidRequest, provider)
+}
+
+// parseGitHub reads a GitHub Actions workflow.
+func parseGitHub(root *yaml.Node) *Pipeline {
+	p := &Pipeline{
+		Provider:    ProviderGitHubActions,
+		Name:        text(get(root, "name")),
+		Env:         variables(get(root, "env")),
+		Permissions: permissions(root, "permissions"),
+	}
+	on := get(root, "on")
+	if on == nil {
+		on = get(root, "true")
+	}
+	if on != nil && on.Kind == yaml.MappingNode {
+		for _, event := range pairs(on) {
+			p.Events = append(p.Events, event.key.Value)
+		}
+	} else {
+		p.Events = texts(on)
+	}
+
+	for _, entry := range pairs(get(root, "jobs")) {
+		node := entry.value
+		job := Job{
+			ID:          entry.key.Value,
+			Name:        text(get(node, "name")),
+			Line:        entry.key.Line,
+			Env:         variables(get(node, "env")),
+			Permissions: permissions(node, "permissions"),
+		}
+		if job.Name == "" {
+			job.Name = job.ID
+		}
+		if steps := get(node, "steps"); steps != nil && steps.Kind == yaml.SequenceNode {
+			for _, item := range steps.Content {
+				item = resolve(item)
+				job.Steps = append(job.Steps, Step{
+					Name: text(get(item, "name")),
+					Line: item.Line,
+					Uses: text(get(item, "uses")),
+					Run:  text(get(item, "run")),
+					With: variables(get(item, "with")),
+					Env:  variables(get(item, "env")),
+				})
+			}
+		}
+		p.Jobs = append(p.Jobs, job)
+	}
+	return p
+}
+
+// gitlabKeywords are the top-level keys of a GitLab CI file that are not
+// jobs.
+var gitlabKeywords = map[string]bool{
+	"default": true, "include": true, "stages": true, "variables": true, "workflow": true,
+	"image": true, "services": true, "cache": true, "before_script": true, "after_script": true,
+	"spec": true,
+}
+
+// gitlabTopLevelDefaults are the settings a GitLab CI file may still set
+// at the top level instead of under default.
+var gitlabTopLevelDefaults = map[string]bool{"image": true, "before_script": true, "after_script": true}
+
+// gitlabScripts are the script sections of a GitLab job, in run order.
+var gitlabScripts = []string{"before_script", "script", "after_script"}
+
+// parseGitLab reads a GitLab CI file, resolving extends and defaults.
+func parseGitLab(root *yaml.Node) (*Pipeline, error) {
+	p := &Pipeline{
+		Provider: ProviderGitLabCI,
+		Env:      variables(get(root, "variables")),
+		Stages:   texts(get(root, "stages")),
+	}
+	defaults := get(root, "default")
+
+	entries := pairs(root)
+	templates := map[string]*yaml.Node{}
+	for _, entry := range entries {
+		if !gitlabKeywords[entry.key.Value] && entry.value.Kind == yaml.MappingNode {
+			templates[entry.key.Value] = entry.value
+		}
+	}
+
+	for _, entry := range entries {
+		if gitlabKeywords[entry.key.Value] || entry.value.Kind != yaml.MappingNode {
+			continue
+		}
+		node := entry.value
+		job := Job{
+			ID:      entry.key.Value,
+			Name:    entry.key.Value,
+			Line:    entry.key.Line,
+			Hidden:  strings.HasPrefix(entry.key.Value, "."),
+			Extends: texts(get(node, "extends")),
+		}
+		chain := extendsChain(node, templates)
+
+		lookup := func(key string) *yaml.Node {
+			for _, n := range chain {
+				if value := get(n, key); value != nil {
+					return value
+				}
+			}
+			if value := get(defaults, key); value != nil {
+				return value
+			}
+			if gitlabTopLevelDefaults[key] {
+				return get(root, key)
+			}
+			return nil
+		}
+
+		job.Stage = text(lookup("stage"))
+		if job.Stage == "" {
+			job.Stage = "test"
+		}
+		if image := lookup("image"); image != nil {
+			if image.Kind == yaml.MappingNode {
+				image = get(image, "name")
+			}
+			if image != nil {
+				job.Image, job.ImageLine = text(image), image.Line
+			}
+		}
+		if needs := lookup("needs"); needs != nil && needs.Kind == yaml.SequenceNode {
+			for _, need := range needs.Content {
+				need = resolve(need)
+				if need.Kind == yaml.MappingNode {
+					need = get(need, "job")
+				}
+				if name := text(need); name != "" {
+					job.Needs = append(job.Needs, name)
+				}
+			}
+		}
+		if line := keyLine(node, "only"); line > 0 {
+			job.OnlyLine = line
+		} else if line := keyLine(node, "except"); line > 0 {
+			job.OnlyLine = line
+		}
+
+		// Variables of templates apply first, the job's own override them
+		for i := len(chain) - 1; i >= 0; i-- {
+			job.Env = append(job.Env, variables(get(chain[i], "variables"))...)
+		}
+		for _, section := range gitlabScripts {
+			script := lookup(section)
+			if script == nil {
+				continue
+			}
+			lines, err := scriptLines(root, script)
+			if err != nil {
+				return nil, fmt.Errorf("job %s: %s: %w", job.ID, section, err)
+			}
+			for _, line := range lines {
+				job.Steps = append(job.Steps, Step{Name: section, Line: line.Line, Run: line.Value})
+			}
+		}
+		p.Jobs = append(p.Jobs, job)
+	}
+	return p, nil
+}
+
+// extendsChain returns a job followed by the templates it extends, nearest
+// first. Cycles and unknown templates are skipped.
+func extendsChain(node *yaml.Node, templates map[string]*yaml.Node) []*yaml.Node {
+	chain := []*yaml.Node{node}
+	seen := map[*yaml.Node]bool{node: true}
+	for i := 0; i < len(chain); i++ {
+		names := texts(get(chain[i], "extends"))
+		// The last template listed takes precedence
+		for j := len(names) - 1; j >= 0; j-- {
+			template := templates[names[j]]
+			if template != nil && !seen[template] {
+				seen[template] = true
+				chain = append(chain, template)
+			}
+		}
+	}
+	return chain
+}
+
+// scriptLines returns the commands of a GitLab script: a string or a
+// sequence of strings, nested sequences flattened and !reference tags
+// ([.template, script]) resolved against root. A !reference that refers
+// back to itself, or nesting deeper than GitLab allows, is an error.
+func scriptLines(root, node *yaml.Node) ([]*yaml.Node, error) {
+	budget := maxExpandedNodes
+	return referencedLines(root, node, nil, &budget)
+}
+
+// maxReferenceDepth bounds nested !reference tags, which GitLab limits to
+// ten levels.
+const maxReferenceDepth = 10
+
+// referencedLines walks a script node. targets holds the !reference targets
+// being expanded, budget the nodes left to visit.
+func referencedLines(root, node *yaml.Node, targets []*yaml.Node, budget *int) ([]*yaml.Node, error) {
+	if *budget--; *budget < 0 {
+		return nil, fmt.Errorf("script expands to more than %d nodes", maxExpandedNodes)
+	}
+	node = resolve(node)
+	if node.Tag == "!reference" {
+		if len(targets) >= maxReferenceDepth {
+			return nil, fmt.Errorf("line %d: !reference nested more than %d levels", node.Line, maxReferenceDepth)
+		}
+		target := root
+		for _, key := range texts(node) {
+			target = get(target, key)
+		}
+		if target == nil {
+			return nil, nil
+		}
+		for _, t := range targets {
+			if t == target {
+				return nil, fmt.Errorf("line %d: !reference [%s] refers to itself", node.Line, strings.Join(texts(node), ", "))
+			}
+		}
+		return referencedLines(root, target, append(targets, target), budget)
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if text(node) == "" {
+			return nil, nil
+		}
+		return []*yaml.Node{node}, nil
+	case yaml.SequenceNode:
+		var lines []*yaml.Node
+		for _, item := range node.Content {
+			itemLines, err := referencedLines(root, item, targets, budget)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, itemLines...)
+		}
+		return lines, nil
+	}
+	return nil, nil
+}
+
+// variables returns the entries of an env, variables or with mapping.
+// GitLab variables may be mappings with a value key.
+func variables(node *yaml.Node) []Variable {
+	entries := pairs(node)
+	if len(entries) == 0 {
+		return nil
+	}
+	vars := make([]Variable, 0, len(entries))
+	for _, entry := range entries {
+		value := entry.value
+		if value.Kind == yaml.MappingNode {
+			value = get(value, "value")
+		}
+		vars = append(vars, Variable{Name: entry.key.Value, Value: text(value), Line: entry.key.Line})
+	}
+	return vars
+}
+
+// permissions reads the GitHub permissions block under key, or returns
+// nil.
+func permissions(parent *yaml.Node, key string) *Permissions {
+	node := get(parent, key)
+	if node == nil {
+		return nil
+	}
+	p := &Permissions{Line: keyLine(parent, key), Scopes: map[string]string{}}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		p.All = text(node)
+	case yaml.MappingNode:
+		for _, scope := range pairs(node) {
+			p.Scopes[scope.key.Value] = text(scope.value)
+		}
+	}
+	return p
+}
+
+// firstLine returns s up to its first newline.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
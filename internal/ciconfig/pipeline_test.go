@@ -0,0 +1,278 @@
+// Package ciconfig provides unit tests for pipeline config parsing, linting, failing step detection and log correlation.
+package ciconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+const githubWorkflow = `name: CI
+on:
+  push:
+    branches: [main]
+  pull_request:
+
+permissions:
+  contents: read
+
+env:
+  NODE_ENV: test
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Install
+        run: npm ci
+      - run: |
+          npm test
+          npm run lint
+        env:
+          API_URL: https://api.example.com
+  comment:
+    needs: build
+    runs-on: ubuntu-latest
+    steps:
+      - run: gh pr comment ${{ github.event.number }} --body "Tests passed"
+        env:
+          GH_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+`
+
+const gitlabPipeline = `stages:
+  - build
+  - test
+
+variables:
+  GO_VERSION: "1.22"
+
+default:
+  image: golang:1.22
+
+.setup:
+  before_script:
+    - go mod download
+  variables:
+    CGO_ENABLED: "0"
+
+build:
+  stage: build
+  extends: .setup
+  script:
+    - go build ./...
+
+test:
+  extends: .setup
+  needs: [build]
+  script:
+    - go vet ./...
+    - go test ./...
+  after_script:
+    - echo done
+`
+
+func TestParse_GitHubActions(t *testing.T) {
+	p, err := Parse("", githubWorkflow)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Provider != ProviderGitHubActions || p.Name != "CI" {
+		t.Errorf("provider, name = %q, %q", p.Provider, p.Name)
+	}
+	if want := []string{"push", "pull_request"}; !reflect.DeepEqual(p.Events, want) {
+		t.Errorf("Events = %q, want %q", p.Events, want)
+	}
+	if p.Permissions == nil || p.Permissions.Level("contents") != "read" || p.Permissions.Level("issues") != "none" {
+		t.Errorf("Permissions = %+v, want contents: read only", p.Permissions)
+	}
+	if len(p.Jobs) != 2 || p.Jobs[0].ID != "build" || p.Jobs[0].Line != 14 {
+		t.Fatalf("Jobs = %+v", p.Jobs)
+	}
+
+	var got []string
+	for _, step := range p.Jobs[0].Steps {
+		got = append(got, step.Title())
+	}
+	if want := []string{"Run actions/checkout@v4", "Install", "Run npm test"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("step titles = %q, want %q", got, want)
+	}
+	if step := p.Jobs[0].Steps[2]; step.Line != 20 || len(step.Env) != 1 || step.Env[0].Name != "API_URL" {
+		t.Errorf("third step = %+v", step)
+	}
+}
+
+func TestParse_GitLabCI(t *testing.T) {
+	p, err := Parse("", gitlabPipeline)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Provider != ProviderGitLabCI {
+		t.Errorf("Provider = %q, want gitlab_ci", p.Provider)
+	}
+	if want := []string{"build", "test"}; !reflect.DeepEqual(p.Stages, want) {
+		t.Errorf("Stages = %q, want %q", p.Stages, want)
+	}
+
+	test, ok := p.FindJob("test")
+	if !ok {
+		t.Fatal("job test not found")
+	}
+	var commands []string
+	for _, step := range test.Steps {
+		commands = append(commands, step.Name+": "+step.Run)
+	}
+	want := []string{"before_script: go mod download", "script: go vet ./...", "script: go test ./...", "after_script: echo done"}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("steps = %q, want %q", commands, want)
+	}
+	if test.Stage != "test" || test.Image != "golang:1.22" || !reflect.DeepEqual(test.Needs, []string{"build"}) {
+		t.Errorf("stage, image, needs = %q, %q, %q", test.Stage, test.Image, test.Needs)
+	}
+	if len(test.Env) != 1 || test.Env[0].Name != "CGO_ENABLED" {
+		t.Errorf("Env = %+v, want CGO_ENABLED from .setup", test.Env)
+	}
+	if setup, _ := p.FindJob(".setup"); !setup.Hidden {
+		t.Error(".setup should be hidden")
+	}
+}
+
+func TestParse_AnchorsAndTags(t *testing.T) {
+	const config = `.defaults: &defaults
+  image: node:20
+  stage: build
+  variables:
+    CI_DEBUG: "false"
+
+.setup:
+  script:
+    - npm ci
+
+lint:
+  stage: test
+  <<: *defaults
+  script:
+    - !reference [.setup, script]
+    - npm run lint
+`
+	p, err := Parse("", config)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	lint, ok := p.FindJob("lint")
+	if !ok {
+		t.Fatal("job lint not found")
+	}
+	// The job's own stage wins over the merged one
+	if lint.Stage != "test" || lint.Image != "node:20" || lint.ImageLine != 2 {
+		t.Errorf("stage, image, image line = %q, %q, %d", lint.Stage, lint.Image, lint.ImageLine)
+	}
+	if len(lint.Env) != 1 || lint.Env[0].Name != "CI_DEBUG" {
+		t.Errorf("Env = %+v, want CI_DEBUG from the anchor", lint.Env)
+	}
+	var got []string
+	for _, step := range lint.Steps {
+		got = append(got, fmt.Sprintf("%s@%d", step.Run, step.Line))
+	}
+	if want := []string{"npm ci@9", "npm run lint@16"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("steps = %q, want %q", got, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		config   string
+		want     error
+	}{
+		{"empty", "", "  \n", domain.ErrEmptyLog},
+		{"invalid yaml", "", "jobs:\n\tbuild: {}\n", domain.ErrInvalidRequest},
+		{"unknown alias", "", "build:\n  <<: *missing\n", domain.ErrInvalidRequest},
+		{"merge key cycle", "", "on: push\njobs:\n  build: &a\n    <<: *a\n    runs-on: ubuntu\n    steps:\n      - run: make\n", domain.ErrInvalidRequest},
+		{"sequence alias cycle", "", "stages: &s [*s]\nbuild:\n  script: make\n", domain.ErrInvalidRequest},
+		{"alias expansion", "", nestedConfig("&a%[1]d ["+strings.Repeat("*a%[2]d, ", 9)+"*a%[2]d]", "stages: *a6\n"), domain.ErrInvalidRequest},
+		{"reference cycle", "", ".setup:\n  script:\n    - !reference [.setup, script]\nbuild:\n  extends: .setup\n", domain.ErrInvalidRequest},
+		{"reference expansion", "", nestedConfig("{script: ["+strings.Repeat("!reference [.t%[2]d, script], ", 9)+"!reference [.t%[2]d, script]]}", "build:\n  extends: .t6\n"), domain.ErrInvalidRequest},
+		{"comments only", "", "# nothing yet\n", domain.ErrInvalidRequest},
+		{"not a mapping", "", "- a\n- b\n", domain.ErrInvalidRequest},
+		{"unknown provider", "jenkins", "a: b\n", domain.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.provider, tt.config); !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// nestedConfig returns a document of templates .t0 to .t6 followed by
+// tail. .t0 is a script anchored as a0, level formats the others with their
+// index and the index of the template before it.
+func nestedConfig(level, tail string) string {
+	var b strings.Builder
+	b.WriteString(".t0: &a0 {script: [make]}\n")
+	for i := 1; i <= 6; i++ {
+		fmt.Fprintf(&b, ".t%d: "+level+"\n", i, i-1)
+	}
+	return b.String() + tail
+}
+
+func TestRequest_AnalysisRequest(t *testing.T) {
+	req := &Request{
+		Config: githubWorkflow,
+		Log:    "Run gh pr comment 42 --body \"Tests passed\"\nGraphQL: Resource not accessible by integration (addComment)\n##[error]Process completed with exit code 1.",
+		Detail: domain.DetailBrief,
+	}
+	got, review, err := req.AnalysisRequest()
+	if err != nil {
+		t.Fatalf("AnalysisRequest() error = %v", err)
+	}
+
+	var names []string
+	for _, section := range got.Sections {
+		names = append(names, section.Name)
+	}
+	if want := []string{"failure log", "pipeline config", "findings"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sections = %q, want %q", names, want)
+	}
+	if got.Mode != domain.AnalysisModeCI || got.Detail != domain.DetailBrief {
+		t.Errorf("mode, detail = %q, %q", got.Mode, got.Detail)
+	}
+	if !strings.HasPrefix(got.Sections[1].Content, "   1 | name: CI\n   2 | on:") {
+		t.Errorf("config section = %q", got.Sections[1].Content)
+	}
+	if !strings.HasPrefix(got.Sections[2].Content, "failing step: line 29: job comment: gh pr comment") {
+		t.Errorf("findings section = %q", got.Sections[2].Content)
+	}
+	if review.Provider != "github_actions" || review.FailingStep == nil || review.FailingStep.Job != "comment" {
+		t.Errorf("review = %+v", review)
+	}
+	want := []string{
+		`config line 29: job comment, step gh pr comment ${{ github.event.number }} --body "Tests passed"`,
+		"config line 7: GITHUB_TOKEN was denied the API call; the workflow grants pull-requests: none and the API call needs pull-requests: write",
+	}
+	if got := Evidence(review); !reflect.DeepEqual(got, want) {
+		t.Errorf("Evidence() = %q, want %q", got, want)
+	}
+
+	errorTests := []struct {
+		name string
+		req  *Request
+		want error
+	}{
+		{"no log", &Request{Config: githubWorkflow}, domain.ErrEmptyLog},
+		{"no config", &Request{Log: "error"}, domain.ErrEmptyLog},
+		{"unknown job", &Request{Config: githubWorkflow, Log: "error", Job: "deploy"}, domain.ErrInvalidRequest},
+	}
+	for _, tt := range errorTests {
+		if _, _, err := tt.req.AnalysisRequest(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
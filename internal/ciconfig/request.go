@@ -0,0 +1,136 @@
+// Package ciconfig analyzes CI pipeline configurations, GitHub Actions
+// workflows and GitLab CI files, together with the log of a failed run.
+package ciconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Request is a pipeline analysis request: the workflow or pipeline config
+// and the log of the failed run, with the options of
+// domain.AnalysisRequest.
+type Request struct {
+	// Provider is github_actions or gitlab_ci; empty detects it from the
+	// config.
+	Provider Provider `json:"provider,omitempty"`
+	Config   string   `json:"config"`
+	Log      string   `json:"log"`
+
+	// Job is the ID or name of the failed job, to narrow the search for
+	// the failing step.
+	Job string `json:"job,omitempty"`
+
+	Language  string              `json:"language,omitempty"`
+	Detail    domain.DetailLevel  `json:"detail,omitempty"`
+	Metadata  *domain.LogMetadata `json:"metadata,omitempty"`
+	Explain   bool                `json:"explain,omitempty"`
+	TimeoutMS int                 `json:"timeout_ms,omitempty"`
+}
+
+// AnalysisRequest reviews the config against the log and returns the
+// analysis request for r in the CI mode with the review. The failure log,
+// the config with line numbers and the findings become sections, in that
+// order, so rules and the AI see the failure next to the config.
+func (r *Request) AnalysisRequest() (*domain.AnalysisRequest, *domain.PipelineReview, error) {
+	if strings.TrimSpace(r.Log) == "" {
+		return nil, nil, domain.ErrEmptyLog
+	}
+	pipeline, err := Parse(r.Provider, r.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.Job != "" {
+		if _, ok := pipeline.FindJob(r.Job); !ok {
+			return nil, nil, fmt.Errorf("%w: job %q is not defined in the pipeline config", domain.ErrInvalidRequest, r.Job)
+		}
+	}
+
+	review := &domain.PipelineReview{Provider: string(pipeline.Provider)}
+	var job *Job
+	var step *Step
+	if j, s, ok := FailingStep(pipeline, r.Log, r.Job); ok {
+		job, step = &j, &s
+		review.FailingStep = &domain.PipelineStep{Job: j.ID, Name: s.Name, Line: s.Line, Command: s.Command()}
+	} else if r.Job != "" {
+		j, _ := pipeline.FindJob(r.Job)
+		job = &j
+	}
+	// Findings about the failure come before the static ones
+	review.Findings = append(Correlate(pipeline, r.Log, job, step), Lint(pipeline)...)
+	if review.Findings == nil {
+		review.Findings = []domain.PipelineFinding{}
+	}
+
+	sections := []domain.LogSection{
+		{Name: "failure log", Content: r.Log},
+		{Name: "pipeline config", Content: numberLines(r.Config)},
+	}
+	if summary := renderReview(review); summary != "" {
+		sections = append(sections, domain.LogSection{Name: "findings", Content: summary})
+	}
+
+	return &domain.AnalysisRequest{
+		Sections:  sections,
+		Language:  r.Language,
+		Metadata:  r.Metadata,
+		Detail:    r.Detail,
+		Mode:      domain.AnalysisModeCI,
+		Explain:   r.Explain,
+		TimeoutMS: r.TimeoutMS,
+	}, review, nil
+}
+
+// numberLines prefixes each line with its number so answers can cite
+// lines.
+func numberLines(content string) string {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf("%4d | %s", i+1, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderReview lists the failing step and the findings, one per line, or
+// returns "".
+func renderReview(review *domain.PipelineReview) string {
+	var lines []string
+	if step := review.FailingStep; step != nil {
+		lines = append(lines, fmt.Sprintf("failing step: line %d: job %s: %s", step.Line, step.Job, step.Command))
+	}
+	for _, f := range review.Findings {
+		lines = append(lines, fmt.Sprintf("line %d [%s] %s: %s", f.Line, f.Severity, f.Rule, f.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Evidence returns the failing step and the findings about the failure of
+// review as result evidence, or nil.
+func Evidence(review *domain.PipelineReview) []string {
+	if review == nil {
+		return nil
+	}
+	var evidence []string
+	if step := review.FailingStep; step != nil {
+		evidence = append(evidence, fmt.Sprintf("config line %d: job %s, step %s", step.Line, step.Job, step.Command))
+	}
+	for _, f := range review.Findings {
+		switch {
+		case !correlated[f.Rule]:
+		case f.Line > 0:
+			evidence = append(evidence, fmt.Sprintf("config line %d: %s", f.Line, f.Message))
+		default:
+			evidence = append(evidence, f.Message)
+		}
+	}
+	return evidence
+}
+
+// correlated are the rules of Correlate, whose findings explain the
+// failure.
+var correlated = map[string]bool{
+	"missing_permission": true, "token_cannot_update_workflows": true, "job_token_push": true,
+	"missing_input": true, "empty_secret": true, "empty_env": true, "env_scope": true, "undefined_env": true,
+}
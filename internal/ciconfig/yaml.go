@@ -0,0 +1,174 @@
+// Package ciconfig analyzes CI pipeline configurations, GitHub Actions
+// workflows and GitLab CI files, together with the log of a failed run.
+package ciconfig
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxExpandedNodes bounds the size of a document with its aliases expanded,
+// so that aliases nested in aliases cannot multiply the work of the walkers
+// below.
+const maxExpandedNodes = 100000
+
+// parseYAML parses a document and returns its root node, or nil for a
+// document without content. Aliases that refer to a node containing them
+// are an error, as the walkers below would follow them forever.
+func parseYAML(content string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	if _, err := expandedSize(doc.Content[0], map[*yaml.Node]int{}, map[*yaml.Node]bool{}); err != nil {
+		return nil, err
+	}
+	return resolve(doc.Content[0]), nil
+}
+
+// expandedSize returns the number of nodes under n with aliases expanded.
+// sizes memoizes the nodes already counted, open holds the nodes being
+// counted: an alias to one of them is a cycle.
+func expandedSize(n *yaml.Node, sizes map[*yaml.Node]int, open map[*yaml.Node]bool) (int, error) {
+	if n.Kind == yaml.AliasNode {
+		if open[n.Alias] {
+			return 0, fmt.Errorf("line %d: alias *%s refers to a node containing it", n.Line, n.Value)
+		}
+		n = n.Alias
+	}
+	if size, ok := sizes[n]; ok {
+		return size, nil
+	}
+	open[n] = true
+	size := 1
+	for _, child := range n.Content {
+		childSize, err := expandedSize(child, sizes, open)
+		if err != nil {
+			return 0, err
+		}
+		if size += childSize; size > maxExpandedNodes {
+			return 0, fmt.Errorf("line %d: document expands to more than %d nodes", n.Line, maxExpandedNodes)
+		}
+	}
+	delete(open, n)
+	sizes[n] = size
+	return size, nil
+}
+
+// resolve returns the node an alias refers to, or n itself.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// pair is a key of a mapping with its value.
+type pair struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+// pairs returns the entries of a mapping in order, with the entries of its
+// merge keys (<<: *anchor) added. As in YAML, the mapping's own keys take
+// precedence over merged ones, and earlier merged mappings over later ones.
+func pairs(n *yaml.Node) []pair {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	var merged, own []pair
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], resolve(n.Content[i+1])
+		if key.Kind != yaml.ScalarNode || key.ShortTag() != "!!merge" {
+			own = append(own, pair{key: key, value: value})
+			continue
+		}
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for j := len(sources) - 1; j >= 0; j-- {
+			merged = append(merged, pairs(sources[j])...)
+		}
+	}
+	if len(merged) == 0 {
+		return own
+	}
+
+	// Keep the last entry of each key, the one that takes precedence
+	all := append(merged, own...)
+	seen := map[string]bool{}
+	kept := make([]pair, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		if !seen[all[i].key.Value] {
+			seen[all[i].key.Value] = true
+			kept = append(kept, all[i])
+		}
+	}
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept
+}
+
+// find returns the entry of key in a mapping; later keys win.
+func find(n *yaml.Node, key string) (pair, bool) {
+	entries := pairs(n)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].key.Value == key {
+			return entries[i], true
+		}
+	}
+	return pair{}, false
+}
+
+// get returns the value of key in a mapping, or nil.
+func get(n *yaml.Node, key string) *yaml.Node {
+	e, _ := find(n, key)
+	return e.value
+}
+
+// keyLine returns the line of key in a mapping, or 0.
+func keyLine(n *yaml.Node, key string) int {
+	if e, ok := find(n, key); ok {
+		return e.key.Line
+	}
+	return 0
+}
+
+// text returns the value of a scalar, or "" for null and collections.
+func text(n *yaml.Node) string {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.ScalarNode || n.ShortTag() == "!!null" {
+		return ""
+	}
+	return n.Value
+}
+
+// texts returns the scalars of a sequence, nested sequences flattened, or
+// the scalar itself.
+func texts(n *yaml.Node) []string {
+	n = resolve(n)
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.ScalarNode {
+		if value := text(n); value != "" {
+			return []string{value}
+		}
+		return nil
+	}
+	if n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var values []string
+	for _, item := range n.Content {
+		values = append(values, texts(item)...)
+	}
+	return values
+}
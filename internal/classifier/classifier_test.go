@@ -0,0 +1,84 @@
+// Package classifier provides unit tests for the bag-of-words classifier.
+package classifier
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func trainingExamples() []Example {
+	oom := &domain.AnalysisResult{ErrorType: "out_of_memory", Severity: domain.SeverityHigh}
+	dns := &domain.AnalysisResult{ErrorType: "dns_resolution_failure", Severity: domain.SeverityMedium}
+	return []Example{
+		{Log: "container killed OOMKilled memory limit exceeded", Result: oom},
+		{Log: "java heap space OutOfMemoryError memory exhausted", Result: oom},
+		{Log: "process killed by oom killer memory cgroup", Result: oom},
+		{Log: "could not resolve host registry lookup failed", Result: dns},
+		{Log: "dial tcp lookup api.internal no such host", Result: dns},
+		{Log: "temporary failure in name resolution host lookup", Result: dns},
+	}
+}
+
+func TestTrainAndPredict(t *testing.T) {
+	model, err := Train(trainingExamples())
+	if err != nil {
+		t.Fatalf("Train() error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := model.Save(path); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	tests := []struct {
+		log  string
+		want string
+	}{
+		{"pod was OOMKilled: memory limit reached, container killed", "out_of_memory"},
+		{"error: could not resolve host: no such host during lookup", "dns_resolution_failure"},
+	}
+	for _, tt := range tests {
+		pred := loaded.Predict(tt.log)
+		if pred.Class.ErrorType != tt.want {
+			t.Errorf("Predict(%q) = %s, want %s", tt.log, pred.Class.ErrorType, tt.want)
+		}
+		if pred.Confidence <= 0.5 || pred.Confidence > 1 {
+			t.Errorf("Predict(%q) confidence = %f", tt.log, pred.Confidence)
+		}
+	}
+}
+
+func TestStage_Decide(t *testing.T) {
+	model, err := Train(trainingExamples())
+	if err != nil {
+		t.Fatalf("Train() error: %v", err)
+	}
+	log := "pod was OOMKilled: memory limit reached, container killed"
+
+	if d := NewStage(model, 0.5, 0.3).Decide(log); d == nil || !d.Skip {
+		t.Errorf("expected confident decision to skip the AI, got %+v", d)
+	}
+	if d := NewStage(model, 1.01, 0.3).Decide(log); d == nil || d.Skip || d.Hint == "" {
+		t.Errorf("expected hint-only decision, got %+v", d)
+	}
+	if d := NewStage(model, 1.01, 1.01).Decide(log); d != nil {
+		t.Errorf("expected no decision below hint threshold, got %+v", d)
+	}
+
+	var nilStage *Stage
+	if nilStage.Decide(log) != nil {
+		t.Error("nil stage should not decide")
+	}
+}
+
+func TestTrain_NeedsTwoClasses(t *testing.T) {
+	if _, err := Train(trainingExamples()[:3]); err == nil {
+		t.Error("expected error for a single class")
+	}
+}
@@ -0,0 +1,141 @@
+// Package classifier provides a lightweight bag-of-words error classifier
+// that runs between the regex rules and the AI.
+package classifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// modelVersion is the model file format version.
+const modelVersion = 1
+
+// tokenPattern splits logs into lowercase word tokens.
+var tokenPattern = regexp.MustCompile(`[a-z][a-z0-9_]{2,}`)
+
+// Class is one error type the model can predict.
+type Class struct {
+	// ErrorType is the predicted error_type.
+	ErrorType string `json:"error_type"`
+
+	// LogPrior is the log prior probability of the class.
+	LogPrior float64 `json:"log_prior"`
+
+	// Result, if set, is a complete answer served without calling the AI
+	// when the prediction is confident enough.
+	Result *domain.AnalysisResult `json:"result,omitempty"`
+
+	// PromptHint, if set, is extra guidance given to the AI when the class
+	// is predicted (e.g. what to check for this kind of failure).
+	PromptHint string `json:"prompt_hint,omitempty"`
+}
+
+// Model is a multinomial naive Bayes model over unique log tokens.
+type Model struct {
+	Version int     `json:"version"`
+	Classes []Class `json:"classes"`
+
+	// Vocabulary maps a token to its per-class log likelihood, indexed like
+	// Classes. Tokens outside the vocabulary are ignored.
+	Vocabulary map[string][]float64 `json:"vocabulary"`
+}
+
+// Prediction is the model's best guess for a log.
+type Prediction struct {
+	// Class is the predicted class.
+	Class *Class
+
+	// Confidence is the posterior probability of Class (0.0-1.0).
+	Confidence float64
+}
+
+// Load reads a model from a JSON file.
+func Load(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read classifier model: %w", err)
+	}
+
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse classifier model: %w", err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Save writes the model to a JSON file.
+func (m *Model) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("encode classifier model: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write classifier model: %w", err)
+	}
+	return nil
+}
+
+func (m *Model) validate() error {
+	if m.Version != modelVersion {
+		return fmt.Errorf("unsupported classifier model version %d", m.Version)
+	}
+	if len(m.Classes) == 0 {
+		return fmt.Errorf("classifier model has no classes")
+	}
+	for token, likelihoods := range m.Vocabulary {
+		if len(likelihoods) != len(m.Classes) {
+			return fmt.Errorf("classifier token %q has %d likelihoods, want %d", token, len(likelihoods), len(m.Classes))
+		}
+	}
+	return nil
+}
+
+// Predict returns the most probable class for log.
+func (m *Model) Predict(log string) Prediction {
+	scores := make([]float64, len(m.Classes))
+	for i, class := range m.Classes {
+		scores[i] = class.LogPrior
+	}
+	for token := range tokenize(log) {
+		if likelihoods, ok := m.Vocabulary[token]; ok {
+			for i, l := range likelihoods {
+				scores[i] += l
+			}
+		}
+	}
+
+	// Softmax over log scores gives the posterior.
+	best := 0
+	for i, score := range scores {
+		if score > scores[best] {
+			best = i
+		}
+	}
+	var total float64
+	for _, score := range scores {
+		total += math.Exp(score - scores[best])
+	}
+
+	return Prediction{
+		Class:      &m.Classes[best],
+		Confidence: 1 / total,
+	}
+}
+
+// tokenize returns the set of tokens in log.
+func tokenize(log string) map[string]struct{} {
+	tokens := make(map[string]struct{})
+	for _, token := range tokenPattern.FindAllString(strings.ToLower(log), -1) {
+		tokens[token] = struct{}{}
+	}
+	return tokens
+}
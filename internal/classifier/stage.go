@@ -0,0 +1,62 @@
+// Package classifier provides a lightweight bag-of-words error classifier
+// that runs between the regex rules and the AI.
+package classifier
+
+import "fmt"
+
+// Decision is the classifier stage's verdict for a log.
+type Decision struct {
+	Prediction
+
+	// Skip means the prediction is confident and has a ready-made result,
+	// so the AI call can be skipped.
+	Skip bool
+
+	// Hint is guidance to prepend to the AI input.
+	Hint string
+}
+
+// Stage applies a model with confidence thresholds.
+//
+// A nil *Stage makes no decisions.
+type Stage struct {
+	model         *Model
+	skipThreshold float64
+	hintThreshold float64
+}
+
+// NewStage creates a classifier stage. Predictions at or above skipThreshold
+// with a ready-made result skip the AI; predictions at or above hintThreshold
+// add a hint to the AI prompt.
+func NewStage(model *Model, skipThreshold, hintThreshold float64) *Stage {
+	return &Stage{
+		model:         model,
+		skipThreshold: skipThreshold,
+		hintThreshold: hintThreshold,
+	}
+}
+
+// Decide classifies log. Returns nil when the prediction is below the hint
+// threshold.
+func (s *Stage) Decide(log string) *Decision {
+	if s == nil {
+		return nil
+	}
+
+	pred := s.model.Predict(log)
+	if pred.Confidence < s.hintThreshold {
+		return nil
+	}
+
+	hint := fmt.Sprintf("Classifier hint: this log most likely shows a '%s' failure (confidence %.2f). Confirm or correct this.",
+		pred.Class.ErrorType, pred.Confidence)
+	if pred.Class.PromptHint != "" {
+		hint += " " + pred.Class.PromptHint
+	}
+
+	return &Decision{
+		Prediction: pred,
+		Skip:       pred.Confidence >= s.skipThreshold && pred.Class.Result != nil,
+		Hint:       hint,
+	}
+}
@@ -0,0 +1,106 @@
+// Package classifier provides a lightweight bag-of-words error classifier
+// that runs between the regex rules and the AI.
+package classifier
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// minTokenCount drops tokens seen in fewer training logs than this.
+const minTokenCount = 2
+
+// Example is a labeled training log.
+type Example struct {
+	Log    string
+	Result *domain.AnalysisResult
+}
+
+// Train fits a model on examples labeled by Result.ErrorType using Laplace
+// smoothing. Each class keeps the most recent example's result as its
+// ready-made answer.
+func Train(examples []Example) (*Model, error) {
+	index := make(map[string]int)
+	var classes []Class
+	var docCounts []int
+	tokenCounts := make(map[string][]int)
+	totalTokens := make(map[int]int)
+
+	for _, ex := range examples {
+		if ex.Result == nil || ex.Result.ErrorType == "" {
+			continue
+		}
+		ci, ok := index[ex.Result.ErrorType]
+		if !ok {
+			ci = len(classes)
+			index[ex.Result.ErrorType] = ci
+			classes = append(classes, Class{ErrorType: ex.Result.ErrorType})
+			docCounts = append(docCounts, 0)
+		}
+		result := *ex.Result
+		result.Usage = nil
+		classes[ci].Result = &result
+		docCounts[ci]++
+
+		for token := range tokenize(ex.Log) {
+			counts, ok := tokenCounts[token]
+			if !ok {
+				counts = make([]int, 0, 4)
+			}
+			for len(counts) <= ci {
+				counts = append(counts, 0)
+			}
+			counts[ci]++
+			tokenCounts[token] = counts
+			totalTokens[ci]++
+		}
+	}
+
+	if len(classes) < 2 {
+		return nil, fmt.Errorf("need examples of at least 2 error types, got %d", len(classes))
+	}
+
+	var docs int
+	for _, n := range docCounts {
+		docs += n
+	}
+	for i := range classes {
+		classes[i].LogPrior = math.Log(float64(docCounts[i]) / float64(docs))
+	}
+
+	// Keep tokens seen often enough, then smooth over the kept vocabulary.
+	var vocab []string
+	for token, counts := range tokenCounts {
+		seen := 0
+		for _, n := range counts {
+			seen += n
+		}
+		if seen >= minTokenCount {
+			vocab = append(vocab, token)
+		}
+	}
+	sort.Strings(vocab)
+
+	model := &Model{
+		Version:    modelVersion,
+		Classes:    classes,
+		Vocabulary: make(map[string][]float64, len(vocab)),
+	}
+	for _, token := range vocab {
+		counts := tokenCounts[token]
+		likelihoods := make([]float64, len(classes))
+		for ci := range classes {
+			n := 0
+			if ci < len(counts) {
+				n = counts[ci]
+			}
+			likelihoods[ci] = math.Log(float64(n+1) / float64(totalTokens[ci]+len(vocab)))
+		}
+		model.Vocabulary[token] = likelihoods
+	}
+
+	return model, nil
+}
@@ -3,8 +3,10 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ai-devops/internal/domain"
@@ -15,11 +17,72 @@ type Config struct {
 	// Server configuration
 	Server ServerConfig
 
+	// Admin API configuration
+	Admin AdminConfig
+
 	// AI service configuration
 	AI AIConfig
 
 	// Log processing configuration
 	Processing ProcessingConfig
+
+	// Inbound webhook verification configuration
+	Webhooks WebhookConfig
+
+	// Analysis storage configuration
+	Store StoreConfig
+
+	// Outbound notification configuration
+	Notify NotifyConfig
+
+	// Response policy configuration
+	Response ResponseConfig
+
+	// Log shipper ingestion configuration
+	Ingest IngestConfig
+
+	// Loki log context configuration
+	Loki LokiConfig
+
+	// Runbook knowledge source configuration
+	Knowledge KnowledgeConfig
+
+	// Issue tracker ticket configuration
+	Tickets TicketsConfig
+
+	// On-call escalation configuration
+	Escalation EscalationConfig
+
+	// Async analysis callback configuration
+	Callback CallbackConfig
+
+	// Few-shot example store configuration
+	FewShot FewShotConfig
+
+	// Embeddings and similar-incident retrieval configuration
+	Embeddings EmbeddingsConfig
+
+	// Redis shared state configuration
+	Redis RedisConfig
+
+	// Multi-model consensus configuration
+	Consensus ConsensusConfig
+
+	// AI prompt and response capture configuration
+	Capture CaptureConfig
+
+	// External plugin configuration
+	Plugins PluginConfig
+
+	// settings records the environment variables read by Load.
+	settings []Setting
+}
+
+// AdminConfig contains settings for the admin API.
+type AdminConfig struct {
+	// Token authenticates admin requests (Authorization: Bearer <token>).
+	// Empty disables the admin API.
+	Token string
 }
 
 // ServerConfig contains HTTP server settings.
@@ -32,6 +95,22 @@ type ServerConfig struct {
 
 	// WriteTimeout is the maximum duration before timing out writes of the response.
 	WriteTimeout time.Duration
+
+	// MaxRequestTimeout caps the timeout_ms an analysis request may ask for.
+	MaxRequestTimeout time.Duration
+
+	// MaxBodyBytes is the largest request body accepted; larger bodies are
+	// rejected with 413 before they are decoded.
+	MaxBodyBytes int64
+
+	// MaxLogBytes is the largest log (log plus sections) an analysis
+	// request may submit. Logs over MAX_LOG_SIZE but under this limit are
+	// truncated; larger logs are rejected with 413.
+	MaxLogBytes int
+
+	// TenantsPath, if set, loads a JSON file of tenants with their own API
+	// keys, AI model, rules, masking and rate limits (see tenant.Config).
+	TenantsPath string
 }
 
 // AIProvider represents the AI provider to use.
@@ -45,6 +124,15 @@ const (
 	AIProviderGemini AIProvider = "gemini"
 )
 
+// Gemini authentication methods.
+const (
+	// GeminiAuthAPIKey authenticates with AIConfig.APIKey.
+	GeminiAuthAPIKey = "api_key"
+
+	// GeminiAuthADC authenticates with Google Application Default Credentials.
+	GeminiAuthADC = "adc"
+)
+
 // AIConfig contains AI service settings.
 type AIConfig struct {
 	// Provider specifies which AI provider to use (openai, gemini).
@@ -53,23 +141,128 @@ type AIConfig struct {
 	// APIKey is the authentication key for the AI provider.
 	APIKey string
 
+	// GeminiAuth selects how Gemini requests authenticate: api_key sends
+	// APIKey in the x-goog-api-key header, adc uses Google Application
+	// Default Credentials (service account, gcloud user or the metadata
+	// server), as Vertex AI endpoints require.
+	GeminiAuth string
+
+	// GoogleCredentialsFile is the credentials JSON used with adc auth.
+	// Empty uses GOOGLE_APPLICATION_CREDENTIALS, then the gcloud default
+	// credentials, then the metadata server.
+	GoogleCredentialsFile string
+
+	// VertexProject and VertexLocation send Gemini requests to Vertex AI
+	// in that Google Cloud project and region instead of the public
+	// Generative Language API. Vertex requests always authenticate with ADC.
+	VertexProject  string
+	VertexLocation string
+
 	// BaseURL is the base URL for the AI API (optional, provider-specific defaults).
 	BaseURL string
 
+	// BaseURLs lists several endpoints of the provider (regions, replicas).
+	// When it has more than one entry requests are routed between them and
+	// BaseURL is ignored.
+	BaseURLs []string
+
+	// Routing selects how requests are spread over BaseURLs:
+	// round_robin or latency.
+	Routing string
+
+	// EndpointCooldown is how long an endpoint that keeps failing is taken
+	// out of rotation.
+	EndpointCooldown time.Duration
+
 	// Model is the AI model to use.
 	Model string
 
+	// CheapModel, if set, analyzes short simple logs instead of Model: logs
+	// of at most CheapMaxBytes with at most CheapMaxErrorLines error lines.
+	CheapModel         string
+	CheapMaxBytes      int
+	CheapMaxErrorLines int
+
 	// Timeout is the maximum time to wait for AI responses.
 	Timeout time.Duration
 
 	// MaxTokens is the maximum tokens for AI response.
 	MaxTokens int
 
+	// Temperature is the sampling temperature (0-2). Low values give the
+	// deterministic output analyses need.
+	Temperature float64
+
 	// MaxRetries is the number of retries on transient failures.
 	MaxRetries int
 
+	// RetryBaseDelay is the backoff before the first retry; it doubles with
+	// each retry up to RetryMaxDelay. A provider's Retry-After replaces it.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// RetryMaxElapsed bounds the total time spent retrying one request.
+	// Zero leaves it to MaxRetries.
+	RetryMaxElapsed time.Duration
+
+	// RetryJitter is the fraction (0-1) of each backoff that is randomized.
+	RetryJitter float64
+
+	// ProxyURL is the proxy for AI requests. Empty uses HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY from the environment.
+	ProxyURL string
+
+	// CACertFile is a PEM bundle of CAs trusted for AI endpoints in
+	// addition to the system roots, e.g. a corporate proxy's private CA.
+	CACertFile string
+
+	// ClientCertFile and ClientKeyFile are a PEM client certificate and key
+	// presented to AI endpoints that require mutual TLS.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// TLSMinVersion is the minimum TLS version for AI requests (1.0-1.3).
+	TLSMinVersion string
+
 	// MockMode enables mock responses for testing without API calls.
 	MockMode bool
+
+	// StructuredOutput asks the provider to enforce the AnalysisResult JSON schema
+	// (OpenAI response_format, Gemini responseSchema).
+	StructuredOutput bool
+
+	// ReferenceDomains are the documentation sites AI references may link
+	// to; other links are dropped. Empty means domain.DefaultReferenceDomains.
+	ReferenceDomains []string
+
+	// MaxConcurrency limits in-flight AI requests. Zero means unlimited.
+	MaxConcurrency int
+
+	// QueueSize is the number of requests allowed to wait for a slot when
+	// MaxConcurrency is reached; further requests fail fast.
+	QueueSize int
+
+	// QueueTimeout is the maximum time a request waits for a slot.
+	QueueTimeout time.Duration
+
+	// TenantWeights gives tenants a relative share of queued AI slots.
+	// Tenants not listed have weight 1.
+	TenantWeights map[string]float64
+
+	// RequestsPerMinute and TokensPerMinute pace outbound provider requests
+	// to stay under provider limits. Zero disables pacing for that limit.
+	RequestsPerMinute int
+	TokensPerMinute   int
+
+	// DailyTokenBudget and MonthlyTokenBudget cap total AI token usage.
+	// Zero means unlimited. When exceeded the service degrades to rules-only.
+	DailyTokenBudget   int
+	MonthlyTokenBudget int
+
+	// PriceInputPerMTok and PriceOutputPerMTok override the built-in price
+	// table for Model (USD per million tokens). Zero uses the built-in price.
+	PriceInputPerMTok  float64
+	PriceOutputPerMTok float64
 }
 
 // ProcessingConfig contains log processing settings.
@@ -77,53 +270,844 @@ type ProcessingConfig struct {
 	// MaxLogSize is the maximum allowed log size in bytes.
 	MaxLogSize int
 
+	// SanitizerConfigPath, if set, is a JSON file with extra masking
+	// patterns and an allowlist of values never to mask.
+	SanitizerConfigPath string
+
+	// MaskStrategy renders masked values: "partial" keeps the first and
+	// last characters of long tokens, "full" replaces them, "hash" with a
+	// keyed hash ([api_key:3f9a1c2b]) and "format" keeps their shape.
+	// MaskHashKey makes hashes stable across replicas.
+	MaskStrategy string
+	MaskHashKey  string
+
+	// ReversibleSanitization masks secrets with numbered placeholders
+	// (SECRET_1, HOST_2) and restores the original values in the result
+	// before it is returned. The AI only ever sees the placeholders.
+	ReversibleSanitization bool
+
+	// RejectPrivateKeys fails analyses of logs containing a private key
+	// instead of sending anything upstream.
+	RejectPrivateKeys bool
+
+	// EntropyDetection also masks random-looking tokens of at least
+	// EntropyMinLength characters that no pattern matched, when their
+	// Shannon entropy exceeds EntropyThreshold bits per character
+	// (EntropyHexThreshold for hexadecimal tokens).
+	EntropyDetection    bool
+	EntropyMinLength    int
+	EntropyThreshold    float64
+	EntropyHexThreshold float64
+
+	// PreprocessLogs strips ANSI escape codes, carriage-return progress
+	// redraws and leading timestamps before rules and the AI see a log.
+	PreprocessLogs bool
+
+	// CompactLogs collapses repeated lines, drops INFO/DEBUG noise when the
+	// log has errors and summarizes long stack traces before AI submission.
+	CompactLogs bool
+
+	// PromptRouting selects a system prompt specialized for the domain
+	// (Kubernetes, Terraform, npm, Docker) indicated by rule matches.
+	PromptRouting bool
+
 	// EnableRules enables rule-based pre-classification.
 	EnableRules bool
 
 	// RuleConfidenceThreshold is the minimum confidence to use rule results.
 	RuleConfidenceThreshold float64
+
+	// EnabledRuleCategories restricts the built-in rules to these categories
+	// (all if empty); DisabledRuleCategories turns categories off, e.g.
+	// "kubernetes" for a shop without clusters.
+	EnabledRuleCategories  []string
+	DisabledRuleCategories []string
+
+	// HybridMerge merges confident rule results with an AI explanation of
+	// the specific log instead of returning the rule result alone.
+	HybridMerge bool
+
+	// RulesOnly answers every analysis from the rules and never calls the
+	// AI, so no API key is needed (offline mode).
+	RulesOnly bool
+
+	// BestEffort answers logs that no rule matches while the AI is disabled
+	// or failing with a generic "unclassified" result instead of an error.
+	BestEffort bool
+
+	// CacheMaxBytes bounds the memory used by the AI result cache.
+	// Zero disables the cache.
+	CacheMaxBytes int
+
+	// CacheSnapshotPath, if set, persists the cache on shutdown and restores
+	// it on startup.
+	CacheSnapshotPath string
+
+	// ShadowSampleRate is the fraction of rule results re-checked by the AI.
+	ShadowSampleRate float64
+
+	// AdaptiveThreshold enables automatic tuning of RuleConfidenceThreshold
+	// from shadow evaluation agreement rates.
+	AdaptiveThreshold bool
+
+	// AdaptiveThresholdMin and AdaptiveThresholdMax bound the tuned threshold.
+	AdaptiveThresholdMin float64
+	AdaptiveThresholdMax float64
+
+	// ClassifierModelPath, if set, loads a bag-of-words classifier that runs
+	// between the rules and the AI.
+	ClassifierModelPath string
+
+	// SeverityPolicyPath, if set, loads a JSON file of severity overrides
+	// applied to every result (see policy.Config).
+	SeverityPolicyPath string
+
+	// ExperimentPath, if set, loads a JSON A/B experiment splitting AI
+	// calls between prompt and model variants (see experiment.Config).
+	ExperimentPath string
+
+	// ClassifierSkipThreshold is the confidence at which a classifier
+	// prediction with a ready-made result skips the AI.
+	ClassifierSkipThreshold float64
+
+	// ClassifierHintThreshold is the confidence at which the prediction is
+	// passed to the AI as a hint.
+	ClassifierHintThreshold float64
+
+	// DefaultLanguage is the output language for analysis text when the
+	// request does not specify one. Empty means English.
+	DefaultLanguage string
+
+	// PostProcessors names the post-processors run on every result, in
+	// order (see service.DefaultPostProcessOrder). Empty runs all of them
+	// in the default order.
+	PostProcessors []string
+}
+
+// WebhookConfig contains per-integration secrets for inbound webhooks.
+// An integration with no secret configured rejects all requests.
+type WebhookConfig struct {
+	// GitHubSecret signs GitHub deliveries (X-Hub-Signature-256).
+	GitHubSecret string
+
+	// GitLabToken is the shared X-Gitlab-Token value.
+	GitLabToken string
+
+	// SentrySecret signs Sentry deliveries (Sentry-Hook-Signature).
+	SentrySecret string
+
+	// ArgoCDToken is the bearer token Argo CD notifications send.
+	ArgoCDToken string
+
+	// ReplayWindow is how long delivery IDs are remembered and the maximum
+	// allowed clock skew for signed timestamps.
+	ReplayWindow time.Duration
+}
+
+// StoreBackend selects the analysis storage implementation.
+type StoreBackend string
+
+const (
+	// StoreBackendMemory keeps analyses in process memory.
+	StoreBackendMemory StoreBackend = "memory"
+)
+
+// StoreConfig contains analysis storage settings.
+type StoreConfig struct {
+	// Backend selects the storage implementation.
+	Backend StoreBackend
+
+	// MaxRecords bounds the in-memory store. Zero means unbounded.
+	MaxRecords int
+}
+
+// NotifyConfig contains outbound notification settings.
+type NotifyConfig struct {
+	// SlackBotToken and SlackChannel enable Slack notifications. A bot token
+	// (not an incoming webhook) is required to edit messages in place.
+	SlackBotToken string
+	SlackChannel  string
+
+	// DedupWindow collapses identical analyses into one message whose
+	// occurrence counter is updated. Zero disables deduplication.
+	DedupWindow time.Duration
+
+	// MinSeverity is the lowest severity that triggers a notification.
+	MinSeverity domain.Severity
+}
+
+// ResponseConfig contains response policy settings.
+type ResponseConfig struct {
+	// RedactProvenanceKeys are client API keys (X-API-Key header) whose
+	// responses must not reveal which AI vendor or model produced them.
+	RedactProvenanceKeys []string
+
+	// ProvenanceMode is how provenance is redacted for those clients.
+	ProvenanceMode domain.ProvenanceMode
+
+	// BlockDestructiveCommands removes suggested actions and commands
+	// classified as destructive (kubectl delete, docker system prune -a)
+	// from results instead of only marking them.
+	BlockDestructiveCommands bool
+}
+
+// IngestConfig contains log shipper ingestion settings.
+type IngestConfig struct {
+	// WindowLines is the number of recent lines buffered per stream and
+	// analyzed when an error burst triggers.
+	WindowLines int
+
+	// Settle is how long a burst collects lines after its first error line.
+	Settle time.Duration
+
+	// MinErrors is the number of error lines within Settle that make a
+	// burst.
+	MinErrors int
+
+	// Cooldown is how long a stream is not analyzed again after a burst.
+	Cooldown time.Duration
+
+	// MaxStreams bounds the streams buffered at once.
+	MaxStreams int
+
+	// ErrorPattern is the regular expression of error lines; empty uses
+	// the built-in pattern.
+	ErrorPattern string
+}
+
+// LokiConfig contains the Grafana Loki settings used to fetch log context
+// for requests with a context query.
+type LokiConfig struct {
+	// URL is the Loki base URL; empty disables context fetching.
+	URL string
+
+	// TenantID is sent as X-Scope-OrgID for multi-tenant Loki.
+	TenantID string
+
+	// Username and Password enable basic auth; Token enables bearer auth.
+	Username string
+	Password string
+	Token    string
+
+	// Timeout bounds a context query.
+	Timeout time.Duration
+
+	// DefaultRange is the time range queried before the end of a context
+	// query without a start.
+	DefaultRange time.Duration
+
+	// MaxRange bounds the time range of a context query.
+	MaxRange time.Duration
+
+	// MaxLines bounds the lines fetched per request.
+	MaxLines int
+}
+
+// KnowledgeConfig contains the knowledge sources searched for organization
+// runbooks matching a result's error type and tags. Each source is enabled
+// by its directory, URL or token; with none configured no runbooks are
+// attached.
+type KnowledgeConfig struct {
+	// MarkdownDir is a directory of markdown runbooks with front matter
+	// (see knowledge.MarkdownSource). MarkdownBaseURL is joined with the
+	// relative path of files that do not declare a url.
+	MarkdownDir     string
+	MarkdownBaseURL string
+
+	// ConfluenceURL is the Confluence base URL, e.g.
+	// "https://example.atlassian.net/wiki". Pages are matched by label.
+	// ConfluenceEmail and ConfluenceToken authenticate (token alone is sent
+	// as a bearer token); ConfluenceSpace restricts the search to a space.
+	ConfluenceURL   string
+	ConfluenceEmail string
+	ConfluenceToken string
+	ConfluenceSpace string
+
+	// NotionToken and NotionDatabaseID select a Notion database of
+	// runbooks matched by its "Error types" and "Tags" properties.
+	NotionToken      string
+	NotionDatabaseID string
+
+	// MaxRunbooks bounds the runbooks attached to a response.
+	MaxRunbooks int
+
+	// Timeout bounds the search of all sources for one analysis.
+	Timeout time.Duration
+
+	// CacheTTL is how long search results are reused per error type and
+	// tags. Zero disables caching.
+	CacheTTL time.Duration
+}
+
+// Enabled reports whether any knowledge source is configured.
+func (k *KnowledgeConfig) Enabled() bool {
+	return k.MarkdownDir != "" || k.ConfluenceURL != "" || k.NotionToken != ""
+}
+
+// TicketsConfig contains issue tracker settings for opening tickets for
+// analyses.
+type TicketsConfig struct {
+	// Tracker selects the issue tracker: "jira", "github", or empty to
+	// disable tickets.
+	Tracker string
+
+	// JiraURL is the Jira site (e.g. "https://example.atlassian.net").
+	// JiraEmail and JiraToken authenticate (token alone is sent as a bearer
+	// token); tickets are opened in JiraProject as JiraIssueType.
+	JiraURL       string
+	JiraEmail     string
+	JiraToken     string
+	JiraProject   string
+	JiraIssueType string
+
+	// GitHubToken and GitHubRepo ("owner/name") select the repository whose
+	// issues are used; GitHubURL is the API base URL.
+	GitHubToken string
+	GitHubRepo  string
+	GitHubURL   string
+
+	// Auto opens tickets for analyses at or above MinSeverity without a
+	// request asking for one.
+	Auto        bool
+	MinSeverity domain.Severity
+
+	// Labels are added to every ticket opened.
+	Labels []string
+}
+
+// EscalationConfig contains on-call paging settings. Each sink is enabled
+// by its key; with none configured nothing pages.
+type EscalationConfig struct {
+	// PagerDutyRoutingKey is an Events API v2 integration key;
+	// PagerDutyURL is the Events API base URL.
+	PagerDutyRoutingKey string
+	PagerDutyURL        string
+
+	// OpsgenieAPIKey is an API integration key; OpsgenieURL is the API
+	// base URL (EU accounts use https://api.eu.opsgenie.com).
+	OpsgenieAPIKey string
+	OpsgenieURL    string
+
+	// MinSeverity, ErrorTypes, Sources and MinConfidence select the
+	// analyses that page (see escalate.Conditions). Empty lists match any.
+	MinSeverity   domain.Severity
+	ErrorTypes    []string
+	Sources       []string
+	MinConfidence float64
+}
+
+// Enabled reports whether any escalation sink is configured.
+func (e *EscalationConfig) Enabled() bool {
+	return e.PagerDutyRoutingKey != "" || e.OpsgenieAPIKey != ""
+}
+
+// CallbackConfig contains settings for delivering asynchronous analyses to
+// request callback URLs.
+type CallbackConfig struct {
+	// Secret signs deliveries; empty disables callback_url requests.
+	Secret string
+
+	// MaxAttempts bounds delivery attempts per callback.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry; it doubles per retry.
+	Backoff time.Duration
+
+	// Timeout bounds a single delivery attempt.
+	Timeout time.Duration
+
+	// AllowedHosts restricts callback URL hosts ("ci.example.com",
+	// "*.example.com"). Empty allows any host.
+	AllowedHosts []string
+
+	// AllowPrivateNetworks allows callbacks to loopback, private and
+	// link-local addresses, which are refused by default.
+	AllowPrivateNetworks bool
+
+	// MaxPending bounds the callback analyses running or being delivered.
+	MaxPending int
+}
+
+// FewShotConfig contains settings for the worked examples shown to the AI.
+type FewShotConfig struct {
+	// Enabled turns on the example store and prompt injection.
+	Enabled bool
+
+	// Path is the JSON file the examples are kept in; empty keeps them in
+	// memory only.
+	Path string
+
+	// Count is the number of similar examples added to a prompt.
+	Count int
+
+	// MinSimilarity is the lowest similarity (0-1) of an example to a log
+	// for it to be added.
+	MinSimilarity float64
+
+	// MaxPerCategory caps the stored examples per taxonomy category.
+	MaxPerCategory int
+
+	// FromFeedback adds analyses to the store once their feedback is
+	// accepted (helpful and never unhelpful).
+	FromFeedback bool
+}
+
+// EmbeddingsConfig contains settings for embedding analyzed logs and
+// retrieving similar past incidents.
+type EmbeddingsConfig struct {
+	// Enabled turns on similar-incident retrieval.
+	Enabled bool
+
+	// Provider, APIKey and BaseURL select the embeddings API; they default
+	// to the AI provider settings.
+	Provider AIProvider
+	APIKey   string
+	BaseURL  string
+
+	// Model is the embedding model.
+	Model string
+
+	// Timeout bounds an embedding request.
+	Timeout time.Duration
+
+	// Count is the number of similar incidents returned per analysis.
+	Count int
+
+	// MinSimilarity is the lowest cosine similarity (0-1) of a past
+	// incident to be returned.
+	MinSimilarity float64
+
+	// LinkBaseURL prefixes incident links (e.g. "https://ai-devops.example.com");
+	// empty returns links relative to the API.
+	LinkBaseURL string
+}
+
+// RedisConfig contains settings for sharing the result cache, outbound
+// pacing buckets and AI endpoint health between replicas through Redis.
+type RedisConfig struct {
+	// URL is redis://[user:password@]host:port[/db] (rediss:// for TLS);
+	// empty keeps all state in memory.
+	URL string
+
+	// KeyPrefix namespaces the keys of this deployment.
+	KeyPrefix string
+
+	// PoolSize bounds the idle connections kept open.
+	PoolSize int
+
+	// Timeout bounds dialing and each command.
+	Timeout time.Duration
+
+	// CacheTTL is how long cached results are kept.
+	CacheTTL time.Duration
+}
+
+// CaptureConfig contains settings for capturing AI prompts and raw
+// responses for debugging. Capture is off unless SampleRate is positive
+// or Rejected is set.
+type CaptureConfig struct {
+	// SampleRate is the fraction (0.0-1.0) of AI analyses captured.
+	SampleRate float64
+
+	// Rejected captures every analysis with an AI response that failed
+	// parsing or validation.
+	Rejected bool
+
+	// MaxEntries is the number of captures kept in memory.
+	MaxEntries int
+
+	// Path, if set, is a file captures are appended to as JSON lines.
+	Path string
+}
+
+// Enabled reports whether any analysis is captured.
+func (c *CaptureConfig) Enabled() bool {
+	return c.SampleRate > 0 || c.Rejected
+}
+
+// PluginConfig lists the plugin executables providing extra rules and AI
+// clients (see pkg/plugin).
+type PluginConfig struct {
+	// Paths are the plugin executables started with the server.
+	Paths []string
+
+	// StartTimeout bounds a plugin's start-up handshake.
+	StartTimeout time.Duration
+
+	// AIClient, if set, names the plugin whose AI client replaces the
+	// configured provider.
+	AIClient string
+}
+
+// Consensus modes.
+const (
+	// ConsensusOff consults a single model.
+	ConsensusOff = "off"
+
+	// ConsensusAlways queries both models in parallel for every analysis.
+	ConsensusAlways = "always"
+
+	// ConsensusHighSeverity asks the second model only when the primary
+	// model rates the failure High.
+	ConsensusHighSeverity = "high_severity"
+)
+
+// ConsensusConfig contains settings for cross-checking AI analyses with a
+// second model.
+type ConsensusConfig struct {
+	// Mode is off, always or high_severity.
+	Mode string
+
+	// Provider, Model, APIKey and BaseURL select the second model. Provider
+	// defaults to the AI provider; APIKey and BaseURL default to the AI
+	// provider settings when the provider is the same.
+	Provider AIProvider
+	Model    string
+	APIKey   string
+	BaseURL  string
+}
+
+// Enabled reports whether a second model is consulted.
+func (c *ConsensusConfig) Enabled() bool {
+	return c.Mode != ConsensusOff
+}
+
+// ConsensusAI returns the AI settings of the second model: the AI settings
+// with the consensus provider, model and credentials.
+func (c *Config) ConsensusAI() AIConfig {
+	ai := c.AI.ForProvider(c.Consensus.Provider)
+	ai.Model = c.Consensus.Model
+	ai.APIKey = c.Consensus.APIKey
+	ai.BaseURL = c.Consensus.BaseURL
+	ai.BaseURLs = nil
+	return ai
+}
+
+// ForProvider returns a copy of c for provider. When the provider changes,
+// the provider-specific endpoint, credentials and Google settings are reset
+// to the new provider's defaults.
+func (c AIConfig) ForProvider(provider AIProvider) AIConfig {
+	if provider == c.Provider {
+		return c
+	}
+	c.Provider = provider
+	c.APIKey = ""
+	c.BaseURL = DefaultBaseURL(provider)
+	c.BaseURLs = nil
+	c.GeminiAuth = GeminiAuthAPIKey
+	c.VertexProject = ""
+	c.CheapModel = ""
+	return c
+}
+
+// ValidateModel checks the settings that may be changed at runtime:
+// provider, model, temperature, max tokens and credentials.
+func (c *AIConfig) ValidateModel() error {
+	if c.Provider != AIProviderOpenAI && c.Provider != AIProviderGemini {
+		return fmt.Errorf("%w: provider must be openai or gemini", domain.ErrInvalidConfig)
+	}
+	if strings.TrimSpace(c.Model) == "" {
+		return fmt.Errorf("%w: model is required", domain.ErrInvalidConfig)
+	}
+	if c.Temperature < 0 || c.Temperature > 2 {
+		return fmt.Errorf("%w: temperature must be between 0 and 2", domain.ErrInvalidConfig)
+	}
+	if c.MaxTokens < 100 {
+		return fmt.Errorf("%w: max tokens must be at least 100", domain.ErrInvalidConfig)
+	}
+	if c.APIKey == "" && !c.UsesADC() {
+		return fmt.Errorf("%w: an API key is required for provider %s", domain.ErrInvalidConfig, c.Provider)
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: base URL must be an http(s) URL", domain.ErrInvalidConfig)
+	}
+	return nil
 }
 
 // Load reads configuration from environment variables.
 func Load() (*Config, error) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	loading = make(map[string]Setting)
+
 	// Determine AI provider
 	provider := AIProvider(getEnvOrDefault("AI_PROVIDER", "openai"))
+	vertexProject := getEnvOrDefault("AI_VERTEX_PROJECT", "")
+	vertexLocation := getEnvOrDefault("AI_VERTEX_LOCATION", "us-central1")
 
 	// Set provider-specific defaults
-	var defaultBaseURL, defaultModel string
+	var defaultBaseURL, defaultModel, defaultEmbeddingModel string
 	switch provider {
 	case AIProviderGemini:
-		defaultBaseURL = "https://generativelanguage.googleapis.com"
+		defaultBaseURL = DefaultBaseURL(provider)
+		if vertexProject != "" {
+			defaultBaseURL = vertexBaseURL(vertexLocation)
+		}
 		defaultModel = "gemini-2.0-flash"
+		defaultEmbeddingModel = "text-embedding-004"
 	default:
 		provider = AIProviderOpenAI
-		defaultBaseURL = "https://api.openai.com/v1"
+		defaultBaseURL = DefaultBaseURL(provider)
 		defaultModel = "gpt-4o-mini"
+		defaultEmbeddingModel = "text-embedding-3-small"
 	}
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:         getEnvOrDefault("PORT", "8080"),
-			ReadTimeout:  getDurationOrDefault("SERVER_READ_TIMEOUT", 30*time.Second),
-			WriteTimeout: getDurationOrDefault("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			Port:              getEnvOrDefault("PORT", "8080"),
+			ReadTimeout:       getDurationOrDefault("SERVER_READ_TIMEOUT", 30*time.Second),
+			WriteTimeout:      getDurationOrDefault("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			MaxRequestTimeout: getDurationOrDefault("MAX_REQUEST_TIMEOUT", 2*time.Minute),
+			MaxBodyBytes:      int64(getIntOrDefault("MAX_REQUEST_BODY_BYTES", 10<<20)), // 10MB
+			MaxLogBytes:       getIntOrDefault("MAX_REQUEST_LOG_BYTES", 5<<20),          // 5MB
+			TenantsPath:       getEnvOrDefault("TENANTS_CONFIG_PATH", ""),
+		},
+		Admin: AdminConfig{
+			Token: getEnvOrDefault("ADMIN_TOKEN", ""),
 		},
 		AI: AIConfig{
-			Provider:   provider,
-			APIKey:     os.Getenv("AI_API_KEY"),
-			BaseURL:    getEnvOrDefault("AI_BASE_URL", defaultBaseURL),
-			Model:      getEnvOrDefault("AI_MODEL", defaultModel),
-			Timeout:    getDurationOrDefault("AI_TIMEOUT", 30*time.Second),
-			MaxTokens:  getIntOrDefault("AI_MAX_TOKENS", 1024),
-			MaxRetries: getIntOrDefault("AI_MAX_RETRIES", 2),
-			MockMode:   getBoolOrDefault("AI_MOCK_MODE", false),
+			Provider:         provider,
+			APIKey:           getEnvOrDefault("AI_API_KEY", ""),
+			BaseURL:          getEnvOrDefault("AI_BASE_URL", defaultBaseURL),
+			BaseURLs:         getListOrDefault("AI_BASE_URLS"),
+			Routing:          getEnvOrDefault("AI_ROUTING", "round_robin"),
+			EndpointCooldown: getDurationOrDefault("AI_ENDPOINT_COOLDOWN", 30*time.Second),
+			Model:            getEnvOrDefault("AI_MODEL", defaultModel),
+			Timeout:          getDurationOrDefault("AI_TIMEOUT", 30*time.Second),
+			MaxTokens:        getIntOrDefault("AI_MAX_TOKENS", 1024),
+			Temperature:      getFloatOrDefault("AI_TEMPERATURE", 0.1),
+			MaxRetries:       getIntOrDefault("AI_MAX_RETRIES", 2),
+			MockMode:         getBoolOrDefault("AI_MOCK_MODE", false),
+
+			CheapModel:         getEnvOrDefault("AI_CHEAP_MODEL", ""),
+			CheapMaxBytes:      getIntOrDefault("AI_CHEAP_MAX_BYTES", 4000),
+			CheapMaxErrorLines: getIntOrDefault("AI_CHEAP_MAX_ERROR_LINES", 2),
+
+			GeminiAuth:            getEnvOrDefault("AI_GEMINI_AUTH", GeminiAuthAPIKey),
+			GoogleCredentialsFile: getEnvOrDefault("AI_GOOGLE_CREDENTIALS_FILE", ""),
+			VertexProject:         vertexProject,
+			VertexLocation:        vertexLocation,
+
+			RetryBaseDelay:  getDurationOrDefault("AI_RETRY_BASE_DELAY", time.Second),
+			RetryMaxDelay:   getDurationOrDefault("AI_RETRY_MAX_DELAY", 20*time.Second),
+			RetryMaxElapsed: getDurationOrDefault("AI_RETRY_MAX_ELAPSED", 0),
+			RetryJitter:     getFloatOrDefault("AI_RETRY_JITTER", 0.5),
+
+			ProxyURL:       getEnvOrDefault("AI_PROXY_URL", ""),
+			CACertFile:     getEnvOrDefault("AI_CA_CERT_FILE", ""),
+			ClientCertFile: getEnvOrDefault("AI_CLIENT_CERT_FILE", ""),
+			ClientKeyFile:  getEnvOrDefault("AI_CLIENT_KEY_FILE", ""),
+			TLSMinVersion:  getEnvOrDefault("AI_TLS_MIN_VERSION", "1.2"),
+
+			StructuredOutput: getBoolOrDefault("AI_STRUCTURED_OUTPUT", true),
+			ReferenceDomains: getListOrDefault("AI_REFERENCE_DOMAINS"),
+
+			MaxConcurrency: getIntOrDefault("AI_MAX_CONCURRENCY", 0),
+			QueueSize:      getIntOrDefault("AI_QUEUE_SIZE", 100),
+			QueueTimeout:   getDurationOrDefault("AI_QUEUE_TIMEOUT", 10*time.Second),
+			TenantWeights:  getWeightsOrDefault("AI_TENANT_WEIGHTS"),
+
+			RequestsPerMinute: getIntOrDefault("AI_RPM_LIMIT", 0),
+			TokensPerMinute:   getIntOrDefault("AI_TPM_LIMIT", 0),
+
+			DailyTokenBudget:   getIntOrDefault("AI_DAILY_TOKEN_BUDGET", 0),
+			MonthlyTokenBudget: getIntOrDefault("AI_MONTHLY_TOKEN_BUDGET", 0),
+			PriceInputPerMTok:  getFloatOrDefault("AI_PRICE_INPUT_PER_MTOK", 0),
+			PriceOutputPerMTok: getFloatOrDefault("AI_PRICE_OUTPUT_PER_MTOK", 0),
 		},
 		Processing: ProcessingConfig{
 			MaxLogSize:              getIntOrDefault("MAX_LOG_SIZE", 50000), // ~50KB
+			SanitizerConfigPath:     getEnvOrDefault("SANITIZER_CONFIG_PATH", ""),
+			MaskStrategy:            getEnvOrDefault("SANITIZER_MASK_STRATEGY", "partial"),
+			MaskHashKey:             getEnvOrDefault("SANITIZER_HASH_KEY", ""),
+			ReversibleSanitization:  getBoolOrDefault("REVERSIBLE_SANITIZATION", false),
+			RejectPrivateKeys:       getBoolOrDefault("REJECT_PRIVATE_KEYS", false),
+			EntropyDetection:        getBoolOrDefault("SANITIZER_ENTROPY", false),
+			EntropyMinLength:        getIntOrDefault("SANITIZER_ENTROPY_MIN_LENGTH", 20),
+			EntropyThreshold:        getFloatOrDefault("SANITIZER_ENTROPY_THRESHOLD", 4.0),
+			EntropyHexThreshold:     getFloatOrDefault("SANITIZER_ENTROPY_HEX_THRESHOLD", 3.0),
+			PreprocessLogs:          getBoolOrDefault("PREPROCESS_LOGS", true),
+			CompactLogs:             getBoolOrDefault("COMPACT_LOGS", true),
+			PromptRouting:           getBoolOrDefault("PROMPT_ROUTING", true),
 			EnableRules:             getBoolOrDefault("ENABLE_RULES", true),
 			RuleConfidenceThreshold: getFloatOrDefault("RULE_CONFIDENCE_THRESHOLD", 0.8),
+			EnabledRuleCategories:   getListOrDefault("RULE_CATEGORIES_ENABLED"),
+			DisabledRuleCategories:  getListOrDefault("RULE_CATEGORIES_DISABLED"),
+			HybridMerge:             getBoolOrDefault("HYBRID_MERGE", false),
+			RulesOnly:               getBoolOrDefault("RULES_ONLY", false),
+			BestEffort:              getBoolOrDefault("BEST_EFFORT", false),
+			CacheMaxBytes:           getIntOrDefault("CACHE_MAX_BYTES", 32<<20), // 32MB
+			CacheSnapshotPath:       getEnvOrDefault("CACHE_SNAPSHOT_PATH", ""),
+			ShadowSampleRate:        getFloatOrDefault("SHADOW_EVAL_SAMPLE_RATE", 0),
+			AdaptiveThreshold:       getBoolOrDefault("ADAPTIVE_THRESHOLD", false),
+			AdaptiveThresholdMin:    getFloatOrDefault("ADAPTIVE_THRESHOLD_MIN", 0.6),
+			AdaptiveThresholdMax:    getFloatOrDefault("ADAPTIVE_THRESHOLD_MAX", 0.98),
+			ClassifierModelPath:     getEnvOrDefault("CLASSIFIER_MODEL_PATH", ""),
+			SeverityPolicyPath:      getEnvOrDefault("SEVERITY_POLICY_PATH", ""),
+			ExperimentPath:          getEnvOrDefault("EXPERIMENT_PATH", ""),
+			ClassifierSkipThreshold: getFloatOrDefault("CLASSIFIER_SKIP_THRESHOLD", 0.95),
+			ClassifierHintThreshold: getFloatOrDefault("CLASSIFIER_HINT_THRESHOLD", 0.7),
+			DefaultLanguage:         getEnvOrDefault("ANALYSIS_LANGUAGE", ""),
+			PostProcessors:          getListOrDefault("POST_PROCESSORS"),
+		},
+		Webhooks: WebhookConfig{
+			GitHubSecret: getEnvOrDefault("WEBHOOK_GITHUB_SECRET", ""),
+			GitLabToken:  getEnvOrDefault("WEBHOOK_GITLAB_TOKEN", ""),
+			SentrySecret: getEnvOrDefault("WEBHOOK_SENTRY_SECRET", ""),
+			ArgoCDToken:  getEnvOrDefault("WEBHOOK_ARGOCD_TOKEN", ""),
+			ReplayWindow: getDurationOrDefault("WEBHOOK_REPLAY_WINDOW", 5*time.Minute),
+		},
+		Store: StoreConfig{
+			Backend:    StoreBackend(getEnvOrDefault("STORE_BACKEND", string(StoreBackendMemory))),
+			MaxRecords: getIntOrDefault("STORE_MAX_RECORDS", 10000),
+		},
+		Notify: NotifyConfig{
+			SlackBotToken: getEnvOrDefault("SLACK_BOT_TOKEN", ""),
+			SlackChannel:  getEnvOrDefault("SLACK_CHANNEL", ""),
+			DedupWindow:   getDurationOrDefault("NOTIFY_DEDUP_WINDOW", 10*time.Minute),
+			MinSeverity:   domain.Severity(getEnvOrDefault("NOTIFY_MIN_SEVERITY", string(domain.SeverityHigh))),
+		},
+		Response: ResponseConfig{
+			RedactProvenanceKeys: getListOrDefault("REDACT_PROVENANCE_API_KEYS"),
+			ProvenanceMode:       domain.ProvenanceMode(getEnvOrDefault("REDACT_PROVENANCE_MODE", string(domain.ProvenanceNormalize))),
+
+			BlockDestructiveCommands: getBoolOrDefault("BLOCK_DESTRUCTIVE_COMMANDS", false),
+		},
+		Ingest: IngestConfig{
+			WindowLines:  getIntOrDefault("INGEST_WINDOW_LINES", 200),
+			Settle:       getDurationOrDefault("INGEST_SETTLE", 5*time.Second),
+			MinErrors:    getIntOrDefault("INGEST_MIN_ERRORS", 1),
+			Cooldown:     getDurationOrDefault("INGEST_COOLDOWN", 5*time.Minute),
+			MaxStreams:   getIntOrDefault("INGEST_MAX_STREAMS", 1000),
+			ErrorPattern: getEnvOrDefault("INGEST_ERROR_PATTERN", ""),
+		},
+		Loki: LokiConfig{
+			URL:          getEnvOrDefault("LOKI_URL", ""),
+			TenantID:     getEnvOrDefault("LOKI_TENANT_ID", ""),
+			Username:     getEnvOrDefault("LOKI_USERNAME", ""),
+			Password:     getEnvOrDefault("LOKI_PASSWORD", ""),
+			Token:        getEnvOrDefault("LOKI_TOKEN", ""),
+			Timeout:      getDurationOrDefault("LOKI_TIMEOUT", 10*time.Second),
+			DefaultRange: getDurationOrDefault("LOKI_DEFAULT_RANGE", 15*time.Minute),
+			MaxRange:     getDurationOrDefault("LOKI_MAX_RANGE", 6*time.Hour),
+			MaxLines:     getIntOrDefault("LOKI_MAX_LINES", 500),
 		},
+		Knowledge: KnowledgeConfig{
+			MarkdownDir:      getEnvOrDefault("RUNBOOKS_MARKDOWN_DIR", ""),
+			MarkdownBaseURL:  getEnvOrDefault("RUNBOOKS_MARKDOWN_BASE_URL", ""),
+			ConfluenceURL:    getEnvOrDefault("CONFLUENCE_URL", ""),
+			ConfluenceEmail:  getEnvOrDefault("CONFLUENCE_EMAIL", ""),
+			ConfluenceToken:  getEnvOrDefault("CONFLUENCE_API_TOKEN", ""),
+			ConfluenceSpace:  getEnvOrDefault("CONFLUENCE_SPACE", ""),
+			NotionToken:      getEnvOrDefault("NOTION_API_TOKEN", ""),
+			NotionDatabaseID: getEnvOrDefault("NOTION_DATABASE_ID", ""),
+			MaxRunbooks:      getIntOrDefault("RUNBOOKS_MAX", 3),
+			Timeout:          getDurationOrDefault("RUNBOOKS_TIMEOUT", 3*time.Second),
+			CacheTTL:         getDurationOrDefault("RUNBOOKS_CACHE_TTL", 10*time.Minute),
+		},
+		Tickets: TicketsConfig{
+			Tracker:       getEnvOrDefault("TICKET_TRACKER", ""),
+			JiraURL:       getEnvOrDefault("JIRA_URL", ""),
+			JiraEmail:     getEnvOrDefault("JIRA_EMAIL", ""),
+			JiraToken:     getEnvOrDefault("JIRA_API_TOKEN", ""),
+			JiraProject:   getEnvOrDefault("JIRA_PROJECT", ""),
+			JiraIssueType: getEnvOrDefault("JIRA_ISSUE_TYPE", "Bug"),
+			GitHubToken:   getEnvOrDefault("GITHUB_ISSUES_TOKEN", ""),
+			GitHubRepo:    getEnvOrDefault("GITHUB_ISSUES_REPO", ""),
+			GitHubURL:     getEnvOrDefault("GITHUB_API_URL", "https://api.github.com"),
+			Auto:          getBoolOrDefault("TICKETS_AUTO", true),
+			MinSeverity:   domain.Severity(getEnvOrDefault("TICKETS_MIN_SEVERITY", string(domain.SeverityHigh))),
+			Labels:        getListOrDefault("TICKETS_LABELS"),
+		},
+		Escalation: EscalationConfig{
+			PagerDutyRoutingKey: getEnvOrDefault("PAGERDUTY_ROUTING_KEY", ""),
+			PagerDutyURL:        getEnvOrDefault("PAGERDUTY_URL", "https://events.pagerduty.com"),
+			OpsgenieAPIKey:      getEnvOrDefault("OPSGENIE_API_KEY", ""),
+			OpsgenieURL:         getEnvOrDefault("OPSGENIE_URL", "https://api.opsgenie.com"),
+			MinSeverity:         domain.Severity(getEnvOrDefault("ESCALATE_MIN_SEVERITY", string(domain.SeverityHigh))),
+			ErrorTypes:          getListOrDefault("ESCALATE_ERROR_TYPES"),
+			Sources:             getListOrDefault("ESCALATE_SOURCES"),
+			MinConfidence:       getFloatOrDefault("ESCALATE_MIN_CONFIDENCE", 0),
+		},
+		Callback: CallbackConfig{
+			Secret:               getEnvOrDefault("CALLBACK_SECRET", ""),
+			MaxAttempts:          getIntOrDefault("CALLBACK_MAX_ATTEMPTS", 5),
+			Backoff:              getDurationOrDefault("CALLBACK_BACKOFF", 2*time.Second),
+			Timeout:              getDurationOrDefault("CALLBACK_TIMEOUT", 10*time.Second),
+			AllowedHosts:         getListOrDefault("CALLBACK_ALLOWED_HOSTS"),
+			AllowPrivateNetworks: getBoolOrDefault("CALLBACK_ALLOW_PRIVATE_NETWORKS", false),
+			MaxPending:           getIntOrDefault("CALLBACK_MAX_PENDING", 100),
+		},
+		Redis: RedisConfig{
+			URL:       getEnvOrDefault("REDIS_URL", ""),
+			KeyPrefix: getEnvOrDefault("REDIS_KEY_PREFIX", "ai-devops:"),
+			PoolSize:  getIntOrDefault("REDIS_POOL_SIZE", 10),
+			Timeout:   getDurationOrDefault("REDIS_TIMEOUT", 2*time.Second),
+			CacheTTL:  getDurationOrDefault("REDIS_CACHE_TTL", 24*time.Hour),
+		},
+		FewShot: FewShotConfig{
+			Enabled:        getBoolOrDefault("FEWSHOT_ENABLED", false),
+			Path:           getEnvOrDefault("FEWSHOT_PATH", ""),
+			Count:          getIntOrDefault("FEWSHOT_COUNT", 3),
+			MinSimilarity:  getFloatOrDefault("FEWSHOT_MIN_SIMILARITY", 0.25),
+			MaxPerCategory: getIntOrDefault("FEWSHOT_MAX_PER_CATEGORY", 50),
+			FromFeedback:   getBoolOrDefault("FEWSHOT_FROM_FEEDBACK", true),
+		},
+		Capture: CaptureConfig{
+			SampleRate: getFloatOrDefault("DEBUG_CAPTURE_SAMPLE_RATE", 0),
+			Rejected:   getBoolOrDefault("DEBUG_CAPTURE_REJECTED", false),
+			MaxEntries: getIntOrDefault("DEBUG_CAPTURE_MAX_ENTRIES", 200),
+			Path:       getEnvOrDefault("DEBUG_CAPTURE_PATH", ""),
+		},
+		Plugins: PluginConfig{
+			Paths:        getListOrDefault("PLUGINS"),
+			StartTimeout: getDurationOrDefault("PLUGIN_START_TIMEOUT", 10*time.Second),
+			AIClient:     getEnvOrDefault("AI_PLUGIN", ""),
+		},
+		Consensus: ConsensusConfig{
+			Mode:     getEnvOrDefault("CONSENSUS_MODE", ConsensusOff),
+			Provider: AIProvider(getEnvOrDefault("CONSENSUS_PROVIDER", "")),
+			Model:    getEnvOrDefault("CONSENSUS_MODEL", ""),
+			APIKey:   getEnvOrDefault("CONSENSUS_API_KEY", ""),
+			BaseURL:  getEnvOrDefault("CONSENSUS_BASE_URL", ""),
+		},
+		Embeddings: EmbeddingsConfig{
+			Enabled:       getBoolOrDefault("EMBEDDINGS_ENABLED", false),
+			Provider:      provider,
+			APIKey:        getEnvOrDefault("EMBEDDINGS_API_KEY", ""),
+			BaseURL:       getEnvOrDefault("EMBEDDINGS_BASE_URL", ""),
+			Model:         getEnvOrDefault("EMBEDDINGS_MODEL", defaultEmbeddingModel),
+			Timeout:       getDurationOrDefault("EMBEDDINGS_TIMEOUT", 10*time.Second),
+			Count:         getIntOrDefault("SIMILAR_INCIDENTS_COUNT", 3),
+			MinSimilarity: getFloatOrDefault("SIMILAR_INCIDENTS_MIN_SIMILARITY", 0.85),
+			LinkBaseURL:   strings.TrimSuffix(getEnvOrDefault("SIMILAR_INCIDENTS_LINK_BASE", ""), "/"),
+		},
+	}
+
+	// The second opinion defaults to the AI provider's
+	if cfg.Consensus.Provider == "" || cfg.Consensus.Provider == cfg.AI.Provider {
+		cfg.Consensus.Provider = cfg.AI.Provider
+		if cfg.Consensus.APIKey == "" {
+			cfg.Consensus.APIKey = cfg.AI.APIKey
+		}
+		if cfg.Consensus.BaseURL == "" {
+			cfg.Consensus.BaseURL = cfg.AI.BaseURL
+		}
+	} else if cfg.Consensus.BaseURL == "" {
+		cfg.Consensus.BaseURL = DefaultBaseURL(cfg.Consensus.Provider)
+	}
+
+	// The embeddings API defaults to the AI provider's
+	if cfg.Embeddings.APIKey == "" {
+		cfg.Embeddings.APIKey = cfg.AI.APIKey
+	}
+	if cfg.Embeddings.BaseURL == "" {
+		cfg.Embeddings.BaseURL = cfg.AI.BaseURL
 	}
 
+	cfg.settings = sortedSettings(loading)
+	loading = nil
+
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
@@ -131,13 +1115,85 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// UsesADC reports whether requests authenticate with Google Application
+// Default Credentials instead of APIKey.
+func (c *AIConfig) UsesADC() bool {
+	return c.Provider == AIProviderGemini && (c.GeminiAuth == GeminiAuthADC || c.UsesVertex())
+}
+
+// UsesVertex reports whether Gemini requests go to Vertex AI.
+func (c *AIConfig) UsesVertex() bool {
+	return c.Provider == AIProviderGemini && c.VertexProject != ""
+}
+
+// DefaultBaseURL returns the public API endpoint of a provider.
+func DefaultBaseURL(provider AIProvider) string {
+	if provider == AIProviderGemini {
+		return "https://generativelanguage.googleapis.com"
+	}
+	return "https://api.openai.com/v1"
+}
+
+// vertexBaseURL returns the Vertex AI endpoint of a region.
+func vertexBaseURL(location string) string {
+	if location == "global" {
+		return "https://aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com", location)
+}
+
 // Validate checks if the configuration is valid.
 func (c *Config) Validate() error {
-	// AI API key is required unless in mock mode
-	if !c.AI.MockMode && c.AI.APIKey == "" {
+	if c.AI.GeminiAuth != GeminiAuthAPIKey && c.AI.GeminiAuth != GeminiAuthADC {
+		return fmt.Errorf("%w: AI_GEMINI_AUTH must be api_key or adc", domain.ErrInvalidConfig)
+	}
+
+	if c.AI.VertexProject != "" && (c.AI.Provider != AIProviderGemini || c.AI.VertexLocation == "") {
+		return fmt.Errorf("%w: AI_VERTEX_PROJECT requires AI_PROVIDER=gemini and AI_VERTEX_LOCATION", domain.ErrInvalidConfig)
+	}
+
+	// AI API key is required unless in mock or rules-only mode, using ADC
+	// or answered by a plugin
+	if !c.AI.MockMode && !c.Processing.RulesOnly && c.AI.APIKey == "" && !c.AI.UsesADC() && c.Plugins.AIClient == "" {
 		return fmt.Errorf("%w: AI_API_KEY is required when not in mock mode", domain.ErrInvalidConfig)
 	}
 
+	if c.Processing.RulesOnly && !c.Processing.EnableRules {
+		return fmt.Errorf("%w: RULES_ONLY requires ENABLE_RULES", domain.ErrInvalidConfig)
+	}
+
+	if c.Server.MaxRequestTimeout <= 0 {
+		return fmt.Errorf("%w: MAX_REQUEST_TIMEOUT must be positive", domain.ErrInvalidConfig)
+	}
+
+	if c.AI.MaxRetries < 0 || c.AI.RetryBaseDelay <= 0 || c.AI.RetryMaxDelay < c.AI.RetryBaseDelay || c.AI.RetryMaxElapsed < 0 {
+		return fmt.Errorf("%w: AI_MAX_RETRIES must not be negative and AI_RETRY_BASE_DELAY must be positive and at most AI_RETRY_MAX_DELAY", domain.ErrInvalidConfig)
+	}
+
+	if c.AI.RetryJitter < 0 || c.AI.RetryJitter > 1 {
+		return fmt.Errorf("%w: AI_RETRY_JITTER must be between 0 and 1", domain.ErrInvalidConfig)
+	}
+
+	if _, err := c.AI.Proxy(); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidConfig, err)
+	}
+
+	if _, err := c.AI.TLSConfig(); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidConfig, err)
+	}
+
+	if c.Capture.SampleRate < 0 || c.Capture.SampleRate > 1 || c.Capture.MaxEntries < 1 {
+		return fmt.Errorf("%w: DEBUG_CAPTURE_SAMPLE_RATE must be between 0 and 1 and DEBUG_CAPTURE_MAX_ENTRIES positive", domain.ErrInvalidConfig)
+	}
+
+	if c.Plugins.StartTimeout <= 0 {
+		return fmt.Errorf("%w: PLUGIN_START_TIMEOUT must be positive", domain.ErrInvalidConfig)
+	}
+
+	if c.Plugins.AIClient != "" && (len(c.Plugins.Paths) == 0 || c.AI.MockMode) {
+		return fmt.Errorf("%w: AI_PLUGIN requires PLUGINS and is not used in mock mode", domain.ErrInvalidConfig)
+	}
+
 	if c.AI.Timeout < time.Second {
 		return fmt.Errorf("%w: AI_TIMEOUT must be at least 1 second", domain.ErrInvalidConfig)
 	}
@@ -146,14 +1202,221 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("%w: AI_MAX_TOKENS must be at least 100", domain.ErrInvalidConfig)
 	}
 
+	if c.AI.Temperature < 0 || c.AI.Temperature > 2 {
+		return fmt.Errorf("%w: AI_TEMPERATURE must be between 0 and 2", domain.ErrInvalidConfig)
+	}
+
+	if c.AI.CheapModel != "" && (c.AI.CheapMaxBytes < 1 || c.AI.CheapMaxErrorLines < 0) {
+		return fmt.Errorf("%w: AI_CHEAP_MAX_BYTES must be positive and AI_CHEAP_MAX_ERROR_LINES not negative", domain.ErrInvalidConfig)
+	}
+
+	if c.AI.MaxConcurrency < 0 || c.AI.QueueSize < 0 {
+		return fmt.Errorf("%w: AI_MAX_CONCURRENCY and AI_QUEUE_SIZE must not be negative", domain.ErrInvalidConfig)
+	}
+
+	for tenant, weight := range c.AI.TenantWeights {
+		if weight <= 0 {
+			return fmt.Errorf("%w: AI_TENANT_WEIGHTS weight for %q must be positive", domain.ErrInvalidConfig, tenant)
+		}
+	}
+
+	if c.AI.Routing != "round_robin" && c.AI.Routing != "latency" {
+		return fmt.Errorf("%w: AI_ROUTING must be round_robin or latency", domain.ErrInvalidConfig)
+	}
+
+	if len(c.AI.BaseURLs) > 1 && c.AI.EndpointCooldown <= 0 {
+		return fmt.Errorf("%w: AI_ENDPOINT_COOLDOWN must be positive", domain.ErrInvalidConfig)
+	}
+
+	if c.AI.RequestsPerMinute < 0 || c.AI.TokensPerMinute < 0 {
+		return fmt.Errorf("%w: AI_RPM_LIMIT and AI_TPM_LIMIT must not be negative", domain.ErrInvalidConfig)
+	}
+
+	if c.AI.DailyTokenBudget < 0 || c.AI.MonthlyTokenBudget < 0 {
+		return fmt.Errorf("%w: AI token budgets must not be negative", domain.ErrInvalidConfig)
+	}
+
+	if c.Store.Backend != StoreBackendMemory {
+		return fmt.Errorf("%w: unsupported STORE_BACKEND %q", domain.ErrInvalidConfig, c.Store.Backend)
+	}
+
+	if !c.Notify.MinSeverity.IsValid() {
+		return fmt.Errorf("%w: NOTIFY_MIN_SEVERITY must be Low, Medium or High", domain.ErrInvalidConfig)
+	}
+
+	if c.Notify.SlackBotToken != "" && c.Notify.SlackChannel == "" {
+		return fmt.Errorf("%w: SLACK_CHANNEL is required when SLACK_BOT_TOKEN is set", domain.ErrInvalidConfig)
+	}
+
+	if !c.Response.ProvenanceMode.IsValid() {
+		return fmt.Errorf("%w: REDACT_PROVENANCE_MODE must be normalize or hide", domain.ErrInvalidConfig)
+	}
+
+	if c.Ingest.WindowLines < 1 || c.Ingest.MinErrors < 1 || c.Ingest.MaxStreams < 1 {
+		return fmt.Errorf("%w: INGEST_WINDOW_LINES, INGEST_MIN_ERRORS and INGEST_MAX_STREAMS must be positive", domain.ErrInvalidConfig)
+	}
+
+	if c.Ingest.Settle < 0 || c.Ingest.Cooldown < 0 {
+		return fmt.Errorf("%w: INGEST_SETTLE and INGEST_COOLDOWN must not be negative", domain.ErrInvalidConfig)
+	}
+
+	if c.Loki.URL != "" {
+		if u, err := url.Parse(c.Loki.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: LOKI_URL must be an http(s) URL", domain.ErrInvalidConfig)
+		}
+		if c.Loki.Timeout <= 0 || c.Loki.DefaultRange <= 0 || c.Loki.DefaultRange > c.Loki.MaxRange {
+			return fmt.Errorf("%w: LOKI_TIMEOUT and LOKI_DEFAULT_RANGE must be positive and LOKI_DEFAULT_RANGE at most LOKI_MAX_RANGE", domain.ErrInvalidConfig)
+		}
+		if c.Loki.MaxLines < 1 {
+			return fmt.Errorf("%w: LOKI_MAX_LINES must be positive", domain.ErrInvalidConfig)
+		}
+	}
+
+	if c.Knowledge.ConfluenceURL != "" {
+		if u, err := url.Parse(c.Knowledge.ConfluenceURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: CONFLUENCE_URL must be an http(s) URL", domain.ErrInvalidConfig)
+		}
+		if c.Knowledge.ConfluenceToken == "" {
+			return fmt.Errorf("%w: CONFLUENCE_API_TOKEN is required when CONFLUENCE_URL is set", domain.ErrInvalidConfig)
+		}
+	}
+	if c.Knowledge.NotionToken != "" && c.Knowledge.NotionDatabaseID == "" {
+		return fmt.Errorf("%w: NOTION_DATABASE_ID is required when NOTION_API_TOKEN is set", domain.ErrInvalidConfig)
+	}
+	if c.Knowledge.Enabled() && (c.Knowledge.MaxRunbooks < 1 || c.Knowledge.Timeout <= 0 || c.Knowledge.CacheTTL < 0) {
+		return fmt.Errorf("%w: RUNBOOKS_MAX and RUNBOOKS_TIMEOUT must be positive and RUNBOOKS_CACHE_TTL not negative", domain.ErrInvalidConfig)
+	}
+
+	switch c.Tickets.Tracker {
+	case "":
+	case "jira":
+		if u, err := url.Parse(c.Tickets.JiraURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: JIRA_URL must be an http(s) URL", domain.ErrInvalidConfig)
+		}
+		if c.Tickets.JiraToken == "" || c.Tickets.JiraProject == "" {
+			return fmt.Errorf("%w: JIRA_API_TOKEN and JIRA_PROJECT are required when TICKET_TRACKER is jira", domain.ErrInvalidConfig)
+		}
+	case "github":
+		if c.Tickets.GitHubToken == "" || !strings.Contains(c.Tickets.GitHubRepo, "/") {
+			return fmt.Errorf("%w: GITHUB_ISSUES_TOKEN and GITHUB_ISSUES_REPO (owner/name) are required when TICKET_TRACKER is github", domain.ErrInvalidConfig)
+		}
+	default:
+		return fmt.Errorf("%w: TICKET_TRACKER must be jira or github", domain.ErrInvalidConfig)
+	}
+	if c.Tickets.Tracker != "" && !c.Tickets.MinSeverity.IsValid() {
+		return fmt.Errorf("%w: TICKETS_MIN_SEVERITY must be Low, Medium or High", domain.ErrInvalidConfig)
+	}
+
+	if c.Escalation.Enabled() {
+		if !c.Escalation.MinSeverity.IsValid() {
+			return fmt.Errorf("%w: ESCALATE_MIN_SEVERITY must be Low, Medium or High", domain.ErrInvalidConfig)
+		}
+		if c.Escalation.MinConfidence < 0 || c.Escalation.MinConfidence > 1 {
+			return fmt.Errorf("%w: ESCALATE_MIN_CONFIDENCE must be between 0 and 1", domain.ErrInvalidConfig)
+		}
+	}
+
+	if c.Callback.Secret != "" && (c.Callback.MaxAttempts < 1 || c.Callback.Backoff <= 0 || c.Callback.Timeout <= 0 || c.Callback.MaxPending < 1) {
+		return fmt.Errorf("%w: CALLBACK_MAX_ATTEMPTS, CALLBACK_BACKOFF, CALLBACK_TIMEOUT and CALLBACK_MAX_PENDING must be positive", domain.ErrInvalidConfig)
+	}
+
+	if c.Redis.URL != "" {
+		if u, err := url.Parse(c.Redis.URL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+			return fmt.Errorf("%w: REDIS_URL must be a redis:// or rediss:// URL", domain.ErrInvalidConfig)
+		}
+		if c.Redis.PoolSize < 1 || c.Redis.Timeout <= 0 || c.Redis.CacheTTL <= 0 {
+			return fmt.Errorf("%w: REDIS_POOL_SIZE, REDIS_TIMEOUT and REDIS_CACHE_TTL must be positive", domain.ErrInvalidConfig)
+		}
+	}
+
+	switch c.Consensus.Mode {
+	case ConsensusOff:
+	case ConsensusAlways, ConsensusHighSeverity:
+		if c.Consensus.Provider != AIProviderOpenAI && c.Consensus.Provider != AIProviderGemini {
+			return fmt.Errorf("%w: CONSENSUS_PROVIDER must be openai or gemini", domain.ErrInvalidConfig)
+		}
+		if c.Consensus.Model == "" {
+			return fmt.Errorf("%w: CONSENSUS_MODEL is required when CONSENSUS_MODE is set", domain.ErrInvalidConfig)
+		}
+		if c.Consensus.Provider != c.AI.Provider && c.Consensus.APIKey == "" && !c.AI.MockMode {
+			return fmt.Errorf("%w: CONSENSUS_API_KEY is required for a different CONSENSUS_PROVIDER", domain.ErrInvalidConfig)
+		}
+	default:
+		return fmt.Errorf("%w: CONSENSUS_MODE must be off, always or high_severity", domain.ErrInvalidConfig)
+	}
+
+	if c.FewShot.Enabled {
+		if c.FewShot.Count < 0 || c.FewShot.MaxPerCategory < 0 {
+			return fmt.Errorf("%w: FEWSHOT_COUNT and FEWSHOT_MAX_PER_CATEGORY must not be negative", domain.ErrInvalidConfig)
+		}
+		if c.FewShot.MinSimilarity < 0 || c.FewShot.MinSimilarity > 1 {
+			return fmt.Errorf("%w: FEWSHOT_MIN_SIMILARITY must be between 0 and 1", domain.ErrInvalidConfig)
+		}
+	}
+
+	if c.Embeddings.Enabled {
+		if c.Embeddings.Count < 1 || c.Embeddings.Timeout <= 0 {
+			return fmt.Errorf("%w: SIMILAR_INCIDENTS_COUNT and EMBEDDINGS_TIMEOUT must be positive", domain.ErrInvalidConfig)
+		}
+		if c.Embeddings.MinSimilarity < 0 || c.Embeddings.MinSimilarity > 1 {
+			return fmt.Errorf("%w: SIMILAR_INCIDENTS_MIN_SIMILARITY must be between 0 and 1", domain.ErrInvalidConfig)
+		}
+	}
+
 	if c.Processing.MaxLogSize < 1000 {
 		return fmt.Errorf("%w: MAX_LOG_SIZE must be at least 1000 bytes", domain.ErrInvalidConfig)
 	}
 
+	switch c.Processing.MaskStrategy {
+	case "partial", "full", "hash", "format":
+	default:
+		return fmt.Errorf("%w: SANITIZER_MASK_STRATEGY must be partial, full, hash or format", domain.ErrInvalidConfig)
+	}
+
+	if c.Processing.EntropyDetection {
+		if c.Processing.EntropyMinLength < 8 {
+			return fmt.Errorf("%w: SANITIZER_ENTROPY_MIN_LENGTH must be at least 8", domain.ErrInvalidConfig)
+		}
+		if c.Processing.EntropyThreshold <= 0 || c.Processing.EntropyThreshold > 6 {
+			return fmt.Errorf("%w: SANITIZER_ENTROPY_THRESHOLD must be between 0 and 6", domain.ErrInvalidConfig)
+		}
+		if c.Processing.EntropyHexThreshold <= 0 || c.Processing.EntropyHexThreshold > 4 {
+			return fmt.Errorf("%w: SANITIZER_ENTROPY_HEX_THRESHOLD must be between 0 and 4", domain.ErrInvalidConfig)
+		}
+	}
+
+	if c.Server.MaxLogBytes < c.Processing.MaxLogSize || int64(c.Server.MaxLogBytes) > c.Server.MaxBodyBytes {
+		return fmt.Errorf("%w: MAX_REQUEST_LOG_BYTES must be between MAX_LOG_SIZE and MAX_REQUEST_BODY_BYTES", domain.ErrInvalidConfig)
+	}
+
 	if c.Processing.RuleConfidenceThreshold < 0 || c.Processing.RuleConfidenceThreshold > 1 {
 		return fmt.Errorf("%w: RULE_CONFIDENCE_THRESHOLD must be between 0 and 1", domain.ErrInvalidConfig)
 	}
 
+	if c.Processing.CacheMaxBytes < 0 {
+		return fmt.Errorf("%w: CACHE_MAX_BYTES must not be negative", domain.ErrInvalidConfig)
+	}
+
+	if c.Processing.ShadowSampleRate < 0 || c.Processing.ShadowSampleRate > 1 {
+		return fmt.Errorf("%w: SHADOW_EVAL_SAMPLE_RATE must be between 0 and 1", domain.ErrInvalidConfig)
+	}
+
+	if c.Processing.ClassifierModelPath != "" &&
+		(c.Processing.ClassifierHintThreshold < 0 || c.Processing.ClassifierSkipThreshold > 1 ||
+			c.Processing.ClassifierHintThreshold > c.Processing.ClassifierSkipThreshold) {
+		return fmt.Errorf("%w: CLASSIFIER_HINT_THRESHOLD/SKIP_THRESHOLD must satisfy 0 <= hint <= skip <= 1", domain.ErrInvalidConfig)
+	}
+
+	if c.Processing.AdaptiveThreshold {
+		if c.Processing.ShadowSampleRate == 0 {
+			return fmt.Errorf("%w: ADAPTIVE_THRESHOLD requires SHADOW_EVAL_SAMPLE_RATE > 0", domain.ErrInvalidConfig)
+		}
+		if c.Processing.AdaptiveThresholdMin < 0 || c.Processing.AdaptiveThresholdMax > 1 ||
+			c.Processing.AdaptiveThresholdMin > c.Processing.AdaptiveThresholdMax {
+			return fmt.Errorf("%w: ADAPTIVE_THRESHOLD_MIN/MAX must satisfy 0 <= min <= max <= 1", domain.ErrInvalidConfig)
+		}
+	}
+
 	return nil
 }
 
@@ -161,35 +1424,44 @@ func (c *Config) Validate() error {
 
 func getEnvOrDefault(key, defaultVal string) string {
 	if val := os.Getenv(key); val != "" {
+		record(key, val, defaultVal, true)
 		return val
 	}
+	record(key, defaultVal, defaultVal, false)
 	return defaultVal
 }
 
 func getIntOrDefault(key string, defaultVal int) int {
 	if val := os.Getenv(key); val != "" {
 		if i, err := strconv.Atoi(val); err == nil {
+			record(key, strconv.Itoa(i), strconv.Itoa(defaultVal), true)
 			return i
 		}
 	}
+	record(key, strconv.Itoa(defaultVal), strconv.Itoa(defaultVal), false)
 	return defaultVal
 }
 
 func getBoolOrDefault(key string, defaultVal bool) bool {
 	if val := os.Getenv(key); val != "" {
 		if b, err := strconv.ParseBool(val); err == nil {
+			record(key, strconv.FormatBool(b), strconv.FormatBool(defaultVal), true)
 			return b
 		}
 	}
+	record(key, strconv.FormatBool(defaultVal), strconv.FormatBool(defaultVal), false)
 	return defaultVal
 }
 
 func getFloatOrDefault(key string, defaultVal float64) float64 {
+	format := func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
 	if val := os.Getenv(key); val != "" {
 		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			record(key, format(f), format(defaultVal), true)
 			return f
 		}
 	}
+	record(key, format(defaultVal), format(defaultVal), false)
 	return defaultVal
 }
 
@@ -197,12 +1469,47 @@ func getDurationOrDefault(key string, defaultVal time.Duration) time.Duration {
 	if val := os.Getenv(key); val != "" {
 		// Try parsing as seconds first (e.g., "15")
 		if secs, err := strconv.Atoi(val); err == nil {
-			return time.Duration(secs) * time.Second
+			d := time.Duration(secs) * time.Second
+			record(key, d.String(), defaultVal.String(), true)
+			return d
 		}
 		// Try parsing as duration string (e.g., "15s", "1m")
 		if d, err := time.ParseDuration(val); err == nil {
+			record(key, d.String(), defaultVal.String(), true)
 			return d
 		}
 	}
+	record(key, defaultVal.String(), defaultVal.String(), false)
 	return defaultVal
 }
+
+// getWeightsOrDefault parses a comma-separated list of name=weight pairs
+// (e.g. "platform=3,batch=1"). Malformed pairs are ignored.
+func getWeightsOrDefault(key string) map[string]float64 {
+	weights := make(map[string]float64)
+	val := os.Getenv(key)
+	for _, pair := range strings.Split(val, ",") {
+		name, raw, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			continue
+		}
+		if w, err := strconv.ParseFloat(strings.TrimSpace(raw), 64); err == nil {
+			weights[strings.TrimSpace(name)] = w
+		}
+	}
+	record(key, val, "", val != "")
+	return weights
+}
+
+// getListOrDefault parses a comma-separated list, dropping empty entries.
+func getListOrDefault(key string) []string {
+	var list []string
+	val := os.Getenv(key)
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	record(key, val, "", val != "")
+	return list
+}
@@ -0,0 +1,150 @@
+// Package config handles application configuration from environment variables.
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secret values in settings output.
+const redacted = "[REDACTED]"
+
+// secretSuffixes identify environment variables holding secrets.
+var secretSuffixes = []string{"_KEY", "_KEYS", "_SECRET", "_TOKEN", "_PASSWORD"}
+
+// loading collects settings while Load runs; loadMu serializes Load calls.
+var (
+	loadMu  sync.Mutex
+	loading map[string]Setting
+)
+
+// Setting describes one environment variable read by Load.
+type Setting struct {
+	// Key is the environment variable name.
+	Key string `json:"key"`
+
+	// Value is the effective value. Secrets are redacted.
+	Value string `json:"value"`
+
+	// Default is the built-in default value.
+	Default string `json:"default"`
+
+	// Overridden is true when the environment supplied a valid value.
+	Overridden bool `json:"overridden"`
+
+	// Secret is true when Value has been redacted.
+	Secret bool `json:"secret,omitempty"`
+
+	// fingerprint detects changes to secret values without storing them.
+	fingerprint string
+}
+
+// SettingChange describes a setting that differs between two configurations.
+type SettingChange struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Settings returns the effective settings recorded by Load, sorted by key,
+// with secret values redacted.
+func (c *Config) Settings() []Setting {
+	settings := make([]Setting, len(c.settings))
+	copy(settings, c.settings)
+	return settings
+}
+
+// DiffSettings returns the settings whose effective value differs between
+// old and new. Changed secrets are reported without revealing either value.
+func DiffSettings(old, new *Config) []SettingChange {
+	before := make(map[string]Setting, len(old.settings))
+	for _, s := range old.settings {
+		before[s.Key] = s
+	}
+
+	var changes []SettingChange
+	for _, s := range new.settings {
+		prev, ok := before[s.Key]
+		if ok && prev.Value == s.Value && prev.fingerprint == s.fingerprint {
+			continue
+		}
+		change := SettingChange{Key: s.Key, Old: prev.Value, New: s.Value}
+		if s.Secret {
+			change.Old, change.New = redacted, redacted+" (changed)"
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// record registers a setting read during Load. Caller holds loadMu.
+func record(key, value, defaultVal string, overridden bool) {
+	if loading == nil {
+		return
+	}
+
+	s := Setting{Key: key, Value: value, Default: defaultVal, Overridden: overridden}
+	if isSecret(key) {
+		s.Secret = true
+		s.fingerprint = secretFingerprint(value)
+		if value != "" {
+			s.Value = redacted
+		}
+		if defaultVal != "" {
+			s.Default = redacted
+		}
+	} else if masked, ok := redactURLPassword(value); ok {
+		// Connection URLs such as REDIS_URL may embed a password
+		s.Secret = true
+		s.fingerprint = secretFingerprint(value)
+		s.Value = masked
+	}
+	loading[key] = s
+}
+
+func isSecret(key string) bool {
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURLPassword masks the password of a URL with userinfo. It reports
+// false when value carries no password.
+func redactURLPassword(value string) (string, bool) {
+	if !strings.Contains(value, "@") {
+		return "", false
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return "", false
+	}
+	if _, ok := u.User.Password(); !ok {
+		return "", false
+	}
+	u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	return u.String(), true
+}
+
+func sortedSettings(m map[string]Setting) []Setting {
+	settings := make([]Setting, 0, len(m))
+	for _, s := range m {
+		settings = append(settings, s)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+func secretFingerprint(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
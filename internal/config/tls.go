@@ -0,0 +1,74 @@
+// Package config handles application configuration from environment variables.
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// tlsVersions maps AI_TLS_MIN_VERSION values to TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Proxy returns the proxy function for outbound AI requests: ProxyURL when
+// set, otherwise HTTP_PROXY, HTTPS_PROXY and NO_PROXY from the environment.
+func (c *AIConfig) Proxy() (func(*http.Request) (*url.URL, error), error) {
+	if c.ProxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(c.ProxyURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+		return nil, fmt.Errorf("AI_PROXY_URL must be an http(s) or socks5 URL")
+	}
+	return http.ProxyURL(u), nil
+}
+
+// TLSConfig returns the TLS configuration for outbound AI requests: the
+// system roots plus CACertFile, the client certificate if configured, and
+// TLSMinVersion.
+func (c *AIConfig) TLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSMinVersion != "" {
+		v, ok := tlsVersions[c.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("AI_TLS_MIN_VERSION must be 1.0, 1.1, 1.2 or 1.3")
+		}
+		tlsConfig.MinVersion = v
+	}
+
+	if c.CACertFile != "" {
+		pem, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("read AI_CA_CERT_FILE: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("AI_CA_CERT_FILE %s contains no PEM certificates", c.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (c.ClientCertFile == "") != (c.ClientKeyFile == "") {
+		return nil, fmt.Errorf("AI_CLIENT_CERT_FILE and AI_CLIENT_KEY_FILE must be set together")
+	}
+	if c.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load AI client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
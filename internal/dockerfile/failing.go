@@ -0,0 +1,139 @@
+// Package dockerfile reviews Dockerfiles for the Dockerfile endpoint.
+package dockerfile
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// sourceMarkerPattern matches the line BuildKit marks in the Dockerfile
+	// excerpt of an error ("  12 | >>> RUN npm run build").
+	sourceMarkerPattern = regexp.MustCompile(`(?m)(?:^|\s)(\d+)\s*\|\s*>>>`)
+
+	// buildKitStepPattern matches a failed BuildKit step in the progress
+	// ("ERROR [builder 4/6] RUN npm run build") or the error summary
+	// (" > [builder 4/6] RUN npm run build:"), without the step duration
+	// of the tty progress. The stage is a name, "stage-N" for unnamed
+	// stages of multi-stage builds, or absent.
+	buildKitStepPattern = regexp.MustCompile(`(?m)(?:ERROR:? |(?:^|\s)> )\[(?:([\w.-]+) )?\d+/\d+\] (.+?)(?:\s+\d+(?:\.\d+)?s)?:?\s*$`)
+
+	// baseImagePattern matches a base image BuildKit could not resolve
+	// ("ERROR [internal] load metadata for docker.io/library/node:18").
+	baseImagePattern = regexp.MustCompile(`(?m)ERROR:? \[internal\] load metadata for (\S+)`)
+
+	// processPattern matches the command of a failed RUN in BuildKit's
+	// summary (process "/bin/sh -c npm ci" did not complete successfully).
+	processPattern = regexp.MustCompile(`process "(?:/bin/(?:ba)?sh -c )?((?:[^"\\]|\\.)*)" did not complete successfully`)
+
+	// legacyStepPattern matches a step of the legacy builder
+	// ("Step 5/9 : RUN make").
+	legacyStepPattern = regexp.MustCompile(`(?m)(?:^|\s)Step (\d+)/\d+ : (.+?)\s*$`)
+)
+
+// FailingInstruction returns the instruction a build log shows failing, or
+// false. BuildKit's Dockerfile excerpt pins the line; otherwise the failed
+// step, the unresolvable base image, the failed RUN command or the last
+// step of the legacy builder is matched against the instructions' text.
+func FailingInstruction(instructions []Instruction, buildLog string) (Instruction, bool) {
+	if m := sourceMarkerPattern.FindStringSubmatch(buildLog); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		// The marked line may continue an instruction
+		for i := len(instructions) - 1; i >= 0; i-- {
+			if instructions[i].Line <= line {
+				return instructions[i], true
+			}
+		}
+	}
+
+	if m := buildKitStepPattern.FindStringSubmatch(buildLog); m != nil {
+		if inst, ok := matchText(instructions, m[2], m[1], -1); ok {
+			return inst, true
+		}
+	}
+
+	if m := baseImagePattern.FindStringSubmatch(buildLog); m != nil {
+		for _, inst := range instructions {
+			if inst.Command == "FROM" && normalizeImage(fromImage(inst.Args)) == normalizeImage(m[1]) {
+				return inst, true
+			}
+		}
+	}
+
+	if m := processPattern.FindStringSubmatch(buildLog); m != nil {
+		command := normalizeSpace(strings.ReplaceAll(m[1], `\"`, `"`))
+		for _, inst := range instructions {
+			if inst.Command == "RUN" && normalizeSpace(inst.ShellCommand()) == command {
+				return inst, true
+			}
+		}
+	}
+
+	if steps := legacyStepPattern.FindAllStringSubmatch(buildLog, -1); len(steps) > 0 {
+		last := steps[len(steps)-1]
+		step, _ := strconv.Atoi(last[1])
+		if inst, ok := matchText(instructions, last[2], "", step-1); ok {
+			return inst, true
+		}
+	}
+	return Instruction{}, false
+}
+
+// matchText returns the instruction whose text is text, preferring the
+// given stage label and the instruction at index. BuildKit shortens long
+// step names, so a prefix of at least 20 characters also matches.
+func matchText(instructions []Instruction, text, stage string, index int) (Instruction, bool) {
+	text = normalizeSpace(text)
+	var candidates []int
+	for i, inst := range instructions {
+		if stage != "" && !inStage(inst, stage) {
+			continue
+		}
+		if normalizeSpace(inst.String()) == text {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 && len(text) >= 20 {
+		for i, inst := range instructions {
+			if (stage == "" || inStage(inst, stage)) && strings.HasPrefix(normalizeSpace(inst.String()), text) {
+				candidates = append(candidates, i)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return Instruction{}, false
+	}
+	for _, i := range candidates {
+		if i == index {
+			return instructions[i], true
+		}
+	}
+	return instructions[candidates[0]], true
+}
+
+// inStage reports whether inst belongs to the stage BuildKit labels label.
+func inStage(inst Instruction, label string) bool {
+	return strings.EqualFold(inst.StageName, label) || label == "stage-"+strconv.Itoa(inst.Stage)
+}
+
+// normalizeImage returns the fully qualified reference of an image
+// (node:18 is docker.io/library/node:18), with the implicit latest tag.
+func normalizeImage(image string) string {
+	first, rest, hasPath := strings.Cut(image, "/")
+	switch {
+	case !hasPath:
+		image = "docker.io/library/" + image
+	case !strings.ContainsAny(first, ".:") && first != "localhost":
+		image = "docker.io/" + first + "/" + rest
+	}
+	if !strings.Contains(image, "@") && !strings.Contains(image[strings.LastIndex(image, "/")+1:], ":") {
+		image += ":latest"
+	}
+	return image
+}
+
+// normalizeSpace collapses runs of whitespace.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
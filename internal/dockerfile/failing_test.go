@@ -0,0 +1,72 @@
+// Package dockerfile provides unit tests for Dockerfile parsing, linting
+// and failing instruction detection.
+package dockerfile
+
+import "testing"
+
+const multiStage = `FROM node:20-alpine AS builder
+WORKDIR /app
+COPY package*.json ./
+RUN npm ci
+COPY . .
+RUN npm run build \
+    --production
+
+FROM nginx:1.25
+WORKDIR /app
+COPY --from=builder /app/dist /usr/share/nginx/html
+`
+
+func TestFailingInstruction(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      string
+		wantLine int
+	}{
+		{
+			name: "buildkit source excerpt",
+			log: "Dockerfile:7\n--------------------\n   5 |     COPY . .\n   6 | >>> RUN npm run build \\\n   7 | >>>     --production\n" +
+				"--------------------\nERROR: failed to solve: process \"/bin/sh -c npm run build --production\" did not complete successfully: exit code: 1",
+			wantLine: 6,
+		},
+		{
+			name:     "buildkit error summary with stage",
+			log:      "------\n > [stage-1 2/3] WORKDIR /app:\n------\nERROR: failed to solve: mkdir /app: permission denied",
+			wantLine: 10,
+		},
+		{
+			name:     "buildkit tty progress",
+			log:      "2024-05-01T10:00:00Z => ERROR [builder 3/6] RUN npm ci                      12.1s",
+			wantLine: 4,
+		},
+		{
+			name:     "failed RUN command",
+			log:      "#9 ERROR: process \"/bin/sh -c npm ci\" did not complete successfully: exit code: 1",
+			wantLine: 4,
+		},
+		{
+			name:     "unresolvable base image",
+			log:      "ERROR [internal] load metadata for docker.io/library/nginx:1.25\nERROR: failed to solve: nginx:1.25: not found",
+			wantLine: 9,
+		},
+		{
+			name:     "legacy builder",
+			log:      "Step 1/10 : FROM node:20-alpine AS builder\nStep 4/10 : RUN npm ci\n ---> Running in 3f2a\nThe command '/bin/sh -c npm ci' returned a non-zero code: 1",
+			wantLine: 4,
+		},
+	}
+
+	instructions := Parse(multiStage)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FailingInstruction(instructions, tt.log)
+			if !ok || got.Line != tt.wantLine {
+				t.Errorf("FailingInstruction() = %+v, %v, want line %d", got, ok, tt.wantLine)
+			}
+		})
+	}
+
+	if got, ok := FailingInstruction(instructions, "npm ERR! code ERESOLVE"); ok {
+		t.Errorf("FailingInstruction() = %+v for a log without a step", got)
+	}
+}
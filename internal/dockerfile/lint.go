@@ -0,0 +1,319 @@
+// Package dockerfile reviews Dockerfiles for the Dockerfile endpoint.
+package dockerfile
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// knownInstructions are the instructions the Dockerfile frontend accepts.
+var knownInstructions = map[string]bool{
+	"ADD": true, "ARG": true, "CMD": true, "COPY": true, "ENTRYPOINT": true,
+	"ENV": true, "EXPOSE": true, "FROM": true, "HEALTHCHECK": true, "LABEL": true,
+	"MAINTAINER": true, "ONBUILD": true, "RUN": true, "SHELL": true,
+	"STOPSIGNAL": true, "USER": true, "VOLUME": true, "WORKDIR": true,
+}
+
+var (
+	// commandSeparator splits a shell command into simple commands.
+	commandSeparator = regexp.MustCompile(`&&|\|\||;|\n`)
+
+	aptInstallPattern = regexp.MustCompile(`\bapt(?:-get)?\s+(?:\S+\s+)*install\b`)
+	aptUpdatePattern  = regexp.MustCompile(`\bapt(?:-get)?\s+(?:\S+\s+)*update\b`)
+	// Any of -y, -qq, --yes, --assume-yes (or combined short flags such as
+	// -qy) answers apt's prompt
+	aptAssumeYesPattern = regexp.MustCompile(`(?:^|\s)(?:-[a-z]*y[a-z]*|-qq|--yes|--assume-yes)(?:\s|$)`)
+	apkAddPattern       = regexp.MustCompile(`\bapk\s+(?:\S+\s+)*add\b`)
+	pipInstallPattern   = regexp.MustCompile(`\bpip3?\s+(?:\S+\s+)*install\b`)
+
+	// curlPipeShellPattern matches downloads piped into a shell.
+	curlPipeShellPattern = regexp.MustCompile(`\b(?:curl|wget)\b[^|;&]*\|\s*(?:sudo\s+)?(?:ba|z|da)?sh\b`)
+
+	// dependencyInstallPattern matches commands installing dependencies
+	// from a manifest.
+	dependencyInstallPattern = regexp.MustCompile(`\b(?:npm\s+(?:ci|install|i)|yarn\s+install|yarn\s+--(?:frozen-lockfile|immutable)|pnpm\s+install|pip3?\s+install\s+(?:\S+\s+)*-r|poetry\s+install|go\s+mod\s+download|bundle\s+install|composer\s+install|mvn\s+(?:\S+\s+)*dependency:)(?:\s|$)`)
+
+	// secretNamePattern matches environment variable and build argument
+	// names that hold credentials.
+	secretNamePattern = regexp.MustCompile(`(?i)(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|ACCESS_?KEY)$`)
+
+	// archivePattern matches the local archives ADD extracts.
+	archivePattern = regexp.MustCompile(`(?i)\.(?:tar|tar\.gz|tgz|tar\.bz2|tbz2?|tar\.xz|txz)$`)
+)
+
+// Lint checks instructions against Dockerfile best practices and returns
+// the findings in Dockerfile order, at most one per rule and instruction.
+func Lint(instructions []Instruction) []domain.DockerfileFinding {
+	l := &linter{stages: map[string]bool{}}
+	pipNoCache := false
+	for _, inst := range instructions {
+		if (inst.Command == "ENV" || inst.Command == "ARG") && strings.Contains(inst.Args, "PIP_NO_CACHE_DIR") {
+			pipNoCache = true
+		}
+	}
+
+	for i, inst := range instructions {
+		switch inst.Command {
+		case "FROM":
+			l.from(inst)
+		case "RUN":
+			l.run(inst, pipNoCache)
+			if inst.Stage >= 0 && dependencyInstallPattern.MatchString(inst.ShellCommand()) {
+				l.copyBeforeInstall(instructions[:i], inst.Stage)
+			}
+		case "ADD":
+			if addsLocalFiles(inst.Args) {
+				l.add(inst, "use_copy", domain.SeverityLow, "ADD of local files; use COPY, which does not fetch URLs or extract archives implicitly")
+			}
+		case "ENV", "ARG":
+			for _, name := range variableNames(inst) {
+				if secretNamePattern.MatchString(name) {
+					l.add(inst, "secret_in_env", domain.SeverityHigh, inst.Command+" "+name+" stores a credential in the image metadata and history; pass it with RUN --mount=type=secret")
+					break
+				}
+			}
+		case "CMD", "ENTRYPOINT":
+			if !inst.ExecForm() {
+				l.add(inst, "shell_form_cmd", domain.SeverityLow, inst.Command+" in shell form runs under /bin/sh -c, which does not forward SIGTERM; use the JSON exec form")
+			}
+		case "WORKDIR":
+			if !strings.HasPrefix(inst.Args, "/") && !strings.HasPrefix(inst.Args, "$") && !windowsPath(inst.Args) {
+				l.add(inst, "workdir_relative", domain.SeverityLow, "relative WORKDIR depends on the previous WORKDIR; use an absolute path")
+			}
+		case "MAINTAINER":
+			l.add(inst, "deprecated_maintainer", domain.SeverityLow, "MAINTAINER is deprecated; use LABEL org.opencontainers.image.authors")
+		default:
+			if !knownInstructions[inst.Command] {
+				l.add(inst, "unknown_instruction", domain.SeverityHigh, "unknown instruction "+inst.Command+" fails the build")
+			}
+		}
+	}
+	l.lastDefinitions(instructions)
+	l.finalUser(instructions)
+
+	sort.SliceStable(l.findings, func(i, j int) bool { return l.findings[i].Line < l.findings[j].Line })
+	return l.findings
+}
+
+// linter collects findings.
+type linter struct {
+	findings []domain.DockerfileFinding
+
+	// stages are the names of the stages declared so far, in lower case.
+	stages map[string]bool
+
+	// copyFlagged marks stages already reported by copy_before_install.
+	copyFlagged []int
+}
+
+// add records a finding for inst unless rule already reported it.
+func (l *linter) add(inst Instruction, rule string, severity domain.Severity, message string) {
+	for _, f := range l.findings {
+		if f.Rule == rule && f.Line == inst.Line {
+			return
+		}
+	}
+	l.findings = append(l.findings, domain.DockerfileFinding{
+		Rule:        rule,
+		Severity:    severity,
+		Line:        inst.Line,
+		Instruction: inst.String(),
+		Message:     message,
+	})
+}
+
+// from checks that base images are pinned. Earlier stages, scratch and
+// images set by build arguments are exempt.
+func (l *linter) from(inst Instruction) {
+	image := fromImage(inst.Args)
+	name := strings.ToLower(fromStageName(inst.Args))
+	defer func() {
+		if name != "" {
+			l.stages[name] = true
+		}
+	}()
+	if image == "" || image == "scratch" || l.stages[strings.ToLower(image)] ||
+		strings.Contains(image, "$") || strings.Contains(image, "@") {
+		return
+	}
+	repository := image[strings.LastIndex(image, "/")+1:]
+	_, tag, tagged := strings.Cut(repository, ":")
+	switch {
+	case !tagged:
+		l.add(inst, "pin_base_image", domain.SeverityMedium, "base image "+image+" has no tag and resolves to latest; pin a version or digest")
+	case tag == "latest":
+		l.add(inst, "pin_base_image", domain.SeverityMedium, "base image "+image+" uses the latest tag; pin a version or digest")
+	}
+}
+
+// run checks a RUN instruction's commands.
+func (l *linter) run(inst Instruction, pipNoCache bool) {
+	command := inst.ShellCommand()
+	cacheMount := strings.Contains(inst.Args, "--mount=type=cache")
+	installs, updates := false, false
+
+	for _, simple := range commandSeparator.Split(command, -1) {
+		simple = strings.TrimSpace(simple)
+		if strings.HasPrefix(simple, "sudo ") {
+			l.add(inst, "sudo", domain.SeverityMedium, "RUN already runs as the current USER; sudo is usually missing from images and hides the real user")
+		}
+		switch {
+		case aptInstallPattern.MatchString(simple):
+			installs = true
+			if !aptAssumeYesPattern.MatchString(simple) {
+				l.add(inst, "apt_assume_yes", domain.SeverityMedium, "apt-get install without -y waits for a confirmation and aborts the build")
+			}
+			if !strings.Contains(simple, "--no-install-recommends") {
+				l.add(inst, "apt_no_install_recommends", domain.SeverityLow, "apt-get install without --no-install-recommends adds unneeded packages")
+			}
+		case aptUpdatePattern.MatchString(simple):
+			updates = true
+		case apkAddPattern.MatchString(simple) && !strings.Contains(simple, "--no-cache") && !cacheMount:
+			l.add(inst, "apk_no_cache", domain.SeverityLow, "apk add without --no-cache keeps the package index in the image")
+		case pipInstallPattern.MatchString(simple) && !strings.Contains(simple, "--no-cache-dir") && !pipNoCache && !cacheMount:
+			l.add(inst, "pip_no_cache", domain.SeverityLow, "pip install without --no-cache-dir keeps downloaded packages in the image")
+		case strings.HasPrefix(simple, "cd "):
+			l.add(inst, "run_cd", domain.SeverityLow, "cd only lasts for this RUN; use WORKDIR")
+		}
+	}
+
+	if updates && !installs {
+		l.add(inst, "apt_update_alone", domain.SeverityMedium, "apt-get update in its own RUN is cached, so later installs use stale package lists; update and install in one RUN")
+	}
+	if installs && !cacheMount && !strings.Contains(command, "/var/lib/apt/lists") {
+		l.add(inst, "apt_lists_not_removed", domain.SeverityLow, "apt package lists are kept in the layer; remove /var/lib/apt/lists/* in the same RUN")
+	}
+	if curlPipeShellPattern.MatchString(command) {
+		l.add(inst, "curl_pipe_shell", domain.SeverityHigh, "a downloaded script is piped into a shell without verification; download it, check its checksum, then run it")
+	}
+}
+
+// copyBeforeInstall reports a COPY of the whole build context before a
+// dependency install in the same stage: every source change then
+// invalidates the cached install.
+func (l *linter) copyBeforeInstall(earlier []Instruction, stage int) {
+	for _, flagged := range l.copyFlagged {
+		if flagged == stage {
+			return
+		}
+	}
+	for _, inst := range earlier {
+		if inst.Stage != stage || inst.Command != "COPY" || !copiesContext(inst.Args) {
+			continue
+		}
+		l.add(inst, "copy_before_install", domain.SeverityMedium, "the whole build context is copied before dependencies are installed, so any change reinstalls them; copy the manifest files first")
+		l.copyFlagged = append(l.copyFlagged, stage)
+		return
+	}
+}
+
+// lastDefinitions reports CMD and ENTRYPOINT instructions overridden later
+// in the same stage.
+func (l *linter) lastDefinitions(instructions []Instruction) {
+	for i, inst := range instructions {
+		if inst.Command != "CMD" && inst.Command != "ENTRYPOINT" {
+			continue
+		}
+		for _, later := range instructions[i+1:] {
+			if later.Stage == inst.Stage && later.Command == inst.Command {
+				l.add(inst, "multiple_cmd", domain.SeverityLow, "only the last "+inst.Command+" of a stage takes effect")
+				break
+			}
+		}
+	}
+}
+
+// finalUser reports a final stage that runs as root.
+func (l *linter) finalUser(instructions []Instruction) {
+	var from, user *Instruction
+	for i := range instructions {
+		switch instructions[i].Command {
+		case "FROM":
+			from, user = &instructions[i], nil
+		case "USER":
+			user = &instructions[i]
+		}
+	}
+	switch {
+	case from == nil || fromImage(from.Args) == "scratch":
+	case user == nil:
+		l.add(*from, "root_user", domain.SeverityMedium, "the final stage sets no USER, so the container runs as root")
+	case isRoot(user.Args):
+		l.add(*user, "root_user", domain.SeverityMedium, "the final stage runs as root")
+	}
+}
+
+// isRoot reports whether a USER argument is the root user.
+func isRoot(user string) bool {
+	name, _, _ := strings.Cut(strings.TrimSpace(user), ":")
+	return name == "root" || name == "0"
+}
+
+// addsLocalFiles reports whether ADD arguments copy local files that are
+// not archives, which COPY does as well.
+func addsLocalFiles(args string) bool {
+	var sources []string
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "--") {
+			sources = append(sources, field)
+		}
+	}
+	if len(sources) < 2 || strings.HasPrefix(args, "[") {
+		return false
+	}
+	for _, source := range sources[:len(sources)-1] {
+		if strings.Contains(source, "://") || strings.HasPrefix(source, "git@") || archivePattern.MatchString(source) || strings.HasPrefix(source, "<<") {
+			return false
+		}
+	}
+	return true
+}
+
+// copiesContext reports whether COPY arguments copy the whole build
+// context (COPY . . or COPY ./ /app).
+func copiesContext(args string) bool {
+	var sources []string
+	for _, field := range strings.Fields(args) {
+		if strings.HasPrefix(field, "--from") {
+			return false
+		}
+		if !strings.HasPrefix(field, "--") {
+			sources = append(sources, field)
+		}
+	}
+	if len(sources) < 2 {
+		return false
+	}
+	for _, source := range sources[:len(sources)-1] {
+		if source == "." || source == "./" {
+			return true
+		}
+	}
+	return false
+}
+
+// variableNames returns the names an ENV or ARG instruction defines.
+func variableNames(inst Instruction) []string {
+	fields := strings.Fields(inst.Args)
+	if len(fields) == 0 {
+		return nil
+	}
+	// Legacy ENV form: ENV NAME value
+	if inst.Command == "ENV" && !strings.Contains(fields[0], "=") {
+		return fields[:1]
+	}
+	var names []string
+	for _, field := range fields {
+		name, _, _ := strings.Cut(field, "=")
+		names = append(names, name)
+	}
+	return names
+}
+
+// windowsPath reports whether path is an absolute Windows path (C:\app).
+func windowsPath(path string) bool {
+	return len(path) >= 3 && path[1] == ':' && (path[2] == '\\' || path[2] == '/')
+}
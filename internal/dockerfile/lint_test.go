@@ -0,0 +1,81 @@
+// Package dockerfile provides unit tests for Dockerfile parsing, linting
+// and failing instruction detection.
+package dockerfile
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		want       []string // rule@line
+	}{
+		{
+			name:       "clean multi-stage build",
+			dockerfile: nodeDockerfile + "USER nginx\n",
+			want:       []string{"copy_before_install@6"},
+		},
+		{
+			name: "apt without flags",
+			dockerfile: "FROM debian:12\n" +
+				"RUN apt-get update\n" +
+				"RUN apt-get install curl\n" +
+				"USER app\n",
+			want: []string{"apt_update_alone@2", "apt_assume_yes@3", "apt_no_install_recommends@3", "apt_lists_not_removed@3"},
+		},
+		{
+			name: "apt done right",
+			dockerfile: "FROM debian:12\n" +
+				"RUN DEBIAN_FRONTEND=noninteractive apt-get update && apt-get install -y --no-install-recommends curl \\\n" +
+				"    && rm -rf /var/lib/apt/lists/*\n" +
+				"USER app\n",
+		},
+		{
+			name:       "unpinned base images",
+			dockerfile: "FROM golang AS build\nFROM build\nFROM alpine:latest\nFROM scratch\n",
+			want:       []string{"pin_base_image@1", "pin_base_image@3"},
+		},
+		{
+			name: "security",
+			dockerfile: "FROM python:3.12-slim\n" +
+				"ENV DB_PASSWORD=hunter2 APP_ENV=prod\n" +
+				"ARG NPM_TOKEN\n" +
+				"RUN curl -fsSL https://example.com/install.sh | sh\n" +
+				"RUN sudo pip install flask\n" +
+				"USER root\n",
+			want: []string{"secret_in_env@2", "secret_in_env@3", "curl_pipe_shell@4", "sudo@5", "pip_no_cache@5", "root_user@6"},
+		},
+		{
+			name: "instruction hygiene",
+			dockerfile: "FROM alpine:3.19\n" +
+				"MAINTAINER ops@example.com\n" +
+				"ADD app.py /app/\n" +
+				"ADD https://example.com/tool.tgz /opt/\n" +
+				"WORKDIR app\n" +
+				"RUN cd /tmp && apk add git\n" +
+				"CPY . .\n" +
+				"CMD python app.py\n" +
+				"CMD [\"python\", \"app.py\"]\n",
+			want: []string{
+				"root_user@1", "deprecated_maintainer@2", "use_copy@3", "workdir_relative@5",
+				"run_cd@6", "apk_no_cache@6", "unknown_instruction@7", "shell_form_cmd@8", "multiple_cmd@8",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range Lint(Parse(tt.dockerfile)) {
+				got = append(got, fmt.Sprintf("%s@%d", f.Rule, f.Line))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
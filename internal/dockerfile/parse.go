@@ -0,0 +1,198 @@
+// Package dockerfile reviews Dockerfiles for the Dockerfile endpoint. Build
+// failures are often best explained by the Dockerfile itself, so the
+// instructions are parsed, checked against best practices (Lint) and
+// matched against the build log to find the instruction that failed
+// (FailingInstruction). The Dockerfile, the findings and the build log are
+// then analyzed together in the Docker mode.
+package dockerfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// ErrEmptyDockerfile indicates a request without Dockerfile instructions.
+var ErrEmptyDockerfile = fmt.Errorf("%w: request has no dockerfile instructions", domain.ErrEmptyLog)
+
+// escapeDirective matches the escape parser directive.
+var escapeDirective = regexp.MustCompile(`(?i)^#\s*escape\s*=\s*([\\` + "`" + `])\s*$`)
+
+// directivePattern matches any parser directive (# syntax=..., # check=...).
+var directivePattern = regexp.MustCompile(`^#\s*[A-Za-z]+\s*=`)
+
+// heredocPattern matches a heredoc start (<<EOF, <<-EOF, <<"EOF") and
+// captures the terminator.
+var heredocPattern = regexp.MustCompile(`<<(-?)["']?([A-Za-z_][A-Za-z0-9_]*)["']?`)
+
+// Instruction is a parsed Dockerfile instruction.
+type Instruction struct {
+	// Line is the 1-based line where the instruction starts.
+	Line int
+
+	// Command is the upper-cased keyword, e.g. "RUN".
+	Command string
+
+	// Args are the arguments with continuation lines joined. Heredoc
+	// bodies follow on separate lines.
+	Args string
+
+	// Stage is the index of the build stage, or -1 for instructions (ARG)
+	// before the first FROM; StageName is the stage's name (FROM ... AS
+	// name), if any.
+	Stage     int
+	StageName string
+}
+
+// String returns the instruction as written, continuation lines joined.
+func (i Instruction) String() string {
+	if i.Args == "" {
+		return i.Command
+	}
+	return i.Command + " " + i.Args
+}
+
+// ExecForm reports whether the arguments are a JSON array, as in
+// CMD ["node", "server.js"].
+func (i Instruction) ExecForm() bool {
+	var args []string
+	return strings.HasPrefix(i.Args, "[") && json.Unmarshal([]byte(i.Args), &args) == nil
+}
+
+// ShellCommand returns the command a RUN, CMD or ENTRYPOINT instruction
+// runs: the arguments of the shell form, or the exec form's arguments
+// joined by spaces. Flags such as --mount are dropped.
+func (i Instruction) ShellCommand() string {
+	var args []string
+	if strings.HasPrefix(i.Args, "[") && json.Unmarshal([]byte(i.Args), &args) == nil {
+		return strings.Join(args, " ")
+	}
+	rest := i.Args
+	for strings.HasPrefix(rest, "--") {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			return ""
+		}
+		rest = strings.TrimLeft(rest[end:], " \t")
+	}
+	return rest
+}
+
+// toDomain returns the instruction for responses.
+func (i Instruction) toDomain() *domain.DockerfileInstruction {
+	return &domain.DockerfileInstruction{Line: i.Line, Instruction: i.String(), Stage: i.StageName}
+}
+
+// Parse returns the instructions of a Dockerfile. Comments and blank lines
+// are skipped, continuation lines (with the escape character set by the
+// escape parser directive) are joined and heredoc bodies are kept with
+// their instruction. Malformed lines become instructions too, so Lint can
+// report them.
+func Parse(content string) []Instruction {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	escape := byte('\\')
+	var instructions []Instruction
+	stage, stageName := -1, ""
+	directives := true
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if directives {
+			if m := escapeDirective.FindStringSubmatch(line); m != nil {
+				escape = m[1][0]
+				continue
+			}
+			if directivePattern.MatchString(line) {
+				continue
+			}
+			directives = false
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		start := i
+		var text strings.Builder
+		for {
+			continued := strings.HasSuffix(line, string(escape))
+			if continued {
+				line = strings.TrimSpace(line[:len(line)-1])
+			}
+			if line != "" {
+				if text.Len() > 0 {
+					text.WriteByte(' ')
+				}
+				text.WriteString(line)
+			}
+			if !continued || i+1 >= len(lines) {
+				break
+			}
+			i++
+			line = strings.TrimSpace(lines[i])
+			// Comments and blank lines inside a continuation are skipped
+			for (line == "" || strings.HasPrefix(line, "#")) && i+1 < len(lines) {
+				i++
+				line = strings.TrimSpace(lines[i])
+			}
+			if line == "" || strings.HasPrefix(line, "#") {
+				break
+			}
+		}
+
+		command, args, _ := strings.Cut(text.String(), " ")
+		inst := Instruction{Line: start + 1, Command: strings.ToUpper(command), Args: strings.TrimSpace(args)}
+		if inst.Command == "RUN" || inst.Command == "COPY" || inst.Command == "ADD" {
+			for _, m := range heredocPattern.FindAllStringSubmatch(inst.Args, -1) {
+				var body []string
+				for i+1 < len(lines) {
+					i++
+					bodyLine := lines[i]
+					if m[1] == "-" {
+						bodyLine = strings.TrimLeft(bodyLine, "\t")
+					}
+					if strings.TrimRight(bodyLine, " \t") == m[2] {
+						break
+					}
+					body = append(body, bodyLine)
+				}
+				if len(body) > 0 {
+					inst.Args += "\n" + strings.Join(body, "\n")
+				}
+			}
+		}
+
+		if inst.Command == "FROM" {
+			stage++
+			stageName = fromStageName(inst.Args)
+		}
+		inst.Stage, inst.StageName = stage, stageName
+		instructions = append(instructions, inst)
+	}
+	return instructions
+}
+
+// fromImage returns the image of FROM arguments, skipping flags such as
+// --platform.
+func fromImage(args string) string {
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "--") {
+			return field
+		}
+	}
+	return ""
+}
+
+// fromStageName returns the stage name of FROM arguments (FROM image AS
+// name), or "".
+func fromStageName(args string) string {
+	fields := strings.Fields(args)
+	for i := 0; i+1 < len(fields); i++ {
+		if strings.EqualFold(fields[i], "AS") {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
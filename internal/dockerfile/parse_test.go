@@ -0,0 +1,112 @@
+// Package dockerfile provides unit tests for Dockerfile parsing, linting
+// and failing instruction detection.
+package dockerfile
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+const nodeDockerfile = `# syntax=docker/dockerfile:1
+ARG NODE_VERSION=20
+
+FROM node:${NODE_VERSION}-alpine AS builder
+WORKDIR /app
+COPY . .
+# Install and build
+RUN npm ci && \
+    # comments inside a continuation are skipped
+    npm run build
+
+FROM nginx:1.25
+COPY --from=builder /app/dist /usr/share/nginx/html
+RUN <<EOF
+echo built > /status
+EOF
+CMD ["nginx", "-g", "daemon off;"]
+`
+
+func TestParse(t *testing.T) {
+	got := Parse(nodeDockerfile)
+	want := []Instruction{
+		{Line: 2, Command: "ARG", Args: "NODE_VERSION=20", Stage: -1},
+		{Line: 4, Command: "FROM", Args: "node:${NODE_VERSION}-alpine AS builder", Stage: 0, StageName: "builder"},
+		{Line: 5, Command: "WORKDIR", Args: "/app", Stage: 0, StageName: "builder"},
+		{Line: 6, Command: "COPY", Args: ". .", Stage: 0, StageName: "builder"},
+		{Line: 8, Command: "RUN", Args: "npm ci && npm run build", Stage: 0, StageName: "builder"},
+		{Line: 12, Command: "FROM", Args: "nginx:1.25", Stage: 1},
+		{Line: 13, Command: "COPY", Args: "--from=builder /app/dist /usr/share/nginx/html", Stage: 1},
+		{Line: 14, Command: "RUN", Args: "<<EOF\necho built > /status", Stage: 1},
+		{Line: 17, Command: "CMD", Args: `["nginx", "-g", "daemon off;"]`, Stage: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParse_EscapeDirective(t *testing.T) {
+	got := Parse("# escape=`\nFROM mcr.microsoft.com/windows/servercore:ltsc2022\nRUN dir C:\\ `\n    && echo done\n")
+	if len(got) != 2 || got[1].Args != `dir C:\ && echo done` {
+		t.Errorf("Parse() = %+v, want the RUN continued with the backtick", got)
+	}
+}
+
+func TestInstruction_ShellCommand(t *testing.T) {
+	tests := []struct {
+		args string
+		want string
+	}{
+		{"npm ci", "npm ci"},
+		{"--mount=type=cache,target=/root/.npm npm ci", "npm ci"},
+		{`["npm", "ci"]`, "npm ci"},
+		{"--network=none", ""},
+	}
+	for _, tt := range tests {
+		if got := (Instruction{Command: "RUN", Args: tt.args}).ShellCommand(); got != tt.want {
+			t.Errorf("ShellCommand(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRequest_AnalysisRequest(t *testing.T) {
+	req := &Request{
+		Dockerfile: "FROM node\nRUN npm ci\n",
+		BuildLog:   "#6 ERROR: process \"/bin/sh -c npm ci\" did not complete successfully: exit code: 1",
+		Detail:     domain.DetailBrief,
+	}
+	got, review, err := req.AnalysisRequest()
+	if err != nil {
+		t.Fatalf("AnalysisRequest() error = %v", err)
+	}
+
+	var names []string
+	for _, section := range got.Sections {
+		names = append(names, section.Name)
+	}
+	if want := []string{"build log", "dockerfile", "findings"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sections = %q, want %q", names, want)
+	}
+	if got.Mode != domain.AnalysisModeDocker || got.Detail != domain.DetailBrief {
+		t.Errorf("mode, detail = %q, %q", got.Mode, got.Detail)
+	}
+	if want := "   1 | FROM node\n   2 | RUN npm ci"; got.Sections[1].Content != want {
+		t.Errorf("dockerfile section = %q, want %q", got.Sections[1].Content, want)
+	}
+	if !strings.HasPrefix(got.Sections[2].Content, "failing instruction: line 2: RUN npm ci\n") {
+		t.Errorf("findings section = %q", got.Sections[2].Content)
+	}
+	if review.FailingInstruction == nil || review.FailingInstruction.Line != 2 {
+		t.Errorf("FailingInstruction = %+v, want line 2", review.FailingInstruction)
+	}
+	if want := []string{"Dockerfile line 2: RUN npm ci"}; !reflect.DeepEqual(Evidence(review), want) {
+		t.Errorf("Evidence() = %q, want %q", Evidence(review), want)
+	}
+
+	if _, _, err := (&Request{Dockerfile: "# only a comment\n"}).AnalysisRequest(); !errors.Is(err, domain.ErrEmptyLog) {
+		t.Errorf("empty dockerfile: error = %v, want ErrEmptyLog", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Package dockerfile reviews Dockerfiles for the Dockerfile endpoint.
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Request is a Dockerfile review request: the Dockerfile and, optionally,
+// the log of the build that failed, with the options of
+// domain.AnalysisRequest.
+type Request struct {
+	Dockerfile string `json:"dockerfile"`
+	BuildLog   string `json:"build_log,omitempty"`
+
+	Language  string              `json:"language,omitempty"`
+	Detail    domain.DetailLevel  `json:"detail,omitempty"`
+	Metadata  *domain.LogMetadata `json:"metadata,omitempty"`
+	Explain   bool                `json:"explain,omitempty"`
+	TimeoutMS int                 `json:"timeout_ms,omitempty"`
+}
+
+// AnalysisRequest reviews the Dockerfile and returns the analysis request
+// for r in the Docker mode with the review. The build log, the Dockerfile
+// with line numbers and the findings become sections, in that order, so
+// rules and the AI see the failure next to the instructions.
+func (r *Request) AnalysisRequest() (*domain.AnalysisRequest, *domain.DockerfileReview, error) {
+	instructions := Parse(r.Dockerfile)
+	if len(instructions) == 0 {
+		return nil, nil, ErrEmptyDockerfile
+	}
+
+	review := &domain.DockerfileReview{Findings: Lint(instructions)}
+	if review.Findings == nil {
+		review.Findings = []domain.DockerfileFinding{}
+	}
+	if inst, ok := FailingInstruction(instructions, r.BuildLog); ok {
+		review.FailingInstruction = inst.toDomain()
+	}
+
+	var sections []domain.LogSection
+	if strings.TrimSpace(r.BuildLog) != "" {
+		sections = append(sections, domain.LogSection{Name: "build log", Content: r.BuildLog})
+	}
+	sections = append(sections, domain.LogSection{Name: "dockerfile", Content: numberLines(r.Dockerfile)})
+	if summary := renderReview(review); summary != "" {
+		sections = append(sections, domain.LogSection{Name: "findings", Content: summary})
+	}
+
+	return &domain.AnalysisRequest{
+		Sections:  sections,
+		Language:  r.Language,
+		Metadata:  r.Metadata,
+		Detail:    r.Detail,
+		Mode:      domain.AnalysisModeDocker,
+		Explain:   r.Explain,
+		TimeoutMS: r.TimeoutMS,
+	}, review, nil
+}
+
+// numberLines prefixes each line with its number, as in BuildKit's
+// Dockerfile excerpts, so answers can cite lines.
+func numberLines(content string) string {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf("%4d | %s", i+1, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderReview lists the failing instruction and the findings, one per
+// line, or returns "".
+func renderReview(review *domain.DockerfileReview) string {
+	var lines []string
+	if inst := review.FailingInstruction; inst != nil {
+		lines = append(lines, fmt.Sprintf("failing instruction: line %d: %s", inst.Line, firstLine(inst.Instruction)))
+	}
+	for _, f := range review.Findings {
+		lines = append(lines, fmt.Sprintf("line %d [%s] %s: %s", f.Line, f.Severity, f.Rule, f.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Evidence returns the failing instruction of review as result evidence,
+// or nil.
+func Evidence(review *domain.DockerfileReview) []string {
+	if review == nil || review.FailingInstruction == nil {
+		return nil
+	}
+	inst := review.FailingInstruction
+	evidence := fmt.Sprintf("Dockerfile line %d: %s", inst.Line, firstLine(inst.Instruction))
+	if inst.Stage != "" {
+		evidence += " (stage " + inst.Stage + ")"
+	}
+	return []string{evidence}
+}
+
+// firstLine returns s up to its first newline, dropping heredoc bodies.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,171 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable identifier for a failed analysis.
+type ErrorCode string
+
+// Error codes returned in AnalysisResponse.Error.
+const (
+	CodeInvalidRequest      ErrorCode = "INVALID_REQUEST"
+	CodeEmptyLog            ErrorCode = "EMPTY_LOG"
+	CodeLogTooLarge         ErrorCode = "LOG_TOO_LARGE"
+	CodeSensitiveData       ErrorCode = "SENSITIVE_DATA"
+	CodeAITimeout           ErrorCode = "AI_TIMEOUT"
+	CodeAIUnavailable       ErrorCode = "AI_UNAVAILABLE"
+	CodeAIRateLimited       ErrorCode = "AI_RATE_LIMITED"
+	CodeAIQuotaExceeded     ErrorCode = "AI_QUOTA_EXCEEDED"
+	CodeAIAuthFailed        ErrorCode = "AI_AUTH_FAILED"
+	CodeAIInvalidModel      ErrorCode = "AI_INVALID_MODEL"
+	CodeAIContextLength     ErrorCode = "AI_CONTEXT_LENGTH_EXCEEDED"
+	CodeAIContentFiltered   ErrorCode = "AI_CONTENT_FILTERED"
+	CodeAIRequestRejected   ErrorCode = "AI_REQUEST_REJECTED"
+	CodeInvalidAIResponse   ErrorCode = "INVALID_AI_RESPONSE"
+	CodeAIQueueFull         ErrorCode = "AI_QUEUE_FULL"
+	CodeBudgetExceeded      ErrorCode = "BUDGET_EXCEEDED"
+	CodeTenantRateLimited   ErrorCode = "TENANT_RATE_LIMITED"
+	CodeTenantQuotaExceeded ErrorCode = "TENANT_QUOTA_EXCEEDED"
+	CodeInvalidTenant       ErrorCode = "INVALID_TENANT"
+	CodeTenantUnauthorized  ErrorCode = "TENANT_UNAUTHORIZED"
+	CodeTenantForbidden     ErrorCode = "TENANT_FORBIDDEN"
+	CodeRequestCanceled     ErrorCode = "REQUEST_CANCELED"
+	CodeInternal            ErrorCode = "INTERNAL_ERROR"
+)
+
+// ErrorDetail describes why an analysis failed.
+type ErrorDetail struct {
+	// Code is the machine-readable error code.
+	Code ErrorCode `json:"code"`
+
+	// Message is a human-readable description.
+	Message string `json:"message"`
+}
+
+// NewErrorDetail creates an ErrorDetail with an explicit code.
+func NewErrorDetail(code ErrorCode, message string) *ErrorDetail {
+	return &ErrorDetail{Code: code, Message: message}
+}
+
+// ErrorDetailFor classifies err and uses its text as the message.
+func ErrorDetailFor(err error) *ErrorDetail {
+	return &ErrorDetail{Code: CodeFor(err), Message: err.Error()}
+}
+
+// errorCodes maps sentinel errors to codes, most specific first.
+var errorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{ErrInvalidRequest, CodeInvalidRequest},
+	{ErrEmptyLog, CodeEmptyLog},
+	{ErrLogTooLarge, CodeLogTooLarge},
+	{ErrSensitiveData, CodeSensitiveData},
+	{ErrAITimeout, CodeAITimeout},
+	{ErrQuotaExceeded, CodeAIQuotaExceeded},
+	{ErrRateLimited, CodeAIRateLimited},
+	{ErrAIAuth, CodeAIAuthFailed},
+	{ErrInvalidModel, CodeAIInvalidModel},
+	{ErrContextLengthExceeded, CodeAIContextLength},
+	{ErrContentFiltered, CodeAIContentFiltered},
+	{ErrAIRequestRejected, CodeAIRequestRejected},
+	{ErrInvalidAIResponse, CodeInvalidAIResponse},
+	{ErrAIUnavailable, CodeAIUnavailable},
+	{ErrAIQueueFull, CodeAIQueueFull},
+	{ErrBudgetExceeded, CodeBudgetExceeded},
+	{ErrTenantQuotaExceeded, CodeTenantQuotaExceeded},
+	{context.DeadlineExceeded, CodeAITimeout},
+	{context.Canceled, CodeRequestCanceled},
+}
+
+// CodeFor returns the error code for err, or CodeInternal if it is not a
+// known domain error.
+func CodeFor(err error) ErrorCode {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return CodeInternal
+}
+
+// ErrorFor returns the sentinel error classified as code, or nil if there
+// is none. It restores errors whose code crossed a process boundary.
+func ErrorFor(code ErrorCode) error {
+	for _, entry := range errorCodes {
+		if entry.code == code {
+			return entry.err
+		}
+	}
+	return nil
+}
+
+// HTTPStatus returns the HTTP status code for a failed analysis with code c.
+func (c ErrorCode) HTTPStatus() int {
+	switch c {
+	case CodeInvalidRequest, CodeEmptyLog, CodeInvalidTenant:
+		return http.StatusBadRequest
+	case CodeTenantUnauthorized:
+		return http.StatusUnauthorized
+	case CodeTenantForbidden:
+		return http.StatusForbidden
+	case CodeLogTooLarge, CodeAIContextLength:
+		return http.StatusRequestEntityTooLarge
+	case CodeAIContentFiltered, CodeSensitiveData:
+		return http.StatusUnprocessableEntity
+	case CodeAIRateLimited, CodeAIQueueFull, CodeTenantRateLimited, CodeTenantQuotaExceeded:
+		return http.StatusTooManyRequests
+	case CodeAIUnavailable, CodeAIQuotaExceeded, CodeBudgetExceeded:
+		return http.StatusServiceUnavailable
+	case CodeAITimeout:
+		return http.StatusGatewayTimeout
+	case CodeAIAuthFailed, CodeAIInvalidModel, CodeAIRequestRejected, CodeInvalidAIResponse:
+		return http.StatusBadGateway
+	case CodeRequestCanceled:
+		return http.StatusRequestTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Description returns a generic, provider-neutral message for code.
+func (c ErrorCode) Description() string {
+	switch c {
+	case CodeInvalidRequest:
+		return "The request is invalid."
+	case CodeEmptyLog:
+		return "The log is empty."
+	case CodeLogTooLarge:
+		return "The log is too large."
+	case CodeSensitiveData:
+		return "The log contains sensitive data and was not analyzed."
+	case CodeAITimeout:
+		return "The analysis timed out."
+	case CodeAIRateLimited, CodeAIQueueFull:
+		return "Too many analyses are in progress; retry later."
+	case CodeTenantRateLimited:
+		return "The request rate limit was exceeded; retry later."
+	case CodeTenantQuotaExceeded:
+		return "The monthly analysis quota is exhausted."
+	case CodeInvalidTenant:
+		return "The tenant ID is invalid."
+	case CodeTenantUnauthorized:
+		return "A valid API key is required."
+	case CodeTenantForbidden:
+		return "The API key does not belong to the requested tenant."
+	case CodeAIUnavailable, CodeAIQuotaExceeded, CodeBudgetExceeded:
+		return "Analysis is temporarily unavailable."
+	case CodeAIContextLength:
+		return "The log is too large to analyze."
+	case CodeAIContentFiltered:
+		return "The log could not be analyzed because of its content."
+	case CodeRequestCanceled:
+		return "The request was canceled."
+	default:
+		return "The analysis failed."
+	}
+}
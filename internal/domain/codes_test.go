@@ -0,0 +1,68 @@
+// Package domain provides unit tests for error code classification.
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCodeFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   ErrorCode
+		wantStatus int
+	}{
+		{"empty log", ErrEmptyLog, CodeEmptyLog, http.StatusBadRequest},
+		{"wrapped timeout", WrapError("ai_timeout", ErrAITimeout, true), CodeAITimeout, http.StatusGatewayTimeout},
+		{"deadline", context.DeadlineExceeded, CodeAITimeout, http.StatusGatewayTimeout},
+		{
+			"provider quota",
+			WrapError("quota_exceeded", &ProviderError{Provider: "openai", Kind: ErrQuotaExceeded}, false),
+			CodeAIQuotaExceeded,
+			http.StatusServiceUnavailable,
+		},
+		{"sensitive data", ErrSensitiveData, CodeSensitiveData, http.StatusUnprocessableEntity},
+		{"queue full", WrapError("ai_queue", ErrAIQueueFull, true), CodeAIQueueFull, http.StatusTooManyRequests},
+		{"tenant quota", ErrTenantQuotaExceeded, CodeTenantQuotaExceeded, http.StatusTooManyRequests},
+		{"unknown", errors.New("boom"), CodeInternal, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := CodeFor(tt.err)
+			if code != tt.wantCode {
+				t.Errorf("CodeFor() = %s, want %s", code, tt.wantCode)
+			}
+			if status := code.HTTPStatus(); status != tt.wantStatus {
+				t.Errorf("HTTPStatus() = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestErrorFor(t *testing.T) {
+	tests := []struct {
+		code ErrorCode
+		want error
+	}{
+		{CodeAIRateLimited, ErrRateLimited},
+		{CodeAITimeout, ErrAITimeout},
+		{CodeInvalidAIResponse, ErrInvalidAIResponse},
+		{CodeInternal, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			err := ErrorFor(tt.code)
+			if err != tt.want {
+				t.Fatalf("ErrorFor() = %v, want %v", err, tt.want)
+			}
+			if err != nil && CodeFor(err) != tt.code {
+				t.Errorf("CodeFor(ErrorFor(%s)) = %s", tt.code, CodeFor(err))
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import "strings"
+
+// Command is a shell command suggested by an analysis.
+type Command struct {
+	// Command is the command line, without a shell prompt. Values the log
+	// does not reveal are left as <placeholders>.
+	Command string `json:"command"`
+
+	// Description says what the command does.
+	Description string `json:"description,omitempty"`
+
+	// Safety classifies what running the command does to the system. Set
+	// by the service layer, never by the AI.
+	Safety Safety `json:"safety,omitempty"`
+}
+
+// Safety classifies a remediation step by its effect on the system.
+type Safety string
+
+const (
+	// SafetyReadOnly steps only inspect state (kubectl get, docker logs).
+	SafetyReadOnly Safety = "read_only"
+
+	// SafetyModifying steps change state in a recoverable way (restarts,
+	// installs, config edits).
+	SafetyModifying Safety = "modifying"
+
+	// SafetyDestructive steps delete data or resources irrecoverably
+	// (docker system prune -a, kubectl delete, terraform destroy).
+	SafetyDestructive Safety = "destructive"
+)
+
+// shellPrompts are prompt prefixes models copy from documentation.
+var shellPrompts = []string{"$ ", "# ", "> ", "PS> "}
+
+// NormalizeCommands returns commands cleaned up for copying: surrounding
+// whitespace, Markdown backticks and a leading shell prompt are removed,
+// and empty and duplicate commands are dropped.
+func NormalizeCommands(commands []Command) []Command {
+	if commands == nil {
+		return nil
+	}
+	normalized := make([]Command, 0, len(commands))
+	seen := make(map[string]bool, len(commands))
+	for _, c := range commands {
+		line := strings.TrimSpace(c.Command)
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "```"), "```"))
+		line = strings.TrimSpace(strings.Trim(line, "`"))
+		for _, prompt := range shellPrompts {
+			line = strings.TrimPrefix(line, prompt)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		normalized = append(normalized, Command{Command: line, Description: strings.TrimSpace(c.Description), Safety: c.Safety})
+	}
+	return normalized
+}
+
+// CommandLines returns the command lines of commands.
+func CommandLines(commands []Command) []string {
+	if commands == nil {
+		return nil
+	}
+	lines := make([]string, len(commands))
+	for i, c := range commands {
+		lines[i] = c.Command
+	}
+	return lines
+}
@@ -0,0 +1,36 @@
+// Package domain provides unit tests for suggested commands.
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []Command
+		want     []Command
+	}{
+		{"nil", nil, nil},
+		{
+			"prompt and backticks",
+			[]Command{{Command: "  $ npm ci  ", Description: " Reinstall "}, {Command: "`go mod tidy`"}, {Command: "```\nterraform init -upgrade\n```"}},
+			[]Command{{Command: "npm ci", Description: "Reinstall"}, {Command: "go mod tidy"}, {Command: "terraform init -upgrade"}},
+		},
+		{
+			"empty and duplicate dropped",
+			[]Command{{Command: "kubectl logs <pod> --previous"}, {Command: " "}, {Command: "$ kubectl logs <pod> --previous", Description: "again"}},
+			[]Command{{Command: "kubectl logs <pod> --previous"}},
+		},
+		{"root prompt", []Command{{Command: "# systemctl start docker"}}, []Command{{Command: "systemctl start docker"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeCommands(tt.commands); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeCommands() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
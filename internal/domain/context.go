@@ -0,0 +1,46 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// labelName matches valid log stream label names.
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ContextQuery selects log lines around an incident in a log store.
+type ContextQuery struct {
+	// Labels select the log streams, e.g. {"app": "api", "namespace": "prod"}.
+	Labels map[string]string `json:"labels"`
+
+	// Start and End bound the time range. End defaults to now and Start to
+	// a configured range before End.
+	Start time.Time `json:"start,omitempty"`
+	End   time.Time `json:"end,omitempty"`
+
+	// Limit is the maximum number of lines, the latest before End. Zero
+	// uses the configured maximum.
+	Limit int `json:"limit,omitempty"`
+}
+
+// Validate checks the query for missing labels, invalid label names and an
+// inverted time range.
+func (q *ContextQuery) Validate() error {
+	if len(q.Labels) == 0 {
+		return fmt.Errorf("%w: context.labels is required", ErrInvalidRequest)
+	}
+	for name := range q.Labels {
+		if !labelName.MatchString(name) {
+			return fmt.Errorf("%w: invalid context label name %q", ErrInvalidRequest, name)
+		}
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && !q.Start.Before(q.End) {
+		return fmt.Errorf("%w: context.start must be before context.end", ErrInvalidRequest)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("%w: context.limit must not be negative", ErrInvalidRequest)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import "time"
+
+// ChatRole is the author of a conversation message.
+type ChatRole string
+
+const (
+	// ChatRoleUser marks messages written by the developer.
+	ChatRoleUser ChatRole = "user"
+
+	// ChatRoleAssistant marks replies written by the AI.
+	ChatRoleAssistant ChatRole = "assistant"
+)
+
+// ChatMessage is one turn of a conversation.
+type ChatMessage struct {
+	Role      ChatRole  `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Conversation is a multi-step troubleshooting session: the logs analyzed
+// so far, their results, and the follow-up messages exchanged about them.
+// The AI sees all of it when answering the next message.
+type Conversation struct {
+	// ID uniquely identifies the conversation. Assigned by the store if
+	// empty.
+	ID string `json:"id"`
+
+	// CreatedAt is when the conversation started.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the conversation was last saved.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// ExpiresAt is when the store forgets the conversation. Set by the
+	// store on every save.
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Logs are the sanitized logs analyzed in the conversation, oldest
+	// first. Raw logs are never stored.
+	Logs []string `json:"logs,omitempty"`
+
+	// Results are the analyses of Logs, in the same order.
+	Results []*AnalysisResult `json:"results,omitempty"`
+
+	// Messages are the follow-up turns, oldest first.
+	Messages []ChatMessage `json:"messages,omitempty"`
+}
+
+// AddAnalysis appends an analyzed log and its result.
+func (c *Conversation) AddAnalysis(log string, result *AnalysisResult) {
+	c.Logs = append(c.Logs, log)
+	c.Results = append(c.Results, result)
+}
+
+// AddMessage appends a message.
+func (c *Conversation) AddMessage(role ChatRole, content string) {
+	c.Messages = append(c.Messages, ChatMessage{Role: role, Content: content, CreatedAt: time.Now()})
+}
+
+// Expired reports whether the conversation expired at now.
+func (c *Conversation) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
+}
+
+// ChatReply is the AI's answer to a conversation message.
+type ChatReply struct {
+	// Content is the answer text.
+	Content string `json:"content"`
+
+	// Usage reports the tokens consumed, if the provider returns them.
+	Usage *TokenUsage `json:"usage,omitempty"`
+}
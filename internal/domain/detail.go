@@ -0,0 +1,58 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import "strings"
+
+// DetailLevel controls how much an analysis says.
+type DetailLevel string
+
+const (
+	// DetailBrief is a one-line root cause and at most two actions, for
+	// chat-ops and notifications.
+	DetailBrief DetailLevel = "brief"
+
+	// DetailStandard is the default analysis.
+	DetailStandard DetailLevel = "standard"
+
+	// DetailDeep adds an extended explanation.
+	DetailDeep DetailLevel = "deep"
+)
+
+// BriefMaxActions is the number of suggested actions and commands kept by
+// brief analyses.
+const BriefMaxActions = 2
+
+// IsValid checks if the detail level is one of the allowed values.
+func (d DetailLevel) IsValid() bool {
+	switch d {
+	case DetailBrief, DetailStandard, DetailDeep:
+		return true
+	default:
+		return false
+	}
+}
+
+// ForDetail returns the result trimmed to level. Brief results keep the
+// first line of the root cause and BriefMaxActions actions and commands;
+// other levels return r unchanged. r itself is never modified because rule
+// results and cached results are shared.
+func (r *AnalysisResult) ForDetail(level DetailLevel) *AnalysisResult {
+	if r == nil || level != DetailBrief {
+		return r
+	}
+
+	brief := *r
+	if line, _, found := strings.Cut(strings.TrimSpace(brief.RootCause), "\n"); found {
+		brief.RootCause = strings.TrimSpace(line)
+	}
+	if len(brief.SuggestedActions) > BriefMaxActions {
+		brief.SuggestedActions = brief.SuggestedActions[:BriefMaxActions]
+	}
+	if len(brief.Commands) > BriefMaxActions {
+		brief.Commands = brief.Commands[:BriefMaxActions]
+	}
+	brief.PreventionTips = nil
+	brief.Explanation = ""
+	brief.References = nil
+	return &brief
+}
@@ -0,0 +1,51 @@
+// Package domain provides unit tests for detail levels.
+package domain
+
+import "testing"
+
+func TestAnalysisResult_ForDetail(t *testing.T) {
+	result := &AnalysisResult{
+		ErrorType:        "dependency_error",
+		Severity:         SeverityHigh,
+		RootCause:        "Module not found.\nThe lockfile references a removed version.",
+		SuggestedActions: []string{"Run npm install", "Update the lockfile", "Clear the cache"},
+		Commands:         []Command{{Command: "npm install"}, {Command: "npm update"}, {Command: "npm cache clean --force"}},
+		PreventionTips:   []string{"Pin versions"},
+		Explanation:      "Long explanation",
+		References:       []Reference{{Title: "npm Docs", URL: "https://docs.npmjs.com"}},
+	}
+
+	tests := []struct {
+		name        string
+		level       DetailLevel
+		wantCause   string
+		wantActions int
+		wantTips    int
+	}{
+		{"standard unchanged", DetailStandard, result.RootCause, 3, 1},
+		{"deep unchanged", DetailDeep, result.RootCause, 3, 1},
+		{"brief trimmed", DetailBrief, "Module not found.", BriefMaxActions, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := result.ForDetail(tt.level)
+			if got.RootCause != tt.wantCause {
+				t.Errorf("RootCause = %q, want %q", got.RootCause, tt.wantCause)
+			}
+			if len(got.SuggestedActions) != tt.wantActions {
+				t.Errorf("SuggestedActions = %d, want %d", len(got.SuggestedActions), tt.wantActions)
+			}
+			if len(got.Commands) != tt.wantActions {
+				t.Errorf("Commands = %d, want %d", len(got.Commands), tt.wantActions)
+			}
+			if len(got.PreventionTips) != tt.wantTips {
+				t.Errorf("PreventionTips = %d, want %d", len(got.PreventionTips), tt.wantTips)
+			}
+		})
+	}
+
+	if len(result.SuggestedActions) != 3 || result.Explanation == "" {
+		t.Error("ForDetail must not modify the original result")
+	}
+}
@@ -0,0 +1,132 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import "strings"
+
+// FieldChange records a changed scalar field.
+type FieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SeverityChange records a changed severity and its direction.
+type SeverityChange struct {
+	From Severity `json:"from"`
+	To   Severity `json:"to"`
+
+	// Direction is "escalated" or "deescalated".
+	Direction string `json:"direction"`
+}
+
+// ListChange records items added to and removed from a list field.
+type ListChange struct {
+	Added     []string `json:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// AnalysisDiff describes how one analysis differs from another. Only changed
+// fields are set.
+type AnalysisDiff struct {
+	FromID string `json:"from_id"`
+	ToID   string `json:"to_id"`
+
+	// Identical is true when the results are equivalent.
+	Identical bool `json:"identical"`
+
+	Source           *FieldChange    `json:"source,omitempty"`
+	ErrorType        *FieldChange    `json:"error_type,omitempty"`
+	Severity         *SeverityChange `json:"severity,omitempty"`
+	RootCause        *FieldChange    `json:"root_cause,omitempty"`
+	SuggestedActions *ListChange     `json:"suggested_actions,omitempty"`
+	Commands         *ListChange     `json:"commands,omitempty"`
+	PreventionTips   *ListChange     `json:"prevention_tips,omitempty"`
+	Evidence         *ListChange     `json:"evidence,omitempty"`
+}
+
+// DiffAnalyses compares two stored analyses. List items are compared
+// ignoring case and surrounding whitespace.
+func DiffAnalyses(from, to *AnalysisRecord) *AnalysisDiff {
+	diff := &AnalysisDiff{FromID: from.ID, ToID: to.ID}
+
+	a, b := from.Result, to.Result
+	if a == nil {
+		a = &AnalysisResult{}
+	}
+	if b == nil {
+		b = &AnalysisResult{}
+	}
+
+	diff.Source = diffField(from.Source, to.Source)
+	diff.ErrorType = diffField(a.ErrorType, b.ErrorType)
+	diff.RootCause = diffField(a.RootCause, b.RootCause)
+	if a.Severity != b.Severity {
+		diff.Severity = &SeverityChange{From: a.Severity, To: b.Severity, Direction: "deescalated"}
+		if severityOrder(b.Severity) > severityOrder(a.Severity) {
+			diff.Severity.Direction = "escalated"
+		}
+	}
+	diff.SuggestedActions = diffList(a.SuggestedActions, b.SuggestedActions)
+	diff.Commands = diffList(CommandLines(a.Commands), CommandLines(b.Commands))
+	diff.PreventionTips = diffList(a.PreventionTips, b.PreventionTips)
+	diff.Evidence = diffList(a.Evidence, b.Evidence)
+
+	diff.Identical = diff.ErrorType == nil && diff.RootCause == nil && diff.Severity == nil &&
+		diff.SuggestedActions == nil && diff.Commands == nil && diff.PreventionTips == nil && diff.Evidence == nil
+
+	return diff
+}
+
+func diffField(from, to string) *FieldChange {
+	if from == to {
+		return nil
+	}
+	return &FieldChange{From: from, To: to}
+}
+
+// diffList returns nil when both lists contain the same items.
+func diffList(from, to []string) *ListChange {
+	normalize := func(s string) string { return strings.ToLower(strings.TrimSpace(s)) }
+
+	inFrom := make(map[string]bool, len(from))
+	for _, item := range from {
+		inFrom[normalize(item)] = true
+	}
+	inTo := make(map[string]bool, len(to))
+	for _, item := range to {
+		inTo[normalize(item)] = true
+	}
+
+	change := &ListChange{}
+	for _, item := range to {
+		if !inFrom[normalize(item)] {
+			change.Added = append(change.Added, item)
+		} else {
+			change.Unchanged++
+		}
+	}
+	for _, item := range from {
+		if !inTo[normalize(item)] {
+			change.Removed = append(change.Removed, item)
+		}
+	}
+
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return nil
+	}
+	return change
+}
+
+// severityOrder ranks severities for comparison. Unknown values rank lowest.
+func severityOrder(s Severity) int {
+	switch s {
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	default:
+		return 0
+	}
+}
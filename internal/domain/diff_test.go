@@ -0,0 +1,48 @@
+// Package domain provides unit tests for analysis diffing.
+package domain
+
+import "testing"
+
+func TestDiffAnalyses(t *testing.T) {
+	from := &AnalysisRecord{
+		ID:     "a",
+		Source: "ai",
+		Result: &AnalysisResult{
+			ErrorType:        "out_of_memory",
+			Severity:         SeverityMedium,
+			RootCause:        "Container exceeded its memory limit",
+			SuggestedActions: []string{"Increase memory limit", "Check for leaks"},
+		},
+	}
+	to := &AnalysisRecord{
+		ID:     "b",
+		Source: "ai",
+		Result: &AnalysisResult{
+			ErrorType:        "out_of_memory",
+			Severity:         SeverityHigh,
+			RootCause:        "Container exceeded its memory limit",
+			SuggestedActions: []string{"increase memory limit ", "Profile heap usage"},
+		},
+	}
+
+	diff := DiffAnalyses(from, to)
+
+	if diff.Identical {
+		t.Error("expected differences")
+	}
+	if diff.ErrorType != nil || diff.RootCause != nil || diff.Source != nil {
+		t.Errorf("unexpected field changes: %+v", diff)
+	}
+	if diff.Severity == nil || diff.Severity.Direction != "escalated" {
+		t.Errorf("severity change = %+v, want escalated", diff.Severity)
+	}
+	actions := diff.SuggestedActions
+	if actions == nil || len(actions.Added) != 1 || actions.Added[0] != "Profile heap usage" ||
+		len(actions.Removed) != 1 || actions.Removed[0] != "Check for leaks" || actions.Unchanged != 1 {
+		t.Errorf("suggested actions change = %+v", actions)
+	}
+
+	if same := DiffAnalyses(from, from); !same.Identical {
+		t.Errorf("diff with itself should be identical: %+v", same)
+	}
+}
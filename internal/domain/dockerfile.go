@@ -0,0 +1,43 @@
+// Package domain contains the core domain models and types.
+package domain
+
+// DockerfileReview is the static review of a Dockerfile returned by the
+// Dockerfile endpoint next to the analysis.
+type DockerfileReview struct {
+	// Findings are best-practice violations, in Dockerfile order.
+	Findings []DockerfileFinding `json:"findings"`
+
+	// FailingInstruction is the instruction the build log shows failing,
+	// if the request had a build log and it names one.
+	FailingInstruction *DockerfileInstruction `json:"failing_instruction,omitempty"`
+}
+
+// DockerfileFinding is a best-practice violation in a Dockerfile.
+type DockerfileFinding struct {
+	// Rule identifies the check (e.g. "pin_base_image").
+	Rule string `json:"rule"`
+
+	// Severity is how much the violation matters: Low for hygiene, Medium
+	// for slow or fragile builds, High for security problems.
+	Severity Severity `json:"severity"`
+
+	// Line is the 1-based line where the instruction starts.
+	Line int `json:"line"`
+
+	// Instruction is the offending instruction, continuation lines joined.
+	Instruction string `json:"instruction"`
+
+	Message string `json:"message"`
+}
+
+// DockerfileInstruction is an instruction of a Dockerfile.
+type DockerfileInstruction struct {
+	// Line is the 1-based line where the instruction starts.
+	Line int `json:"line"`
+
+	// Instruction is the instruction text, continuation lines joined.
+	Instruction string `json:"instruction"`
+
+	// Stage names the build stage (FROM ... AS name), if any.
+	Stage string `json:"stage,omitempty"`
+}
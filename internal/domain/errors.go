@@ -4,6 +4,7 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Sentinel errors for common failure cases.
@@ -14,6 +15,9 @@ var (
 	// ErrLogTooLarge indicates the log exceeds the maximum allowed size.
 	ErrLogTooLarge = errors.New("log content exceeds maximum size")
 
+	// ErrInvalidRequest indicates the analysis request is malformed.
+	ErrInvalidRequest = errors.New("invalid analysis request")
+
 	// ErrAITimeout indicates the AI service did not respond in time.
 	ErrAITimeout = errors.New("AI service timeout")
 
@@ -26,10 +30,91 @@ var (
 	// ErrRateLimited indicates too many requests were made.
 	ErrRateLimited = errors.New("rate limit exceeded")
 
+	// ErrQuotaExceeded indicates the provider account is out of quota or
+	// credit. Unlike ErrRateLimited, retrying soon will not help.
+	ErrQuotaExceeded = errors.New("AI provider quota exceeded")
+
+	// ErrAIAuth indicates the provider rejected the API credentials.
+	ErrAIAuth = errors.New("AI provider authentication failed")
+
+	// ErrInvalidModel indicates the configured model does not exist or is not
+	// available to the account.
+	ErrInvalidModel = errors.New("AI model not found or unavailable")
+
+	// ErrContextLengthExceeded indicates the prompt exceeds the model's
+	// context window.
+	ErrContextLengthExceeded = errors.New("AI context length exceeded")
+
+	// ErrContentFiltered indicates the provider's safety or content filter
+	// blocked the prompt or response.
+	ErrContentFiltered = errors.New("AI content filtered")
+
+	// ErrAIRequestRejected indicates the provider rejected the request for a
+	// reason not covered by a more specific error.
+	ErrAIRequestRejected = errors.New("AI request rejected by provider")
+
+	// ErrAIQueueFull indicates too many AI requests are already in flight or queued.
+	ErrAIQueueFull = errors.New("AI request queue is full")
+
+	// ErrBudgetExceeded indicates the AI token budget has been exhausted.
+	ErrBudgetExceeded = errors.New("AI token budget exceeded")
+
+	// ErrTenantQuotaExceeded indicates the tenant's monthly quota has been
+	// exhausted.
+	ErrTenantQuotaExceeded = errors.New("tenant quota exceeded")
+
+	// ErrSensitiveData indicates the log contains data that must not leave
+	// the service, such as a private key.
+	ErrSensitiveData = errors.New("log contains sensitive data")
+
 	// ErrInvalidConfig indicates invalid configuration.
 	ErrInvalidConfig = errors.New("invalid configuration")
 )
 
+// ProviderError describes a failure reported by an AI provider. Kind is one
+// of the sentinel errors above, so callers can use errors.Is on the result.
+type ProviderError struct {
+	// Provider is the AI provider name (e.g. "openai", "gemini").
+	Provider string
+
+	// StatusCode is the HTTP status, or 0 for errors reported in a 200 response.
+	StatusCode int
+
+	// Code is the provider's own error code or status (e.g. "insufficient_quota").
+	Code string
+
+	// Message is the provider's error message.
+	Message string
+
+	// Kind classifies the failure.
+	Kind error
+
+	// RetryAfter is the delay the provider asked for before retrying
+	// (Retry-After header), or zero.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *ProviderError) Error() string {
+	msg := fmt.Sprintf("%v (%s", e.Kind, e.Provider)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" status %d", e.StatusCode)
+	}
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	msg += ")"
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap returns the failure kind.
+func (e *ProviderError) Unwrap() error {
+	return e.Kind
+}
+
 // AnalysisError wraps an error with additional context.
 type AnalysisError struct {
 	// Op is the operation that failed.
@@ -0,0 +1,36 @@
+// Package domain contains the core domain models and types.
+package domain
+
+// AnalysisMode selects a specialist analysis.
+type AnalysisMode string
+
+const (
+	// AnalysisModeIaC specializes the analysis in infrastructure as code
+	// (Terraform, Pulumi, CloudFormation): the AI uses the IaC prompt and
+	// the result names the offending resource addresses as evidence.
+	AnalysisModeIaC AnalysisMode = "iac"
+
+	// AnalysisModeKubernetes specializes the analysis in Kubernetes
+	// workloads: the AI uses the Kubernetes prompt. The Kubernetes endpoint
+	// sets it.
+	AnalysisModeKubernetes AnalysisMode = "k8s"
+
+	// AnalysisModeDocker specializes the analysis in Docker builds: the AI
+	// uses the Docker prompt. The Dockerfile endpoint sets it.
+	AnalysisModeDocker AnalysisMode = "docker"
+
+	// AnalysisModeCI specializes the analysis in CI pipelines (GitHub
+	// Actions, GitLab CI): the AI uses the CI prompt. The pipeline endpoint
+	// sets it.
+	AnalysisModeCI AnalysisMode = "ci"
+)
+
+// IsValid checks if the mode is one of the allowed values.
+func (m AnalysisMode) IsValid() bool {
+	switch m {
+	case AnalysisModeIaC, AnalysisModeKubernetes, AnalysisModeDocker, AnalysisModeCI:
+		return true
+	default:
+		return false
+	}
+}
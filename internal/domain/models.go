@@ -3,7 +3,10 @@
 // of any infrastructure concerns.
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Severity represents the severity level of an identified issue.
 type Severity string
@@ -24,10 +27,140 @@ func (s Severity) IsValid() bool {
 	}
 }
 
+// Rank orders severities for threshold comparison: High ranks highest and
+// unknown severities rank lowest.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NormalizeSeverity maps a case-insensitive severity ("high", "HIGH") to its
+// canonical form. Unknown values are returned unchanged.
+func NormalizeSeverity(s Severity) Severity {
+	for _, valid := range []Severity{SeverityLow, SeverityMedium, SeverityHigh} {
+		if strings.EqualFold(string(s), string(valid)) {
+			return valid
+		}
+	}
+	return s
+}
+
 // AnalysisRequest represents an incoming log analysis request.
 type AnalysisRequest struct {
 	// Log is the raw log content to be analyzed.
-	Log string `json:"log" binding:"required"`
+	Log string `json:"log"`
+
+	// Sections are additional named logs from the same incident (e.g. build
+	// log, pod events, application log) analyzed together with Log.
+	Sections []LogSection `json:"sections,omitempty"`
+
+	// Language is the language for root_cause, suggested_actions and
+	// prevention_tips (e.g. "Vietnamese", "ja"). Empty uses the server default.
+	Language string `json:"language,omitempty"`
+
+	// Metadata describes where the log came from. It is given to the AI as
+	// context and can be used by rules as match conditions.
+	Metadata *LogMetadata `json:"metadata,omitempty"`
+
+	// Detail selects how much the analysis says: brief, standard (default)
+	// or deep.
+	Detail DetailLevel `json:"detail,omitempty"`
+
+	// Mode selects a specialist analysis: "iac" for Terraform, Pulumi and
+	// CloudFormation logs, "k8s" for Kubernetes workloads, "docker" for
+	// Docker builds or "ci" for CI pipelines. Empty is the general analysis.
+	Mode AnalysisMode `json:"mode,omitempty"`
+
+	// Explain adds an Explanation of how the result was produced to the
+	// response (also set by the analyze endpoint's ?explain=true).
+	Explain bool `json:"explain,omitempty"`
+
+	// Context selects surrounding log lines to fetch from a log store (Loki)
+	// and analyze together with Log, for when only the final error line was
+	// submitted. Ignored when no log store is configured.
+	Context *ContextQuery `json:"context,omitempty"`
+
+	// CallbackURL makes the analysis asynchronous: the request is accepted
+	// immediately and the AnalysisResponse is POSTed to this URL, signed,
+	// when the analysis finishes.
+	CallbackURL string `json:"callback_url,omitempty"`
+
+	// TimeoutMS bounds the analysis in milliseconds, capped by the server's
+	// MAX_REQUEST_TIMEOUT; AI retries are skipped when they cannot finish in
+	// time. Zero applies no request-specific timeout.
+	TimeoutMS int `json:"timeout_ms,omitempty"`
+
+	// Ticket overrides automatic issue creation for this request: true opens
+	// (or updates) a ticket whatever the severity and returns it in the
+	// response, false never files one. Unset follows the server settings.
+	Ticket *bool `json:"ticket,omitempty"`
+}
+
+// LogMetadata is optional structured context about the log's origin.
+type LogMetadata struct {
+	// Pipeline and Stage name the CI/CD pipeline and step that produced the log.
+	Pipeline string `json:"pipeline,omitempty"`
+	Stage    string `json:"stage,omitempty"`
+
+	// Repository and Branch identify the source being built.
+	Repository string `json:"repository,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+
+	// Namespace is the Kubernetes namespace or deployment environment the
+	// workload runs in (e.g. "prod").
+	Namespace string `json:"namespace,omitempty"`
+
+	// RunnerOS and Arch describe the build machine (e.g. "windows", "arm64").
+	RunnerOS string `json:"runner_os,omitempty"`
+	Arch     string `json:"arch,omitempty"`
+
+	// ToolVersions maps tool names to versions (e.g. "go": "1.22.1").
+	ToolVersions map[string]string `json:"tool_versions,omitempty"`
+}
+
+// Get returns a metadata field by its JSON name. Tool versions are addressed
+// as "tool:<name>". Returns "" for unknown or unset fields and nil metadata.
+func (m *LogMetadata) Get(field string) string {
+	if m == nil {
+		return ""
+	}
+	switch field {
+	case "pipeline":
+		return m.Pipeline
+	case "stage":
+		return m.Stage
+	case "repository":
+		return m.Repository
+	case "branch":
+		return m.Branch
+	case "namespace":
+		return m.Namespace
+	case "runner_os":
+		return m.RunnerOS
+	case "arch":
+		return m.Arch
+	}
+	if tool, ok := strings.CutPrefix(field, "tool:"); ok {
+		return m.ToolVersions[tool]
+	}
+	return ""
+}
+
+// LogSection is a named log captured from one component of an incident.
+type LogSection struct {
+	// Name identifies the source (e.g. "build", "kubelet_events").
+	Name string `json:"name"`
+
+	// Content is the raw log content.
+	Content string `json:"content"`
 }
 
 // AnalysisResult represents the structured output of log analysis.
@@ -42,29 +175,289 @@ type AnalysisResult struct {
 	// RootCause describes the underlying reason for the issue.
 	RootCause string `json:"root_cause"`
 
+	// Confidence is how sure the AI is of the root cause (0-1), as reported
+	// by the model. Rule results leave it unset.
+	Confidence float64 `json:"confidence,omitempty"`
+
 	// SuggestedActions lists actionable remediation steps.
 	SuggestedActions []string `json:"suggested_actions"`
 
+	// ActionSafety classifies each of SuggestedActions, index for index.
+	// Set by the service layer, never by the AI.
+	ActionSafety []Safety `json:"action_safety,omitempty"`
+
+	// Commands lists copy-pasteable shell commands that diagnose or fix
+	// the failure, separate from the prose of SuggestedActions.
+	Commands []Command `json:"commands,omitempty"`
+
 	// PreventionTips lists ways to prevent this issue in the future.
 	PreventionTips []string `json:"prevention_tips"`
+
+	// Evidence lists concrete artifacts from the input that support the
+	// analysis, such as the offending Terraform resource addresses.
+	Evidence []string `json:"evidence,omitempty"`
+
+	// Explanation is an extended walk-through of the failure. Only set for
+	// deep analyses.
+	Explanation string `json:"explanation,omitempty"`
+
+	// References lists documentation links relevant to the failure: curated
+	// links for rule results, allowlisted links for AI results.
+	References []Reference `json:"references,omitempty"`
+
+	// Consensus reports how a second model's analysis compared with this
+	// one. Only set in consensus mode.
+	Consensus *Consensus `json:"consensus,omitempty"`
+
+	// Usage is the token usage reported by the AI provider. It is moved into
+	// the response metadata by the service layer and never serialized here.
+	Usage *TokenUsage `json:"-"`
+}
+
+// Consensus compares the analyses of two models of the same log.
+type Consensus struct {
+	// Models are the models whose analyses were compared, primary first.
+	Models []string `json:"models"`
+
+	// Verified is false when only one model answered; Agreement and the
+	// field lists are then empty.
+	Verified bool `json:"verified"`
+
+	// Agreement is the fraction (0-1) of compared fields the models agreed
+	// on. Results below 1 deserve a human look before paging anyone.
+	Agreement float64 `json:"agreement"`
+
+	// Agreed lists the fields both models agreed on.
+	Agreed []string `json:"agreed,omitempty"`
+
+	// Disagreements lists the fields they disagreed on with each answer.
+	// The result keeps the primary model's value.
+	Disagreements []ConsensusDisagreement `json:"disagreements,omitempty"`
+}
+
+// ConsensusDisagreement is a field two models answered differently.
+type ConsensusDisagreement struct {
+	Field     string `json:"field"`
+	Primary   string `json:"primary"`
+	Secondary string `json:"secondary"`
+}
+
+// TokenUsage records the tokens consumed by a single AI call.
+type TokenUsage struct {
+	// Model is the model that served the call. Empty when the client does
+	// not report it; the meter then prices the call at the default model.
+	Model string `json:"model,omitempty"`
+
+	// PromptTokens is the number of input tokens.
+	PromptTokens int `json:"prompt_tokens"`
+
+	// CompletionTokens is the number of output tokens (including reasoning).
+	CompletionTokens int `json:"completion_tokens"`
+
+	// TotalTokens is the total billed tokens.
+	TotalTokens int `json:"total_tokens"`
+
+	// Calls holds the usage of each call when several models served one
+	// result (a consensus, an escalation after a failed cheap attempt), so
+	// each is priced at its own model. The tokens above are their sum.
+	Calls []TokenUsage `json:"-"`
+}
+
+// ResponseMetadata carries auxiliary information about how a result was produced.
+type ResponseMetadata struct {
+	// Usage is the AI token usage, if the AI was called.
+	Usage *TokenUsage `json:"usage,omitempty"`
+
+	// EstimatedCostUSD is the estimated cost of the AI call from the model price table.
+	EstimatedCostUSD float64 `json:"estimated_cost_usd,omitempty"`
+
+	// Degraded indicates the AI was skipped (e.g. token budget exhausted).
+	Degraded bool `json:"degraded,omitempty"`
+
+	// Cached indicates the result was served from the fingerprint cache.
+	Cached bool `json:"cached,omitempty"`
+
+	// Reduction describes how the log was shrunk after the provider reported
+	// a context-length error. Nil if the full log was analyzed.
+	Reduction *LogReduction `json:"reduction,omitempty"`
+
+	// PromptVersion identifies the prompt the AI was given, so results can be
+	// invalidated when the prompt changes.
+	PromptVersion string `json:"prompt_version,omitempty"`
+
+	// PromptDomain names the specialized system prompt the AI was given
+	// (e.g. "kubernetes"); empty for the generic prompt.
+	PromptDomain string `json:"prompt_domain,omitempty"`
+
+	// Variant names the A/B experiment variant that produced the result.
+	Variant string `json:"variant,omitempty"`
+
+	// RuleIDs are the rules that were merged into or hinted to the AI.
+	RuleIDs []string `json:"rule_ids,omitempty"`
+
+	// ExampleIDs are the stored worked examples shown to the AI.
+	ExampleIDs []string `json:"example_ids,omitempty"`
+
+	// ContextLines is the number of lines fetched from the log store for
+	// AnalysisRequest.Context.
+	ContextLines int `json:"context_lines,omitempty"`
+
+	// SeverityOverride names the severity policy override applied to the
+	// result; OriginalSeverity is the severity it replaced.
+	SeverityOverride string   `json:"severity_override,omitempty"`
+	OriginalSeverity Severity `json:"original_severity,omitempty"`
+
+	// WithheldDestructive is the number of destructive suggested actions
+	// and commands removed from the result by policy.
+	WithheldDestructive int `json:"withheld_destructive,omitempty"`
+
+	// Redactions counts the sensitive values masked in the log by category
+	// (api_key, password, email, ip, jwt, private_key, custom).
+	Redactions map[string]int `json:"redactions,omitempty"`
+}
+
+// LogReduction records an automatic log reduction applied before analysis.
+type LogReduction struct {
+	// Level is the reduction level applied (1 = mildest).
+	Level int `json:"level"`
+
+	// OriginalBytes and ReducedBytes are the log sizes before and after.
+	OriginalBytes int `json:"original_bytes"`
+	ReducedBytes  int `json:"reduced_bytes"`
+
+	// ContextLines is the number of lines kept around each error line.
+	ContextLines int `json:"context_lines"`
 }
 
 // AnalysisResponse wraps the analysis result with metadata.
 type AnalysisResponse struct {
+	// ID identifies the stored analysis, for history lookups and feedback.
+	ID string `json:"id,omitempty"`
+
+	// RequestID identifies the request the response answers, as in the
+	// X-Request-ID header.
+	RequestID string `json:"request_id,omitempty"`
+
 	// Success indicates whether the analysis completed successfully.
 	Success bool `json:"success"`
 
 	// Result contains the analysis result if successful.
 	Result *AnalysisResult `json:"result,omitempty"`
 
-	// Error contains error details if the analysis failed.
-	Error string `json:"error,omitempty"`
+	// Error contains the error code and message if the analysis failed.
+	Error *ErrorDetail `json:"error,omitempty"`
 
 	// Source indicates whether the result came from rules or AI.
 	Source string `json:"source,omitempty"`
 
 	// ProcessedAt is the timestamp when the analysis was completed.
 	ProcessedAt time.Time `json:"processed_at"`
+
+	// Metadata contains token usage and processing details.
+	Metadata *ResponseMetadata `json:"metadata,omitempty"`
+
+	// Evidence points at the log lines that triggered a rule-based
+	// classification, so clients can highlight them. Only set when a rule
+	// supplied the result.
+	Evidence []LogEvidence `json:"evidence,omitempty"`
+
+	// Explain describes how the result was produced, if requested.
+	Explain *Explanation `json:"explain,omitempty"`
+
+	// SimilarIncidents are stored analyses of similar past logs, most
+	// similar first, with what was done about them.
+	SimilarIncidents []SimilarIncident `json:"similar_incidents,omitempty"`
+
+	// Runbooks are organization runbooks matching the result's error type
+	// or tags, best match first.
+	Runbooks []Runbook `json:"runbooks,omitempty"`
+
+	// Ticket is the issue tracker ticket opened or updated for a request
+	// with ticket set to true.
+	Ticket *Ticket `json:"ticket,omitempty"`
+
+	// Dockerfile is the static review of the Dockerfile, for requests to
+	// the Dockerfile endpoint.
+	Dockerfile *DockerfileReview `json:"dockerfile,omitempty"`
+
+	// Pipeline is the review of the pipeline configuration, for requests to
+	// the pipeline endpoint.
+	Pipeline *PipelineReview `json:"pipeline,omitempty"`
+}
+
+// SimilarIncident is a past analysis similar to the analyzed log.
+type SimilarIncident struct {
+	// AnalysisID and Link identify the stored analysis.
+	AnalysisID string `json:"analysis_id"`
+	Link       string `json:"link"`
+
+	// Similarity is the cosine similarity of the two logs' embeddings.
+	Similarity float64 `json:"similarity"`
+
+	// OccurredAt is when the past analysis was recorded.
+	OccurredAt time.Time `json:"occurred_at"`
+
+	ErrorType string `json:"error_type"`
+	RootCause string `json:"root_cause"`
+
+	// Resolution is the remediation suggested for the past incident.
+	Resolution []string `json:"resolution"`
+
+	// Confirmed is true when feedback marked the past analysis helpful and
+	// never unhelpful; Notes are the comments of its helpful feedback.
+	Confirmed bool     `json:"confirmed"`
+	Notes     []string `json:"notes,omitempty"`
+}
+
+// Explanation describes how an analysis was produced, for debugging why a
+// result came from an unexpected source.
+type Explanation struct {
+	// Stages are the pipeline stages that ran, in order, with their
+	// durations.
+	Stages []StageTiming `json:"stages"`
+
+	// TotalMS is the duration of the whole analysis in milliseconds.
+	TotalMS float64 `json:"total_ms"`
+
+	// RuleThreshold is the confidence a rule match needed to be used, and
+	// RuleMatches every match considered, including those below it.
+	RuleThreshold float64          `json:"rule_threshold"`
+	RuleMatches   []ExplainedMatch `json:"rule_matches"`
+
+	// PromptBytes is the size of the last prompt sent to the AI, system
+	// prompt included.
+	PromptBytes int `json:"prompt_bytes,omitempty"`
+
+	// AIAttempts are the requests made to AI providers; Retries counts
+	// those after the first.
+	AIAttempts []AIAttempt `json:"ai_attempts,omitempty"`
+	Retries    int         `json:"retries"`
+
+	// Provider and Model answered the successful AI request, if any.
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+}
+
+// StageTiming is the duration of one pipeline stage.
+type StageTiming struct {
+	Name       string  `json:"name"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// ExplainedMatch is a rule match considered during an analysis.
+type ExplainedMatch struct {
+	RuleID         string  `json:"rule_id"`
+	Confidence     float64 `json:"confidence"`
+	AboveThreshold bool    `json:"above_threshold"`
+}
+
+// AIAttempt is one request to an AI provider.
+type AIAttempt struct {
+	Provider   string  `json:"provider"`
+	Model      string  `json:"model,omitempty"`
+	Endpoint   string  `json:"endpoint,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
 }
 
 // RuleMatch represents a match from the rule-based pre-classification.
@@ -77,6 +470,23 @@ type RuleMatch struct {
 
 	// Result is the pre-computed analysis result from the rule.
 	Result *AnalysisResult
+
+	// Evidence lists the log lines where the rule's keywords and patterns
+	// occur.
+	Evidence []LogEvidence
+}
+
+// LogEvidence points at a log line that supports a result.
+type LogEvidence struct {
+	// Line is the 1-based line number in the analyzed log, i.e. after
+	// sanitization and, for requests with sections, after composition.
+	Line int `json:"line"`
+
+	// Snippet is the line's text, trimmed and shortened if very long.
+	Snippet string `json:"snippet"`
+
+	// Match is the text a keyword or pattern matched within the line.
+	Match string `json:"match"`
 }
 
 // PreprocessedLog contains the log after sanitization and pre-processing.
@@ -90,6 +500,33 @@ type PreprocessedLog struct {
 	// RuleMatches contains any matches from rule-based analysis.
 	RuleMatches []RuleMatch
 
-	// Metadata contains extracted metadata from the log.
+	// Metadata contains extracted metadata from the log, keyed by the
+	// Extracted* constants.
 	Metadata map[string]string
 }
+
+// Keys of PreprocessedLog.Metadata. Rules can restrict matching to one of
+// them with Rule.Section.
+const (
+	// ExtractedStackTrace is the most relevant (last) complete stack trace.
+	ExtractedStackTrace = "stack_trace"
+
+	// ExtractedStackLanguage is the runtime that produced it: java, python,
+	// go or node.
+	ExtractedStackLanguage = "stack_trace_language"
+
+	// ExtractedException is the exception or panic message; for chained
+	// Java exceptions, the innermost "Caused by".
+	ExtractedException = "exception"
+
+	// ExtractedStackTraceCount is the number of stack traces found.
+	ExtractedStackTraceCount = "stack_trace_count"
+
+	// ExtractedExitCode is the last non-zero process exit code.
+	ExtractedExitCode = "exit_code"
+
+	// ExtractedResources lists the infrastructure-as-code resource
+	// addresses named in the log (Terraform addresses, Pulumi URNs,
+	// CloudFormation logical IDs), comma-separated.
+	ExtractedResources = "resources"
+)
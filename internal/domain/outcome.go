@@ -0,0 +1,101 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+type outcomeKey struct{}
+
+// Outcome collects how a request's analysis was answered, for the access
+// log line written when the request completes. The analyzer and handlers
+// fill in the Outcome carried by the request context. A nil *Outcome
+// records nothing; it is safe for concurrent use.
+type Outcome struct {
+	mu     sync.Mutex
+	fields OutcomeFields
+}
+
+// OutcomeFields is a snapshot of an Outcome. Zero values were not recorded.
+type OutcomeFields struct {
+	// Analyzed reports whether an analysis response was recorded.
+	Analyzed bool
+
+	Source    string
+	ErrorType string
+	Severity  Severity
+	ErrorCode ErrorCode
+
+	// RuleID is the rule that answered the request, for rule sources.
+	RuleID string
+
+	// AILatency is the time spent waiting for the AI, including retries.
+	AILatency time.Duration
+
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// WithOutcome returns a context whose analysis outcome is recorded in
+// outcome.
+func WithOutcome(ctx context.Context, outcome *Outcome) context.Context {
+	return context.WithValue(ctx, outcomeKey{}, outcome)
+}
+
+// OutcomeFromContext returns the Outcome carried by ctx, or nil.
+func OutcomeFromContext(ctx context.Context) *Outcome {
+	outcome, _ := ctx.Value(outcomeKey{}).(*Outcome)
+	return outcome
+}
+
+// RecordAILatency adds d to the time spent waiting for the AI.
+func (o *Outcome) RecordAILatency(d time.Duration) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fields.AILatency += d
+}
+
+// RecordResponse records the source, result and token usage of the
+// response returned to the client.
+func (o *Outcome) RecordResponse(resp *AnalysisResponse) {
+	if o == nil || resp == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	f := &o.fields
+	f.Analyzed = true
+	f.Source = resp.Source
+	if kind, ruleID, ok := strings.Cut(resp.Source, ":"); ok && strings.HasPrefix(kind, "rules") {
+		f.RuleID = ruleID
+	}
+	if resp.Result != nil {
+		f.ErrorType = resp.Result.ErrorType
+		f.Severity = resp.Result.Severity
+	}
+	if resp.Error != nil {
+		f.ErrorCode = resp.Error.Code
+	}
+	if resp.Metadata != nil && resp.Metadata.Usage != nil {
+		f.PromptTokens = resp.Metadata.Usage.PromptTokens
+		f.CompletionTokens = resp.Metadata.Usage.CompletionTokens
+		f.TotalTokens = resp.Metadata.Usage.TotalTokens
+	}
+}
+
+// Fields returns what has been recorded so far.
+func (o *Outcome) Fields() OutcomeFields {
+	if o == nil {
+		return OutcomeFields{}
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.fields
+}
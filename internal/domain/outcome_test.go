@@ -0,0 +1,72 @@
+// Package domain provides unit tests for analysis outcomes.
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOutcome_RecordResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *AnalysisResponse
+		want OutcomeFields
+	}{
+		{
+			name: "rule",
+			resp: &AnalysisResponse{
+				Success: true,
+				Source:  "rules:npm_eresolve",
+				Result:  &AnalysisResult{ErrorType: "dependency_error", Severity: SeverityMedium},
+			},
+			want: OutcomeFields{Analyzed: true, Source: "rules:npm_eresolve", RuleID: "npm_eresolve", ErrorType: "dependency_error", Severity: SeverityMedium},
+		},
+		{
+			name: "ai with usage",
+			resp: &AnalysisResponse{
+				Success:  true,
+				Source:   "ai",
+				Result:   &AnalysisResult{ErrorType: "disk_full", Severity: SeverityHigh},
+				Metadata: &ResponseMetadata{Usage: &TokenUsage{PromptTokens: 900, CompletionTokens: 100, TotalTokens: 1000}},
+			},
+			want: OutcomeFields{Analyzed: true, Source: "ai", ErrorType: "disk_full", Severity: SeverityHigh, PromptTokens: 900, CompletionTokens: 100, TotalTokens: 1000},
+		},
+		{
+			name: "classifier is not a rule",
+			resp: &AnalysisResponse{Success: true, Source: "classifier:oom"},
+			want: OutcomeFields{Analyzed: true, Source: "classifier:oom"},
+		},
+		{
+			name: "error",
+			resp: &AnalysisResponse{Error: ErrorDetailFor(ErrEmptyLog)},
+			want: OutcomeFields{Analyzed: true, ErrorCode: CodeEmptyLog},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := &Outcome{}
+			outcome.RecordResponse(tt.resp)
+			if got := outcome.Fields(); got != tt.want {
+				t.Errorf("Fields() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutcome_Context(t *testing.T) {
+	if OutcomeFromContext(context.Background()) != nil {
+		t.Fatal("expected no outcome in an empty context")
+	}
+	// A nil Outcome records nothing
+	OutcomeFromContext(context.Background()).RecordAILatency(time.Second)
+
+	outcome := &Outcome{}
+	ctx := WithOutcome(context.Background(), outcome)
+	OutcomeFromContext(ctx).RecordAILatency(2 * time.Second)
+	OutcomeFromContext(ctx).RecordAILatency(time.Second)
+	if got := outcome.Fields().AILatency; got != 3*time.Second {
+		t.Errorf("AILatency = %v, want 3s", got)
+	}
+}
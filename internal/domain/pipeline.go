@@ -0,0 +1,53 @@
+// Package domain contains the core domain models and types.
+package domain
+
+// PipelineReview is the review of a CI pipeline configuration returned by
+// the pipeline endpoint next to the analysis.
+type PipelineReview struct {
+	// Provider is the CI system: "github_actions" or "gitlab_ci".
+	Provider string `json:"provider"`
+
+	// Findings are the problems of the config the failure log points at,
+	// then the config's other problems in config order.
+	Findings []PipelineFinding `json:"findings"`
+
+	// FailingStep is the step the failure log shows failing, if the log
+	// names one.
+	FailingStep *PipelineStep `json:"failing_step,omitempty"`
+}
+
+// PipelineFinding is a problem of a pipeline configuration.
+type PipelineFinding struct {
+	// Rule identifies the check (e.g. "missing_permission").
+	Rule string `json:"rule"`
+
+	// Severity is how much the problem matters: Low for hygiene, Medium for
+	// fragile pipelines, High for security problems and for the cause of
+	// the failure.
+	Severity Severity `json:"severity"`
+
+	// Line is the 1-based line of the config the problem is at, or 0.
+	Line int `json:"line,omitempty"`
+
+	// Job is the ID of the job, if the problem is in one.
+	Job string `json:"job,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// PipelineStep is a step of a pipeline job: a GitHub Actions step or a
+// GitLab script line.
+type PipelineStep struct {
+	// Job is the ID of the job.
+	Job string `json:"job"`
+
+	// Name is the step's name (GitHub) or script section (GitLab).
+	Name string `json:"name,omitempty"`
+
+	// Line is the 1-based line of the step in the config.
+	Line int `json:"line"`
+
+	// Command is the action or the first line of the command the step
+	// runs.
+	Command string `json:"command"`
+}
@@ -0,0 +1,64 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ProvenanceMode controls how provenance is redacted from responses shown to
+// consumers that must not learn which AI vendor or model answered.
+type ProvenanceMode string
+
+const (
+	// ProvenanceNormalize reduces Source to its kind ("ai", "rules", ...)
+	// and drops provider-specific details.
+	ProvenanceNormalize ProvenanceMode = "normalize"
+
+	// ProvenanceHide removes Source entirely.
+	ProvenanceHide ProvenanceMode = "hide"
+)
+
+// IsValid checks if the provenance mode is one of the allowed values.
+func (m ProvenanceMode) IsValid() bool {
+	return m == ProvenanceNormalize || m == ProvenanceHide
+}
+
+// WithoutProvenance returns a copy of r with provenance redacted according to
+// mode: Source is normalized or hidden, token usage and cost are dropped, and
+// messages of upstream errors, which may quote the provider, are replaced by
+// generic ones.
+// r itself is not modified, so stored records and audit logs keep the full
+// provenance.
+func (r *AnalysisResponse) WithoutProvenance(mode ProvenanceMode) *AnalysisResponse {
+	redacted := *r
+
+	if mode == ProvenanceHide {
+		redacted.Source = ""
+	} else {
+		redacted.Source, _, _ = strings.Cut(r.Source, ":")
+	}
+
+	if r.Metadata != nil {
+		metadata := *r.Metadata
+		metadata.Usage = nil
+		metadata.EstimatedCostUSD = 0
+		metadata.PromptVersion = ""
+		metadata.RuleIDs = nil
+		if metadata.Reduction == nil && !metadata.Degraded && !metadata.Cached {
+			redacted.Metadata = nil
+		} else {
+			redacted.Metadata = &metadata
+		}
+	}
+
+	// Explanations name rules, providers and models.
+	redacted.Explain = nil
+
+	// Request validation messages only describe the caller's input.
+	if r.Error != nil && r.Error.Code.HTTPStatus() != http.StatusBadRequest {
+		redacted.Error = &ErrorDetail{Code: r.Error.Code, Message: r.Error.Code.Description()}
+	}
+
+	return &redacted
+}
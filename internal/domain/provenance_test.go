@@ -0,0 +1,67 @@
+// Package domain provides unit tests for provenance redaction.
+package domain
+
+import "testing"
+
+func TestAnalysisResponse_WithoutProvenance(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    *AnalysisResponse
+		mode        ProvenanceMode
+		wantSource  string
+		wantMessage string
+	}{
+		{
+			name: "normalize ai source and drop usage",
+			response: &AnalysisResponse{
+				Success:  true,
+				Source:   "ai:terraform",
+				Metadata: &ResponseMetadata{Usage: &TokenUsage{TotalTokens: 10}, EstimatedCostUSD: 0.1},
+			},
+			mode:       ProvenanceNormalize,
+			wantSource: "ai",
+		},
+		{
+			name:       "hide source",
+			response:   &AnalysisResponse{Success: true, Source: "rules:oom"},
+			mode:       ProvenanceHide,
+			wantSource: "",
+		},
+		{
+			name: "generic upstream error",
+			response: &AnalysisResponse{
+				Error: NewErrorDetail(CodeAIAuthFailed, "AI authentication failed (openai status 401)"),
+			},
+			mode:        ProvenanceNormalize,
+			wantMessage: CodeAIAuthFailed.Description(),
+		},
+		{
+			name: "request error kept",
+			response: &AnalysisResponse{
+				Error: NewErrorDetail(CodeInvalidRequest, "invalid detail \"huge\""),
+			},
+			mode:        ProvenanceNormalize,
+			wantMessage: "invalid detail \"huge\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := *tt.response
+			got := tt.response.WithoutProvenance(tt.mode)
+
+			if got.Source != tt.wantSource {
+				t.Errorf("Source = %q, want %q", got.Source, tt.wantSource)
+			}
+			if got.Metadata != nil {
+				t.Errorf("Metadata = %+v, want nil", got.Metadata)
+			}
+			if got.Error != nil && got.Error.Message != tt.wantMessage {
+				t.Errorf("Error.Message = %q, want %q", got.Error.Message, tt.wantMessage)
+			}
+			if tt.response.Source != original.Source || tt.response.Metadata != original.Metadata || tt.response.Error != original.Error {
+				t.Error("WithoutProvenance must not modify the original response")
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// AnalysisRecord is a persisted analysis.
+type AnalysisRecord struct {
+	// ID uniquely identifies the analysis. Assigned by the store if empty.
+	ID string `json:"id"`
+
+	// CreatedAt is when the analysis was recorded.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Log is the sanitized log that was analyzed. Raw logs are never stored.
+	Log string `json:"log"`
+
+	// Source indicates whether the result came from rules or AI.
+	Source string `json:"source"`
+
+	// Result is the analysis result.
+	Result *AnalysisResult `json:"result"`
+
+	// Metadata carries token usage and processing details.
+	Metadata *ResponseMetadata `json:"metadata,omitempty"`
+
+	// Evidence points at the lines of Log that triggered a rule-based
+	// classification.
+	Evidence []LogEvidence `json:"evidence,omitempty"`
+
+	// Stale marks a result produced by a rule or prompt version that has
+	// since changed; its advice may be outdated.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// DependsOnRule reports whether the record's result was produced, merged
+// or hinted by the rule with id.
+func (r *AnalysisRecord) DependsOnRule(id string) bool {
+	if _, ruleID, ok := strings.Cut(r.Source, ":"); ok && ruleID == id && !strings.HasPrefix(r.Source, "ai") {
+		return true
+	}
+	if r.Metadata != nil {
+		for _, ruleID := range r.Metadata.RuleIDs {
+			if ruleID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UsesPrompt reports whether the record's result was produced by the AI with
+// the given prompt version.
+func (r *AnalysisRecord) UsesPrompt(version string) bool {
+	return r.Metadata != nil && r.Metadata.PromptVersion == version
+}
+
+// Feedback is a user's assessment of an analysis.
+type Feedback struct {
+	// AnalysisID references the analysis being rated.
+	AnalysisID string `json:"analysis_id"`
+
+	// Helpful indicates whether the analysis was useful.
+	Helpful bool `json:"helpful"`
+
+	// Comment is optional free-form feedback.
+	Comment string `json:"comment,omitempty"`
+
+	// CreatedAt is when the feedback was recorded.
+	CreatedAt time.Time `json:"created_at"`
+}
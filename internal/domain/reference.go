@@ -0,0 +1,92 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Reference is a documentation link relevant to a failure.
+type Reference struct {
+	// Title names the linked page.
+	Title string `json:"title"`
+
+	// URL is the absolute http(s) URL of the page.
+	URL string `json:"url"`
+}
+
+// DefaultReferenceDomains are the documentation sites AI references may
+// link to. Subdomains are allowed.
+var DefaultReferenceDomains = []string{
+	"kubernetes.io",
+	"docs.docker.com",
+	"helm.sh",
+	"developer.hashicorp.com",
+	"registry.terraform.io",
+	"www.pulumi.com",
+	"docs.npmjs.com",
+	"nodejs.org",
+	"yarnpkg.com",
+	"pnpm.io",
+	"go.dev",
+	"pkg.go.dev",
+	"docs.python.org",
+	"pip.pypa.io",
+	"python-poetry.org",
+	"maven.apache.org",
+	"docs.gradle.org",
+	"doc.rust-lang.org",
+	"docs.github.com",
+	"docs.gitlab.com",
+	"git-scm.com",
+	"docs.aws.amazon.com",
+	"cloud.google.com",
+	"learn.microsoft.com",
+	"www.postgresql.org",
+	"dev.mysql.com",
+	"redis.io",
+	"nginx.org",
+	"letsencrypt.org",
+}
+
+// FilterReferences returns the references whose URL is an http(s) URL on
+// one of domains or their subdomains, so links a model made up on other
+// sites are dropped. Duplicate URLs are dropped and an empty title becomes
+// the URL.
+func FilterReferences(refs []Reference, domains []string) []Reference {
+	if refs == nil {
+		return nil
+	}
+	filtered := make([]Reference, 0, len(refs))
+	seen := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		link := strings.TrimSpace(ref.URL)
+		if seen[link] || !allowedReferenceURL(link, domains) {
+			continue
+		}
+		seen[link] = true
+		title := strings.TrimSpace(ref.Title)
+		if title == "" {
+			title = link
+		}
+		filtered = append(filtered, Reference{Title: title, URL: link})
+	}
+	return filtered
+}
+
+// allowedReferenceURL reports whether link is an http(s) URL on one of
+// domains.
+func allowedReferenceURL(link string, domains []string) bool {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
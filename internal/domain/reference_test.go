@@ -0,0 +1,62 @@
+// Package domain provides unit tests for reference links.
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterReferences(t *testing.T) {
+	domains := []string{"kubernetes.io", "docs.docker.com"}
+	tests := []struct {
+		name string
+		refs []Reference
+		want []Reference
+	}{
+		{
+			name: "allowed domain and subdomain",
+			refs: []Reference{
+				{Title: "Pods", URL: "https://kubernetes.io/docs/concepts/workloads/pods/"},
+				{Title: "Blog", URL: " https://blog.kubernetes.io/ "},
+			},
+			want: []Reference{
+				{Title: "Pods", URL: "https://kubernetes.io/docs/concepts/workloads/pods/"},
+				{Title: "Blog", URL: "https://blog.kubernetes.io/"},
+			},
+		},
+		{
+			name: "other sites and lookalikes dropped",
+			refs: []Reference{
+				{Title: "Fix", URL: "https://fix-my-k8s.example.com/"},
+				{Title: "Lookalike", URL: "https://evilkubernetes.io/"},
+				{Title: "Credentials", URL: "https://user@kubernetes.io/"},
+			},
+			want: []Reference{},
+		},
+		{
+			name: "non-http schemes dropped",
+			refs: []Reference{
+				{Title: "Script", URL: "javascript:alert(1)"},
+				{Title: "Relative", URL: "/docs/pods"},
+			},
+			want: []Reference{},
+		},
+		{
+			name: "duplicates dropped and empty title filled",
+			refs: []Reference{
+				{URL: "https://docs.docker.com/engine/"},
+				{Title: "Engine", URL: "https://docs.docker.com/engine/"},
+			},
+			want: []Reference{{Title: "https://docs.docker.com/engine/", URL: "https://docs.docker.com/engine/"}},
+		},
+		{name: "nil", refs: nil, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FilterReferences(tt.refs, domains); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterReferences() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Package domain provides unit tests for request IDs.
+package domain
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewRequestID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := NewRequestID()
+		if !uuidV4.MatchString(id) {
+			t.Fatalf("NewRequestID() = %q, want a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Fatalf("NewRequestID() returned %q twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", true},
+		{"ci-build:1234.5_a", true},
+		{"", false},
+		{"id with spaces", false},
+		{"id\nforged log line", false},
+		{`"quoted"`, false},
+		{strings.Repeat("a", MaxRequestIDLength), true},
+		{strings.Repeat("a", MaxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		if got := ValidRequestID(tt.id); got != tt.want {
+			t.Errorf("ValidRequestID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestRequestIDContext(t *testing.T) {
+	if id := RequestIDFromContext(context.Background()); id != "" {
+		t.Errorf("RequestIDFromContext() = %q, want empty", id)
+	}
+	ctx := WithRequestID(context.Background(), "req-1")
+	if id := RequestIDFromContext(ctx); id != "req-1" {
+		t.Errorf("RequestIDFromContext() = %q, want req-1", id)
+	}
+}
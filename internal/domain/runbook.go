@@ -0,0 +1,17 @@
+// Package domain contains the core domain models and types.
+package domain
+
+// Runbook is an organization runbook linked to an analysis by its error
+// type or tags.
+type Runbook struct {
+	// ID is the runbook's identifier in its source (e.g. "RB-114"), if any.
+	ID string `json:"id,omitempty"`
+
+	// Title and URL identify the runbook page.
+	Title string `json:"title"`
+	URL   string `json:"url"`
+
+	// Source names the knowledge source the runbook came from
+	// ("markdown", "confluence", "notion").
+	Source string `json:"source"`
+}
@@ -0,0 +1,335 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Error categories group canonical error types.
+const (
+	ErrorCategoryBuild          = "build"
+	ErrorCategoryDependency     = "dependency"
+	ErrorCategoryContainer      = "container"
+	ErrorCategoryKubernetes     = "kubernetes"
+	ErrorCategoryInfrastructure = "infrastructure"
+	ErrorCategoryNetwork        = "network"
+	ErrorCategoryAccess         = "access"
+	ErrorCategoryResource       = "resource"
+	ErrorCategoryApplication    = "application"
+	ErrorCategoryTest           = "test"
+)
+
+// Canonical error types, the values of AnalysisResult.ErrorType that rules
+// produce and AI answers are normalized to.
+const (
+	ErrorTypeCompilationError = "compilation_error"
+	ErrorTypeLintFailure      = "lint_failure"
+	ErrorTypeCargoBuild       = "cargo_build_failure"
+
+	ErrorTypeNPMInstall         = "npm_install_failure"
+	ErrorTypeYarnInstall        = "yarn_install_failure"
+	ErrorTypePNPMInstall        = "pnpm_install_failure"
+	ErrorTypePipInstall         = "pip_install_failure"
+	ErrorTypePoetryResolution   = "poetry_dependency_resolution"
+	ErrorTypeMavenResolution    = "maven_dependency_resolution"
+	ErrorTypeGradleResolution   = "gradle_dependency_resolution"
+	ErrorTypeGoModuleChecksum   = "go_module_checksum_mismatch"
+	ErrorTypeDependencyConflict = "dependency_conflict"
+
+	ErrorTypeDockerBuild             = "docker_build_failure"
+	ErrorTypeDockerPermissionDenied  = "docker_permission_denied"
+	ErrorTypeDockerDaemonUnavailable = "docker_daemon_unavailable"
+	ErrorTypeDockerImageNotFound     = "docker_image_not_found"
+	ErrorTypeRegistryRateLimited     = "registry_rate_limited"
+
+	ErrorTypeKubernetesImagePull = "kubernetes_image_pull_failure"
+	ErrorTypeCrashLoopBackoff    = "k8s_crash_loop"
+	ErrorTypeProbeFailure        = "k8s_probe_failure"
+	ErrorTypePodUnschedulable    = "k8s_pod_unschedulable"
+	ErrorTypePVCPending          = "k8s_pvc_pending"
+	ErrorTypeHelmReleaseFailed   = "helm_release_failed"
+
+	ErrorTypeTerraformStateLocked     = "terraform_state_locked"
+	ErrorTypeTerraformProviderInstall = "terraform_provider_install_failure"
+	ErrorTypeTerraformApply           = "terraform_apply_failure"
+	ErrorTypeTerraformPlan            = "terraform_plan_failure"
+	ErrorTypePulumiStackLocked        = "pulumi_stack_locked"
+	ErrorTypePulumiUpdate             = "pulumi_update_failure"
+	ErrorTypeCloudFormationStack      = "cloudformation_stack_failure"
+	ErrorTypeIaCProviderAuth          = "iac_provider_auth_failure"
+	ErrorTypeIaCDrift                 = "iac_drift_detected"
+
+	ErrorTypeConnectionTimeout = "connection_timeout"
+	ErrorTypeConnectionRefused = "connection_refused"
+	ErrorTypeDNSResolution     = "dns_resolution_failure"
+	ErrorTypeSSLCertificate    = "ssl_certificate_error"
+	ErrorTypeProxyTLSHandshake = "proxy_tls_handshake_failure"
+
+	ErrorTypeAuthentication    = "authentication_failure"
+	ErrorTypeGitAuthentication = "git_authentication_failure"
+	ErrorTypePermissionDenied  = "permission_denied"
+	ErrorTypeCITokenPermission = "ci_token_permission"
+
+	ErrorTypeOutOfMemory        = "out_of_memory"
+	ErrorTypeDiskSpaceFull      = "disk_space_full"
+	ErrorTypeWindowsPathTooLong = "windows_path_too_long"
+	ErrorTypeFileLocked         = "file_locked"
+	ErrorTypePortInUse          = "port_already_in_use"
+
+	ErrorTypeNullPointer         = "null_pointer"
+	ErrorTypeConfiguration       = "configuration_error"
+	ErrorTypeDatabaseUnreachable = "database_unreachable"
+	ErrorTypeDatabaseMigration   = "database_migration_failure"
+
+	ErrorTypeTestFailure = "test_failure"
+	ErrorTypeFlakyTest   = "flaky_test"
+)
+
+// ErrorTypeInfo places a canonical error type in the taxonomy.
+type ErrorTypeInfo struct {
+	Type        string `json:"error_type"`
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory"`
+}
+
+// taxonomy lists the canonical error types by category.
+var taxonomy = []ErrorTypeInfo{
+	{ErrorTypeCompilationError, ErrorCategoryBuild, "compile"},
+	{ErrorTypeLintFailure, ErrorCategoryBuild, "lint"},
+	{ErrorTypeCargoBuild, ErrorCategoryBuild, "cargo"},
+
+	{ErrorTypeNPMInstall, ErrorCategoryDependency, "npm"},
+	{ErrorTypeYarnInstall, ErrorCategoryDependency, "yarn"},
+	{ErrorTypePNPMInstall, ErrorCategoryDependency, "pnpm"},
+	{ErrorTypePipInstall, ErrorCategoryDependency, "pip"},
+	{ErrorTypePoetryResolution, ErrorCategoryDependency, "poetry"},
+	{ErrorTypeMavenResolution, ErrorCategoryDependency, "maven"},
+	{ErrorTypeGradleResolution, ErrorCategoryDependency, "gradle"},
+	{ErrorTypeGoModuleChecksum, ErrorCategoryDependency, "go"},
+	{ErrorTypeDependencyConflict, ErrorCategoryDependency, "resolution"},
+
+	{ErrorTypeDockerBuild, ErrorCategoryContainer, "build"},
+	{ErrorTypeDockerPermissionDenied, ErrorCategoryContainer, "daemon"},
+	{ErrorTypeDockerDaemonUnavailable, ErrorCategoryContainer, "daemon"},
+	{ErrorTypeDockerImageNotFound, ErrorCategoryContainer, "registry"},
+	{ErrorTypeRegistryRateLimited, ErrorCategoryContainer, "registry"},
+
+	{ErrorTypeKubernetesImagePull, ErrorCategoryKubernetes, "image"},
+	{ErrorTypeCrashLoopBackoff, ErrorCategoryKubernetes, "pod"},
+	{ErrorTypeProbeFailure, ErrorCategoryKubernetes, "pod"},
+	{ErrorTypePodUnschedulable, ErrorCategoryKubernetes, "scheduling"},
+	{ErrorTypePVCPending, ErrorCategoryKubernetes, "storage"},
+	{ErrorTypeHelmReleaseFailed, ErrorCategoryKubernetes, "helm"},
+
+	{ErrorTypeTerraformStateLocked, ErrorCategoryInfrastructure, "terraform"},
+	{ErrorTypeTerraformProviderInstall, ErrorCategoryInfrastructure, "terraform"},
+	{ErrorTypeTerraformApply, ErrorCategoryInfrastructure, "terraform"},
+	{ErrorTypeTerraformPlan, ErrorCategoryInfrastructure, "terraform"},
+	{ErrorTypePulumiStackLocked, ErrorCategoryInfrastructure, "pulumi"},
+	{ErrorTypePulumiUpdate, ErrorCategoryInfrastructure, "pulumi"},
+	{ErrorTypeCloudFormationStack, ErrorCategoryInfrastructure, "cloudformation"},
+	{ErrorTypeIaCProviderAuth, ErrorCategoryInfrastructure, "iac"},
+	{ErrorTypeIaCDrift, ErrorCategoryInfrastructure, "iac"},
+
+	{ErrorTypeConnectionTimeout, ErrorCategoryNetwork, "connection"},
+	{ErrorTypeConnectionRefused, ErrorCategoryNetwork, "connection"},
+	{ErrorTypeDNSResolution, ErrorCategoryNetwork, "dns"},
+	{ErrorTypeSSLCertificate, ErrorCategoryNetwork, "tls"},
+	{ErrorTypeProxyTLSHandshake, ErrorCategoryNetwork, "proxy"},
+
+	{ErrorTypeAuthentication, ErrorCategoryAccess, "authentication"},
+	{ErrorTypeGitAuthentication, ErrorCategoryAccess, "authentication"},
+	{ErrorTypePermissionDenied, ErrorCategoryAccess, "authorization"},
+	{ErrorTypeCITokenPermission, ErrorCategoryAccess, "ci"},
+
+	{ErrorTypeOutOfMemory, ErrorCategoryResource, "memory"},
+	{ErrorTypeDiskSpaceFull, ErrorCategoryResource, "disk"},
+	{ErrorTypeWindowsPathTooLong, ErrorCategoryResource, "filesystem"},
+	{ErrorTypeFileLocked, ErrorCategoryResource, "filesystem"},
+	{ErrorTypePortInUse, ErrorCategoryResource, "port"},
+
+	{ErrorTypeNullPointer, ErrorCategoryApplication, "runtime"},
+	{ErrorTypeConfiguration, ErrorCategoryApplication, "configuration"},
+	{ErrorTypeDatabaseUnreachable, ErrorCategoryApplication, "database"},
+	{ErrorTypeDatabaseMigration, ErrorCategoryApplication, "database"},
+
+	{ErrorTypeTestFailure, ErrorCategoryTest, "assertion"},
+	{ErrorTypeFlakyTest, ErrorCategoryTest, "flaky"},
+}
+
+// errorTypeAliases maps spellings whose key differs from the canonical
+// type's key, such as error names from the tools themselves.
+var errorTypeAliases = map[string]string{
+	"oom":                     ErrorTypeOutOfMemory,
+	"oom_killed":              ErrorTypeOutOfMemory,
+	"memory_exhausted":        ErrorTypeOutOfMemory,
+	"heap_out_of_memory":      ErrorTypeOutOfMemory,
+	"disk_full":               ErrorTypeDiskSpaceFull,
+	"no_space_left_on_device": ErrorTypeDiskSpaceFull,
+	"enospc":                  ErrorTypeDiskSpaceFull,
+	"access_denied":           ErrorTypePermissionDenied,
+	"eacces":                  ErrorTypePermissionDenied,
+	"eperm":                   ErrorTypePermissionDenied,
+	"unauthorized":            ErrorTypeAuthentication,
+	"image_pull_back_off":     ErrorTypeKubernetesImagePull,
+	"image_pull_backoff":      ErrorTypeKubernetesImagePull,
+	"err_image_pull":          ErrorTypeKubernetesImagePull,
+	"crash_loop_back_off":     ErrorTypeCrashLoopBackoff,
+	"crash_loop_backoff":      ErrorTypeCrashLoopBackoff,
+	"liveness_probe":          ErrorTypeProbeFailure,
+	"readiness_probe":         ErrorTypeProbeFailure,
+	"pod_scheduling":          ErrorTypePodUnschedulable,
+	"failed_scheduling":       ErrorTypePodUnschedulable,
+	"econnrefused":            ErrorTypeConnectionRefused,
+	"etimedout":               ErrorTypeConnectionTimeout,
+	"network_timeout":         ErrorTypeConnectionTimeout,
+	"enotfound":               ErrorTypeDNSResolution,
+	"dns":                     ErrorTypeDNSResolution,
+	"certificate_expired":     ErrorTypeSSLCertificate,
+	"tls_certificate":         ErrorTypeSSLCertificate,
+	"npe":                     ErrorTypeNullPointer,
+	"nil_pointer_dereference": ErrorTypeNullPointer,
+	"compile":                 ErrorTypeCompilationError,
+	"build_compilation":       ErrorTypeCompilationError,
+	"eaddrinuse":              ErrorTypePortInUse,
+	"address_already_in_use":  ErrorTypePortInUse,
+	"tests":                   ErrorTypeTestFailure,
+	"unit_test":               ErrorTypeTestFailure,
+	"insufficient_resources":  ErrorTypePodUnschedulable,
+	"unbound_pvc":             ErrorTypePVCPending,
+	"pvc_not_bound":           ErrorTypePVCPending,
+	"terraform_provider_auth": ErrorTypeIaCProviderAuth,
+	"provider_credentials":    ErrorTypeIaCProviderAuth,
+	"terraform_drift":         ErrorTypeIaCDrift,
+	"state_drift":             ErrorTypeIaCDrift,
+	"terraform_validation":    ErrorTypeTerraformPlan,
+	"cloudformation_rollback": ErrorTypeCloudFormationStack,
+	"stack_rollback":          ErrorTypeCloudFormationStack,
+	"github_token_permission": ErrorTypeCITokenPermission,
+	"resource_not_accessible": ErrorTypeCITokenPermission,
+	"ci_job_token_permission": ErrorTypeCITokenPermission,
+}
+
+// genericWords carry no meaning in an error type ("docker_build_failed"
+// and "DockerBuildError" are both "docker_build").
+var genericWords = map[string]bool{
+	"error": true, "errors": true, "err": true, "failure": true, "failures": true,
+	"failed": true, "fail": true, "fails": true, "issue": true, "problem": true,
+	"exception": true,
+}
+
+// wordSynonyms replace abbreviations and variants word by word.
+var wordSynonyms = map[string]string{
+	"auth":         "authentication",
+	"authn":        "authentication",
+	"k8s":          "kubernetes",
+	"img":          "image",
+	"deps":         "dependency",
+	"dependencies": "dependency",
+	"config":       "configuration",
+	"db":           "database",
+	"cert":         "certificate",
+	"timed_out":    "timeout",
+}
+
+var (
+	taxonomyByType map[string]ErrorTypeInfo
+	errorTypeKeys  map[string]string
+)
+
+func init() {
+	taxonomyByType = make(map[string]ErrorTypeInfo, len(taxonomy))
+	errorTypeKeys = make(map[string]string, len(taxonomy)+len(errorTypeAliases))
+	add := func(spelling, canonical string) {
+		key := errorTypeKey(snakeCase(spelling))
+		if other, ok := errorTypeKeys[key]; ok && other != canonical {
+			panic(fmt.Sprintf("error type %q and %q share the key %q", other, canonical, key))
+		}
+		errorTypeKeys[key] = canonical
+	}
+	for _, info := range taxonomy {
+		taxonomyByType[info.Type] = info
+		add(info.Type, info.Type)
+	}
+	for alias, canonical := range errorTypeAliases {
+		add(alias, canonical)
+	}
+}
+
+// ErrorTaxonomy returns the canonical error types.
+func ErrorTaxonomy() []ErrorTypeInfo {
+	return append([]ErrorTypeInfo(nil), taxonomy...)
+}
+
+// LookupErrorType returns the taxonomy entry of a canonical error type.
+func LookupErrorType(errorType string) (ErrorTypeInfo, bool) {
+	info, ok := taxonomyByType[errorType]
+	return info, ok
+}
+
+// NormalizeErrorType maps a free-form error type ("docker-build-failed",
+// "DockerBuildError", "OOMKilled") to its canonical type. Error types not in
+// the taxonomy are returned in snake_case and ok is false.
+func NormalizeErrorType(errorType string) (normalized string, ok bool) {
+	snake := snakeCase(errorType)
+	if canonical, found := errorTypeKeys[errorTypeKey(snake)]; found {
+		return canonical, true
+	}
+	return snake, false
+}
+
+// snakeCase lowercases s, splits camelCase words and joins words with "_".
+func snakeCase(s string) string {
+	runes := []rune(strings.TrimSpace(s))
+	var b strings.Builder
+	pendingSep := false
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingSep = b.Len() > 0
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// "DockerBuild" and the "K" of "OOMKilled" start words
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				pendingSep = b.Len() > 0
+			}
+		}
+		if pendingSep {
+			b.WriteByte('_')
+			pendingSep = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// errorTypeKey reduces a snake_case error type to the words that identify
+// it: generic words dropped, synonyms replaced.
+func errorTypeKey(snake string) string {
+	words := strings.Split(snake, "_")
+	kept := words[:0]
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if i+1 < len(words) {
+			if synonym, ok := wordSynonyms[word+"_"+words[i+1]]; ok {
+				kept = append(kept, synonym)
+				i++
+				continue
+			}
+		}
+		if synonym, ok := wordSynonyms[word]; ok {
+			word = synonym
+		}
+		if !genericWords[word] && word != "" {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		return snake
+	}
+	return strings.Join(kept, "_")
+}
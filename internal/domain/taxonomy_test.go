@@ -0,0 +1,55 @@
+// Package domain provides unit tests for the error type taxonomy.
+package domain
+
+import "testing"
+
+func TestNormalizeErrorType(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"docker_build_failure", ErrorTypeDockerBuild, true},
+		{"docker-build-failed", ErrorTypeDockerBuild, true},
+		{"DockerBuildError", ErrorTypeDockerBuild, true},
+		{"Docker Build Failure", ErrorTypeDockerBuild, true},
+		{"NPM_INSTALL_ERROR", ErrorTypeNPMInstall, true},
+		{"OOMKilled", ErrorTypeOutOfMemory, true},
+		{"out-of-memory", ErrorTypeOutOfMemory, true},
+		{"NullPointerException", ErrorTypeNullPointer, true},
+		{"ImagePullBackOff", ErrorTypeKubernetesImagePull, true},
+		{"CrashLoopBackOff", ErrorTypeCrashLoopBackoff, true},
+		{"k8s-image-pull-error", ErrorTypeKubernetesImagePull, true},
+		{"auth_failure", ErrorTypeAuthentication, true},
+		{"ENOSPC", ErrorTypeDiskSpaceFull, true},
+		{"connection timed out", ErrorTypeConnectionTimeout, true},
+		{"TestFailed", ErrorTypeTestFailure, true},
+		{"terraform_provider_auth", ErrorTypeIaCProviderAuth, true},
+		{"cloudformation-rollback", ErrorTypeCloudFormationStack, true},
+		{"GPUDriverMismatch", "gpu_driver_mismatch", false},
+		{"  weird--Type ", "weird_type", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := NormalizeErrorType(tt.in)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("NormalizeErrorType(%q) = (%q, %v), want (%q, %v)", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestErrorTaxonomy(t *testing.T) {
+	for _, info := range ErrorTaxonomy() {
+		if info.Category == "" || info.Subcategory == "" {
+			t.Errorf("%s has no category or subcategory", info.Type)
+		}
+		if got, ok := NormalizeErrorType(info.Type); !ok || got != info.Type {
+			t.Errorf("canonical %s normalizes to %s", info.Type, got)
+		}
+		if _, ok := LookupErrorType(info.Type); !ok {
+			t.Errorf("LookupErrorType(%s) not found", info.Type)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+// Package domain contains the core domain models and types.
+package domain
+
+import "context"
+
+// DefaultTenant is used when a request does not identify its tenant.
+const DefaultTenant = "default"
+
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the tenant ID.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant ID carried by ctx, or DefaultTenant.
+func TenantFromContext(ctx context.Context) string {
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok && tenant != "" {
+		return tenant
+	}
+	return DefaultTenant
+}
@@ -0,0 +1,17 @@
+// Package domain contains the core domain models and types.
+package domain
+
+// Ticket is an issue tracker ticket opened or updated for an analysis.
+type Ticket struct {
+	// Tracker names the issue tracker ("jira", "github").
+	Tracker string `json:"tracker"`
+
+	// Key identifies the ticket in the tracker (e.g. "OPS-42" or
+	// "acme/api#17"); URL links to it.
+	Key string `json:"key"`
+	URL string `json:"url"`
+
+	// Created is true when the analysis opened the ticket and false when it
+	// commented on an open ticket for the same log fingerprint.
+	Created bool `json:"created"`
+}
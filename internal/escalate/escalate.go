@@ -0,0 +1,187 @@
+// Package escalate pages on-call responders (PagerDuty, Opsgenie) for
+// analyses matching operator-defined conditions.
+package escalate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// maxErrorBody bounds how much of an error response is reported.
+const maxErrorBody = 512
+
+// Conditions select the analyses that page. All set conditions must hold.
+type Conditions struct {
+	// MinSeverity is the lowest severity that pages.
+	MinSeverity domain.Severity
+
+	// ErrorTypes, if set, restricts paging to these error types.
+	ErrorTypes []string
+
+	// Sources, if set, restricts paging to these result sources, by kind
+	// ("ai", "rules", "rules_fallback", "classifier", ...).
+	Sources []string
+
+	// MinConfidence is the lowest AI-reported confidence that pages. It
+	// applies to AI results only; rule results matched above the rule
+	// threshold already.
+	MinConfidence float64
+}
+
+// Matches reports whether the successful analysis in response pages.
+func (c *Conditions) Matches(response *domain.AnalysisResponse) bool {
+	if response == nil || !response.Success || response.Result == nil {
+		return false
+	}
+	result := response.Result
+	if result.Severity.Rank() < c.MinSeverity.Rank() {
+		return false
+	}
+	if len(c.ErrorTypes) > 0 && !containsFold(c.ErrorTypes, result.ErrorType) {
+		return false
+	}
+	kind, _, _ := strings.Cut(response.Source, ":")
+	if len(c.Sources) > 0 && !containsFold(c.Sources, kind) {
+		return false
+	}
+	if kind == "ai" && result.Confidence < c.MinConfidence {
+		return false
+	}
+	return true
+}
+
+// Alert is an on-call alert for an analysis.
+type Alert struct {
+	// DedupKey groups repeats of the same failure into one incident.
+	DedupKey string
+
+	// Summary is the one-line alert title.
+	Summary string
+
+	// Severity, ErrorType, RootCause and Source describe the analysis.
+	Severity  domain.Severity
+	ErrorType string
+	RootCause string
+	Source    string
+
+	// AnalysisID identifies the stored analysis, if it was stored.
+	AnalysisID string
+
+	// SuggestedActions are the first remediation steps.
+	SuggestedActions []string
+}
+
+// Sink delivers alerts to an on-call service.
+type Sink interface {
+	// Name identifies the sink in logs.
+	Name() string
+
+	// Trigger opens an alert, or adds to the open one with its DedupKey.
+	Trigger(ctx context.Context, alert *Alert) error
+}
+
+// Escalator pages every sink for analyses matching its conditions.
+//
+// A nil *Escalator pages nobody.
+type Escalator struct {
+	sinks      []Sink
+	conditions Conditions
+	logger     *zap.Logger
+}
+
+// NewEscalator creates an Escalator paging sinks for analyses matching
+// conditions. Error types are mapped onto the taxonomy, so "OOMKilled"
+// matches out_of_memory results.
+func NewEscalator(sinks []Sink, conditions Conditions, logger *zap.Logger) *Escalator {
+	errorTypes := make([]string, len(conditions.ErrorTypes))
+	for i, errorType := range conditions.ErrorTypes {
+		errorTypes[i], _ = domain.NormalizeErrorType(errorType)
+	}
+	conditions.ErrorTypes = errorTypes
+	return &Escalator{
+		sinks:      sinks,
+		conditions: conditions,
+		logger:     logger.Named("escalator"),
+	}
+}
+
+// Wants reports whether response matches the escalation conditions.
+func (e *Escalator) Wants(response *domain.AnalysisResponse) bool {
+	return e != nil && len(e.sinks) > 0 && e.conditions.Matches(response)
+}
+
+// Escalate pages every sink for the analysis of the log with fingerprint if
+// it matches the conditions. It returns whether the analysis matched and
+// the sink failures joined.
+func (e *Escalator) Escalate(ctx context.Context, fingerprint string, response *domain.AnalysisResponse) (bool, error) {
+	if !e.Wants(response) {
+		return false, nil
+	}
+	alert := newAlert(fingerprint, response)
+
+	var errs []error
+	for _, sink := range e.sinks {
+		if err := sink.Trigger(ctx, alert); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		e.logger.Info("analysis escalated",
+			zap.String("sink", sink.Name()),
+			zap.String("error_type", alert.ErrorType),
+		)
+	}
+	if len(errs) > 0 {
+		return true, domain.WrapError("escalate", errors.Join(errs...), true)
+	}
+	return true, nil
+}
+
+// maxAlertActions bounds the suggested actions included in an alert.
+const maxAlertActions = 3
+
+// newAlert builds the alert for an analysis.
+func newAlert(fingerprint string, response *domain.AnalysisResponse) *Alert {
+	result := response.Result
+	actions := result.SuggestedActions
+	if len(actions) > maxAlertActions {
+		actions = actions[:maxAlertActions]
+	}
+	return &Alert{
+		DedupKey:         "ai-devops-" + fingerprint,
+		Summary:          fmt.Sprintf("[%s] %s: %s", result.Severity, result.ErrorType, strings.Join(strings.Fields(result.RootCause), " ")),
+		Severity:         result.Severity,
+		ErrorType:        result.ErrorType,
+		RootCause:        result.RootCause,
+		Source:           response.Source,
+		AnalysisID:       response.ID,
+		SuggestedActions: actions,
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate shortens s to at most max bytes, marking the cut. It never
+// splits a UTF-8 sequence.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max - len("...")
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return strings.TrimSpace(s[:cut]) + "..."
+}
@@ -0,0 +1,178 @@
+// Package escalate provides unit tests for escalation conditions and sinks.
+package escalate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// fakeSink records triggered alerts.
+type fakeSink struct {
+	alerts []*Alert
+	err    error
+}
+
+func (f *fakeSink) Name() string { return "fake" }
+
+func (f *fakeSink) Trigger(_ context.Context, alert *Alert) error {
+	f.alerts = append(f.alerts, alert)
+	return f.err
+}
+
+func response(source, errorType string, severity domain.Severity, confidence float64) *domain.AnalysisResponse {
+	return &domain.AnalysisResponse{
+		Success: true,
+		Source:  source,
+		Result: &domain.AnalysisResult{
+			ErrorType:        errorType,
+			Severity:         severity,
+			RootCause:        "The container exceeded its memory limit.",
+			Confidence:       confidence,
+			SuggestedActions: []string{"a", "b", "c", "d"},
+		},
+	}
+}
+
+func TestConditions_Matches(t *testing.T) {
+	conditions := Conditions{
+		MinSeverity:   domain.SeverityHigh,
+		ErrorTypes:    []string{"out_of_memory"},
+		Sources:       []string{"ai", "rules"},
+		MinConfidence: 0.8,
+	}
+
+	tests := []struct {
+		name     string
+		response *domain.AnalysisResponse
+		want     bool
+	}{
+		{"confident ai", response("ai", "out_of_memory", domain.SeverityHigh, 0.9), true},
+		{"unsure ai", response("ai", "out_of_memory", domain.SeverityHigh, 0.5), false},
+		{"rule ignores confidence", response("rules:oom_killed", "out_of_memory", domain.SeverityHigh, 0), true},
+		{"below severity", response("ai", "out_of_memory", domain.SeverityMedium, 0.9), false},
+		{"other error type", response("ai", "disk_space_full", domain.SeverityHigh, 0.9), false},
+		{"other source", response("rules_fallback:oom_killed", "out_of_memory", domain.SeverityHigh, 0), false},
+		{"failed", &domain.AnalysisResponse{Success: false}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conditions.Matches(tt.response); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscalator_Escalate(t *testing.T) {
+	first := &fakeSink{}
+	failing := &fakeSink{err: errors.New("unavailable")}
+	e := NewEscalator([]Sink{first, failing}, Conditions{
+		MinSeverity: domain.SeverityHigh,
+		ErrorTypes:  []string{"OOMKilled"},
+	}, zap.NewNop())
+	ctx := context.Background()
+
+	matched, err := e.Escalate(ctx, "fp", response("ai", "out_of_memory", domain.SeverityHigh, 0.9))
+	if !matched {
+		t.Fatal("Escalate() did not match an aliased error type")
+	}
+	if err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("Escalate() error = %v, want the failing sink's error", err)
+	}
+	if len(first.alerts) != 1 || len(failing.alerts) != 1 {
+		t.Fatalf("alerts = %d and %d, want 1 each", len(first.alerts), len(failing.alerts))
+	}
+	alert := first.alerts[0]
+	if alert.DedupKey != "ai-devops-fp" || len(alert.SuggestedActions) != maxAlertActions {
+		t.Errorf("alert = %+v", alert)
+	}
+
+	if matched, _ := e.Escalate(ctx, "fp", response("ai", "out_of_memory", domain.SeverityLow, 0.9)); matched {
+		t.Error("Escalate() matched a Low severity analysis")
+	}
+
+	var nilEscalator *Escalator
+	if nilEscalator.Wants(response("ai", "out_of_memory", domain.SeverityHigh, 1)) {
+		t.Error("nil Escalator wants to escalate")
+	}
+}
+
+func TestPagerDutySink(t *testing.T) {
+	var event pagerDutyEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/enqueue" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&event)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status": "success", "dedup_key": "ai-devops-fp"}`))
+	}))
+	defer server.Close()
+
+	sink := NewPagerDutySink(server.URL+"/", "routing", server.Client())
+	alert := newAlert("fp", response("ai", "out_of_memory", domain.SeverityHigh, 0.9))
+	if err := sink.Trigger(context.Background(), alert); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+
+	if event.RoutingKey != "routing" || event.EventAction != "trigger" || event.DedupKey != "ai-devops-fp" {
+		t.Errorf("event = %+v", event)
+	}
+	if event.Payload.Severity != "critical" || event.Payload.Class != "out_of_memory" {
+		t.Errorf("payload = %+v", event.Payload)
+	}
+	if event.Payload.Summary != "[High] out_of_memory: The container exceeded its memory limit." {
+		t.Errorf("summary = %q", event.Payload.Summary)
+	}
+}
+
+func TestOpsgenieSink(t *testing.T) {
+	var created opsgenieAlert
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/alerts" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"result": "Request will be processed"}`))
+	}))
+	defer server.Close()
+
+	sink := NewOpsgenieSink(server.URL, "key", server.Client())
+	alert := newAlert("fp", response("ai", "out_of_memory", domain.SeverityHigh, 0.9))
+	alert.Summary = strings.Repeat("é", 100)
+	if err := sink.Trigger(context.Background(), alert); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+
+	if auth != "GenieKey key" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if created.Alias != "ai-devops-fp" || created.Priority != "P1" {
+		t.Errorf("alert = %+v", created)
+	}
+	if len(created.Message) > maxOpsgenieMessage || !strings.HasSuffix(created.Message, "...") {
+		t.Errorf("message not truncated to %d bytes: %q", maxOpsgenieMessage, created.Message)
+	}
+	if !strings.Contains(created.Description, "Suggested actions:\n- a") {
+		t.Errorf("description = %q", created.Description)
+	}
+
+	server.Close()
+	if err := sink.Trigger(context.Background(), alert); err == nil {
+		t.Error("Trigger() error = nil for an unreachable server")
+	}
+}
@@ -0,0 +1,120 @@
+// Package escalate pages on-call responders (PagerDuty, Opsgenie) for
+// analyses matching operator-defined conditions.
+package escalate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// DefaultOpsgenieURL is the Opsgenie API base URL (EU accounts use
+// "https://api.eu.opsgenie.com").
+const DefaultOpsgenieURL = "https://api.opsgenie.com"
+
+// Opsgenie field limits.
+const (
+	maxOpsgenieMessage     = 130
+	maxOpsgenieDescription = 15000
+)
+
+// OpsgenieSink creates Opsgenie alerts. Repeats of a failure share an
+// alias, which Opsgenie deduplicates into the open alert.
+type OpsgenieSink struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewOpsgenieSink creates a sink authenticating with an API integration
+// key. baseURL is usually DefaultOpsgenieURL.
+func NewOpsgenieSink(baseURL, apiKey string, httpClient *http.Client) *OpsgenieSink {
+	return &OpsgenieSink{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: httpClient,
+	}
+}
+
+// opsgenieAlert is the create alert request.
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// Name implements Sink.
+func (s *OpsgenieSink) Name() string { return "opsgenie" }
+
+// Trigger implements Sink.
+func (s *OpsgenieSink) Trigger(ctx context.Context, alert *Alert) error {
+	var description strings.Builder
+	description.WriteString(alert.RootCause)
+	if len(alert.SuggestedActions) > 0 {
+		description.WriteString("\n\nSuggested actions:")
+		for _, action := range alert.SuggestedActions {
+			description.WriteString("\n- " + action)
+		}
+	}
+	details := map[string]string{
+		"error_type": alert.ErrorType,
+		"source":     alert.Source,
+	}
+	if alert.AnalysisID != "" {
+		details["analysis_id"] = alert.AnalysisID
+	}
+
+	body, err := json.Marshal(opsgenieAlert{
+		Message:     truncate(alert.Summary, maxOpsgenieMessage),
+		Alias:       alert.DedupKey,
+		Description: truncate(description.String(), maxOpsgenieDescription),
+		Priority:    opsgeniePriority(alert.Severity),
+		Source:      "ai-devops",
+		Tags:        []string{alert.ErrorType},
+		Details:     details,
+	})
+	if err != nil {
+		return fmt.Errorf("encode opsgenie alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v2/alerts", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create opsgenie request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+s.apiKey)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("opsgenie create alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("opsgenie create alert: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// opsgeniePriority maps a severity onto Opsgenie priorities.
+func opsgeniePriority(severity domain.Severity) string {
+	switch severity {
+	case domain.SeverityHigh:
+		return "P1"
+	case domain.SeverityMedium:
+		return "P3"
+	default:
+		return "P5"
+	}
+}
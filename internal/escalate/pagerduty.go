@@ -0,0 +1,121 @@
+// Package escalate pages on-call responders (PagerDuty, Opsgenie) for
+// analyses matching operator-defined conditions.
+package escalate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 base URL.
+const DefaultPagerDutyURL = "https://events.pagerduty.com"
+
+// maxPagerDutySummary is the Events API limit on the summary length.
+const maxPagerDutySummary = 1024
+
+// PagerDutySink triggers PagerDuty incidents through an Events API v2
+// integration. Repeats of a failure share a dedup key and are grouped
+// into the open incident.
+type PagerDutySink struct {
+	baseURL    string
+	routingKey string
+	httpClient *http.Client
+}
+
+// NewPagerDutySink creates a sink for the service integration with
+// routingKey. baseURL is usually DefaultPagerDutyURL.
+func NewPagerDutySink(baseURL, routingKey string, httpClient *http.Client) *PagerDutySink {
+	return &PagerDutySink{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		routingKey: routingKey,
+		httpClient: httpClient,
+	}
+}
+
+// pagerDutyEvent is an Events API v2 event.
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+// pagerDutyPayload is the event payload.
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// Name implements Sink.
+func (s *PagerDutySink) Name() string { return "pagerduty" }
+
+// Trigger implements Sink.
+func (s *PagerDutySink) Trigger(ctx context.Context, alert *Alert) error {
+	details := map[string]any{
+		"root_cause": alert.RootCause,
+		"source":     alert.Source,
+	}
+	if alert.AnalysisID != "" {
+		details["analysis_id"] = alert.AnalysisID
+	}
+	if len(alert.SuggestedActions) > 0 {
+		details["suggested_actions"] = alert.SuggestedActions
+	}
+	body, err := json.Marshal(pagerDutyEvent{
+		RoutingKey:  s.routingKey,
+		EventAction: "trigger",
+		DedupKey:    alert.DedupKey,
+		Payload: pagerDutyPayload{
+			Summary:       truncate(alert.Summary, maxPagerDutySummary),
+			Source:        "ai-devops",
+			Severity:      pagerDutySeverity(alert.Severity),
+			Class:         alert.ErrorType,
+			CustomDetails: details,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("encode pagerduty event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v2/enqueue", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create pagerduty request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("pagerduty enqueue: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("pagerduty enqueue: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// pagerDutySeverity maps a severity onto the Events API severities.
+func pagerDutySeverity(severity domain.Severity) string {
+	switch severity {
+	case domain.SeverityHigh:
+		return "critical"
+	case domain.SeverityMedium:
+		return "error"
+	case domain.SeverityLow:
+		return "warning"
+	default:
+		return "info"
+	}
+}
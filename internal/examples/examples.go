@@ -0,0 +1,69 @@
+// Package examples provides curated sample logs paired with the analyses the
+// service returns for them, so clients can be built without calling the AI.
+package examples
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Example is a sample analysis request and its expected response.
+type Example struct {
+	// ID is a stable, URL-safe identifier.
+	ID string `json:"id"`
+
+	// Title and Description explain what the example demonstrates.
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	// Request is the body to send to POST /api/v1/analyze.
+	Request domain.AnalysisRequest `json:"request"`
+
+	// Expected is the response the service gives for Request. Rule-sourced
+	// results are exact; AI-sourced results are representative.
+	Expected Expected `json:"expected"`
+}
+
+// Expected is the stable part of an AnalysisResponse: it leaves out IDs,
+// timestamps and usage, which vary per call.
+type Expected struct {
+	Success bool                   `json:"success"`
+	Source  string                 `json:"source,omitempty"`
+	Result  *domain.AnalysisResult `json:"result,omitempty"`
+	Error   *domain.ErrorDetail    `json:"error,omitempty"`
+}
+
+// Load parses the embedded fixtures, ordered by file name.
+func Load() ([]*Example, error) {
+	entries, err := fixtures.ReadDir("fixtures")
+	if err != nil {
+		return nil, fmt.Errorf("list examples: %w", err)
+	}
+
+	examples := make([]*Example, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		data, err := fixtures.ReadFile(path.Join("fixtures", name))
+		if err != nil {
+			return nil, fmt.Errorf("read example %s: %w", name, err)
+		}
+		var example Example
+		if err := json.Unmarshal(data, &example); err != nil {
+			return nil, fmt.Errorf("parse example %s: %w", name, err)
+		}
+		if example.ID == "" || seen[example.ID] {
+			return nil, fmt.Errorf("example %s: missing or duplicate id %q", name, example.ID)
+		}
+		seen[example.ID] = true
+		examples = append(examples, &example)
+	}
+	return examples, nil
+}
@@ -0,0 +1,68 @@
+// Package examples provides unit tests for the embedded example fixtures.
+package examples
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/pkg/analyzer"
+)
+
+// TestExamples_MatchPipeline runs every example through the real pipeline
+// (with the mock AI) so fixtures cannot drift from the rules they document.
+func TestExamples_MatchPipeline(t *testing.T) {
+	examples, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(examples) == 0 {
+		t.Fatal("expected embedded examples")
+	}
+
+	a, err := analyzer.New(analyzer.WithMockAI())
+	if err != nil {
+		t.Fatalf("analyzer.New() error = %v", err)
+	}
+
+	for _, example := range examples {
+		t.Run(example.ID, func(t *testing.T) {
+			req := example.Request
+			resp, err := a.Analyze(context.Background(), &req)
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			want := example.Expected
+			if resp.Success != want.Success {
+				t.Fatalf("success = %v, want %v (error %+v)", resp.Success, want.Success, resp.Error)
+			}
+			if want.Error != nil {
+				if resp.Error == nil || *resp.Error != *want.Error {
+					t.Errorf("error = %+v, want %+v", resp.Error, want.Error)
+				}
+				return
+			}
+
+			switch {
+			case strings.HasPrefix(want.Source, "rules:"):
+				if resp.Source != want.Source {
+					t.Fatalf("source = %q, want %q", resp.Source, want.Source)
+				}
+				if !reflect.DeepEqual(resp.Result, want.Result) {
+					t.Errorf("result = %+v, want %+v", resp.Result, want.Result)
+				}
+			case want.Source == "ai":
+				// AI answers vary; only check that no rule claimed the log.
+				if resp.Source != "ai" {
+					t.Errorf("source = %q, want ai", resp.Source)
+				}
+				if want.Result == nil || want.Result.ErrorType == "" {
+					t.Error("AI example should include a representative result")
+				}
+			default:
+				t.Errorf("unexpected expected source %q", want.Source)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+// Package experiment runs A/B experiments on the AI analysis: traffic is
+// split between variants that differ in prompt, model or temperature, every
+// analysis is tagged with its variant, and the variants are compared by
+// validation failures, feedback and latency.
+package experiment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/store"
+)
+
+// reportPageSize is the page size used when scanning stored analyses.
+const reportPageSize = 200
+
+// VariantConfig defines one arm of the experiment. Unset fields keep the
+// service's configuration.
+type VariantConfig struct {
+	// Name identifies the variant in stored analyses and stats.
+	Name string `json:"name"`
+
+	// Weight is the variant's relative share of the traffic.
+	Weight float64 `json:"weight"`
+
+	// Model overrides AI_MODEL.
+	Model string `json:"model,omitempty"`
+
+	// Temperature overrides AI_TEMPERATURE.
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// SystemPromptFile is a text file replacing the default system prompt.
+	// Specialized domain prompts are not used with a replaced prompt.
+	SystemPromptFile string `json:"system_prompt_file,omitempty"`
+}
+
+// Overrides reports whether the variant changes the service's AI settings.
+// A variant without overrides is the control and uses the service's client.
+func (v VariantConfig) Overrides() bool {
+	return v.Model != "" || v.Temperature != nil || v.SystemPromptFile != ""
+}
+
+// Config is the JSON experiment file.
+type Config struct {
+	// Name identifies the experiment in logs and stats.
+	Name string `json:"name"`
+
+	// Variants share the AI traffic in proportion to their weights.
+	Variants []VariantConfig `json:"variants"`
+}
+
+// LoadConfig reads and validates a JSON experiment file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read experiment: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse experiment: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks that the experiment has uniquely named variants with
+// non-negative weights, at least one of them positive.
+func (c *Config) Validate() error {
+	if len(c.Variants) < 2 {
+		return fmt.Errorf("%w: experiment needs at least two variants", domain.ErrInvalidConfig)
+	}
+	seen := make(map[string]bool)
+	total := 0.0
+	for i, v := range c.Variants {
+		if v.Name == "" {
+			return fmt.Errorf("%w: experiment variant %d has no name", domain.ErrInvalidConfig, i+1)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("%w: duplicate experiment variant %q", domain.ErrInvalidConfig, v.Name)
+		}
+		seen[v.Name] = true
+		if v.Weight < 0 {
+			return fmt.Errorf("%w: experiment variant %q has a negative weight", domain.ErrInvalidConfig, v.Name)
+		}
+		if v.Temperature != nil && (*v.Temperature < 0 || *v.Temperature > 2) {
+			return fmt.Errorf("%w: experiment variant %q: temperature must be between 0 and 2", domain.ErrInvalidConfig, v.Name)
+		}
+		total += v.Weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("%w: experiment variants have no weight", domain.ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Variant is an arm of a running experiment.
+type Variant struct {
+	// Name identifies the variant.
+	Name string
+
+	// Client analyzes the variant's share of the traffic.
+	Client ai.Client
+
+	// PromptVersion identifies the variant's prompt (see ai.PromptVersion).
+	PromptVersion string
+
+	weight float64
+
+	requests           atomic.Uint64
+	failures           atomic.Uint64
+	validationFailures atomic.Uint64
+	latencyNanos       atomic.Int64
+}
+
+// Record counts an AI call made for the variant.
+func (v *Variant) Record(latency time.Duration, err error) {
+	v.requests.Add(1)
+	v.latencyNanos.Add(int64(latency))
+	if err != nil {
+		v.failures.Add(1)
+		if errors.Is(err, domain.ErrInvalidAIResponse) {
+			v.validationFailures.Add(1)
+		}
+	}
+}
+
+// Experiment assigns AI calls to variants at random, in proportion to
+// their weights.
+type Experiment struct {
+	name     string
+	variants []*Variant
+	total    float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// VariantClient builds the AI client and prompt version of a variant.
+type VariantClient func(VariantConfig) (ai.Client, string, error)
+
+// New creates an experiment from a validated cfg, building each variant's
+// client with build.
+func New(cfg *Config, build VariantClient) (*Experiment, error) {
+	e := &Experiment{
+		name: cfg.Name,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, vc := range cfg.Variants {
+		client, promptVersion, err := build(vc)
+		if err != nil {
+			return nil, fmt.Errorf("experiment variant %q: %w", vc.Name, err)
+		}
+		e.variants = append(e.variants, &Variant{
+			Name:          vc.Name,
+			Client:        client,
+			PromptVersion: promptVersion,
+			weight:        vc.Weight,
+		})
+		e.total += vc.Weight
+	}
+	return e, nil
+}
+
+// Name returns the experiment's name.
+func (e *Experiment) Name() string {
+	return e.name
+}
+
+// Assign picks the variant of an AI call. It returns nil on a nil
+// experiment.
+func (e *Experiment) Assign() *Variant {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	n := e.rand.Float64() * e.total
+	e.mu.Unlock()
+
+	for _, v := range e.variants {
+		if n < v.weight {
+			return v
+		}
+		n -= v.weight
+	}
+	// Rounding can leave n just above the last weight
+	for i := len(e.variants) - 1; ; i-- {
+		if e.variants[i].weight > 0 {
+			return e.variants[i]
+		}
+	}
+}
+
+type variantKey struct{}
+
+// WithVariant returns a context carrying the variant of the AI call.
+func WithVariant(ctx context.Context, v *Variant) context.Context {
+	return context.WithValue(ctx, variantKey{}, v)
+}
+
+// VariantFromContext returns the variant carried by ctx, or nil.
+func VariantFromContext(ctx context.Context) *Variant {
+	v, _ := ctx.Value(variantKey{}).(*Variant)
+	return v
+}
+
+// VariantStats compares a variant with the others. Request counts and
+// latency cover AI calls since the service started; feedback covers the
+// stored analyses tagged with the variant.
+type VariantStats struct {
+	Name                  string  `json:"name"`
+	PromptVersion         string  `json:"prompt_version,omitempty"`
+	Requests              uint64  `json:"requests"`
+	Failures              uint64  `json:"failures"`
+	ValidationFailures    uint64  `json:"validation_failures"`
+	ValidationFailureRate float64 `json:"validation_failure_rate"`
+	AvgLatencyMS          float64 `json:"avg_latency_ms"`
+	Analyses              int     `json:"analyses"`
+	HelpfulFeedback       int     `json:"helpful_feedback"`
+	UnhelpfulFeedback     int     `json:"unhelpful_feedback"`
+
+	// FeedbackScore is the fraction of feedback marked helpful; zero
+	// without feedback.
+	FeedbackScore float64 `json:"feedback_score"`
+}
+
+// Report compares the variants, reading their feedback from s, which may
+// be nil.
+func (e *Experiment) Report(ctx context.Context, s store.Store) ([]VariantStats, error) {
+	stats := make([]VariantStats, len(e.variants))
+	index := make(map[string]int, len(e.variants))
+	for i, v := range e.variants {
+		index[v.Name] = i
+		stats[i] = VariantStats{
+			Name:               v.Name,
+			PromptVersion:      v.PromptVersion,
+			Requests:           v.requests.Load(),
+			Failures:           v.failures.Load(),
+			ValidationFailures: v.validationFailures.Load(),
+		}
+		if requests := stats[i].Requests; requests > 0 {
+			stats[i].ValidationFailureRate = float64(stats[i].ValidationFailures) / float64(requests)
+			stats[i].AvgLatencyMS = float64(v.latencyNanos.Load()) / float64(requests) / float64(time.Millisecond)
+		}
+	}
+	if s == nil {
+		return stats, nil
+	}
+
+	for offset := 0; ; offset += reportPageSize {
+		records, err := s.ListAnalyses(ctx, store.ListOptions{Limit: reportPageSize, Offset: offset})
+		if err != nil {
+			return nil, fmt.Errorf("list analyses: %w", err)
+		}
+		for _, record := range records {
+			if record.Metadata == nil {
+				continue
+			}
+			i, ok := index[record.Metadata.Variant]
+			if !ok {
+				continue
+			}
+			stats[i].Analyses++
+			feedback, err := s.ListFeedback(ctx, record.ID)
+			if err != nil {
+				return nil, fmt.Errorf("list feedback: %w", err)
+			}
+			for _, f := range feedback {
+				if f.Helpful {
+					stats[i].HelpfulFeedback++
+				} else {
+					stats[i].UnhelpfulFeedback++
+				}
+			}
+		}
+		if len(records) < reportPageSize {
+			break
+		}
+	}
+
+	for i := range stats {
+		if rated := stats[i].HelpfulFeedback + stats[i].UnhelpfulFeedback; rated > 0 {
+			stats[i].FeedbackScore = float64(stats[i].HelpfulFeedback) / float64(rated)
+		}
+	}
+	return stats, nil
+}
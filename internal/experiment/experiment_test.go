@@ -0,0 +1,140 @@
+// Package experiment provides unit tests for A/B experiments.
+package experiment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/store"
+	"go.uber.org/zap"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	hot := 3.0
+
+	tests := []struct {
+		name     string
+		variants []VariantConfig
+		wantErr  bool
+	}{
+		{"valid", []VariantConfig{{Name: "control", Weight: 1}, {Name: "gpt-4o", Weight: 1, Model: "gpt-4o"}}, false},
+		{"one zero weight", []VariantConfig{{Name: "control", Weight: 1}, {Name: "paused", Weight: 0}}, false},
+		{"single variant", []VariantConfig{{Name: "control", Weight: 1}}, true},
+		{"unnamed", []VariantConfig{{Name: "control", Weight: 1}, {Weight: 1}}, true},
+		{"duplicate", []VariantConfig{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}, true},
+		{"negative weight", []VariantConfig{{Name: "a", Weight: 1}, {Name: "b", Weight: -1}}, true},
+		{"no weight", []VariantConfig{{Name: "a"}, {Name: "b"}}, true},
+		{"temperature out of range", []VariantConfig{{Name: "a", Weight: 1}, {Name: "b", Weight: 1, Temperature: &hot}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{Variants: tt.variants}).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidConfig) {
+				t.Errorf("Validate() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+// newTestExperiment builds an experiment whose variants use mock clients.
+func newTestExperiment(t *testing.T, variants ...VariantConfig) *Experiment {
+	t.Helper()
+	e, err := New(&Config{Name: "test", Variants: variants}, func(v VariantConfig) (ai.Client, string, error) {
+		return ai.NewMockClient(zap.NewNop()), "prompt-" + v.Name, nil
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return e
+}
+
+func TestExperiment_Assign(t *testing.T) {
+	e := newTestExperiment(t,
+		VariantConfig{Name: "a", Weight: 3},
+		VariantConfig{Name: "paused", Weight: 0},
+		VariantConfig{Name: "b", Weight: 1},
+	)
+
+	counts := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		counts[e.Assign().Name]++
+	}
+	if counts["paused"] != 0 {
+		t.Errorf("zero-weight variant assigned %d times", counts["paused"])
+	}
+	if share := float64(counts["a"]) / 4000; share < 0.7 || share > 0.8 {
+		t.Errorf("variant a share = %.2f, want about 0.75", share)
+	}
+
+	var none *Experiment
+	if v := none.Assign(); v != nil {
+		t.Errorf("nil experiment assigned %q", v.Name)
+	}
+}
+
+func TestExperiment_Report(t *testing.T) {
+	ctx := context.Background()
+	e := newTestExperiment(t,
+		VariantConfig{Name: "control", Weight: 1},
+		VariantConfig{Name: "candidate", Weight: 1},
+	)
+	control, candidate := e.variants[0], e.variants[1]
+
+	control.Record(100*time.Millisecond, nil)
+	control.Record(300*time.Millisecond, domain.WrapError("extract_json", domain.ErrInvalidAIResponse, false))
+	candidate.Record(50*time.Millisecond, domain.WrapError("ai_request", domain.ErrRateLimited, true))
+
+	s := store.NewMemoryStore(100)
+	save := func(variant string, helpful ...bool) {
+		record := &domain.AnalysisRecord{
+			Result:   &domain.AnalysisResult{ErrorType: "x"},
+			Metadata: &domain.ResponseMetadata{Variant: variant},
+		}
+		if err := s.SaveAnalysis(ctx, record); err != nil {
+			t.Fatalf("SaveAnalysis() error = %v", err)
+		}
+		for _, h := range helpful {
+			if err := s.SaveFeedback(ctx, &domain.Feedback{AnalysisID: record.ID, Helpful: h}); err != nil {
+				t.Fatalf("SaveFeedback() error = %v", err)
+			}
+		}
+	}
+	save("control", true, false)
+	save("control", true)
+	save("candidate")
+	save("retired", true)
+
+	stats, err := e.Report(ctx, s)
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	want := []VariantStats{
+		{
+			Name: "control", PromptVersion: "prompt-control",
+			Requests: 2, Failures: 1, ValidationFailures: 1, ValidationFailureRate: 0.5, AvgLatencyMS: 200,
+			Analyses: 2, HelpfulFeedback: 2, UnhelpfulFeedback: 1, FeedbackScore: 2.0 / 3,
+		},
+		{
+			Name: "candidate", PromptVersion: "prompt-candidate",
+			Requests: 1, Failures: 1, AvgLatencyMS: 50,
+			Analyses: 1,
+		},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("Report() returned %d variants, want %d", len(stats), len(want))
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("Report()[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+}
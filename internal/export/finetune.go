@@ -0,0 +1,159 @@
+// Package export converts stored analyses into datasets for offline use.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/store"
+)
+
+// exportPageSize is the number of analyses read from the store per page.
+const exportPageSize = 200
+
+// ChatMessage is one message of a fine-tuning example.
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatExample is one JSONL line in the OpenAI chat fine-tuning format.
+type ChatExample struct {
+	Messages []ChatMessage `json:"messages"`
+}
+
+// Options filters the exported analyses.
+type Options struct {
+	// Since, if set, only exports analyses created at or after this time.
+	Since time.Time
+}
+
+// Report summarizes an export.
+type Report struct {
+	// Scanned is the number of analyses examined.
+	Scanned int `json:"scanned"`
+
+	// Exported is the number of examples written.
+	Exported int `json:"exported"`
+
+	// SkippedNoFeedback counts analyses without accepting feedback.
+	SkippedNoFeedback int `json:"skipped_no_feedback"`
+
+	// SkippedStale counts analyses marked stale by a rule or prompt change.
+	SkippedStale int `json:"skipped_stale"`
+
+	// SkippedPII counts accepted analyses withheld because they contain
+	// personal data, by kind.
+	SkippedPII map[string]int `json:"skipped_pii"`
+}
+
+// FineTuneExporter writes accepted analyses as chat fine-tuning examples.
+// The prompts are built with the production PromptBuilder so a model tuned
+// on the dataset sees the same input format it will receive in service.
+type FineTuneExporter struct {
+	store    store.Store
+	prompter ai.PromptBuilder
+}
+
+// NewFineTuneExporter creates an exporter reading from s.
+func NewFineTuneExporter(s store.Store, prompter ai.PromptBuilder) *FineTuneExporter {
+	return &FineTuneExporter{
+		store:    s,
+		prompter: prompter,
+	}
+}
+
+// Export writes one JSON example per line to w. An analysis is accepted when
+// it has at least one helpful and no unhelpful feedback; stale analyses and
+// accepted analyses containing personal data are skipped and counted in the
+// report.
+func (e *FineTuneExporter) Export(ctx context.Context, w io.Writer, opts Options) (*Report, error) {
+	report := &Report{SkippedPII: make(map[string]int)}
+	enc := json.NewEncoder(w)
+
+	for offset := 0; ; offset += exportPageSize {
+		records, err := e.store.ListAnalyses(ctx, store.ListOptions{
+			Limit:  exportPageSize,
+			Offset: offset,
+			Since:  opts.Since,
+		})
+		if err != nil {
+			return report, fmt.Errorf("list analyses: %w", err)
+		}
+
+		for _, record := range records {
+			report.Scanned++
+			if record.Stale {
+				report.SkippedStale++
+				continue
+			}
+
+			accepted, err := store.Accepted(ctx, e.store, record.ID)
+			if err != nil {
+				return report, err
+			}
+			if !accepted || record.Result == nil {
+				report.SkippedNoFeedback++
+				continue
+			}
+
+			example, err := e.example(record)
+			if err != nil {
+				return report, err
+			}
+
+			if kinds := DetectPII(exampleText(example)); len(kinds) > 0 {
+				for _, kind := range kinds {
+					report.SkippedPII[kind]++
+				}
+				continue
+			}
+
+			if err := enc.Encode(example); err != nil {
+				return report, fmt.Errorf("write example: %w", err)
+			}
+			report.Exported++
+		}
+
+		if len(records) < exportPageSize {
+			return report, nil
+		}
+	}
+}
+
+// example builds the chat example for a record.
+func (e *FineTuneExporter) example(record *domain.AnalysisRecord) (*ChatExample, error) {
+	result := *record.Result
+	result.Usage = nil
+
+	answer, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshal result: %w", err)
+	}
+
+	return &ChatExample{
+		Messages: []ChatMessage{
+			{Role: "system", Content: e.prompter.BuildSystemPrompt()},
+			{Role: "user", Content: e.prompter.BuildUserPrompt(record.Log)},
+			{Role: "assistant", Content: string(answer)},
+		},
+	}, nil
+}
+
+// exampleText returns the user and assistant content checked for PII.
+// The system prompt is fixed and not scanned.
+func exampleText(example *ChatExample) string {
+	var parts []string
+	for _, m := range example.Messages {
+		if m.Role != "system" {
+			parts = append(parts, m.Content)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,86 @@
+// Package export provides unit tests for the fine-tuning dataset export.
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/store"
+)
+
+func TestFineTuneExporter_Export(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore(0)
+
+	save := func(log string, feedback ...bool) {
+		record := &domain.AnalysisRecord{
+			Log:    log,
+			Source: "ai",
+			Result: &domain.AnalysisResult{ErrorType: "oom", Severity: domain.SeverityHigh, RootCause: "out of memory"},
+		}
+		if err := s.SaveAnalysis(ctx, record); err != nil {
+			t.Fatalf("SaveAnalysis() error: %v", err)
+		}
+		for _, helpful := range feedback {
+			if err := s.SaveFeedback(ctx, &domain.Feedback{AnalysisID: record.ID, Helpful: helpful}); err != nil {
+				t.Fatalf("SaveFeedback() error: %v", err)
+			}
+		}
+	}
+
+	save("container killed: OOMKilled", true)
+	save("no feedback")
+	save("mixed feedback", true, false)
+	save("mail alice@example.com failed", true)
+
+	prompter, _ := ai.NewDefaultPromptBuilder()
+	var buf bytes.Buffer
+	report, err := NewFineTuneExporter(s, prompter).Export(ctx, &buf, Options{})
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+
+	if report.Scanned != 4 || report.Exported != 1 || report.SkippedNoFeedback != 2 || report.SkippedPII["email"] != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1", len(lines))
+	}
+	var example ChatExample
+	if err := json.Unmarshal([]byte(lines[0]), &example); err != nil {
+		t.Fatalf("invalid JSONL line: %v", err)
+	}
+	if len(example.Messages) != 3 || !strings.Contains(example.Messages[1].Content, "OOMKilled") ||
+		!strings.Contains(example.Messages[2].Content, `"error_type":"oom"`) {
+		t.Errorf("unexpected example: %+v", example)
+	}
+}
+
+func TestDetectPII(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"clean", "build 1234567 failed at 10.0.0.12", nil},
+		{"email", "contact ops@example.org", []string{"email"}},
+		{"public ip", "connect to 8.8.8.8 refused", []string{"ipv4"}},
+		{"card", "charge 4111 1111 1111 1111 declined", []string{"credit_card"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectPII(tt.text)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("DetectPII() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
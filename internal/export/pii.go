@@ -0,0 +1,95 @@
+// Package export converts stored analyses into datasets for offline use.
+package export
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// piiPatterns detect personal data the sanitizer does not mask. Secret
+// masking happens at ingestion; these checks guard what leaves the service.
+var piiPatterns = map[string]*regexp.Regexp{
+	"email":       regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	"ipv4":        regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+	"phone":       regexp.MustCompile(`\+\d{1,3}[ -]?\(?\d{2,4}\)?[ -]?\d{3,4}[ -]?\d{3,4}\b`),
+	"credit_card": regexp.MustCompile(`\b(?:\d[ -]?){13,19}\b`),
+}
+
+// DetectPII returns the sorted kinds of personal data found in text.
+func DetectPII(text string) []string {
+	var kinds []string
+	for kind, pattern := range piiPatterns {
+		matches := pattern.FindAllString(text, -1)
+		if kind == "credit_card" {
+			matches = filterLuhn(matches)
+		}
+		if kind == "ipv4" {
+			matches = filterPublicIPs(matches)
+		}
+		if len(matches) > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// filterLuhn keeps candidate card numbers that pass the Luhn checksum, which
+// rules out most build numbers and timestamps.
+func filterLuhn(candidates []string) []string {
+	var valid []string
+	for _, candidate := range candidates {
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, candidate)
+		if len(digits) >= 13 && luhn(digits) {
+			valid = append(valid, candidate)
+		}
+	}
+	return valid
+}
+
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// filterPublicIPs drops loopback, private and link-local addresses, which are
+// infrastructure details rather than personal data.
+func filterPublicIPs(candidates []string) []string {
+	var public []string
+	for _, ip := range candidates {
+		switch {
+		case strings.HasPrefix(ip, "10."), strings.HasPrefix(ip, "127."),
+			strings.HasPrefix(ip, "192.168."), strings.HasPrefix(ip, "169.254."),
+			strings.HasPrefix(ip, "0."):
+			continue
+		case strings.HasPrefix(ip, "172."):
+			var second int
+			for _, c := range strings.Split(ip, ".")[1] {
+				second = second*10 + int(c-'0')
+			}
+			if second >= 16 && second <= 31 {
+				continue
+			}
+		}
+		public = append(public, ip)
+	}
+	return public
+}
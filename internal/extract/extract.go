@@ -0,0 +1,280 @@
+// Package extract pulls structured failure details out of raw logs: complete
+// stack traces (Java, Python, Go panics, Node), process exit codes and the
+// infrastructure-as-code resources that failed.
+package extract
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// StackTrace is a complete stack trace found in a log.
+type StackTrace struct {
+	// Language is the runtime that produced the trace: java, python, go or node.
+	Language string
+
+	// Header is the exception or panic line that starts (or, for Python,
+	// ends) the trace.
+	Header string
+
+	// RootCause is the innermost "Caused by" for chained Java exceptions and
+	// equals Header otherwise.
+	RootCause string
+
+	// Text is the whole trace, header included.
+	Text string
+
+	// Frames is the number of call frames in the trace.
+	Frames int
+}
+
+var (
+	pythonStartPattern = regexp.MustCompile(`^Traceback \(most recent call last\):`)
+	pythonFramePattern = regexp.MustCompile(`^\s+File "`)
+
+	goStartPattern = regexp.MustCompile(`^(panic|fatal error): `)
+	goLinePattern  = regexp.MustCompile(`^(goroutine \d+ \[|\[signal |created by |\t|\S+\(.*\)$)`)
+	goFramePattern = regexp.MustCompile(`^\t.*\.go:\d+`)
+
+	atFramePattern    = regexp.MustCompile(`^\s+at \S`)
+	causedByPrefix    = regexp.MustCompile(`^\s*Caused by: `)
+	moreFramesPattern = regexp.MustCompile(`^\s+\.\.\. \d+ more`)
+	javaFramePattern  = regexp.MustCompile(`\.(java|kt|scala|groovy):\d+\)|\((Native Method|Unknown Source)\)`)
+	javaThreadPrefix  = regexp.MustCompile(`^Exception in thread "[^"]*" `)
+	nodeFramePattern  = regexp.MustCompile(`\.(js|mjs|cjs|ts):\d+:\d+\)?$|\(node:|at node:`)
+
+	exitCodePattern = regexp.MustCompile(`(?i)(?:exit(?:ed)?\s+(?:with\s+)?(?:code|status)|non-zero\s+(?:exit\s+)?code|exit_?code)\s*[:=]?\s*(\d{1,3})\b`)
+
+	// Terraform names the resource of a diagnostic with "with <address>,"
+	// or, for configuration errors, `in resource "<type>" "<name>"`; drift
+	// notes use "# <address> has changed".
+	terraformAddress         = `(?:module\.[\w-]+(?:\[[^\]\s]+\])?\.)*(?:data\.)?[a-z][a-z0-9]*_[a-z0-9_]+\.[A-Za-z_][\w-]*(?:\[[^\]\s]+\])?`
+	terraformWithPattern     = regexp.MustCompile(`\bwith (` + terraformAddress + `),`)
+	terraformDriftPattern    = regexp.MustCompile(`# (` + terraformAddress + `) has (?:changed|been deleted)`)
+	terraformResourcePattern = regexp.MustCompile(`\bin resource "([a-z][a-z0-9]*_[a-z0-9_]+)" "([A-Za-z_][\w-]*)"`)
+
+	// Pulumi names resources by URN, and its diagnostics by
+	// "<type> (<name>):" lines.
+	pulumiURNPattern        = regexp.MustCompile(`urn:pulumi:[^\s"',]+`)
+	pulumiDiagnosticPattern = regexp.MustCompile(`(?m)^\s+([a-z0-9-]+:[\w/.-]*:[\w.]+) \(([^()\s]+)\):\s*$`)
+
+	// CloudFormation lists failed logical IDs after a stack failure, and
+	// CDK prints failed events as "CREATE_FAILED | <type> | <logical ID>".
+	cloudFormationFailedPattern = regexp.MustCompile(`The following resource\(s\) failed to (?:create|update|delete): \[([^\]]+)\]`)
+	cloudFormationEventPattern  = regexp.MustCompile(`\b(?:CREATE|UPDATE|DELETE)_FAILED\s*\|\s*AWS::\w+::[\w:]+\s*\|\s*([A-Za-z][\w/]*)`)
+)
+
+// maxResources bounds the resource addresses reported for a log.
+const maxResources = 20
+
+// StackTraces returns the complete stack traces in log, in order.
+func StackTraces(log string) []StackTrace {
+	lines := strings.Split(log, "\n")
+
+	var traces []StackTrace
+	for i := 0; i < len(lines); {
+		trace, next, ok := scanPython(lines, i)
+		if !ok {
+			trace, next, ok = scanGo(lines, i)
+		}
+		if !ok {
+			trace, next, ok = scanAtFrames(lines, i)
+		}
+		if !ok {
+			i++
+			continue
+		}
+		traces = append(traces, trace)
+		i = next
+	}
+	return traces
+}
+
+// scanPython reads a "Traceback (most recent call last):" block: indented
+// frames followed by the exception line.
+func scanPython(lines []string, i int) (StackTrace, int, bool) {
+	if !pythonStartPattern.MatchString(lines[i]) {
+		return StackTrace{}, i, false
+	}
+	trace := StackTrace{Language: "python"}
+	j := i + 1
+	for ; j < len(lines) && (strings.HasPrefix(lines[j], " ") || strings.HasPrefix(lines[j], "\t")); j++ {
+		if pythonFramePattern.MatchString(lines[j]) {
+			trace.Frames++
+		}
+	}
+	if j < len(lines) && strings.TrimSpace(lines[j]) != "" {
+		trace.Header = strings.TrimSpace(lines[j])
+		j++
+	}
+	trace.RootCause = trace.Header
+	trace.Text = strings.Join(lines[i:j], "\n")
+	return trace, j, trace.Frames > 0
+}
+
+// scanGo reads a Go panic or fatal error with its goroutine dumps.
+func scanGo(lines []string, i int) (StackTrace, int, bool) {
+	if !goStartPattern.MatchString(lines[i]) {
+		return StackTrace{}, i, false
+	}
+	trace := StackTrace{Language: "go", Header: strings.TrimSpace(lines[i])}
+	end := i + 1
+	for j := i + 1; j < len(lines); j++ {
+		line := lines[j]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !goLinePattern.MatchString(line) {
+			break
+		}
+		if goFramePattern.MatchString(line) {
+			trace.Frames++
+		}
+		end = j + 1
+	}
+	trace.RootCause = trace.Header
+	trace.Text = strings.Join(lines[i:end], "\n")
+	return trace, end, trace.Frames > 0
+}
+
+// scanAtFrames reads a Java or Node trace: a header line followed by
+// "at ..." frames, with "Caused by:" and "... N more" continuations.
+func scanAtFrames(lines []string, i int) (StackTrace, int, bool) {
+	if i+1 >= len(lines) || strings.TrimSpace(lines[i]) == "" ||
+		atFramePattern.MatchString(lines[i]) || !atFramePattern.MatchString(lines[i+1]) {
+		return StackTrace{}, i, false
+	}
+
+	header := strings.TrimSpace(javaThreadPrefix.ReplaceAllString(lines[i], ""))
+	trace := StackTrace{Header: header, RootCause: header}
+	java, node := 0, 0
+	j := i + 1
+scan:
+	for ; j < len(lines); j++ {
+		line := lines[j]
+		switch {
+		case atFramePattern.MatchString(line):
+			trace.Frames++
+			if javaFramePattern.MatchString(line) {
+				java++
+			} else if nodeFramePattern.MatchString(line) {
+				node++
+			}
+		case causedByPrefix.MatchString(line):
+			trace.RootCause = strings.TrimSpace(causedByPrefix.ReplaceAllString(line, ""))
+		case moreFramesPattern.MatchString(line):
+		default:
+			break scan
+		}
+	}
+
+	trace.Language = "java"
+	if node > java {
+		trace.Language = "node"
+	}
+	trace.Text = strings.Join(lines[i:j], "\n")
+	return trace, j, true
+}
+
+// ExitCodes returns the process exit codes reported in log, in order.
+func ExitCodes(log string) []int {
+	var codes []int
+	for _, match := range exitCodePattern.FindAllStringSubmatch(log, -1) {
+		if code, err := strconv.Atoi(match[1]); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Resources returns the infrastructure-as-code resources named in the
+// errors of log, in order of first appearance and at most maxResources:
+// Terraform addresses (module.vpc.aws_subnet.private[0]), Pulumi URNs or
+// "<type> (<name>)" and CloudFormation logical IDs.
+func Resources(log string) []string {
+	var resources []string
+	seen := make(map[string]bool)
+	add := func(resource string) {
+		resource = strings.TrimSpace(resource)
+		if resource == "" || seen[resource] || len(resources) == maxResources {
+			return
+		}
+		seen[resource] = true
+		resources = append(resources, resource)
+	}
+
+	for _, line := range strings.Split(log, "\n") {
+		for _, match := range terraformWithPattern.FindAllStringSubmatch(line, -1) {
+			add(match[1])
+		}
+		for _, match := range terraformDriftPattern.FindAllStringSubmatch(line, -1) {
+			add(match[1])
+		}
+		for _, match := range terraformResourcePattern.FindAllStringSubmatch(line, -1) {
+			add(match[1] + "." + match[2])
+		}
+		for _, urn := range pulumiURNPattern.FindAllString(line, -1) {
+			add(strings.TrimRight(urn, ":."))
+		}
+		if match := pulumiDiagnosticPattern.FindStringSubmatch(line); match != nil && match[1] != "pulumi:pulumi:Stack" {
+			add(match[1] + " (" + match[2] + ")")
+		}
+		for _, match := range cloudFormationFailedPattern.FindAllStringSubmatch(line, -1) {
+			for _, id := range strings.Split(match[1], ",") {
+				add(id)
+			}
+		}
+		for _, match := range cloudFormationEventPattern.FindAllStringSubmatch(line, -1) {
+			add(match[1])
+		}
+	}
+	return resources
+}
+
+// Metadata extracts the failure details of log, keyed by the
+// domain.Extracted* constants. Keys without a value are omitted; the result
+// is nil when nothing was found.
+func Metadata(log string) map[string]string {
+	metadata := make(map[string]string)
+
+	if traces := StackTraces(log); len(traces) > 0 {
+		// The last trace is usually the failure that ended the run.
+		last := traces[len(traces)-1]
+		metadata[domain.ExtractedStackTrace] = last.Text
+		metadata[domain.ExtractedStackLanguage] = last.Language
+		metadata[domain.ExtractedStackTraceCount] = strconv.Itoa(len(traces))
+		if last.RootCause != "" {
+			metadata[domain.ExtractedException] = last.RootCause
+		}
+	}
+
+	codes := ExitCodes(log)
+	for i := len(codes) - 1; i >= 0; i-- {
+		if codes[i] != 0 {
+			metadata[domain.ExtractedExitCode] = strconv.Itoa(codes[i])
+			break
+		}
+	}
+
+	if resources := Resources(log); len(resources) > 0 {
+		metadata[domain.ExtractedResources] = strings.Join(resources, ", ")
+	}
+
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+// Preprocess builds the PreprocessedLog for a request: the original and
+// sanitized logs plus the details extracted from the sanitized one.
+func Preprocess(original, sanitized string) *domain.PreprocessedLog {
+	return &domain.PreprocessedLog{
+		Original:  original,
+		Sanitized: sanitized,
+		Metadata:  Metadata(sanitized),
+	}
+}
@@ -0,0 +1,168 @@
+// Package extract provides unit tests for stack trace, exit code and resource
+// extraction.
+package extract
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+const javaLog = `[INFO] Running OrderServiceIT
+Exception in thread "main" java.lang.IllegalStateException: context failed
+	at org.springframework.context.Refresh.run(Refresh.java:120)
+	at com.example.App.main(App.java:12)
+Caused by: java.sql.SQLException: pool exhausted
+	at com.zaxxer.hikari.Pool.get(Pool.java:88)
+	... 2 more
+[ERROR] BUILD FAILURE`
+
+const pythonLog = `collecting tests
+Traceback (most recent call last):
+  File "/app/main.py", line 8, in <module>
+    run()
+  File "/app/main.py", line 5, in run
+    config["db"]
+KeyError: 'db'
+Error: Process completed with exit code 1.`
+
+const goLog = `starting server
+panic: runtime error: invalid memory address or nil pointer dereference
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x18 pc=0x6b2f1a]
+
+goroutine 57 [running]:
+example.com/billing.(*Service).Total(0x0)
+	/app/service.go:112 +0x3a
+main.main()
+	/app/main.go:20 +0x1c5
+exit status 2`
+
+const nodeLog = `> app@1.0.0 start
+TypeError: Cannot read properties of undefined (reading 'id')
+    at getUser (/app/src/users.js:14:21)
+    at process.processTicksAndRejections (node:internal/process/task_queues:95:5)
+npm ERR! code ELIFECYCLE`
+
+func TestStackTraces(t *testing.T) {
+	tests := []struct {
+		name      string
+		log       string
+		language  string
+		header    string
+		rootCause string
+		frames    int
+	}{
+		{"java with cause", javaLog, "java", "java.lang.IllegalStateException: context failed", "java.sql.SQLException: pool exhausted", 3},
+		{"python", pythonLog, "python", "KeyError: 'db'", "KeyError: 'db'", 2},
+		{"go panic", goLog, "go", "panic: runtime error: invalid memory address or nil pointer dereference", "panic: runtime error: invalid memory address or nil pointer dereference", 2},
+		{"node", nodeLog, "node", "TypeError: Cannot read properties of undefined (reading 'id')", "TypeError: Cannot read properties of undefined (reading 'id')", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traces := StackTraces(tt.log)
+			if len(traces) != 1 {
+				t.Fatalf("found %d traces, want 1: %+v", len(traces), traces)
+			}
+			got := traces[0]
+			if got.Language != tt.language || got.Header != tt.header || got.RootCause != tt.rootCause || got.Frames != tt.frames {
+				t.Errorf("trace = {%s %q %q %d}, want {%s %q %q %d}",
+					got.Language, got.Header, got.RootCause, got.Frames,
+					tt.language, tt.header, tt.rootCause, tt.frames)
+			}
+		})
+	}
+}
+
+func TestStackTraces_TextIsComplete(t *testing.T) {
+	traces := StackTraces(goLog)
+	if len(traces) != 1 {
+		t.Fatalf("found %d traces, want 1", len(traces))
+	}
+	want := goLog[len("starting server\n") : len(goLog)-len("\nexit status 2")]
+	if traces[0].Text != want {
+		t.Errorf("Text =\n%s\nwant\n%s", traces[0].Text, want)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	log := "Process completed with exit code 1.\n" +
+		"make: exit status 2\n" +
+		"container exited with code 137\n" +
+		"The command '/bin/sh -c npm ci' returned a non-zero code: 127\n" +
+		"ExitCode=0"
+	if got, want := ExitCodes(log), []int{1, 2, 137, 127, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExitCodes() = %v, want %v", got, want)
+	}
+}
+
+func TestResources(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want []string
+	}{
+		{
+			name: "terraform",
+			log: "│ Error: creating EC2 Instance: UnauthorizedOperation\n" +
+				"│\n" +
+				"│   with module.app.aws_instance.web[0],\n" +
+				"│   on modules/app/main.tf line 12, in resource \"aws_instance\" \"web\":\n" +
+				"│ Error: Unsupported argument\n" +
+				"│   with aws_s3_bucket.logs,\n" +
+				"│   with module.app.aws_instance.web[0],",
+			want: []string{"module.app.aws_instance.web[0]", "aws_instance.web", "aws_s3_bucket.logs"},
+		},
+		{
+			name: "terraform drift",
+			log:  "Note: Objects have changed outside of Terraform\n\n  # aws_security_group.api has changed",
+			want: []string{"aws_security_group.api"},
+		},
+		{
+			name: "pulumi",
+			log: "Diagnostics:\n" +
+				"  pulumi:pulumi:Stack (infra-dev):\n" +
+				"    error: update failed\n\n" +
+				"  aws:s3:Bucket (assets):\n" +
+				"    error: creating urn:pulumi:dev::infra::aws:s3/bucket:Bucket::assets: BucketAlreadyExists",
+			want: []string{"aws:s3:Bucket (assets)", "urn:pulumi:dev::infra::aws:s3/bucket:Bucket::assets"},
+		},
+		{
+			name: "cloudformation",
+			log: "ApiStack | 2/5 | 10:01:12 AM | CREATE_FAILED | AWS::IAM::Role | ApiRole (ApiRole1A2B3C) Resource handler returned message\n" +
+				"The following resource(s) failed to create: [ApiRole, ApiBucket]. Rollback requested by user.",
+			want: []string{"ApiRole", "ApiBucket"},
+		},
+		{
+			name: "none",
+			log:  "go test ./... failed with exit code 1 in main_test.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resources(tt.log); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resources() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	if got := Metadata("all good\nexit code 0"); got != nil {
+		t.Errorf("Metadata() = %v, want nil", got)
+	}
+
+	got := Metadata(pythonLog)
+	want := map[string]string{
+		domain.ExtractedStackTrace:      pythonLog[len("collecting tests\n") : len(pythonLog)-len("\nError: Process completed with exit code 1.")],
+		domain.ExtractedStackLanguage:   "python",
+		domain.ExtractedStackTraceCount: "1",
+		domain.ExtractedException:       "KeyError: 'db'",
+		domain.ExtractedExitCode:        "1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Metadata() = %v, want %v", got, want)
+	}
+}
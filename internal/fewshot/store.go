@@ -0,0 +1,342 @@
+// Package fewshot stores curated log→result pairs and selects the ones most
+// similar to a new log, so they can be shown to the AI as worked examples.
+package fewshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ai-devops/internal/cache"
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Example sources.
+const (
+	// SourceCurated marks examples added by an operator.
+	SourceCurated = "curated"
+
+	// SourceFeedback marks analyses added after accepting feedback.
+	SourceFeedback = "feedback"
+)
+
+// categoryOther groups error types outside the taxonomy.
+const categoryOther = "other"
+
+// Example is a sanitized log paired with a known good analysis.
+type Example struct {
+	// ID identifies the example. Examples fed from feedback use the
+	// analysis ID; curated examples get one derived from the log if empty.
+	ID string `json:"id"`
+
+	// Category is the taxonomy category of the result's error type.
+	Category string `json:"category"`
+
+	// Source is SourceCurated or SourceFeedback.
+	Source string `json:"source"`
+
+	// Log is the sanitized log.
+	Log string `json:"log"`
+
+	// Result is the accepted analysis of Log.
+	Result *domain.AnalysisResult `json:"result"`
+
+	// AddedAt is when the example was stored.
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Match is an example and its similarity to a log, between 0 and 1.
+type Match struct {
+	Example *Example `json:"example"`
+	Score   float64  `json:"score"`
+}
+
+// Config configures a Store.
+type Config struct {
+	// Path is the JSON file the examples are kept in. Empty keeps them in
+	// memory only.
+	Path string
+
+	// MaxPerCategory caps the examples kept per category; the oldest are
+	// dropped first. Zero means no cap.
+	MaxPerCategory int
+}
+
+// entry is a stored example with its precomputed tokens and fingerprint.
+type entry struct {
+	example     *Example
+	tokens      map[string]struct{}
+	fingerprint string
+}
+
+// Store is a file-backed set of examples. It is safe for concurrent use.
+type Store struct {
+	mu             sync.RWMutex
+	entries        []*entry
+	path           string
+	maxPerCategory int
+	logger         *zap.Logger
+}
+
+// NewStore creates a store and loads the examples saved at cfg.Path. A
+// missing file is not an error.
+func NewStore(cfg Config, logger *zap.Logger) (*Store, error) {
+	s := &Store{
+		path:           cfg.Path,
+		maxPerCategory: cfg.MaxPerCategory,
+		logger:         logger.Named("fewshot"),
+	}
+	if s.path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read examples: %w", err)
+	}
+	var examples []*Example
+	if err := json.Unmarshal(data, &examples); err != nil {
+		return nil, fmt.Errorf("parse examples: %w", err)
+	}
+	for _, example := range examples {
+		if err := validate(example); err != nil {
+			return nil, fmt.Errorf("example %q: %w", example.ID, err)
+		}
+		s.entries = append(s.entries, newEntry(example))
+	}
+	return s, nil
+}
+
+// FromRecord builds an example from an accepted analysis.
+func FromRecord(record *domain.AnalysisRecord) *Example {
+	result := *record.Result
+	result.Usage = nil
+	return &Example{
+		ID:     record.ID,
+		Source: SourceFeedback,
+		Log:    record.Log,
+		Result: &result,
+	}
+}
+
+// Add stores example, replacing an example with the same ID. It returns
+// false without storing anything when another example already has the same
+// log fingerprint.
+func (s *Store) Add(example *Example) (bool, error) {
+	if err := validate(example); err != nil {
+		return false, err
+	}
+	stored := *example
+	stored.Category = categoryOf(stored.Result.ErrorType)
+	if stored.Source == "" {
+		stored.Source = SourceCurated
+	}
+	if stored.AddedAt.IsZero() {
+		stored.AddedAt = time.Now().UTC()
+	}
+	e := newEntry(&stored)
+	if stored.ID == "" {
+		stored.ID = "ex_" + e.fingerprint[:12]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, other := range s.entries {
+		if other.fingerprint == e.fingerprint && other.example.ID != stored.ID {
+			return false, nil
+		}
+	}
+	s.removeLocked(stored.ID)
+	s.entries = append(s.entries, e)
+	s.capLocked(stored.Category)
+
+	return true, s.saveLocked()
+}
+
+// Remove deletes the example with id and reports whether it existed.
+func (s *Store) Remove(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.removeLocked(id) {
+		return false, nil
+	}
+	return true, s.saveLocked()
+}
+
+// List returns the examples, oldest first, optionally limited to a category.
+func (s *Store) List(category string) []*Example {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	examples := make([]*Example, 0, len(s.entries))
+	for _, e := range s.entries {
+		if category == "" || e.example.Category == category {
+			examples = append(examples, e.example)
+		}
+	}
+	return examples
+}
+
+// Len returns the number of stored examples.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+// Similar returns up to k examples whose similarity to log is at least
+// minScore, most similar first. Similarity is the Jaccard index of the
+// logs' word sets.
+func (s *Store) Similar(log string, k int, minScore float64) []Match {
+	if k <= 0 {
+		return nil
+	}
+	tokens := tokenize(log)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	var matches []Match
+	for _, e := range s.entries {
+		if score := jaccard(tokens, e.tokens); score >= minScore && score > 0 {
+			matches = append(matches, Match{Example: e.example, Score: score})
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+// removeLocked deletes the example with id. s.mu must be held.
+func (s *Store) removeLocked(id string) bool {
+	for i, e := range s.entries {
+		if e.example.ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// capLocked drops the oldest examples of category beyond the cap. Entries
+// are kept in insertion order. s.mu must be held.
+func (s *Store) capLocked(category string) {
+	if s.maxPerCategory <= 0 {
+		return
+	}
+	count := 0
+	for _, e := range s.entries {
+		if e.example.Category == category {
+			count++
+		}
+	}
+	for i := 0; count > s.maxPerCategory && i < len(s.entries); {
+		if s.entries[i].example.Category == category {
+			s.logger.Debug("dropping oldest example", zap.String("id", s.entries[i].example.ID), zap.String("category", category))
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			count--
+			continue
+		}
+		i++
+	}
+}
+
+// saveLocked writes the examples to the store file. s.mu must be held.
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	examples := make([]*Example, len(s.entries))
+	for i, e := range s.entries {
+		examples[i] = e.example
+	}
+	data, err := json.MarshalIndent(examples, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal examples: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write examples: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// validate checks that example has a log and a classified result.
+func validate(example *Example) error {
+	if strings.TrimSpace(example.Log) == "" {
+		return fmt.Errorf("%w: example log is required", domain.ErrInvalidRequest)
+	}
+	if example.Result == nil || example.Result.ErrorType == "" {
+		return fmt.Errorf("%w: example result with error_type is required", domain.ErrInvalidRequest)
+	}
+	return nil
+}
+
+func newEntry(example *Example) *entry {
+	if example.Category == "" {
+		example.Category = categoryOf(example.Result.ErrorType)
+	}
+	return &entry{
+		example:     example,
+		tokens:      tokenize(example.Log),
+		fingerprint: cache.Fingerprint(example.Log),
+	}
+}
+
+// categoryOf returns the taxonomy category of errorType.
+func categoryOf(errorType string) string {
+	if info, ok := domain.LookupErrorType(errorType); ok {
+		return info.Category
+	}
+	return categoryOther
+}
+
+// tokenize returns the distinct lowercase words of log. Words with digits
+// (IDs, timestamps, addresses) and very short words are ignored so that
+// repeated failures compare by their message text.
+func tokenize(log string) map[string]struct{} {
+	tokens := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToLower(log), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		if len(word) < 3 || strings.ContainsAny(word, "0123456789") {
+			continue
+		}
+		tokens[word] = struct{}{}
+	}
+	return tokens
+}
+
+// jaccard returns |a ∩ b| / |a ∪ b|.
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for token := range a {
+		if _, ok := b[token]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,146 @@
+// Package fewshot provides unit tests for the example store.
+package fewshot
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+func example(id, log, errorType string) *Example {
+	return &Example{ID: id, Log: log, Result: &domain.AnalysisResult{ErrorType: errorType, Severity: domain.SeverityMedium}}
+}
+
+func TestStore_Similar(t *testing.T) {
+	s, err := NewStore(Config{}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	for _, e := range []*Example{
+		example("npm", "npm ERR! code ERESOLVE unable to resolve dependency tree", domain.ErrorTypeNPMInstall),
+		example("oom", "container killed: OOMKilled exit code 137", domain.ErrorTypeOutOfMemory),
+		example("docker", "failed to solve: node:18-alpinee not found", domain.ErrorTypeDockerImageNotFound),
+	} {
+		if _, err := s.Add(e); err != nil {
+			t.Fatalf("Add(%s) error = %v", e.ID, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		log      string
+		k        int
+		minScore float64
+		want     []string
+	}{
+		{"closest first", "npm ERR! code ERESOLVE could not resolve dependency", 3, 0.1, []string{"npm"}},
+		{"ignores numbers", "pod OOMKilled, exit code 9", 1, 0.1, []string{"oom"}},
+		{"below threshold", "segmentation fault in worker", 3, 0.1, nil},
+		{"k bounds results", "code not found killed resolve", 2, 0.01, []string{"oom", "docker"}},
+		{"zero k", "npm ERR! code ERESOLVE", 0, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := s.Similar(tt.log, tt.k, tt.minScore)
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.Example.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Similar() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Similar() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStore_Add(t *testing.T) {
+	s, err := NewStore(Config{MaxPerCategory: 2}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	if _, err := s.Add(&Example{Log: "x"}); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("Add() without result error = %v, want ErrInvalidRequest", err)
+	}
+
+	curated := example("", "npm ERR! code E404 not found", domain.ErrorTypeNPMInstall)
+	if added, err := s.Add(curated); !added || err != nil {
+		t.Fatalf("Add() = %v, %v", added, err)
+	}
+	list := s.List("")
+	if len(list) != 1 || list[0].ID == "" || list[0].Source != SourceCurated || list[0].Category != domain.ErrorCategoryDependency {
+		t.Fatalf("List() = %+v, want one curated dependency example with an ID", list)
+	}
+
+	// The same failure with different numbers is a duplicate
+	if added, _ := s.Add(example("other", "npm ERR! code E403 not found", domain.ErrorTypeNPMInstall)); added {
+		t.Error("Add() should skip a log with the same fingerprint")
+	}
+
+	// Adding with an existing ID replaces the example
+	if added, _ := s.Add(example(list[0].ID, "npm ERR! code E404 not found", domain.ErrorTypeNPMInstall)); !added {
+		t.Error("Add() should replace an example with the same ID")
+	}
+
+	// The oldest example of a full category is dropped
+	s.Add(example("b", "npm ERR! ETARGET no matching version", domain.ErrorTypeNPMInstall))
+	s.Add(example("c", "npm ERR! EINTEGRITY checksum failed", domain.ErrorTypeNPMInstall))
+	s.Add(example("d", "connection timed out", domain.ErrorTypeConnectionTimeout))
+	if got := len(s.List(domain.ErrorCategoryDependency)); got != 2 {
+		t.Errorf("dependency examples = %d, want 2", got)
+	}
+	if got := s.Len(); got != 3 {
+		t.Errorf("Len() = %d, want 3", got)
+	}
+
+	if removed, _ := s.Remove("c"); !removed {
+		t.Error("Remove() should report an existing example")
+	}
+	if removed, _ := s.Remove("c"); removed {
+		t.Error("Remove() should report a missing example")
+	}
+}
+
+func TestStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "examples.json")
+	s, err := NewStore(Config{Path: path}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if _, err := s.Add(example("a", "terraform state lock held", domain.ErrorTypeTerraformStateLocked)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	reloaded, err := NewStore(Config{Path: path}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewStore() reload error = %v", err)
+	}
+	matches := reloaded.Similar("Error acquiring the state lock", 1, 0.1)
+	if len(matches) != 1 || matches[0].Example.ID != "a" {
+		t.Errorf("Similar() after reload = %+v, want example a", matches)
+	}
+}
+
+func TestFromRecord(t *testing.T) {
+	record := &domain.AnalysisRecord{
+		ID:     "an_1",
+		Log:    "log",
+		Result: &domain.AnalysisResult{ErrorType: "x", Usage: &domain.TokenUsage{PromptTokens: 10}},
+	}
+	got := FromRecord(record)
+	if got.ID != "an_1" || got.Source != SourceFeedback || got.Result.Usage != nil {
+		t.Errorf("FromRecord() = %+v", got)
+	}
+	if record.Result.Usage == nil {
+		t.Error("FromRecord() should not modify the record")
+	}
+}
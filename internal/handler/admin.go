@@ -0,0 +1,174 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/config"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminAuthMiddleware admits requests carrying "Authorization: Bearer
+// <token>". With an empty token the admin API is disabled and every request
+// is answered 404.
+func AdminAuthMiddleware(token string, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("admin_auth")
+	want := sha256.Sum256([]byte(token))
+
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   "admin API is disabled",
+			})
+			return
+		}
+
+		// Compare hashes so the comparison time does not depend on the token
+		got := sha256.Sum256([]byte(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")))
+		if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+			logger.Warn("admin request rejected",
+				zap.String("request_id", c.GetString("request_id")),
+				zap.String("client_ip", c.ClientIP()),
+				zap.String("path", c.Request.URL.Path),
+			)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "invalid admin token",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// aiConfigView is the AI configuration as reported by the admin API.
+// Credentials are never returned.
+type aiConfigView struct {
+	Provider    config.AIProvider `json:"provider"`
+	Model       string            `json:"model"`
+	Temperature float64           `json:"temperature"`
+	MaxTokens   int               `json:"max_tokens"`
+	BaseURL     string            `json:"base_url"`
+}
+
+func newAIConfigView(cfg config.AIConfig) aiConfigView {
+	return aiConfigView{
+		Provider:    cfg.Provider,
+		Model:       cfg.Model,
+		Temperature: cfg.Temperature,
+		MaxTokens:   cfg.MaxTokens,
+		BaseURL:     cfg.BaseURL,
+	}
+}
+
+// aiConfigUpdate is the body of PUT /admin/config/ai. Omitted fields keep
+// their current value; changing the provider resets the base URL to the
+// provider's default and requires an API key.
+type aiConfigUpdate struct {
+	Provider    *config.AIProvider `json:"provider"`
+	Model       *string            `json:"model"`
+	Temperature *float64           `json:"temperature"`
+	MaxTokens   *int               `json:"max_tokens"`
+	APIKey      *string            `json:"api_key"`
+	BaseURL     *string            `json:"base_url"`
+}
+
+// apply returns cfg with the update applied.
+func (u *aiConfigUpdate) apply(cfg config.AIConfig) config.AIConfig {
+	if u.Provider != nil {
+		cfg = cfg.ForProvider(*u.Provider)
+	}
+	if u.Model != nil {
+		cfg.Model = *u.Model
+	}
+	if u.Temperature != nil {
+		cfg.Temperature = *u.Temperature
+	}
+	if u.MaxTokens != nil {
+		cfg.MaxTokens = *u.MaxTokens
+	}
+	if u.APIKey != nil {
+		cfg.APIKey = *u.APIKey
+	}
+	if u.BaseURL != nil {
+		cfg.BaseURL = *u.BaseURL
+		cfg.BaseURLs = nil
+	}
+	return cfg
+}
+
+// AIConfigHandler reports and changes the AI provider settings at runtime.
+type AIConfigHandler struct {
+	clients []*ai.SwitchableClient
+	logger  *zap.Logger
+}
+
+// NewAIConfigHandler creates a new AIConfigHandler. Every client is switched
+// together; the first one's settings are reported. With no clients (mock
+// or rules-only mode) changes are refused.
+func NewAIConfigHandler(clients []*ai.SwitchableClient, logger *zap.Logger) *AIConfigHandler {
+	return &AIConfigHandler{
+		clients: clients,
+		logger:  logger.Named("ai_config_handler"),
+	}
+}
+
+// Get processes GET /admin/config/ai requests.
+func (h *AIConfigHandler) Get(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "config": newAIConfigView(h.clients[0].Config())})
+}
+
+// Update processes PUT /admin/config/ai requests.
+func (h *AIConfigHandler) Update(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+	var req aiConfigUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	// The clients share their settings apart from the prompt, so validating
+	// the first change validates them all and no client is left behind
+	previous := h.clients[0].Config()
+	next := req.apply(previous)
+	if err := next.ValidateModel(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err := ai.SwitchAll(h.clients, req.apply); err != nil {
+		h.logger.Error("failed to switch AI clients", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to switch AI clients"})
+		return
+	}
+
+	h.logger.Info("AI configuration changed",
+		zap.Any("previous", newAIConfigView(previous)),
+		zap.Any("current", newAIConfigView(next)),
+		zap.Bool("api_key_changed", previous.APIKey != next.APIKey),
+		zap.String("request_id", c.GetString("request_id")),
+		zap.String("client_ip", c.ClientIP()),
+	)
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "config": newAIConfigView(next)})
+}
+
+// enabled responds 409 and returns false when the AI cannot be switched.
+func (h *AIConfigHandler) enabled(c *gin.Context) bool {
+	if len(h.clients) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "error": "the AI provider cannot be changed in mock or rules-only mode"})
+		return false
+	}
+	return true
+}
@@ -2,9 +2,13 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/ai-devops/internal/callback"
 	"github.com/ai-devops/internal/domain"
 	"github.com/ai-devops/internal/service"
 	"github.com/gin-gonic/gin"
@@ -13,49 +17,60 @@ import (
 
 // AnalyzeHandler handles log analysis requests.
 type AnalyzeHandler struct {
-	analyzer *service.Analyzer
-	logger   *zap.Logger
+	analyzer    *service.Analyzer
+	callbacks   *callback.Sender
+	maxLogBytes int
+	logger      *zap.Logger
 }
 
-// NewAnalyzeHandler creates a new AnalyzeHandler.
-func NewAnalyzeHandler(analyzer *service.Analyzer, logger *zap.Logger) *AnalyzeHandler {
+// NewAnalyzeHandler creates a new AnalyzeHandler. callbacks may be nil, in
+// which case requests with a callback_url are rejected. Requests whose log
+// exceeds maxLogBytes are rejected with 413.
+func NewAnalyzeHandler(analyzer *service.Analyzer, callbacks *callback.Sender, maxLogBytes int, logger *zap.Logger) *AnalyzeHandler {
 	return &AnalyzeHandler{
-		analyzer: analyzer,
-		logger:   logger.Named("analyze_handler"),
+		analyzer:    analyzer,
+		callbacks:   callbacks,
+		maxLogBytes: maxLogBytes,
+		logger:      logger.Named("analyze_handler"),
 	}
 }
 
 // Handle processes POST /analyze requests.
 func (h *AnalyzeHandler) Handle(c *gin.Context) {
 	startTime := time.Now()
-	requestID := c.GetHeader("X-Request-ID")
-	if requestID == "" {
-		requestID = generateRequestID()
-	}
-
+	requestID := c.GetString("request_id")
 	logger := h.logger.With(zap.String("request_id", requestID))
 	logger.Debug("received analysis request")
 
-	// Parse request body
+	// Parse and validate request body
 	var req domain.AnalysisRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Warn("invalid request body", zap.Error(err))
-		c.JSON(http.StatusBadRequest, domain.AnalysisResponse{
+	if detail := bindAnalysisRequest(c, &req, h.maxLogBytes); detail != nil {
+		logger.Warn("invalid request", zap.String("code", string(detail.Code)), zap.String("error", detail.Message))
+		writeAnalysisResponse(c, detail.Code.HTTPStatus(), &domain.AnalysisResponse{
 			Success:     false,
-			Error:       "Invalid request body: " + err.Error(),
+			Error:       detail,
 			ProcessedAt: time.Now(),
 		})
 		return
 	}
 
+	if explain, err := strconv.ParseBool(c.Query("explain")); err == nil && explain {
+		req.Explain = true
+	}
+
+	if req.CallbackURL != "" {
+		h.handleAsync(c, &req, requestID, logger)
+		return
+	}
+
 	// Perform analysis
 	ctx := c.Request.Context()
 	response, err := h.analyzer.Analyze(ctx, &req)
 	if err != nil {
 		logger.Error("analysis failed", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, domain.AnalysisResponse{
+		writeAnalysisResponse(c, http.StatusInternalServerError, &domain.AnalysisResponse{
 			Success:     false,
-			Error:       "Internal error during analysis",
+			Error:       domain.NewErrorDetail(domain.CodeInternal, "Internal error during analysis"),
 			ProcessedAt: time.Now(),
 		})
 		return
@@ -69,33 +84,85 @@ func (h *AnalyzeHandler) Handle(c *gin.Context) {
 	)
 
 	// Return appropriate status code
-	if response.Success {
-		c.JSON(http.StatusOK, response)
-	} else {
-		c.JSON(http.StatusUnprocessableEntity, response)
-	}
+	writeAnalysisResponse(c, responseStatus(response), response)
 }
 
-// HealthHandler handles health check requests.
-type HealthHandler struct {
-	logger *zap.Logger
-}
+// handleAsync accepts a request with a callback URL and analyzes it in the
+// background; the response is delivered to the callback.
+func (h *AnalyzeHandler) handleAsync(c *gin.Context, req *domain.AnalysisRequest, requestID string, logger *zap.Logger) {
+	if h.callbacks == nil {
+		c.JSON(http.StatusBadRequest, domain.AnalysisResponse{
+			RequestID:   c.GetString("request_id"),
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInvalidRequest, "callback_url is not supported: CALLBACK_SECRET is not configured"),
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+	if err := h.callbacks.ValidateURL(req.CallbackURL); err != nil {
+		c.JSON(http.StatusBadRequest, domain.AnalysisResponse{
+			RequestID:   c.GetString("request_id"),
+			Success:     false,
+			Error:       domain.ErrorDetailFor(err),
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
 
-// NewHealthHandler creates a new HealthHandler.
-func NewHealthHandler(logger *zap.Logger) *HealthHandler {
-	return &HealthHandler{
-		logger: logger.Named("health_handler"),
+	// The request context ends with this handler; the copy keeps the
+	// response policy for the background analysis
+	policy := c.Copy()
+	tenant := domain.TenantFromContext(c.Request.Context())
+	err := h.callbacks.Submit(req.CallbackURL, requestID, func(ctx context.Context) *domain.AnalysisResponse {
+		response, err := h.analyzer.Analyze(domain.WithTenant(ctx, tenant), req)
+		if err != nil {
+			logger.Error("analysis failed", zap.Error(err))
+			response = &domain.AnalysisResponse{
+				Success:     false,
+				Error:       domain.NewErrorDetail(domain.CodeInternal, "Internal error during analysis"),
+				ProcessedAt: time.Now(),
+			}
+		}
+		return withRequestID(applyResponsePolicy(policy, response), requestID)
+	})
+	if errors.Is(err, callback.ErrBusy) {
+		detail := domain.ErrorDetailFor(err)
+		c.JSON(detail.Code.HTTPStatus(), domain.AnalysisResponse{
+			RequestID:   c.GetString("request_id"),
+			Success:     false,
+			Error:       detail,
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, domain.AnalysisResponse{
+			RequestID:   c.GetString("request_id"),
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInternal, "server is shutting down"),
+			ProcessedAt: time.Now(),
+		})
+		return
 	}
-}
 
-// Handle processes GET /health requests.
-func (h *HealthHandler) Handle(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "healthy",
-		"time":   time.Now().UTC().Format(time.RFC3339),
+	logger.Info("analysis accepted for callback")
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":    true,
+		"request_id": requestID,
 	})
 }
 
+// responseStatus maps an analysis response to its HTTP status code.
+func responseStatus(response *domain.AnalysisResponse) int {
+	if response.Success {
+		return http.StatusOK
+	}
+	if response.Error == nil {
+		return http.StatusInternalServerError
+	}
+	return response.Error.Code.HTTPStatus()
+}
+
 // ReadyHandler handles readiness check requests.
 type ReadyHandler struct {
 	logger *zap.Logger
@@ -116,8 +183,3 @@ func (h *ReadyHandler) Handle(c *gin.Context) {
 		"time":   time.Now().UTC().Format(time.RFC3339),
 	})
 }
-
-// generateRequestID creates a simple unique request ID.
-func generateRequestID() string {
-	return time.Now().Format("20060102150405.000000")
-}
@@ -0,0 +1,38 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/cache"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CacheStatsHandler reports fingerprint cache metrics.
+type CacheStatsHandler struct {
+	cache  cache.Cache
+	logger *zap.Logger
+}
+
+// NewCacheStatsHandler creates a new CacheStatsHandler.
+// c may be nil when the cache is disabled.
+func NewCacheStatsHandler(c cache.Cache, logger *zap.Logger) *CacheStatsHandler {
+	return &CacheStatsHandler{
+		cache:  c,
+		logger: logger.Named("cache_stats_handler"),
+	}
+}
+
+// Handle processes GET /cache/stats requests.
+func (h *CacheStatsHandler) Handle(c *gin.Context) {
+	if h.cache == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"stats":   h.cache.Stats(),
+	})
+}
@@ -0,0 +1,67 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ai-devops/internal/capture"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CaptureHandler serves the captured AI prompts and raw responses.
+type CaptureHandler struct {
+	recorder *capture.Recorder
+	logger   *zap.Logger
+}
+
+// NewCaptureHandler creates a new CaptureHandler. recorder is nil when
+// debug capture is disabled.
+func NewCaptureHandler(recorder *capture.Recorder, logger *zap.Logger) *CaptureHandler {
+	return &CaptureHandler{
+		recorder: recorder,
+		logger:   logger.Named("capture_handler"),
+	}
+}
+
+// List processes GET /admin/captures requests, newest first.
+// Query parameters: rejected (true for rejected responses only), limit.
+func (h *CaptureHandler) List(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "limit must be a non-negative integer"})
+		return
+	}
+
+	entries := h.recorder.List(c.Query("rejected") == "true", limit)
+	if entries == nil {
+		entries = []capture.Entry{}
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "count": len(entries), "captures": entries})
+}
+
+// Get processes GET /admin/captures/:id requests.
+func (h *CaptureHandler) Get(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+	entry, ok := h.recorder.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "capture not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "capture": entry})
+}
+
+// enabled responds with 404 and returns false when capture is disabled.
+func (h *CaptureHandler) enabled(c *gin.Context) bool {
+	if h.recorder == nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "debug capture is disabled"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-devops/internal/dockerfile"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DockerfileHandler handles Dockerfile review requests.
+type DockerfileHandler struct {
+	analyzer    *service.Analyzer
+	maxLogBytes int
+	logger      *zap.Logger
+}
+
+// NewDockerfileHandler creates a new DockerfileHandler. Requests whose
+// Dockerfile and build log exceed maxLogBytes are rejected with 413.
+func NewDockerfileHandler(analyzer *service.Analyzer, maxLogBytes int, logger *zap.Logger) *DockerfileHandler {
+	return &DockerfileHandler{
+		analyzer:    analyzer,
+		maxLogBytes: maxLogBytes,
+		logger:      logger.Named("dockerfile_handler"),
+	}
+}
+
+// Handle processes POST /analyze/dockerfile requests. The body is a
+// dockerfile.Request: the Dockerfile and, optionally, the failing build
+// log. The response carries the static review in "dockerfile".
+func (h *DockerfileHandler) Handle(c *gin.Context) {
+	startTime := time.Now()
+	logger := h.logger.With(zap.String("request_id", c.GetString("request_id")))
+
+	analysisReq, review, detail := h.bind(c)
+	if detail != nil {
+		logger.Warn("invalid request", zap.String("code", string(detail.Code)), zap.String("error", detail.Message))
+		writeAnalysisResponse(c, detail.Code.HTTPStatus(), &domain.AnalysisResponse{
+			Success:     false,
+			Error:       detail,
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+	if explain, err := strconv.ParseBool(c.Query("explain")); err == nil && explain {
+		analysisReq.Explain = true
+	}
+
+	response, err := h.analyzer.AnalyzeDockerfile(c.Request.Context(), analysisReq, review)
+	if err != nil {
+		logger.Error("dockerfile analysis failed", zap.Error(err))
+		writeAnalysisResponse(c, http.StatusInternalServerError, &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInternal, "Internal error during analysis"),
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+
+	logger.Info("dockerfile analysis completed",
+		zap.Bool("success", response.Success),
+		zap.String("source", response.Source),
+		zap.Int("findings", len(review.Findings)),
+		zap.Duration("duration", time.Since(startTime)),
+	)
+
+	writeAnalysisResponse(c, responseStatus(response), response)
+}
+
+// bind decodes the JSON request body and builds the analysis request,
+// checked like the analyze endpoint's.
+func (h *DockerfileHandler) bind(c *gin.Context) (*domain.AnalysisRequest, *domain.DockerfileReview, *domain.ErrorDetail) {
+	var req dockerfile.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, nil, bodyTooLarge(tooLarge.Limit)
+		}
+		return nil, nil, domain.NewErrorDetail(domain.CodeInvalidRequest, "Invalid request body: "+err.Error())
+	}
+	analysisReq, review, err := req.AnalysisRequest()
+	if err != nil {
+		return nil, nil, domain.ErrorDetailFor(err)
+	}
+	if detail := checkAnalysisRequest(analysisReq, h.maxLogBytes); detail != nil {
+		return nil, nil, detail
+	}
+	return analysisReq, review, nil
+}
@@ -0,0 +1,48 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// EndpointStatsHandler reports per-endpoint AI routing metrics.
+type EndpointStatsHandler struct {
+	routers map[string]*ai.Router
+	logger  *zap.Logger
+}
+
+// NewEndpointStatsHandler creates a new EndpointStatsHandler. routers maps a
+// client name to its router; nil routers (single endpoint) are omitted.
+func NewEndpointStatsHandler(routers map[string]*ai.Router, logger *zap.Logger) *EndpointStatsHandler {
+	active := make(map[string]*ai.Router)
+	for name, router := range routers {
+		if router != nil {
+			active[name] = router
+		}
+	}
+	return &EndpointStatsHandler{
+		routers: active,
+		logger:  logger.Named("endpoint_stats_handler"),
+	}
+}
+
+// Handle processes GET /ai/endpoints requests.
+func (h *EndpointStatsHandler) Handle(c *gin.Context) {
+	if len(h.routers) == 0 {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	endpoints := make(map[string][]ai.EndpointStats, len(h.routers))
+	for name, router := range h.routers {
+		endpoints[name] = router.Stats()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":   true,
+		"endpoints": endpoints,
+	})
+}
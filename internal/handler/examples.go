@@ -0,0 +1,44 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/examples"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ExamplesHandler serves curated sample requests and their expected analyses.
+type ExamplesHandler struct {
+	examples []*examples.Example
+	logger   *zap.Logger
+}
+
+// NewExamplesHandler creates a new ExamplesHandler.
+func NewExamplesHandler(list []*examples.Example, logger *zap.Logger) *ExamplesHandler {
+	if list == nil {
+		list = []*examples.Example{}
+	}
+	return &ExamplesHandler{
+		examples: list,
+		logger:   logger.Named("examples_handler"),
+	}
+}
+
+// List processes GET /examples requests.
+func (h *ExamplesHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "examples": h.examples})
+}
+
+// Get processes GET /examples/:id requests.
+func (h *ExamplesHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+	for _, example := range h.examples {
+		if example.ID == id {
+			c.JSON(http.StatusOK, gin.H{"success": true, "example": example})
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "example not found"})
+}
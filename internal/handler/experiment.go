@@ -0,0 +1,49 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/experiment"
+	"github.com/ai-devops/internal/store"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ExperimentHandler compares the variants of the running A/B experiment.
+type ExperimentHandler struct {
+	experiment *experiment.Experiment
+	store      store.Store
+	logger     *zap.Logger
+}
+
+// NewExperimentHandler creates a new ExperimentHandler. exp may be nil when
+// no experiment is running.
+func NewExperimentHandler(exp *experiment.Experiment, s store.Store, logger *zap.Logger) *ExperimentHandler {
+	return &ExperimentHandler{
+		experiment: exp,
+		store:      s,
+		logger:     logger.Named("experiment_handler"),
+	}
+}
+
+// Handle processes GET /ai/experiment requests.
+func (h *ExperimentHandler) Handle(c *gin.Context) {
+	if h.experiment == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	variants, err := h.experiment.Report(c.Request.Context(), h.store)
+	if err != nil {
+		h.logger.Error("failed to compare experiment variants", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to compare experiment variants"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":  true,
+		"name":     h.experiment.Name(),
+		"variants": variants,
+	})
+}
@@ -0,0 +1,70 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-devops/internal/export"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ExportHandler serves the fine-tuning dataset export.
+type ExportHandler struct {
+	exporter *export.FineTuneExporter
+	logger   *zap.Logger
+}
+
+// NewExportHandler creates a new ExportHandler.
+func NewExportHandler(exporter *export.FineTuneExporter, logger *zap.Logger) *ExportHandler {
+	return &ExportHandler{
+		exporter: exporter,
+		logger:   logger.Named("export_handler"),
+	}
+}
+
+// FineTune processes GET /admin/analyses/export requests. It returns accepted
+// analyses as JSONL chat examples; the X-Export-* headers report how many
+// analyses were exported or skipped. Query parameters: since (RFC3339).
+func (h *ExportHandler) FineTune(c *gin.Context) {
+	var opts export.Options
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "since must be RFC3339"})
+			return
+		}
+		opts.Since = t
+	}
+
+	// Buffer so that a failed export returns an error instead of a partial file.
+	var buf bytes.Buffer
+	report, err := h.exporter.Export(c.Request.Context(), &buf, opts)
+	if err != nil {
+		h.logger.Error("fine-tuning export failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "export failed"})
+		return
+	}
+
+	skippedPII := 0
+	for _, n := range report.SkippedPII {
+		skippedPII += n
+	}
+	h.logger.Info("fine-tuning dataset exported",
+		zap.Int("scanned", report.Scanned),
+		zap.Int("exported", report.Exported),
+		zap.Int("skipped_no_feedback", report.SkippedNoFeedback),
+		zap.Int("skipped_stale", report.SkippedStale),
+		zap.Any("skipped_pii", report.SkippedPII),
+	)
+
+	c.Header("Content-Disposition", `attachment; filename="finetune.jsonl"`)
+	c.Header("X-Export-Scanned", strconv.Itoa(report.Scanned))
+	c.Header("X-Export-Exported", strconv.Itoa(report.Exported))
+	c.Header("X-Export-Skipped-PII", strconv.Itoa(skippedPII))
+	c.Header("X-Export-Skipped-Stale", strconv.Itoa(report.SkippedStale))
+	c.Data(http.StatusOK, "application/x-ndjson", buf.Bytes())
+}
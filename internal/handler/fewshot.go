@@ -0,0 +1,113 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/fewshot"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// FewShotHandler curates the worked examples shown to the AI.
+type FewShotHandler struct {
+	examples *fewshot.Store
+	logger   *zap.Logger
+}
+
+// NewFewShotHandler creates a new FewShotHandler.
+func NewFewShotHandler(examples *fewshot.Store, logger *zap.Logger) *FewShotHandler {
+	return &FewShotHandler{
+		examples: examples,
+		logger:   logger.Named("fewshot_handler"),
+	}
+}
+
+// fewShotRequest is the body of POST /admin/fewshot.
+type fewShotRequest struct {
+	ID     string                 `json:"id"`
+	Log    string                 `json:"log" binding:"required"`
+	Result *domain.AnalysisResult `json:"result" binding:"required"`
+}
+
+// List processes GET /fewshot requests. Query parameters: category, and log
+// with an optional k to preview the examples a log would be given.
+func (h *FewShotHandler) List(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+	if log := c.Query("log"); log != "" {
+		k, err := strconv.Atoi(c.DefaultQuery("k", "3"))
+		if err != nil || k < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "k must be a positive integer"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true, "matches": h.examples.Similar(log, k, 0)})
+		return
+	}
+
+	examples := h.examples.List(c.Query("category"))
+	c.JSON(http.StatusOK, gin.H{"success": true, "count": len(examples), "examples": examples})
+}
+
+// Add processes POST /admin/fewshot requests, storing a curated example. The
+// log must already be sanitized.
+func (h *FewShotHandler) Add(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+	var req fewShotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	example := &fewshot.Example{ID: req.ID, Source: fewshot.SourceCurated, Log: req.Log, Result: req.Result}
+	added, err := h.examples.Add(example)
+	if errors.Is(err, domain.ErrInvalidRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to add example", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to add example"})
+		return
+	}
+	if !added {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "error": "an example with the same log already exists"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "count": h.examples.Len()})
+}
+
+// Delete processes DELETE /admin/fewshot/:id requests.
+func (h *FewShotHandler) Delete(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+	removed, err := h.examples.Remove(c.Param("id"))
+	if err != nil {
+		h.logger.Error("failed to remove example", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to remove example"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "example not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// enabled responds 404 and returns false when no example store is configured.
+func (h *FewShotHandler) enabled(c *gin.Context) bool {
+	if h.examples == nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "few-shot examples are disabled"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/render"
+	"github.com/gin-gonic/gin"
+)
+
+// Response formats of the analyze endpoints, selected by the "format" query
+// parameter or the Accept header.
+const (
+	formatJSON     = "json"
+	formatMarkdown = "markdown"
+	formatText     = "text"
+)
+
+// responseFormats maps each format to its content type and renderer; JSON
+// is written by gin.
+var responseFormats = map[string]struct {
+	contentType string
+	render      render.Func
+}{
+	formatMarkdown: {"text/markdown; charset=utf-8", render.Markdown},
+	formatText:     {"text/plain; charset=utf-8", render.Text},
+}
+
+// responseFormat returns the format the client asked for. The format query
+// parameter wins over the Accept header; JSON is the default.
+func responseFormat(c *gin.Context) (string, bool) {
+	if format := c.Query("format"); format != "" {
+		if _, ok := responseFormats[format]; !ok && format != formatJSON {
+			return "", false
+		}
+		return format, true
+	}
+	switch c.NegotiateFormat(gin.MIMEJSON, "text/markdown", gin.MIMEPlain) {
+	case "text/markdown":
+		return formatMarkdown, true
+	case gin.MIMEPlain:
+		return formatText, true
+	default:
+		return formatJSON, true
+	}
+}
+
+// writeAnalysisResponse writes response with status in the format the
+// client asked for, after applying the response policy, and summarizes it
+// in the analysis headers. The unredacted response is recorded for the
+// access log.
+func writeAnalysisResponse(c *gin.Context, status int, response *domain.AnalysisResponse) {
+	domain.OutcomeFromContext(c.Request.Context()).RecordResponse(response)
+	response = withRequestID(applyResponsePolicy(c, response), c.GetString("request_id"))
+	setAnalysisHeaders(c, response)
+
+	format, ok := responseFormat(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, domain.AnalysisResponse{
+			RequestID:   c.GetString("request_id"),
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInvalidRequest, "format must be json, markdown or text"),
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+	out, ok := responseFormats[format]
+	if !ok {
+		c.JSON(status, response)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := out.render(&buf, response); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.AnalysisResponse{
+			RequestID:   c.GetString("request_id"),
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInternal, "failed to render response"),
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+	c.Data(status, out.contentType, buf.Bytes())
+}
+
+// withRequestID returns a copy of response answering requestID; the
+// response itself may be shared with the result cache.
+func withRequestID(response *domain.AnalysisResponse, requestID string) *domain.AnalysisResponse {
+	answered := *response
+	answered.RequestID = requestID
+	return &answered
+}
+
+// Analysis response headers, so gateways can route and alert on results
+// without parsing bodies.
+const (
+	headerAnalysisSeverity  = "X-Analysis-Severity"
+	headerAnalysisSource    = "X-Analysis-Source"
+	headerAnalysisErrorType = "X-Analysis-ErrorType"
+)
+
+// setAnalysisHeaders sets the analysis headers of response; those without
+// a value (e.g. severity of a failed analysis) are omitted. The source is
+// the one the response policy lets the client see.
+func setAnalysisHeaders(c *gin.Context, response *domain.AnalysisResponse) {
+	if response.Source != "" {
+		c.Header(headerAnalysisSource, response.Source)
+	}
+	if response.Result == nil {
+		return
+	}
+	if response.Result.Severity != "" {
+		c.Header(headerAnalysisSeverity, string(response.Result.Severity))
+	}
+	if response.Result.ErrorType != "" {
+		c.Header(headerAnalysisErrorType, response.Result.ErrorType)
+	}
+}
@@ -0,0 +1,150 @@
+// Package handler provides unit tests for analysis response formatting and headers.
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// newAnalysisRouter returns a router answering GET /analysis with response
+// through writeAnalysisResponse, behind RequestIDMiddleware and
+// ResponsePolicyMiddleware, which applies mode, if any, to the
+// "partner-key" API key.
+func newAnalysisRouter(response *domain.AnalysisResponse, mode domain.ProvenanceMode) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	if mode != "" {
+		router.Use(ResponsePolicyMiddleware([]string{"partner-key"}, mode))
+	}
+	router.GET("/analysis", func(c *gin.Context) {
+		writeAnalysisResponse(c, http.StatusOK, response)
+	})
+	return router
+}
+
+func TestWriteAnalysisResponse_RequestID(t *testing.T) {
+	// The response may be shared with the result cache
+	cached := &domain.AnalysisResponse{Success: true, Source: "rules:test"}
+	router := newAnalysisRouter(cached, "")
+
+	for _, id := range []string{"req-1", "req-2"} {
+		req := httptest.NewRequest(http.MethodGet, "/analysis", nil)
+		req.Header.Set("X-Request-ID", id)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var got domain.AnalysisResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("invalid JSON body %s: %v", rec.Body, err)
+		}
+		if got.RequestID != id || rec.Header().Get("X-Request-ID") != id {
+			t.Errorf("request_id = %q, header = %q, want %q", got.RequestID, rec.Header().Get("X-Request-ID"), id)
+		}
+	}
+	if cached.RequestID != "" {
+		t.Errorf("cached response modified: request_id = %q", cached.RequestID)
+	}
+}
+
+func TestWriteAnalysisResponse_Headers(t *testing.T) {
+	analyzed := &domain.AnalysisResponse{
+		Success: true,
+		Source:  "ai:gemini-2.0-flash",
+		Result:  &domain.AnalysisResult{ErrorType: "npm_missing_script", Severity: domain.SeverityHigh},
+	}
+	failed := &domain.AnalysisResponse{
+		Source: "rules:npm",
+		Error:  domain.NewErrorDetail(domain.CodeAITimeout, "timed out"),
+	}
+
+	tests := []struct {
+		name       string
+		response   *domain.AnalysisResponse
+		mode       domain.ProvenanceMode
+		query      string
+		wantStatus int
+		want       map[string]string
+	}{
+		{
+			name:       "analyzed",
+			response:   analyzed,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				headerAnalysisSource:    "ai:gemini-2.0-flash",
+				headerAnalysisSeverity:  "High",
+				headerAnalysisErrorType: "npm_missing_script",
+			},
+		},
+		{
+			name:       "markdown",
+			response:   analyzed,
+			query:      "?format=markdown",
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				headerAnalysisSource:    "ai:gemini-2.0-flash",
+				headerAnalysisSeverity:  "High",
+				headerAnalysisErrorType: "npm_missing_script",
+			},
+		},
+		{
+			name:       "failed analysis has no result headers",
+			response:   failed,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				headerAnalysisSource:    "rules:npm",
+				headerAnalysisSeverity:  "",
+				headerAnalysisErrorType: "",
+			},
+		},
+		{
+			name:       "normalized provenance",
+			response:   analyzed,
+			mode:       domain.ProvenanceNormalize,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				headerAnalysisSource:   "ai",
+				headerAnalysisSeverity: "High",
+			},
+		},
+		{
+			name:       "hidden provenance",
+			response:   analyzed,
+			mode:       domain.ProvenanceHide,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				headerAnalysisSource:   "",
+				headerAnalysisSeverity: "High",
+			},
+		},
+		{
+			name:       "unknown format",
+			response:   analyzed,
+			query:      "?format=xml",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/analysis"+tt.query, nil)
+			req.Header.Set("X-API-Key", "partner-key")
+			rec := httptest.NewRecorder()
+			newAnalysisRouter(tt.response, tt.mode).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			for header, want := range tt.want {
+				if got := rec.Header().Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/internal/version"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AI modes reported by GET /health.
+const (
+	AIModeLive      = "live"
+	AIModeMock      = "mock"
+	AIModeRulesOnly = "rules_only"
+)
+
+// HealthInfo is what GET /health reports besides the build.
+type HealthInfo struct {
+	// Started is when the service started.
+	Started time.Time
+
+	// AIMode is AIModeLive, AIModeMock or AIModeRulesOnly.
+	AIMode string
+
+	// AIProvider returns the provider and model currently configured,
+	// which may change at runtime.
+	AIProvider func() (provider, model string)
+
+	// Rules is the service's rule engine.
+	Rules *rules.Engine
+
+	// Activity records the last successful AI call.
+	Activity *ai.Activity
+}
+
+// HealthHandler handles health check requests.
+type HealthHandler struct {
+	info   HealthInfo
+	build  version.Build
+	logger *zap.Logger
+}
+
+// NewHealthHandler creates a new HealthHandler.
+func NewHealthHandler(info HealthInfo, logger *zap.Logger) *HealthHandler {
+	return &HealthHandler{
+		info:   info,
+		build:  version.Get(),
+		logger: logger.Named("health_handler"),
+	}
+}
+
+// Handle processes GET /health requests.
+func (h *HealthHandler) Handle(c *gin.Context) {
+	now := time.Now()
+
+	aiStatus := gin.H{"mode": h.info.AIMode}
+	if h.info.AIProvider != nil {
+		provider, model := h.info.AIProvider()
+		aiStatus["provider"] = provider
+		aiStatus["model"] = model
+	}
+	if last := h.info.Activity.LastSuccess(); !last.IsZero() {
+		aiStatus["last_success"] = last.UTC().Format(time.RFC3339)
+		aiStatus["seconds_since_success"] = int(now.Sub(last).Seconds())
+	} else {
+		aiStatus["last_success"] = nil
+	}
+
+	rulesStatus := gin.H{"loaded": 0}
+	if h.info.Rules != nil {
+		rulesStatus["loaded"] = len(h.info.Rules.Rules())
+		rulesStatus["confidence_threshold"] = h.info.Rules.ConfidenceThreshold()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "healthy",
+		"time":           now.UTC().Format(time.RFC3339),
+		"version":        h.build.Version,
+		"commit":         h.build.Commit,
+		"go_version":     h.build.GoVersion,
+		"started_at":     h.info.Started.UTC().Format(time.RFC3339),
+		"uptime_seconds": int(now.Sub(h.info.Started).Seconds()),
+		"components": gin.H{
+			"ai":    aiStatus,
+			"rules": rulesStatus,
+		},
+	})
+}
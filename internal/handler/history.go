@@ -0,0 +1,213 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/export"
+	"github.com/ai-devops/internal/fewshot"
+	"github.com/ai-devops/internal/render"
+	"github.com/ai-devops/internal/store"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HistoryHandler serves stored analyses and accepts feedback on them.
+type HistoryHandler struct {
+	store    store.Store
+	examples *fewshot.Store
+	logger   *zap.Logger
+}
+
+// NewHistoryHandler creates a new HistoryHandler. If examples is set,
+// analyses are added to it once their feedback is accepted and removed
+// from it on unhelpful feedback.
+func NewHistoryHandler(s store.Store, examples *fewshot.Store, logger *zap.Logger) *HistoryHandler {
+	return &HistoryHandler{
+		store:    s,
+		examples: examples,
+		logger:   logger.Named("history_handler"),
+	}
+}
+
+// feedbackRequest is the body of POST /analyses/:id/feedback.
+type feedbackRequest struct {
+	Helpful *bool  `json:"helpful" binding:"required"`
+	Comment string `json:"comment"`
+}
+
+// List processes GET /analyses requests.
+// Query parameters: limit, offset, error_type, since (RFC3339).
+func (h *HistoryHandler) List(c *gin.Context) {
+	opts := store.ListOptions{
+		ErrorType: c.Query("error_type"),
+	}
+	opts.Limit, _ = strconv.Atoi(c.Query("limit"))
+	opts.Offset, _ = strconv.Atoi(c.Query("offset"))
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "since must be RFC3339"})
+			return
+		}
+		opts.Since = t
+	}
+
+	records, err := h.store.ListAnalyses(c.Request.Context(), opts)
+	if err != nil {
+		h.logger.Error("failed to list analyses", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to list analyses"})
+		return
+	}
+	if records == nil {
+		records = []*domain.AnalysisRecord{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "analyses": records})
+}
+
+// Get processes GET /analyses/:id requests.
+func (h *HistoryHandler) Get(c *gin.Context) {
+	record, err := h.store.GetAnalysis(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "analysis not found"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get analysis", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get analysis"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "analysis": record})
+}
+
+// Report processes GET /analyses/:id/report requests. It renders the
+// analysis as a standalone HTML page that CI can link to.
+func (h *HistoryHandler) Report(c *gin.Context) {
+	record, err := h.store.GetAnalysis(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "analysis not found"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get analysis", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get analysis"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := render.HTML(&buf, record); err != nil {
+		h.logger.Error("failed to render report", zap.String("analysis_id", record.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to render report"})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// Diff processes GET /analyses/:id/diff/:otherId requests. It reports how
+// the analysis otherId differs from id.
+func (h *HistoryHandler) Diff(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var records [2]*domain.AnalysisRecord
+	for i, id := range []string{c.Param("id"), c.Param("otherId")} {
+		record, err := h.store.GetAnalysis(ctx, id)
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "analysis not found: " + id})
+			return
+		}
+		if err != nil {
+			h.logger.Error("failed to get analysis", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get analysis"})
+			return
+		}
+		records[i] = record
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "diff": domain.DiffAnalyses(records[0], records[1])})
+}
+
+// Feedback processes POST /analyses/:id/feedback requests.
+func (h *HistoryHandler) Feedback(c *gin.Context) {
+	var req feedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	feedback := &domain.Feedback{
+		AnalysisID: c.Param("id"),
+		Helpful:    *req.Helpful,
+		Comment:    req.Comment,
+	}
+	err := h.store.SaveFeedback(c.Request.Context(), feedback)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "analysis not found"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to save feedback", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to save feedback"})
+		return
+	}
+	h.updateExamples(c.Request.Context(), feedback)
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "feedback": feedback})
+}
+
+// updateExamples adds the analysis to the example store when feedback makes
+// it accepted and removes it on unhelpful feedback. Stale analyses, degraded
+// results and logs containing personal data are never added. Failures are
+// logged; the feedback itself is already saved.
+func (h *HistoryHandler) updateExamples(ctx context.Context, feedback *domain.Feedback) {
+	if h.examples == nil {
+		return
+	}
+	id := feedback.AnalysisID
+	if !feedback.Helpful {
+		if _, err := h.examples.Remove(id); err != nil {
+			h.logger.Error("failed to remove example", zap.String("analysis_id", id), zap.Error(err))
+		}
+		return
+	}
+
+	accepted, err := store.Accepted(ctx, h.store, id)
+	if err != nil || !accepted {
+		return
+	}
+	record, err := h.store.GetAnalysis(ctx, id)
+	if err != nil || record.Stale || record.Result == nil || (record.Metadata != nil && record.Metadata.Degraded) {
+		return
+	}
+	if kinds := export.DetectPII(record.Log); len(kinds) > 0 {
+		h.logger.Info("not adding example containing personal data", zap.String("analysis_id", id), zap.Strings("kinds", kinds))
+		return
+	}
+	added, err := h.examples.Add(fewshot.FromRecord(record))
+	if err != nil {
+		h.logger.Error("failed to add example", zap.String("analysis_id", id), zap.Error(err))
+		return
+	}
+	if added {
+		h.logger.Info("added accepted analysis as example", zap.String("analysis_id", id))
+	}
+}
+
+// Stats processes GET /analyses/stats requests.
+func (h *HistoryHandler) Stats(c *gin.Context) {
+	stats, err := h.store.Stats(c.Request.Context())
+	if err != nil {
+		h.logger.Error("failed to get store stats", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "stats": stats})
+}
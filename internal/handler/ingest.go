@@ -0,0 +1,93 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/ingest"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxIngestBodySize bounds a batch of shipped records.
+const maxIngestBodySize = 10 << 20 // 10MB
+
+// IngestHandler accepts records from log shippers.
+type IngestHandler struct {
+	ingester *ingest.Ingester
+	logger   *zap.Logger
+}
+
+// NewIngestHandler creates a new IngestHandler.
+func NewIngestHandler(ingester *ingest.Ingester, logger *zap.Logger) *IngestHandler {
+	return &IngestHandler{
+		ingester: ingester,
+		logger:   logger.Named("ingest_handler"),
+	}
+}
+
+// Fluent processes POST /api/v1/ingest/fluent[/:tag] requests from the
+// Fluent Bit and Fluentd HTTP outputs. The stream of records without a tag
+// field is the :tag path parameter or the X-Fluent-Tag header.
+func (h *IngestHandler) Fluent(c *gin.Context) {
+	tag := c.Param("tag")
+	if tag == "" {
+		tag = c.GetHeader("X-Fluent-Tag")
+	}
+	h.ingest(c, tag)
+}
+
+// Webhook processes POST /api/v1/ingest/webhooks/:integration requests,
+// verified by WebhookRouteMiddleware. Each delivery is a record of the
+// stream webhook/<integration>, so failure events are analyzed like
+// shipped logs.
+func (h *IngestHandler) Webhook(c *gin.Context) {
+	h.ingest(c, "webhook/"+c.Param("integration"))
+}
+
+// ingest parses a batch of records of stream tag and hands it to the
+// ingester.
+func (h *IngestHandler) ingest(c *gin.Context, tag string) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIngestBodySize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   domain.NewErrorDetail(domain.CodeInvalidRequest, "failed to read request body"),
+		})
+		return
+	}
+	if len(body) > maxIngestBodySize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"success": false,
+			"error":   domain.NewErrorDetail(domain.CodeLogTooLarge, "batch exceeds 10MB; lower the shipper's buffer size"),
+		})
+		return
+	}
+
+	records, err := ingest.ParseFluent(body, tag)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   domain.ErrorDetailFor(err),
+		})
+		return
+	}
+
+	h.ingester.Ingest(records)
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"accepted": len(records),
+	})
+}
+
+// Streams processes GET /api/v1/ingest/streams requests.
+func (h *IngestHandler) Streams(c *gin.Context) {
+	streams := h.ingester.Streams()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(streams),
+		"streams": streams,
+	})
+}
@@ -0,0 +1,83 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/cache"
+	"github.com/ai-devops/internal/store"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// InvalidateHandler marks cached and stored results stale after a rule or
+// prompt change.
+type InvalidateHandler struct {
+	cache  cache.Cache
+	store  store.Store
+	logger *zap.Logger
+}
+
+// NewInvalidateHandler creates a new InvalidateHandler.
+// c may be nil when the cache is disabled.
+func NewInvalidateHandler(c cache.Cache, s store.Store, logger *zap.Logger) *InvalidateHandler {
+	return &InvalidateHandler{
+		cache:  c,
+		store:  s,
+		logger: logger.Named("invalidate_handler"),
+	}
+}
+
+// invalidateRequest selects the results to invalidate.
+type invalidateRequest struct {
+	RuleID        string `json:"rule_id"`
+	PromptVersion string `json:"prompt_version"`
+}
+
+// Handle processes POST /admin/analyses/invalidate requests. Cached results
+// shaped by the rule or prompt version are dropped so identical requests are
+// re-analyzed; stored analyses are kept but marked stale.
+func (h *InvalidateHandler) Handle(c *gin.Context) {
+	var req invalidateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid request body: " + err.Error()})
+		return
+	}
+	if req.RuleID == "" && req.PromptVersion == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "rule_id or prompt_version is required"})
+		return
+	}
+
+	removed := 0
+	if h.cache != nil {
+		if req.RuleID != "" {
+			removed += h.cache.Invalidate(cache.RuleTag(req.RuleID))
+		}
+		if req.PromptVersion != "" {
+			removed += h.cache.Invalidate(cache.PromptTag(req.PromptVersion))
+		}
+	}
+
+	marked, err := h.store.MarkStale(c.Request.Context(), store.StaleFilter{
+		RuleID:        req.RuleID,
+		PromptVersion: req.PromptVersion,
+	})
+	if err != nil {
+		h.logger.Error("failed to mark analyses stale", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to mark analyses stale"})
+		return
+	}
+
+	h.logger.Info("results invalidated",
+		zap.String("rule_id", req.RuleID),
+		zap.String("prompt_version", req.PromptVersion),
+		zap.Int("cache_entries_removed", removed),
+		zap.Int("analyses_marked_stale", marked),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":               true,
+		"cache_entries_removed": removed,
+		"analyses_marked_stale": marked,
+	})
+}
@@ -0,0 +1,94 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/kubernetes"
+	"github.com/ai-devops/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// KubernetesHandler handles Kubernetes pod analysis requests.
+type KubernetesHandler struct {
+	analyzer    *service.Analyzer
+	maxLogBytes int
+	logger      *zap.Logger
+}
+
+// NewKubernetesHandler creates a new KubernetesHandler. Requests whose
+// rendered pod, events and logs exceed maxLogBytes are rejected with 413.
+func NewKubernetesHandler(analyzer *service.Analyzer, maxLogBytes int, logger *zap.Logger) *KubernetesHandler {
+	return &KubernetesHandler{
+		analyzer:    analyzer,
+		maxLogBytes: maxLogBytes,
+		logger:      logger.Named("kubernetes_handler"),
+	}
+}
+
+// Handle processes POST /analyze/k8s requests. The body is a
+// kubernetes.Request: the pod (kubectl get pod -o json), its events and
+// its container logs.
+func (h *KubernetesHandler) Handle(c *gin.Context) {
+	startTime := time.Now()
+	logger := h.logger.With(zap.String("request_id", c.GetString("request_id")))
+
+	req, analysisReq, detail := h.bind(c)
+	if detail != nil {
+		logger.Warn("invalid request", zap.String("code", string(detail.Code)), zap.String("error", detail.Message))
+		writeAnalysisResponse(c, detail.Code.HTTPStatus(), &domain.AnalysisResponse{
+			Success:     false,
+			Error:       detail,
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+	if explain, err := strconv.ParseBool(c.Query("explain")); err == nil && explain {
+		analysisReq.Explain = true
+	}
+
+	response, err := h.analyzer.AnalyzeKubernetes(c.Request.Context(), analysisReq, req.Pod)
+	if err != nil {
+		logger.Error("kubernetes analysis failed", zap.Error(err))
+		writeAnalysisResponse(c, http.StatusInternalServerError, &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInternal, "Internal error during analysis"),
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+
+	logger.Info("kubernetes analysis completed",
+		zap.Bool("success", response.Success),
+		zap.String("source", response.Source),
+		zap.Duration("duration", time.Since(startTime)),
+	)
+
+	writeAnalysisResponse(c, responseStatus(response), response)
+}
+
+// bind decodes the JSON request body and builds the analysis request,
+// checked like the analyze endpoint's.
+func (h *KubernetesHandler) bind(c *gin.Context) (*kubernetes.Request, *domain.AnalysisRequest, *domain.ErrorDetail) {
+	var req kubernetes.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, nil, bodyTooLarge(tooLarge.Limit)
+		}
+		return nil, nil, domain.NewErrorDetail(domain.CodeInvalidRequest, "Invalid request body: "+err.Error())
+	}
+	analysisReq, err := req.AnalysisRequest()
+	if err != nil {
+		return nil, nil, domain.ErrorDetailFor(err)
+	}
+	if detail := checkAnalysisRequest(analysisReq, h.maxLogBytes); detail != nil {
+		return nil, nil, detail
+	}
+	return &req, analysisReq, nil
+}
@@ -0,0 +1,38 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LimiterStatsHandler reports AI concurrency limiter occupancy per tenant.
+type LimiterStatsHandler struct {
+	limiter *service.ConcurrencyLimiter
+	logger  *zap.Logger
+}
+
+// NewLimiterStatsHandler creates a new LimiterStatsHandler.
+// limiter may be nil when concurrency is unlimited.
+func NewLimiterStatsHandler(limiter *service.ConcurrencyLimiter, logger *zap.Logger) *LimiterStatsHandler {
+	return &LimiterStatsHandler{
+		limiter: limiter,
+		logger:  logger.Named("limiter_stats_handler"),
+	}
+}
+
+// Handle processes GET /limiter/stats requests.
+func (h *LimiterStatsHandler) Handle(c *gin.Context) {
+	if h.limiter == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"stats":   h.limiter.Stats(),
+	})
+}
@@ -2,20 +2,33 @@
 package handler
 
 import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/tenant"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 // Middleware provides common HTTP middleware functions.
 
-// LoggingMiddleware logs request details.
+// LoggingMiddleware logs request details. Requests answered with an
+// analysis also log its outcome (source, error type, severity, rule, AI
+// latency and tokens), recorded through the request context.
 func LoggingMiddleware(logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		startTime := time.Now()
 		path := c.Request.URL.Path
 		method := c.Request.Method
+		outcome := &domain.Outcome{}
+		c.Request = c.Request.WithContext(domain.WithOutcome(c.Request.Context(), outcome))
 
 		// Process request
 		c.Next()
@@ -24,14 +37,47 @@ func LoggingMiddleware(logger *zap.Logger) gin.HandlerFunc {
 		duration := time.Since(startTime)
 		statusCode := c.Writer.Status()
 
-		logger.Info("request completed",
+		fields := []zap.Field{
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.Int("status", statusCode),
 			zap.Duration("duration", duration),
 			zap.String("client_ip", c.ClientIP()),
+		}
+		logger.Info("request completed", append(fields, outcomeFields(outcome.Fields())...)...)
+	}
+}
+
+// outcomeFields returns the log fields of an analysis outcome, omitting
+// those not recorded.
+func outcomeFields(o domain.OutcomeFields) []zap.Field {
+	if !o.Analyzed {
+		return nil
+	}
+	fields := []zap.Field{zap.String("analysis_source", o.Source)}
+	if o.ErrorType != "" {
+		fields = append(fields, zap.String("error_type", o.ErrorType))
+	}
+	if o.Severity != "" {
+		fields = append(fields, zap.String("severity", string(o.Severity)))
+	}
+	if o.RuleID != "" {
+		fields = append(fields, zap.String("rule_id", o.RuleID))
+	}
+	if o.ErrorCode != "" {
+		fields = append(fields, zap.String("error_code", string(o.ErrorCode)))
+	}
+	if o.AILatency > 0 {
+		fields = append(fields, zap.Duration("ai_latency", o.AILatency))
+	}
+	if o.TotalTokens > 0 {
+		fields = append(fields,
+			zap.Int("prompt_tokens", o.PromptTokens),
+			zap.Int("completion_tokens", o.CompletionTokens),
+			zap.Int("total_tokens", o.TotalTokens),
 		)
 	}
+	return fields
 }
 
 // RecoveryMiddleware handles panics gracefully.
@@ -58,7 +104,8 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, X-Tenant-ID, X-API-Key")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, X-Analysis-Severity, X-Analysis-Source, X-Analysis-ErrorType")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -69,15 +116,140 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-// RequestIDMiddleware ensures each request has a unique ID.
+// RequestIDMiddleware ensures each request has a unique ID: the client's
+// X-Request-ID if it is valid, or a new UUID. The ID is returned in the
+// X-Request-ID header and as "request_id" in analysis responses, and is
+// carried by the request context for downstream logs and AI requests.
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			requestID = generateRequestID()
+		if !domain.ValidRequestID(requestID) {
+			requestID = domain.NewRequestID()
 		}
 		c.Header("X-Request-ID", requestID)
 		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(domain.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// BodyLimitMiddleware rejects request bodies larger than maxBytes with 413.
+// Bodies with a declared length are rejected before they are read; others
+// fail when the handler reads past the limit.
+func BodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, domain.AnalysisResponse{
+				RequestID:   c.GetString("request_id"),
+				Success:     false,
+				Error:       bodyTooLarge(maxBytes),
+				ProcessedAt: time.Now(),
+			})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// bodyTooLarge describes a request body over the size limit.
+func bodyTooLarge(maxBytes int64) *domain.ErrorDetail {
+	return domain.NewErrorDetail(domain.CodeLogTooLarge, fmt.Sprintf("request body exceeds %d bytes", maxBytes))
+}
+
+// GzipBodyMiddleware transparently decompresses request bodies sent with
+// "Content-Encoding: gzip". The decompressed body is capped at maxBytes so a
+// small compressed body cannot expand without bound; other encodings are
+// rejected with 415.
+func GzipBodyMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		switch encoding {
+		case "", "identity":
+			c.Next()
+			return
+		case "gzip", "x-gzip":
+		default:
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, domain.AnalysisResponse{
+				RequestID:   c.GetString("request_id"),
+				Success:     false,
+				Error:       domain.NewErrorDetail(domain.CodeInvalidRequest, "unsupported Content-Encoding "+encoding),
+				ProcessedAt: time.Now(),
+			})
+			return
+		}
+
+		reader, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, domain.AnalysisResponse{
+				RequestID:   c.GetString("request_id"),
+				Success:     false,
+				Error:       domain.NewErrorDetail(domain.CodeInvalidRequest, "invalid gzip body: "+err.Error()),
+				ProcessedAt: time.Now(),
+			})
+			return
+		}
+		defer reader.Close()
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, reader, maxBytes)
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+		c.Next()
+	}
+}
+
+// TenantMiddleware resolves the tenant of each request and stores it in the
+// request context, so scheduling is fair per tenant and per-tenant policies
+// and history apply. Tenants are identified by their X-API-Key or named by
+// the X-Tenant-ID header (see tenant.Registry.Resolve); requests naming none
+// belong to domain.DefaultTenant.
+func TenantMiddleware(registry *tenant.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("X-Tenant-ID")
+		if header != "" && !tenant.ValidID(header) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   domain.NewErrorDetail(domain.CodeInvalidTenant, "invalid X-Tenant-ID header"),
+			})
+			return
+		}
+
+		id, err := registry.Resolve(c.GetHeader("X-API-Key"), header)
+		switch {
+		case errors.Is(err, tenant.ErrForbidden):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   domain.NewErrorDetail(domain.CodeTenantForbidden, err.Error()),
+			})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   domain.NewErrorDetail(domain.CodeTenantUnauthorized, err.Error()),
+			})
+			return
+		}
+
+		c.Set("tenant_id", id)
+		c.Request = c.Request.WithContext(domain.WithTenant(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// TenantRateLimitMiddleware rejects requests of tenants over their rate
+// limit with 429 and a Retry-After header. It runs after TenantMiddleware.
+func TenantRateLimitMiddleware(registry *tenant.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := domain.TenantFromContext(c.Request.Context())
+		if ok, retryAfter := registry.Allow(id); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   domain.NewErrorDetail(domain.CodeTenantRateLimited, "tenant "+id+" exceeded its request rate limit"),
+			})
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,216 @@
+// Package handler provides unit tests for the HTTP middleware.
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+// errorBody is the JSON body of a rejected request.
+type errorBody struct {
+	Success bool                `json:"success"`
+	Error   *domain.ErrorDetail `json:"error"`
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry, err := tenant.NewRegistry(&tenant.Config{Tenants: []tenant.Tenant{
+		{ID: "payments", APIKeys: []string{"pay-key"}},
+		{ID: "search"},
+	}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		header     string
+		wantStatus int
+		wantCode   domain.ErrorCode
+	}{
+		{"api key", "pay-key", "", http.StatusOK, ""},
+		{"named tenant without keys", "", "search", http.StatusOK, ""},
+		{"invalid tenant id", "", "no spaces", http.StatusBadRequest, domain.CodeInvalidTenant},
+		{"key of another tenant", "pay-key", "search", http.StatusForbidden, domain.CodeTenantForbidden},
+		{"tenant requires a key", "", "payments", http.StatusUnauthorized, domain.CodeTenantUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", TenantMiddleware(registry), func(c *gin.Context) {
+				c.String(http.StatusOK, domain.TenantFromContext(c.Request.Context()))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-API-Key", tt.apiKey)
+			req.Header.Set("X-Tenant-ID", tt.header)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var body errorBody
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error == nil {
+				t.Fatalf("body = %s, want an error detail", rec.Body)
+			}
+			if body.Error.Code != tt.wantCode || body.Error.Code.HTTPStatus() != tt.wantStatus {
+				t.Errorf("error code = %s (status %d), want %s", body.Error.Code, body.Error.Code.HTTPStatus(), tt.wantCode)
+			}
+		})
+	}
+}
+
+// newBodyLimitRouter returns a router that binds analysis requests to
+// POST /analyze behind RequestIDMiddleware and BodyLimitMiddleware.
+func newBodyLimitRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), BodyLimitMiddleware(maxBytes))
+	router.POST("/analyze", bindLog)
+	return router
+}
+
+// bindLog answers an analysis request with its log, or with the error
+// binding it.
+func bindLog(c *gin.Context) {
+	var req domain.AnalysisRequest
+	if detail := bindAnalysisRequest(c, &req, 0); detail != nil {
+		writeAnalysisResponse(c, detail.Code.HTTPStatus(), &domain.AnalysisResponse{Error: detail})
+		return
+	}
+	c.String(http.StatusOK, req.Log)
+}
+
+// readerOnly hides the length of a body, as a chunked upload does.
+type readerOnly struct{ io.Reader }
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		chunked    bool
+		wantStatus int
+		wantCode   domain.ErrorCode
+	}{
+		{"under the limit", "panic: boom", false, http.StatusOK, ""},
+		{"declared length over the limit", strings.Repeat("x", 33), false, http.StatusRequestEntityTooLarge, domain.CodeLogTooLarge},
+		{"chunked under the limit", "panic: boom", true, http.StatusOK, ""},
+		{"chunked over the limit", strings.Repeat("x", 33), true, http.StatusRequestEntityTooLarge, domain.CodeLogTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.chunked {
+				body = readerOnly{body}
+			}
+			req := httptest.NewRequest(http.MethodPost, "/analyze", body)
+			req.Header.Set("Content-Type", "text/plain")
+			req.Header.Set("X-Request-ID", "req-1")
+			rec := httptest.NewRecorder()
+			newBodyLimitRouter(32).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantCode == "" {
+				if rec.Body.String() != tt.body {
+					t.Errorf("handler read %q, want %q", rec.Body, tt.body)
+				}
+				return
+			}
+			var resp domain.AnalysisResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error == nil {
+				t.Fatalf("body = %s, want an analysis error", rec.Body)
+			}
+			if resp.Error.Code != tt.wantCode || resp.RequestID != "req-1" {
+				t.Errorf("error code = %s, request_id = %q, want %s, req-1", resp.Error.Code, resp.RequestID, tt.wantCode)
+			}
+		})
+	}
+}
+
+// gzipped returns data compressed with gzip.
+func gzipped(t *testing.T, data string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(data))
+	if err := w.Close(); err != nil {
+		t.Fatalf("gzip Close() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestGzipBodyMiddleware(t *testing.T) {
+	const maxBytes = 64 << 10
+	bomb := gzipped(t, strings.Repeat("0", 16*maxBytes))
+	if len(bomb) >= maxBytes {
+		t.Fatalf("compressed bomb is %d bytes, want it under the limit", len(bomb))
+	}
+
+	tests := []struct {
+		name       string
+		encoding   string
+		body       string
+		wantStatus int
+		wantLog    string
+		wantCode   domain.ErrorCode
+	}{
+		{"gzip", "gzip", gzipped(t, "panic: boom"), http.StatusOK, "panic: boom", ""},
+		{"x-gzip", "X-Gzip", gzipped(t, "panic: boom"), http.StatusOK, "panic: boom", ""},
+		{"identity", "identity", "panic: boom", http.StatusOK, "panic: boom", ""},
+		{"no encoding", "", "panic: boom", http.StatusOK, "panic: boom", ""},
+		{"gzip bomb", "gzip", bomb, http.StatusRequestEntityTooLarge, "", domain.CodeLogTooLarge},
+		{"brotli", "br", "panic: boom", http.StatusUnsupportedMediaType, "", domain.CodeInvalidRequest},
+		{"not gzip", "gzip", "panic: boom", http.StatusBadRequest, "", domain.CodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(RequestIDMiddleware(), BodyLimitMiddleware(1<<20))
+			router.POST("/analyze", GzipBodyMiddleware(maxBytes), bindLog)
+
+			req := httptest.NewRequest(http.MethodPost, "/analyze", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "text/plain")
+			req.Header.Set("Content-Encoding", tt.encoding)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %.200s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantCode == "" {
+				if rec.Body.String() != tt.wantLog {
+					t.Errorf("handler read %q, want %q", rec.Body, tt.wantLog)
+				}
+				return
+			}
+			var resp domain.AnalysisResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error == nil {
+				t.Fatalf("body = %.200s, want an analysis error", rec.Body)
+			}
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("error code = %s, want %s", resp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
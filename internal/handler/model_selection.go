@@ -0,0 +1,38 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ModelSelectionStatsHandler reports how many analyses each model received.
+type ModelSelectionStatsHandler struct {
+	selector *ai.ModelSelector
+	logger   *zap.Logger
+}
+
+// NewModelSelectionStatsHandler creates a new ModelSelectionStatsHandler.
+// selector may be nil when model selection is disabled.
+func NewModelSelectionStatsHandler(selector *ai.ModelSelector, logger *zap.Logger) *ModelSelectionStatsHandler {
+	return &ModelSelectionStatsHandler{
+		selector: selector,
+		logger:   logger.Named("model_selection_stats_handler"),
+	}
+}
+
+// Handle processes GET /ai/model-selection requests.
+func (h *ModelSelectionStatsHandler) Handle(c *gin.Context) {
+	if h.selector == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"stats":   h.selector.Stats(),
+	})
+}
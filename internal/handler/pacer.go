@@ -0,0 +1,38 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PacerStatsHandler reports outbound AI request pacing metrics.
+type PacerStatsHandler struct {
+	pacer  *ai.Pacer
+	logger *zap.Logger
+}
+
+// NewPacerStatsHandler creates a new PacerStatsHandler.
+// pacer may be nil when pacing is disabled.
+func NewPacerStatsHandler(pacer *ai.Pacer, logger *zap.Logger) *PacerStatsHandler {
+	return &PacerStatsHandler{
+		pacer:  pacer,
+		logger: logger.Named("pacer_stats_handler"),
+	}
+}
+
+// Handle processes GET /pacer/stats requests.
+func (h *PacerStatsHandler) Handle(c *gin.Context) {
+	if h.pacer == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"stats":   h.pacer.Stats(),
+	})
+}
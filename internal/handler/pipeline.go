@@ -0,0 +1,96 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-devops/internal/ciconfig"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PipelineHandler handles CI pipeline analysis requests.
+type PipelineHandler struct {
+	analyzer    *service.Analyzer
+	maxLogBytes int
+	logger      *zap.Logger
+}
+
+// NewPipelineHandler creates a new PipelineHandler. Requests whose
+// pipeline config and failure log exceed maxLogBytes are rejected with 413.
+func NewPipelineHandler(analyzer *service.Analyzer, maxLogBytes int, logger *zap.Logger) *PipelineHandler {
+	return &PipelineHandler{
+		analyzer:    analyzer,
+		maxLogBytes: maxLogBytes,
+		logger:      logger.Named("pipeline_handler"),
+	}
+}
+
+// Handle processes POST /analyze/pipeline requests. The body is a
+// ciconfig.Request: the GitHub Actions workflow or GitLab CI file and the
+// log of the failed run. The response carries the review in "pipeline".
+func (h *PipelineHandler) Handle(c *gin.Context) {
+	startTime := time.Now()
+	logger := h.logger.With(zap.String("request_id", c.GetString("request_id")))
+
+	analysisReq, review, detail := h.bind(c)
+	if detail != nil {
+		logger.Warn("invalid request", zap.String("code", string(detail.Code)), zap.String("error", detail.Message))
+		writeAnalysisResponse(c, detail.Code.HTTPStatus(), &domain.AnalysisResponse{
+			Success:     false,
+			Error:       detail,
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+	if explain, err := strconv.ParseBool(c.Query("explain")); err == nil && explain {
+		analysisReq.Explain = true
+	}
+
+	response, err := h.analyzer.AnalyzePipeline(c.Request.Context(), analysisReq, review)
+	if err != nil {
+		logger.Error("pipeline analysis failed", zap.Error(err))
+		writeAnalysisResponse(c, http.StatusInternalServerError, &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInternal, "Internal error during analysis"),
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+
+	logger.Info("pipeline analysis completed",
+		zap.Bool("success", response.Success),
+		zap.String("source", response.Source),
+		zap.String("provider", review.Provider),
+		zap.Int("findings", len(review.Findings)),
+		zap.Duration("duration", time.Since(startTime)),
+	)
+
+	writeAnalysisResponse(c, responseStatus(response), response)
+}
+
+// bind decodes the JSON request body and builds the analysis request,
+// checked like the analyze endpoint's.
+func (h *PipelineHandler) bind(c *gin.Context) (*domain.AnalysisRequest, *domain.PipelineReview, *domain.ErrorDetail) {
+	var req ciconfig.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, nil, bodyTooLarge(tooLarge.Limit)
+		}
+		return nil, nil, domain.NewErrorDetail(domain.CodeInvalidRequest, "Invalid request body: "+err.Error())
+	}
+	analysisReq, review, err := req.AnalysisRequest()
+	if err != nil {
+		return nil, nil, domain.ErrorDetailFor(err)
+	}
+	if detail := checkAnalysisRequest(analysisReq, h.maxLogBytes); detail != nil {
+		return nil, nil, detail
+	}
+	return analysisReq, review, nil
+}
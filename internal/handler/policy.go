@@ -0,0 +1,40 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"crypto/sha256"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// provenanceModeKey is the gin context key holding the provenance redaction
+// mode for the current client.
+const provenanceModeKey = "provenance_mode"
+
+// ResponsePolicyMiddleware marks requests whose X-API-Key is one of keys so
+// that analysis responses hide their provenance (see applyResponsePolicy).
+// Keys are compared by hash so lookups do not leak timing information.
+func ResponsePolicyMiddleware(keys []string, mode domain.ProvenanceMode) gin.HandlerFunc {
+	redacted := make(map[[sha256.Size]byte]bool, len(keys))
+	for _, key := range keys {
+		redacted[sha256.Sum256([]byte(key))] = true
+	}
+
+	return func(c *gin.Context) {
+		if key := c.GetHeader("X-API-Key"); key != "" && redacted[sha256.Sum256([]byte(key))] {
+			c.Set(provenanceModeKey, mode)
+		}
+		c.Next()
+	}
+}
+
+// applyResponsePolicy returns the response as the client may see it. It is
+// called after the response has been stored and logged, which keep the full
+// provenance.
+func applyResponsePolicy(c *gin.Context, response *domain.AnalysisResponse) *domain.AnalysisResponse {
+	if mode, ok := c.Get(provenanceModeKey); ok {
+		return response.WithoutProvenance(mode.(domain.ProvenanceMode))
+	}
+	return response
+}
@@ -0,0 +1,113 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/pkg/sanitizer"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RulesHandler lists the rules the engine applies and dry-runs ad-hoc rules.
+type RulesHandler struct {
+	engine    *rules.Engine
+	sanitizer *sanitizer.Sanitizer
+	logger    *zap.Logger
+}
+
+// NewRulesHandler creates a new RulesHandler. Logs sent to Test are
+// sanitized with logSanitizer, as the engine only sees sanitized logs.
+func NewRulesHandler(engine *rules.Engine, logSanitizer *sanitizer.Sanitizer, logger *zap.Logger) *RulesHandler {
+	return &RulesHandler{
+		engine:    engine,
+		sanitizer: logSanitizer,
+		logger:    logger.Named("rules_handler"),
+	}
+}
+
+// ruleTestRequest is the body of POST /rules/test.
+type ruleTestRequest struct {
+	Log      string              `json:"log" binding:"required"`
+	Metadata *domain.LogMetadata `json:"metadata"`
+	Rule     rules.Definition    `json:"rule"`
+}
+
+// List processes GET /rules requests. The optional category and tag query
+// parameters filter the listing; categories counts the active rules of each
+// category regardless of filters.
+func (h *RulesHandler) List(c *gin.Context) {
+	category := c.Query("category")
+	tag := c.Query("tag")
+
+	summaries := []rules.Summary{}
+	categories := make(map[string]int)
+	for _, rule := range h.engine.Rules() {
+		if rule.Category != "" {
+			categories[rule.Category]++
+		}
+		if category != "" && !strings.EqualFold(rule.Category, category) {
+			continue
+		}
+		if tag != "" && !rule.HasTag(tag) {
+			continue
+		}
+		summaries = append(summaries, rule.Summary())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"rules":      summaries,
+		"categories": categories,
+	})
+}
+
+// Test processes POST /rules/test requests: it evaluates an ad-hoc rule
+// against a log without deploying it and reports whether it matches, its
+// confidence and which keywords and patterns hit.
+func (h *RulesHandler) Test(c *gin.Context) {
+	var req ruleTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   domain.NewErrorDetail(domain.CodeInvalidRequest, "Invalid request body: "+err.Error()),
+		})
+		return
+	}
+	if h.sanitizer.IsTooLarge(req.Log) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   domain.ErrorDetailFor(domain.ErrLogTooLarge),
+		})
+		return
+	}
+
+	rule, err := req.Rule.Compile()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   domain.NewErrorDetail(domain.CodeInvalidRequest, "Invalid rule: "+err.Error()),
+		})
+		return
+	}
+
+	log, _ := h.sanitizer.SanitizeWithStats(req.Log)
+	result := rules.DryRun(rule, log, req.Metadata)
+	threshold := h.engine.ConfidenceThreshold()
+
+	h.logger.Debug("rule dry run",
+		zap.String("rule_id", rule.ID),
+		zap.Bool("matched", result.Matched),
+		zap.Float64("confidence", result.Confidence),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"result":    result,
+		"threshold": threshold,
+		"would_use": result.Matched && result.Confidence >= threshold,
+	})
+}
@@ -0,0 +1,42 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TaxonomyHandler serves the canonical error type taxonomy.
+type TaxonomyHandler struct {
+	logger *zap.Logger
+}
+
+// NewTaxonomyHandler creates a new TaxonomyHandler.
+func NewTaxonomyHandler(logger *zap.Logger) *TaxonomyHandler {
+	return &TaxonomyHandler{
+		logger: logger.Named("taxonomy_handler"),
+	}
+}
+
+// Handle processes GET /taxonomy requests: the canonical error types with
+// their categories, for dashboards aggregating by error_type.
+func (h *TaxonomyHandler) Handle(c *gin.Context) {
+	errorTypes := domain.ErrorTaxonomy()
+	if category := c.Query("category"); category != "" {
+		filtered := errorTypes[:0]
+		for _, info := range errorTypes {
+			if info.Category == category {
+				filtered = append(filtered, info)
+			}
+		}
+		errorTypes = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"count":       len(errorTypes),
+		"error_types": errorTypes,
+	})
+}
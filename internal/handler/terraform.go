@@ -0,0 +1,66 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TerraformHandler handles Terraform JSON stream analysis requests.
+type TerraformHandler struct {
+	analyzer    *service.TerraformAnalyzer
+	maxLogBytes int
+	logger      *zap.Logger
+}
+
+// NewTerraformHandler creates a new TerraformHandler. Requests whose log
+// exceeds maxLogBytes are rejected with 413.
+func NewTerraformHandler(analyzer *service.TerraformAnalyzer, maxLogBytes int, logger *zap.Logger) *TerraformHandler {
+	return &TerraformHandler{
+		analyzer:    analyzer,
+		maxLogBytes: maxLogBytes,
+		logger:      logger.Named("terraform_handler"),
+	}
+}
+
+// Handle processes POST /analyze/terraform requests.
+// The request "log" field carries the output of `terraform plan -json`
+// or `terraform apply -json`.
+func (h *TerraformHandler) Handle(c *gin.Context) {
+	startTime := time.Now()
+	logger := h.logger.With(zap.String("request_id", c.GetString("request_id")))
+
+	var req domain.AnalysisRequest
+	if detail := bindAnalysisRequest(c, &req, h.maxLogBytes); detail != nil {
+		logger.Warn("invalid request", zap.String("code", string(detail.Code)), zap.String("error", detail.Message))
+		writeAnalysisResponse(c, detail.Code.HTTPStatus(), &domain.AnalysisResponse{
+			Success:     false,
+			Error:       detail,
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+
+	response, err := h.analyzer.Analyze(c.Request.Context(), &req)
+	if err != nil {
+		logger.Error("terraform analysis failed", zap.Error(err))
+		writeAnalysisResponse(c, http.StatusInternalServerError, &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInternal, "Internal error during analysis"),
+			ProcessedAt: time.Now(),
+		})
+		return
+	}
+
+	logger.Info("terraform analysis completed",
+		zap.Bool("success", response.Success),
+		zap.Duration("duration", time.Since(startTime)),
+	)
+
+	writeAnalysisResponse(c, responseStatus(response), response)
+}
@@ -0,0 +1,41 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/rules"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ThresholdHandler reports the rule confidence threshold and its audit trail.
+type ThresholdHandler struct {
+	engine     *rules.Engine
+	controller *rules.AdaptiveController
+	logger     *zap.Logger
+}
+
+// NewThresholdHandler creates a new ThresholdHandler.
+// controller may be nil when adaptive thresholds are disabled.
+func NewThresholdHandler(engine *rules.Engine, controller *rules.AdaptiveController, logger *zap.Logger) *ThresholdHandler {
+	return &ThresholdHandler{
+		engine:     engine,
+		controller: controller,
+		logger:     logger.Named("threshold_handler"),
+	}
+}
+
+// Handle processes GET /rules/threshold requests.
+func (h *ThresholdHandler) Handle(c *gin.Context) {
+	adjustments := []rules.ThresholdAdjustment{}
+	if h.controller != nil {
+		adjustments = h.controller.Audit()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"threshold":   h.engine.ConfidenceThreshold(),
+		"adaptive":    h.controller != nil,
+		"adjustments": adjustments,
+	})
+}
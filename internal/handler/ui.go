@@ -0,0 +1,22 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/ui"
+	"github.com/gin-gonic/gin"
+)
+
+// UIHandler serves the built-in web UI.
+type UIHandler struct{}
+
+// NewUIHandler creates a new UIHandler.
+func NewUIHandler() *UIHandler {
+	return &UIHandler{}
+}
+
+// Handle processes GET /ui requests.
+func (h *UIHandler) Handle(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", ui.Index)
+}
@@ -0,0 +1,36 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ai-devops/internal/usage"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UsageHandler reports AI token spend against the service budget and the
+// analyses and token spend of each tenant this month.
+type UsageHandler struct {
+	meter   *usage.Meter
+	tenants *usage.TenantMeter
+	logger  *zap.Logger
+}
+
+// NewUsageHandler creates a new UsageHandler. Either meter may be nil.
+func NewUsageHandler(meter *usage.Meter, tenants *usage.TenantMeter, logger *zap.Logger) *UsageHandler {
+	return &UsageHandler{
+		meter:   meter,
+		tenants: tenants,
+		logger:  logger.Named("usage_handler"),
+	}
+}
+
+// Handle processes GET /admin/usage requests.
+func (h *UsageHandler) Handle(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"budget":  h.meter.Budget().Snapshot(),
+		"tenants": h.tenants.Report(),
+	})
+}
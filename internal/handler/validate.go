@@ -0,0 +1,164 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Request body formats accepted by the analyze endpoints besides JSON.
+const (
+	mimePlainText = "text/plain"
+	mimeMultipart = "multipart/form-data"
+)
+
+// multipartRequestField is the optional multipart field holding the JSON
+// request envelope; multipartLogField holds the uploaded log files.
+const (
+	multipartRequestField = "request"
+	multipartLogField     = "log"
+)
+
+// bindAnalysisRequest decodes an analysis request body and checks it before
+// it reaches the service: a body over the router's size limit or a log over
+// maxLogBytes is too large, and a request without log content (or a
+// context query to fetch it) is empty. maxLogBytes <= 0 disables the log
+// size check.
+func bindAnalysisRequest(c *gin.Context, req *domain.AnalysisRequest, maxLogBytes int) *domain.ErrorDetail {
+	if err := decodeAnalysisRequest(c, req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return bodyTooLarge(tooLarge.Limit)
+		}
+		return domain.NewErrorDetail(domain.CodeInvalidRequest, "Invalid request body: "+err.Error())
+	}
+	return checkAnalysisRequest(req, maxLogBytes)
+}
+
+// checkAnalysisRequest checks the size and content of a decoded analysis
+// request; see bindAnalysisRequest.
+func checkAnalysisRequest(req *domain.AnalysisRequest, maxLogBytes int) *domain.ErrorDetail {
+	size := len(req.Log)
+	empty := strings.TrimSpace(req.Log) == ""
+	for _, section := range req.Sections {
+		size += len(section.Content)
+		if strings.TrimSpace(section.Content) != "" {
+			empty = false
+		}
+	}
+	if empty && req.Context == nil {
+		return domain.ErrorDetailFor(domain.ErrEmptyLog)
+	}
+	if maxLogBytes > 0 && size > maxLogBytes {
+		return domain.NewErrorDetail(domain.CodeLogTooLarge, fmt.Sprintf("log is %d bytes, the limit is %d", size, maxLogBytes))
+	}
+	return nil
+}
+
+// decodeAnalysisRequest decodes the body by content type:
+//   - application/json (default): the request envelope.
+//   - text/plain: the raw log; language, detail, mode and timeout_ms may be
+//     given as query parameters.
+//   - multipart/form-data: one or more files in the "log" field and an
+//     optional JSON envelope in the "request" field. A single file becomes
+//     the log; several become sections named after their file names.
+func decodeAnalysisRequest(c *gin.Context, req *domain.AnalysisRequest) error {
+	switch c.ContentType() {
+	case mimePlainText:
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		req.Log = string(data)
+		return bindQueryOptions(c, req)
+	case mimeMultipart:
+		return decodeMultipart(c, req)
+	default:
+		return c.ShouldBindJSON(req)
+	}
+}
+
+// decodeMultipart decodes a multipart analysis request.
+func decodeMultipart(c *gin.Context, req *domain.AnalysisRequest) error {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return err
+	}
+	if envelope := form.Value[multipartRequestField]; len(envelope) > 0 {
+		if err := json.Unmarshal([]byte(envelope[0]), req); err != nil {
+			return fmt.Errorf("%s field: %w", multipartRequestField, err)
+		}
+	}
+
+	files := form.File[multipartLogField]
+	if len(files) == 0 {
+		if len(form.Value[multipartRequestField]) == 0 {
+			return fmt.Errorf("multipart body has no %q file", multipartLogField)
+		}
+		return nil
+	}
+	if len(files) == 1 && req.Log == "" && len(req.Sections) == 0 {
+		req.Log, err = readFormFile(files[0])
+		return err
+	}
+	for _, file := range files {
+		content, err := readFormFile(file)
+		if err != nil {
+			return err
+		}
+		req.Sections = append(req.Sections, domain.LogSection{Name: file.Filename, Content: content})
+	}
+	return nil
+}
+
+// readFormFile returns the content of an uploaded file.
+func readFormFile(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", header.Filename, err)
+	}
+	return string(data), nil
+}
+
+// bindQueryOptions sets the request options given as query parameters.
+func bindQueryOptions(c *gin.Context, req *domain.AnalysisRequest) error {
+	if language := c.Query("language"); language != "" {
+		req.Language = language
+	}
+	if detail := c.Query("detail"); detail != "" {
+		req.Detail = domain.DetailLevel(detail)
+	}
+	if mode := c.Query("mode"); mode != "" {
+		req.Mode = domain.AnalysisMode(mode)
+	}
+	if timeout := c.Query("timeout_ms"); timeout != "" {
+		ms, err := strconv.Atoi(timeout)
+		if err != nil {
+			return fmt.Errorf("timeout_ms: %w", err)
+		}
+		req.TimeoutMS = ms
+	}
+	if ticket := c.Query("ticket"); ticket != "" {
+		file, err := strconv.ParseBool(ticket)
+		if err != nil {
+			return fmt.Errorf("ticket: %w", err)
+		}
+		req.Ticket = &file
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Package handler provides unit tests for analysis request decoding and checks.
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// formFile is a file of a multipart test body.
+type formFile struct {
+	name    string
+	content string
+}
+
+// multipartBody returns a multipart body with an optional request envelope
+// and log files, and its content type.
+func multipartBody(t *testing.T, envelope string, files ...formFile) (string, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if envelope != "" {
+		if err := w.WriteField(multipartRequestField, envelope); err != nil {
+			t.Fatalf("WriteField() error = %v", err)
+		}
+	}
+	for _, f := range files {
+		part, err := w.CreateFormFile(multipartLogField, f.name)
+		if err != nil {
+			t.Fatalf("CreateFormFile() error = %v", err)
+		}
+		part.Write([]byte(f.content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.String(), w.FormDataContentType()
+}
+
+// newRequestContext returns a gin context for a POST of body to target.
+func newRequestContext(target, contentType, body string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	return c
+}
+
+func TestDecodeAnalysisRequest(t *testing.T) {
+	ticket := true
+	single, singleType := multipartBody(t, "", formFile{"build.log", "npm ERR! missing script"})
+	several, severalType := multipartBody(t, `{"language":"vi"}`,
+		formFile{"build.log", "npm ERR! missing script"},
+		formFile{"deploy.log", "Error: ImagePullBackOff"},
+	)
+	envelopeOnly, envelopeOnlyType := multipartBody(t, `{"log":"panic: boom","detail":"brief"}`)
+	empty, emptyType := multipartBody(t, "")
+	badEnvelope, badEnvelopeType := multipartBody(t, `{"log":`, formFile{"build.log", "x"})
+
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		want        domain.AnalysisRequest
+		wantErr     bool
+	}{
+		{
+			name:        "json envelope",
+			target:      "/analyze",
+			contentType: "application/json",
+			body:        `{"log":"panic: boom","mode":"ci"}`,
+			want:        domain.AnalysisRequest{Log: "panic: boom", Mode: domain.AnalysisModeCI},
+		},
+		{
+			name:        "text plain with query options",
+			target:      "/analyze?language=vi&detail=brief&mode=ci&timeout_ms=1500&ticket=true",
+			contentType: "text/plain; charset=utf-8",
+			body:        "npm ERR! missing script: build\n",
+			want: domain.AnalysisRequest{
+				Log:       "npm ERR! missing script: build\n",
+				Language:  "vi",
+				Detail:    domain.DetailBrief,
+				Mode:      domain.AnalysisModeCI,
+				TimeoutMS: 1500,
+				Ticket:    &ticket,
+			},
+		},
+		{
+			name:        "single file",
+			target:      "/analyze",
+			contentType: singleType,
+			body:        single,
+			want:        domain.AnalysisRequest{Log: "npm ERR! missing script"},
+		},
+		{
+			name:        "several files become sections",
+			target:      "/analyze",
+			contentType: severalType,
+			body:        several,
+			want: domain.AnalysisRequest{
+				Language: "vi",
+				Sections: []domain.LogSection{
+					{Name: "build.log", Content: "npm ERR! missing script"},
+					{Name: "deploy.log", Content: "Error: ImagePullBackOff"},
+				},
+			},
+		},
+		{
+			name:        "envelope without a file",
+			target:      "/analyze",
+			contentType: envelopeOnlyType,
+			body:        envelopeOnly,
+			want:        domain.AnalysisRequest{Log: "panic: boom", Detail: domain.DetailBrief},
+		},
+		{
+			name:        "multipart without a file or envelope",
+			target:      "/analyze",
+			contentType: emptyType,
+			body:        empty,
+			wantErr:     true,
+		},
+		{
+			name:        "invalid envelope",
+			target:      "/analyze",
+			contentType: badEnvelopeType,
+			body:        badEnvelope,
+			wantErr:     true,
+		},
+		{
+			name:        "invalid json",
+			target:      "/analyze",
+			contentType: "application/json",
+			body:        `{"log":`,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got domain.AnalysisRequest
+			err := decodeAnalysisRequest(newRequestContext(tt.target, tt.contentType, tt.body), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeAnalysisRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("request = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindQueryOptions_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		field string
+	}{
+		{"timeout not a number", "timeout_ms=soon", "timeout_ms"},
+		{"timeout fraction", "timeout_ms=1.5", "timeout_ms"},
+		{"ticket not a bool", "ticket=maybe", "ticket"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req domain.AnalysisRequest
+			err := bindQueryOptions(newRequestContext("/analyze?"+tt.query, "text/plain", "log"), &req)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.field+":") {
+				t.Errorf("bindQueryOptions() error = %v, want a %s error", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestCheckAnalysisRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         domain.AnalysisRequest
+		maxLogBytes int
+		want        domain.ErrorCode
+	}{
+		{"log", domain.AnalysisRequest{Log: "panic: boom"}, 100, ""},
+		{"empty", domain.AnalysisRequest{}, 100, domain.CodeEmptyLog},
+		{"whitespace only", domain.AnalysisRequest{Log: " \n\t"}, 100, domain.CodeEmptyLog},
+		{"sections", domain.AnalysisRequest{Sections: []domain.LogSection{{Name: "build", Content: "panic: boom"}}}, 100, ""},
+		{"blank sections", domain.AnalysisRequest{Sections: []domain.LogSection{{Name: "build", Content: " "}}}, 100, domain.CodeEmptyLog},
+		{"context query without log", domain.AnalysisRequest{Context: &domain.ContextQuery{Labels: map[string]string{"app": "api"}}}, 100, ""},
+		{"log at the limit", domain.AnalysisRequest{Log: "0123456789"}, 10, ""},
+		{"log over the limit", domain.AnalysisRequest{Log: "0123456789x"}, 10, domain.CodeLogTooLarge},
+		{
+			"sections count toward the limit",
+			domain.AnalysisRequest{Log: "01234", Sections: []domain.LogSection{{Name: "a", Content: "56789"}, {Name: "b", Content: "x"}}},
+			10, domain.CodeLogTooLarge,
+		},
+		{"no limit", domain.AnalysisRequest{Log: strings.Repeat("x", 1000)}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail := checkAnalysisRequest(&tt.req, tt.maxLogBytes)
+			var got domain.ErrorCode
+			if detail != nil {
+				got = detail.Code
+			}
+			if got != tt.want {
+				t.Errorf("checkAnalysisRequest() = %v, want code %q", detail, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package handler contains HTTP handlers for the API.
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ai-devops/internal/webhook"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxWebhookBodySize bounds the body read for signature verification.
+const maxWebhookBodySize = 5 << 20 // 5MB
+
+// WebhookAuthMiddleware verifies inbound webhooks for an integration before
+// they reach the receiver. integration may be nil when no secret is
+// configured, in which case every request is rejected. The verified body is
+// restored for downstream handlers.
+func WebhookAuthMiddleware(integration *webhook.Integration, guard *webhook.ReplayGuard, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("webhook_auth")
+
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "failed to read request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if integration == nil {
+			err = webhook.ErrNotConfigured
+		} else {
+			err = integration.Verify(c.Request.Header, body, guard)
+		}
+
+		if err != nil {
+			name := c.Param("integration")
+			if integration != nil {
+				name = integration.Name
+			}
+			logger.Warn("webhook rejected",
+				zap.String("integration", name),
+				zap.String("reason", rejectionReason(err)),
+				zap.Error(err),
+				zap.String("request_id", c.GetString("request_id")),
+				zap.String("client_ip", c.ClientIP()),
+				zap.String("path", c.Request.URL.Path),
+			)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "webhook verification failed",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// WebhookRouteMiddleware verifies inbound webhooks with the integration named
+// by the :integration path parameter. Integrations missing from integrations
+// are rejected.
+func WebhookRouteMiddleware(integrations map[string]*webhook.Integration, guard *webhook.ReplayGuard, logger *zap.Logger) gin.HandlerFunc {
+	verifiers := make(map[string]gin.HandlerFunc, len(integrations))
+	for name, integration := range integrations {
+		verifiers[name] = WebhookAuthMiddleware(integration, guard, logger)
+	}
+	reject := WebhookAuthMiddleware(nil, guard, logger)
+
+	return func(c *gin.Context) {
+		if verify, ok := verifiers[c.Param("integration")]; ok {
+			verify(c)
+			return
+		}
+		reject(c)
+	}
+}
+
+// rejectionReason maps verification errors to stable log values.
+func rejectionReason(err error) string {
+	switch {
+	case errors.Is(err, webhook.ErrMissingSignature):
+		return "missing_signature"
+	case errors.Is(err, webhook.ErrInvalidSignature):
+		return "invalid_signature"
+	case errors.Is(err, webhook.ErrReplayed):
+		return "replayed"
+	case errors.Is(err, webhook.ErrStale):
+		return "stale_timestamp"
+	case errors.Is(err, webhook.ErrNotConfigured):
+		return "not_configured"
+	default:
+		return "unknown"
+	}
+}
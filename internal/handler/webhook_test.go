@@ -0,0 +1,68 @@
+// Package handler provides unit tests for inbound webhook verification.
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/ingest"
+	"github.com/ai-devops/internal/webhook"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestWebhookRouteMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		integration string
+		token       string
+		wantStatus  int
+		wantStream  string
+	}{
+		{"verified delivery", "gitlab", "tok", http.StatusOK, "webhook/gitlab"},
+		{"wrong token", "gitlab", "nope", http.StatusUnauthorized, ""},
+		{"unconfigured integration", "argocd", "tok", http.StatusUnauthorized, ""},
+		{"unknown integration", "jenkins", "tok", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingester, err := ingest.New(nil, ingest.Config{WindowLines: 10, MinErrors: 1, MaxStreams: 10, Settle: time.Hour}, zap.NewNop())
+			if err != nil {
+				t.Fatalf("ingest.New() error = %v", err)
+			}
+			defer ingester.Close()
+
+			integrations := map[string]*webhook.Integration{"gitlab": webhook.GitLab("tok")}
+			router := gin.New()
+			router.POST("/ingest/webhooks/:integration",
+				WebhookRouteMiddleware(integrations, webhook.NewReplayGuard(time.Minute), zap.NewNop()),
+				NewIngestHandler(ingester, zap.NewNop()).Webhook,
+			)
+
+			req := httptest.NewRequest(http.MethodPost, "/ingest/webhooks/"+tt.integration, strings.NewReader(`{"object_kind":"pipeline"}`))
+			req.Header.Set("X-Gitlab-Token", tt.token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			streams := ingester.Streams()
+			if tt.wantStream == "" {
+				if len(streams) != 0 {
+					t.Errorf("rejected delivery ingested into %q", streams[0].Stream)
+				}
+				return
+			}
+			if len(streams) != 1 || streams[0].Stream != tt.wantStream || streams[0].Records != 1 {
+				t.Errorf("streams = %+v, want one record in %q", streams, tt.wantStream)
+			}
+		})
+	}
+}
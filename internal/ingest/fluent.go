@@ -0,0 +1,141 @@
+// Package ingest accepts log records from log shippers and analyzes error
+// bursts in each stream.
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// defaultStream names the stream of records without a tag.
+const defaultStream = "default"
+
+// Record is one log line of a stream.
+type Record struct {
+	// Stream identifies the source, e.g. a Fluent Bit tag or a Kubernetes
+	// container.
+	Stream string
+
+	// Time is when the line was logged; zero if the record had no timestamp.
+	Time time.Time
+
+	// Line is the log text.
+	Line string
+}
+
+// Record fields holding the log text, the timestamp and the tag, in order
+// of preference. They cover Fluent Bit's tail, docker and systemd inputs
+// and Fluentd's defaults.
+var (
+	messageKeys = []string{"log", "message", "msg", "MESSAGE"}
+	timeKeys    = []string{"date", "time", "timestamp", "@timestamp"}
+	tagKeys     = []string{"tag", "fluent_tag"}
+)
+
+// ParseFluent decodes the body of a Fluent Bit or Fluentd HTTP output:
+// newline-delimited JSON records (Fluent Bit "json_lines", Fluentd "ndjson")
+// or a JSON array of records (Fluent Bit "json", Fluentd "json_array").
+// Records without a tag field belong to stream tag. Records enriched with
+// Kubernetes metadata are split per container.
+func ParseFluent(body []byte, tag string) ([]Record, error) {
+	if tag == "" {
+		tag = defaultStream
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, fmt.Errorf("%w: empty body", domain.ErrInvalidRequest)
+	}
+
+	var raw []map[string]any
+	if body[0] == '[' {
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return nil, fmt.Errorf("%w: invalid JSON array: %v", domain.ErrInvalidRequest, err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		for dec.More() {
+			var fields map[string]any
+			if err := dec.Decode(&fields); err != nil {
+				return nil, fmt.Errorf("%w: invalid JSON record %d: %v", domain.ErrInvalidRequest, len(raw)+1, err)
+			}
+			raw = append(raw, fields)
+		}
+	}
+
+	records := make([]Record, 0, len(raw))
+	for _, fields := range raw {
+		if fields == nil {
+			continue
+		}
+		records = append(records, Record{
+			Stream: streamOf(fields, tag),
+			Time:   timeOf(fields),
+			Line:   lineOf(fields),
+		})
+	}
+	return records, nil
+}
+
+// streamOf returns the record's tag, refined by its Kubernetes container.
+func streamOf(fields map[string]any, tag string) string {
+	if t, ok := firstString(fields, tagKeys); ok && t != "" {
+		tag = t
+	}
+	k8s, ok := fields["kubernetes"].(map[string]any)
+	if !ok {
+		return tag
+	}
+	var parts []string
+	for _, key := range []string{"namespace_name", "pod_name", "container_name"} {
+		if v, ok := k8s[key].(string); ok && v != "" {
+			parts = append(parts, v)
+		}
+	}
+	if len(parts) == 0 {
+		return tag
+	}
+	return tag + "/" + strings.Join(parts, "/")
+}
+
+// lineOf returns the record's log text, or the record itself as JSON when
+// it has no known message field.
+func lineOf(fields map[string]any) string {
+	if line, ok := firstString(fields, messageKeys); ok {
+		return strings.TrimRight(line, "\r\n")
+	}
+	data, _ := json.Marshal(fields)
+	return string(data)
+}
+
+// timeOf parses the timestamp formats of Fluent Bit's json_date_format
+// (double, epoch, iso8601) and RFC 3339 strings.
+func timeOf(fields map[string]any) time.Time {
+	for _, key := range timeKeys {
+		switch v := fields[key].(type) {
+		case float64:
+			sec, frac := math.Modf(v)
+			return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t.UTC()
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func firstString(fields map[string]any, keys []string) (string, bool) {
+	for _, key := range keys {
+		if v, ok := fields[key].(string); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,215 @@
+// Package ingest provides unit tests for log shipper ingestion.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestParseFluent(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		tag       string
+		want      []Record
+		wantErrIs error
+	}{
+		{
+			name: "json_lines from tail input",
+			body: `{"date":1700000000.5,"log":"ERROR boom\n"}` + "\n" + `{"date":1700000001,"log":"at main.go:3"}`,
+			tag:  "app",
+			want: []Record{
+				{Stream: "app", Time: time.Unix(1700000000, 5e8).UTC(), Line: "ERROR boom"},
+				{Stream: "app", Time: time.Unix(1700000001, 0).UTC(), Line: "at main.go:3"},
+			},
+		},
+		{
+			name: "json array with tag field and iso8601 date",
+			body: `[{"tag":"nginx","date":"2024-05-01T10:00:00.000Z","message":"upstream timed out"}]`,
+			want: []Record{
+				{Stream: "nginx", Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Line: "upstream timed out"},
+			},
+		},
+		{
+			name: "kubernetes metadata",
+			body: `{"log":"OOMKilled","kubernetes":{"namespace_name":"prod","pod_name":"api-1","container_name":"api"}}`,
+			tag:  "kube",
+			want: []Record{{Stream: "kube/prod/api-1/api", Line: "OOMKilled"}},
+		},
+		{
+			name: "no message field",
+			body: `{"level":"error","code":7}`,
+			want: []Record{{Stream: "default", Line: `{"code":7,"level":"error"}`}},
+		},
+		{
+			name:      "invalid record",
+			body:      `{"log":"ok"}` + "\n" + `{"log":`,
+			wantErrIs: domain.ErrInvalidRequest,
+		},
+		{
+			name:      "empty body",
+			body:      "  \n",
+			wantErrIs: domain.ErrInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFluent([]byte(tt.body), tt.tag)
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErrIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFluent() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d records, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].Stream != tt.want[i].Stream || got[i].Line != tt.want[i].Line || !got[i].Time.Equal(tt.want[i].Time) {
+					t.Errorf("record %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// recordingAnalyzer records the logs it analyzes.
+type recordingAnalyzer struct {
+	mu   sync.Mutex
+	logs []string
+	done chan struct{}
+}
+
+func (a *recordingAnalyzer) Analyze(_ context.Context, req *domain.AnalysisRequest) (*domain.AnalysisResponse, error) {
+	a.mu.Lock()
+	a.logs = append(a.logs, req.Log)
+	a.mu.Unlock()
+	a.done <- struct{}{}
+	return &domain.AnalysisResponse{
+		Success: true,
+		Source:  "rules:test",
+		Result:  &domain.AnalysisResult{ErrorType: "test", Severity: domain.SeverityHigh},
+	}, nil
+}
+
+func newTestIngester(t *testing.T, cfg Config) (*Ingester, *recordingAnalyzer) {
+	t.Helper()
+	analyzer := &recordingAnalyzer{done: make(chan struct{}, 10)}
+	ingester, err := New(analyzer, cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(ingester.Close)
+	return ingester, analyzer
+}
+
+func lines(stream string, texts ...string) []Record {
+	records := make([]Record, len(texts))
+	for i, text := range texts {
+		records[i] = Record{Stream: stream, Line: text}
+	}
+	return records
+}
+
+func waitAnalysis(t *testing.T, a *recordingAnalyzer) {
+	t.Helper()
+	select {
+	case <-a.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an analysis")
+	}
+}
+
+func TestIngester_Burst(t *testing.T) {
+	ingester, analyzer := newTestIngester(t, Config{
+		WindowLines: 3, Settle: 10 * time.Millisecond, MinErrors: 1, Cooldown: time.Hour, MaxStreams: 10,
+	})
+
+	ingester.Ingest(lines("api", "INFO start", "INFO ready", "ERROR db down"))
+	ingester.Ingest(lines("api", "\tat db.go:12"))
+	ingester.Ingest(lines("web", "INFO ok"))
+	waitAnalysis(t, analyzer)
+
+	if got, want := analyzer.logs[0], "INFO ready\nERROR db down\n\tat db.go:12"; got != want {
+		t.Errorf("analyzed %q, want %q", got, want)
+	}
+
+	// The cooldown suppresses the next burst of the stream
+	ingester.Ingest(lines("api", "ERROR db down"))
+	time.Sleep(30 * time.Millisecond)
+
+	stats := ingester.Streams()
+	if len(stats) != 2 {
+		t.Fatalf("got %d streams, want 2", len(stats))
+	}
+	for _, s := range stats {
+		if s.Stream != "api" {
+			continue
+		}
+		if s.Records != 5 || s.ErrorLines != 2 || s.Bursts != 1 || s.Suppressed != 1 {
+			t.Errorf("unexpected stats %+v", s)
+		}
+		if s.LastAnalysis == nil || s.LastAnalysis.ErrorType != "test" || s.LastAnalysis.Lines != 3 {
+			t.Errorf("unexpected last analysis %+v", s.LastAnalysis)
+		}
+	}
+	if len(analyzer.done) != 0 {
+		t.Error("burst during cooldown was analyzed")
+	}
+}
+
+func TestIngester_MinErrors(t *testing.T) {
+	ingester, analyzer := newTestIngester(t, Config{
+		WindowLines: 10, Settle: 10 * time.Millisecond, MinErrors: 2, MaxStreams: 10,
+	})
+
+	ingester.Ingest(lines("api", "ERROR transient"))
+	time.Sleep(30 * time.Millisecond)
+	if len(analyzer.done) != 0 {
+		t.Fatal("a single error line was analyzed")
+	}
+
+	ingester.Ingest(lines("api", "ERROR one", "FATAL two"))
+	waitAnalysis(t, analyzer)
+	if !strings.Contains(analyzer.logs[0], "ERROR transient") {
+		t.Errorf("window should keep earlier lines, got %q", analyzer.logs[0])
+	}
+}
+
+func TestIngester_MaxStreams(t *testing.T) {
+	ingester, _ := newTestIngester(t, Config{
+		WindowLines: 10, Settle: time.Hour, MinErrors: 1, MaxStreams: 2,
+	})
+
+	ingester.Ingest(lines("a", "x"))
+	time.Sleep(time.Millisecond)
+	ingester.Ingest(lines("b", "x"))
+	time.Sleep(time.Millisecond)
+	ingester.Ingest(lines("c", "x"))
+
+	var names []string
+	for _, s := range ingester.Streams() {
+		names = append(names, s.Stream)
+	}
+	if strings.Join(names, ",") != "c,b" {
+		t.Errorf("streams = %v, want the two most recent", names)
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	_, err := New(&recordingAnalyzer{}, Config{WindowLines: 1, MinErrors: 1, MaxStreams: 1, ErrorPattern: "("}, zap.NewNop())
+	if !errors.Is(err, domain.ErrInvalidConfig) {
+		t.Errorf("error = %v, want ErrInvalidConfig", err)
+	}
+}
@@ -0,0 +1,296 @@
+// Package ingest accepts log records from log shippers and analyzes error
+// bursts in each stream.
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// DefaultErrorPattern matches the log lines that start an error burst.
+const DefaultErrorPattern = `(?i)\b(error|fatal|panic)\b|ERR!|Exception\b`
+
+// analysisTimeout bounds a background burst analysis.
+const analysisTimeout = 2 * time.Minute
+
+// Analyzer runs an analysis; *service.Analyzer implements it.
+type Analyzer interface {
+	Analyze(ctx context.Context, req *domain.AnalysisRequest) (*domain.AnalysisResponse, error)
+}
+
+// Config controls buffering and burst detection.
+type Config struct {
+	// WindowLines is the number of recent lines kept per stream and analyzed
+	// when a burst triggers.
+	WindowLines int
+
+	// Settle is how long a burst collects lines after its first error line
+	// before it is analyzed, so that the trace that follows is included.
+	Settle time.Duration
+
+	// MinErrors is the number of error lines within Settle that make a
+	// burst; fewer are ignored.
+	MinErrors int
+
+	// Cooldown is how long a stream is not analyzed again after a burst, so
+	// a crash-looping service is analyzed once.
+	Cooldown time.Duration
+
+	// MaxStreams bounds the streams buffered; the least recently active
+	// stream is dropped when a new one arrives.
+	MaxStreams int
+
+	// ErrorPattern is the regular expression of error lines.
+	ErrorPattern string
+}
+
+// Ingester buffers records per stream and analyzes error bursts in the
+// background. The results are stored and notified by the analyzer like any
+// other analysis. It is safe for concurrent use.
+type Ingester struct {
+	analyzer Analyzer
+	cfg      Config
+	pattern  *regexp.Regexp
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	streams map[string]*stream
+	closed  bool
+	running sync.WaitGroup
+}
+
+// stream is the buffer and burst state of one stream.
+type stream struct {
+	name     string
+	lines    []string
+	errors   int
+	burst    *time.Timer
+	cooldown time.Time
+	stats    StreamStats
+}
+
+// StreamStats describes a stream's activity.
+type StreamStats struct {
+	Stream   string    `json:"stream"`
+	LastSeen time.Time `json:"last_seen"`
+
+	// Records and ErrorLines count the lines received.
+	Records    int64 `json:"records"`
+	ErrorLines int64 `json:"error_lines"`
+
+	// Bursts counts the analyses triggered; Suppressed counts error lines
+	// ignored during a cooldown.
+	Bursts     int   `json:"bursts"`
+	Suppressed int64 `json:"suppressed"`
+
+	// Pending is true while a burst is settling.
+	Pending bool `json:"pending"`
+
+	// LastAnalysis is the outcome of the latest burst analysis.
+	LastAnalysis *BurstAnalysis `json:"last_analysis,omitempty"`
+}
+
+// BurstAnalysis is the outcome of a burst analysis.
+type BurstAnalysis struct {
+	At        time.Time           `json:"at"`
+	Lines     int                 `json:"lines"`
+	Success   bool                `json:"success"`
+	ID        string              `json:"id,omitempty"`
+	Source    string              `json:"source,omitempty"`
+	ErrorType string              `json:"error_type,omitempty"`
+	Severity  domain.Severity     `json:"severity,omitempty"`
+	Error     *domain.ErrorDetail `json:"error,omitempty"`
+}
+
+// New creates an Ingester.
+func New(analyzer Analyzer, cfg Config, logger *zap.Logger) (*Ingester, error) {
+	if cfg.ErrorPattern == "" {
+		cfg.ErrorPattern = DefaultErrorPattern
+	}
+	pattern, err := regexp.Compile(cfg.ErrorPattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid error pattern: %v", domain.ErrInvalidConfig, err)
+	}
+	if cfg.WindowLines < 1 || cfg.MinErrors < 1 || cfg.MaxStreams < 1 || cfg.Settle < 0 || cfg.Cooldown < 0 {
+		return nil, fmt.Errorf("%w: ingest window, min errors and max streams must be positive", domain.ErrInvalidConfig)
+	}
+
+	return &Ingester{
+		analyzer: analyzer,
+		cfg:      cfg,
+		pattern:  pattern,
+		logger:   logger.Named("ingest"),
+		streams:  make(map[string]*stream),
+	}, nil
+}
+
+// Ingest buffers records and starts a burst for each stream that logs an
+// error line. It returns immediately; bursts are analyzed in the background.
+func (i *Ingester) Ingest(records []Record) {
+	now := time.Now()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.closed {
+		return
+	}
+
+	for _, record := range records {
+		s := i.streamLocked(record.Stream, now)
+		s.stats.Records++
+		s.stats.LastSeen = now
+		s.lines = append(s.lines, record.Line)
+		if len(s.lines) > i.cfg.WindowLines {
+			s.lines = s.lines[len(s.lines)-i.cfg.WindowLines:]
+		}
+
+		if !i.pattern.MatchString(record.Line) {
+			continue
+		}
+		s.stats.ErrorLines++
+		if now.Before(s.cooldown) {
+			s.stats.Suppressed++
+			continue
+		}
+		s.errors++
+		if s.burst == nil {
+			name := s.name
+			s.burst = time.AfterFunc(i.cfg.Settle, func() { i.settle(name) })
+		}
+	}
+}
+
+// streamLocked returns the stream named name, creating it and evicting the
+// least recently active stream at capacity.
+func (i *Ingester) streamLocked(name string, now time.Time) *stream {
+	if s, ok := i.streams[name]; ok {
+		return s
+	}
+
+	if len(i.streams) >= i.cfg.MaxStreams {
+		var oldest *stream
+		for _, s := range i.streams {
+			if oldest == nil || s.stats.LastSeen.Before(oldest.stats.LastSeen) {
+				oldest = s
+			}
+		}
+		if oldest.burst != nil {
+			oldest.burst.Stop()
+		}
+		delete(i.streams, oldest.name)
+		i.logger.Debug("stream evicted", zap.String("stream", oldest.name))
+	}
+
+	s := &stream{name: name, stats: StreamStats{Stream: name, LastSeen: now}}
+	i.streams[name] = s
+	return s
+}
+
+// settle ends the burst of a stream and analyzes its window if the burst
+// had enough error lines.
+func (i *Ingester) settle(name string) {
+	i.mu.Lock()
+	s, ok := i.streams[name]
+	if !ok || i.closed {
+		i.mu.Unlock()
+		return
+	}
+	s.burst = nil
+	errors := s.errors
+	s.errors = 0
+	if errors < i.cfg.MinErrors {
+		i.mu.Unlock()
+		return
+	}
+
+	log := strings.Join(s.lines, "\n")
+	lines := len(s.lines)
+	s.lines = nil
+	s.cooldown = time.Now().Add(i.cfg.Cooldown)
+	s.stats.Bursts++
+	i.running.Add(1)
+	i.mu.Unlock()
+
+	i.logger.Info("error burst detected",
+		zap.String("stream", name),
+		zap.Int("error_lines", errors),
+		zap.Int("lines", lines),
+	)
+	go i.analyze(name, log, lines)
+}
+
+// analyze runs the analysis of a burst and records its outcome.
+func (i *Ingester) analyze(name, log string, lines int) {
+	defer i.running.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), analysisTimeout)
+	defer cancel()
+
+	outcome := &BurstAnalysis{At: time.Now(), Lines: lines}
+	resp, err := i.analyzer.Analyze(ctx, &domain.AnalysisRequest{Log: log})
+	if err != nil {
+		outcome.Error = domain.ErrorDetailFor(err)
+	} else {
+		outcome.Success = resp.Success
+		outcome.ID = resp.ID
+		outcome.Source = resp.Source
+		outcome.Error = resp.Error
+		if resp.Result != nil {
+			outcome.ErrorType = resp.Result.ErrorType
+			outcome.Severity = resp.Result.Severity
+		}
+	}
+	if !outcome.Success {
+		i.logger.Warn("burst analysis failed", zap.String("stream", name), zap.Any("error", outcome.Error))
+	}
+
+	i.mu.Lock()
+	if s, ok := i.streams[name]; ok {
+		s.stats.LastAnalysis = outcome
+	}
+	i.mu.Unlock()
+}
+
+// Streams returns the stats of the buffered streams, most recently active
+// first.
+func (i *Ingester) Streams() []StreamStats {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	stats := make([]StreamStats, 0, len(i.streams))
+	for _, s := range i.streams {
+		st := s.stats
+		st.Pending = s.burst != nil
+		stats = append(stats, st)
+	}
+	sort.Slice(stats, func(a, b int) bool {
+		if !stats[a].LastSeen.Equal(stats[b].LastSeen) {
+			return stats[a].LastSeen.After(stats[b].LastSeen)
+		}
+		return stats[a].Stream < stats[b].Stream
+	})
+	return stats
+}
+
+// Close drops settling bursts and waits for running analyses.
+func (i *Ingester) Close() {
+	i.mu.Lock()
+	i.closed = true
+	for _, s := range i.streams {
+		if s.burst != nil {
+			s.burst.Stop()
+			s.burst = nil
+		}
+	}
+	i.mu.Unlock()
+
+	i.running.Wait()
+}
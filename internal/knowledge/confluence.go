@@ -0,0 +1,124 @@
+// Package knowledge links analyses to organization runbooks kept in
+// knowledge sources such as a markdown repository, Confluence or Notion.
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// ConfluenceSource searches Confluence pages labeled with an error type or
+// tag, e.g. a page labeled "docker_image_not_found" or "registry".
+type ConfluenceSource struct {
+	baseURL    string
+	email      string
+	token      string
+	space      string
+	httpClient *http.Client
+}
+
+// NewConfluenceSource creates a Confluence source for the site at baseURL
+// (e.g. "https://example.atlassian.net/wiki"). Requests authenticate with
+// email and an API token; without an email the token is sent as a bearer
+// token (Confluence Data Center personal access tokens). A non-empty space
+// restricts the search to that space key.
+func NewConfluenceSource(baseURL, email, token, space string, httpClient *http.Client) *ConfluenceSource {
+	return &ConfluenceSource{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		email:      email,
+		token:      token,
+		space:      space,
+		httpClient: httpClient,
+	}
+}
+
+// confluenceSearchResponse is the content search response.
+type confluenceSearchResponse struct {
+	Results []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+		Links struct {
+			WebUI string `json:"webui"`
+		} `json:"_links"`
+	} `json:"results"`
+	Links struct {
+		Base string `json:"base"`
+	} `json:"_links"`
+}
+
+// Name implements Source.
+func (s *ConfluenceSource) Name() string { return "confluence" }
+
+// Search implements Source with a CQL label query.
+func (s *ConfluenceSource) Search(ctx context.Context, q Query, limit int) ([]domain.Runbook, error) {
+	params := url.Values{}
+	params.Set("cql", s.cql(q))
+	params.Set("limit", strconv.Itoa(limit))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/rest/api/content/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create confluence request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.email != "" {
+		req.SetBasicAuth(s.email, s.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("confluence search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("confluence search: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result confluenceSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode confluence response: %w", err)
+	}
+
+	base := result.Links.Base
+	if base == "" {
+		base = s.baseURL
+	}
+	runbooks := make([]domain.Runbook, 0, len(result.Results))
+	for _, page := range result.Results {
+		if page.Links.WebUI == "" {
+			continue
+		}
+		runbooks = append(runbooks, domain.Runbook{
+			ID:     page.ID,
+			Title:  page.Title,
+			URL:    base + page.Links.WebUI,
+			Source: s.Name(),
+		})
+	}
+	return runbooks, nil
+}
+
+// cql returns the CQL query for pages labeled with one of q's terms.
+func (s *ConfluenceSource) cql(q Query) string {
+	terms := q.terms()
+	labels := make([]string, len(terms))
+	for i, term := range terms {
+		labels[i] = strconv.Quote(term)
+	}
+	cql := "type = page AND label in (" + strings.Join(labels, ", ") + ")"
+	if s.space != "" {
+		cql += " AND space = " + strconv.Quote(s.space)
+	}
+	return cql
+}
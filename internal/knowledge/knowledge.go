@@ -0,0 +1,188 @@
+// Package knowledge links analyses to organization runbooks kept in
+// knowledge sources such as a markdown repository, Confluence or Notion.
+package knowledge
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// maxCachedQueries bounds the Finder's result cache.
+const maxCachedQueries = 1024
+
+// maxErrorBody bounds how much of an error response is reported.
+const maxErrorBody = 512
+
+// Query selects runbooks by the error type of a result and its tags (rule
+// tags, taxonomy category and subcategory).
+type Query struct {
+	ErrorType string
+	Tags      []string
+}
+
+// key identifies q in the cache.
+func (q Query) key() string {
+	tags := append([]string(nil), q.Tags...)
+	sort.Strings(tags)
+	return q.ErrorType + "|" + strings.Join(tags, ",")
+}
+
+// terms returns the error type and tags, lowercased and deduplicated.
+func (q Query) terms() []string {
+	seen := make(map[string]bool, len(q.Tags)+1)
+	var terms []string
+	for _, term := range append([]string{q.ErrorType}, q.Tags...) {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// Source is a knowledge source that can be searched for runbooks.
+type Source interface {
+	// Name identifies the source in results and logs.
+	Name() string
+
+	// Search returns up to limit runbooks matching q, best match first.
+	Search(ctx context.Context, q Query, limit int) ([]domain.Runbook, error)
+}
+
+// cacheEntry is a cached Find result.
+type cacheEntry struct {
+	runbooks []domain.Runbook
+	expires  time.Time
+}
+
+// Finder searches all configured sources for runbooks and merges the
+// results. Results are cached per query so remote sources are not queried
+// for every analysis.
+type Finder struct {
+	sources    []Source
+	maxResults int
+	timeout    time.Duration
+	cacheTTL   time.Duration
+	logger     *zap.Logger
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewFinder creates a Finder returning up to maxResults runbooks from
+// sources, each searched with timeout. Results are cached for cacheTTL;
+// zero disables the cache.
+func NewFinder(sources []Source, maxResults int, timeout, cacheTTL time.Duration, logger *zap.Logger) *Finder {
+	return &Finder{
+		sources:    sources,
+		maxResults: maxResults,
+		timeout:    timeout,
+		cacheTTL:   cacheTTL,
+		logger:     logger.Named("knowledge"),
+		now:        time.Now,
+		cache:      make(map[string]cacheEntry),
+	}
+}
+
+// Find returns the runbooks matching q, in source order, without duplicate
+// URLs. Failing sources are logged and skipped.
+func (f *Finder) Find(ctx context.Context, q Query) []domain.Runbook {
+	if len(f.sources) == 0 || f.maxResults <= 0 || len(q.terms()) == 0 {
+		return nil
+	}
+
+	key := q.key()
+	if runbooks, ok := f.cached(key); ok {
+		return runbooks
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	results := make([][]domain.Runbook, len(f.sources))
+	failed := false
+	var wg sync.WaitGroup
+	var failMu sync.Mutex
+	for i, source := range f.sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+			runbooks, err := source.Search(ctx, q, f.maxResults)
+			if err != nil {
+				f.logger.Warn("knowledge source search failed",
+					zap.String("source", source.Name()),
+					zap.Error(err),
+				)
+				failMu.Lock()
+				failed = true
+				failMu.Unlock()
+				return
+			}
+			results[i] = runbooks
+		}(i, source)
+	}
+	wg.Wait()
+
+	var merged []domain.Runbook
+	seen := make(map[string]bool)
+	for _, runbooks := range results {
+		for _, rb := range runbooks {
+			if rb.URL == "" || seen[rb.URL] || len(merged) == f.maxResults {
+				continue
+			}
+			seen[rb.URL] = true
+			merged = append(merged, rb)
+		}
+	}
+
+	// Failures are retried on the next analysis instead of cached
+	if !failed {
+		f.store(key, merged)
+	}
+	return merged
+}
+
+func (f *Finder) cached(key string) ([]domain.Runbook, bool) {
+	if f.cacheTTL <= 0 {
+		return nil, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.cache[key]
+	if !ok || f.now().After(entry.expires) {
+		return nil, false
+	}
+	return append([]domain.Runbook(nil), entry.runbooks...), true
+}
+
+func (f *Finder) store(key string, runbooks []domain.Runbook) {
+	if f.cacheTTL <= 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.cache) >= maxCachedQueries {
+		now := f.now()
+		for k, entry := range f.cache {
+			if now.After(entry.expires) {
+				delete(f.cache, k)
+			}
+		}
+		if len(f.cache) >= maxCachedQueries {
+			f.cache = make(map[string]cacheEntry)
+		}
+	}
+	f.cache[key] = cacheEntry{
+		runbooks: append([]domain.Runbook(nil), runbooks...),
+		expires:  f.now().Add(f.cacheTTL),
+	}
+}
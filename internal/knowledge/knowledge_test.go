@@ -0,0 +1,351 @@
+// Package knowledge provides unit tests for runbook sources and the Finder.
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// fakeSource returns fixed runbooks and counts searches.
+type fakeSource struct {
+	name     string
+	runbooks []domain.Runbook
+	err      error
+	calls    int
+	lastQ    Query
+}
+
+func (s *fakeSource) Name() string { return s.name }
+
+func (s *fakeSource) Search(_ context.Context, q Query, limit int) ([]domain.Runbook, error) {
+	s.calls++
+	s.lastQ = q
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(s.runbooks) > limit {
+		return s.runbooks[:limit], nil
+	}
+	return s.runbooks, nil
+}
+
+func TestFinder_MergesAndDeduplicates(t *testing.T) {
+	first := &fakeSource{name: "first", runbooks: []domain.Runbook{
+		{Title: "A", URL: "https://wiki/a"},
+		{Title: "B", URL: "https://wiki/b"},
+	}}
+	second := &fakeSource{name: "second", runbooks: []domain.Runbook{
+		{Title: "B again", URL: "https://wiki/b"},
+		{Title: "C", URL: "https://wiki/c"},
+		{Title: "D", URL: "https://wiki/d"},
+	}}
+	finder := NewFinder([]Source{first, second}, 3, time.Second, 0, zap.NewNop())
+
+	got := finder.Find(context.Background(), Query{ErrorType: "docker_image_not_found"})
+	want := []domain.Runbook{
+		{Title: "A", URL: "https://wiki/a"},
+		{Title: "B", URL: "https://wiki/b"},
+		{Title: "C", URL: "https://wiki/c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Find() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFinder_SkipsFailingSource(t *testing.T) {
+	failing := &fakeSource{name: "failing", err: errors.New("unavailable")}
+	ok := &fakeSource{name: "ok", runbooks: []domain.Runbook{{Title: "A", URL: "https://wiki/a"}}}
+	finder := NewFinder([]Source{failing, ok}, 3, time.Second, time.Minute, zap.NewNop())
+
+	for i := 0; i < 2; i++ {
+		got := finder.Find(context.Background(), Query{ErrorType: "x"})
+		if len(got) != 1 || got[0].URL != "https://wiki/a" {
+			t.Fatalf("Find() = %+v, want the healthy source's runbook", got)
+		}
+	}
+	// Results with a failed source are not cached
+	if ok.calls != 2 {
+		t.Errorf("healthy source searched %d times, want 2", ok.calls)
+	}
+}
+
+func TestFinder_CachesResults(t *testing.T) {
+	source := &fakeSource{name: "fake", runbooks: []domain.Runbook{{Title: "A", URL: "https://wiki/a"}}}
+	finder := NewFinder([]Source{source}, 3, time.Second, time.Minute, zap.NewNop())
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	finder.now = func() time.Time { return now }
+
+	q := Query{ErrorType: "x", Tags: []string{"b", "a"}}
+	finder.Find(context.Background(), q)
+	finder.Find(context.Background(), Query{ErrorType: "x", Tags: []string{"a", "b"}})
+	if source.calls != 1 {
+		t.Errorf("source searched %d times, want 1 (cached)", source.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	finder.Find(context.Background(), q)
+	if source.calls != 2 {
+		t.Errorf("source searched %d times after expiry, want 2", source.calls)
+	}
+}
+
+func TestFinder_EmptyQuery(t *testing.T) {
+	source := &fakeSource{name: "fake", runbooks: []domain.Runbook{{Title: "A", URL: "https://wiki/a"}}}
+	finder := NewFinder([]Source{source}, 3, time.Second, 0, zap.NewNop())
+
+	if got := finder.Find(context.Background(), Query{Tags: []string{" ", ""}}); got != nil {
+		t.Errorf("Find() = %+v, want nil", got)
+	}
+	if source.calls != 0 {
+		t.Errorf("source searched %d times, want 0", source.calls)
+	}
+}
+
+func writeRunbook(t *testing.T, dir, name, content string) {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarkdownSource(t *testing.T) {
+	dir := t.TempDir()
+	writeRunbook(t, dir, "registry.md", `---
+id: RB-114
+title: "Registry outage"
+error_types: [docker_image_not_found]
+tags: [docker, registry]
+url: https://wiki.example.com/rb-114
+---
+# Ignored heading
+`)
+	writeRunbook(t, dir, "docker/disk.md", `---
+tags: [docker]
+---
+
+# Docker disk full
+`)
+	writeRunbook(t, dir, "npm/install.md", `---
+tags: npm
+---
+No heading here.
+`)
+	writeRunbook(t, dir, "notes.md", "# Team notes\n")
+	writeRunbook(t, dir, "readme.txt", "---\ntags: [docker]\n---\n")
+
+	source, err := LoadMarkdown(dir, "https://git.example.com/runbooks/")
+	if err != nil {
+		t.Fatalf("LoadMarkdown() error = %v", err)
+	}
+	if source.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", source.Len())
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		limit int
+		want  []domain.Runbook
+	}{
+		{
+			name:  "error type ranks first",
+			query: Query{ErrorType: "docker_image_not_found", Tags: []string{"docker"}},
+			limit: 5,
+			want: []domain.Runbook{
+				{ID: "RB-114", Title: "Registry outage", URL: "https://wiki.example.com/rb-114", Source: "markdown"},
+				{Title: "Docker disk full", URL: "https://git.example.com/runbooks/docker/disk.md", Source: "markdown"},
+			},
+		},
+		{
+			name:  "limit",
+			query: Query{ErrorType: "docker_image_not_found", Tags: []string{"docker"}},
+			limit: 1,
+			want: []domain.Runbook{
+				{ID: "RB-114", Title: "Registry outage", URL: "https://wiki.example.com/rb-114", Source: "markdown"},
+			},
+		},
+		{
+			name:  "tags are case insensitive, title falls back to file name",
+			query: Query{ErrorType: "npm_install_failed", Tags: []string{"NPM"}},
+			limit: 5,
+			want: []domain.Runbook{
+				{Title: "install", URL: "https://git.example.com/runbooks/npm/install.md", Source: "markdown"},
+			},
+		},
+		{
+			name:  "no match",
+			query: Query{ErrorType: "oom_killed"},
+			limit: 5,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := source.Search(context.Background(), tt.query, tt.limit)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMarkdown_MissingURL(t *testing.T) {
+	dir := t.TempDir()
+	writeRunbook(t, dir, "registry.md", "---\ntags: [docker]\n---\n")
+
+	if _, err := LoadMarkdown(dir, ""); err == nil {
+		t.Error("LoadMarkdown() error = nil, want error for runbook without url")
+	}
+}
+
+func TestConfluenceSource_Search(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		wantAuth string
+	}{
+		{name: "basic auth", email: "ops@example.com", wantAuth: "Basic "},
+		{name: "bearer token", wantAuth: "Bearer secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCQL, gotLimit, gotAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/wiki/rest/api/content/search" {
+					http.NotFound(w, r)
+					return
+				}
+				gotCQL = r.URL.Query().Get("cql")
+				gotLimit = r.URL.Query().Get("limit")
+				gotAuth = r.Header.Get("Authorization")
+				w.Write([]byte(`{
+					"results": [
+						{"id": "42", "title": "Registry outage", "_links": {"webui": "/spaces/OPS/pages/42"}},
+						{"id": "43", "title": "No link", "_links": {}}
+					],
+					"_links": {"base": "https://example.atlassian.net/wiki"}
+				}`))
+			}))
+			defer server.Close()
+
+			source := NewConfluenceSource(server.URL+"/wiki/", tt.email, "secret", "OPS", server.Client())
+			got, err := source.Search(context.Background(), Query{ErrorType: "docker_image_not_found", Tags: []string{"Docker"}}, 3)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			wantCQL := `type = page AND label in ("docker_image_not_found", "docker") AND space = "OPS"`
+			if gotCQL != wantCQL {
+				t.Errorf("cql = %q, want %q", gotCQL, wantCQL)
+			}
+			if gotLimit != "3" {
+				t.Errorf("limit = %q, want 3", gotLimit)
+			}
+			if !strings.HasPrefix(gotAuth, tt.wantAuth) {
+				t.Errorf("Authorization = %q, want prefix %q", gotAuth, tt.wantAuth)
+			}
+			want := []domain.Runbook{{
+				ID:     "42",
+				Title:  "Registry outage",
+				URL:    "https://example.atlassian.net/wiki/spaces/OPS/pages/42",
+				Source: "confluence",
+			}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Search() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestConfluenceSource_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	source := NewConfluenceSource(server.URL, "", "bad", "", server.Client())
+	_, err := source.Search(context.Background(), Query{ErrorType: "x"}, 3)
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Errorf("Search() error = %v, want status 401", err)
+	}
+}
+
+func TestNotionSource_Search(t *testing.T) {
+	var gotBody map[string]any
+	var gotAuth, gotVersion string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/databases/db1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		gotAuth = r.Header.Get("Authorization")
+		gotVersion = r.Header.Get("Notion-Version")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{
+			"results": [
+				{
+					"id": "p1",
+					"url": "https://www.notion.so/Registry-outage-p1",
+					"properties": {
+						"Name": {"type": "title", "title": [{"plain_text": "Registry "}, {"plain_text": "outage"}]},
+						"ID": {"type": "rich_text", "rich_text": [{"plain_text": "RB-114"}]},
+						"Tags": {"type": "multi_select"}
+					}
+				},
+				{"id": "p2", "url": "https://www.notion.so/p2", "properties": {}}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	source := NewNotionSource(server.URL, "secret", "db1", server.Client())
+	got, err := source.Search(context.Background(), Query{ErrorType: "docker_image_not_found", Tags: []string{"docker"}}, 2)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer secret")
+	}
+	if gotVersion != notionVersion {
+		t.Errorf("Notion-Version = %q, want %q", gotVersion, notionVersion)
+	}
+	if gotBody["page_size"] != float64(2) {
+		t.Errorf("page_size = %v, want 2", gotBody["page_size"])
+	}
+	filter, _ := gotBody["filter"].(map[string]any)
+	conditions, _ := filter["or"].([]any)
+	// error type property plus a tag condition per term
+	if len(conditions) != 3 {
+		t.Errorf("filter has %d conditions, want 3: %v", len(conditions), gotBody["filter"])
+	}
+
+	want := []domain.Runbook{
+		{ID: "RB-114", Title: "Registry outage", URL: "https://www.notion.so/Registry-outage-p1", Source: "notion"},
+		{Title: "https://www.notion.so/p2", URL: "https://www.notion.so/p2", Source: "notion"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Search() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+// Package knowledge links analyses to organization runbooks kept in
+// knowledge sources such as a markdown repository, Confluence or Notion.
+package knowledge
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// markdownRunbook is a runbook file with the terms it applies to.
+type markdownRunbook struct {
+	runbook    domain.Runbook
+	errorTypes map[string]bool
+	tags       map[string]bool
+}
+
+// MarkdownSource serves runbooks from a directory of markdown files, such
+// as a checked-out runbook repository. Each file declares what it applies
+// to in YAML-style front matter:
+//
+//	---
+//	id: RB-114
+//	title: Registry outage
+//	error_types: [docker_image_not_found, registry_rate_limited]
+//	tags: [docker, registry]
+//	url: https://wiki.example.com/rb-114
+//	---
+//
+// The title defaults to the first "# " heading and the URL to BaseURL
+// joined with the file's relative path. Files without error_types or tags
+// are ignored.
+type MarkdownSource struct {
+	runbooks []markdownRunbook
+}
+
+// LoadMarkdown reads the runbooks under dir. Links without a url in their
+// front matter point at baseURL plus the file's path relative to dir.
+func LoadMarkdown(dir, baseURL string) (*MarkdownSource, error) {
+	source := &MarkdownSource{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".md") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rb, ok, err := parseMarkdownRunbook(p, filepath.ToSlash(rel), baseURL)
+		if err != nil {
+			return err
+		}
+		if ok {
+			source.runbooks = append(source.runbooks, rb)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load markdown runbooks: %w", err)
+	}
+	return source, nil
+}
+
+// Name implements Source.
+func (s *MarkdownSource) Name() string { return "markdown" }
+
+// Len returns the number of runbooks loaded.
+func (s *MarkdownSource) Len() int { return len(s.runbooks) }
+
+// Search implements Source. Runbooks for the error type rank above those
+// sharing only tags; more shared tags rank higher.
+func (s *MarkdownSource) Search(_ context.Context, q Query, limit int) ([]domain.Runbook, error) {
+	type scored struct {
+		runbook domain.Runbook
+		score   int
+	}
+	errorType := strings.ToLower(q.ErrorType)
+	var hits []scored
+	for _, rb := range s.runbooks {
+		score := 0
+		if errorType != "" && rb.errorTypes[errorType] {
+			score += 10
+		}
+		for _, term := range q.terms() {
+			if rb.tags[term] {
+				score++
+			}
+		}
+		if score > 0 {
+			hits = append(hits, scored{rb.runbook, score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+
+	var runbooks []domain.Runbook
+	for _, hit := range hits {
+		if len(runbooks) == limit {
+			break
+		}
+		runbooks = append(runbooks, hit.runbook)
+	}
+	return runbooks, nil
+}
+
+// parseMarkdownRunbook reads the front matter and first heading of the
+// file at p. ok is false for files that do not declare error types or tags.
+func parseMarkdownRunbook(p, rel, baseURL string) (markdownRunbook, bool, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return markdownRunbook{}, false, err
+	}
+	defer f.Close()
+
+	rb := markdownRunbook{
+		runbook:    domain.Runbook{Source: "markdown"},
+		errorTypes: make(map[string]bool),
+		tags:       make(map[string]bool),
+	}
+	scanner := bufio.NewScanner(f)
+	inFrontMatter := false
+	for lineNo := 0; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if lineNo == 0 && line == "---" {
+			inFrontMatter = true
+			continue
+		}
+		if inFrontMatter {
+			if line == "---" {
+				inFrontMatter = false
+				continue
+			}
+			key, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			switch strings.TrimSpace(key) {
+			case "id":
+				rb.runbook.ID = unquote(value)
+			case "title":
+				rb.runbook.Title = unquote(value)
+			case "url":
+				rb.runbook.URL = unquote(value)
+			case "error_types":
+				addTerms(rb.errorTypes, value)
+			case "tags":
+				addTerms(rb.tags, value)
+			}
+			continue
+		}
+		if rb.runbook.Title != "" {
+			break
+		}
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			rb.runbook.Title = strings.TrimSpace(title)
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return markdownRunbook{}, false, fmt.Errorf("read %s: %w", rel, err)
+	}
+	if len(rb.errorTypes) == 0 && len(rb.tags) == 0 {
+		return markdownRunbook{}, false, nil
+	}
+
+	if rb.runbook.Title == "" {
+		rb.runbook.Title = strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+	}
+	if rb.runbook.URL == "" {
+		if baseURL == "" {
+			return markdownRunbook{}, false, fmt.Errorf("runbook %s has no url and no base URL is configured", rel)
+		}
+		rb.runbook.URL = strings.TrimSuffix(baseURL, "/") + "/" + rel
+	}
+	return rb, true, nil
+}
+
+// addTerms adds the terms of a "[a, b]" or "a, b" front matter list.
+func addTerms(terms map[string]bool, value string) {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	for _, term := range strings.Split(value, ",") {
+		if term = strings.ToLower(unquote(term)); term != "" {
+			terms[term] = true
+		}
+	}
+}
+
+// unquote trims whitespace and surrounding quotes from a front matter value.
+func unquote(value string) string {
+	return strings.Trim(strings.TrimSpace(value), `"'`)
+}
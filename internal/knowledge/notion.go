@@ -0,0 +1,158 @@
+// Package knowledge links analyses to organization runbooks kept in
+// knowledge sources such as a markdown repository, Confluence or Notion.
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Notion API defaults.
+const (
+	// DefaultNotionURL is the Notion API base URL.
+	DefaultNotionURL = "https://api.notion.com"
+
+	// notionVersion is the Notion API version requests are written against.
+	notionVersion = "2022-06-28"
+
+	// notionErrorTypesProperty and notionTagsProperty are the multi-select
+	// database properties runbooks are matched on.
+	notionErrorTypesProperty = "Error types"
+	notionTagsProperty       = "Tags"
+
+	// notionIDProperty is an optional rich text property with the runbook ID.
+	notionIDProperty = "ID"
+)
+
+// NotionSource searches a Notion database of runbooks. Pages are matched
+// by their "Error types" and "Tags" multi-select properties.
+type NotionSource struct {
+	baseURL    string
+	token      string
+	databaseID string
+	httpClient *http.Client
+}
+
+// NewNotionSource creates a Notion source for the database with
+// databaseID, authenticating with an integration token. baseURL is usually
+// DefaultNotionURL.
+func NewNotionSource(baseURL, token, databaseID string, httpClient *http.Client) *NotionSource {
+	return &NotionSource{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		databaseID: databaseID,
+		httpClient: httpClient,
+	}
+}
+
+// notionQueryResponse is the database query response.
+type notionQueryResponse struct {
+	Results []struct {
+		ID         string                    `json:"id"`
+		URL        string                    `json:"url"`
+		Properties map[string]notionProperty `json:"properties"`
+	} `json:"results"`
+}
+
+// notionProperty is a page property; only text properties are decoded.
+type notionProperty struct {
+	Type     string       `json:"type"`
+	Title    []notionText `json:"title"`
+	RichText []notionText `json:"rich_text"`
+}
+
+// notionText is a rich text fragment.
+type notionText struct {
+	PlainText string `json:"plain_text"`
+}
+
+// Name implements Source.
+func (s *NotionSource) Name() string { return "notion" }
+
+// Search implements Source with a database query.
+func (s *NotionSource) Search(ctx context.Context, q Query, limit int) ([]domain.Runbook, error) {
+	body, err := json.Marshal(map[string]any{
+		"filter":    s.filter(q),
+		"page_size": limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode notion query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v1/databases/"+s.databaseID+"/query", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create notion request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("Notion-Version", notionVersion)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("notion query: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("notion query: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result notionQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode notion response: %w", err)
+	}
+
+	runbooks := make([]domain.Runbook, 0, len(result.Results))
+	for _, page := range result.Results {
+		runbook := domain.Runbook{URL: page.URL, Source: s.Name()}
+		for name, prop := range page.Properties {
+			switch {
+			case prop.Type == "title":
+				runbook.Title = plainText(prop.Title)
+			case name == notionIDProperty && prop.Type == "rich_text":
+				runbook.ID = plainText(prop.RichText)
+			}
+		}
+		if runbook.Title == "" {
+			runbook.Title = page.URL
+		}
+		runbooks = append(runbooks, runbook)
+	}
+	return runbooks, nil
+}
+
+// filter matches pages whose error types contain q's error type or whose
+// tags contain one of q's terms.
+func (s *NotionSource) filter(q Query) map[string]any {
+	var conditions []map[string]any
+	if q.ErrorType != "" {
+		conditions = append(conditions, map[string]any{
+			"property":     notionErrorTypesProperty,
+			"multi_select": map[string]any{"contains": q.ErrorType},
+		})
+	}
+	for _, term := range q.terms() {
+		conditions = append(conditions, map[string]any{
+			"property":     notionTagsProperty,
+			"multi_select": map[string]any{"contains": term},
+		})
+	}
+	return map[string]any{"or": conditions}
+}
+
+// plainText joins rich text fragments.
+func plainText(texts []notionText) string {
+	var b strings.Builder
+	for _, t := range texts {
+		b.WriteString(t.PlainText)
+	}
+	return strings.TrimSpace(b.String())
+}
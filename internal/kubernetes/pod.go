@@ -0,0 +1,439 @@
+// Package kubernetes turns the structured state of a failing pod (its spec
+// and status, the events about it and its container logs) into log sections
+// for analysis. Failures such as OOMKilled containers, failing probes or
+// unbound volume claims show up in the pod status and events, not in the
+// container logs, so those are rendered first and in kubectl's terms.
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// ErrEmptyRequest indicates a request without a pod, events or logs.
+var ErrEmptyRequest = fmt.Errorf("%w: request has no pod, events or logs", domain.ErrEmptyLog)
+
+// Pod is the subset of a Kubernetes Pod object (kubectl get pod -o json)
+// that explains failures. Unknown fields are ignored.
+type Pod struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     PodSpec    `json:"spec"`
+	Status   PodStatus  `json:"status"`
+}
+
+// ObjectMeta names an object.
+type ObjectMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// PodSpec is the desired state of a pod.
+type PodSpec struct {
+	InitContainers []Container `json:"initContainers,omitempty"`
+	Containers     []Container `json:"containers"`
+	Volumes        []Volume    `json:"volumes,omitempty"`
+	NodeName       string      `json:"nodeName,omitempty"`
+}
+
+// Container is a container of a pod spec.
+type Container struct {
+	Name           string    `json:"name"`
+	Image          string    `json:"image"`
+	Resources      Resources `json:"resources"`
+	LivenessProbe  *Probe    `json:"livenessProbe,omitempty"`
+	ReadinessProbe *Probe    `json:"readinessProbe,omitempty"`
+	StartupProbe   *Probe    `json:"startupProbe,omitempty"`
+}
+
+// Resources are the requests and limits of a container, as quantities
+// such as "256Mi" or "500m".
+type Resources struct {
+	Limits   map[string]string `json:"limits,omitempty"`
+	Requests map[string]string `json:"requests,omitempty"`
+}
+
+// Probe is a liveness, readiness or startup probe. Ports may be numbers or
+// named ports.
+type Probe struct {
+	HTTPGet *struct {
+		Path string `json:"path,omitempty"`
+		Port any    `json:"port"`
+	} `json:"httpGet,omitempty"`
+	TCPSocket *struct {
+		Port any `json:"port"`
+	} `json:"tcpSocket,omitempty"`
+	Exec *struct {
+		Command []string `json:"command"`
+	} `json:"exec,omitempty"`
+	GRPC *struct {
+		Port int `json:"port"`
+	} `json:"grpc,omitempty"`
+
+	InitialDelaySeconds int `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      int `json:"timeoutSeconds,omitempty"`
+	FailureThreshold    int `json:"failureThreshold,omitempty"`
+}
+
+// Volume is a pod volume. Only persistent volume claims are described.
+type Volume struct {
+	Name                  string `json:"name"`
+	PersistentVolumeClaim *struct {
+		ClaimName string `json:"claimName"`
+	} `json:"persistentVolumeClaim,omitempty"`
+}
+
+// PodStatus is the observed state of a pod.
+type PodStatus struct {
+	Phase                 string            `json:"phase,omitempty"`
+	Reason                string            `json:"reason,omitempty"`
+	Message               string            `json:"message,omitempty"`
+	Conditions            []PodCondition    `json:"conditions,omitempty"`
+	InitContainerStatuses []ContainerStatus `json:"initContainerStatuses,omitempty"`
+	ContainerStatuses     []ContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+// PodCondition is a condition such as PodScheduled or Ready.
+type PodCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ContainerStatus is the observed state of a container.
+type ContainerStatus struct {
+	Name         string         `json:"name"`
+	RestartCount int            `json:"restartCount"`
+	State        ContainerState `json:"state"`
+	LastState    ContainerState `json:"lastState"`
+}
+
+// ContainerState is the state of a container; at most one field is set.
+type ContainerState struct {
+	Waiting    *ContainerStateWaiting    `json:"waiting,omitempty"`
+	Running    *ContainerStateRunning    `json:"running,omitempty"`
+	Terminated *ContainerStateTerminated `json:"terminated,omitempty"`
+}
+
+// ContainerStateWaiting is a container that is not running yet.
+type ContainerStateWaiting struct {
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ContainerStateRunning is a running container.
+type ContainerStateRunning struct {
+	StartedAt string `json:"startedAt,omitempty"`
+}
+
+// ContainerStateTerminated is a container that exited.
+type ContainerStateTerminated struct {
+	Reason   string `json:"reason,omitempty"`
+	Message  string `json:"message,omitempty"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// Event is a Kubernetes event (an item of kubectl get events -o json).
+type Event struct {
+	Type           string          `json:"type"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Count          int             `json:"count,omitempty"`
+	InvolvedObject ObjectReference `json:"involvedObject"`
+}
+
+// ObjectReference names the object an event is about.
+type ObjectReference struct {
+	Kind string `json:"kind,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// ContainerLog is the log of one container. Previous marks the log of the
+// previous, crashed instance (kubectl logs --previous).
+type ContainerLog struct {
+	Container string `json:"container"`
+	Previous  bool   `json:"previous,omitempty"`
+	Log       string `json:"log"`
+}
+
+// Request is a Kubernetes analysis request: the pod, the events about it
+// and its container logs, with the options of domain.AnalysisRequest.
+type Request struct {
+	Pod    *Pod           `json:"pod,omitempty"`
+	Events []Event        `json:"events,omitempty"`
+	Logs   []ContainerLog `json:"logs,omitempty"`
+
+	Language  string              `json:"language,omitempty"`
+	Detail    domain.DetailLevel  `json:"detail,omitempty"`
+	Metadata  *domain.LogMetadata `json:"metadata,omitempty"`
+	Explain   bool                `json:"explain,omitempty"`
+	TimeoutMS int                 `json:"timeout_ms,omitempty"`
+}
+
+// AnalysisRequest returns the analysis request for r in the Kubernetes
+// mode: the rendered pod, the events and each container log become
+// sections, in that order, and the pod's namespace becomes the metadata
+// namespace unless set.
+func (r *Request) AnalysisRequest() (*domain.AnalysisRequest, error) {
+	var sections []domain.LogSection
+	if r.Pod != nil {
+		sections = append(sections, domain.LogSection{Name: "pod", Content: RenderPod(r.Pod)})
+	}
+	if len(r.Events) > 0 {
+		sections = append(sections, domain.LogSection{Name: "events", Content: RenderEvents(r.Events)})
+	}
+	for _, log := range r.Logs {
+		if strings.TrimSpace(log.Log) == "" {
+			continue
+		}
+		name := "logs"
+		if log.Container != "" {
+			name += ": " + log.Container
+		}
+		if log.Previous {
+			name += " (previous)"
+		}
+		sections = append(sections, domain.LogSection{Name: name, Content: log.Log})
+	}
+	if len(sections) == 0 {
+		return nil, ErrEmptyRequest
+	}
+
+	meta := r.Metadata
+	if r.Pod != nil && r.Pod.Metadata.Namespace != "" && (meta == nil || meta.Namespace == "") {
+		withNamespace := domain.LogMetadata{}
+		if meta != nil {
+			withNamespace = *meta
+		}
+		withNamespace.Namespace = r.Pod.Metadata.Namespace
+		meta = &withNamespace
+	}
+
+	return &domain.AnalysisRequest{
+		Sections:  sections,
+		Language:  r.Language,
+		Metadata:  meta,
+		Detail:    r.Detail,
+		Mode:      domain.AnalysisModeKubernetes,
+		Explain:   r.Explain,
+		TimeoutMS: r.TimeoutMS,
+	}, nil
+}
+
+// RenderPod describes the pod like kubectl describe pod: phase and
+// conditions, then each container with its resources, state, last state
+// and probes, then the persistent volume claims.
+func RenderPod(p *Pod) string {
+	var b strings.Builder
+	name := p.Metadata.Name
+	if p.Metadata.Namespace != "" {
+		name = p.Metadata.Namespace + "/" + name
+	}
+	fmt.Fprintf(&b, "Pod %s", name)
+	if p.Status.Phase != "" {
+		fmt.Fprintf(&b, ": phase %s", p.Status.Phase)
+	}
+	if p.Status.Reason != "" {
+		fmt.Fprintf(&b, " (%s)", p.Status.Reason)
+	}
+	if p.Status.Message != "" {
+		fmt.Fprintf(&b, ": %s", p.Status.Message)
+	}
+	b.WriteString("\n")
+	if p.Spec.NodeName != "" {
+		fmt.Fprintf(&b, "  Node: %s\n", p.Spec.NodeName)
+	}
+	for _, c := range p.Status.Conditions {
+		if c.Status == "True" {
+			continue
+		}
+		fmt.Fprintf(&b, "  Condition %s=%s", c.Type, c.Status)
+		if c.Reason != "" {
+			fmt.Fprintf(&b, " (%s)", c.Reason)
+		}
+		if c.Message != "" {
+			fmt.Fprintf(&b, ": %s", c.Message)
+		}
+		b.WriteString("\n")
+	}
+
+	renderContainers(&b, "Init container", p.Spec.InitContainers, p.Status.InitContainerStatuses)
+	renderContainers(&b, "Container", p.Spec.Containers, p.Status.ContainerStatuses)
+
+	for _, v := range p.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			fmt.Fprintf(&b, "Volume %s: PersistentVolumeClaim %s\n", v.Name, v.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// renderContainers describes containers with their statuses.
+func renderContainers(b *strings.Builder, label string, containers []Container, statuses []ContainerStatus) {
+	for _, c := range containers {
+		fmt.Fprintf(b, "%s %s (image %s)", label, c.Name, c.Image)
+		status, hasStatus := findStatus(statuses, c.Name)
+		if hasStatus {
+			fmt.Fprintf(b, ": restarts %d", status.RestartCount)
+		}
+		b.WriteString("\n")
+
+		if len(c.Resources.Limits) > 0 || len(c.Resources.Requests) > 0 {
+			fmt.Fprintf(b, "  Limits: %s; Requests: %s\n", quantities(c.Resources.Limits), quantities(c.Resources.Requests))
+		}
+		if hasStatus {
+			if state := renderState(status.State); state != "" {
+				fmt.Fprintf(b, "  State: %s\n", state)
+			}
+			if state := renderState(status.LastState); state != "" {
+				fmt.Fprintf(b, "  Last State: %s\n", state)
+			}
+		}
+		for _, probe := range []struct {
+			name  string
+			probe *Probe
+		}{{"Liveness", c.LivenessProbe}, {"Readiness", c.ReadinessProbe}, {"Startup", c.StartupProbe}} {
+			if probe.probe != nil {
+				fmt.Fprintf(b, "  %s probe: %s\n", probe.name, renderProbe(probe.probe))
+			}
+		}
+	}
+}
+
+// findStatus returns the status of the container named name.
+func findStatus(statuses []ContainerStatus, name string) (ContainerStatus, bool) {
+	for _, status := range statuses {
+		if status.Name == name {
+			return status, true
+		}
+	}
+	return ContainerStatus{}, false
+}
+
+// quantities formats resource quantities sorted by resource name, or
+// "none".
+func quantities(values map[string]string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + values[name]
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderState describes a container state in kubectl's terms, or "".
+func renderState(state ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		return withDetail("Waiting", state.Waiting.Reason, state.Waiting.Message)
+	case state.Terminated != nil:
+		return withDetail("Terminated", state.Terminated.Reason, state.Terminated.Message) +
+			fmt.Sprintf(", Exit Code: %d", state.Terminated.ExitCode)
+	case state.Running != nil:
+		return "Running"
+	}
+	return ""
+}
+
+// withDetail appends the reason and message of a state to its name.
+func withDetail(name, reason, message string) string {
+	if reason != "" {
+		name += ", Reason: " + reason
+	}
+	if message != "" {
+		name += ", Message: " + message
+	}
+	return name
+}
+
+// renderProbe describes a probe's handler and timing.
+func renderProbe(p *Probe) string {
+	var handler string
+	switch {
+	case p.HTTPGet != nil:
+		handler = fmt.Sprintf("http-get :%v%s", p.HTTPGet.Port, p.HTTPGet.Path)
+	case p.TCPSocket != nil:
+		handler = fmt.Sprintf("tcp-socket :%v", p.TCPSocket.Port)
+	case p.Exec != nil:
+		handler = "exec " + strings.Join(p.Exec.Command, " ")
+	case p.GRPC != nil:
+		handler = fmt.Sprintf("grpc :%d", p.GRPC.Port)
+	default:
+		handler = "unknown handler"
+	}
+	return fmt.Sprintf("%s delay=%ds timeout=%ds period=%ds failure=%d",
+		handler, p.InitialDelaySeconds, p.TimeoutSeconds, p.PeriodSeconds, p.FailureThreshold)
+}
+
+// RenderEvents lists events like kubectl get events: type, reason, object,
+// message and repeat count, one per line.
+func RenderEvents(events []Event) string {
+	lines := make([]string, len(events))
+	for i, e := range events {
+		object := strings.ToLower(e.InvolvedObject.Kind)
+		if e.InvolvedObject.Name != "" {
+			object += "/" + e.InvolvedObject.Name
+		}
+		fields := []string{e.Type, e.Reason}
+		if object != "" {
+			fields = append(fields, object)
+		}
+		line := strings.Join(append(fields, e.Message), "  ")
+		if e.Count > 1 {
+			line += fmt.Sprintf(" (x%d)", e.Count)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Evidence returns the facts of the pod that explain common failures:
+// containers killed for exceeding their memory limit, with the limit, and
+// the claims of a pending pod.
+func Evidence(p *Pod) []string {
+	if p == nil {
+		return nil
+	}
+	containers := append(append([]Container(nil), p.Spec.InitContainers...), p.Spec.Containers...)
+	statuses := append(append([]ContainerStatus(nil), p.Status.InitContainerStatuses...), p.Status.ContainerStatuses...)
+
+	var evidence []string
+	for _, c := range containers {
+		status, ok := findStatus(statuses, c.Name)
+		if !ok {
+			continue
+		}
+		for _, state := range []ContainerState{status.State, status.LastState} {
+			if state.Terminated == nil || state.Terminated.Reason != "OOMKilled" {
+				continue
+			}
+			limit := c.Resources.Limits["memory"]
+			if limit == "" {
+				limit = "none"
+			}
+			evidence = append(evidence, fmt.Sprintf("container %s was OOMKilled (exit code %d) with memory limit %s",
+				c.Name, state.Terminated.ExitCode, limit))
+			break
+		}
+	}
+	if p.Status.Phase == "Pending" {
+		for _, v := range p.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				evidence = append(evidence, fmt.Sprintf("pending pod mounts PersistentVolumeClaim %s (volume %s)",
+					v.PersistentVolumeClaim.ClaimName, v.Name))
+			}
+		}
+	}
+	return evidence
+}
@@ -0,0 +1,130 @@
+// Package kubernetes provides unit tests for pod, event and log rendering.
+package kubernetes
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+const oomPod = `{
+  "metadata": {"name": "api-7d9f", "namespace": "shop"},
+  "spec": {
+    "containers": [{
+      "name": "api",
+      "image": "acme/api:1.4.2",
+      "resources": {"limits": {"memory": "256Mi", "cpu": "500m"}, "requests": {"memory": "128Mi"}},
+      "livenessProbe": {"httpGet": {"path": "/healthz", "port": 8080}, "initialDelaySeconds": 5, "timeoutSeconds": 1, "periodSeconds": 10, "failureThreshold": 3}
+    }],
+    "volumes": [{"name": "data", "persistentVolumeClaim": {"claimName": "api-data"}}]
+  },
+  "status": {
+    "phase": "Running",
+    "conditions": [{"type": "Ready", "status": "False", "reason": "ContainersNotReady"}],
+    "containerStatuses": [{
+      "name": "api",
+      "restartCount": 6,
+      "state": {"waiting": {"reason": "CrashLoopBackOff", "message": "back-off 5m0s restarting failed container"}},
+      "lastState": {"terminated": {"reason": "OOMKilled", "exitCode": 137}}
+    }]
+  }
+}`
+
+func decodePod(t *testing.T, data string) *Pod {
+	t.Helper()
+	var pod Pod
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("decode pod: %v", err)
+	}
+	return &pod
+}
+
+func TestRenderPod(t *testing.T) {
+	got := RenderPod(decodePod(t, oomPod))
+	want := `Pod shop/api-7d9f: phase Running
+  Condition Ready=False (ContainersNotReady)
+Container api (image acme/api:1.4.2): restarts 6
+  Limits: cpu=500m, memory=256Mi; Requests: memory=128Mi
+  State: Waiting, Reason: CrashLoopBackOff, Message: back-off 5m0s restarting failed container
+  Last State: Terminated, Reason: OOMKilled, Exit Code: 137
+  Liveness probe: http-get :8080/healthz delay=5s timeout=1s period=10s failure=3
+Volume data: PersistentVolumeClaim api-data`
+	if got != want {
+		t.Errorf("RenderPod() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderEvents(t *testing.T) {
+	got := RenderEvents([]Event{
+		{Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container api", Count: 12, InvolvedObject: ObjectReference{Kind: "Pod", Name: "api-7d9f"}},
+		{Type: "Normal", Reason: "Pulled", Message: "Container image already present", Count: 1},
+	})
+	want := "Warning  BackOff  pod/api-7d9f  Back-off restarting failed container api (x12)\n" +
+		"Normal  Pulled  Container image already present"
+	if got != want {
+		t.Errorf("RenderEvents() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRequest_AnalysisRequest(t *testing.T) {
+	req := &Request{
+		Pod:    decodePod(t, oomPod),
+		Events: []Event{{Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container"}},
+		Logs: []ContainerLog{
+			{Container: "api", Previous: true, Log: "starting server\nloading catalog"},
+			{Container: "sidecar", Log: "  "},
+		},
+		Detail:   domain.DetailBrief,
+		Metadata: &domain.LogMetadata{Pipeline: "deploy"},
+	}
+	got, err := req.AnalysisRequest()
+	if err != nil {
+		t.Fatalf("AnalysisRequest() error = %v", err)
+	}
+
+	var names []string
+	for _, section := range got.Sections {
+		names = append(names, section.Name)
+	}
+	if want := []string{"pod", "events", "logs: api (previous)"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sections = %q, want %q", names, want)
+	}
+	if got.Mode != domain.AnalysisModeKubernetes || got.Detail != domain.DetailBrief {
+		t.Errorf("mode, detail = %q, %q", got.Mode, got.Detail)
+	}
+	if got.Metadata.Namespace != "shop" || got.Metadata.Pipeline != "deploy" {
+		t.Errorf("metadata = %+v, want the pod namespace and the pipeline", got.Metadata)
+	}
+	if req.Metadata.Namespace != "" {
+		t.Error("AnalysisRequest() modified the request metadata")
+	}
+	if !strings.Contains(got.Sections[0].Content, "OOMKilled") {
+		t.Errorf("pod section = %q", got.Sections[0].Content)
+	}
+
+	if _, err := (&Request{Logs: []ContainerLog{{Log: ""}}}).AnalysisRequest(); !errors.Is(err, domain.ErrEmptyLog) {
+		t.Errorf("empty request: error = %v, want ErrEmptyLog", err)
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	want := []string{"container api was OOMKilled (exit code 137) with memory limit 256Mi"}
+	if got := Evidence(decodePod(t, oomPod)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Evidence() = %q, want %q", got, want)
+	}
+
+	pending := decodePod(t, oomPod)
+	pending.Status = PodStatus{Phase: "Pending"}
+	want = []string{"pending pod mounts PersistentVolumeClaim api-data (volume data)"}
+	if got := Evidence(pending); !reflect.DeepEqual(got, want) {
+		t.Errorf("Evidence() = %q, want %q", got, want)
+	}
+
+	if got := Evidence(nil); got != nil {
+		t.Errorf("Evidence(nil) = %q", got)
+	}
+}
@@ -0,0 +1,18 @@
+// Package logger provides structured logging setup.
+package logger
+
+import (
+	"context"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// FromContext returns l with the request ID carried by ctx, if any, so
+// the log lines of a request can be correlated.
+func FromContext(ctx context.Context, l *zap.Logger) *zap.Logger {
+	if id := domain.RequestIDFromContext(ctx); id != "" {
+		return l.With(zap.String("request_id", id))
+	}
+	return l
+}
@@ -0,0 +1,180 @@
+// Package loki fetches log context from Grafana Loki.
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// maxErrorBody bounds how much of an error response is reported.
+const maxErrorBody = 512
+
+// Config contains the Loki connection and query limits.
+type Config struct {
+	// URL is the Loki base URL, e.g. "http://loki:3100".
+	URL string
+
+	// TenantID is sent as X-Scope-OrgID for multi-tenant Loki.
+	TenantID string
+
+	// Username and Password enable basic auth; Token enables bearer auth.
+	Username string
+	Password string
+	Token    string
+
+	// Timeout bounds a query.
+	Timeout time.Duration
+
+	// DefaultRange is the time range before End when a query has no Start.
+	DefaultRange time.Duration
+
+	// MaxRange bounds the time range of a query.
+	MaxRange time.Duration
+
+	// MaxLines bounds the lines returned by a query.
+	MaxLines int
+}
+
+// Client queries the Loki HTTP API.
+type Client struct {
+	cfg        Config
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a Loki client.
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:        cfg,
+		baseURL:    strings.TrimSuffix(cfg.URL, "/"),
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// queryResponse is the query_range response for log queries.
+type queryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Values [][2]string `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// entry is a log line with its timestamp in nanoseconds.
+type entry struct {
+	ts   int64
+	line string
+}
+
+// Fetch returns the latest lines of the streams selected by q's labels in
+// q's time range, oldest first. Invalid queries wrap
+// domain.ErrInvalidRequest.
+func (c *Client) Fetch(ctx context.Context, q *domain.ContextQuery) ([]string, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	end := q.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := q.Start
+	if start.IsZero() {
+		start = end.Add(-c.cfg.DefaultRange)
+	}
+	if end.Sub(start) > c.cfg.MaxRange {
+		return nil, fmt.Errorf("%w: context time range exceeds %s", domain.ErrInvalidRequest, c.cfg.MaxRange)
+	}
+	limit := q.Limit
+	if limit == 0 || limit > c.cfg.MaxLines {
+		limit = c.cfg.MaxLines
+	}
+
+	params := url.Values{}
+	params.Set("query", Selector(q.Labels))
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+	// Backward returns the lines closest to the incident when limited
+	params.Set("direction", "backward")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/loki/api/v1/query_range?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create loki request: %w", err)
+	}
+	if c.cfg.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", c.cfg.TenantID)
+	}
+	switch {
+	case c.cfg.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	case c.cfg.Username != "":
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("loki query: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("loki query: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode loki response: %w", err)
+	}
+	if result.Status != "success" || result.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("loki query: unexpected %s %q result", result.Status, result.Data.ResultType)
+	}
+
+	// Streams are merged by timestamp; each is sorted newest first
+	var entries []entry
+	for _, stream := range result.Data.Result {
+		for _, value := range stream.Values {
+			ts, _ := strconv.ParseInt(value[0], 10, 64)
+			entries = append(entries, entry{ts: ts, line: strings.TrimRight(value[1], "\r\n")})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].ts < entries[j].ts })
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.line
+	}
+	return lines, nil
+}
+
+// Selector returns the LogQL stream selector matching labels exactly, with
+// labels in sorted order.
+func Selector(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matchers := make([]string, len(names))
+	for i, name := range names {
+		matchers[i] = name + "=" + strconv.Quote(labels[name])
+	}
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
@@ -0,0 +1,104 @@
+// Package loki provides unit tests for the Loki context client.
+package loki
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func testConfig(url string) Config {
+	return Config{
+		URL:          url + "/",
+		TenantID:     "team-a",
+		Token:        "secret",
+		Timeout:      time.Second,
+		DefaultRange: 15 * time.Minute,
+		MaxRange:     time.Hour,
+		MaxLines:     3,
+	}
+}
+
+func TestClient_Fetch(t *testing.T) {
+	end := time.Unix(1700000000, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/query_range" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if got := q.Get("query"); got != `{app="api", namespace="prod"}` {
+			t.Errorf("query = %s", got)
+		}
+		if q.Get("start") != "1699999100000000000" || q.Get("end") != "1700000000000000000" {
+			t.Errorf("range = %s..%s", q.Get("start"), q.Get("end"))
+		}
+		if q.Get("limit") != "3" || q.Get("direction") != "backward" {
+			t.Errorf("limit = %s, direction = %s", q.Get("limit"), q.Get("direction"))
+		}
+		if r.Header.Get("X-Scope-OrgID") != "team-a" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"pod":"a"},"values":[["30","ERROR db down\n"],["10","INFO start"]]},
+			{"stream":{"pod":"b"},"values":[["20","WARN slow query"],["5","INFO boot"]]}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(testConfig(srv.URL))
+	lines, err := client.Fetch(context.Background(), &domain.ContextQuery{
+		Labels: map[string]string{"namespace": "prod", "app": "api"},
+		End:    end,
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got, want := strings.Join(lines, "|"), "INFO start|WARN slow query|ERROR db down"; got != want {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+}
+
+func TestClient_FetchErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "parse error", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	client := NewClient(testConfig(srv.URL))
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		query       domain.ContextQuery
+		wantInvalid bool
+	}{
+		{"no labels", domain.ContextQuery{}, true},
+		{"invalid label name", domain.ContextQuery{Labels: map[string]string{"app-name": "x"}}, true},
+		{"range too long", domain.ContextQuery{Labels: map[string]string{"app": "x"}, Start: now.Add(-2 * time.Hour), End: now}, true},
+		{"server error", domain.ContextQuery{Labels: map[string]string{"app": "x"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Fetch(context.Background(), &tt.query)
+			if err == nil {
+				t.Fatal("Fetch() succeeded, want error")
+			}
+			if got := errors.Is(err, domain.ErrInvalidRequest); got != tt.wantInvalid {
+				t.Errorf("error = %v, invalid request = %v, want %v", err, got, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestSelector(t *testing.T) {
+	got := Selector(map[string]string{"job": `say "hi"`, "app": "api"})
+	if want := `{app="api", job="say \"hi\""}`; got != want {
+		t.Errorf("Selector() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+// Package notify delivers analysis notifications to chat channels.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Message is a notification about an analyzed failure.
+type Message struct {
+	// Fingerprint identifies the normalized log that produced the analysis.
+	Fingerprint string
+
+	// ErrorType, Severity and RootCause summarize the analysis.
+	ErrorType string
+	Severity  domain.Severity
+	RootCause string
+
+	// Source is the analysis source (e.g. "ai", "rules:oom").
+	Source string
+
+	// Occurrences counts identical analyses within the dedup window.
+	Occurrences int
+
+	// FirstSeen and LastSeen bound the occurrences.
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Text renders the message as plain chat text.
+func (m *Message) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s\n%s", m.Severity, m.ErrorType, m.RootCause)
+	if m.Occurrences > 1 {
+		fmt.Fprintf(&b, "\nOccurrences: %d (first %s, last %s)",
+			m.Occurrences,
+			m.FirstSeen.UTC().Format(time.RFC3339),
+			m.LastSeen.UTC().Format(time.RFC3339),
+		)
+	}
+	return b.String()
+}
+
+// Sender posts and edits messages on a chat backend.
+type Sender interface {
+	// Send posts a new message and returns a reference for later edits.
+	Send(ctx context.Context, msg *Message) (ref string, err error)
+
+	// Update edits a previously sent message in place.
+	Update(ctx context.Context, ref string, msg *Message) error
+}
+
+// dedupEntry tracks a sent message within the dedup window.
+type dedupEntry struct {
+	ref     string
+	msg     Message
+	expires time.Time
+}
+
+// Notifier sends analysis notifications through a Sender, collapsing
+// identical analyses (same fingerprint and error type) within a window into
+// a single message whose occurrence counter is edited in place.
+//
+// A nil *Notifier discards notifications.
+type Notifier struct {
+	sender      Sender
+	window      time.Duration
+	minSeverity domain.Severity
+	logger      *zap.Logger
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+// NewNotifier creates a Notifier. Analyses below minSeverity are not sent;
+// a zero window disables deduplication.
+func NewNotifier(sender Sender, window time.Duration, minSeverity domain.Severity, logger *zap.Logger) *Notifier {
+	return &Notifier{
+		sender:      sender,
+		window:      window,
+		minSeverity: minSeverity,
+		logger:      logger.Named("notifier"),
+		now:         time.Now,
+		entries:     make(map[string]*dedupEntry),
+	}
+}
+
+// Notify sends or updates the notification for an analysis result.
+func (n *Notifier) Notify(ctx context.Context, fingerprint, source string, result *domain.AnalysisResult) error {
+	if n == nil || result == nil || result.Severity.Rank() < n.minSeverity.Rank() {
+		return nil
+	}
+
+	now := n.now()
+	key := fingerprint + "|" + result.ErrorType
+
+	// The lock is held across the send so concurrent duplicates wait for the
+	// first message's reference instead of posting their own.
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sweep(now)
+
+	if entry, ok := n.entries[key]; ok {
+		entry.msg.Occurrences++
+		entry.msg.LastSeen = now
+		msg := entry.msg
+		if err := n.sender.Update(ctx, entry.ref, &msg); err != nil {
+			return domain.WrapError("notify_update", err, true)
+		}
+		n.logger.Debug("notification updated",
+			zap.String("error_type", msg.ErrorType),
+			zap.Int("occurrences", msg.Occurrences),
+		)
+		return nil
+	}
+
+	msg := Message{
+		Fingerprint: fingerprint,
+		ErrorType:   result.ErrorType,
+		Severity:    result.Severity,
+		RootCause:   result.RootCause,
+		Source:      source,
+		Occurrences: 1,
+		FirstSeen:   now,
+		LastSeen:    now,
+	}
+	ref, err := n.sender.Send(ctx, &msg)
+	if err != nil {
+		return domain.WrapError("notify_send", err, true)
+	}
+	if n.window > 0 {
+		n.entries[key] = &dedupEntry{ref: ref, msg: msg, expires: now.Add(n.window)}
+	}
+	return nil
+}
+
+// sweep drops entries whose window has passed. Caller holds mu.
+func (n *Notifier) sweep(now time.Time) {
+	for key, entry := range n.entries {
+		if !now.Before(entry.expires) {
+			delete(n.entries, key)
+		}
+	}
+}
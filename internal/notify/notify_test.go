@@ -0,0 +1,93 @@
+// Package notify provides unit tests for notification deduplication.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// fakeSender records sends and updates.
+type fakeSender struct {
+	sent    []Message
+	updates []Message
+}
+
+func (f *fakeSender) Send(_ context.Context, msg *Message) (string, error) {
+	f.sent = append(f.sent, *msg)
+	return "ref", nil
+}
+
+func (f *fakeSender) Update(_ context.Context, _ string, msg *Message) error {
+	f.updates = append(f.updates, *msg)
+	return nil
+}
+
+func TestNotifier_Deduplicates(t *testing.T) {
+	sender := &fakeSender{}
+	n := NewNotifier(sender, time.Minute, domain.SeverityMedium, zap.NewNop())
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	oom := &domain.AnalysisResult{ErrorType: "OOMKilled", Severity: domain.SeverityHigh}
+	low := &domain.AnalysisResult{ErrorType: "Deprecation", Severity: domain.SeverityLow}
+
+	_ = n.Notify(ctx, "fp1", "ai", oom)
+	_ = n.Notify(ctx, "fp1", "ai", oom)
+	_ = n.Notify(ctx, "fp1", "ai", low)
+
+	if len(sender.sent) != 1 || len(sender.updates) != 1 {
+		t.Fatalf("sent=%d updates=%d, want 1 send and 1 update", len(sender.sent), len(sender.updates))
+	}
+	if sender.updates[0].Occurrences != 2 {
+		t.Errorf("occurrences = %d, want 2", sender.updates[0].Occurrences)
+	}
+
+	// A different fingerprint is a new notification.
+	_ = n.Notify(ctx, "fp2", "ai", oom)
+	if len(sender.sent) != 2 {
+		t.Errorf("sent = %d, want 2 for a new fingerprint", len(sender.sent))
+	}
+
+	// After the window a repeat starts a new message.
+	now = now.Add(2 * time.Minute)
+	_ = n.Notify(ctx, "fp1", "ai", oom)
+	if len(sender.sent) != 3 {
+		t.Errorf("sent = %d, want 3 after window expiry", len(sender.sent))
+	}
+}
+
+func TestSlackSender_SendAndUpdate(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.URL.Path)
+		var req slackRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path == "/chat.update" && req.TS != "123.456" {
+			t.Errorf("update ts = %q, want 123.456", req.TS)
+		}
+		_ = json.NewEncoder(w).Encode(slackResponse{OK: true, Channel: "C1", TS: "123.456"})
+	}))
+	defer server.Close()
+
+	s := NewSlackSender("xoxb-test", "#alerts", server.URL)
+	msg := &Message{ErrorType: "OOMKilled", Severity: domain.SeverityHigh, Occurrences: 1}
+
+	ref, err := s.Send(context.Background(), msg)
+	if err != nil || ref != "C1:123.456" {
+		t.Fatalf("Send() = %q, %v", ref, err)
+	}
+	if err := s.Update(context.Background(), ref, msg); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(methods) != 2 || methods[0] != "/chat.postMessage" || methods[1] != "/chat.update" {
+		t.Errorf("methods = %v", methods)
+	}
+}
@@ -0,0 +1,107 @@
+// Package notify delivers analysis notifications to chat channels.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultSlackBaseURL is the Slack Web API base URL.
+const DefaultSlackBaseURL = "https://slack.com/api"
+
+// SlackSender posts messages with the Slack Web API. Incoming webhooks
+// cannot edit messages, so a bot token is required for in-place updates.
+type SlackSender struct {
+	token      string
+	channel    string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewSlackSender creates a sender posting to channel with a bot token.
+func NewSlackSender(token, channel, baseURL string) *SlackSender {
+	if baseURL == "" {
+		baseURL = DefaultSlackBaseURL
+	}
+	return &SlackSender{
+		token:      token,
+		channel:    channel,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// slackRequest is the chat.postMessage / chat.update payload.
+type slackRequest struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts,omitempty"`
+	Text    string `json:"text"`
+}
+
+// slackResponse is the common Slack Web API response envelope.
+type slackResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	TS      string `json:"ts,omitempty"`
+}
+
+// Send posts msg and returns "<channel id>:<ts>" as its reference.
+func (s *SlackSender) Send(ctx context.Context, msg *Message) (string, error) {
+	resp, err := s.call(ctx, "chat.postMessage", slackRequest{
+		Channel: s.channel,
+		Text:    msg.Text(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Channel + ":" + resp.TS, nil
+}
+
+// Update edits the message identified by ref.
+func (s *SlackSender) Update(ctx context.Context, ref string, msg *Message) error {
+	channel, ts, ok := strings.Cut(ref, ":")
+	if !ok {
+		return fmt.Errorf("invalid slack message reference %q", ref)
+	}
+	_, err := s.call(ctx, "chat.update", slackRequest{
+		Channel: channel,
+		TS:      ts,
+		Text:    msg.Text(),
+	})
+	return err
+}
+
+func (s *SlackSender) call(ctx context.Context, method string, payload slackRequest) (*slackResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal slack request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	httpResp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("slack %s failed: %w", method, err)
+	}
+	defer httpResp.Body.Close()
+
+	var resp slackResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode slack %s response (status %d): %w", method, httpResp.StatusCode, err)
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("slack %s failed: %s", method, resp.Error)
+	}
+	return &resp, nil
+}
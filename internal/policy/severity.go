@@ -0,0 +1,102 @@
+// Package policy applies operator-defined severity overrides to analysis
+// results, mapping rule and AI severities onto an organization's incident
+// taxonomy.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+)
+
+// Override sets the severity of results matching a condition.
+type Override struct {
+	// Name identifies the override in responses and logs.
+	Name string `json:"name"`
+
+	// Condition is a rule condition (see rules.Condition) that can also
+	// refer to result.error_type, result.severity and result.source, e.g.
+	// `result.error_type == "npm_install_failure" && metadata.branch != "main"`.
+	Condition string `json:"condition"`
+
+	// Severity is the severity of matching results.
+	Severity domain.Severity `json:"severity"`
+}
+
+// Config is the JSON severity policy file.
+type Config struct {
+	// Overrides are evaluated in order; the first match applies.
+	Overrides []Override `json:"overrides"`
+}
+
+// LoadConfig reads a JSON severity policy file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read severity policy: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse severity policy: %w", err)
+	}
+	return &cfg, nil
+}
+
+// compiledOverride is an Override with its condition compiled.
+type compiledOverride struct {
+	name      string
+	condition *rules.Condition
+	severity  domain.Severity
+}
+
+// SeverityPolicy applies the first matching override to a result.
+type SeverityPolicy struct {
+	overrides []compiledOverride
+}
+
+// NewSeverityPolicy compiles the overrides of cfg. Severities are matched
+// case-insensitively.
+func NewSeverityPolicy(cfg *Config) (*SeverityPolicy, error) {
+	p := &SeverityPolicy{}
+	seen := make(map[string]bool)
+	for i, o := range cfg.Overrides {
+		if o.Name == "" {
+			return nil, fmt.Errorf("%w: severity override %d has no name", domain.ErrInvalidConfig, i+1)
+		}
+		if seen[o.Name] {
+			return nil, fmt.Errorf("%w: duplicate severity override %q", domain.ErrInvalidConfig, o.Name)
+		}
+		seen[o.Name] = true
+
+		severity := domain.NormalizeSeverity(o.Severity)
+		if !severity.IsValid() {
+			return nil, fmt.Errorf("%w: severity override %q: invalid severity %q", domain.ErrInvalidConfig, o.Name, o.Severity)
+		}
+		condition, err := rules.CompileResultCondition(o.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("%w: severity override %q: %v", domain.ErrInvalidConfig, o.Name, err)
+		}
+		p.overrides = append(p.overrides, compiledOverride{name: o.Name, condition: condition, severity: severity})
+	}
+	return p, nil
+}
+
+// Len returns the number of overrides.
+func (p *SeverityPolicy) Len() int {
+	return len(p.overrides)
+}
+
+// Apply returns the severity of the first override matching result from
+// source, and the override's name. ok is false when no override matches.
+func (p *SeverityPolicy) Apply(pre *domain.PreprocessedLog, meta *domain.LogMetadata, result *domain.AnalysisResult, source string) (severity domain.Severity, name string, ok bool) {
+	for _, o := range p.overrides {
+		if o.condition.EvalResult(pre, meta, result, source) {
+			return o.severity, o.name, true
+		}
+	}
+	return "", "", false
+}
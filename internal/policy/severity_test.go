@@ -0,0 +1,64 @@
+// Package policy provides unit tests for severity overrides.
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestSeverityPolicy_Apply(t *testing.T) {
+	p, err := NewSeverityPolicy(&Config{Overrides: []Override{
+		{Name: "npm-off-main", Severity: "low", Condition: `result.error_type == "npm_install_failure" && metadata.branch != "main"`},
+		{Name: "prod", Severity: "High", Condition: `metadata.namespace == "prod"`},
+	}})
+	if err != nil {
+		t.Fatalf("NewSeverityPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		meta         *domain.LogMetadata
+		errorType    string
+		wantSeverity domain.Severity
+		wantName     string
+		wantOK       bool
+	}{
+		{"feature branch npm failure", &domain.LogMetadata{Branch: "feature/x"}, "npm_install_failure", domain.SeverityLow, "npm-off-main", true},
+		{"first match wins", &domain.LogMetadata{Branch: "dev", Namespace: "prod"}, "npm_install_failure", domain.SeverityLow, "npm-off-main", true},
+		{"prod namespace", &domain.LogMetadata{Branch: "main", Namespace: "prod"}, "npm_install_failure", domain.SeverityHigh, "prod", true},
+		{"no match", &domain.LogMetadata{Branch: "main"}, "npm_install_failure", "", "", false},
+		{"no metadata", nil, "oom_killed", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &domain.AnalysisResult{ErrorType: tt.errorType, Severity: domain.SeverityMedium}
+			severity, name, ok := p.Apply(&domain.PreprocessedLog{}, tt.meta, result, "ai")
+			if severity != tt.wantSeverity || name != tt.wantName || ok != tt.wantOK {
+				t.Errorf("Apply() = (%q, %q, %v), want (%q, %q, %v)", severity, name, ok, tt.wantSeverity, tt.wantName, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewSeverityPolicy_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		override Override
+	}{
+		{"no name", Override{Severity: "Low", Condition: "true"}},
+		{"invalid severity", Override{Name: "x", Severity: "Critical", Condition: "true"}},
+		{"invalid condition", Override{Name: "x", Severity: "Low", Condition: `result.owner == "me"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSeverityPolicy(&Config{Overrides: []Override{tt.override}})
+			if !errors.Is(err, domain.ErrInvalidConfig) {
+				t.Errorf("error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
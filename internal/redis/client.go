@@ -0,0 +1,264 @@
+// Package redis is a minimal Redis client (RESP2 over TCP or TLS) used to
+// share cache, pacing and endpoint health state between replicas.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// ErrNil is returned when a key does not exist.
+var ErrNil = errors.New("redis: nil")
+
+// ErrClosed is returned by commands after Close.
+var ErrClosed = errors.New("redis: client closed")
+
+// Error is an error reply from the server.
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// Config configures a Client.
+type Config struct {
+	// URL is redis://[user:password@]host:port[/db], or rediss:// for TLS.
+	URL string
+
+	// PoolSize bounds the idle connections kept open.
+	PoolSize int
+
+	// Timeout bounds dialing and each command.
+	Timeout time.Duration
+}
+
+// Client is a pool of Redis connections. It is safe for concurrent use.
+type Client struct {
+	addr     string
+	tls      *tls.Config
+	username string
+	password string
+	db       int
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	idle   []*conn
+	size   int
+	closed bool
+}
+
+// conn is one connection with its buffered reader and writer.
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// New parses cfg and creates a client. Connections are opened on demand.
+func New(cfg Config) (*Client, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+		return nil, fmt.Errorf("%w: redis URL must be redis:// or rediss://", domain.ErrInvalidConfig)
+	}
+
+	c := &Client{
+		addr:    u.Host,
+		size:    cfg.PoolSize,
+		timeout: cfg.Timeout,
+	}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.Scheme == "rediss" {
+		c.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("%w: redis database %q is not a number", domain.ErrInvalidConfig, db)
+		}
+	}
+	if c.size <= 0 {
+		c.size = 10
+	}
+	if c.timeout <= 0 {
+		c.timeout = 2 * time.Second
+	}
+	return c, nil
+}
+
+// Do sends a command and returns its reply: string for simple and bulk
+// strings, int64 for integers, []any for arrays. A nil bulk string returns
+// ErrNil; an error reply returns Error.
+func (c *Client) Do(ctx context.Context, args ...any) (any, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	cn.SetDeadline(deadline)
+
+	reply, err := cn.do(args)
+	var replyErr Error
+	if err != nil && !errors.Is(err, ErrNil) && !errors.As(err, &replyErr) {
+		// The connection state is unknown after an I/O error
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Ping checks connectivity.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Get returns the value of key or ErrNil.
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	reply, err := c.Do(ctx, "GET", key)
+	if err != nil {
+		return "", err
+	}
+	s, _ := reply.(string)
+	return s, nil
+}
+
+// Set stores value under key, expiring after ttl unless ttl is zero.
+func (c *Client) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	args := []any{"SET", key, value}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// Del deletes keys and returns how many existed.
+func (c *Client) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	args := make([]any, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	reply, err := c.Do(ctx, args...)
+	n, _ := reply.(int64)
+	return n, err
+}
+
+// Eval runs a Lua script with keys and args.
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	cmd := make([]any, 0, 3+len(keys)+len(args))
+	cmd = append(cmd, "EVAL", script, len(keys))
+	for _, key := range keys {
+		cmd = append(cmd, key)
+	}
+	return c.Do(ctx, append(cmd, args...)...)
+}
+
+// Close closes idle connections; later commands fail with ErrClosed.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for _, cn := range c.idle {
+		cn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+// get returns an idle connection or dials a new one.
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	return c.dial(ctx)
+}
+
+// put returns a healthy connection to the pool.
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || len(c.idle) >= c.size {
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+// dial opens a connection, authenticates and selects the database.
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	var nc net.Conn
+	var err error
+	if c.tls != nil {
+		nc, err = (&tls.Dialer{NetDialer: dialer, Config: c.tls}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		nc, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: dial %s: %w", c.addr, err)
+	}
+
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	cn.SetDeadline(time.Now().Add(c.timeout))
+	var setup [][]any
+	switch {
+	case c.password != "" && c.username != "":
+		setup = append(setup, []any{"AUTH", c.username, c.password})
+	case c.password != "":
+		setup = append(setup, []any{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []any{"SELECT", c.db})
+	}
+	for _, cmd := range setup {
+		if _, err := cn.do(cmd); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("redis: %s: %w", cmd[0], err)
+		}
+	}
+	return cn, nil
+}
+
+// do writes a command and reads its reply.
+func (cn *conn) do(args []any) (any, error) {
+	if err := writeCommand(cn.w, args); err != nil {
+		return nil, err
+	}
+	if err := cn.w.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(cn.r)
+}
@@ -0,0 +1,105 @@
+// Package redis provides unit tests for the Redis client.
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/redis"
+	"github.com/ai-devops/internal/redis/redistest"
+)
+
+func newClient(t *testing.T, url string) *redis.Client {
+	t.Helper()
+	c, err := redis.New(redis.Config{URL: url, PoolSize: 2, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestNew_RejectsBadURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"wrong scheme", "http://localhost:6379"},
+		{"no host", "redis://"},
+		{"bad database", "redis://localhost:6379/cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := redis.New(redis.Config{URL: tt.url}); !errors.Is(err, domain.ErrInvalidConfig) {
+				t.Errorf("New(%q) error = %v, want ErrInvalidConfig", tt.url, err)
+			}
+		})
+	}
+}
+
+func TestClient_Commands(t *testing.T) {
+	srv := redistest.NewServer(t)
+	c := newClient(t, srv.URL())
+	ctx := context.Background()
+
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, redis.ErrNil) {
+		t.Errorf("Get(missing) error = %v, want ErrNil", err)
+	}
+
+	if err := c.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if v, err := c.Get(ctx, "k"); err != nil || v != "v" {
+		t.Errorf("Get(k) = %q, %v, want v", v, err)
+	}
+	if ttl := srv.TTL("k"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL(k) = %v, want up to 1m", ttl)
+	}
+
+	if n, err := c.Del(ctx, "k", "missing"); err != nil || n != 1 {
+		t.Errorf("Del() = %d, %v, want 1", n, err)
+	}
+
+	var replyErr redis.Error
+	if _, err := c.Do(ctx, "BOGUS"); !errors.As(err, &replyErr) {
+		t.Errorf("Do(BOGUS) error = %v, want error reply", err)
+	}
+	// The connection survives an error reply.
+	if err := c.Ping(ctx); err != nil {
+		t.Errorf("Ping() after error reply = %v", err)
+	}
+
+	c.Close()
+	if err := c.Ping(ctx); !errors.Is(err, redis.ErrClosed) {
+		t.Errorf("Ping() after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestClient_AuthAndSelect(t *testing.T) {
+	srv := redistest.NewServer(t)
+	var auth []string
+	srv.Handle("AUTH", func(args []string) any {
+		auth = args
+		return "OK"
+	})
+
+	c := newClient(t, "redis://cache:secret@"+srv.URL()[len("redis://"):]+"/2")
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	if len(auth) != 2 || auth[0] != "cache" || auth[1] != "secret" {
+		t.Errorf("AUTH args = %v, want [cache secret]", auth)
+	}
+	commands := srv.Commands()
+	if len(commands) != 3 || commands[1] != "SELECT" {
+		t.Errorf("commands = %v, want AUTH SELECT PING", commands)
+	}
+}
@@ -0,0 +1,243 @@
+// Package redistest provides an in-process Redis server implementing the
+// subset of commands used by this service, for tests.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Handler answers a command with a RESP-encodable reply: nil, string,
+// int64, error or []any.
+type Handler func(args []string) any
+
+// Server is a fake Redis server. Keys expire lazily.
+type Server struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	strings  map[string]string
+	sets     map[string]map[string]bool
+	expiry   map[string]time.Time
+	handlers map[string]Handler
+	commands []string
+}
+
+// NewServer starts a server that is closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("redistest: listen: %v", err)
+	}
+	s := &Server{
+		listener: l,
+		strings:  make(map[string]string),
+		sets:     make(map[string]map[string]bool),
+		expiry:   make(map[string]time.Time),
+		handlers: make(map[string]Handler),
+	}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+// URL returns the redis:// URL of the server.
+func (s *Server) URL() string {
+	return "redis://" + s.listener.Addr().String()
+}
+
+// Handle overrides the reply to a command (e.g. "EVAL").
+func (s *Server) Handle(command string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[strings.ToUpper(command)] = h
+}
+
+// Commands returns the names of the commands received, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// TTL returns the remaining time to live of key, or zero.
+func (s *Server) TTL(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if at, ok := s.expiry[key]; ok {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func (s *Server) serve() {
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		writeReply(w, s.exec(args))
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) exec(args []string) any {
+	if len(args) == 0 {
+		return fmt.Errorf("ERR empty command")
+	}
+	name := strings.ToUpper(args[0])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, name)
+	if h, ok := s.handlers[name]; ok {
+		return h(args[1:])
+	}
+	for key, at := range s.expiry {
+		if time.Now().After(at) {
+			s.deleteLocked(key)
+		}
+	}
+
+	switch name {
+	case "PING":
+		return "PONG"
+	case "AUTH", "SELECT":
+		return "OK"
+	case "GET":
+		if v, ok := s.strings[args[1]]; ok {
+			return v
+		}
+		return nil
+	case "MGET":
+		values := make([]any, len(args)-1)
+		for i, key := range args[1:] {
+			if v, ok := s.strings[key]; ok {
+				values[i] = v
+			}
+		}
+		return values
+	case "SET":
+		s.deleteLocked(args[1])
+		s.strings[args[1]] = args[2]
+		if len(args) == 5 && strings.EqualFold(args[3], "PX") {
+			s.expireLocked(args[1], args[4])
+		}
+		return "OK"
+	case "DEL":
+		var n int64
+		for _, key := range args[1:] {
+			if s.deleteLocked(key) {
+				n++
+			}
+		}
+		return n
+	case "SADD":
+		set := s.sets[args[1]]
+		if set == nil {
+			set = make(map[string]bool)
+			s.sets[args[1]] = set
+		}
+		var n int64
+		for _, member := range args[2:] {
+			if !set[member] {
+				set[member] = true
+				n++
+			}
+		}
+		return n
+	case "SMEMBERS":
+		members := []any{}
+		for member := range s.sets[args[1]] {
+			members = append(members, member)
+		}
+		return members
+	case "PEXPIRE":
+		s.expireLocked(args[1], args[2])
+		return int64(1)
+	}
+	return fmt.Errorf("ERR unknown command '%s'", name)
+}
+
+func (s *Server) expireLocked(key, ms string) {
+	n, _ := strconv.ParseInt(ms, 10, 64)
+	s.expiry[key] = time.Now().Add(time.Duration(n) * time.Millisecond)
+}
+
+func (s *Server) deleteLocked(key string) bool {
+	_, isString := s.strings[key]
+	_, isSet := s.sets[key]
+	delete(s.strings, key)
+	delete(s.sets, key)
+	delete(s.expiry, key)
+	return isString || isSet
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func writeReply(w *bufio.Writer, reply any) {
+	switch v := reply.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case string:
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case error:
+		fmt.Fprintf(w, "-%s\r\n", v.Error())
+	case []any:
+		fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package redis is a minimal Redis client (RESP2 over TCP or TLS) used to
+// share cache, pacing and endpoint health state between replicas.
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// reserveScript implements the pacer's reservation token bucket atomically:
+// refill by elapsed time, take ARGV[3] tokens (negative refunds), and
+// return the milliseconds the caller must wait for a negative balance.
+const reserveScript = `
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+local tokens = tonumber(redis.call('HGET', KEYS[1], 'tokens'))
+local last = tonumber(redis.call('HGET', KEYS[1], 'last'))
+if tokens == nil or last == nil then
+  tokens = capacity
+  last = now
+end
+tokens = math.min(capacity, tokens + (now - last) / 1000 * rate) - n
+if tokens > capacity then tokens = capacity end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate * 1000) + 60000)
+if tokens >= 0 then return 0 end
+return math.ceil(-tokens / rate * 1000)
+`
+
+// Buckets keeps token buckets in Redis so that replicas pace against the
+// same provider limits.
+type Buckets struct {
+	client *Client
+	prefix string
+}
+
+// NewBuckets creates token buckets stored under prefix.
+func NewBuckets(client *Client, prefix string) *Buckets {
+	return &Buckets{client: client, prefix: prefix + "bucket:"}
+}
+
+// Reserve takes n tokens from the bucket name, refilling at ratePerSecond
+// up to capacity, and returns how long the caller must wait.
+func (b *Buckets) Reserve(ctx context.Context, name string, n, ratePerSecond, capacity float64) (time.Duration, error) {
+	reply, err := b.client.Eval(ctx, reserveScript, []string{b.prefix + name}, ratePerSecond, capacity, n)
+	if err != nil {
+		return 0, err
+	}
+	ms, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected reserve reply %T", reply)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// EndpointHealth shares the times until which endpoints are out of
+// rotation, so a failing endpoint is skipped by every replica.
+type EndpointHealth struct {
+	client *Client
+	prefix string
+}
+
+// NewEndpointHealth creates endpoint health state stored under prefix.
+func NewEndpointHealth(client *Client, prefix string) *EndpointHealth {
+	return &EndpointHealth{client: client, prefix: prefix + "endpoint_down:"}
+}
+
+// MarkDown takes url out of rotation until the given time; a zero time
+// returns it to rotation.
+func (h *EndpointHealth) MarkDown(ctx context.Context, url string, until time.Time) error {
+	key := h.key(url)
+	if until.IsZero() {
+		_, err := h.client.Del(ctx, key)
+		return err
+	}
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return h.client.Set(ctx, key, strconv.FormatInt(until.UnixMilli(), 10), ttl)
+}
+
+// DownUntil returns the times until which the given endpoints are out of
+// rotation; endpoints in rotation are omitted.
+func (h *EndpointHealth) DownUntil(ctx context.Context, urls []string) (map[string]time.Time, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	args := make([]any, 0, len(urls)+1)
+	args = append(args, "MGET")
+	for _, url := range urls {
+		args = append(args, h.key(url))
+	}
+	reply, err := h.client.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := reply.([]any)
+
+	down := make(map[string]time.Time)
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok || i >= len(urls) {
+			continue
+		}
+		if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+			down[urls[i]] = time.UnixMilli(ms)
+		}
+	}
+	return down, nil
+}
+
+// key hashes url so credentials or query strings never appear in key names.
+func (h *EndpointHealth) key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return h.prefix + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,64 @@
+// Package redis provides unit tests for shared pacing and endpoint state.
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/redis"
+	"github.com/ai-devops/internal/redis/redistest"
+)
+
+func TestBuckets_Reserve(t *testing.T) {
+	srv := redistest.NewServer(t)
+	var got []string
+	srv.Handle("EVAL", func(args []string) any {
+		got = args
+		return int64(1500)
+	})
+
+	b := redis.NewBuckets(newClient(t, srv.URL()), "app:")
+	delay, err := b.Reserve(context.Background(), "tokens", 250, 100, 1000)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if delay != 1500*time.Millisecond {
+		t.Errorf("delay = %v, want 1.5s", delay)
+	}
+
+	// script, numkeys, key, rate, capacity, n
+	if len(got) != 6 || got[1] != "1" || got[2] != "app:bucket:tokens" || got[3] != "100" || got[4] != "1000" || got[5] != "250" {
+		t.Errorf("EVAL args = %q", got[1:])
+	}
+}
+
+func TestEndpointHealth(t *testing.T) {
+	srv := redistest.NewServer(t)
+	h := redis.NewEndpointHealth(newClient(t, srv.URL()), "app:")
+	ctx := context.Background()
+
+	until := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	if err := h.MarkDown(ctx, "https://a.example", until); err != nil {
+		t.Fatalf("MarkDown() error = %v", err)
+	}
+	// Times in the past are not stored.
+	if err := h.MarkDown(ctx, "https://b.example", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("MarkDown() error = %v", err)
+	}
+
+	down, err := h.DownUntil(ctx, []string{"https://a.example", "https://b.example"})
+	if err != nil {
+		t.Fatalf("DownUntil() error = %v", err)
+	}
+	if len(down) != 1 || !down["https://a.example"].Equal(until) {
+		t.Errorf("DownUntil() = %v, want only a until %v", down, until)
+	}
+
+	if err := h.MarkDown(ctx, "https://a.example", time.Time{}); err != nil {
+		t.Fatalf("MarkDown(zero) error = %v", err)
+	}
+	if down, _ := h.DownUntil(ctx, []string{"https://a.example"}); len(down) != 0 {
+		t.Errorf("DownUntil() after clear = %v, want none", down)
+	}
+}
@@ -0,0 +1,126 @@
+// Package render writes analysis responses in human-readable formats for
+// the CLI and the API's content negotiation.
+package render
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+//go:embed templates/report.html
+var templates embed.FS
+
+var reportTemplate = template.Must(template.ParseFS(templates, "templates/report.html"))
+
+// Log excerpt limits of the HTML report.
+const (
+	// excerptContext is the number of lines shown around each evidence line.
+	excerptContext = 5
+
+	// excerptTailLines is the number of final lines shown when there is no
+	// evidence to center the excerpt on.
+	excerptTailLines = 60
+)
+
+// excerptLine is a line of the report's log excerpt. Gap marks skipped
+// lines.
+type excerptLine struct {
+	Number int
+	Text   string
+	Hit    bool
+	Gap    bool
+}
+
+// reportAction is a suggested action of the report.
+type reportAction struct {
+	Text        string
+	Destructive bool
+}
+
+// reportData is the HTML report template's data.
+type reportData struct {
+	*domain.AnalysisRecord
+	SeverityClass string
+	Actions       []reportAction
+	Excerpt       []excerptLine
+}
+
+// HTML writes a standalone HTML report of a stored analysis: the result
+// with a severity badge and an excerpt of the sanitized log around the
+// evidence lines, which are highlighted.
+func HTML(w io.Writer, record *domain.AnalysisRecord) error {
+	if record.Result == nil {
+		return fmt.Errorf("analysis %s has no result", record.ID)
+	}
+	data := reportData{
+		AnalysisRecord: record,
+		SeverityClass:  strings.ToLower(string(record.Result.Severity)),
+		Actions:        reportActions(record.Result),
+		Excerpt:        logExcerpt(record.Log, record.Evidence),
+	}
+	return reportTemplate.Execute(w, data)
+}
+
+// reportActions pairs the suggested actions with their safety.
+func reportActions(result *domain.AnalysisResult) []reportAction {
+	actions := make([]reportAction, len(result.SuggestedActions))
+	for i, action := range result.SuggestedActions {
+		actions[i] = reportAction{
+			Text:        action,
+			Destructive: actionSafety(result, i) == domain.SafetyDestructive,
+		}
+	}
+	return actions
+}
+
+// logExcerpt selects the lines around the evidence, or the last lines of
+// the log without evidence, marking skipped lines with gaps.
+func logExcerpt(log string, evidence []domain.LogEvidence) []excerptLine {
+	if strings.TrimSpace(log) == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+
+	hits := make(map[int]bool, len(evidence))
+	shown := make(map[int]bool)
+	for _, ev := range evidence {
+		if ev.Line < 1 || ev.Line > len(lines) {
+			continue
+		}
+		hits[ev.Line] = true
+		for n := max(1, ev.Line-excerptContext); n <= min(len(lines), ev.Line+excerptContext); n++ {
+			shown[n] = true
+		}
+	}
+	if len(shown) == 0 {
+		for n := max(1, len(lines)-excerptTailLines+1); n <= len(lines); n++ {
+			shown[n] = true
+		}
+	}
+
+	numbers := make([]int, 0, len(shown))
+	for n := range shown {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	excerpt := make([]excerptLine, 0, len(numbers)+2)
+	previous := 0
+	for _, n := range numbers {
+		if n > previous+1 {
+			excerpt = append(excerpt, excerptLine{Gap: true})
+		}
+		excerpt = append(excerpt, excerptLine{Number: n, Text: lines[n-1], Hit: hits[n]})
+		previous = n
+	}
+	if previous < len(lines) {
+		excerpt = append(excerpt, excerptLine{Gap: true})
+	}
+	return excerpt
+}
@@ -0,0 +1,203 @@
+// Package render writes analysis responses in human-readable formats for
+// the CLI and the API's content negotiation.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Func writes an analysis response.
+type Func func(io.Writer, *domain.AnalysisResponse) error
+
+// JSON writes the response as the server returns it by default.
+func JSON(w io.Writer, resp *domain.AnalysisResponse) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(resp)
+}
+
+// Text writes the response as plain text for terminals and CI logs.
+func Text(w io.Writer, resp *domain.AnalysisResponse) error {
+	var b strings.Builder
+	if !resp.Success {
+		fmt.Fprintf(&b, "Analysis failed: %s\n", failure(resp))
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	result := resp.Result
+	fmt.Fprintf(&b, "Error type: %s\n", result.ErrorType)
+	fmt.Fprintf(&b, "Severity:   %s\n", result.Severity)
+	if resp.Source != "" {
+		fmt.Fprintf(&b, "Source:     %s\n", resp.Source)
+	}
+	fmt.Fprintf(&b, "\nRoot cause:\n  %s\n", indent(result.RootCause, "  "))
+	if result.Explanation != "" {
+		fmt.Fprintf(&b, "\nExplanation:\n  %s\n", indent(result.Explanation, "  "))
+	}
+	if len(result.SuggestedActions) > 0 {
+		b.WriteString("\nSuggested actions:\n")
+		for i, action := range result.SuggestedActions {
+			if actionSafety(result, i) == domain.SafetyDestructive {
+				action += " [destructive]"
+			}
+			fmt.Fprintf(&b, "  %d. %s\n", i+1, action)
+		}
+	}
+	if len(result.Commands) > 0 {
+		b.WriteString("\nCommands:\n")
+		for _, c := range result.Commands {
+			if c.Safety == domain.SafetyDestructive {
+				b.WriteString("  # DESTRUCTIVE: review before running\n")
+			}
+			if c.Description != "" {
+				fmt.Fprintf(&b, "  # %s\n", c.Description)
+			}
+			fmt.Fprintf(&b, "  %s\n", c.Command)
+		}
+	}
+	if len(result.PreventionTips) > 0 {
+		b.WriteString("\nPrevention tips:\n")
+		for _, tip := range result.PreventionTips {
+			fmt.Fprintf(&b, "  - %s\n", tip)
+		}
+	}
+	if len(result.References) > 0 {
+		b.WriteString("\nReferences:\n")
+		for _, ref := range result.References {
+			fmt.Fprintf(&b, "  - %s: %s\n", ref.Title, ref.URL)
+		}
+	}
+	if len(resp.Runbooks) > 0 {
+		b.WriteString("\nRunbooks:\n")
+		for _, rb := range resp.Runbooks {
+			fmt.Fprintf(&b, "  - %s: %s\n", runbookTitle(rb), rb.URL)
+		}
+	}
+	if len(resp.Evidence) > 0 {
+		b.WriteString("\nEvidence:\n")
+		for _, ev := range resp.Evidence {
+			fmt.Fprintf(&b, "  line %d: %s\n", ev.Line, ev.Snippet)
+		}
+	}
+	if resp.Explain != nil {
+		b.WriteString("\nStages:\n")
+		for _, stage := range resp.Explain.Stages {
+			fmt.Fprintf(&b, "  %-16s %8.1f ms\n", stage.Name, stage.DurationMS)
+		}
+		fmt.Fprintf(&b, "  %-16s %8.1f ms\n", "total", resp.Explain.TotalMS)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Markdown writes the response as Markdown, e.g. for a pull request
+// comment or a CI job summary.
+func Markdown(w io.Writer, resp *domain.AnalysisResponse) error {
+	var b strings.Builder
+	if !resp.Success {
+		fmt.Fprintf(&b, "## Analysis failed\n\n%s\n", failure(resp))
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	result := resp.Result
+	fmt.Fprintf(&b, "## %s\n\n", result.ErrorType)
+	fmt.Fprintf(&b, "**Severity:** %s", result.Severity)
+	if resp.Source != "" {
+		fmt.Fprintf(&b, " | **Source:** `%s`", resp.Source)
+	}
+	fmt.Fprintf(&b, "\n\n### Root cause\n\n%s\n", result.RootCause)
+	if result.Explanation != "" {
+		fmt.Fprintf(&b, "\n### Explanation\n\n%s\n", result.Explanation)
+	}
+	if len(result.SuggestedActions) > 0 {
+		b.WriteString("\n### Suggested actions\n\n")
+		for i, action := range result.SuggestedActions {
+			if actionSafety(result, i) == domain.SafetyDestructive {
+				action = "**Destructive:** " + action
+			}
+			fmt.Fprintf(&b, "%d. %s\n", i+1, action)
+		}
+	}
+	if len(result.Commands) > 0 {
+		// One block per command, so each gets its own copy button
+		b.WriteString("\n### Commands\n")
+		for _, c := range result.Commands {
+			b.WriteString("\n")
+			if c.Safety == domain.SafetyDestructive {
+				b.WriteString("**Destructive, review before running.** ")
+			}
+			if c.Description != "" {
+				fmt.Fprintf(&b, "%s:\n\n", c.Description)
+			} else if c.Safety == domain.SafetyDestructive {
+				b.WriteString("\n\n")
+			}
+			fmt.Fprintf(&b, "```sh\n%s\n```\n", c.Command)
+		}
+	}
+	if len(result.PreventionTips) > 0 {
+		b.WriteString("\n### Prevention tips\n\n")
+		for _, tip := range result.PreventionTips {
+			fmt.Fprintf(&b, "- %s\n", tip)
+		}
+	}
+	if len(result.References) > 0 {
+		b.WriteString("\n### References\n\n")
+		for _, ref := range result.References {
+			fmt.Fprintf(&b, "- [%s](%s)\n", strings.ReplaceAll(ref.Title, "]", `\]`), ref.URL)
+		}
+	}
+	if len(resp.Runbooks) > 0 {
+		b.WriteString("\n### Runbooks\n\n")
+		for _, rb := range resp.Runbooks {
+			fmt.Fprintf(&b, "- [%s](%s)\n", strings.ReplaceAll(runbookTitle(rb), "]", `\]`), rb.URL)
+		}
+	}
+	if len(resp.Evidence) > 0 {
+		b.WriteString("\n### Evidence\n\n")
+		for _, ev := range resp.Evidence {
+			fmt.Fprintf(&b, "- Line %d: `%s`\n", ev.Line, strings.ReplaceAll(ev.Snippet, "`", "'"))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// runbookTitle prefixes a runbook's title with its ID, e.g.
+// "RB-114 Registry outage".
+func runbookTitle(rb domain.Runbook) string {
+	if rb.ID == "" || strings.Contains(rb.Title, rb.ID) {
+		return rb.Title
+	}
+	return rb.ID + " " + rb.Title
+}
+
+// actionSafety returns the safety of the i-th suggested action, or "" if
+// the result was not classified.
+func actionSafety(result *domain.AnalysisResult, i int) domain.Safety {
+	if i < len(result.ActionSafety) {
+		return result.ActionSafety[i]
+	}
+	return ""
+}
+
+// failure describes a failed analysis.
+func failure(resp *domain.AnalysisResponse) string {
+	if resp.Error == nil {
+		return "unknown error"
+	}
+	return fmt.Sprintf("%s: %s", resp.Error.Code, resp.Error.Message)
+}
+
+// indent prefixes the continuation lines of text.
+func indent(text, prefix string) string {
+	return strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n"+prefix)
+}
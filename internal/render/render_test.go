@@ -0,0 +1,134 @@
+// Package render provides unit tests for the response renderers.
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestRenderers(t *testing.T) {
+	success := &domain.AnalysisResponse{
+		Success: true,
+		Source:  "rules:npm_eresolve",
+		Result: &domain.AnalysisResult{
+			ErrorType:        "npm_install_failure",
+			Severity:         domain.SeverityMedium,
+			RootCause:        "Conflicting peer dependencies.",
+			SuggestedActions: []string{"Align the react versions", "Retry with --legacy-peer-deps"},
+			Commands:         []domain.Command{{Command: "npm ls react", Description: "Show who depends on react"}},
+			PreventionTips:   []string{"Commit the lockfile"},
+			References:       []domain.Reference{{Title: "npm install", URL: "https://docs.npmjs.com/cli/commands/npm-install"}},
+		},
+		Evidence: []domain.LogEvidence{{Line: 3, Snippet: "npm ERR! code `ERESOLVE`"}},
+		Runbooks: []domain.Runbook{{ID: "RB-114", Title: "npm registry outage", URL: "https://wiki.example.com/rb-114", Source: "confluence"}},
+	}
+	failed := &domain.AnalysisResponse{
+		Error: domain.NewErrorDetail(domain.CodeEmptyLog, "log content is empty"),
+	}
+
+	tests := []struct {
+		name   string
+		render Func
+		resp   *domain.AnalysisResponse
+		want   []string
+	}{
+		{"markdown", Markdown, success, []string{
+			"## npm_install_failure",
+			"**Severity:** Medium | **Source:** `rules:npm_eresolve`",
+			"### Root cause\n\nConflicting peer dependencies.",
+			"1. Align the react versions\n2. Retry with --legacy-peer-deps",
+			"### Commands\n\nShow who depends on react:\n\n```sh\nnpm ls react\n```",
+			"- Commit the lockfile",
+			"### References\n\n- [npm install](https://docs.npmjs.com/cli/commands/npm-install)",
+			"### Runbooks\n\n- [RB-114 npm registry outage](https://wiki.example.com/rb-114)",
+			"- Line 3: `npm ERR! code 'ERESOLVE'`",
+		}},
+		{"markdown failure", Markdown, failed, []string{"## Analysis failed\n\nEMPTY_LOG: log content is empty"}},
+		{"text", Text, success, []string{
+			"Error type: npm_install_failure",
+			"Severity:   Medium",
+			"Root cause:\n  Conflicting peer dependencies.",
+			"  2. Retry with --legacy-peer-deps",
+			"Commands:\n  # Show who depends on react\n  npm ls react",
+			"References:\n  - npm install: https://docs.npmjs.com/cli/commands/npm-install",
+			"Runbooks:\n  - RB-114 npm registry outage: https://wiki.example.com/rb-114",
+			"  line 3: npm ERR! code `ERESOLVE`",
+		}},
+		{"text failure", Text, failed, []string{"Analysis failed: EMPTY_LOG: log content is empty"}},
+		{"json", JSON, success, []string{`"error_type": "npm_install_failure"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tt.render(&b, tt.resp); err != nil {
+				t.Fatalf("render error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, b.String())
+				}
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var log []string
+	for i := 1; i <= 40; i++ {
+		log = append(log, fmt.Sprintf("step %d", i))
+	}
+	log[19] = `npm ERR! peer react@"<script>alert(1)</script>"`
+	record := &domain.AnalysisRecord{
+		ID:     "a1",
+		Log:    strings.Join(log, "\n"),
+		Source: "rules:npm_eresolve",
+		Result: &domain.AnalysisResult{
+			ErrorType: "npm_install_failure",
+			Severity:  domain.SeverityHigh,
+			RootCause: "Conflicting peer dependencies.",
+			Commands:  []domain.Command{{Command: "npm ls react && echo <done>"}},
+			References: []domain.Reference{
+				{Title: "npm install", URL: "https://docs.npmjs.com/cli/commands/npm-install"},
+				{Title: "bad", URL: "javascript:alert(1)"},
+			},
+		},
+		Evidence: []domain.LogEvidence{{Line: 20}},
+	}
+
+	var b strings.Builder
+	if err := HTML(&b, record); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		`<span class="badge badge-high">High</span>`,
+		`<span class="hit"><span class="ln">20</span>npm ERR! peer react@&#34;&lt;script&gt;`,
+		`<span class="ln">15</span>step 15`,
+		`<span class="ln">25</span>step 25`,
+		`<span class="gap">&hellip;</span>`,
+		`<pre><code>npm ls react &amp;&amp; echo &lt;done&gt;</code></pre>`,
+		`<a href="https://docs.npmjs.com/cli/commands/npm-install" rel="noopener noreferrer">npm install</a>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"<script>", "javascript:", `<span class="ln">14</span>`, `<span class="ln">26</span>`} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("report contains %q", unwanted)
+		}
+	}
+}
+
+func TestLogExcerpt_NoEvidence(t *testing.T) {
+	log := strings.Repeat("line\n", excerptTailLines+10)
+	excerpt := logExcerpt(log, nil)
+	if len(excerpt) != excerptTailLines+1 || !excerpt[0].Gap || excerpt[1].Number != 11 {
+		t.Errorf("logExcerpt() = %d lines starting %+v, want a gap and the last %d lines", len(excerpt), excerpt[0], excerptTailLines)
+	}
+}
@@ -0,0 +1,125 @@
+// Package retry runs operations with jittered exponential backoff, bounded
+// by a retry count, a total elapsed time and the context deadline.
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MinAttemptTime is the least time left before the context deadline worth
+// starting another attempt with.
+const MinAttemptTime = 2 * time.Second
+
+// randFloat returns a number in [0, 1); replaced in tests.
+var randFloat = rand.Float64
+
+// Policy configures retries.
+type Policy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+
+	// BaseDelay is the delay before the first retry; it doubles with each
+	// retry up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// MaxElapsed bounds the total time spent, including attempts. Zero
+	// leaves it to MaxRetries and the context deadline.
+	MaxElapsed time.Duration
+
+	// Jitter is the fraction (0-1) of each delay that is randomized, so
+	// replicas that failed together do not retry together.
+	Jitter float64
+
+	// Retryable reports whether an error is worth retrying. Nil retries
+	// every error.
+	Retryable func(error) bool
+
+	// RetryAfter returns the delay a server asked for with an error (e.g.
+	// Retry-After on 429), or zero. It replaces the backoff delay.
+	RetryAfter func(error) time.Duration
+
+	// OnRetry is called before waiting delay for retry number n.
+	OnRetry func(n int, delay time.Duration, err error)
+}
+
+// Do calls op until it succeeds, fails with an error that is not retryable,
+// or no retry fits the policy or the context deadline; the last error is
+// returned. If ctx ends while waiting, ctx.Err() is returned.
+func (p Policy) Do(ctx context.Context, op func(ctx context.Context) error) error {
+	start := time.Now()
+	for n := 0; ; n++ {
+		err := op(ctx)
+		if err == nil || n >= p.MaxRetries || (p.Retryable != nil && !p.Retryable(err)) {
+			return err
+		}
+
+		delay := p.Delay(n + 1)
+		if p.RetryAfter != nil {
+			if after := p.RetryAfter(err); after > 0 {
+				delay = after
+			}
+		}
+		// The last error says more than a deadline hit mid-backoff
+		if !p.fits(ctx, start, delay) {
+			return err
+		}
+
+		if p.OnRetry != nil {
+			p.OnRetry(n+1, delay, err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Delay returns the jittered backoff before retry number n (1-based).
+func (p Policy) Delay(n int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < n && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * randFloat() * float64(delay))
+	}
+	return delay
+}
+
+// fits reports whether a retry after delay can finish within MaxElapsed
+// and before ctx's deadline.
+func (p Policy) fits(ctx context.Context, start time.Time, delay time.Duration) bool {
+	if p.MaxElapsed > 0 && time.Since(start)+delay+MinAttemptTime > p.MaxElapsed {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay+MinAttemptTime {
+		return false
+	}
+	return true
+}
+
+// ParseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. It returns zero for a missing or invalid header.
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+// Package retry provides unit tests for retry policies.
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+
+func TestPolicy_Delay(t *testing.T) {
+	orig := randFloat
+	randFloat = func() float64 { return 0.5 }
+	t.Cleanup(func() { randFloat = orig })
+
+	tests := []struct {
+		name   string
+		policy Policy
+		n      int
+		want   time.Duration
+	}{
+		{"first retry", Policy{BaseDelay: time.Second}, 1, time.Second},
+		{"doubles", Policy{BaseDelay: time.Second}, 3, 4 * time.Second},
+		{"capped", Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}, 10, 5 * time.Second},
+		{"jittered", Policy{BaseDelay: time.Second, Jitter: 0.5}, 2, 1500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.n); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_Do(t *testing.T) {
+	permanent := errors.New("permanent")
+
+	tests := []struct {
+		name      string
+		policy    Policy
+		errs      []error
+		deadline  time.Duration
+		wantCalls int
+		wantErr   error
+	}{
+		{"succeeds after retries", Policy{MaxRetries: 3}, []error{errTransient, errTransient, nil}, 0, 3, nil},
+		{"gives up after max retries", Policy{MaxRetries: 2}, []error{errTransient, errTransient, errTransient, nil}, 0, 3, errTransient},
+		{
+			"stops on errors that are not retryable",
+			Policy{MaxRetries: 3, Retryable: func(err error) bool { return err == errTransient }},
+			[]error{errTransient, permanent, nil}, 0, 2, permanent,
+		},
+		{"skips retries past the deadline", Policy{MaxRetries: 3, BaseDelay: time.Second}, []error{errTransient, nil}, 2 * time.Second, 1, errTransient},
+		{"skips retries past max elapsed", Policy{MaxRetries: 3, BaseDelay: time.Second, MaxElapsed: 2 * time.Second}, []error{errTransient, nil}, 0, 1, errTransient},
+		{
+			"retry-after replaces backoff",
+			Policy{MaxRetries: 1, BaseDelay: time.Hour, RetryAfter: func(error) time.Duration { return time.Millisecond }},
+			[]error{errTransient, nil}, 0, 2, nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			calls := 0
+			err := tt.policy.Do(ctx, func(context.Context) error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if err != tt.wantErr {
+				t.Errorf("Do() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_DoCancelledWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := Policy{MaxRetries: 1, BaseDelay: time.Hour, OnRetry: func(int, time.Duration, error) { cancel() }}
+
+	if err := p.Do(ctx, func(context.Context) error { return errTransient }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() = %v, want context.Canceled", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"soon", 0},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
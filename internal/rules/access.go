@@ -0,0 +1,132 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"regexp"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// accessRules detects authentication and authorization failures.
+func accessRules() []*Rule {
+	return []*Rule{
+		authenticationFailure(),
+		gitAuthenticationFailure(),
+		ciTokenPermission(),
+	}
+}
+
+func authenticationFailure() *Rule {
+	return &Rule{
+		ID:          "authentication_failure",
+		Category:    CategoryAccess,
+		Tags:        []string{"credentials"},
+		Name:        "Authentication Failure",
+		Description: "Detects authentication and authorization failures",
+		Keywords:    []string{"authentication failed", "unauthorized", "access denied", "invalid credentials"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)authentication\s+failed`),
+			regexp.MustCompile(`(?i)401\s+unauthorized`),
+			regexp.MustCompile(`(?i)403\s+forbidden`),
+			regexp.MustCompile(`(?i)invalid\s+(credentials|token|api.?key)`),
+			regexp.MustCompile(`(?i)access\s+denied`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeAuthentication,
+			Severity:  domain.SeverityHigh,
+			RootCause: "Authentication or authorization failed. Credentials may be invalid, expired, or missing. The user/service may also lack required permissions.",
+			SuggestedActions: []string{
+				"Verify credentials are correct and not expired",
+				"Check if API keys or tokens need renewal",
+				"Verify the service account has required permissions",
+				"Check for environment variable configuration issues",
+				"Review IAM policies and role assignments",
+			},
+			PreventionTips: []string{
+				"Use secret management systems (Vault, AWS Secrets Manager)",
+				"Implement credential rotation policies",
+				"Use service accounts with minimal required permissions",
+				"Monitor for authentication failures in security logs",
+			},
+		},
+	}
+}
+
+func gitAuthenticationFailure() *Rule {
+	return &Rule{
+		ID:          "git_authentication_failure",
+		Category:    CategoryAccess,
+		Tags:        []string{"git", "credentials"},
+		Name:        "Git Authentication Failure",
+		Description: "Detects git clone, fetch and push authentication failures",
+		Keywords: []string{
+			"fatal: authentication failed for",
+			"permission denied (publickey)",
+			"could not read from remote repository",
+			"could not read username for",
+			"host key verification failed",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)remote: (Invalid username or password|Support for password authentication was removed)`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeGitAuthentication,
+			Severity:  domain.SeverityHigh,
+			RootCause: "Git could not authenticate to the remote. The token or SSH key may be missing, expired or lack access to the repository, or the SSH host key is not trusted.",
+			SuggestedActions: []string{
+				"Check that the CI token or deploy key has access to the repository",
+				"Rotate expired personal access tokens or deploy keys",
+				"Use a token instead of a password for HTTPS remotes",
+				"Add the host's SSH key to known_hosts for SSH remotes",
+			},
+			Commands: []domain.Command{
+				{Command: "git ls-remote <repository-url>", Description: "Check that the credentials can read the repository"},
+			},
+			PreventionTips: []string{
+				"Use short-lived CI tokens scoped to the repositories a job needs",
+				"Track token and deploy key expiry dates",
+			},
+		},
+	}
+}
+
+func ciTokenPermission() *Rule {
+	return &Rule{
+		ID:          "ci_token_permission",
+		Category:    CategoryAccess,
+		Tags:        []string{"ci", "github-actions", "gitlab-ci", "credentials"},
+		Name:        "CI Token Permission",
+		Description: "Detects CI job tokens (GITHUB_TOKEN, CI_JOB_TOKEN) lacking a permission",
+		Keywords: []string{
+			"resource not accessible by integration",
+			"denied to github-actions[bot]",
+			"unable to get actions_id_token_request_url",
+			"refusing to allow a github app to create or update workflow",
+			"installation not allowed to write organization package",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)\b(gitlab-ci-token|CI_JOB_TOKEN)\b.*\b(403|denied|not allowed)\b`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeCITokenPermission,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The CI job token lacks a permission the step needs. GITHUB_TOKEN only gets the scopes of the workflow or job permissions block (read-only by default in newer repositories); GitLab's CI_JOB_TOKEN cannot push and only reaches projects on its allowlist.",
+			SuggestedActions: []string{
+				"Grant the missing scope in the job's permissions block (e.g. contents: write, pull-requests: write, id-token: write)",
+				"Check the repository or organization default workflow permissions",
+				"Use a GitHub App token or deploy key for changes GITHUB_TOKEN cannot make, such as workflow files",
+				"Add the project to the CI_JOB_TOKEN allowlist, or use a project access token to push from GitLab CI",
+			},
+			Commands: []domain.Command{
+				{Command: "gh api repos/<owner>/<repo>/actions/permissions/workflow", Description: "Show the default GITHUB_TOKEN permissions of the repository"},
+			},
+			PreventionTips: []string{
+				"Declare permissions per job with the least scopes each job needs",
+				"Review token permissions when adding steps that write to the repository, packages or pull requests",
+			},
+		},
+	}
+}
@@ -0,0 +1,175 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxAuditEntries bounds the in-memory threshold audit trail.
+const maxAuditEntries = 100
+
+// AdaptiveConfig configures the adaptive confidence threshold controller.
+type AdaptiveConfig struct {
+	// MinThreshold and MaxThreshold bound the threshold adjustments.
+	MinThreshold float64
+	MaxThreshold float64
+
+	// Step is the amount the threshold moves per adjustment.
+	Step float64
+
+	// WindowSize is the number of shadow evaluations per adjustment decision.
+	WindowSize int
+
+	// RaiseBelow raises the threshold when the agreement rate falls below it.
+	RaiseBelow float64
+
+	// LowerAbove lowers the threshold when the agreement rate is at or above it.
+	LowerAbove float64
+}
+
+// DefaultAdaptiveConfig returns conservative controller defaults: raise the
+// threshold when rules and AI disagree more than 30% of the time, lower it
+// only when they always agree.
+func DefaultAdaptiveConfig() AdaptiveConfig {
+	return AdaptiveConfig{
+		MinThreshold: 0.6,
+		MaxThreshold: 0.98,
+		Step:         0.02,
+		WindowSize:   50,
+		RaiseBelow:   0.7,
+		LowerAbove:   1.0,
+	}
+}
+
+// ThresholdAdjustment records a single threshold change for auditing.
+type ThresholdAdjustment struct {
+	// At is when the adjustment was made.
+	At time.Time `json:"at"`
+
+	// From and To are the previous and new thresholds.
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+
+	// AgreementRate is the observed rule/AI agreement rate in the window.
+	AgreementRate float64 `json:"agreement_rate"`
+
+	// Samples is the number of shadow evaluations in the window.
+	Samples int `json:"samples"`
+}
+
+// AdaptiveController adjusts the engine's confidence threshold based on how
+// often rule results agree with shadow AI evaluations.
+type AdaptiveController struct {
+	engine *Engine
+	config AdaptiveConfig
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	agreed   int
+	observed int
+	audit    []ThresholdAdjustment
+}
+
+// NewAdaptiveController creates a controller that tunes engine's threshold.
+// A starting threshold outside the configured bounds is clamped into them.
+func NewAdaptiveController(engine *Engine, config AdaptiveConfig, logger *zap.Logger) *AdaptiveController {
+	if config.WindowSize <= 0 {
+		config.WindowSize = DefaultAdaptiveConfig().WindowSize
+	}
+	logger = logger.Named("adaptive_threshold")
+
+	from := engine.ConfidenceThreshold()
+	if to := clamp(from, config.MinThreshold, config.MaxThreshold); to != from {
+		engine.SetConfidenceThreshold(to)
+		logger.Warn("starting rule confidence threshold outside adaptive bounds, clamped",
+			zap.Float64("from", from),
+			zap.Float64("to", to),
+		)
+	}
+	return &AdaptiveController{
+		engine: engine,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Record registers the outcome of a shadow evaluation. Once a full window
+// has been observed the threshold is re-evaluated and the window reset.
+func (c *AdaptiveController) Record(agreed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.observed++
+	if agreed {
+		c.agreed++
+	}
+
+	if c.observed < c.config.WindowSize {
+		return
+	}
+
+	rate := float64(c.agreed) / float64(c.observed)
+	samples := c.observed
+	c.agreed, c.observed = 0, 0
+
+	from := c.engine.ConfidenceThreshold()
+	to := from
+	switch {
+	case rate < c.config.RaiseBelow:
+		to = from + c.config.Step
+	case rate >= c.config.LowerAbove:
+		to = from - c.config.Step
+	}
+	to = clamp(to, c.config.MinThreshold, c.config.MaxThreshold)
+
+	if to == from {
+		return
+	}
+
+	c.engine.SetConfidenceThreshold(to)
+	c.audit = append(c.audit, ThresholdAdjustment{
+		At:            time.Now(),
+		From:          from,
+		To:            to,
+		AgreementRate: rate,
+		Samples:       samples,
+	})
+	if len(c.audit) > maxAuditEntries {
+		c.audit = c.audit[len(c.audit)-maxAuditEntries:]
+	}
+
+	c.logger.Info("rule confidence threshold adjusted",
+		zap.Float64("from", from),
+		zap.Float64("to", to),
+		zap.Float64("agreement_rate", rate),
+		zap.Int("samples", samples),
+	)
+}
+
+// Audit returns a copy of the threshold adjustment history, oldest first.
+func (c *AdaptiveController) Audit() []ThresholdAdjustment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	audit := make([]ThresholdAdjustment, len(c.audit))
+	copy(audit, c.audit)
+	return audit
+}
+
+// Threshold returns the engine's current confidence threshold.
+func (c *AdaptiveController) Threshold() float64 {
+	return c.engine.ConfidenceThreshold()
+}
+
+func clamp(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
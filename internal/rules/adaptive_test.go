@@ -0,0 +1,94 @@
+// Package rules provides unit tests for the adaptive threshold controller.
+package rules
+
+import (
+	"math"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestAdaptiveController_Record(t *testing.T) {
+	cfg := AdaptiveConfig{
+		MinThreshold: 0.7,
+		MaxThreshold: 0.9,
+		Step:         0.05,
+		WindowSize:   4,
+		RaiseBelow:   0.5,
+		LowerAbove:   1.0,
+	}
+
+	tests := []struct {
+		name     string
+		start    float64
+		outcomes []bool
+		want     float64
+		audits   int
+	}{
+		{
+			name:     "frequent disagreement raises threshold",
+			outcomes: []bool{false, false, false, true},
+			want:     0.85,
+			audits:   1,
+		},
+		{
+			name:     "full agreement lowers threshold",
+			outcomes: []bool{true, true, true, true},
+			want:     0.75,
+			audits:   1,
+		},
+		{
+			name:     "mixed agreement keeps threshold",
+			outcomes: []bool{true, true, true, false},
+			want:     0.8,
+			audits:   0,
+		},
+		{
+			name:     "raise is bounded by max",
+			outcomes: []bool{false, false, false, false, false, false, false, false, false, false, false, false},
+			want:     0.9,
+			audits:   2,
+		},
+		{
+			name:     "incomplete window does not adjust",
+			outcomes: []bool{false, false},
+			want:     0.8,
+			audits:   0,
+		},
+		{
+			name:   "start above max is clamped",
+			start:  0.95,
+			want:   0.9,
+			audits: 0,
+		},
+		{
+			name:     "start below min is clamped before lowering",
+			start:    0.5,
+			outcomes: []bool{true, true, true, true},
+			want:     0.7,
+			audits:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := tt.start
+			if start == 0 {
+				start = 0.8
+			}
+			engine := NewEngine(DefaultRules(), start, zap.NewNop())
+			controller := NewAdaptiveController(engine, cfg, zap.NewNop())
+
+			for _, agreed := range tt.outcomes {
+				controller.Record(agreed)
+			}
+
+			if got := engine.ConfidenceThreshold(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("threshold = %v, want %v", got, tt.want)
+			}
+			if got := len(controller.Audit()); got != tt.audits {
+				t.Errorf("audit entries = %d, want %d", got, tt.audits)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Categories of the built-in rules.
+const (
+	CategoryContainer      = "container"
+	CategoryDependencies   = "dependencies"
+	CategoryResources      = "resources"
+	CategoryNetwork        = "network"
+	CategoryAccess         = "access"
+	CategoryKubernetes     = "kubernetes"
+	CategoryInfrastructure = "infrastructure"
+)
+
+// Categories returns the categories of the built-in rules.
+func Categories() []string {
+	return []string{
+		CategoryContainer,
+		CategoryDependencies,
+		CategoryResources,
+		CategoryNetwork,
+		CategoryAccess,
+		CategoryKubernetes,
+		CategoryInfrastructure,
+	}
+}
+
+// FilterCategories returns the rules whose category is in enabled (all
+// categories if enabled is empty) and not in disabled, e.g. disabled
+// "kubernetes" for a shop without clusters. Names are case-insensitive and
+// must be built-in categories. Rules without a category are always kept.
+func FilterCategories(rules []*Rule, enabled, disabled []string) ([]*Rule, error) {
+	enabledSet, err := categorySet(enabled)
+	if err != nil {
+		return nil, err
+	}
+	disabledSet, err := categorySet(disabled)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []*Rule
+	for _, rule := range rules {
+		category := strings.ToLower(rule.Category)
+		if category != "" {
+			if len(enabledSet) > 0 && !enabledSet[category] || disabledSet[category] {
+				continue
+			}
+		}
+		kept = append(kept, rule)
+	}
+	return kept, nil
+}
+
+func categorySet(names []string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	for _, category := range Categories() {
+		known[category] = true
+	}
+
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !known[name] {
+			return nil, fmt.Errorf("%w: unknown rule category %q (known: %s)",
+				domain.ErrInvalidConfig, name, strings.Join(Categories(), ", "))
+		}
+		set[name] = true
+	}
+	return set, nil
+}
+
+// Summary describes a rule for listings.
+type Summary struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Category    string          `json:"category,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Confidence  float64         `json:"confidence"`
+	ErrorType   string          `json:"error_type,omitempty"`
+	Severity    domain.Severity `json:"severity,omitempty"`
+	Section     string          `json:"section,omitempty"`
+	Condition   string          `json:"condition,omitempty"`
+}
+
+// Summary returns the listing view of the rule.
+func (r *Rule) Summary() Summary {
+	s := Summary{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Category:    r.Category,
+		Tags:        r.Tags,
+		Confidence:  r.Confidence,
+		Section:     r.Section,
+	}
+	if r.Result != nil {
+		s.ErrorType = r.Result.ErrorType
+		s.Severity = r.Result.Severity
+	}
+	if r.Condition != nil {
+		s.Condition = r.Condition.String()
+	}
+	return s
+}
+
+// HasTag reports whether the rule has tag (case-insensitive).
+func (r *Rule) HasTag(tag string) bool {
+	for _, t := range r.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
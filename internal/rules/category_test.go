@@ -0,0 +1,84 @@
+// Package rules provides unit tests for rule categories.
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestDefaultRules_Categories(t *testing.T) {
+	known := make(map[string]bool)
+	for _, category := range Categories() {
+		known[category] = true
+	}
+	for _, rule := range DefaultRules() {
+		if !known[rule.Category] {
+			t.Errorf("rule %q has unknown category %q", rule.ID, rule.Category)
+		}
+		if len(rule.Tags) == 0 {
+			t.Errorf("rule %q has no tags", rule.ID)
+		}
+	}
+}
+
+func TestFilterCategories(t *testing.T) {
+	custom := &Rule{ID: "custom"}
+	all := append(DefaultRules(), custom)
+
+	tests := []struct {
+		name     string
+		enabled  []string
+		disabled []string
+		check    func(t *testing.T, kept []*Rule)
+	}{
+		{
+			name: "no filters keeps everything",
+			check: func(t *testing.T, kept []*Rule) {
+				if len(kept) != len(all) {
+					t.Errorf("kept %d rules, want %d", len(kept), len(all))
+				}
+			},
+		},
+		{
+			name:     "disabled category is dropped",
+			disabled: []string{"Kubernetes"},
+			check: func(t *testing.T, kept []*Rule) {
+				for _, rule := range kept {
+					if rule.Category == CategoryKubernetes {
+						t.Errorf("kubernetes rule %q kept", rule.ID)
+					}
+				}
+			},
+		},
+		{
+			name:    "enabled restricts to categories and uncategorized rules",
+			enabled: []string{"network"},
+			check: func(t *testing.T, kept []*Rule) {
+				for _, rule := range kept {
+					if rule.Category != CategoryNetwork && rule != custom {
+						t.Errorf("rule %q of category %q kept", rule.ID, rule.Category)
+					}
+				}
+				if kept[len(kept)-1] != custom {
+					t.Error("uncategorized rule dropped")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, err := FilterCategories(all, tt.enabled, tt.disabled)
+			if err != nil {
+				t.Fatalf("FilterCategories() error = %v", err)
+			}
+			tt.check(t, kept)
+		})
+	}
+
+	if _, err := FilterCategories(all, nil, []string{"k8s"}); !errors.Is(err, domain.ErrInvalidConfig) {
+		t.Errorf("unknown category error = %v, want ErrInvalidConfig", err)
+	}
+}
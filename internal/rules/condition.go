@@ -0,0 +1,765 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Condition is a compiled boolean expression over a log and its request
+// metadata, for conditions keywords and regexes can't express. The language
+// is a subset of CEL (Common Expression Language):
+//
+//	metadata.runner_os == "windows" && log.contains("EPERM")
+//	extracted.exit_code == "137" || log.matches(r"Killed process \d+")
+//	"node" in metadata.tool_versions && metadata.tool_versions["node"].startsWith("16.")
+//
+// Variables are log (the sanitized log), metadata (the request metadata:
+// pipeline, stage, repository, branch, namespace, runner_os and arch, "" when unset,
+// plus the tool_versions map) and extracted (values extracted from the log,
+// keyed by the domain.Extracted* names). Expressions use the operators !,
+// &&, ||, ==, !=, <, <=, >, >= and in (map keys), the functions size and
+// int, and the string methods contains, startsWith, endsWith, matches,
+// lowerAscii and size. As in CEL, an evaluation error such as a missing map
+// key makes the condition false.
+//
+// Conditions compiled with CompileResultCondition can also refer to result
+// (error_type, severity and source of an analysis result).
+type Condition struct {
+	source        string
+	eval          evalFunc
+	usesExtracted bool
+}
+
+// CompileCondition parses and type-checks a condition. Regexes passed to
+// matches are compiled here, so a compiled condition cannot fail on syntax.
+func CompileCondition(source string) (*Condition, error) {
+	return compileCondition(source, false)
+}
+
+// CompileResultCondition is like CompileCondition but also declares the
+// result variable, for conditions evaluated after analysis with EvalResult.
+func CompileResultCondition(source string) (*Condition, error) {
+	return compileCondition(source, true)
+}
+
+func compileCondition(source string, withResult bool) (*Condition, error) {
+	tokens, err := lexCondition(source)
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %w", source, err)
+	}
+
+	p := &conditionParser{tokens: tokens, withResult: withResult}
+	e, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.unexpected(p.peek())
+	}
+	if err == nil && e.typ != boolType {
+		err = fmt.Errorf("condition must be a bool, got %s", e.typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %w", source, err)
+	}
+
+	return &Condition{source: source, eval: e.eval, usesExtracted: p.usesExtracted}, nil
+}
+
+// MustCompileCondition is like CompileCondition but panics on error. It is
+// meant for built-in rules.
+func MustCompileCondition(source string) *Condition {
+	c, err := CompileCondition(source)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// String returns the source of the condition.
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Eval reports whether the condition holds for the log and metadata.
+func (c *Condition) Eval(pre *domain.PreprocessedLog, meta *domain.LogMetadata) bool {
+	v, err := c.eval(&conditionEnv{pre: pre, meta: meta})
+	return err == nil && v.(bool)
+}
+
+// EvalResult reports whether the condition holds for the log, metadata and
+// an analysis result produced by source (e.g. "ai", "rules:oom_killed").
+func (c *Condition) EvalResult(pre *domain.PreprocessedLog, meta *domain.LogMetadata, result *domain.AnalysisResult, source string) bool {
+	v, err := c.eval(&conditionEnv{pre: pre, meta: meta, result: result, source: source})
+	return err == nil && v.(bool)
+}
+
+// conditionEnv holds the variables a condition is evaluated against.
+type conditionEnv struct {
+	pre    *domain.PreprocessedLog
+	meta   *domain.LogMetadata
+	result *domain.AnalysisResult
+	source string
+}
+
+// valueType is the static type of an expression.
+type valueType int
+
+const (
+	stringType valueType = iota
+	intType
+	boolType
+	mapType
+	metadataType
+	resultType
+)
+
+func (t valueType) String() string {
+	switch t {
+	case stringType:
+		return "string"
+	case intType:
+		return "int"
+	case boolType:
+		return "bool"
+	case mapType:
+		return "map"
+	case resultType:
+		return "result"
+	default:
+		return "metadata"
+	}
+}
+
+// evalFunc evaluates an expression to a string, int64, bool or
+// map[string]string matching its static type.
+type evalFunc func(env *conditionEnv) (any, error)
+
+// expr is a type-checked expression.
+type expr struct {
+	typ  valueType
+	eval evalFunc
+
+	// literal is set for string literals so that matches can compile its
+	// regex at load time.
+	literal *string
+}
+
+func constant(typ valueType, v any) *expr {
+	return &expr{typ: typ, eval: func(*conditionEnv) (any, error) { return v, nil }}
+}
+
+// metadataFields are the string fields of the metadata variable.
+var metadataFields = map[string]bool{
+	"pipeline": true, "stage": true, "repository": true,
+	"branch": true, "namespace": true, "runner_os": true, "arch": true,
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokOp
+)
+
+// token is a lexed token; text is the identifier, operator, digits or the
+// decoded string.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// conditionOps are the operators, longest first.
+var conditionOps = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "-", "(", ")", "[", "]", ".", ","}
+
+func lexCondition(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'' || ((c == 'r' || c == 'R') && i+1 < len(src) && (src[i+1] == '"' || src[i+1] == '\'')):
+			text, end, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: text, pos: i})
+			i = end
+		case isIdentByte(c, true):
+			start := i
+			for i < len(src) && isIdentByte(src[i], false) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokInt, text: src[start:i], pos: start})
+		default:
+			op := ""
+			for _, candidate := range conditionOps {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("at offset %d: unexpected character %q", i, c)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+func isIdentByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+// lexString decodes the quoted string starting at src[start], which may have
+// an r prefix for a raw string, and returns it with the offset after it.
+func lexString(src string, start int) (string, int, error) {
+	i := start
+	raw := src[i] == 'r' || src[i] == 'R'
+	if raw {
+		i++
+	}
+	quote := src[i]
+	i++
+
+	var b strings.Builder
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && !raw:
+			if i+1 >= len(src) {
+				return "", 0, fmt.Errorf("at offset %d: unterminated string", start)
+			}
+			switch esc := src[i+1]; esc {
+			case '\\', '"', '\'':
+				b.WriteByte(esc)
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				return "", 0, fmt.Errorf("at offset %d: invalid escape \\%c", i, esc)
+			}
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("at offset %d: unterminated string", start)
+}
+
+// conditionParser is a recursive-descent parser that type-checks while it
+// parses. Precedence from lowest: ||, &&, relations, unary, member access.
+type conditionParser struct {
+	tokens        []token
+	pos           int
+	usesExtracted bool
+	withResult    bool
+}
+
+func (p *conditionParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the operator op.
+func (p *conditionParser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) expect(op string) error {
+	if !p.accept(op) {
+		return fmt.Errorf("at offset %d: expected %q", p.peek().pos, op)
+	}
+	return nil
+}
+
+func (p *conditionParser) unexpected(tok token) error {
+	if tok.kind == tokEOF {
+		return errors.New("unexpected end of expression")
+	}
+	return fmt.Errorf("at offset %d: unexpected %q", tok.pos, tok.text)
+}
+
+func (p *conditionParser) parseOr() (*expr, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.accept("||") {
+		var right *expr
+		if right, err = p.parseAnd(); err == nil {
+			left, err = logical("||", left, right)
+		}
+	}
+	return left, err
+}
+
+func (p *conditionParser) parseAnd() (*expr, error) {
+	left, err := p.parseRelation()
+	for err == nil && p.accept("&&") {
+		var right *expr
+		if right, err = p.parseRelation(); err == nil {
+			left, err = logical("&&", left, right)
+		}
+	}
+	return left, err
+}
+
+func (p *conditionParser) parseRelation() (*expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	isRelation := tok.kind == tokIdent && tok.text == "in"
+	if tok.kind == tokOp {
+		switch tok.text {
+		case "==", "!=", "<", "<=", ">", ">=":
+			isRelation = true
+		}
+	}
+	if !isRelation {
+		return left, nil
+	}
+
+	p.next()
+	right, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return relation(tok.text, left, right)
+}
+
+func (p *conditionParser) parseUnary() (*expr, error) {
+	switch {
+	case p.accept("!"):
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operand.typ != boolType {
+			return nil, fmt.Errorf("operator ! needs a bool, got %s", operand.typ)
+		}
+		return &expr{typ: boolType, eval: func(env *conditionEnv) (any, error) {
+			v, err := operand.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			return !v.(bool), nil
+		}}, nil
+	case p.accept("-"):
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operand.typ != intType {
+			return nil, fmt.Errorf("operator - needs an int, got %s", operand.typ)
+		}
+		return &expr{typ: intType, eval: func(env *conditionEnv) (any, error) {
+			v, err := operand.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			return -v.(int64), nil
+		}}, nil
+	}
+	return p.parseMember()
+}
+
+func (p *conditionParser) parseMember() (*expr, error) {
+	e, err := p.parsePrimary()
+	for err == nil {
+		switch {
+		case p.accept("."):
+			name := p.next()
+			if name.kind != tokIdent {
+				return nil, p.unexpected(name)
+			}
+			if p.accept("(") {
+				var args []*expr
+				if args, err = p.parseArgs(); err == nil {
+					e, err = method(name.text, e, args)
+				}
+			} else {
+				e, err = field(name.text, e)
+			}
+		case p.accept("["):
+			var key *expr
+			if key, err = p.parseOr(); err == nil {
+				if err = p.expect("]"); err == nil {
+					e, err = index(e, key)
+				}
+			}
+		default:
+			return e, nil
+		}
+	}
+	return nil, err
+}
+
+// parseArgs parses call arguments after the opening parenthesis.
+func (p *conditionParser) parseArgs() ([]*expr, error) {
+	var args []*expr
+	if p.accept(")") {
+		return args, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.accept(")") {
+			return args, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *conditionParser) parsePrimary() (*expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokString:
+		text := tok.text
+		e := constant(stringType, text)
+		e.literal = &text
+		return e, nil
+	case tokInt:
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("at offset %d: %w", tok.pos, err)
+		}
+		return constant(intType, n), nil
+	case tokOp:
+		if tok.text != "(" {
+			return nil, p.unexpected(tok)
+		}
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	case tokIdent:
+		if p.accept("(") {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return function(tok.text, args)
+		}
+		return p.variable(tok)
+	}
+	return nil, p.unexpected(tok)
+}
+
+func (p *conditionParser) variable(tok token) (*expr, error) {
+	switch tok.text {
+	case "true", "false":
+		return constant(boolType, tok.text == "true"), nil
+	case "log":
+		return &expr{typ: stringType, eval: func(env *conditionEnv) (any, error) {
+			return env.pre.Sanitized, nil
+		}}, nil
+	case "metadata":
+		return &expr{typ: metadataType}, nil
+	case "extracted":
+		p.usesExtracted = true
+		return &expr{typ: mapType, eval: func(env *conditionEnv) (any, error) {
+			return env.pre.Metadata, nil
+		}}, nil
+	case "result":
+		if p.withResult {
+			return &expr{typ: resultType}, nil
+		}
+	}
+	return nil, fmt.Errorf("at offset %d: undeclared reference to %q", tok.pos, tok.text)
+}
+
+func logical(op string, left, right *expr) (*expr, error) {
+	if left.typ != boolType || right.typ != boolType {
+		return nil, fmt.Errorf("operator %s needs bool operands, got %s and %s", op, left.typ, right.typ)
+	}
+
+	// decisive is the operand value that decides the result on its own; as
+	// in CEL, it wins over an error in the other operand.
+	decisive := op == "||"
+	return &expr{typ: boolType, eval: func(env *conditionEnv) (any, error) {
+		l, lerr := left.eval(env)
+		if lerr == nil && l.(bool) == decisive {
+			return decisive, nil
+		}
+		r, rerr := right.eval(env)
+		if rerr == nil && r.(bool) == decisive {
+			return decisive, nil
+		}
+		if lerr != nil {
+			return nil, lerr
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+		return !decisive, nil
+	}}, nil
+}
+
+func relation(op string, left, right *expr) (*expr, error) {
+	if op == "in" {
+		if left.typ != stringType || right.typ != mapType {
+			return nil, fmt.Errorf("operator in needs a string and a map, got %s and %s", left.typ, right.typ)
+		}
+		return binary(left, right, boolType, func(l, r any) (any, error) {
+			_, ok := r.(map[string]string)[l.(string)]
+			return ok, nil
+		}), nil
+	}
+
+	if left.typ != right.typ {
+		return nil, fmt.Errorf("operator %s needs operands of one type, got %s and %s", op, left.typ, right.typ)
+	}
+	ordered := left.typ == stringType || left.typ == intType
+	if !ordered && (left.typ != boolType || (op != "==" && op != "!=")) {
+		return nil, fmt.Errorf("operator %s is not defined for %s", op, left.typ)
+	}
+
+	return binary(left, right, boolType, func(l, r any) (any, error) {
+		switch op {
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		}
+		cmp := compareOrdered(l, r)
+		switch op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	}), nil
+}
+
+// compareOrdered compares two strings or two int64s.
+func compareOrdered(l, r any) int {
+	if ls, ok := l.(string); ok {
+		return strings.Compare(ls, r.(string))
+	}
+	li, ri := l.(int64), r.(int64)
+	switch {
+	case li < ri:
+		return -1
+	case li > ri:
+		return 1
+	}
+	return 0
+}
+
+// binary evaluates both operands, then f.
+func binary(left, right *expr, typ valueType, f func(l, r any) (any, error)) *expr {
+	return &expr{typ: typ, eval: func(env *conditionEnv) (any, error) {
+		l, err := left.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		r, err := right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		return f(l, r)
+	}}
+}
+
+// unary evaluates the operand, then f.
+func unary(operand *expr, typ valueType, f func(v any) (any, error)) *expr {
+	return &expr{typ: typ, eval: func(env *conditionEnv) (any, error) {
+		v, err := operand.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		return f(v)
+	}}
+}
+
+func field(name string, e *expr) (*expr, error) {
+	switch e.typ {
+	case metadataType:
+		if metadataFields[name] {
+			return &expr{typ: stringType, eval: func(env *conditionEnv) (any, error) {
+				return env.meta.Get(name), nil
+			}}, nil
+		}
+		if name == "tool_versions" {
+			return &expr{typ: mapType, eval: func(env *conditionEnv) (any, error) {
+				if env.meta == nil {
+					return map[string]string(nil), nil
+				}
+				return env.meta.ToolVersions, nil
+			}}, nil
+		}
+		return nil, fmt.Errorf("metadata has no field %q", name)
+	case resultType:
+		return resultField(name)
+	case mapType:
+		return index(e, constant(stringType, name))
+	}
+	return nil, fmt.Errorf("%s has no fields", e.typ)
+}
+
+// resultField returns a string field of the result variable.
+func resultField(name string) (*expr, error) {
+	var get func(env *conditionEnv) string
+	switch name {
+	case "error_type":
+		get = func(env *conditionEnv) string { return env.result.ErrorType }
+	case "severity":
+		get = func(env *conditionEnv) string { return string(env.result.Severity) }
+	case "source":
+		get = func(env *conditionEnv) string { return env.source }
+	default:
+		return nil, fmt.Errorf("result has no field %q", name)
+	}
+	return &expr{typ: stringType, eval: func(env *conditionEnv) (any, error) {
+		if env.result == nil {
+			return nil, errors.New("no result")
+		}
+		return get(env), nil
+	}}, nil
+}
+
+func index(e, key *expr) (*expr, error) {
+	if e.typ != mapType || key.typ != stringType {
+		return nil, fmt.Errorf("cannot index %s with %s", e.typ, key.typ)
+	}
+	return binary(e, key, stringType, func(m, k any) (any, error) {
+		v, ok := m.(map[string]string)[k.(string)]
+		if !ok {
+			return nil, fmt.Errorf("no such key: %s", k)
+		}
+		return v, nil
+	}), nil
+}
+
+func function(name string, args []*expr) (*expr, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%s() takes one argument, got %d", name, len(args))
+	}
+	arg := args[0]
+
+	switch name {
+	case "size":
+		return size(arg)
+	case "int":
+		switch arg.typ {
+		case intType:
+			return arg, nil
+		case stringType:
+			return unary(arg, intType, func(v any) (any, error) {
+				return strconv.ParseInt(strings.TrimSpace(v.(string)), 10, 64)
+			}), nil
+		}
+		return nil, fmt.Errorf("int() is not defined for %s", arg.typ)
+	}
+	return nil, fmt.Errorf("undeclared function %q", name)
+}
+
+func size(e *expr) (*expr, error) {
+	switch e.typ {
+	case stringType:
+		return unary(e, intType, func(v any) (any, error) {
+			return int64(utf8.RuneCountInString(v.(string))), nil
+		}), nil
+	case mapType:
+		return unary(e, intType, func(v any) (any, error) {
+			return int64(len(v.(map[string]string))), nil
+		}), nil
+	}
+	return nil, fmt.Errorf("size() is not defined for %s", e.typ)
+}
+
+func method(name string, recv *expr, args []*expr) (*expr, error) {
+	if name == "size" && len(args) == 0 {
+		return size(recv)
+	}
+	if recv.typ != stringType {
+		return nil, fmt.Errorf("%s has no method %q", recv.typ, name)
+	}
+
+	switch name {
+	case "lowerAscii":
+		if len(args) != 0 {
+			return nil, fmt.Errorf("lowerAscii() takes no arguments, got %d", len(args))
+		}
+		return unary(recv, stringType, func(v any) (any, error) {
+			return strings.ToLower(v.(string)), nil
+		}), nil
+	case "contains", "startsWith", "endsWith", "matches":
+	default:
+		return nil, fmt.Errorf("string has no method %q", name)
+	}
+
+	if len(args) != 1 || args[0].typ != stringType {
+		return nil, fmt.Errorf("%s() takes one string argument", name)
+	}
+	arg := args[0]
+
+	var test func(s, a string) bool
+	switch name {
+	case "contains":
+		test = strings.Contains
+	case "startsWith":
+		test = strings.HasPrefix
+	case "endsWith":
+		test = strings.HasSuffix
+	case "matches":
+		if arg.literal == nil {
+			return nil, errors.New("matches() needs a string literal so the regex compiles at load time")
+		}
+		re, err := regexp.Compile(*arg.literal)
+		if err != nil {
+			return nil, fmt.Errorf("matches(): %w", err)
+		}
+		return unary(recv, boolType, func(v any) (any, error) {
+			return re.MatchString(v.(string)), nil
+		}), nil
+	}
+	return binary(recv, arg, boolType, func(s, a any) (any, error) {
+		return test(s.(string), a.(string)), nil
+	}), nil
+}
@@ -0,0 +1,150 @@
+// Package rules provides unit tests for rule conditions.
+package rules
+
+import (
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestCondition_Eval(t *testing.T) {
+	pre := &domain.PreprocessedLog{
+		Sanitized: "npm ERR! code EPERM\nnpm ERR! syscall rename",
+		Metadata:  map[string]string{domain.ExtractedExitCode: "137"},
+	}
+	meta := &domain.LogMetadata{
+		RunnerOS:     "windows",
+		ToolVersions: map[string]string{"node": "16.20.0"},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`metadata.runner_os == "windows" && log.contains("EPERM")`, true},
+		{`metadata.runner_os == "linux" && log.contains("EPERM")`, false},
+		{`"node" in metadata.tool_versions && metadata.tool_versions["node"].startsWith("16.")`, true},
+		{`"go" in metadata.tool_versions`, false},
+		{`int(extracted.exit_code) >= 128`, true},
+		{`log.matches(r"code E[A-Z]+")`, true},
+		{`log.lowerAscii().contains("syscall") && !log.endsWith("open")`, true},
+		{`size(extracted) == 1 && metadata.branch == ""`, true},
+		{`(metadata.arch == "arm64" || metadata.runner_os == 'windows') && true`, true},
+		// Missing keys are evaluation errors: false, unless || decides.
+		{`extracted.stack_trace.contains("panic")`, false},
+		{`!extracted.stack_trace.contains("panic")`, false},
+		{`extracted.stack_trace.contains("panic") || log.contains("npm")`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := CompileCondition(tt.expr)
+			if err != nil {
+				t.Fatalf("CompileCondition() error = %v", err)
+			}
+			if got := c.Eval(pre, meta); got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileCondition_Errors(t *testing.T) {
+	tests := []string{
+		`log`,
+		`logs.contains("x")`,
+		`metadata.os == "linux"`,
+		`log.contains(1)`,
+		`log == 1`,
+		`log.matches("(")`,
+		`log.matches(metadata.branch)`,
+		`true && "x"`,
+		`log.contains("x"`,
+		`log.contains("x") extra`,
+		`"unterminated`,
+		`log.contains("x") & true`,
+		`result.severity == "High"`,
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := CompileCondition(expr); err == nil {
+				t.Errorf("CompileCondition(%q) should fail", expr)
+			}
+		})
+	}
+}
+
+func TestCondition_EvalResult(t *testing.T) {
+	pre := &domain.PreprocessedLog{Sanitized: "npm ERR! code ENOTFOUND"}
+	meta := &domain.LogMetadata{Branch: "feature/x", Namespace: "prod"}
+	result := &domain.AnalysisResult{ErrorType: "npm_install_failure", Severity: domain.SeverityHigh}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`result.error_type == "npm_install_failure" && metadata.branch != "main"`, true},
+		{`result.severity == "High" && result.source.startsWith("rules:")`, true},
+		{`metadata.namespace == "prod" && log.contains("ENOTFOUND")`, true},
+		{`result.error_type == "oom_killed"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := CompileResultCondition(tt.expr)
+			if err != nil {
+				t.Fatalf("CompileResultCondition() error = %v", err)
+			}
+			if got := c.EvalResult(pre, meta, result, "rules:npm_registry"); got != tt.want {
+				t.Errorf("EvalResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := CompileResultCondition(`result.confidence > 1`); err == nil {
+		t.Error("unknown result field should fail to compile")
+	}
+}
+
+func TestEngine_ConditionRules(t *testing.T) {
+	windowsEPERM := &Rule{
+		ID:         "windows_file_lock",
+		Keywords:   []string{"EPERM"},
+		Condition:  MustCompileCondition(`metadata.runner_os == "windows"`),
+		Confidence: 0.9,
+		Result:     &domain.AnalysisResult{ErrorType: "file_locked"},
+	}
+	oomKilled := &Rule{
+		ID:         "oom_killed_exit",
+		Condition:  MustCompileCondition(`extracted.exit_code == "137"`),
+		Confidence: 0.85,
+		Result:     &domain.AnalysisResult{ErrorType: "out_of_memory"},
+	}
+	engine := NewEngine([]*Rule{windowsEPERM, oomKilled}, 0.8, zap.NewNop())
+
+	tests := []struct {
+		name string
+		log  string
+		meta *domain.LogMetadata
+		want []string
+	}{
+		{"comparison is case-sensitive", "Error: EPERM: operation not permitted", &domain.LogMetadata{RunnerOS: "Windows"}, nil},
+		{"windows runner", "Error: EPERM: operation not permitted", &domain.LogMetadata{RunnerOS: "windows"}, []string{"windows_file_lock"}},
+		{"linux runner", "Error: EPERM: operation not permitted", &domain.LogMetadata{RunnerOS: "linux"}, nil},
+		{"condition-only rule", "Process completed with exit code 137.", nil, []string{"oom_killed_exit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range engine.AnalyzeWithMetadata(tt.log, tt.meta) {
+				got = append(got, m.RuleID)
+			}
+			if len(got) != len(tt.want) || len(got) > 0 && got[0] != tt.want[0] {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
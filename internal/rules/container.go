@@ -0,0 +1,156 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"regexp"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// containerRules detects Docker daemon, build and registry failures.
+func containerRules() []*Rule {
+	return []*Rule{
+		dockerBuildPermissionDenied(),
+		dockerDaemonNotRunning(),
+		dockerImageNotFound(),
+		dockerPullRateLimit(),
+	}
+}
+
+func dockerBuildPermissionDenied() *Rule {
+	return &Rule{
+		ID:          "docker_build_permission",
+		Category:    CategoryContainer,
+		Tags:        []string{"docker", "permissions"},
+		Name:        "Docker Build Permission Denied",
+		Description: "Detects Docker build failures due to permission issues",
+		Keywords:    []string{"docker build", "permission denied"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)docker.*build.*permission\s+denied`),
+			regexp.MustCompile(`(?i)error.*docker.*EACCES`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeDockerPermissionDenied,
+			Severity:  domain.SeverityHigh,
+			RootCause: "Docker build failed due to insufficient permissions. This typically occurs when the user running Docker doesn't have access to required files or the Docker socket.",
+			SuggestedActions: []string{
+				"Ensure the user is in the 'docker' group: sudo usermod -aG docker $USER",
+				"Check file permissions in the build context",
+				"If using CI/CD, ensure the runner has Docker socket access",
+				"Verify Dockerfile COPY/ADD commands reference accessible files",
+			},
+			Commands: []domain.Command{
+				{Command: "sudo usermod -aG docker $USER", Description: "Add the user to the docker group; log in again afterwards"},
+			},
+			PreventionTips: []string{
+				"Run Docker with appropriate user permissions",
+				"Use multi-stage builds with proper ownership",
+				"Configure CI/CD runners with Docker access",
+			},
+		},
+	}
+}
+
+func dockerDaemonNotRunning() *Rule {
+	return &Rule{
+		ID:          "docker_daemon_not_running",
+		Category:    CategoryContainer,
+		Tags:        []string{"docker"},
+		Name:        "Docker Daemon Not Running",
+		Description: "Detects when Docker daemon is not available",
+		Keywords:    []string{"cannot connect to the docker daemon", "docker daemon is not running"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)cannot connect to the docker daemon`),
+			regexp.MustCompile(`(?i)is the docker daemon running`),
+			regexp.MustCompile(`(?i)docker\.sock.*no such file`),
+		},
+		Confidence: 0.95,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeDockerDaemonUnavailable,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The Docker daemon is not running or not accessible. Docker commands require a running daemon to execute.",
+			SuggestedActions: []string{
+				"Start the Docker daemon: sudo systemctl start docker",
+				"Check Docker service status: sudo systemctl status docker",
+				"Verify Docker installation: docker --version",
+				"If using Docker Desktop, ensure the application is running",
+			},
+			Commands: []domain.Command{
+				{Command: "sudo systemctl start docker", Description: "Start the Docker daemon"},
+				{Command: "sudo systemctl status docker", Description: "Show the daemon status and recent errors"},
+			},
+			PreventionTips: []string{
+				"Enable Docker to start on boot: sudo systemctl enable docker",
+				"Monitor Docker daemon health in production",
+				"Use Docker healthchecks in CI/CD pipelines",
+			},
+		},
+	}
+}
+
+func dockerImageNotFound() *Rule {
+	return &Rule{
+		ID:          "docker_image_not_found",
+		Category:    CategoryContainer,
+		Tags:        []string{"docker", "registry"},
+		Name:        "Docker Image Not Found",
+		Description: "Detects pulls of images or tags that do not exist or are not accessible",
+		Keywords:    []string{"manifest unknown", "pull access denied", "repository does not exist or may require 'docker login'"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)manifest for \S+ not found`),
+			regexp.MustCompile(`(?i)pull access denied for \S+`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeDockerImageNotFound,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Docker could not pull the image because the repository or tag does not exist, or the registry denied access to a private repository.",
+			SuggestedActions: []string{
+				"Check the image name and tag for typos",
+				"List the available tags in the registry",
+				"Run docker login for private repositories before pulling",
+				"Verify the image was pushed by the upstream pipeline",
+			},
+			Commands: []domain.Command{
+				{Command: "docker manifest inspect <image>:<tag>", Description: "Check that the tag exists in the registry"},
+			},
+			PreventionTips: []string{
+				"Pin base images to tags or digests that are known to exist",
+				"Push images before the jobs that consume them run",
+			},
+		},
+	}
+}
+
+func dockerPullRateLimit() *Rule {
+	return &Rule{
+		ID:          "docker_pull_rate_limit",
+		Category:    CategoryContainer,
+		Tags:        []string{"docker", "registry"},
+		Name:        "Docker Hub Pull Rate Limit",
+		Description: "Detects Docker Hub pull rate limiting",
+		Keywords:    []string{"toomanyrequests", "pull rate limit"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)you have reached your (unauthenticated )?pull rate limit`),
+		},
+		Confidence: 0.95,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeRegistryRateLimited,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Docker Hub rejected the pull because the anonymous or account pull rate limit was reached. Shared CI runners often exhaust the anonymous limit of their IP address.",
+			SuggestedActions: []string{
+				"Authenticate to Docker Hub before pulling to use the account limit",
+				"Retry the job after the rate limit window resets",
+				"Pull the image from a mirror or another registry",
+			},
+			Commands: []domain.Command{
+				{Command: "docker login", Description: "Authenticate to Docker Hub to use the account's pull limit"},
+			},
+			PreventionTips: []string{
+				"Configure a registry mirror or pull-through cache for CI",
+				"Copy frequently used base images to your own registry",
+			},
+		},
+	}
+}
@@ -0,0 +1,95 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Definition is the JSON form of a Rule, for rules written outside Go code
+// such as the ad-hoc rules of POST /rules/test. Patterns are regex sources
+// and Condition is a condition source; see Compile.
+type Definition struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	Description      string                 `json:"description"`
+	Category         string                 `json:"category"`
+	Tags             []string               `json:"tags"`
+	Keywords         []string               `json:"keywords"`
+	Patterns         []string               `json:"patterns"`
+	ExcludeKeywords  []string               `json:"exclude_keywords"`
+	ExcludePatterns  []string               `json:"exclude_patterns"`
+	Confidence       float64                `json:"confidence"`
+	Weights          map[string]float64     `json:"weights"`
+	RequiredMetadata map[string]string      `json:"required_metadata"`
+	Section          string                 `json:"section"`
+	Condition        string                 `json:"condition"`
+	Result           *domain.AnalysisResult `json:"result"`
+}
+
+// sections are the valid Rule.Section values.
+var sections = map[string]bool{
+	domain.ExtractedStackTrace:      true,
+	domain.ExtractedStackLanguage:   true,
+	domain.ExtractedException:       true,
+	domain.ExtractedStackTraceCount: true,
+	domain.ExtractedExitCode:        true,
+}
+
+// Compile validates the definition and compiles its patterns and condition
+// into a Rule.
+func (d *Definition) Compile() (*Rule, error) {
+	if len(d.Keywords) == 0 && len(d.Patterns) == 0 && d.Condition == "" {
+		return nil, errors.New("rule needs keywords, patterns or a condition")
+	}
+	if d.Confidence <= 0 || d.Confidence > 1 {
+		return nil, fmt.Errorf("rule confidence must be in (0, 1], got %v", d.Confidence)
+	}
+	if d.Section != "" && !sections[d.Section] {
+		return nil, fmt.Errorf("unknown rule section %q", d.Section)
+	}
+
+	rule := &Rule{
+		ID:               d.ID,
+		Name:             d.Name,
+		Description:      d.Description,
+		Category:         d.Category,
+		Tags:             d.Tags,
+		Keywords:         d.Keywords,
+		ExcludeKeywords:  d.ExcludeKeywords,
+		Confidence:       d.Confidence,
+		Weights:          d.Weights,
+		RequiredMetadata: d.RequiredMetadata,
+		Section:          d.Section,
+		Result:           d.Result,
+	}
+
+	var err error
+	if rule.Patterns, err = compilePatterns("pattern", d.Patterns); err != nil {
+		return nil, err
+	}
+	if rule.ExcludePatterns, err = compilePatterns("exclude pattern", d.ExcludePatterns); err != nil {
+		return nil, err
+	}
+	if d.Condition != "" {
+		if rule.Condition, err = CompileCondition(d.Condition); err != nil {
+			return nil, err
+		}
+	}
+	return rule, nil
+}
+
+func compilePatterns(kind string, sources []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(sources))
+	for _, source := range sources {
+		re, err := regexp.Compile(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %s %q: %w", kind, source, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
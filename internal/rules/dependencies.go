@@ -0,0 +1,347 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"regexp"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// dependencyRules detects package manager and dependency resolution failures.
+func dependencyRules() []*Rule {
+	return []*Rule{
+		npmInstallFailure(),
+		yarnInstallFailure(),
+		pnpmInstallFailure(),
+		mavenDependencyResolution(),
+		gradleDependencyResolution(),
+		pipInstallFailure(),
+		poetryDependencyResolution(),
+		goModuleChecksumMismatch(),
+		cargoBuildFailure(),
+	}
+}
+
+func npmInstallFailure() *Rule {
+	return &Rule{
+		ID:          "npm_install_failure",
+		Category:    CategoryDependencies,
+		Tags:        []string{"node", "npm"},
+		Name:        "NPM Install Failure",
+		Description: "Detects npm install failures",
+		Keywords:    []string{"npm err!", "npm install", "enoent", "package.json"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)npm ERR!.*code\s+E[A-Z]+`),
+			regexp.MustCompile(`(?i)npm ERR!.*404.*not found`),
+			regexp.MustCompile(`(?i)npm ERR!.*peer dep`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeNPMInstall,
+			Severity:  domain.SeverityMedium,
+			RootCause: "NPM package installation failed. This could be due to missing packages, version conflicts, network issues, or corrupted cache.",
+			SuggestedActions: []string{
+				"Clear npm cache: npm cache clean --force",
+				"Delete node_modules and package-lock.json, then reinstall",
+				"Check if the package exists and version is correct",
+				"Verify network connectivity to npm registry",
+				"Check for peer dependency conflicts",
+			},
+			Commands: []domain.Command{
+				{Command: "npm cache clean --force", Description: "Clear the npm cache"},
+				{Command: "rm -rf node_modules package-lock.json && npm install", Description: "Reinstall and regenerate the lockfile"},
+			},
+			PreventionTips: []string{
+				"Lock dependency versions in package-lock.json",
+				"Use npm ci in CI/CD for reproducible builds",
+				"Regularly update dependencies to avoid conflicts",
+			},
+		},
+	}
+}
+
+func yarnInstallFailure() *Rule {
+	return &Rule{
+		ID:          "yarn_install_failure",
+		Category:    CategoryDependencies,
+		Tags:        []string{"node", "yarn"},
+		Name:        "Yarn Install Failure",
+		Description: "Detects Yarn install and lockfile failures",
+		Keywords: []string{
+			"your lockfile needs to be updated, but yarn was run with",
+			"the lockfile would have been modified by this install",
+			"couldn't find package",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?im)^error An unexpected error occurred:`),
+			regexp.MustCompile(`\bYN0(001|018|028|035)\b`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeYarnInstall,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Yarn failed to install dependencies. The lockfile may be out of date with package.json while installs are frozen, a package or version may not exist, or a download failed its checksum.",
+			SuggestedActions: []string{
+				"Run yarn install locally and commit the updated yarn.lock",
+				"Check that every package and version in package.json exists in the registry",
+				"Clear the Yarn cache and retry: yarn cache clean",
+				"Verify registry configuration and credentials in .yarnrc.yml or .npmrc",
+			},
+			Commands: []domain.Command{
+				{Command: "yarn install", Description: "Update yarn.lock locally, then commit it"},
+				{Command: "yarn cache clean", Description: "Clear the Yarn cache"},
+			},
+			PreventionTips: []string{
+				"Install with --immutable (or --frozen-lockfile) in CI and keep yarn.lock committed",
+				"Pin the Yarn version with packageManager in package.json",
+			},
+		},
+	}
+}
+
+func pnpmInstallFailure() *Rule {
+	return &Rule{
+		ID:          "pnpm_install_failure",
+		Category:    CategoryDependencies,
+		Tags:        []string{"node", "pnpm"},
+		Name:        "pnpm Install Failure",
+		Description: "Detects pnpm install and lockfile failures",
+		Keywords:    []string{"err_pnpm_"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`ERR_PNPM_(OUTDATED_LOCKFILE|FETCH_\d+|NO_MATCHING_VERSION|PEER_DEP_ISSUES)`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypePNPMInstall,
+			Severity:  domain.SeverityMedium,
+			RootCause: "pnpm failed to install dependencies. The lockfile may not match package.json, a version may be missing from the registry, or peer dependency requirements are not met.",
+			SuggestedActions: []string{
+				"Run pnpm install locally and commit the updated pnpm-lock.yaml",
+				"Check the reported package and version in the registry",
+				"Resolve peer dependency conflicts or configure peerDependencyRules",
+				"Verify registry configuration and credentials in .npmrc",
+			},
+			Commands: []domain.Command{
+				{Command: "pnpm install", Description: "Update pnpm-lock.yaml locally, then commit it"},
+			},
+			PreventionTips: []string{
+				"Use pnpm install --frozen-lockfile in CI and keep the lockfile committed",
+				"Pin the pnpm version with packageManager in package.json",
+			},
+		},
+	}
+}
+
+func mavenDependencyResolution() *Rule {
+	return &Rule{
+		ID:          "maven_dependency_resolution",
+		Category:    CategoryDependencies,
+		Tags:        []string{"java", "maven"},
+		Name:        "Maven Dependency Resolution Failure",
+		Description: "Detects Maven builds that cannot resolve dependencies",
+		Keywords: []string{
+			"could not resolve dependencies for project",
+			"failed to read artifact descriptor for",
+			"could not transfer artifact",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)Failed to execute goal .*: Could not resolve dependencies`),
+			regexp.MustCompile(`(?i)was cached in the local repository, resolution will not be reattempted`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeMavenResolution,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Maven could not download one or more dependencies. The artifact or version may not exist, the repository may require credentials, or a failed download was cached in the local repository.",
+			SuggestedActions: []string{
+				"Check the artifact coordinates and version in pom.xml",
+				"Verify repository URLs and credentials in settings.xml",
+				"Force a re-download with mvn -U, or remove the artifact from ~/.m2/repository",
+				"Check connectivity to the repository or proxy",
+			},
+			Commands: []domain.Command{
+				{Command: "mvn -U dependency:resolve", Description: "Re-download the dependencies, ignoring cached failures"},
+			},
+			PreventionTips: []string{
+				"Use a repository manager (Nexus, Artifactory) as a mirror",
+				"Cache ~/.m2/repository in CI keyed by the pom.xml hash",
+			},
+		},
+	}
+}
+
+func gradleDependencyResolution() *Rule {
+	return &Rule{
+		ID:          "gradle_dependency_resolution",
+		Category:    CategoryDependencies,
+		Tags:        []string{"java", "gradle"},
+		Name:        "Gradle Dependency Resolution Failure",
+		Description: "Detects Gradle builds that cannot resolve dependencies",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)Could not resolve all (dependencies|files|artifacts|task dependencies) for configuration`),
+			regexp.MustCompile(`(?i)Could not find [\w.\-]+:[\w.\-]+:[\w.\-]+\.`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeGradleResolution,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Gradle could not resolve one or more dependencies. The artifact or version may not exist in the declared repositories, or a repository is unreachable or requires credentials.",
+			SuggestedActions: []string{
+				"Check the dependency coordinates and version",
+				"Verify the repositories block declares the repository hosting the artifact",
+				"Refresh dependencies: ./gradlew build --refresh-dependencies",
+				"Check repository credentials and network access",
+			},
+			Commands: []domain.Command{
+				{Command: "./gradlew build --refresh-dependencies", Description: "Re-resolve the dependencies, ignoring the cache"},
+			},
+			PreventionTips: []string{
+				"Use dependency locking or version catalogs for reproducible builds",
+				"Cache the Gradle dependency cache in CI",
+			},
+		},
+	}
+}
+
+func pipInstallFailure() *Rule {
+	return &Rule{
+		ID:          "pip_install_failure",
+		Category:    CategoryDependencies,
+		Tags:        []string{"python", "pip"},
+		Name:        "pip Install Failure",
+		Description: "Detects pip installs that cannot find, resolve or build packages",
+		Keywords: []string{
+			"could not find a version that satisfies the requirement",
+			"no matching distribution found for",
+			"resolutionimpossible",
+			"failed building wheel for",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)Cannot install .* because these package versions have conflicting dependencies`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypePipInstall,
+			Severity:  domain.SeverityMedium,
+			RootCause: "pip failed to install the requirements. A package version may not exist for this Python version or platform, requirements may conflict, or a source package failed to build because of missing system libraries.",
+			SuggestedActions: []string{
+				"Check that the pinned versions exist for the runner's Python version and platform",
+				"Relax or align conflicting version constraints",
+				"Install the system build dependencies a source package needs",
+				"Upgrade pip, setuptools and wheel before installing",
+			},
+			Commands: []domain.Command{
+				{Command: "python -m pip install --upgrade pip setuptools wheel", Description: "Upgrade the build tooling before installing"},
+			},
+			PreventionTips: []string{
+				"Pin dependencies with a lock or constraints file",
+				"Match the CI Python version to the one used to lock dependencies",
+			},
+		},
+	}
+}
+
+func poetryDependencyResolution() *Rule {
+	return &Rule{
+		ID:          "poetry_dependency_resolution",
+		Category:    CategoryDependencies,
+		Tags:        []string{"python", "poetry"},
+		Name:        "Poetry Dependency Failure",
+		Description: "Detects Poetry solver and lock file failures",
+		Keywords: []string{
+			"solverproblemerror",
+			"version solving failed",
+			"pyproject.toml changed significantly since poetry.lock was last generated",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)Because (no versions of|\S+ depends on) .* version solving failed`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypePoetryResolution,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Poetry could not install the project: its dependency constraints cannot be satisfied together, or poetry.lock is out of date with pyproject.toml.",
+			SuggestedActions: []string{
+				"Regenerate the lock file: poetry lock, then commit poetry.lock",
+				"Relax the conflicting constraints named in the solver output",
+				"Check that the Python version constraint matches the runner",
+			},
+			Commands: []domain.Command{
+				{Command: "poetry lock", Description: "Regenerate poetry.lock, then commit it"},
+			},
+			PreventionTips: []string{
+				"Run poetry check --lock in CI to catch stale lock files early",
+				"Update dependencies together with poetry update rather than by hand",
+			},
+		},
+	}
+}
+
+func goModuleChecksumMismatch() *Rule {
+	return &Rule{
+		ID:          "go_module_checksum_mismatch",
+		Category:    CategoryDependencies,
+		Tags:        []string{"go", "security"},
+		Name:        "Go Module Checksum Mismatch",
+		Description: "Detects Go module downloads that fail go.sum verification",
+		Keywords:    []string{"checksum mismatch", "this download does not match", "missing go.sum entry"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)verifying \S+@\S+: checksum mismatch`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeGoModuleChecksum,
+			Severity:  domain.SeverityHigh,
+			RootCause: "A Go module did not match the checksum recorded in go.sum or the checksum database, or go.sum lacks an entry. The module version may have been re-tagged upstream, go.sum may be stale, or the download may have been tampered with.",
+			SuggestedActions: []string{
+				"Run go mod tidy and commit the updated go.sum",
+				"Clear the module cache and retry: go clean -modcache",
+				"Check whether the module version was re-tagged upstream",
+				"Verify GOPROXY, GONOSUMDB and GOPRIVATE settings for private modules",
+			},
+			Commands: []domain.Command{
+				{Command: "go mod tidy", Description: "Update go.sum, then commit it"},
+				{Command: "go clean -modcache", Description: "Clear the module cache"},
+			},
+			PreventionTips: []string{
+				"Never re-tag released module versions",
+				"Run go mod verify in CI",
+			},
+		},
+	}
+}
+
+func cargoBuildFailure() *Rule {
+	return &Rule{
+		ID:          "cargo_build_failure",
+		Category:    CategoryDependencies,
+		Tags:        []string{"rust", "cargo"},
+		Name:        "Cargo Build Failure",
+		Description: "Detects Rust compilation failures",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile("(?i)error: could not compile `[^`]+`"),
+			regexp.MustCompile(`error\[E\d{4}\]`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeCargoBuild,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Cargo failed to compile a crate. The error code and message identify the compiler error, commonly type or borrow checker errors, or a dependency incompatible with the toolchain version.",
+			SuggestedActions: []string{
+				"Read the first error[E....] message; later errors often follow from it",
+				"Explain an error code with rustc --explain <code>",
+				"Check that the toolchain matches rust-toolchain.toml or the crate's rust-version",
+				"Build with cargo build --locked to use the versions in Cargo.lock",
+			},
+			Commands: []domain.Command{
+				{Command: "rustc --explain <code>", Description: "Explain the first error code"},
+				{Command: "cargo build --locked", Description: "Build with the versions in Cargo.lock"},
+			},
+			PreventionTips: []string{
+				"Pin the toolchain with rust-toolchain.toml",
+				"Run cargo check and cargo clippy before pushing",
+				"Commit Cargo.lock for applications",
+			},
+		},
+	}
+}
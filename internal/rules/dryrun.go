@@ -0,0 +1,118 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Hit is an occurrence of one keyword or pattern of a rule in a log.
+type Hit struct {
+	// Signal is the keyword or the pattern source.
+	Signal string `json:"signal"`
+
+	// Kind is "keyword" or "pattern".
+	Kind string `json:"kind"`
+
+	// Line is the 1-based line of the first occurrence and Match the text
+	// it matched.
+	Line  int    `json:"line"`
+	Match string `json:"match"`
+
+	// Weight is the signal's weight (see Rule.Weights).
+	Weight float64 `json:"weight"`
+
+	// Counted is false when the hit overlaps a heavier one or has no
+	// weight, so it does not add to the confidence.
+	Counted bool `json:"counted"`
+}
+
+// DryRunResult reports how a rule behaves on a log.
+type DryRunResult struct {
+	// Matched reports whether the engine would return a match, and
+	// Confidence its confidence.
+	Matched    bool    `json:"matched"`
+	Confidence float64 `json:"confidence"`
+
+	// Applies reports whether the metadata satisfies RequiredMetadata.
+	Applies bool `json:"applies"`
+
+	// ConditionHolds is the value of the rule's condition, if it has one.
+	ConditionHolds *bool `json:"condition_holds,omitempty"`
+
+	// Hits are the keywords and patterns found, ignoring excluded lines.
+	Hits []Hit `json:"hits"`
+
+	// Evidence are the matched lines reported with a match.
+	Evidence []domain.LogEvidence `json:"evidence,omitempty"`
+}
+
+// DryRun evaluates a single rule against log the way the engine would,
+// for testing rules without deploying them.
+func DryRun(rule *Rule, log string, meta *domain.LogMetadata) DryRunResult {
+	engine := NewEngine([]*Rule{rule}, 0, zap.NewNop())
+	pre := engine.preprocess(log)
+
+	result := DryRunResult{
+		Applies: rule.Applies(meta),
+		Hits:    []Hit{},
+	}
+	if rule.Condition != nil {
+		holds := rule.Condition.Eval(pre, meta)
+		result.ConditionHolds = &holds
+	}
+
+	text := pre.Sanitized
+	if rule.Section != "" {
+		text = pre.Metadata[rule.Section]
+	}
+	if offset, ok := sectionOffset(pre.Sanitized, text); ok {
+		result.Hits = rule.hits(text, offset)
+	}
+
+	if matches := engine.AnalyzePreprocessed(pre, meta); len(matches) > 0 {
+		result.Matched = true
+		result.Confidence = matches[0].Confidence
+		result.Evidence = matches[0].Evidence
+	}
+	return result
+}
+
+// hits lists every keyword and pattern occurring in text, numbering lines
+// from offset.
+func (r *Rule) hits(text string, offset int) []Hit {
+	text = r.withoutExcludedLines(text)
+
+	counted := make(map[signalHit]bool)
+	for _, hit := range r.signalHits(text) {
+		counted[hit] = true
+	}
+
+	hits := []Hit{}
+	for _, hit := range r.allSignalHits(text) {
+		kind := "keyword"
+		if hit.isPattern {
+			kind = "pattern"
+		}
+		hits = append(hits, Hit{
+			Signal:  hit.signal,
+			Kind:    kind,
+			Line:    offset + hit.line + 1,
+			Match:   matchText(text, hit),
+			Weight:  hit.weight,
+			Counted: counted[hit],
+		})
+	}
+	return hits
+}
+
+// matchText returns the text of a hit. Keyword offsets come from the
+// lower-cased text and only apply to text when lower-casing kept its length.
+func matchText(text string, hit signalHit) string {
+	if !hit.isPattern && len(strings.ToLower(text)) != len(text) {
+		return hit.signal
+	}
+	return text[hit.start:hit.end]
+}
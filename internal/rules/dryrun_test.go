@@ -0,0 +1,119 @@
+// Package rules provides unit tests for rule dry runs.
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestDefinition_Compile(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     Definition
+		wantErr string
+	}{
+		{
+			name: "valid",
+			def:  Definition{ID: "r", Patterns: []string{`exit code \d+`}, Condition: `log.contains("x")`, Confidence: 0.9},
+		},
+		{
+			name:    "no signals",
+			def:     Definition{ID: "r", Confidence: 0.9},
+			wantErr: "needs keywords",
+		},
+		{
+			name:    "confidence out of range",
+			def:     Definition{ID: "r", Keywords: []string{"x"}, Confidence: 1.5},
+			wantErr: "confidence",
+		},
+		{
+			name:    "invalid pattern",
+			def:     Definition{ID: "r", Patterns: []string{`(`}, Confidence: 0.9},
+			wantErr: "invalid rule pattern",
+		},
+		{
+			name:    "invalid condition",
+			def:     Definition{ID: "r", Condition: `log.contains(1)`, Confidence: 0.9},
+			wantErr: "condition",
+		},
+		{
+			name:    "unknown section",
+			def:     Definition{ID: "r", Keywords: []string{"x"}, Section: "body", Confidence: 0.9},
+			wantErr: "section",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.def.Compile()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Compile() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	def := Definition{
+		ID:              "oom_exit",
+		Keywords:        []string{"killed"},
+		Patterns:        []string{`exit code 137`, `killed process \d+`},
+		ExcludePatterns: []string{`(?i)^hint:`},
+		Confidence:      0.8,
+	}
+	rule, err := def.Compile()
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	log := "Hint: exit code 137 means OOM\nstep 3\nkilled process 4242 (java)\nError: exit code 137"
+	result := DryRun(rule, log, nil)
+
+	if !result.Matched || result.Confidence <= 0.8 {
+		t.Errorf("matched = %v, confidence = %v; want a match above 0.8", result.Matched, result.Confidence)
+	}
+	if !result.Applies || result.ConditionHolds != nil {
+		t.Errorf("applies = %v, condition = %v", result.Applies, result.ConditionHolds)
+	}
+
+	want := map[string]Hit{
+		"killed":             {Kind: "keyword", Line: 3, Match: "killed", Counted: true},
+		`killed process \d+`: {Kind: "pattern", Line: 3, Match: "killed process 4242", Counted: false},
+		`exit code 137`:      {Kind: "pattern", Line: 4, Match: "exit code 137", Counted: true},
+	}
+	if len(result.Hits) != len(want) {
+		t.Fatalf("hits = %+v, want %d", result.Hits, len(want))
+	}
+	for _, hit := range result.Hits {
+		w, ok := want[hit.Signal]
+		if !ok || hit.Kind != w.Kind || hit.Line != w.Line || hit.Match != w.Match || hit.Counted != w.Counted {
+			t.Errorf("hit %+v, want %+v", hit, w)
+		}
+	}
+}
+
+func TestDryRun_ConditionAndMetadata(t *testing.T) {
+	rule, err := (&Definition{
+		ID:               "windows_eperm",
+		Keywords:         []string{"EPERM"},
+		Condition:        `log.contains("rename")`,
+		RequiredMetadata: map[string]string{"runner_os": "windows"},
+		Confidence:       0.9,
+	}).Compile()
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	result := DryRun(rule, "npm ERR! code EPERM\nnpm ERR! syscall open", &domain.LogMetadata{RunnerOS: "linux"})
+	if result.Matched || result.Applies || result.ConditionHolds == nil || *result.ConditionHolds {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(result.Hits) != 1 {
+		t.Errorf("hits should be reported even without a match, got %+v", result.Hits)
+	}
+}
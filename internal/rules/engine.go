@@ -2,48 +2,153 @@
 package rules
 
 import (
+	"strings"
+	"sync"
+
 	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/extract"
 	"go.uber.org/zap"
 )
 
 // Engine applies rules to logs before AI analysis.
 type Engine struct {
-	rules               []*Rule
+	rules   []*Rule
+	matcher *matcher
+	logger  *zap.Logger
+
+	mu                  sync.RWMutex
 	confidenceThreshold float64
-	logger              *zap.Logger
 }
 
 // NewEngine creates a new rule engine with the provided configuration.
+// Rules must not be modified after they are passed to the engine.
 func NewEngine(rules []*Rule, confidenceThreshold float64, logger *zap.Logger) *Engine {
 	return &Engine{
 		rules:               rules,
+		matcher:             newMatcher(rules),
 		confidenceThreshold: confidenceThreshold,
 		logger:              logger.Named("rule_engine"),
 	}
 }
 
-// Analyze applies all rules to the log and returns matches.
+// Rules returns the rules the engine applies, in order.
+func (e *Engine) Rules() []*Rule {
+	return append([]*Rule(nil), e.rules...)
+}
+
+// Analyze applies all rules to the log and returns matches. Rules that
+// require request metadata never match.
 func (e *Engine) Analyze(log string) []domain.RuleMatch {
+	return e.AnalyzeWithMetadata(log, nil)
+}
+
+// AnalyzeWithMetadata applies the rules whose metadata conditions meta
+// satisfies and returns matches. Sections are extracted from log only if a
+// rule needs them.
+func (e *Engine) AnalyzeWithMetadata(log string, meta *domain.LogMetadata) []domain.RuleMatch {
+	return e.AnalyzePreprocessed(e.preprocess(log), meta)
+}
+
+// preprocess wraps log for AnalyzePreprocessed, extracting sections only if
+// a rule needs them.
+func (e *Engine) preprocess(log string) *domain.PreprocessedLog {
+	pre := &domain.PreprocessedLog{Original: log, Sanitized: log}
+	if e.usesSections() {
+		pre.Metadata = extract.Metadata(log)
+	}
+	return pre
+}
+
+// AnalyzePreprocessed is AnalyzeWithMetadata for a log whose sections have
+// already been extracted, so rules with a Section can match them.
+func (e *Engine) AnalyzePreprocessed(pre *domain.PreprocessedLog, meta *domain.LogMetadata) []domain.RuleMatch {
 	var matches []domain.RuleMatch
 
-	for _, rule := range e.rules {
-		if rule.Match(log) {
-			e.logger.Debug("rule matched",
-				zap.String("rule_id", rule.ID),
-				zap.Float64("confidence", rule.Confidence),
-			)
-
-			matches = append(matches, domain.RuleMatch{
-				RuleID:     rule.ID,
-				Confidence: rule.Confidence,
-				Result:     rule.Result,
-			})
+	matched := e.matcher.match(pre.Sanitized)
+	for i, rule := range e.rules {
+		text := pre.Sanitized
+		if rule.Section != "" {
+			text = pre.Metadata[rule.Section]
+			matched[i] = rule.MatchPreprocessed(pre)
+		}
+		signals := rule.hasSignals()
+		if signals && !matched[i] || !signals && rule.Condition == nil || !rule.Applies(meta) {
+			continue
+		}
+		if rule.Condition != nil && !rule.Condition.Eval(pre, meta) {
+			continue
+		}
+
+		// The matcher only finds candidates; the confidence depends on
+		// which signals occur in this log and how close together. A rule
+		// matching on its condition alone has its base confidence.
+		confidence := rule.Confidence
+		if signals {
+			confidence = rule.Score(text)
+		}
+		if confidence <= 0 {
+			continue
 		}
+		e.logger.Debug("rule matched",
+			zap.String("rule_id", rule.ID),
+			zap.Float64("confidence", confidence),
+		)
+
+		matches = append(matches, domain.RuleMatch{
+			RuleID:     rule.ID,
+			Confidence: confidence,
+			Result:     rule.Result,
+			Evidence:   evidenceIn(pre.Sanitized, text, rule),
+		})
 	}
 
 	return matches
 }
 
+// evidenceIn collects the rule's evidence from text and numbers the lines
+// relative to log, which text is either equal to or a section of. Evidence
+// from a section that cannot be located in the log is dropped rather than
+// pointing at the wrong lines.
+func evidenceIn(log, text string, rule *Rule) []domain.LogEvidence {
+	evidence := rule.Evidence(text)
+	if len(evidence) == 0 {
+		return evidence
+	}
+
+	offset, ok := sectionOffset(log, text)
+	if !ok {
+		return nil
+	}
+	for i := range evidence {
+		evidence[i].Line += offset
+	}
+	return evidence
+}
+
+// sectionOffset returns the number of lines of log before text, which is
+// either log itself or a section of it, and whether text was found.
+func sectionOffset(log, text string) (int, bool) {
+	if text == log {
+		return 0, true
+	}
+	idx := strings.Index(log, text)
+	if idx < 0 || text == "" {
+		return 0, false
+	}
+	return strings.Count(log[:idx], "\n"), true
+}
+
+// usesSections reports whether any rule matches an extracted section or
+// reads extracted values in its condition.
+func (e *Engine) usesSections() bool {
+	for _, rule := range e.rules {
+		if rule.Section != "" || rule.Condition != nil && rule.Condition.usesExtracted {
+			return true
+		}
+	}
+	return false
+}
+
 // GetBestMatch returns the highest confidence match that exceeds the threshold.
 // Returns nil if no match exceeds the threshold.
 func (e *Engine) GetBestMatch(matches []domain.RuleMatch) *domain.RuleMatch {
@@ -51,10 +156,12 @@ func (e *Engine) GetBestMatch(matches []domain.RuleMatch) *domain.RuleMatch {
 		return nil
 	}
 
+	threshold := e.ConfidenceThreshold()
+
 	var best *domain.RuleMatch
 	for i := range matches {
 		match := &matches[i]
-		if match.Confidence >= e.confidenceThreshold {
+		if match.Confidence >= threshold {
 			if best == nil || match.Confidence > best.Confidence {
 				best = match
 			}
@@ -64,8 +171,35 @@ func (e *Engine) GetBestMatch(matches []domain.RuleMatch) *domain.RuleMatch {
 	return best
 }
 
+// GetTopMatch returns the highest confidence match regardless of the threshold.
+// Returns nil if there are no matches.
+func (e *Engine) GetTopMatch(matches []domain.RuleMatch) *domain.RuleMatch {
+	var top *domain.RuleMatch
+	for i := range matches {
+		if top == nil || matches[i].Confidence > top.Confidence {
+			top = &matches[i]
+		}
+	}
+	return top
+}
+
 // ShouldUseRuleResult determines if a rule result should be used instead of AI.
 func (e *Engine) ShouldUseRuleResult(matches []domain.RuleMatch) bool {
 	best := e.GetBestMatch(matches)
-	return best != nil && best.Confidence >= e.confidenceThreshold
+	return best != nil && best.Confidence >= e.ConfidenceThreshold()
+}
+
+// ConfidenceThreshold returns the current minimum confidence for rule results.
+func (e *Engine) ConfidenceThreshold() float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.confidenceThreshold
+}
+
+// SetConfidenceThreshold updates the minimum confidence for rule results.
+// It is safe to call while the engine is serving requests.
+func (e *Engine) SetConfidenceThreshold(threshold float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.confidenceThreshold = threshold
 }
@@ -0,0 +1,73 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// maxEvidence bounds the evidence lines reported per match.
+const maxEvidence = 10
+
+// maxSnippetLength bounds the length of an evidence snippet in bytes.
+const maxSnippetLength = 200
+
+// Evidence returns the lines of log on which the rule's keywords or patterns
+// occur, in order and at most maxEvidence. Excluded lines are skipped, and
+// patterns that only match across lines yield no evidence.
+func (r *Rule) Evidence(log string) []domain.LogEvidence {
+	var evidence []domain.LogEvidence
+	for i, line := range strings.Split(log, "\n") {
+		if len(evidence) == maxEvidence {
+			break
+		}
+		if r.hasExclusions() && r.excludes(line) {
+			continue
+		}
+		if match := r.lineMatch(line); match != "" {
+			evidence = append(evidence, domain.LogEvidence{
+				Line:    i + 1,
+				Snippet: snippet(line),
+				Match:   match,
+			})
+		}
+	}
+	return evidence
+}
+
+// lineMatch returns the text on line that a keyword or pattern matches.
+func (r *Rule) lineMatch(line string) string {
+	lineLower := strings.ToLower(line)
+	for _, kw := range r.Keywords {
+		idx := strings.Index(lineLower, strings.ToLower(kw))
+		if idx < 0 || kw == "" {
+			continue
+		}
+		if len(lineLower) != len(line) {
+			// Lower-casing changed byte offsets; report the keyword itself.
+			return kw
+		}
+		return line[idx : idx+len(kw)]
+	}
+	for _, pattern := range r.Patterns {
+		if match := pattern.FindString(line); match != "" {
+			return match
+		}
+	}
+	return ""
+}
+
+// snippet trims line and shortens it to maxSnippetLength bytes.
+func snippet(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) <= maxSnippetLength {
+		return line
+	}
+	cut := maxSnippetLength
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "…"
+}
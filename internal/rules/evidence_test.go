@@ -0,0 +1,100 @@
+// Package rules provides unit tests for rule evidence.
+package rules
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestRule_Evidence(t *testing.T) {
+	rule := &Rule{
+		ID:              "oom",
+		Keywords:        []string{"OOMKilled"},
+		Patterns:        []*regexp.Regexp{regexp.MustCompile(`exit code \d+`)},
+		ExcludePatterns: []*regexp.Regexp{hintLinePattern},
+		Confidence:      0.9,
+	}
+
+	tests := []struct {
+		name string
+		log  string
+		want []domain.LogEvidence
+	}{
+		{
+			name: "keyword and pattern lines",
+			log:  "starting job\n  container oomkilled by kernel\nprocess exited with exit code 137",
+			want: []domain.LogEvidence{
+				{Line: 2, Snippet: "container oomkilled by kernel", Match: "oomkilled"},
+				{Line: 3, Snippet: "process exited with exit code 137", Match: "exit code 137"},
+			},
+		},
+		{
+			name: "excluded line skipped",
+			log:  "Hint: OOMKilled means the limit is too low\nstep failed: exit code 1",
+			want: []domain.LogEvidence{
+				{Line: 2, Snippet: "step failed: exit code 1", Match: "exit code 1"},
+			},
+		},
+		{
+			name: "no signal",
+			log:  "all good",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rule.Evidence(tt.log)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Evidence() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Evidence()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRule_EvidenceLimits(t *testing.T) {
+	rule := &Rule{ID: "fail", Keywords: []string{"FAIL"}}
+
+	long := "FAIL " + strings.Repeat("é", maxSnippetLength)
+	evidence := rule.Evidence(long)
+	if len(evidence) != 1 {
+		t.Fatalf("expected one evidence line, got %d", len(evidence))
+	}
+	if s := evidence[0].Snippet; len(s) > maxSnippetLength+len("…") || !strings.HasSuffix(s, "…") {
+		t.Errorf("snippet not shortened: %d bytes", len(s))
+	}
+
+	many := strings.Repeat("FAIL\n", maxEvidence+5)
+	if got := len(rule.Evidence(many)); got != maxEvidence {
+		t.Errorf("evidence lines = %d, want %d", got, maxEvidence)
+	}
+}
+
+func TestEngine_SectionEvidenceLines(t *testing.T) {
+	rule := &Rule{
+		ID:         "npe_in_trace",
+		Keywords:   []string{"NullPointerException"},
+		Section:    domain.ExtractedException,
+		Confidence: 0.9,
+		Result:     &domain.AnalysisResult{ErrorType: "null_pointer"},
+	}
+	engine := NewEngine([]*Rule{rule}, 0.8, zap.NewNop())
+
+	log := "build started\nrunning tests\njava.lang.NullPointerException: user is null\n\tat com.example.A.run(A.java:3)"
+	matches := engine.AnalyzeWithMetadata(log, nil)
+	if len(matches) != 1 || len(matches[0].Evidence) == 0 {
+		t.Fatalf("expected a match with evidence, got %+v", matches)
+	}
+	if line := matches[0].Evidence[0].Line; line != 3 {
+		t.Errorf("evidence line = %d, want 3", line)
+	}
+}
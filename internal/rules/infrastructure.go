@@ -0,0 +1,379 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"regexp"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// infrastructureRules detects infrastructure-as-code (Terraform, Pulumi,
+// CloudFormation) and database migration failures.
+func infrastructureRules() []*Rule {
+	return []*Rule{
+		terraformStateLock(),
+		terraformProviderInstall(),
+		terraformPlanFailure(),
+		terraformApplyFailure(),
+		pulumiStackLock(),
+		pulumiUpdateFailure(),
+		cloudFormationStackFailure(),
+		iacProviderAuth(),
+		iacDrift(),
+		databaseMigrationFailure(),
+	}
+}
+
+func terraformStateLock() *Rule {
+	return &Rule{
+		ID:          "terraform_state_lock",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"terraform"},
+		Name:        "Terraform State Lock",
+		Description: "Detects Terraform runs blocked by a held state lock",
+		Keywords:    []string{"error acquiring the state lock", "state blob is already locked", "conditionalcheckfailedexception"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)Error locking state`),
+		},
+		Confidence: 0.95,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeTerraformStateLocked,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Terraform could not acquire the state lock because another run holds it, or a previous run crashed without releasing it.",
+			SuggestedActions: []string{
+				"Check whether another plan or apply on the same state is still running",
+				"Once no run holds it, release a stale lock: terraform force-unlock <lock-id>",
+				"Use -lock-timeout to wait for short-lived locks",
+			},
+			Commands: []domain.Command{
+				{Command: "terraform force-unlock <lock-id>", Description: "Release a stale lock once no other run holds it"},
+			},
+			PreventionTips: []string{
+				"Serialize Terraform runs per state in CI",
+				"Avoid cancelling Terraform jobs mid-apply",
+			},
+		},
+	}
+}
+
+func terraformProviderInstall() *Rule {
+	return &Rule{
+		ID:          "terraform_provider_install",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"terraform"},
+		Name:        "Terraform Provider Installation Failure",
+		Description: "Detects terraform init failing to install providers",
+		Keywords: []string{
+			"failed to query available provider packages",
+			"could not retrieve the list of available versions for provider",
+			"failed to install provider",
+			"the current .terraform.lock.hcl file only includes checksums for",
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeTerraformProviderInstall,
+			Severity:  domain.SeverityMedium,
+			RootCause: "terraform init could not install a provider. The version constraints may not match any release, the registry or mirror may be unreachable, or the dependency lock file lacks checksums for the runner's platform.",
+			SuggestedActions: []string{
+				"Check the provider source and version constraints in required_providers",
+				"Verify access to registry.terraform.io or the configured provider mirror",
+				"Add checksums for all platforms: terraform providers lock -platform=linux_amd64 -platform=darwin_arm64",
+				"Run terraform init -upgrade after changing constraints",
+			},
+			Commands: []domain.Command{
+				{Command: "terraform init -upgrade", Description: "Reinstall providers after changing constraints"},
+				{Command: "terraform providers lock -platform=linux_amd64 -platform=darwin_arm64", Description: "Record provider checksums for every platform"},
+			},
+			PreventionTips: []string{
+				"Commit .terraform.lock.hcl with checksums for every platform that runs Terraform",
+				"Cache providers with TF_PLUGIN_CACHE_DIR or a provider mirror",
+			},
+		},
+	}
+}
+
+func terraformPlanFailure() *Rule {
+	return &Rule{
+		ID:          "terraform_plan_failure",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"terraform"},
+		Name:        "Terraform Plan Failure",
+		Description: "Detects invalid Terraform configuration rejected by validate or plan",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`Error: (Unsupported argument|Missing required argument|Unsupported attribute|Unsupported block type|Invalid reference|Reference to undeclared (resource|input variable|module|local value)|Invalid value for (input )?variable|Invalid function argument|Cycle: )`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeTerraformPlan,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Terraform rejected the configuration before changing anything: an argument, attribute or reference does not match the provider schema or the declared resources, variables and modules.",
+			SuggestedActions: []string{
+				"Open the file and line shown under the error and fix the named argument or reference",
+				"Check the argument against the provider version in .terraform.lock.hcl; arguments change between major versions",
+				"Run terraform validate locally to catch the remaining errors at once",
+			},
+			Commands: []domain.Command{
+				{Command: "terraform validate", Description: "Check the configuration without contacting providers"},
+				{Command: "terraform providers", Description: "Show the providers and versions the configuration requires"},
+			},
+			PreventionTips: []string{
+				"Run terraform fmt -check and terraform validate in CI before plan",
+				"Pin provider versions and read the upgrade guide before raising them",
+			},
+		},
+	}
+}
+
+func terraformApplyFailure() *Rule {
+	return &Rule{
+		ID:          "terraform_apply_failure",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"terraform"},
+		Name:        "Terraform Apply Failure",
+		Description: "Detects provider errors creating, updating or deleting resources during apply",
+		Keywords:    []string{"error applying plan"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`Error: (creating|updating|deleting|modifying|waiting for) [^\n:]+:`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeTerraformApply,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The cloud provider rejected a change during terraform apply, leaving the infrastructure partially changed. The API error names the cause, e.g. a quota, a permission, a name conflict or an invalid value.",
+			SuggestedActions: []string{
+				"Read the API error under the failing resource address (the 'with' line)",
+				"Fix the configuration or the account (quota, permission, conflicting resource) the error names",
+				"Run terraform plan again: resources created before the failure are already in the state",
+				"Import resources that exist outside Terraform instead of creating them",
+			},
+			Commands: []domain.Command{
+				{Command: "terraform plan", Description: "Show what remains to be applied after the partial apply"},
+				{Command: "terraform import <address> <id>", Description: "Adopt an existing resource instead of creating it"},
+			},
+			PreventionTips: []string{
+				"Review plans in CI and apply the reviewed plan file",
+				"Check quotas and permissions of the deployment role in a staging account first",
+			},
+		},
+	}
+}
+
+func pulumiStackLock() *Rule {
+	return &Rule{
+		ID:          "pulumi_stack_lock",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"pulumi"},
+		Name:        "Pulumi Stack Locked",
+		Description: "Detects Pulumi updates blocked by a concurrent update or pending operations",
+		Keywords: []string{
+			"the stack is currently locked by",
+			"another update is currently in progress",
+			"the current deployment has",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)conflict: Another update is currently in progress`),
+			regexp.MustCompile(`(?i)resource operation\(s\) pending`),
+		},
+		Confidence: 0.95,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypePulumiStackLocked,
+			Severity:  domain.SeverityMedium,
+			RootCause: "Pulumi could not start the update because another update holds the stack lock, or an interrupted update left pending operations in the checkpoint.",
+			SuggestedActions: []string{
+				"Check whether another update of the stack is still running and wait for it",
+				"Once no update runs, clear the lock: pulumi cancel (Pulumi Cloud) or remove the lock file of a self-managed backend",
+				"For pending operations, run pulumi refresh and check the resources it reports",
+			},
+			Commands: []domain.Command{
+				{Command: "pulumi stack history", Description: "Show the recent and running updates of the stack"},
+				{Command: "pulumi refresh", Description: "Reconcile pending operations with the cloud provider"},
+			},
+			PreventionTips: []string{
+				"Serialize updates per stack in CI",
+				"Avoid cancelling Pulumi jobs mid-update",
+			},
+		},
+	}
+}
+
+func pulumiUpdateFailure() *Rule {
+	return &Rule{
+		ID:          "pulumi_update_failure",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"pulumi"},
+		Name:        "Pulumi Update Failure",
+		Description: "Detects failed Pulumi previews, updates and destroys",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)error: (update|preview|destroy|refresh) failed`),
+			regexp.MustCompile(`pulumi:pulumi:Stack \S+ \*\*failed\*\*`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypePulumiUpdate,
+			Severity:  domain.SeverityHigh,
+			RootCause: "A Pulumi operation failed. The diagnostics name the failing resource and the provider or program error; resources changed before the failure stay changed.",
+			SuggestedActions: []string{
+				"Read the Diagnostics section for the resource (type and name) that failed",
+				"Fix the program or the account (quota, permission, conflicting resource) the error names",
+				"Run pulumi preview to see what remains after the partial update",
+			},
+			Commands: []domain.Command{
+				{Command: "pulumi preview --diff", Description: "Show the remaining changes in detail"},
+				{Command: "pulumi up --target <urn>", Description: "Retry the failing resource only"},
+			},
+			PreventionTips: []string{
+				"Run pulumi preview in CI on every change",
+				"Use pulumi import for resources that already exist",
+			},
+		},
+	}
+}
+
+func cloudFormationStackFailure() *Rule {
+	return &Rule{
+		ID:          "cloudformation_stack_failure",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"cloudformation", "aws"},
+		Name:        "CloudFormation Stack Failure",
+		Description: "Detects CloudFormation stacks that failed, rolled back or are stuck in a rollback state",
+		Keywords: []string{
+			"update_rollback_failed",
+			"the following resource(s) failed to",
+			"waiter stackcreatecomplete failed",
+			"waiter stackupdatecomplete failed",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\b(CREATE|UPDATE|DELETE)_FAILED\b`),
+			regexp.MustCompile(`is in (UPDATE_)?ROLLBACK_(COMPLETE|FAILED) state and can not be updated`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeCloudFormationStack,
+			Severity:  domain.SeverityHigh,
+			RootCause: "A CloudFormation stack operation failed and rolled back. The first *_FAILED event names the resource and the reason; later failures are usually cancellations caused by it.",
+			SuggestedActions: []string{
+				"Find the first CREATE_FAILED or UPDATE_FAILED event and its status reason",
+				"A stack in ROLLBACK_COMPLETE after its first create must be deleted before it can be deployed again",
+				"For UPDATE_ROLLBACK_FAILED, fix the blocking resource and continue the rollback, skipping it only if it can be left as is",
+			},
+			Commands: []domain.Command{
+				{Command: "aws cloudformation describe-stack-events --stack-name <stack>", Description: "List the stack events with their status reasons"},
+				{Command: "aws cloudformation continue-update-rollback --stack-name <stack>", Description: "Resume a rollback stuck in UPDATE_ROLLBACK_FAILED"},
+			},
+			PreventionTips: []string{
+				"Deploy through change sets and review them before executing",
+				"Validate templates with cfn-lint in CI",
+			},
+		},
+	}
+}
+
+func iacProviderAuth() *Rule {
+	return &Rule{
+		ID:          "iac_provider_auth",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"terraform", "pulumi", "cloudformation"},
+		Name:        "IaC Provider Authentication Failure",
+		Description: "Detects Terraform, Pulumi and CloudFormation runs without valid cloud credentials",
+		Keywords: []string{
+			"no valid credential sources found",
+			"failed to refresh cached credentials",
+			"could not find default credentials",
+			"building azurerm client",
+			"error configuring terraform aws provider",
+			"unable to locate credentials",
+			"invalidclienttokenid",
+			"the security token included in the request is expired",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)error configuring (the )?(backend|provider) "?\w+"?:.*(credential|token|unauthori)`),
+			regexp.MustCompile(`(?i)ExpiredToken(Exception)?:`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeIaCProviderAuth,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The infrastructure tool could not authenticate to the cloud provider: no credentials were found, or the credentials or assumed role session expired or are invalid.",
+			SuggestedActions: []string{
+				"Check which credential source the provider uses in CI: environment variables, profile, OIDC role or service principal",
+				"Verify the role or service account can be assumed and the session has not expired",
+				"Confirm the provider or backend configuration points at the right account, region and profile",
+			},
+			Commands: []domain.Command{
+				{Command: "aws sts get-caller-identity", Description: "Show the AWS identity the credentials resolve to"},
+				{Command: "gcloud auth application-default print-access-token", Description: "Check Google application default credentials"},
+				{Command: "az account show", Description: "Show the Azure account in use"},
+			},
+			PreventionTips: []string{
+				"Use short-lived OIDC credentials in CI instead of stored keys",
+				"Make session durations longer than the slowest apply",
+			},
+		},
+	}
+}
+
+func iacDrift() *Rule {
+	return &Rule{
+		ID:          "iac_drift",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"terraform", "pulumi", "cloudformation"},
+		Name:        "IaC Drift Detected",
+		Description: "Detects infrastructure changed outside Terraform, Pulumi or CloudFormation",
+		Keywords:    []string{"objects have changed outside of terraform", "drift detected"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`"?StackDriftStatus"?\s*[:=]\s*"?DRIFTED`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeIaCDrift,
+			Severity:  domain.SeverityMedium,
+			RootCause: "The deployed infrastructure no longer matches the state or template: resources were changed or deleted outside the infrastructure-as-code tool, so the next apply will revert or recreate them.",
+			SuggestedActions: []string{
+				"Review the changed attributes of each drifted resource",
+				"Keep intended changes by adding them to the code; otherwise let the next apply revert them",
+				"Find who changed the resource in the cloud audit log (e.g. CloudTrail)",
+			},
+			Commands: []domain.Command{
+				{Command: "terraform plan -refresh-only", Description: "Show the drift without proposing configuration changes"},
+				{Command: "pulumi refresh --preview-only", Description: "Show the drift of a Pulumi stack"},
+				{Command: "aws cloudformation detect-stack-drift --stack-name <stack>", Description: "Start drift detection on a CloudFormation stack"},
+			},
+			PreventionTips: []string{
+				"Restrict console and CLI write access to resources managed as code",
+				"Run drift detection on a schedule and alert on changes",
+			},
+		},
+	}
+}
+
+func databaseMigrationFailure() *Rule {
+	return &Rule{
+		ID:          "database_migration_failure",
+		Category:    CategoryInfrastructure,
+		Tags:        []string{"database"},
+		Name:        "Database Migration Failure",
+		Description: "Detects failed schema migrations",
+		Keywords:    []string{"migration failed", "dirty database version", "flywayexception", "migrations have failed validation"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)Migration \S+ failed`),
+			regexp.MustCompile(`(?i)liquibase.*(exception|migration failed)`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeDatabaseMigration,
+			Severity:  domain.SeverityHigh,
+			RootCause: "A database schema migration failed. The migration SQL may be invalid for the current schema, an applied migration may have been edited, or an earlier failure left the schema in a dirty state.",
+			SuggestedActions: []string{
+				"Read the failing statement and database error in the migration output",
+				"Check whether an already-applied migration file was modified",
+				"Repair the migration history (e.g. flyway repair, or migrate force for a dirty version) after fixing the schema",
+				"Test the migration against a copy of the production schema",
+			},
+			PreventionTips: []string{
+				"Never edit migrations that were already applied; add a new one",
+				"Run migrations against a production-like database in CI",
+				"Make migrations backward compatible with the running release",
+			},
+		},
+	}
+}
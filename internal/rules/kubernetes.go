@@ -0,0 +1,279 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"regexp"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// kubernetesRules detects Kubernetes workload and Helm release failures.
+func kubernetesRules() []*Rule {
+	return []*Rule{
+		kubernetesImagePullBackoff(),
+		kubernetesCrashLoopBackOff(),
+		kubernetesOOMKilled(),
+		kubernetesProbeFailure(),
+		helmReleaseFailed(),
+		kubernetesPodUnschedulable(),
+		kubernetesPVCPending(),
+	}
+}
+
+func kubernetesImagePullBackoff() *Rule {
+	return &Rule{
+		ID:          "k8s_image_pull_backoff",
+		Category:    CategoryKubernetes,
+		Tags:        []string{"registry", "pods"},
+		Name:        "Kubernetes Image Pull BackOff",
+		Description: "Detects Kubernetes image pull failures",
+		Keywords:    []string{"imagepullbackoff", "errimagepull", "failed to pull image"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)ImagePullBackOff`),
+			regexp.MustCompile(`(?i)ErrImagePull`),
+			regexp.MustCompile(`(?i)failed to pull image`),
+			regexp.MustCompile(`(?i)rpc error.*pulling image`),
+		},
+		Confidence: 0.95,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeKubernetesImagePull,
+			Severity:  domain.SeverityHigh,
+			RootCause: "Kubernetes cannot pull the specified container image. This could be due to image not existing, registry authentication issues, network problems, or incorrect image name/tag.",
+			SuggestedActions: []string{
+				"Verify the image name and tag are correct",
+				"Check if the image exists in the registry",
+				"Verify imagePullSecrets are configured correctly",
+				"Test registry connectivity from the cluster",
+				"Check if the registry requires authentication",
+			},
+			Commands: []domain.Command{
+				{Command: "kubectl describe pod <pod>", Description: "Show the pull error in the pod events"},
+			},
+			PreventionTips: []string{
+				"Use image digests instead of mutable tags",
+				"Implement CI/CD checks for image availability",
+				"Configure proper registry credentials in secrets",
+				"Use a container registry with high availability",
+			},
+		},
+	}
+}
+
+func kubernetesCrashLoopBackOff() *Rule {
+	return &Rule{
+		ID:          "k8s_crash_loop_backoff",
+		Category:    CategoryKubernetes,
+		Tags:        []string{"pods"},
+		Name:        "Kubernetes CrashLoopBackOff",
+		Description: "Detects containers that keep crashing after start",
+		Keywords:    []string{"crashloopbackoff", "back-off restarting failed container"},
+		Confidence:  0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeCrashLoopBackoff,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The container starts and exits repeatedly, so Kubernetes backs off restarting it. The application is failing at startup, e.g. on missing configuration, an unreachable dependency or a failing entrypoint.",
+			SuggestedActions: []string{
+				"Inspect the previous container logs: kubectl logs <pod> --previous",
+				"Describe the pod for the exit code and last state: kubectl describe pod <pod>",
+				"Verify environment variables, secrets and config maps the app needs",
+				"Check that the command and entrypoint exist in the image",
+			},
+			Commands: []domain.Command{
+				{Command: "kubectl logs <pod> --previous", Description: "Show the logs of the crashed container"},
+				{Command: "kubectl describe pod <pod>", Description: "Show the exit code and last state"},
+			},
+			PreventionTips: []string{
+				"Fail fast with clear log messages on invalid configuration",
+				"Run the image with production configuration in CI before deploying",
+				"Use startup probes for slow-starting applications",
+			},
+		},
+	}
+}
+
+func kubernetesOOMKilled() *Rule {
+	return &Rule{
+		ID:          "k8s_oom_killed",
+		Category:    CategoryKubernetes,
+		Tags:        []string{"pods", "memory"},
+		Name:        "Kubernetes Container OOMKilled",
+		Description: "Detects containers killed for exceeding their memory limit",
+		Keywords:    []string{"reason: oomkilled"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)Terminated,? +Reason: +OOMKilled`),
+			regexp.MustCompile(`(?i)Reason: +OOMKilled.*Exit Code: +137`),
+			regexp.MustCompile(`(?i)Limits:.*memory=`),
+		},
+		Weights: map[string]float64{
+			`(?i)Limits:.*memory=`: 0.5,
+		},
+		Confidence: 0.95,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeOutOfMemory,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The container used more memory than its resources.limits.memory and the kernel killed it (OOMKilled, exit code 137). Either the limit is too low for the workload or the application leaks or buffers too much memory.",
+			SuggestedActions: []string{
+				"Compare the container's memory usage with its limit: kubectl top pod <pod> --containers",
+				"Raise resources.limits.memory (and requests) if the usage is expected",
+				"Make the runtime respect the limit, e.g. -XX:MaxRAMPercentage for the JVM or --max-old-space-size for Node",
+				"Profile the application if the usage grows until it is killed",
+			},
+			Commands: []domain.Command{
+				{Command: "kubectl top pod <pod> --containers", Description: "Show the memory usage of each container"},
+				{Command: "kubectl describe pod <pod>", Description: "Show the memory limit and the last termination reason"},
+			},
+			PreventionTips: []string{
+				"Set memory requests and limits from observed peak usage",
+				"Alert on container_memory_working_set_bytes approaching the limit",
+			},
+		},
+	}
+}
+
+func kubernetesProbeFailure() *Rule {
+	return &Rule{
+		ID:          "k8s_probe_failure",
+		Category:    CategoryKubernetes,
+		Tags:        []string{"pods", "health-checks"},
+		Name:        "Kubernetes Probe Failure",
+		Description: "Detects failing liveness, readiness and startup probes",
+		Keywords:    []string{"liveness probe failed", "readiness probe failed", "startup probe failed"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)container \S+ failed (liveness|startup) probe, will be restarted`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeProbeFailure,
+			Severity:  domain.SeverityMedium,
+			RootCause: "A health probe of the container failed. Failing liveness probes restart the container and failing readiness probes remove it from service endpoints; the probe may target the wrong port or path, or time out before the app is ready.",
+			SuggestedActions: []string{
+				"Check the probe path, port and scheme against the application",
+				"Increase initialDelaySeconds, timeoutSeconds or failureThreshold for slow starts",
+				"Add a startup probe so liveness checks wait for startup",
+				"Check whether the app is overloaded or blocked on a dependency",
+			},
+			Commands: []domain.Command{
+				{Command: "kubectl describe pod <pod>", Description: "Show the probe failures in the pod events"},
+			},
+			PreventionTips: []string{
+				"Keep liveness endpoints cheap and independent of dependencies",
+				"Tune probe timings from measured startup times",
+			},
+		},
+	}
+}
+
+func helmReleaseFailed() *Rule {
+	return &Rule{
+		ID:          "helm_release_failed",
+		Category:    CategoryKubernetes,
+		Tags:        []string{"helm"},
+		Name:        "Helm Release Failed",
+		Description: "Detects failed or blocked Helm installs and upgrades",
+		Keywords: []string{
+			"error: upgrade failed",
+			"error: installation failed",
+			"another operation (install/upgrade/rollback) is in progress",
+			"has no deployed releases",
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeHelmReleaseFailed,
+			Severity:  domain.SeverityHigh,
+			RootCause: "Helm could not install or upgrade the release. Common causes are a release stuck in a pending state from an interrupted operation, a failed previous release, invalid rendered manifests or resources that did not become ready before the timeout.",
+			SuggestedActions: []string{
+				"Inspect the release history: helm history <release>",
+				"Roll back a release stuck in pending-upgrade: helm rollback <release> <revision>",
+				"Render the chart locally with helm template to validate manifests",
+				"Check the events of the release's pods for readiness failures",
+			},
+			Commands: []domain.Command{
+				{Command: "helm history <release>", Description: "Show the release revisions and their status"},
+				{Command: "helm rollback <release> <revision>", Description: "Roll back to a working revision"},
+			},
+			PreventionTips: []string{
+				"Use --atomic so failed upgrades roll back automatically",
+				"Serialize deployments of the same release in CI",
+				"Lint and template charts in CI before deploying",
+			},
+		},
+	}
+}
+
+func kubernetesPodUnschedulable() *Rule {
+	return &Rule{
+		ID:          "k8s_pod_unschedulable",
+		Category:    CategoryKubernetes,
+		Tags:        []string{"pods", "scheduling"},
+		Name:        "Kubernetes Pod Unschedulable",
+		Description: "Detects pods that no node can run",
+		Keywords:    []string{"failedscheduling", "insufficient cpu", "insufficient memory", "didn't match pod's node affinity"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)0/\d+ nodes are available`),
+		},
+		// Pods waiting for their volume claims fail scheduling too
+		ExcludeKeywords: []string{"unbound immediate persistentvolumeclaims"},
+		Confidence:      0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypePodUnschedulable,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The scheduler found no node that satisfies the pod's resource requests, node selectors, affinity rules or taint tolerations, so the pod stays Pending.",
+			SuggestedActions: []string{
+				"Describe the pod to see why each node was rejected",
+				"Lower the pod's CPU and memory requests if they are oversized",
+				"Scale up the node pool or enable the cluster autoscaler",
+				"Check node selectors, affinity rules and tolerations",
+			},
+			Commands: []domain.Command{
+				{Command: "kubectl describe pod <pod>", Description: "Show why each node rejected the pod"},
+			},
+			PreventionTips: []string{
+				"Size resource requests from observed usage",
+				"Alert on pods pending longer than a few minutes",
+			},
+		},
+	}
+}
+
+func kubernetesPVCPending() *Rule {
+	return &Rule{
+		ID:          "k8s_pvc_pending",
+		Category:    CategoryKubernetes,
+		Tags:        []string{"pods", "storage"},
+		Name:        "Kubernetes PersistentVolumeClaim Pending",
+		Description: "Detects pods waiting for a persistent volume claim to bind, provision or attach",
+		Keywords: []string{
+			"unbound immediate persistentvolumeclaims",
+			"waiting for a volume to be created",
+			"waiting for first consumer to be created before binding",
+			"provisioningfailed",
+			"failedattachvolume",
+			"no persistent volumes available for this claim",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)persistentvolumeclaim "[^"]+" not found`),
+			regexp.MustCompile(`(?i)FailedMount.*Unable to attach or mount volumes`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypePVCPending,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The pod cannot start because a PersistentVolumeClaim it mounts is not bound or attached: the claim does not exist, no volume matches it, the storage class cannot provision one, or the volume is still attached to another node.",
+			SuggestedActions: []string{
+				"Check the claim's status and events: kubectl describe pvc <claim>",
+				"Verify the storage class exists and its provisioner is running",
+				"Check that the requested size, access mode and zone can be satisfied",
+				"For attach errors, check whether the volume is still attached to another node",
+			},
+			Commands: []domain.Command{
+				{Command: "kubectl get pvc -n <namespace>", Description: "List the claims and whether they are bound"},
+				{Command: "kubectl describe pvc <claim>", Description: "Show why the claim is not bound"},
+				{Command: "kubectl get storageclass", Description: "List the storage classes and the default one"},
+			},
+			PreventionTips: []string{
+				"Create claims with the workload and set a default storage class",
+				"Use volumeBindingMode WaitForFirstConsumer for zonal storage",
+			},
+		},
+	}
+}
@@ -0,0 +1,271 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// matcher evaluates many rules against one log in a single pass. Keywords of
+// all rules, plus a literal that each regex requires in order to match, are
+// compiled into one Aho-Corasick automaton; a regex is only run when its
+// required literal occurs in the log. Results are identical to calling
+// Rule.Match on every rule.
+type matcher struct {
+	automaton *ahoCorasick
+	rules     []*Rule
+
+	// keywordRules maps an automaton pattern ID to the rules that have it
+	// as a keyword.
+	keywordRules [][]int
+
+	// regexes are the patterns of each rule with their prefilters.
+	regexes [][]prefilteredRegex
+}
+
+// prefilteredRegex is a rule pattern and the automaton pattern IDs of the
+// literals it requires; any one of them must occur for the pattern to
+// match. A nil literal list means the pattern is always evaluated.
+type prefilteredRegex struct {
+	pattern  *regexp.Regexp
+	literals []int
+}
+
+// newMatcher compiles rules into a matcher. Rules with a Section match
+// extracted text rather than the log and are left out. Rules must not be
+// modified afterwards.
+func newMatcher(rules []*Rule) *matcher {
+	m := &matcher{
+		rules:   rules,
+		regexes: make([][]prefilteredRegex, len(rules)),
+	}
+
+	var patterns []string
+	ids := make(map[string]int)
+	add := func(literal string) int {
+		if id, ok := ids[literal]; ok {
+			return id
+		}
+		ids[literal] = len(patterns)
+		patterns = append(patterns, literal)
+		m.keywordRules = append(m.keywordRules, nil)
+		return ids[literal]
+	}
+
+	for i, rule := range rules {
+		if rule.Section != "" {
+			continue
+		}
+		for _, kw := range rule.Keywords {
+			if kw = strings.ToLower(kw); kw != "" {
+				id := add(kw)
+				m.keywordRules[id] = append(m.keywordRules[id], i)
+			}
+		}
+		for _, pattern := range rule.Patterns {
+			entry := prefilteredRegex{pattern: pattern}
+			for _, literal := range requiredLiterals(pattern) {
+				entry.literals = append(entry.literals, add(literal))
+			}
+			m.regexes[i] = append(m.regexes[i], entry)
+		}
+	}
+
+	m.automaton = newAhoCorasick(patterns)
+	return m
+}
+
+// match reports, for each rule, whether it matches log.
+func (m *matcher) match(log string) []bool {
+	hits := m.automaton.scan(strings.ToLower(log))
+
+	matched := make([]bool, len(m.rules))
+	for id, hit := range hits {
+		if hit {
+			for _, rule := range m.keywordRules[id] {
+				matched[rule] = true
+			}
+		}
+	}
+
+	for i, regexes := range m.regexes {
+		if matched[i] {
+			continue
+		}
+		for _, entry := range regexes {
+			if entry.literals != nil && !anyHit(hits, entry.literals) {
+				continue
+			}
+			if entry.pattern.MatchString(log) {
+				matched[i] = true
+				break
+			}
+		}
+	}
+
+	// Excluding lines can only remove matches, so the whole-log result is a
+	// prefilter; confirm it for rules that ignore some lines.
+	for i, rule := range m.rules {
+		if matched[i] && rule.hasExclusions() {
+			matched[i] = rule.Match(log)
+		}
+	}
+	return matched
+}
+
+func anyHit(hits []bool, ids []int) bool {
+	for _, id := range ids {
+		if hits[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredLiterals returns lower-cased literals of which at least one occurs
+// (case-insensitively) in every string pattern matches, or nil if no such
+// set can be derived.
+func requiredLiterals(pattern *regexp.Regexp) []string {
+	re, err := syntax.Parse(pattern.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return literalsOf(re.Simplify())
+}
+
+func literalsOf(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		literal := string(re.Rune)
+		for _, r := range literal {
+			if r > unicode.MaxASCII {
+				return nil
+			}
+		}
+		return []string{strings.ToLower(literal)}
+	case syntax.OpCapture:
+		return literalsOf(re.Sub[0])
+	case syntax.OpPlus:
+		return literalsOf(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return literalsOf(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		// Any child's literals will do; prefer the most selective one.
+		var best []string
+		for _, sub := range re.Sub {
+			if literals := literalsOf(sub); literals != nil && shortest(literals) > shortest(best) {
+				best = literals
+			}
+		}
+		return best
+	case syntax.OpAlternate:
+		var all []string
+		for _, sub := range re.Sub {
+			literals := literalsOf(sub)
+			if literals == nil {
+				return nil
+			}
+			all = append(all, literals...)
+		}
+		return all
+	}
+	return nil
+}
+
+// shortest returns the length of the shortest literal, or 0 for none.
+func shortest(literals []string) int {
+	n := 0
+	for i, literal := range literals {
+		if i == 0 || len(literal) < n {
+			n = len(literal)
+		}
+	}
+	return n
+}
+
+// ahoCorasick finds every occurrence of a fixed set of byte strings in one
+// pass over the text.
+type ahoCorasick struct {
+	next     []map[byte]int
+	fail     []int
+	outputs  [][]int
+	patterns int
+}
+
+func newAhoCorasick(patterns []string) *ahoCorasick {
+	ac := &ahoCorasick{
+		next:     []map[byte]int{{}},
+		fail:     []int{0},
+		outputs:  [][]int{nil},
+		patterns: len(patterns),
+	}
+
+	for id, pattern := range patterns {
+		state := 0
+		for i := 0; i < len(pattern); i++ {
+			next, ok := ac.next[state][pattern[i]]
+			if !ok {
+				next = len(ac.next)
+				ac.next = append(ac.next, map[byte]int{})
+				ac.fail = append(ac.fail, 0)
+				ac.outputs = append(ac.outputs, nil)
+				ac.next[state][pattern[i]] = next
+			}
+			state = next
+		}
+		ac.outputs[state] = append(ac.outputs[state], id)
+	}
+
+	// Breadth-first: a state's failure link points to the longest proper
+	// suffix that is also a prefix, and it inherits that state's outputs.
+	queue := make([]int, 0, len(ac.next))
+	for _, child := range ac.next[0] {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for b, child := range ac.next[state] {
+			fail := ac.fail[state]
+			for {
+				if target, ok := ac.next[fail][b]; ok && target != child {
+					ac.fail[child] = target
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = ac.fail[fail]
+			}
+			ac.outputs[child] = append(ac.outputs[child], ac.outputs[ac.fail[child]]...)
+			queue = append(queue, child)
+		}
+	}
+	return ac
+}
+
+// scan reports which patterns occur in text.
+func (ac *ahoCorasick) scan(text string) []bool {
+	hits := make([]bool, ac.patterns)
+	state := 0
+	for i := 0; i < len(text); i++ {
+		for {
+			if next, ok := ac.next[state][text[i]]; ok {
+				state = next
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = ac.fail[state]
+		}
+		for _, id := range ac.outputs[state] {
+			hits[id] = true
+		}
+	}
+	return hits
+}
@@ -0,0 +1,124 @@
+// Package rules provides unit tests for the single-pass rule matcher.
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestMatcher_EquivalentToRuleMatch checks that the automaton and regex
+// prefilters never change which rules match.
+func TestMatcher_EquivalentToRuleMatch(t *testing.T) {
+	rules := DefaultRules()
+	m := newMatcher(rules)
+
+	logs := []string{
+		"ERROR: docker build failed: permission denied",
+		"Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?",
+		"dial unix /var/run/docker.sock: connect: no such file or directory",
+		"npm ERR! code ENOENT\nnpm ERR! syscall open",
+		"java.lang.OutOfMemoryError: Java heap space",
+		"fork/exec /bin/sh: Cannot allocate memory",
+		"Warning  Failed  pod/myapp-abc123  Failed to pull image: ErrImagePull",
+		"rpc error: code = Unknown desc = Error response from daemon: pulling image",
+		"write /tmp/x: No Space Left On Device",
+		"x509: certificate has expired or is not yet valid",
+		"listen tcp :8080: bind: address already in use",
+		"fatal: Authentication failed for 'https://github.com/org/repo.git/'",
+		"dial tcp 10.0.0.1:5432: i/o timeout",
+		"INFO: Application started successfully",
+		"",
+	}
+
+	for _, log := range logs {
+		got := m.match(log)
+		for i, rule := range rules {
+			if want := rule.Match(log); got[i] != want {
+				t.Errorf("rule %s on %q: matcher = %v, Rule.Match = %v", rule.ID, log, got[i], want)
+			}
+		}
+	}
+}
+
+func TestRequiredLiterals(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`(?i)out\s+of\s+memory`, []string{"memory"}},
+		{`AKIA[0-9A-Z]{16}`, []string{"akia"}},
+		{`(?i)(api[_-]?key|apikey)\s*=`, []string{"api"}},
+		{`(mysql|redis)://`, []string{"mysql", "redis"}},
+		{`(?i)ImagePullBackOff`, []string{"imagepullbackoff"}},
+		{`\d+\s\d+`, nil},
+		{`(foo)?bar*`, []string{"ba"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got := requiredLiterals(regexp.MustCompile(tt.pattern))
+			sort.Strings(got)
+			sort.Strings(tt.want)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requiredLiterals() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAhoCorasick_Scan(t *testing.T) {
+	ac := newAhoCorasick([]string{"he", "she", "his", "hers", "xyz"})
+	got := ac.scan("ushers")
+	want := []bool{true, true, false, true, false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scan() = %v, want %v", got, want)
+	}
+}
+
+// benchmarkRules is the default rule set plus n generated rules, roughly the
+// size of a large user-loaded rule set.
+func benchmarkRules(n int) []*Rule {
+	rules := DefaultRules()
+	for i := 0; i < n; i++ {
+		rules = append(rules, &Rule{
+			ID:       fmt.Sprintf("generated_%d", i),
+			Keywords: []string{fmt.Sprintf("component%d crashed", i)},
+			Patterns: []*regexp.Regexp{regexp.MustCompile(fmt.Sprintf(`(?i)service%d\s+exited with status \d+`, i))},
+		})
+	}
+	return rules
+}
+
+func benchmarkLog() string {
+	return strings.Repeat("INFO compiling module with gradle daemon worker\n", 1000) +
+		"FATAL: container killed: OOMKilled"
+}
+
+func BenchmarkEngine_Analyze(b *testing.B) {
+	engine := NewEngine(benchmarkRules(300), 0.8, zap.NewNop())
+	log := benchmarkLog()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.Analyze(log)
+	}
+}
+
+// BenchmarkRule_MatchEach is the per-rule loop the matcher replaces.
+func BenchmarkRule_MatchEach(b *testing.B) {
+	rules := benchmarkRules(300)
+	log := benchmarkLog()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, rule := range rules {
+			rule.Match(log)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"regexp"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// networkRules detects connectivity, DNS, TLS and port failures.
+func networkRules() []*Rule {
+	return []*Rule{
+		connectionTimeout(),
+		sslCertificateError(),
+		portAlreadyInUse(),
+		dnsResolutionFailure(),
+		proxyTLSHandshakeFailure(),
+	}
+}
+
+func connectionTimeout() *Rule {
+	return &Rule{
+		ID:              "connection_timeout",
+		Category:        CategoryNetwork,
+		Tags:            []string{"connectivity"},
+		Name:            "Connection Timeout",
+		Description:     "Detects connection timeout errors",
+		Keywords:        []string{"connection timed out", "timeout", "etimedout", "connection refused"},
+		ExcludePatterns: []*regexp.Regexp{hintLinePattern},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)connection\s+timed?\s*out`),
+			regexp.MustCompile(`(?i)ETIMEDOUT`),
+			regexp.MustCompile(`(?i)ECONNREFUSED`),
+			regexp.MustCompile(`(?i)dial tcp.*timeout`),
+		},
+		Confidence: 0.85,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeConnectionTimeout,
+			Severity:  domain.SeverityMedium,
+			RootCause: "A network connection attempt timed out. This could indicate the target service is down, network issues, firewall blocking, or incorrect host/port configuration.",
+			SuggestedActions: []string{
+				"Verify the target service is running and healthy",
+				"Check network connectivity: ping, telnet, curl",
+				"Review firewall rules and security groups",
+				"Verify the host and port configuration",
+				"Check DNS resolution",
+			},
+			Commands: []domain.Command{
+				{Command: "curl -v --max-time 10 <url>", Description: "Test connectivity to the service from the runner"},
+			},
+			PreventionTips: []string{
+				"Implement health checks for dependencies",
+				"Use circuit breakers for external services",
+				"Configure appropriate timeout values",
+				"Add retry logic with exponential backoff",
+			},
+		},
+	}
+}
+
+func sslCertificateError() *Rule {
+	return &Rule{
+		ID:          "ssl_certificate_error",
+		Category:    CategoryNetwork,
+		Tags:        []string{"tls"},
+		Name:        "SSL Certificate Error",
+		Description: "Detects SSL/TLS certificate issues",
+		Keywords:    []string{"certificate verify failed", "ssl", "certificate expired", "unable to verify"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)certificate\s+verify\s+failed`),
+			regexp.MustCompile(`(?i)SSL.*certificate.*expired`),
+			regexp.MustCompile(`(?i)unable to verify the first certificate`),
+			regexp.MustCompile(`(?i)self.signed certificate`),
+			regexp.MustCompile(`(?i)x509.*certificate`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeSSLCertificate,
+			Severity:  domain.SeverityHigh,
+			RootCause: "SSL/TLS certificate validation failed. The certificate may be expired, self-signed, issued by an untrusted CA, or the hostname doesn't match.",
+			SuggestedActions: []string{
+				"Check certificate expiration date",
+				"Verify the certificate chain is complete",
+				"Ensure the CA is trusted in the system's trust store",
+				"Verify the hostname matches the certificate CN/SAN",
+				"For internal services, add the CA to trusted certificates",
+			},
+			Commands: []domain.Command{
+				{Command: "openssl s_client -connect <host>:443 -servername <host> -showcerts </dev/null", Description: "Show the certificate chain the server presents"},
+			},
+			PreventionTips: []string{
+				"Set up certificate expiration monitoring",
+				"Use automated certificate renewal (Let's Encrypt)",
+				"Implement certificate rotation procedures",
+				"Document internal CA trust requirements",
+			},
+		},
+	}
+}
+
+func portAlreadyInUse() *Rule {
+	return &Rule{
+		ID:              "port_in_use",
+		Category:        CategoryNetwork,
+		Tags:            []string{"connectivity"},
+		Name:            "Port Already In Use",
+		Description:     "Detects port binding conflicts",
+		Keywords:        []string{"address already in use", "eaddrinuse", "port is already allocated"},
+		ExcludePatterns: []*regexp.Regexp{hintLinePattern},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)address already in use`),
+			regexp.MustCompile(`(?i)EADDRINUSE`),
+			regexp.MustCompile(`(?i)bind.*port.*already`),
+			regexp.MustCompile(`(?i)port\s+\d+.*is already allocated`),
+		},
+		Confidence: 0.95,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypePortInUse,
+			Severity:  domain.SeverityMedium,
+			RootCause: "The application cannot bind to the specified port because another process is already using it.",
+			SuggestedActions: []string{
+				"Find the process using the port: lsof -i :<port> or netstat -tlnp",
+				"Stop the conflicting process or service",
+				"Configure the application to use a different port",
+				"Check for zombie processes from previous runs",
+			},
+			Commands: []domain.Command{
+				{Command: "lsof -i :<port>", Description: "Find the process using the port"},
+			},
+			PreventionTips: []string{
+				"Use unique ports for each service",
+				"Implement graceful shutdown to release ports",
+				"Use port 0 for dynamic port allocation in tests",
+				"Document port assignments in project documentation",
+			},
+		},
+	}
+}
+
+func dnsResolutionFailure() *Rule {
+	return &Rule{
+		ID:          "dns_resolution_failure",
+		Category:    CategoryNetwork,
+		Tags:        []string{"dns"},
+		Name:        "DNS Resolution Failure",
+		Description: "Detects host names that cannot be resolved",
+		Keywords: []string{
+			"nxdomain",
+			"no such host",
+			"could not resolve host",
+			"temporary failure in name resolution",
+			"name or service not known",
+			"getaddrinfo enotfound",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)lookup \S+( on \S+)?: no such host`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeDNSResolution,
+			Severity:  domain.SeverityMedium,
+			RootCause: "A host name could not be resolved. The name may be misspelled or not exist (NXDOMAIN), or the runner's DNS server is unreachable or cannot see private zones.",
+			SuggestedActions: []string{
+				"Check the host name for typos and that the record exists: dig <host>",
+				"Verify the DNS servers configured on the runner or in the pod's resolv.conf",
+				"Check whether the host lives in a private zone the runner cannot resolve",
+				"Retry if the failure was a temporary resolver outage",
+			},
+			Commands: []domain.Command{
+				{Command: "dig <host>", Description: "Check that the record resolves"},
+			},
+			PreventionTips: []string{
+				"Configure host names through environment-specific configuration",
+				"Monitor DNS resolution from CI runners and clusters",
+			},
+		},
+	}
+}
+
+func proxyTLSHandshakeFailure() *Rule {
+	return &Rule{
+		ID:          "proxy_tls_handshake_failure",
+		Category:    CategoryNetwork,
+		Tags:        []string{"proxy", "tls"},
+		Name:        "Proxy or TLS Handshake Failure",
+		Description: "Detects failures connecting through proxies and failed TLS handshakes",
+		Keywords: []string{
+			"tls handshake timeout",
+			"tls: handshake failure",
+			"proxyconnect tcp",
+			"407 proxy authentication required",
+			"ssl_error_syscall",
+			"wrong version number",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)received HTTP code 40[37] from proxy after CONNECT`),
+		},
+		Confidence: 0.9,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeProxyTLSHandshake,
+			Severity:  domain.SeverityMedium,
+			RootCause: "The TLS handshake or the connection through an HTTP proxy failed. The proxy may require authentication or block the host, HTTP_PROXY/HTTPS_PROXY may be misconfigured, or a TLS-intercepting proxy or plain-HTTP endpoint breaks the handshake.",
+			SuggestedActions: []string{
+				"Check HTTP_PROXY, HTTPS_PROXY and NO_PROXY on the runner",
+				"Verify proxy credentials and that the proxy allows the target host",
+				"Confirm the endpoint speaks TLS on that port (wrong version number means plain HTTP)",
+				"Install the proxy's CA certificate if it intercepts TLS",
+			},
+			Commands: []domain.Command{
+				{Command: "env | grep -i _proxy", Description: "Show the proxy settings of the runner"},
+			},
+			PreventionTips: []string{
+				"Manage proxy settings centrally in runner images",
+				"Keep internal hosts in NO_PROXY",
+			},
+		},
+	}
+}
@@ -0,0 +1,144 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import "github.com/ai-devops/internal/domain"
+
+// referenceLinks are curated official documentation pages per error type.
+// Built-in rule results without their own references link to them.
+var referenceLinks = map[string][]domain.Reference{
+	domain.ErrorTypeDockerDaemonUnavailable: {
+		{Title: "Start the Docker daemon", URL: "https://docs.docker.com/engine/daemon/start/"},
+		{Title: "Troubleshooting the Docker daemon", URL: "https://docs.docker.com/engine/daemon/troubleshoot/"},
+	},
+	domain.ErrorTypeDockerPermissionDenied: {
+		{Title: "Linux post-installation steps for Docker Engine", URL: "https://docs.docker.com/engine/install/linux-postinstall/"},
+	},
+	domain.ErrorTypeDockerBuild: {
+		{Title: "Dockerfile reference", URL: "https://docs.docker.com/reference/dockerfile/"},
+	},
+	domain.ErrorTypeDockerImageNotFound: {
+		{Title: "docker manifest inspect", URL: "https://docs.docker.com/reference/cli/docker/manifest/inspect/"},
+	},
+	domain.ErrorTypeRegistryRateLimited: {
+		{Title: "Docker Hub usage and limits", URL: "https://docs.docker.com/docker-hub/usage/"},
+	},
+	domain.ErrorTypeKubernetesImagePull: {
+		{Title: "Images", URL: "https://kubernetes.io/docs/concepts/containers/images/"},
+		{Title: "Pull an Image from a Private Registry", URL: "https://kubernetes.io/docs/tasks/configure-pod-container/pull-image-private-registry/"},
+	},
+	domain.ErrorTypeCrashLoopBackoff: {
+		{Title: "Debug Running Pods", URL: "https://kubernetes.io/docs/tasks/debug/debug-application/debug-running-pod/"},
+		{Title: "Determine the Reason for Pod Failure", URL: "https://kubernetes.io/docs/tasks/debug/debug-application/determine-reason-pod-failure/"},
+	},
+	domain.ErrorTypeProbeFailure: {
+		{Title: "Configure Liveness, Readiness and Startup Probes", URL: "https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/"},
+	},
+	domain.ErrorTypePodUnschedulable: {
+		{Title: "Assigning Pods to Nodes", URL: "https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node/"},
+		{Title: "Resource Management for Pods and Containers", URL: "https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/"},
+	},
+	domain.ErrorTypePVCPending: {
+		{Title: "Persistent Volumes", URL: "https://kubernetes.io/docs/concepts/storage/persistent-volumes/"},
+		{Title: "Storage Classes", URL: "https://kubernetes.io/docs/concepts/storage/storage-classes/"},
+	},
+	domain.ErrorTypeHelmReleaseFailed: {
+		{Title: "helm history", URL: "https://helm.sh/docs/helm/helm_history/"},
+		{Title: "helm rollback", URL: "https://helm.sh/docs/helm/helm_rollback/"},
+	},
+	domain.ErrorTypeTerraformStateLocked: {
+		{Title: "State locking", URL: "https://developer.hashicorp.com/terraform/language/state/locking"},
+		{Title: "terraform force-unlock", URL: "https://developer.hashicorp.com/terraform/cli/commands/force-unlock"},
+	},
+	domain.ErrorTypeTerraformProviderInstall: {
+		{Title: "terraform init", URL: "https://developer.hashicorp.com/terraform/cli/commands/init"},
+		{Title: "Dependency lock file", URL: "https://developer.hashicorp.com/terraform/language/files/dependency-lock"},
+	},
+	domain.ErrorTypeTerraformApply: {
+		{Title: "terraform apply", URL: "https://developer.hashicorp.com/terraform/cli/commands/apply"},
+	},
+	domain.ErrorTypeTerraformPlan: {
+		{Title: "terraform validate", URL: "https://developer.hashicorp.com/terraform/cli/commands/validate"},
+	},
+	domain.ErrorTypePulumiStackLocked: {
+		{Title: "pulumi cancel", URL: "https://www.pulumi.com/docs/iac/cli/commands/pulumi_cancel/"},
+	},
+	domain.ErrorTypePulumiUpdate: {
+		{Title: "pulumi up", URL: "https://www.pulumi.com/docs/iac/cli/commands/pulumi_up/"},
+	},
+	domain.ErrorTypeCloudFormationStack: {
+		{Title: "Troubleshooting CloudFormation", URL: "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/troubleshooting.html"},
+	},
+	domain.ErrorTypeIaCDrift: {
+		{Title: "Refresh-only mode", URL: "https://developer.hashicorp.com/terraform/tutorials/state/refresh"},
+	},
+	domain.ErrorTypeNPMInstall: {
+		{Title: "npm install", URL: "https://docs.npmjs.com/cli/commands/npm-install"},
+		{Title: "npm ci", URL: "https://docs.npmjs.com/cli/commands/npm-ci"},
+	},
+	domain.ErrorTypeYarnInstall: {
+		{Title: "yarn install", URL: "https://yarnpkg.com/cli/install"},
+	},
+	domain.ErrorTypePNPMInstall: {
+		{Title: "pnpm install", URL: "https://pnpm.io/cli/install"},
+	},
+	domain.ErrorTypePipInstall: {
+		{Title: "Dependency Resolution", URL: "https://pip.pypa.io/en/stable/topics/dependency-resolution/"},
+	},
+	domain.ErrorTypePoetryResolution: {
+		{Title: "Dependency specification", URL: "https://python-poetry.org/docs/dependency-specification/"},
+	},
+	domain.ErrorTypeMavenResolution: {
+		{Title: "Introduction to the Dependency Mechanism", URL: "https://maven.apache.org/guides/introduction/introduction-to-dependency-mechanism.html"},
+	},
+	domain.ErrorTypeGradleResolution: {
+		{Title: "Viewing and Debugging Dependencies", URL: "https://docs.gradle.org/current/userguide/viewing_debugging_dependencies.html"},
+	},
+	domain.ErrorTypeGoModuleChecksum: {
+		{Title: "Checksum database", URL: "https://go.dev/ref/mod#checksum-database"},
+	},
+	domain.ErrorTypeCargoBuild: {
+		{Title: "cargo build", URL: "https://doc.rust-lang.org/cargo/commands/cargo-build.html"},
+	},
+	domain.ErrorTypeOutOfMemory: {
+		{Title: "Assign Memory Resources to Containers and Pods", URL: "https://kubernetes.io/docs/tasks/configure-pod-container/assign-memory-resource/"},
+		{Title: "Resource constraints", URL: "https://docs.docker.com/engine/containers/resource_constraints/"},
+	},
+	domain.ErrorTypeDiskSpaceFull: {
+		{Title: "Prune unused Docker objects", URL: "https://docs.docker.com/engine/manage-resources/pruning/"},
+	},
+	domain.ErrorTypeDNSResolution: {
+		{Title: "Debugging DNS Resolution", URL: "https://kubernetes.io/docs/tasks/administer-cluster/dns-debugging-resolution/"},
+	},
+	domain.ErrorTypeGitAuthentication: {
+		{Title: "Troubleshooting SSH", URL: "https://docs.github.com/en/authentication/troubleshooting-ssh"},
+		{Title: "gitcredentials", URL: "https://git-scm.com/docs/gitcredentials"},
+	},
+	domain.ErrorTypeCITokenPermission: {
+		{Title: "Controlling permissions for GITHUB_TOKEN", URL: "https://docs.github.com/en/actions/writing-workflows/choosing-what-your-workflow-does/controlling-permissions-for-github_token"},
+		{Title: "GitLab CI/CD job token", URL: "https://docs.gitlab.com/ci/jobs/ci_job_token/"},
+	},
+	domain.ErrorTypeWindowsPathTooLong: {
+		{Title: "Maximum Path Length Limitation", URL: "https://learn.microsoft.com/en-us/windows/win32/fileio/maximum-file-path-limitation"},
+	},
+}
+
+// ReferencesFor returns the curated documentation links for errorType, or
+// nil if there are none. The returned slice may be modified.
+func ReferencesFor(errorType string) []domain.Reference {
+	refs := referenceLinks[errorType]
+	if refs == nil {
+		return nil
+	}
+	return append([]domain.Reference(nil), refs...)
+}
+
+// withReferences links the results of rules without references to the
+// curated documentation for their error type.
+func withReferences(rules []*Rule) []*Rule {
+	for _, rule := range rules {
+		if rule.Result != nil && rule.Result.References == nil {
+			rule.Result.References = ReferencesFor(rule.Result.ErrorType)
+		}
+	}
+	return rules
+}
@@ -0,0 +1,129 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"regexp"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// resourceRules detects exhausted memory, disk and path limits.
+func resourceRules() []*Rule {
+	return []*Rule{
+		outOfMemory(),
+		diskSpaceFull(),
+		windowsPathTooLong(),
+	}
+}
+
+func outOfMemory() *Rule {
+	return &Rule{
+		ID:          "out_of_memory",
+		Category:    CategoryResources,
+		Tags:        []string{"memory"},
+		Name:        "Out of Memory",
+		Description: "Detects out of memory errors",
+		Keywords:    []string{"out of memory", "oomkilled", "memory allocation failed", "heap out of memory"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)out\s+of\s+memory`),
+			regexp.MustCompile(`(?i)OOMKilled`),
+			regexp.MustCompile(`(?i)Cannot allocate memory`),
+			regexp.MustCompile(`(?i)JavaScript heap out of memory`),
+			regexp.MustCompile(`(?i)java\.lang\.OutOfMemoryError`),
+		},
+		Confidence: 0.95,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeOutOfMemory,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The process exhausted available memory and was terminated. This can be caused by memory leaks, insufficient resource limits, or processing large datasets.",
+			SuggestedActions: []string{
+				"Increase memory limits for the container/process",
+				"Profile the application for memory leaks",
+				"Implement pagination for large data processing",
+				"Check for unbounded caches or collections",
+				"Review Kubernetes resource limits",
+			},
+			Commands: []domain.Command{
+				{Command: "kubectl top pod <pod>", Description: "Compare the memory usage with the limit"},
+			},
+			PreventionTips: []string{
+				"Set appropriate memory limits based on profiling",
+				"Implement memory monitoring and alerting",
+				"Use streaming for large file processing",
+				"Regular load testing with realistic data volumes",
+			},
+		},
+	}
+}
+
+func diskSpaceFull() *Rule {
+	return &Rule{
+		ID:          "disk_space_full",
+		Category:    CategoryResources,
+		Tags:        []string{"disk"},
+		Name:        "Disk Space Full",
+		Description: "Detects disk space exhaustion",
+		Keywords:    []string{"no space left on device", "disk full", "enospc"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)no space left on device`),
+			regexp.MustCompile(`(?i)ENOSPC`),
+			regexp.MustCompile(`(?i)disk\s+quota\s+exceeded`),
+		},
+		Confidence: 0.95,
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeDiskSpaceFull,
+			Severity:  domain.SeverityHigh,
+			RootCause: "The disk has run out of available space. This prevents writing new data and can cause application crashes or data corruption.",
+			SuggestedActions: []string{
+				"Identify large files: du -sh /* | sort -h",
+				"Clean up Docker resources: docker system prune -a",
+				"Remove old log files and temporary data",
+				"Extend disk size if in cloud environment",
+				"Check for log rotation configuration",
+			},
+			Commands: []domain.Command{
+				{Command: "df -h", Description: "Show the free space per filesystem"},
+				{Command: "docker system prune -a", Description: "Remove unused Docker images, containers and build cache"},
+			},
+			PreventionTips: []string{
+				"Implement disk space monitoring with alerts",
+				"Configure log rotation policies",
+				"Set up automatic cleanup of temporary files",
+				"Use separate volumes for logs and data",
+			},
+		},
+	}
+}
+
+func windowsPathTooLong() *Rule {
+	return &Rule{
+		ID:          "windows_path_too_long",
+		Category:    CategoryResources,
+		Tags:        []string{"windows", "filesystem"},
+		Name:        "Windows Path Too Long",
+		Description: "Detects MAX_PATH failures on Windows runners",
+		Keywords:    []string{"filename too long", "the filename or extension is too long"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)path.*exceeds.*260`),
+		},
+		Confidence:       0.9,
+		RequiredMetadata: map[string]string{"runner_os": "windows"},
+		Result: &domain.AnalysisResult{
+			ErrorType: domain.ErrorTypeWindowsPathTooLong,
+			Severity:  domain.SeverityMedium,
+			RootCause: "A file path exceeded the Windows 260-character MAX_PATH limit. Deeply nested dependency directories (e.g. node_modules) or long checkout paths on Windows runners commonly hit this limit.",
+			SuggestedActions: []string{
+				"Enable long paths for git: git config --system core.longpaths true",
+				"Enable Win32 long paths via the LongPathsEnabled registry setting or group policy",
+				"Shorten the runner work directory or checkout path",
+			},
+			Commands: []domain.Command{
+				{Command: "git config --system core.longpaths true", Description: "Enable long paths for git"},
+			},
+			PreventionTips: []string{
+				"Configure Windows runner images with long path support enabled",
+				"Avoid deeply nested output and dependency directories",
+			},
+		},
+	}
+}
@@ -2,8 +2,10 @@
 package rules
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/ai-devops/internal/domain"
 	"go.uber.org/zap"
 )
 
@@ -78,6 +80,63 @@ func TestRule_Match(t *testing.T) {
 	}
 }
 
+func TestRule_MatchExclusions(t *testing.T) {
+	rule := portAlreadyInUse()
+
+	tests := []struct {
+		name      string
+		log       string
+		wantMatch bool
+	}{
+		{
+			name:      "real bind failure",
+			log:       "listen tcp :8080: bind: address already in use",
+			wantMatch: true,
+		},
+		{
+			name:      "hint quoting the error",
+			log:       "server started on :8080\nHint: if you see 'address already in use', stop the old container",
+			wantMatch: false,
+		},
+		{
+			name:      "note quoting the error",
+			log:       "  Note: EADDRINUSE means another process holds the port",
+			wantMatch: false,
+		},
+		{
+			name:      "real failure next to a hint",
+			log:       "Hint: if you see 'address already in use', stop the old container\nError: listen EADDRINUSE: address already in use :::3000",
+			wantMatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rule.Match(tt.log); got != tt.wantMatch {
+				t.Errorf("Match() = %v, want %v", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestEngine_ExcludeKeywords(t *testing.T) {
+	rule := &Rule{
+		ID:              "flaky_test",
+		Keywords:        []string{"test failed"},
+		ExcludeKeywords: []string{"retrying"},
+		Confidence:      0.9,
+		Result:          &domain.AnalysisResult{ErrorType: "flaky_test"},
+	}
+	engine := NewEngine([]*Rule{rule}, 0.8, zap.NewNop())
+
+	if matches := engine.Analyze("test failed, retrying (1/3)\nall tests passed"); len(matches) != 0 {
+		t.Errorf("excluded line should not match, got %v", matches)
+	}
+	if matches := engine.Analyze("test failed, retrying (1/3)\ntest failed after 3 attempts"); len(matches) != 1 {
+		t.Errorf("unexcluded line should match, got %v", matches)
+	}
+}
+
 func TestEngine_GetBestMatch(t *testing.T) {
 	logger := zap.NewNop()
 	engine := NewEngine(DefaultRules(), 0.8, logger)
@@ -90,3 +149,154 @@ func TestEngine_GetBestMatch(t *testing.T) {
 	// Test with actual log that matches multiple rules
 	// The actual behavior is tested through integration tests
 }
+
+func TestEngine_AnalyzeWithMetadata(t *testing.T) {
+	engine := NewEngine(DefaultRules(), 0.8, zap.NewNop())
+	log := "error: unable to create file src/very/deep/path: Filename too long"
+
+	tests := []struct {
+		name      string
+		meta      *domain.LogMetadata
+		wantMatch bool
+	}{
+		{"no metadata", nil, false},
+		{"linux runner", &domain.LogMetadata{RunnerOS: "linux"}, false},
+		{"windows runner", &domain.LogMetadata{RunnerOS: "Windows"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched := false
+			for _, m := range engine.AnalyzeWithMetadata(log, tt.meta) {
+				if m.RuleID == "windows_path_too_long" {
+					matched = true
+				}
+			}
+			if matched != tt.wantMatch {
+				t.Errorf("windows_path_too_long matched = %v, want %v", matched, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestEngine_SectionRules(t *testing.T) {
+	rule := &Rule{
+		ID:         "npe_in_trace",
+		Keywords:   []string{"NullPointerException"},
+		Section:    domain.ExtractedException,
+		Confidence: 0.9,
+		Result:     &domain.AnalysisResult{ErrorType: "null_pointer"},
+	}
+	engine := NewEngine([]*Rule{rule}, 0.8, zap.NewNop())
+
+	tests := []struct {
+		name      string
+		log       string
+		wantMatch bool
+	}{
+		{
+			name:      "exception of the trace",
+			log:       "java.lang.NullPointerException: user is null\n\tat com.example.A.run(A.java:3)",
+			wantMatch: true,
+		},
+		{
+			name:      "mentioned outside a trace",
+			log:       "INFO retrying after NullPointerException earlier",
+			wantMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := engine.AnalyzeWithMetadata(tt.log, nil)
+			if got := len(matches) > 0; got != tt.wantMatch {
+				t.Errorf("matched = %v, want %v", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestDefaultRules_Valid(t *testing.T) {
+	rules := DefaultRules()
+	if len(rules) < 30 {
+		t.Errorf("expected at least 30 default rules, got %d", len(rules))
+	}
+
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if seen[rule.ID] {
+			t.Errorf("duplicate rule ID %q", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		if rule.Name == "" || rule.Result == nil || rule.Result.RootCause == "" || len(rule.Result.SuggestedActions) == 0 {
+			t.Errorf("rule %q is incomplete", rule.ID)
+		}
+		if !rule.Result.Severity.IsValid() {
+			t.Errorf("rule %q has invalid severity %q", rule.ID, rule.Result.Severity)
+		}
+		if _, ok := domain.LookupErrorType(rule.Result.ErrorType); !ok {
+			t.Errorf("rule %q has error type %q outside the taxonomy", rule.ID, rule.Result.ErrorType)
+		}
+		if !reflect.DeepEqual(domain.NormalizeCommands(rule.Result.Commands), rule.Result.Commands) {
+			t.Errorf("rule %q has commands that are not copy-pasteable: %+v", rule.ID, rule.Result.Commands)
+		}
+		if !reflect.DeepEqual(domain.FilterReferences(rule.Result.References, domain.DefaultReferenceDomains), rule.Result.References) {
+			t.Errorf("rule %q has references outside the default domains: %+v", rule.ID, rule.Result.References)
+		}
+	}
+}
+
+func TestDefaultRules_Library(t *testing.T) {
+	engine := NewEngine(DefaultRules(), 0.8, zap.NewNop())
+
+	tests := []struct {
+		log      string
+		wantRule string
+	}{
+		{"Error response from daemon: manifest for myorg/app:1.4.2 not found: manifest unknown", "docker_image_not_found"},
+		{"toomanyrequests: You have reached your pull rate limit. You may increase the limit by authenticating and upgrading", "docker_pull_rate_limit"},
+		{"error Your lockfile needs to be updated, but yarn was run with `--frozen-lockfile`.", "yarn_install_failure"},
+		{" ERR_PNPM_OUTDATED_LOCKFILE  Cannot install with \"frozen-lockfile\" because pnpm-lock.yaml is not up to date", "pnpm_install_failure"},
+		{"[ERROR] Failed to execute goal on project api: Could not resolve dependencies for project com.acme:api:jar:1.0: Could not find artifact com.acme:core:jar:2.1", "maven_dependency_resolution"},
+		{"> Could not resolve all files for configuration ':app:compileClasspath'.\n   > Could not find com.acme:core:2.1.", "gradle_dependency_resolution"},
+		{"ERROR: Could not find a version that satisfies the requirement torch==9.9 (from versions: 2.1.0)\nERROR: No matching distribution found for torch==9.9", "pip_install_failure"},
+		{"SolverProblemError\n\nBecause no versions of requests match >3.0 and app depends on requests (>3.0), version solving failed.", "poetry_dependency_resolution"},
+		{"verifying github.com/acme/lib@v1.2.0: checksum mismatch\n\tdownloaded: h1:abc\n\tgo.sum:     h1:def\n\nSECURITY ERROR\nThis download does NOT match an earlier download recorded in go.sum.", "go_module_checksum_mismatch"},
+		{"error[E0308]: mismatched types\n --> src/main.rs:4:18\nerror: could not compile `app` (bin \"app\") due to 1 previous error", "cargo_build_failure"},
+		{"dial tcp: lookup db.internal on 10.0.0.2:53: no such host", "dns_resolution_failure"},
+		{"Get \"https://registry.example.com/v2/\": net/http: TLS handshake timeout", "proxy_tls_handshake_failure"},
+		{"git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.", "git_authentication_failure"},
+		{"##[error]Resource not accessible by integration - https://docs.github.com/rest/issues/comments#create-an-issue-comment", "ci_token_permission"},
+		{"remote: Permission to acme/app.git denied to github-actions[bot].\nfatal: unable to access 'https://github.com/acme/app/': The requested URL returned error: 403", "ci_token_permission"},
+		{"Warning  BackOff  kubelet  Back-off restarting failed container api in pod api-7d9f (CrashLoopBackOff)", "k8s_crash_loop_backoff"},
+		{"Warning  Unhealthy  kubelet  Liveness probe failed: HTTP probe failed with statuscode: 503", "k8s_probe_failure"},
+		{"Warning  FailedScheduling  default-scheduler  0/3 nodes are available: 3 Insufficient cpu.", "k8s_pod_unschedulable"},
+		{"Container api (image acme/api:1.4.2): restarts 6\n  Limits: cpu=500m, memory=256Mi; Requests: memory=128Mi\n  Last State: Terminated, Reason: OOMKilled, Exit Code: 137", "k8s_oom_killed"},
+		{"Warning  FailedScheduling  pod/db-0  0/3 nodes are available: pod has unbound immediate PersistentVolumeClaims. preemption: 0/3 nodes are available", "k8s_pvc_pending"},
+		{"Warning  FailedMount  pod/db-0  Unable to attach or mount volumes: unmounted volumes=[data]: timed out waiting for the condition", "k8s_pvc_pending"},
+		{"Error: UPGRADE FAILED: another operation (install/upgrade/rollback) is in progress", "helm_release_failed"},
+		{"Error: Error acquiring the state lock\n\nError message: ConditionalCheckFailedException: The conditional request failed", "terraform_state_lock"},
+		{"Error: Failed to query available provider packages\n\nCould not retrieve the list of available versions for provider hashicorp/aws", "terraform_provider_install"},
+		{"│ Error: Unsupported argument\n│\n│   on main.tf line 4, in resource \"aws_s3_bucket\" \"logs\":\n│ An argument named \"acl_policy\" is not expected here.", "terraform_plan_failure"},
+		{"│ Error: creating EC2 Instance: InstanceLimitExceeded: You have requested more instances (21) than your current instance limit\n│\n│   with aws_instance.web,", "terraform_apply_failure"},
+		{"error: the stack is currently locked by 1 lock(s). Either wait for the other process(es) to end or delete the lock file with `pulumi cancel`.", "pulumi_stack_lock"},
+		{"Diagnostics:\n  pulumi:pulumi:Stack (infra-dev):\n    error: update failed", "pulumi_update_failure"},
+		{"An error occurred (ValidationError) when calling the UpdateStack operation: Stack:arn:aws:cloudformation:us-east-1:123:stack/api is in UPDATE_ROLLBACK_FAILED state and can not be updated.", "cloudformation_stack_failure"},
+		{"Error: No valid credential sources found\n\n  with provider[\"registry.terraform.io/hashicorp/aws\"],", "iac_provider_auth"},
+		{"Note: Objects have changed outside of Terraform\n\n  # aws_security_group.api has changed", "iac_drift"},
+		{"error: Dirty database version 12. Fix and force version.", "database_migration_failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantRule, func(t *testing.T) {
+			best := engine.GetBestMatch(engine.Analyze(tt.log))
+			if best == nil {
+				t.Fatalf("no rule matched %q", tt.log)
+			}
+			if best.RuleID != tt.wantRule {
+				t.Errorf("best match = %s, want %s", best.RuleID, tt.wantRule)
+			}
+		})
+	}
+}
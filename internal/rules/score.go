@@ -0,0 +1,126 @@
+// Package rules provides rule-based log pre-classification.
+package rules
+
+import (
+	"sort"
+	"strings"
+)
+
+// Proximity boost: when two signals of a rule occur within proximityLines
+// lines of each other, proximityBoost of the remaining doubt is removed.
+const (
+	proximityLines = 3
+	proximityBoost = 0.5
+)
+
+// maxSignalConfidence caps the evidence of a single signal so that weights
+// above 1 cannot produce certainty on their own.
+const maxSignalConfidence = 0.99
+
+// signalHit is where one keyword or pattern of a rule occurred in a log.
+type signalHit struct {
+	signal     string
+	isPattern  bool
+	start, end int
+	line       int
+	weight     float64
+}
+
+// Score computes the rule's confidence for log, or 0 if it does not match.
+// Each keyword and pattern that occurs is independent evidence with
+// probability Confidence × its weight (see Weights), combined as
+// 1 - Π(1 - p). A single full-weight signal therefore scores exactly
+// Confidence; more signals, especially close together, score higher and
+// low-weight signals lower. Overlapping hits (a keyword and a pattern
+// matching the same text) count once. Excluded lines are ignored.
+func (r *Rule) Score(log string) float64 {
+	log = r.withoutExcludedLines(log)
+	hits := r.signalHits(log)
+	if len(hits) == 0 {
+		return 0
+	}
+
+	doubt := 1.0
+	for _, hit := range hits {
+		p := r.Confidence * hit.weight
+		if p > maxSignalConfidence {
+			p = maxSignalConfidence
+		}
+		doubt *= 1 - p
+	}
+	if clustered(hits) {
+		doubt *= 1 - proximityBoost
+	}
+	return 1 - doubt
+}
+
+// weight returns the weight of a keyword or pattern source.
+func (r *Rule) weight(signal string) float64 {
+	if w, ok := r.Weights[signal]; ok {
+		return w
+	}
+	return 1
+}
+
+// signalHits finds the first occurrence of each keyword and pattern and
+// drops hits that overlap a heavier one.
+func (r *Rule) signalHits(log string) []signalHit {
+	hits := r.allSignalHits(log)
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].weight > hits[j].weight })
+	kept := hits[:0]
+	for _, hit := range hits {
+		if hit.weight <= 0 || overlapsAny(hit, kept) {
+			continue
+		}
+		kept = append(kept, hit)
+	}
+	return kept
+}
+
+// allSignalHits finds the first occurrence of each keyword and pattern.
+func (r *Rule) allSignalHits(log string) []signalHit {
+	logLower := strings.ToLower(log)
+
+	var hits []signalHit
+	for _, kw := range r.Keywords {
+		if idx := strings.Index(logLower, strings.ToLower(kw)); idx >= 0 && kw != "" {
+			line := strings.Count(logLower[:idx], "\n")
+			hits = append(hits, signalHit{signal: kw, start: idx, end: idx + len(kw), line: line, weight: r.weight(kw)})
+		}
+	}
+	for _, pattern := range r.Patterns {
+		if loc := pattern.FindStringIndex(log); loc != nil {
+			line := strings.Count(log[:loc[0]], "\n")
+			hits = append(hits, signalHit{
+				signal:    pattern.String(),
+				isPattern: true,
+				start:     loc[0],
+				end:       loc[1],
+				line:      line,
+				weight:    r.weight(pattern.String()),
+			})
+		}
+	}
+	return hits
+}
+
+func overlapsAny(hit signalHit, others []signalHit) bool {
+	for _, other := range others {
+		if hit.start < other.end && other.start < hit.end {
+			return true
+		}
+	}
+	return false
+}
+
+// clustered reports whether any two hits are within proximityLines lines.
+func clustered(hits []signalHit) bool {
+	for i := range hits {
+		for j := i + 1; j < len(hits); j++ {
+			if d := hits[i].line - hits[j].line; d <= proximityLines && d >= -proximityLines {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+// Package rules provides unit tests for weighted rule scoring.
+package rules
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRule_Score(t *testing.T) {
+	rule := &Rule{
+		ID:         "oom",
+		Keywords:   []string{"out of memory", "killed"},
+		Patterns:   []*regexp.Regexp{regexp.MustCompile(`(?i)out of memory`), regexp.MustCompile(`exit code 137`)},
+		Weights:    map[string]float64{"killed": 0.5},
+		Confidence: 0.8,
+	}
+	far := strings.Repeat("compiling\n", 10)
+
+	tests := []struct {
+		name string
+		log  string
+		want float64
+	}{
+		{"no signal", "build succeeded", 0},
+		{"single signal scores Confidence", "fatal: out of memory", 0.8},
+		{"keyword and pattern on the same text count once", "OUT OF MEMORY", 0.8},
+		{"low-weight signal", "process killed", 0.4},
+		{"two signals far apart", "out of memory\n" + far + "exit code 137", 1 - 0.2*0.2},
+		{"two signals close together", "out of memory\nexit code 137", 1 - 0.2*0.2*(1-proximityBoost)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rule.Score(tt.log); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRule_ScoreCapsHeavySignals(t *testing.T) {
+	rule := &Rule{
+		Keywords:   []string{"segfault"},
+		Weights:    map[string]float64{"segfault": 3},
+		Confidence: 0.9,
+	}
+	if got := rule.Score("segfault"); got != maxSignalConfidence {
+		t.Errorf("Score() = %v, want %v", got, maxSignalConfidence)
+	}
+}
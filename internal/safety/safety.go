@@ -0,0 +1,218 @@
+// Package safety classifies remediation steps as read-only, modifying or
+// destructive so callers can review or withhold dangerous suggestions.
+package safety
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// destructivePatterns match commands that delete data or resources in a way
+// that cannot be undone by rerunning the pipeline.
+var destructivePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\brm\s+(-[a-zA-Z]*[rf][a-zA-Z]*\s+)+`),
+	regexp.MustCompile(`\bdocker\s+(system|volume|image|container|network|builder)\s+prune\b`),
+	regexp.MustCompile(`\bdocker\s+(rm|rmi|volume\s+rm)\b`),
+	regexp.MustCompile(`\bkubectl\s+(delete|drain)\b`),
+	regexp.MustCompile(`\bkubectl\s+replace\s+.*--force\b`),
+	regexp.MustCompile(`\bhelm\s+(uninstall|delete)\b`),
+	regexp.MustCompile(`\bterraform\s+(destroy|state\s+rm)\b`),
+	regexp.MustCompile(`\bterraform\s+apply\b.*-(replace|destroy)\b`),
+	regexp.MustCompile(`\bgit\s+push\b.*(--force\b|\s-f\b|--delete\b)`),
+	regexp.MustCompile(`\bgit\s+(reset\s+--hard|clean\s+-[a-zA-Z]*f)`),
+	regexp.MustCompile(`(?i)\b(drop\s+(table|database|schema)|truncate\s+table|delete\s+from)\b`),
+	regexp.MustCompile(`\bmkfs(\.\w+)?\b`),
+	regexp.MustCompile(`\bdd\s+.*\bof=`),
+	regexp.MustCompile(`(?i)\bflush(all|db)\b`),
+	regexp.MustCompile(`\b(aws\s+\S+\s+(delete|terminate|remove)-|gcloud\s+.*\bdelete\b|az\s+.*\bdelete\b)`),
+	regexp.MustCompile(`\bnpm\s+cache\s+clean\b.*--force\b`),
+	regexp.MustCompile(`\bfind\b.*\s-delete\b`),
+}
+
+// writeMarkers turn an otherwise read-only command into a modifying one:
+// output redirection and HTTP requests that change state.
+var writeMarkers = regexp.MustCompile(`(^|[^0-9&])>|\s-X\s*(POST|PUT|PATCH|DELETE)\b|\s(-d|--data\S*|--request)\s`)
+
+// readOnlyCommands are command prefixes that only inspect state.
+var readOnlyCommands = []string{
+	"cat", "less", "head", "tail", "grep", "ls", "find", "df", "du", "free",
+	"ps", "top", "env", "printenv", "which", "whoami", "id", "uname", "lsof",
+	"netstat", "ss", "dig", "nslookup", "host", "ping", "traceroute", "curl",
+	"wget --spider", "openssl", "echo", "stat", "file", "journalctl",
+	"systemctl status", "node --version", "node -v", "npm ls", "npm view",
+	"npm config get", "npm --version", "go version", "go env", "go list",
+	"python --version", "python3 --version", "pip show", "pip list",
+	"java -version", "mvn dependency:tree", "rustc --explain", "cargo tree",
+	"git status", "git log", "git diff", "git show", "git ls-remote",
+	"git remote -v", "git branch",
+	"docker ps", "docker logs", "docker inspect", "docker images",
+	"docker info", "docker version", "docker manifest inspect", "docker stats",
+	"docker system df", "docker history",
+	"kubectl get", "kubectl describe", "kubectl logs", "kubectl top",
+	"kubectl explain", "kubectl auth can-i", "kubectl version",
+	"kubectl config view", "kubectl config get-contexts", "kubectl events",
+	"kubectl rollout status", "kubectl rollout history",
+	"helm history", "helm status", "helm list", "helm ls", "helm template",
+	"helm get", "helm lint", "helm show",
+	"terraform plan", "terraform validate", "terraform show", "terraform output",
+	"terraform state list", "terraform state show", "terraform version",
+	"terraform providers", "terraform fmt -check",
+	"aws sts get-caller-identity",
+}
+
+// readOnlyVerbs start prose actions that only inspect state.
+var readOnlyVerbs = []string{
+	"check", "verify", "inspect", "review", "confirm", "compare", "look",
+	"search", "list", "view", "examine", "investigate", "monitor", "identify",
+}
+
+// inlineCode matches `code` spans in prose actions.
+var inlineCode = regexp.MustCompile("`([^`]+)`")
+
+// placeholder matches <placeholders> for values the log does not reveal,
+// which would otherwise read as redirections.
+var placeholder = regexp.MustCompile(`<[\w.:/-]+>`)
+
+// segmentSeparators split a command line into the commands it runs.
+var segmentSeparators = regexp.MustCompile(`\|\||&&|[|;]`)
+
+// Classify returns the safety of a shell command line. Pipelines and
+// command lists are as dangerous as their most dangerous command; commands
+// that are neither known to be read-only nor destructive are modifying.
+func Classify(command string) domain.Safety {
+	if isDestructive(command) {
+		return domain.SafetyDestructive
+	}
+	segments := segmentSeparators.Split(placeholder.ReplaceAllString(command, "_"), -1)
+	for _, segment := range segments {
+		if !isReadOnly(segment) {
+			return domain.SafetyModifying
+		}
+	}
+	return domain.SafetyReadOnly
+}
+
+// ClassifyAction returns the safety of a prose suggested action. Commands
+// quoted in backticks are classified as commands; otherwise the action is
+// destructive if it spells out a destructive command and read-only if it
+// starts with an inspecting verb such as "Check" or "Verify".
+func ClassifyAction(action string) domain.Safety {
+	if isDestructive(action) {
+		return domain.SafetyDestructive
+	}
+
+	safety := domain.SafetyReadOnly
+	spans := inlineCode.FindAllStringSubmatch(action, -1)
+	for _, span := range spans {
+		if Classify(span[1]) != domain.SafetyReadOnly {
+			safety = domain.SafetyModifying
+		}
+	}
+	if safety == domain.SafetyReadOnly && !startsWithAny(strings.ToLower(strings.TrimSpace(action)), readOnlyVerbs) {
+		safety = domain.SafetyModifying
+	}
+	return safety
+}
+
+// Annotate returns a copy of result with every command and suggested action
+// classified. result itself may be a shared rule or cached result and is
+// never modified.
+func Annotate(result *domain.AnalysisResult) *domain.AnalysisResult {
+	if result == nil {
+		return nil
+	}
+	annotated := *result
+	if result.Commands != nil {
+		annotated.Commands = make([]domain.Command, len(result.Commands))
+		for i, c := range result.Commands {
+			c.Safety = Classify(c.Command)
+			annotated.Commands[i] = c
+		}
+	}
+	annotated.ActionSafety = nil
+	if len(result.SuggestedActions) > 0 {
+		annotated.ActionSafety = make([]domain.Safety, len(result.SuggestedActions))
+		for i, action := range result.SuggestedActions {
+			annotated.ActionSafety[i] = ClassifyAction(action)
+		}
+	}
+	return &annotated
+}
+
+// WithholdDestructive removes destructive commands and suggested actions
+// from an annotated result in place and returns how many were removed.
+func WithholdDestructive(result *domain.AnalysisResult) int {
+	if result == nil {
+		return 0
+	}
+	withheld := 0
+
+	commands := make([]domain.Command, 0, len(result.Commands))
+	for _, c := range result.Commands {
+		if c.Safety == domain.SafetyDestructive {
+			withheld++
+			continue
+		}
+		commands = append(commands, c)
+	}
+	if result.Commands != nil {
+		result.Commands = commands
+	}
+
+	if len(result.ActionSafety) == len(result.SuggestedActions) {
+		actions := make([]string, 0, len(result.SuggestedActions))
+		safeties := make([]domain.Safety, 0, len(result.ActionSafety))
+		for i, action := range result.SuggestedActions {
+			if result.ActionSafety[i] == domain.SafetyDestructive {
+				withheld++
+				continue
+			}
+			actions = append(actions, action)
+			safeties = append(safeties, result.ActionSafety[i])
+		}
+		result.SuggestedActions = actions
+		result.ActionSafety = safeties
+	}
+	return withheld
+}
+
+func isDestructive(text string) bool {
+	for _, pattern := range destructivePatterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func isReadOnly(segment string) bool {
+	segment = strings.TrimSpace(segment)
+	segment = strings.TrimPrefix(segment, "sudo ")
+	if segment == "" {
+		return true
+	}
+	if writeMarkers.MatchString(segment) {
+		return false
+	}
+	return startsWithAny(segment, readOnlyCommands)
+}
+
+// startsWithAny reports whether s starts with one of prefixes as a whole
+// word.
+func startsWithAny(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(s, prefix) {
+			continue
+		}
+		if len(s) == len(prefix) || !isWordByte(s[len(prefix)]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b == '-' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
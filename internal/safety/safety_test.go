@@ -0,0 +1,101 @@
+// Package safety provides unit tests for remediation safety classification.
+package safety
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		command string
+		want    domain.Safety
+	}{
+		{"kubectl get pods -n prod", domain.SafetyReadOnly},
+		{"kubectl top pod <pod> -n <namespace>", domain.SafetyReadOnly},
+		{"kubectl logs deploy/api --previous | grep -i error", domain.SafetyReadOnly},
+		{"docker logs api 2>&1 | tail -n 50", domain.SafetyReadOnly},
+		{"sudo lsof -i :8080", domain.SafetyReadOnly},
+		{"terraform plan -out=tfplan", domain.SafetyReadOnly},
+		{"curl -v https://registry.npmjs.org/", domain.SafetyReadOnly},
+		{"curl -X DELETE https://api.example.com/v1/cache", domain.SafetyModifying},
+		{"echo 'nameserver 8.8.8.8' > /etc/resolv.conf", domain.SafetyModifying},
+		{"kubectl rollout restart deploy/api", domain.SafetyModifying},
+		{"npm ci", domain.SafetyModifying},
+		{"df -h && npm install", domain.SafetyModifying},
+		{"kubectl delete pod api-0", domain.SafetyDestructive},
+		{"docker system prune -a", domain.SafetyDestructive},
+		{"docker volume prune -f", domain.SafetyDestructive},
+		{"docker rmi myimage:latest", domain.SafetyDestructive},
+		{"rm -rf node_modules && npm install", domain.SafetyDestructive},
+		{"terraform destroy -target=aws_instance.web", domain.SafetyDestructive},
+		{"terraform apply -replace=aws_instance.web", domain.SafetyDestructive},
+		{"git push --force origin main", domain.SafetyDestructive},
+		{"git reset --hard origin/main", domain.SafetyDestructive},
+		{"helm uninstall api", domain.SafetyDestructive},
+		{`psql -c "DROP TABLE users"`, domain.SafetyDestructive},
+		{"redis-cli FLUSHALL", domain.SafetyDestructive},
+		{"find /tmp -name '*.log' -delete", domain.SafetyDestructive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := Classify(tt.command); got != tt.want {
+				t.Errorf("Classify(%q) = %s, want %s", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyAction(t *testing.T) {
+	tests := []struct {
+		action string
+		want   domain.Safety
+	}{
+		{"Check the pod events with `kubectl describe pod api-0`", domain.SafetyReadOnly},
+		{"Verify the registry URL in .npmrc", domain.SafetyReadOnly},
+		{"Check disk usage and run `docker builder prune` if needed", domain.SafetyDestructive},
+		{"Increase the container memory limit", domain.SafetyModifying},
+		{"Restart the deployment with `kubectl rollout restart deploy/api`", domain.SafetyModifying},
+		{"Delete the stuck pod with kubectl delete pod api-0", domain.SafetyDestructive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			if got := ClassifyAction(tt.action); got != tt.want {
+				t.Errorf("ClassifyAction(%q) = %s, want %s", tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateAndWithhold(t *testing.T) {
+	shared := &domain.AnalysisResult{
+		SuggestedActions: []string{"Run `docker system prune -a`", "Check `docker ps -a`"},
+		Commands:         []domain.Command{{Command: "kubectl delete pod api-0"}, {Command: "kubectl get pods"}},
+	}
+
+	annotated := Annotate(shared)
+	if shared.ActionSafety != nil || shared.Commands[0].Safety != "" {
+		t.Fatal("Annotate() modified its argument")
+	}
+	wantActions := []domain.Safety{domain.SafetyDestructive, domain.SafetyReadOnly}
+	if !reflect.DeepEqual(annotated.ActionSafety, wantActions) {
+		t.Errorf("ActionSafety = %v, want %v", annotated.ActionSafety, wantActions)
+	}
+
+	if got := WithholdDestructive(annotated); got != 2 {
+		t.Errorf("WithholdDestructive() = %d, want 2", got)
+	}
+	if !reflect.DeepEqual(annotated.SuggestedActions, []string{"Check `docker ps -a`"}) {
+		t.Errorf("SuggestedActions = %v", annotated.SuggestedActions)
+	}
+	if len(annotated.Commands) != 1 || annotated.Commands[0].Command != "kubectl get pods" {
+		t.Errorf("Commands = %+v", annotated.Commands)
+	}
+	if len(shared.SuggestedActions) != 2 || len(shared.Commands) != 2 {
+		t.Error("WithholdDestructive() modified the shared result")
+	}
+}
@@ -3,149 +3,1027 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/cache"
+	"github.com/ai-devops/internal/capture"
+	"github.com/ai-devops/internal/classifier"
 	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/escalate"
+	"github.com/ai-devops/internal/experiment"
+	"github.com/ai-devops/internal/extract"
+	"github.com/ai-devops/internal/fewshot"
+	"github.com/ai-devops/internal/knowledge"
+	"github.com/ai-devops/internal/logger"
+	"github.com/ai-devops/internal/notify"
+	"github.com/ai-devops/internal/policy"
 	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/internal/store"
+	"github.com/ai-devops/internal/tickets"
+	"github.com/ai-devops/internal/usage"
+	"github.com/ai-devops/internal/vectorindex"
 	"github.com/ai-devops/pkg/sanitizer"
 	"go.uber.org/zap"
 )
 
+// maxLogSections bounds the number of named log sections per request.
+const maxLogSections = 10
+
+// notifyTimeout bounds a background notification delivery.
+const notifyTimeout = 15 * time.Second
+
+// errRulesOnly reports a log no rule matched when the AI is disabled.
+var errRulesOnly = fmt.Errorf("%w: no rule matched and AI analysis is disabled", domain.ErrAIUnavailable)
+
 // Analyzer orchestrates the log analysis pipeline.
 type Analyzer struct {
 	aiClient    ai.Client
 	ruleEngine  *rules.Engine
 	sanitizer   *sanitizer.Sanitizer
 	enableRules bool
+	hybridMerge bool
+	rulesOnly   bool
+	bestEffort  bool
+	maxTimeout  time.Duration
+	reversible  bool
+	rejectKeys  bool
+	compactLogs bool
+	routePrompt bool
 	logger      *zap.Logger
+
+	blockDestructive bool
+	shadowSampleRate float64
+	thresholdCtl     *rules.AdaptiveController
+	meter            *usage.Meter
+	tenantMeter      *usage.TenantMeter
+	capture          *capture.Recorder
+	activity         *ai.Activity
+	cache            cache.Cache
+	limiter          *ConcurrencyLimiter
+	store            store.Store
+	notifier         *notify.Notifier
+	tickets          *tickets.Filer
+	escalator        *escalate.Escalator
+	classifier       *classifier.Stage
+	defaultLanguage  string
+	promptVersion    string
+	experiment       *experiment.Experiment
+	contextFetcher   ContextFetcher
+	severityPolicy   *policy.SeverityPolicy
+	examples         *fewshot.Store
+	exampleCount     int
+	exampleMinScore  float64
+	embedder         ai.Embedder
+	incidents        *vectorindex.Index
+	incidentCount    int
+	incidentMinScore float64
+	incidentLinkBase string
+	knowledge        *knowledge.Finder
+	tenants          map[string]TenantPolicy
+	stages           []Stage
+	postProcessors   []PostProcessor
 }
 
 // AnalyzerConfig contains configuration for the Analyzer.
 type AnalyzerConfig struct {
 	EnableRules bool
+
+	// HybridMerge sends confident rule matches to the AI too and merges the
+	// results: error_type and severity from the rule, root cause and actions
+	// from the AI.
+	HybridMerge bool
+
+	// RulesOnly never calls the AI: logs without a confident rule match get
+	// the top match regardless of the threshold, as when the token budget is
+	// exhausted.
+	RulesOnly bool
+
+	// MaxRequestTimeout caps AnalysisRequest.TimeoutMS. Zero leaves it
+	// uncapped.
+	MaxRequestTimeout time.Duration
+
+	// BestEffort answers with a generic heuristic result instead of an error
+	// when no rule matches and the AI is disabled or fails.
+	BestEffort bool
+
+	// ReversibleSanitize masks secrets with placeholders and restores them in
+	// the returned result. Stored and notified results keep the placeholders.
+	ReversibleSanitize bool
+
+	// RejectPrivateKeys fails analyses of logs containing a private key
+	// with SENSITIVE_DATA instead of sending the masked log upstream.
+	RejectPrivateKeys bool
+
+	// CompactLogs collapses repeated lines, drops verbose lines away from
+	// errors and summarizes long stack traces before the log goes to the AI.
+	CompactLogs bool
+
+	// PromptRouting asks the AI client for the system prompt specialized
+	// for the domain of the strongest rule match (see ai.RoutePromptDomain).
+	PromptRouting bool
+
+	// BlockDestructive removes suggested actions and commands classified as
+	// destructive (see safety.Classify) from results instead of only
+	// marking them.
+	BlockDestructive bool
+
+	// ShadowSampleRate is the fraction (0.0-1.0) of rule-based results that are
+	// also evaluated by the AI in the background to measure agreement.
+	ShadowSampleRate float64
+
+	// ThresholdController, if set, receives shadow evaluation outcomes and
+	// adapts the rule confidence threshold.
+	ThresholdController *rules.AdaptiveController
+
+	// Meter, if set, records AI token usage and cost. When its budget is
+	// exhausted the analyzer degrades to rules-only results.
+	Meter *usage.Meter
+
+	// TenantMeter, if set, records analyses and token spend per tenant. A
+	// tenant over its quota is blocked or degraded to rules-only results.
+	TenantMeter *usage.TenantMeter
+
+	// Capture, if set, keeps sampled AI prompts and raw responses, and
+	// those of rejected responses, for debugging.
+	Capture *capture.Recorder
+
+	// Activity, if set, records successful AI calls for health reporting.
+	Activity *ai.Activity
+
+	// Cache, if set, stores AI results by log fingerprint so repeated
+	// failures do not trigger repeated AI calls.
+	Cache cache.Cache
+
+	// Limiter, if set, bounds concurrent upstream AI requests.
+	Limiter *ConcurrencyLimiter
+
+	// Store, if set, persists successful analyses for history and feedback.
+	Store store.Store
+
+	// Notifier, if set, sends deduplicated notifications for analyses.
+	Notifier *notify.Notifier
+
+	// Tickets, if set, opens or updates issue tracker tickets for analyses
+	// (High severity by default, or as requested per request).
+	Tickets *tickets.Filer
+
+	// Escalator, if set, pages on-call (PagerDuty, Opsgenie) for analyses
+	// matching its conditions.
+	Escalator *escalate.Escalator
+
+	// Classifier, if set, predicts the error type between the rules and the
+	// AI; confident predictions skip the AI or hint the prompt.
+	Classifier *classifier.Stage
+
+	// DefaultLanguage is the output language when the request sets none.
+	DefaultLanguage string
+
+	// PromptVersion identifies the AI prompt (see ai.PromptVersion). It is
+	// part of the cache key and recorded with AI results so they can be
+	// invalidated when the prompt changes.
+	PromptVersion string
+
+	// Experiment, if set, splits AI calls between A/B variants. Each
+	// variant's results are cached separately and tagged with its name.
+	Experiment *experiment.Experiment
+
+	// ContextFetcher, if set, fetches the surrounding log lines selected by
+	// a request's Context and adds them to the analyzed log.
+	ContextFetcher ContextFetcher
+
+	// SeverityPolicy, if set, overrides result severities according to
+	// operator-defined conditions.
+	SeverityPolicy *policy.SeverityPolicy
+
+	// Examples, if set, supplies worked examples: up to ExampleCount stored
+	// examples at least ExampleMinSimilarity similar to the log are shown to
+	// the AI before it.
+	Examples             *fewshot.Store
+	ExampleCount         int
+	ExampleMinSimilarity float64
+
+	// Embedder and Incidents, if set along with Store, enable similar-incident
+	// retrieval: each stored analysis is indexed by the embedding of its log,
+	// and responses list up to SimilarIncidentCount past analyses at least
+	// SimilarIncidentMinSimilarity similar, linked under IncidentLinkBase.
+	Embedder                     ai.Embedder
+	Incidents                    *vectorindex.Index
+	SimilarIncidentCount         int
+	SimilarIncidentMinSimilarity float64
+	IncidentLinkBase             string
+
+	// Knowledge, if set, links results to organization runbooks matching
+	// their error type and tags.
+	Knowledge *knowledge.Finder
+
+	// Tenants overrides the AI client, rules and sanitizer by tenant ID
+	// (see domain.TenantFromContext).
+	Tenants map[string]TenantPolicy
+
+	// CustomStages are inserted into the analysis pipeline after the
+	// built-in stages they name. NewAnalyzer fails if one follows an
+	// unknown stage; see ValidateCustomStages.
+	CustomStages []CustomStage
+
+	// PostProcessors are custom post-processors, run after the response
+	// is shaped for the client and before it is returned.
+	PostProcessors []PostProcessor
+
+	// PostProcessOrder names the built-in (see DefaultPostProcessOrder) and
+	// custom post-processors in the order they run; built-ins it leaves
+	// out are skipped. Empty runs the built-ins in their default order,
+	// then PostProcessors. NewAnalyzer fails on unknown or repeated
+	// names; see ValidatePostProcessors.
+	PostProcessOrder []string
 }
 
-// NewAnalyzer creates a new Analyzer with all dependencies.
+// NewAnalyzer creates a new Analyzer with all dependencies. It fails when a
+// custom stage follows an unknown stage or PostProcessOrder names unknown
+// or repeated post-processors.
 func NewAnalyzer(
 	aiClient ai.Client,
 	ruleEngine *rules.Engine,
 	sanitizer *sanitizer.Sanitizer,
 	config AnalyzerConfig,
 	logger *zap.Logger,
-) *Analyzer {
-	return &Analyzer{
+) (*Analyzer, error) {
+	a := &Analyzer{
 		aiClient:    aiClient,
 		ruleEngine:  ruleEngine,
 		sanitizer:   sanitizer,
 		enableRules: config.EnableRules,
+		hybridMerge: config.HybridMerge,
+		rulesOnly:   config.RulesOnly,
+		bestEffort:  config.BestEffort,
+		maxTimeout:  config.MaxRequestTimeout,
+		reversible:  config.ReversibleSanitize,
+		rejectKeys:  config.RejectPrivateKeys,
+		compactLogs: config.CompactLogs,
+		routePrompt: config.PromptRouting,
 		logger:      logger.Named("analyzer"),
+
+		blockDestructive: config.BlockDestructive,
+		shadowSampleRate: config.ShadowSampleRate,
+		thresholdCtl:     config.ThresholdController,
+		meter:            config.Meter,
+		tenantMeter:      config.TenantMeter,
+		capture:          config.Capture,
+		activity:         config.Activity,
+		cache:            config.Cache,
+		limiter:          config.Limiter,
+		store:            config.Store,
+		notifier:         config.Notifier,
+		tickets:          config.Tickets,
+		escalator:        config.Escalator,
+		classifier:       config.Classifier,
+		defaultLanguage:  config.DefaultLanguage,
+		promptVersion:    config.PromptVersion,
+		experiment:       config.Experiment,
+		contextFetcher:   config.ContextFetcher,
+		severityPolicy:   config.SeverityPolicy,
+		examples:         config.Examples,
+		exampleCount:     config.ExampleCount,
+		exampleMinScore:  config.ExampleMinSimilarity,
+		embedder:         config.Embedder,
+		incidents:        config.Incidents,
+		incidentCount:    config.SimilarIncidentCount,
+		incidentMinScore: config.SimilarIncidentMinSimilarity,
+		incidentLinkBase: config.IncidentLinkBase,
+		knowledge:        config.Knowledge,
+		tenants:          config.Tenants,
 	}
+	stages, err := a.newPipeline(config.CustomStages)
+	if err != nil {
+		return nil, err
+	}
+	postProcessors, err := a.newPostProcessors(config.PostProcessOrder, config.PostProcessors)
+	if err != nil {
+		return nil, err
+	}
+	a.stages, a.postProcessors = stages, postProcessors
+	return a, nil
 }
 
-// Analyze processes a log through the analysis pipeline:
-// 1. Sanitize input
-// 2. Apply rule-based analysis
-// 3. If no high-confidence rule match, use AI
-// 4. Validate and return result
+// Analyze validates the request and runs it through the analysis pipeline:
+// sanitize, classify, enrich, analyze and post_process, with any custom
+// stages (see Stages).
 func (a *Analyzer) Analyze(ctx context.Context, req *domain.AnalysisRequest) (*domain.AnalysisResponse, error) {
 	startTime := time.Now()
-	a.logger.Debug("starting analysis", zap.Int("log_length", len(req.Log)))
 
-	// Step 1: Validate input
-	if a.sanitizer.IsEmpty(req.Log) {
+	var exp *explainer
+	if req.Explain {
+		ctx, exp = withExplainer(ctx)
+	}
+
+	// Step 1: Validate input and add context from the log store
+	ctx, cancel, err := withTimeout(ctx, req.TimeoutMS, a.maxTimeout)
+	defer cancel()
+	var contextLines int
+	if err == nil {
+		req, contextLines, err = a.withContext(ctx, req)
+	}
+	var log string
+	if err == nil {
+		log, err = requestLog(req)
+	}
+	if err == nil {
+		ctx, err = withLanguage(ctx, req.Language, a.defaultLanguage)
+	}
+	if err == nil {
+		ctx, err = withDetail(ctx, req.Detail)
+	}
+	if err == nil {
+		err = validateMode(req.Mode)
+	}
+	if err != nil {
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInvalidRequest, err.Error()),
+			ProcessedAt: time.Now(),
+		}, nil
+	}
+	a.loggerFor(ctx).Debug("starting analysis",
+		zap.Int("log_length", len(log)),
+		zap.Int("sections", len(req.Sections)),
+	)
+
+	logSanitizer := a.sanitizerFor(ctx)
+	if logSanitizer.IsEmpty(log) {
 		return &domain.AnalysisResponse{
 			Success:     false,
-			Error:       domain.ErrEmptyLog.Error(),
+			Error:       domain.ErrorDetailFor(domain.ErrEmptyLog),
 			ProcessedAt: time.Now(),
 		}, nil
 	}
 
-	if a.sanitizer.IsTooLarge(req.Log) {
-		a.logger.Warn("log too large, will be truncated",
-			zap.Int("original_size", len(req.Log)),
+	if resp := a.checkTenantQuota(ctx); resp != nil {
+		return resp, nil
+	}
+
+	if logSanitizer.IsTooLarge(log) {
+		a.loggerFor(ctx).Warn("log too large, will be truncated",
+			zap.Int("original_size", len(log)),
 		)
 	}
 
-	// Step 2: Sanitize the log
-	sanitizedLog, stats := a.sanitizer.SanitizeWithStats(req.Log)
-	a.logger.Debug("log sanitized",
+	analysis := &Analysis{
+		Request:      req,
+		raw:          log,
+		started:      startTime,
+		contextLines: contextLines,
+	}
+	if err := a.runPipeline(ctx, analysis); err != nil {
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.ErrorDetailFor(err),
+			ProcessedAt: time.Now(),
+		}, nil
+	}
+	response := analysis.Response
+	response.Explain = exp.finish()
+
+	return response, nil
+}
+
+// sanitizeStage masks secrets in the log and extracts the failure details.
+func (a *Analyzer) sanitizeStage(ctx context.Context, analysis *Analysis) error {
+	sanitizeStart := time.Now()
+	logSanitizer := a.sanitizerFor(ctx)
+	var sanitizedLog string
+	if a.reversible {
+		sanitizedLog, analysis.placeholders, analysis.stats = logSanitizer.SanitizeReversible(analysis.raw)
+	} else {
+		sanitizedLog, analysis.stats = logSanitizer.SanitizeWithStats(analysis.raw)
+	}
+	stats := analysis.stats
+	a.loggerFor(ctx).Debug("log sanitized",
 		zap.Int("original_size", stats.OriginalSize),
 		zap.Int("sanitized_size", stats.SanitizedSize),
 		zap.Int("secrets_found", stats.SecretsFound),
 		zap.Bool("truncated", stats.Truncated),
 	)
 
-	// Step 3: Apply rule-based analysis
+	if a.rejectKeys && stats.Has(sanitizer.CategoryPrivateKey) {
+		a.loggerFor(ctx).Warn("rejecting log containing a private key")
+		return fmt.Errorf("%w: a private key was found in the log", domain.ErrSensitiveData)
+	}
+
+	analysis.Log = extract.Preprocess(analysis.raw, sanitizedLog)
+	analysis.raw = ""
+	explainerFrom(ctx).stage("sanitize", sanitizeStart)
+	return nil
+}
+
+// classifyStage applies the rules and the classifier. Confident matches
+// answer the request, or in hybrid mode are explained by the AI; matches
+// below the threshold are passed to the AI as hints.
+func (a *Analyzer) classifyStage(ctx context.Context, analysis *Analysis) error {
+	if analysis.Response != nil {
+		return nil
+	}
+	pre, meta := analysis.Log, analysis.Request.Metadata
+
+	exp := explainerFrom(ctx)
 	if a.enableRules {
-		matches := a.ruleEngine.Analyze(sanitizedLog)
-		if a.ruleEngine.ShouldUseRuleResult(matches) {
-			best := a.ruleEngine.GetBestMatch(matches)
-			a.logger.Info("using rule-based result",
+		ruleEngine := a.rulesFor(ctx)
+		rulesStart := time.Now()
+		matches := ruleEngine.AnalyzePreprocessed(pre, meta)
+		pre.RuleMatches = matches
+		exp.stage("rules", rulesStart)
+		exp.ruleMatches(matches, ruleEngine.ConfidenceThreshold())
+		if ruleEngine.ShouldUseRuleResult(matches) {
+			best := ruleEngine.GetBestMatch(matches)
+			if a.hybridMerge && !a.rulesOnly {
+				analysis.confident = best
+				return nil
+			}
+			a.loggerFor(ctx).Info("using rule-based result",
 				zap.String("rule_id", best.RuleID),
 				zap.Float64("confidence", best.Confidence),
-				zap.Duration("duration", time.Since(startTime)),
+				zap.Duration("duration", time.Since(analysis.started)),
 			)
 
-			return &domain.AnalysisResponse{
+			// Shadow outcomes tune the shared rules with the shared client
+			if _, ok := a.tenantPolicy(ctx); !ok {
+				a.maybeShadowEvaluate(pre.Sanitized, best)
+			}
+
+			analysis.Response = &domain.AnalysisResponse{
 				Success:     true,
 				Result:      best.Result,
 				Source:      "rules:" + best.RuleID,
+				Evidence:    best.Evidence,
 				ProcessedAt: time.Now(),
-			}, nil
+			}
+			return nil
 		}
 
 		if len(matches) > 0 {
-			a.logger.Debug("rule matches below threshold, proceeding to AI",
+			a.loggerFor(ctx).Debug("rule matches below threshold, proceeding to AI",
 				zap.Int("match_count", len(matches)),
 			)
+			analysis.Hints = matches
+		}
+	}
+
+	classifierStart := time.Now()
+	decision := a.classifier.Decide(pre.Sanitized)
+	if a.classifier != nil {
+		exp.stage("classifier", classifierStart)
+	}
+	if decision != nil && decision.Skip {
+		a.loggerFor(ctx).Info("using classifier result",
+			zap.String("error_type", decision.Class.ErrorType),
+			zap.Float64("confidence", decision.Confidence),
+			zap.Duration("duration", time.Since(analysis.started)),
+		)
+		result := *decision.Class.Result
+		analysis.Response = &domain.AnalysisResponse{
+			Success:     true,
+			Result:      &result,
+			Source:      "classifier:" + decision.Class.ErrorType,
+			ProcessedAt: time.Now(),
+		}
+		return nil
+	}
+	analysis.decision = decision
+	return nil
+}
+
+// enrichStage builds the prompt log. The AI sees the request metadata as
+// context; it is part of the cache key because the same log can mean
+// different things on another platform.
+func (a *Analyzer) enrichStage(ctx context.Context, analysis *Analysis) error {
+	if analysis.Response != nil {
+		return nil
+	}
+	promptStart := time.Now()
+	promptLog := a.promptLog(ctx, analysis.Log, analysis.Request.Metadata)
+	if match := analysis.confident; match != nil {
+		analysis.PromptLog = ai.WithRuleResult(promptLog, match.Result)
+		analysis.promptDomain = a.promptDomain(ctx, analysis.Request.Mode, []domain.RuleMatch{*match})
+		analysis.ruleIDs = []string{match.RuleID}
+		explainerFrom(ctx).stage("prompt", promptStart)
+		return nil
+	}
+
+	analysis.PromptLog = ai.WithRuleHints(promptLog, analysis.Hints)
+	analysis.promptDomain = a.promptDomain(ctx, analysis.Request.Mode, analysis.Hints)
+	analysis.ruleIDs = ruleIDs(analysis.Hints)
+	if analysis.decision != nil {
+		analysis.PromptLog = analysis.decision.Hint + "\n\n" + analysis.PromptLog
+	}
+	explainerFrom(ctx).stage("prompt", promptStart)
+	return nil
+}
+
+// analyzeStage answers the request with the AI.
+func (a *Analyzer) analyzeStage(ctx context.Context, analysis *Analysis) error {
+	if analysis.Response != nil {
+		return nil
+	}
+	if analysis.promptDomain != "" {
+		ctx = ai.WithPromptDomain(ctx, analysis.promptDomain)
+	}
+	response := a.analyzeAI(ctx, analysis.Log.Sanitized, analysis.Request.Metadata, analysis.PromptLog, analysis.ruleIDs, analysis.started)
+	if analysis.confident != nil {
+		response = a.mergeHybrid(ctx, analysis.confident, response)
+	}
+	analysis.Response = response
+	return nil
+}
+
+// postProcessStage shapes the response for the client and runs the
+// post-processors.
+func (a *Analyzer) postProcessStage(ctx context.Context, analysis *Analysis) error {
+	response := analysis.Response
+	response.Result = response.Result.ForDetail(ai.DetailFromContext(ctx))
+	if analysis.Request.Mode == domain.AnalysisModeIaC {
+		response.Result = withResourceEvidence(response.Result, analysis.Log.Metadata)
+	}
+	applySafety(response, a.blockDestructive, a.logger)
+	if analysis.contextLines > 0 {
+		if response.Metadata == nil {
+			response.Metadata = &domain.ResponseMetadata{}
+		}
+		response.Metadata.ContextLines = analysis.contextLines
+	}
+	if analysis.stats.SecretsFound > 0 {
+		if response.Metadata == nil {
+			response.Metadata = &domain.ResponseMetadata{}
 		}
+		response.Metadata.Redactions = redactionCounts(analysis.stats)
+	}
+	if response.Success {
+		analysis.vector, response.SimilarIncidents = a.similarIncidents(ctx, analysis.Log.Sanitized)
+		response.Runbooks = a.runbooks(ctx, response)
+	}
+	a.runPostProcessors(ctx, analysis)
+	a.tenantMeter.RecordAnalysis(domain.TenantFromContext(ctx))
+	response.Result = restorePlaceholders(response.Result, analysis.placeholders)
+	return nil
+}
+
+// redactionCounts returns the masked values per category for the response.
+func redactionCounts(stats sanitizer.SanitizationStats) map[string]int {
+	counts := make(map[string]int, len(stats.Categories))
+	for category, n := range stats.Categories {
+		if n > 0 {
+			counts[string(category)] = n
+		}
+	}
+	return counts
+}
+
+// requestLog returns the log to analyze. Requests with sections are composed
+// into one correlated log; Log, if also set, becomes the first section.
+func requestLog(req *domain.AnalysisRequest) (string, error) {
+	if len(req.Sections) == 0 {
+		return req.Log, nil
+	}
+	if len(req.Sections) > maxLogSections {
+		return "", fmt.Errorf("%w: at most %d sections are allowed", domain.ErrInvalidRequest, maxLogSections)
+	}
+
+	sections := make([]domain.LogSection, 0, len(req.Sections)+1)
+	if strings.TrimSpace(req.Log) != "" {
+		sections = append(sections, domain.LogSection{Name: "log", Content: req.Log})
+	}
+	empty := true
+	for i, section := range req.Sections {
+		if strings.TrimSpace(section.Name) == "" {
+			return "", fmt.Errorf("%w: section %d has no name", domain.ErrInvalidRequest, i+1)
+		}
+		if strings.TrimSpace(section.Content) != "" {
+			empty = false
+		}
+		sections = append(sections, section)
+	}
+	if empty && strings.TrimSpace(req.Log) == "" {
+		return "", nil
 	}
 
-	// Step 4: Use AI for analysis
-	result, err := a.aiClient.Analyze(ctx, sanitizedLog)
+	return ai.ComposeLogSections(sections), nil
+}
+
+// ruleIDs returns the IDs of matches.
+func ruleIDs(matches []domain.RuleMatch) []string {
+	var ids []string
+	for _, match := range matches {
+		ids = append(ids, match.RuleID)
+	}
+	return ids
+}
+
+// analyzeAI analyzes aiLog, the sanitized log with any context and hints,
+// using the cache and the AI, falling back to rules when the AI is
+// unavailable. ruleIDs are the rules that shaped aiLog; results are tagged
+// with them and the prompt version for invalidation.
+func (a *Analyzer) analyzeAI(ctx context.Context, sanitizedLog string, meta *domain.LogMetadata, aiLog string, ruleIDs []string, startTime time.Time) *domain.AnalysisResponse {
+	exp := explainerFrom(ctx)
+
+	client, promptVersion := a.clientFor(ctx), a.promptVersion
+	policy, _ := a.tenantPolicy(ctx)
+	// Experiment variants replace the service's model, not a tenant's
+	var variant *experiment.Variant
+	if policy.AIClient == nil {
+		variant = a.experiment.Assign()
+	}
+	if variant != nil {
+		ctx = experiment.WithVariant(ctx, variant)
+		client, promptVersion = variant.Client, variant.PromptVersion
+	}
+
+	// Step 5: Serve repeated failures from the fingerprint cache
+	var fingerprint string
+	if a.cache != nil {
+		cacheStart := time.Now()
+		fingerprint = cache.Fingerprint(aiLog)
+		if promptVersion != "" {
+			fingerprint += ":" + promptVersion
+		}
+		if variant != nil {
+			fingerprint += ":" + variant.Name
+		}
+		if policy.AIClient != nil {
+			fingerprint += ":tenant:" + domain.TenantFromContext(ctx)
+		}
+		if language := ai.LanguageFromContext(ctx); language != "" {
+			fingerprint += ":" + strings.ToLower(language)
+		}
+		if detail := ai.DetailFromContext(ctx); detail != domain.DetailStandard {
+			fingerprint += ":" + string(detail)
+		}
+		if name := ai.PromptDomainFromContext(ctx); name != "" {
+			fingerprint += ":" + name
+		}
+		cached, ok := a.cache.Get(fingerprint)
+		exp.stage("cache", cacheStart)
+		if ok {
+			a.loggerFor(ctx).Info("using cached AI result",
+				zap.String("fingerprint", fingerprint),
+				zap.Duration("duration", time.Since(startTime)),
+			)
+			return &domain.AnalysisResponse{
+				Success:     true,
+				Result:      cached,
+				Source:      "ai",
+				ProcessedAt: time.Now(),
+				Metadata:    a.withProvenance(ctx, &domain.ResponseMetadata{Cached: true}, ruleIDs),
+			}
+		}
+	}
+
+	// Step 6: Degrade to rules-only if the token budget is exhausted
+	if a.rulesOnly || a.meter.Exceeded() || a.tenantQuotaExceeded(ctx) {
+		return a.degradedResponse(ctx, sanitizedLog, meta)
+	}
+
+	// Step 7: Use AI for analysis, with worked examples of similar logs
+	aiLog, exampleIDs := a.withExamples(ctx, sanitizedLog, aiLog)
+	aiStart := time.Now()
+	aiCtx, captured := a.capture.Start(ctx)
+	result, reduction, err := analyzeWithRecovery(aiCtx, a.limiter, client, aiLog, a.loggerFor(ctx))
+	exp.stage("ai", aiStart)
+	domain.OutcomeFromContext(ctx).RecordAILatency(time.Since(aiStart))
+	if err := captured.Finish(); err != nil {
+		a.loggerFor(ctx).Warn("failed to save AI capture", zap.Error(err))
+	}
+	if variant != nil {
+		variant.Record(time.Since(aiStart), err)
+	}
 	if err != nil {
-		a.logger.Error("AI analysis failed",
+		a.loggerFor(ctx).Error("AI analysis failed",
 			zap.Error(err),
 			zap.Duration("duration", time.Since(startTime)),
 		)
 
 		// Try to use rule-based fallback if AI fails
 		if a.enableRules {
-			matches := a.ruleEngine.Analyze(sanitizedLog)
+			fallbackStart := time.Now()
+			ruleEngine := a.rulesFor(ctx)
+			matches := ruleEngine.AnalyzeWithMetadata(sanitizedLog, meta)
+			exp.stage("rules_fallback", fallbackStart)
 			if len(matches) > 0 {
-				best := a.ruleEngine.GetBestMatch(matches)
+				best := ruleEngine.GetBestMatch(matches)
 				if best != nil {
-					a.logger.Info("using rule-based fallback after AI failure",
+					a.loggerFor(ctx).Info("using rule-based fallback after AI failure",
 						zap.String("rule_id", best.RuleID),
 					)
 					return &domain.AnalysisResponse{
 						Success:     true,
 						Result:      best.Result,
 						Source:      "rules_fallback:" + best.RuleID,
+						Evidence:    best.Evidence,
 						ProcessedAt: time.Now(),
-					}, nil
+					}
 				}
 			}
 		}
 
+		if a.bestEffort && !errors.Is(ctx.Err(), context.Canceled) {
+			return a.heuristicResponse(sanitizedLog, &domain.ResponseMetadata{Degraded: true})
+		}
+
 		return &domain.AnalysisResponse{
 			Success:     false,
-			Error:       err.Error(),
+			Error:       domain.ErrorDetailFor(err),
 			ProcessedAt: time.Now(),
-		}, nil
+		}
 	}
 
-	a.logger.Info("AI analysis completed",
+	a.activity.RecordSuccess()
+	a.loggerFor(ctx).Info("AI analysis completed",
 		zap.String("error_type", result.ErrorType),
 		zap.String("severity", string(result.Severity)),
 		zap.Duration("duration", time.Since(startTime)),
 	)
 
+	usageMeta := usageMetadata(a.meter, result, a.loggerFor(ctx))
+	recordTenantAI(a.tenantMeter, domain.TenantFromContext(ctx), usageMeta)
+	metadata := a.withProvenance(ctx, withReduction(usageMeta, reduction), ruleIDs)
+	if len(exampleIDs) > 0 {
+		if metadata == nil {
+			metadata = &domain.ResponseMetadata{}
+		}
+		metadata.ExampleIDs = exampleIDs
+	}
+
+	if a.cache != nil {
+		cached := *result
+		cached.Usage = nil
+		a.cache.Put(fingerprint, &cached, cacheTags(promptVersion, ruleIDs)...)
+	}
+
 	return &domain.AnalysisResponse{
 		Success:     true,
 		Result:      result,
 		Source:      "ai",
 		ProcessedAt: time.Now(),
-	}, nil
+		Metadata:    metadata,
+	}
+}
+
+// mergeHybrid merges the AI's explanation of a confident rule match with
+// the rule: the rule supplies error_type and severity, the AI supplies the
+// log-specific root cause, actions and tips. The rule result is returned
+// unchanged if the AI could not be used.
+func (a *Analyzer) mergeHybrid(ctx context.Context, match *domain.RuleMatch, response *domain.AnalysisResponse) *domain.AnalysisResponse {
+	if response.Metadata != nil && response.Metadata.Degraded {
+		return response
+	}
+	if !response.Success || response.Source != "ai" {
+		a.loggerFor(ctx).Info("using rule-based result after hybrid AI failure",
+			zap.String("rule_id", match.RuleID),
+		)
+		return &domain.AnalysisResponse{
+			Success:     true,
+			Result:      match.Result,
+			Source:      "rules:" + match.RuleID,
+			Evidence:    match.Evidence,
+			ProcessedAt: time.Now(),
+		}
+	}
+
+	merged := *response.Result
+	merged.ErrorType = match.Result.ErrorType
+	merged.Severity = match.Result.Severity
+	response.Result = &merged
+	response.Source = "hybrid:" + match.RuleID
+	return response
+}
+
+// withProvenance records the prompt version and domain, the experiment
+// variant and the contributing rules in metadata, which may be nil.
+func (a *Analyzer) withProvenance(ctx context.Context, metadata *domain.ResponseMetadata, ruleIDs []string) *domain.ResponseMetadata {
+	promptDomain := ai.PromptDomainFromContext(ctx)
+	promptVersion, variantName := a.promptVersion, ""
+	if variant := experiment.VariantFromContext(ctx); variant != nil {
+		promptVersion, variantName = variant.PromptVersion, variant.Name
+	}
+	if promptVersion == "" && promptDomain == "" && variantName == "" && len(ruleIDs) == 0 {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = &domain.ResponseMetadata{}
+	}
+	metadata.PromptVersion = promptVersion
+	metadata.PromptDomain = promptDomain
+	metadata.Variant = variantName
+	metadata.RuleIDs = ruleIDs
+	return metadata
+}
+
+// promptDomain returns the domain of the specialized prompt for the
+// strongest match, if prompt routing is enabled and there is one. The
+// specialist modes always use their prompts.
+func (a *Analyzer) promptDomain(ctx context.Context, mode domain.AnalysisMode, matches []domain.RuleMatch) string {
+	switch mode {
+	case domain.AnalysisModeIaC:
+		return ai.PromptDomainIaC
+	case domain.AnalysisModeKubernetes:
+		return ai.PromptDomainKubernetes
+	case domain.AnalysisModeDocker:
+		return ai.PromptDomainDocker
+	case domain.AnalysisModeCI:
+		return ai.PromptDomainCI
+	}
+	if !a.routePrompt {
+		return ""
+	}
+	name := ai.RoutePromptDomain(matches)
+	if name != "" {
+		a.loggerFor(ctx).Debug("using specialized prompt", zap.String("prompt_domain", name))
+	}
+	return name
+}
+
+// cacheTags returns the invalidation tags for a cached AI result.
+func cacheTags(promptVersion string, ruleIDs []string) []string {
+	var tags []string
+	if promptVersion != "" {
+		tags = append(tags, cache.PromptTag(promptVersion))
+	}
+	for _, id := range ruleIDs {
+		tags = append(tags, cache.RuleTag(id))
+	}
+	return tags
+}
+
+// promptLog prepares the sanitized log for the AI: compacted if enabled and
+// prefixed with the extracted failure details and the sanitized request
+// metadata.
+func (a *Analyzer) promptLog(ctx context.Context, pre *domain.PreprocessedLog, meta *domain.LogMetadata) string {
+	sanitizedLog := pre.Sanitized
+	if a.compactLogs {
+		compacted := compactLog(sanitizedLog)
+		a.loggerFor(ctx).Debug("log compacted",
+			zap.Int("original_size", len(sanitizedLog)),
+			zap.Int("compacted_size", len(compacted)),
+		)
+		sanitizedLog = compacted
+	}
+	sanitizedLog = ai.WithFailureContext(sanitizedLog, pre.Metadata)
+
+	header, _ := a.sanitizerFor(ctx).Sanitize(ai.WithLogMetadata("", meta))
+	if header == "" {
+		return sanitizedLog
+	}
+	return header + "\n\n" + sanitizedLog
+}
+
+// persist stores a successful analysis and sets the response ID.
+// Storage failures are logged but never fail the analysis.
+func (a *Analyzer) persist(ctx context.Context, sanitizedLog string, response *domain.AnalysisResponse) {
+	if a.store == nil || !response.Success {
+		return
+	}
+
+	record := &domain.AnalysisRecord{
+		Log:      sanitizedLog,
+		Source:   response.Source,
+		Result:   response.Result,
+		Metadata: response.Metadata,
+		Evidence: response.Evidence,
+	}
+	if err := a.store.SaveAnalysis(ctx, record); err != nil {
+		a.loggerFor(ctx).Warn("failed to store analysis", zap.Error(err))
+		return
+	}
+	response.ID = record.ID
+}
+
+// notify sends a notification for a successful analysis in the background so
+// chat API latency never delays the response.
+func (a *Analyzer) notify(sanitizedLog string, response *domain.AnalysisResponse) {
+	if a.notifier == nil || !response.Success {
+		return
+	}
+
+	fingerprint := cache.Fingerprint(sanitizedLog)
+	source, result := response.Source, response.Result
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := a.notifier.Notify(ctx, fingerprint, source, result); err != nil {
+			a.loggerFor(ctx).Warn("failed to send notification", zap.Error(err))
+		}
+	}()
+}
+
+// escalate pages on-call for an analysis matching the escalation
+// conditions, in the background like notifications.
+func (a *Analyzer) escalate(sanitizedLog string, response *domain.AnalysisResponse) {
+	if !a.escalator.Wants(response) {
+		return
+	}
+
+	fingerprint := cache.Fingerprint(sanitizedLog)
+	// The caller keeps changing response (restored placeholders, explain)
+	snapshot := *response
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if _, err := a.escalator.Escalate(ctx, fingerprint, &snapshot); err != nil {
+			a.loggerFor(ctx).Warn("failed to escalate analysis", zap.Error(err))
+		}
+	}()
+}
+
+// analyzeWithLimit calls the AI client once the limiter admits the request.
+func analyzeWithLimit(ctx context.Context, limiter *ConcurrencyLimiter, client ai.Client, log string) (*domain.AnalysisResult, error) {
+	release, err := limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return client.Analyze(ctx, log)
+}
+
+// usageMetadata records the AI call's token usage and builds response metadata.
+// Returns nil when the provider did not report usage.
+func usageMetadata(meter *usage.Meter, result *domain.AnalysisResult, logger *zap.Logger) *domain.ResponseMetadata {
+	if result.Usage == nil {
+		return nil
+	}
+
+	cost := meter.Record(result.Usage)
+	logger.Info("AI token usage",
+		zap.String("model", result.Usage.Model),
+		zap.Int("prompt_tokens", result.Usage.PromptTokens),
+		zap.Int("completion_tokens", result.Usage.CompletionTokens),
+		zap.Int("total_tokens", result.Usage.TotalTokens),
+		zap.Float64("estimated_cost_usd", cost),
+	)
+
+	return &domain.ResponseMetadata{
+		Usage:            result.Usage,
+		EstimatedCostUSD: cost,
+	}
+}
+
+// loggerFor returns the analyzer's logger with the request ID in ctx.
+func (a *Analyzer) loggerFor(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, a.logger)
+}
+
+// withReduction attaches a log reduction note to metadata, creating it if needed.
+func withReduction(metadata *domain.ResponseMetadata, reduction *domain.LogReduction) *domain.ResponseMetadata {
+	if reduction == nil {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = &domain.ResponseMetadata{}
+	}
+	metadata.Reduction = reduction
+	return metadata
+}
+
+// degradedResponse answers from rules only, ignoring the confidence threshold,
+// when the AI may not be used.
+func (a *Analyzer) degradedResponse(ctx context.Context, log string, meta *domain.LogMetadata) *domain.AnalysisResponse {
+	reason := domain.ErrBudgetExceeded
+	switch {
+	case a.rulesOnly:
+		reason = errRulesOnly
+	case a.meter.Exceeded():
+		a.loggerFor(ctx).Warn("token budget exceeded, degrading to rules-only analysis")
+	default:
+		reason = domain.ErrTenantQuotaExceeded
+		a.loggerFor(ctx).Warn("tenant quota exceeded, degrading to rules-only analysis",
+			zap.String("tenant", domain.TenantFromContext(ctx)),
+		)
+	}
+
+	metadata := &domain.ResponseMetadata{Degraded: true}
+
+	if a.enableRules {
+		ruleEngine := a.rulesFor(ctx)
+		if top := ruleEngine.GetTopMatch(ruleEngine.AnalyzeWithMetadata(log, meta)); top != nil {
+			return &domain.AnalysisResponse{
+				Success:     true,
+				Result:      top.Result,
+				Source:      "rules_degraded:" + top.RuleID,
+				Evidence:    top.Evidence,
+				ProcessedAt: time.Now(),
+				Metadata:    metadata,
+			}
+		}
+	}
+
+	if a.bestEffort {
+		return a.heuristicResponse(log, metadata)
+	}
+
+	return &domain.AnalysisResponse{
+		Success:     false,
+		Error:       domain.ErrorDetailFor(reason),
+		ProcessedAt: time.Now(),
+		Metadata:    metadata,
+	}
 }
@@ -0,0 +1,31 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+
+	"github.com/ai-devops/internal/ciconfig"
+	"github.com/ai-devops/internal/domain"
+)
+
+// AnalyzePipeline analyzes req, built by ciconfig.Request.AnalysisRequest,
+// like Analyze and returns the pipeline review with the response, whether
+// the analysis succeeded or not. A successful result without evidence gets
+// the failing step and the findings about the failure as evidence.
+func (a *Analyzer) AnalyzePipeline(ctx context.Context, req *domain.AnalysisRequest, review *domain.PipelineReview) (*domain.AnalysisResponse, error) {
+	response, err := a.Analyze(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	response.Pipeline = review
+	if !response.Success || response.Result == nil || len(response.Result.Evidence) > 0 {
+		return response, nil
+	}
+	if evidence := ciconfig.Evidence(review); len(evidence) > 0 {
+		// Rule and cached results are shared between analyses
+		result := *response.Result
+		result.Evidence = evidence
+		response.Result = &result
+	}
+	return response, nil
+}
@@ -0,0 +1,99 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ai-devops/pkg/sanitizer"
+)
+
+// verboseLinePattern matches lines logged at INFO, DEBUG or TRACE level in
+// plain ("INFO ...", "[debug] ..."), logfmt and JSON formats.
+var verboseLinePattern = regexp.MustCompile(`^(?:\S+\s+){0,2}\[?(?:INFO|DEBUG|TRACE|Info|Debug|Trace|info|debug|trace)\]?[:\s]|(?i:level=(?:info|debug|trace)\b)|(?i:"level"\s*:\s*"(?:info|debug|trace)")`)
+
+// verboseContextLines is the number of verbose lines kept on each side of an
+// error line, since they often say what was being attempted.
+const verboseContextLines = 2
+
+// Stack traces longer than maxStackFrames keep their first headStackFrames
+// frames (where the failure is) and the rest of the budget from the end
+// (the entry point).
+const (
+	maxStackFrames  = 12
+	headStackFrames = 8
+)
+
+// compactLog shrinks a log before it is sent to the AI: INFO/DEBUG/TRACE
+// lines away from any error are dropped (only if the log has an error line),
+// long stack traces are summarized and runs of repeated lines are collapsed.
+// Rules always see the full log.
+func compactLog(log string) string {
+	lines := dropVerboseLines(strings.Split(log, "\n"))
+	lines = summarizeStackTraces(lines)
+	return strings.Join(collapseRepeats(lines), "\n")
+}
+
+// dropVerboseLines removes verbose lines that are not near an error line.
+// Logs without an error line are returned unchanged: then the verbose lines
+// may be all there is to go on.
+func dropVerboseLines(lines []string) []string {
+	nearError := make([]bool, len(lines))
+	hasError := false
+	for i, line := range lines {
+		if !errorLinePattern.MatchString(line) {
+			continue
+		}
+		hasError = true
+		for j := max(0, i-verboseContextLines); j <= min(len(lines)-1, i+verboseContextLines); j++ {
+			nearError[j] = true
+		}
+	}
+	if !hasError {
+		return lines
+	}
+
+	out := make([]string, 0, len(lines))
+	omitted := 0
+	for i, line := range lines {
+		if !nearError[i] && verboseLinePattern.MatchString(line) {
+			omitted++
+			continue
+		}
+		if omitted > 0 {
+			out = append(out, fmt.Sprintf("... [%d verbose lines omitted] ...", omitted))
+			omitted = 0
+		}
+		out = append(out, line)
+	}
+	if omitted > 0 {
+		out = append(out, fmt.Sprintf("... [%d verbose lines omitted] ...", omitted))
+	}
+	return out
+}
+
+// summarizeStackTraces shortens runs of more than maxStackFrames frames.
+func summarizeStackTraces(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); {
+		j := i
+		for j < len(lines) && sanitizer.IsStackFrame(lines[j]) {
+			j++
+		}
+		switch {
+		case j == i:
+			out = append(out, lines[i])
+			j++
+		case j-i <= maxStackFrames:
+			out = append(out, lines[i:j]...)
+		default:
+			tail := maxStackFrames - headStackFrames
+			out = append(out, lines[i:i+headStackFrames]...)
+			out = append(out, fmt.Sprintf("... [%d frames omitted] ...", j-i-maxStackFrames))
+			out = append(out, lines[j-tail:j]...)
+		}
+		i = j
+	}
+	return out
+}
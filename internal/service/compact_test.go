@@ -0,0 +1,58 @@
+// Package service provides unit tests for log compaction.
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompactLog(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&b, "INFO request %d handled\n", i)
+	}
+	b.WriteString("DEBUG opening pool\n")
+	b.WriteString("INFO connecting to db\n")
+	b.WriteString("ERROR java.sql.SQLException: pool exhausted\n")
+	for i := 0; i < 30; i++ {
+		layer := "Layer" + strings.Repeat("x", i)
+		fmt.Fprintf(&b, "\tat com.example.%s.call(%s.java:42)\n", layer, layer)
+	}
+	for i := 0; i < 5; i++ {
+		b.WriteString("WARN retrying\n")
+	}
+	b.WriteString(`{"level":"debug","msg":"shutdown"}`)
+	log := b.String()
+
+	got := compactLog(log)
+
+	for _, want := range []string{
+		"INFO connecting to db",
+		"ERROR java.sql.SQLException: pool exhausted",
+		"\tat com.example.Layer.call(Layer.java:42)",
+		"... [18 frames omitted] ...",
+		"\tat com.example.Layer" + strings.Repeat("x", 29) + ".call(",
+		"WARN retrying\n... [previous line repeated 4 more times] ...",
+		"... [50 verbose lines omitted] ...",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("compacted log missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"request 10 handled", "Layer" + strings.Repeat("x", 12) + ".call", `"level":"debug"`} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("compacted log should not contain %q:\n%s", unwanted, got)
+		}
+	}
+	if len(got) >= len(log)/3 {
+		t.Errorf("compacted size %d not much smaller than %d", len(got), len(log))
+	}
+}
+
+func TestCompactLog_NoErrorKeepsVerboseLines(t *testing.T) {
+	log := "INFO starting\nDEBUG config loaded\nINFO ready"
+	if got := compactLog(log); got != log {
+		t.Errorf("compactLog() = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,22 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+)
+
+// withDetail validates the requested detail level and attaches it to ctx
+// for the AI client. An empty level means standard.
+func withDetail(ctx context.Context, requested domain.DetailLevel) (context.Context, error) {
+	if requested == "" {
+		return ctx, nil
+	}
+	if !requested.IsValid() {
+		return ctx, fmt.Errorf("%w: invalid detail %q (want brief, standard or deep)", domain.ErrInvalidRequest, requested)
+	}
+	return ai.WithDetail(ctx, requested), nil
+}
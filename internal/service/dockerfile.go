@@ -0,0 +1,31 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+
+	"github.com/ai-devops/internal/dockerfile"
+	"github.com/ai-devops/internal/domain"
+)
+
+// AnalyzeDockerfile analyzes req, built by dockerfile.Request.AnalysisRequest,
+// like Analyze and returns the static review with the response, whether
+// the analysis succeeded or not. A successful result without evidence gets
+// the failing instruction as evidence.
+func (a *Analyzer) AnalyzeDockerfile(ctx context.Context, req *domain.AnalysisRequest, review *domain.DockerfileReview) (*domain.AnalysisResponse, error) {
+	response, err := a.Analyze(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	response.Dockerfile = review
+	if !response.Success || response.Result == nil || len(response.Result.Evidence) > 0 {
+		return response, nil
+	}
+	if evidence := dockerfile.Evidence(review); len(evidence) > 0 {
+		// Rule and cached results are shared between analyses
+		result := *response.Result
+		result.Evidence = evidence
+		response.Result = &result
+	}
+	return response, nil
+}
@@ -0,0 +1,68 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// contextSection names the section holding fetched context lines.
+const contextSection = "context"
+
+// ContextFetcher fetches log lines surrounding an incident from a log store.
+// Invalid queries return an error wrapping domain.ErrInvalidRequest.
+type ContextFetcher interface {
+	Fetch(ctx context.Context, q *domain.ContextQuery) ([]string, error)
+}
+
+// withContext returns req with the lines selected by req.Context added as a
+// section, and the number of lines added. Lines already in req.Log are not
+// repeated. A failed fetch is logged and the request analyzed as submitted.
+func (a *Analyzer) withContext(ctx context.Context, req *domain.AnalysisRequest) (*domain.AnalysisRequest, int, error) {
+	if req.Context == nil || a.contextFetcher == nil {
+		return req, 0, nil
+	}
+
+	start := time.Now()
+	fetched, err := a.contextFetcher.Fetch(ctx, req.Context)
+	explainerFrom(ctx).stage("context", start)
+	if errors.Is(err, domain.ErrInvalidRequest) {
+		return nil, 0, err
+	}
+	if err != nil {
+		a.loggerFor(ctx).Warn("context fetch failed, analyzing submitted log only", zap.Error(err))
+		return req, 0, nil
+	}
+
+	submitted := make(map[string]bool)
+	for _, line := range strings.Split(req.Log, "\n") {
+		submitted[strings.TrimSpace(line)] = true
+	}
+	for _, section := range req.Sections {
+		for _, line := range strings.Split(section.Content, "\n") {
+			submitted[strings.TrimSpace(line)] = true
+		}
+	}
+	var lines []string
+	for _, line := range fetched {
+		if !submitted[strings.TrimSpace(line)] {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return req, 0, nil
+	}
+
+	enriched := *req
+	enriched.Sections = append(append([]domain.LogSection(nil), req.Sections...), domain.LogSection{
+		Name:    contextSection,
+		Content: strings.Join(lines, "\n"),
+	})
+	a.loggerFor(ctx).Debug("log enriched with context", zap.Int("context_lines", len(lines)))
+	return &enriched, len(lines), nil
+}
@@ -0,0 +1,38 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"go.uber.org/zap"
+)
+
+// withExamples prefixes aiLog with the stored examples most similar to the
+// sanitized log and returns their IDs. aiLog is returned unchanged when no
+// example store is configured or none is similar enough.
+func (a *Analyzer) withExamples(ctx context.Context, sanitizedLog, aiLog string) (string, []string) {
+	if a.examples == nil || a.exampleCount <= 0 {
+		return aiLog, nil
+	}
+
+	start := time.Now()
+	matches := a.examples.Similar(sanitizedLog, a.exampleCount, a.exampleMinScore)
+	explainerFrom(ctx).stage("examples", start)
+	if len(matches) == 0 {
+		return aiLog, nil
+	}
+
+	examples := make([]ai.WorkedExample, len(matches))
+	ids := make([]string, len(matches))
+	for i, match := range matches {
+		examples[i] = ai.WorkedExample{Log: match.Example.Log, Result: match.Example.Result}
+		ids[i] = match.Example.ID
+	}
+	a.loggerFor(ctx).Debug("adding worked examples to prompt",
+		zap.Strings("example_ids", ids),
+		zap.Float64("top_score", matches[0].Score),
+	)
+	return ai.WithWorkedExamples(aiLog, examples), ids
+}
@@ -0,0 +1,92 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+)
+
+type explainerKey struct{}
+
+// explainer collects the Explanation of one analysis. A nil explainer
+// records nothing, so the pipeline calls it unconditionally.
+type explainer struct {
+	start       time.Time
+	trace       *ai.Trace
+	explanation domain.Explanation
+}
+
+// withExplainer returns a context that records the analysis run with it,
+// including its AI requests.
+func withExplainer(ctx context.Context) (context.Context, *explainer) {
+	e := &explainer{
+		start: time.Now(),
+		trace: &ai.Trace{},
+		explanation: domain.Explanation{
+			Stages:      []domain.StageTiming{},
+			RuleMatches: []domain.ExplainedMatch{},
+		},
+	}
+	ctx = context.WithValue(ctx, explainerKey{}, e)
+	return ai.WithTrace(ctx, e.trace), e
+}
+
+// explainerFrom returns the explainer carried by ctx, or nil.
+func explainerFrom(ctx context.Context) *explainer {
+	e, _ := ctx.Value(explainerKey{}).(*explainer)
+	return e
+}
+
+// stage records a pipeline stage that started at start and just ended.
+func (e *explainer) stage(name string, start time.Time) {
+	if e == nil {
+		return
+	}
+	e.explanation.Stages = append(e.explanation.Stages, domain.StageTiming{
+		Name:       name,
+		DurationMS: milliseconds(time.Since(start)),
+	})
+}
+
+// ruleMatches records the rule matches considered and the threshold they
+// were held to.
+func (e *explainer) ruleMatches(matches []domain.RuleMatch, threshold float64) {
+	if e == nil {
+		return
+	}
+	e.explanation.RuleThreshold = threshold
+	for _, match := range matches {
+		e.explanation.RuleMatches = append(e.explanation.RuleMatches, domain.ExplainedMatch{
+			RuleID:         match.RuleID,
+			Confidence:     match.Confidence,
+			AboveThreshold: match.Confidence >= threshold,
+		})
+	}
+}
+
+// finish completes the explanation with the AI requests and total time.
+func (e *explainer) finish() *domain.Explanation {
+	if e == nil {
+		return nil
+	}
+	explanation := e.explanation
+	explanation.TotalMS = milliseconds(time.Since(e.start))
+	explanation.PromptBytes = e.trace.PromptBytes()
+	explanation.AIAttempts = e.trace.Attempts()
+	if n := len(explanation.AIAttempts); n > 0 {
+		explanation.Retries = n - 1
+		if last := explanation.AIAttempts[n-1]; last.Error == "" {
+			explanation.Provider = last.Provider
+			explanation.Model = last.Model
+		}
+	}
+	return &explanation
+}
+
+// milliseconds converts d to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,82 @@
+// Package service provides unit tests for explain mode.
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/pkg/sanitizer"
+	"go.uber.org/zap"
+)
+
+func TestAnalyzer_Explain(t *testing.T) {
+	logger := zap.NewNop()
+	weak := &rules.Rule{
+		ID:         "weak_timeout",
+		Keywords:   []string{"timeout"},
+		Confidence: 0.5,
+		Result:     &domain.AnalysisResult{ErrorType: "timeout"},
+	}
+	oom := &rules.Rule{
+		ID:         "oom",
+		Keywords:   []string{"OOMKilled"},
+		Confidence: 0.95,
+		Result:     &domain.AnalysisResult{ErrorType: "OOMKilled"},
+	}
+	engine := rules.NewEngine([]*rules.Rule{weak, oom}, 0.8, logger)
+
+	tests := []struct {
+		name    string
+		log     string
+		explain bool
+		hybrid  bool
+	}{
+		{name: "not requested", log: "request timeout after 30s"},
+		{name: "requested", log: "request timeout after 30s", explain: true},
+		{name: "confident match merged", log: "request timeout after 30s, container OOMKilled", explain: true, hybrid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAnalyzer(t, ai.NewMockClient(logger), engine, sanitizer.New(10000),
+				AnalyzerConfig{EnableRules: true, HybridMerge: tt.hybrid}, logger)
+			resp, err := a.Analyze(context.Background(), &domain.AnalysisRequest{
+				Log:     tt.log,
+				Explain: tt.explain,
+			})
+			if err != nil || !resp.Success {
+				t.Fatalf("Analyze() = %+v, %v", resp, err)
+			}
+			if !tt.explain {
+				if resp.Explain != nil {
+					t.Errorf("unexpected explanation %+v", resp.Explain)
+				}
+				return
+			}
+
+			exp := resp.Explain
+			if exp == nil {
+				t.Fatal("missing explanation")
+			}
+			var stages []string
+			for _, stage := range exp.Stages {
+				stages = append(stages, stage.Name)
+			}
+			if got, want := len(stages), 4; got != want || stages[0] != "sanitize" || stages[1] != "rules" || stages[2] != "prompt" || stages[3] != "ai" {
+				t.Errorf("stages = %v, want sanitize, rules, prompt, ai", stages)
+			}
+			if tt.hybrid {
+				return
+			}
+			if len(exp.RuleMatches) != 1 || exp.RuleMatches[0].AboveThreshold || exp.RuleThreshold != 0.8 {
+				t.Errorf("rule matches = %+v (threshold %v)", exp.RuleMatches, exp.RuleThreshold)
+			}
+			if exp.Provider != "mock" || len(exp.AIAttempts) != 1 || exp.Retries != 0 || exp.PromptBytes == 0 {
+				t.Errorf("AI details = %+v", exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// heuristicErrorType is the error type of best-effort results.
+const heuristicErrorType = "unclassified"
+
+// maxHeuristicLines bounds the error lines quoted in a best-effort result.
+const maxHeuristicLines = 10
+
+// maxHeuristicLineLength bounds the length of a quoted line in bytes.
+const maxHeuristicLineLength = 200
+
+// heuristicActions are generic triage steps for a failure nothing classified.
+var heuristicActions = []string{
+	"Read the error lines listed as evidence, starting with the first: later errors are often consequences of it.",
+	"Re-run the job to rule out a transient failure such as a network, registry or runner problem.",
+	"Compare with the last successful run: check recent changes to code, dependencies, configuration and infrastructure.",
+	"Retry the analysis once AI analysis is available for a specific diagnosis.",
+}
+
+// heuristicTips are generic prevention tips for best-effort results.
+var heuristicTips = []string{
+	"Once diagnosed, add a rule for this failure so it is classified without the AI.",
+}
+
+// heuristicResponse is the best-effort answer for a log neither the rules
+// nor the AI could classify: the log's error lines and generic triage steps.
+func (a *Analyzer) heuristicResponse(log string, metadata *domain.ResponseMetadata) *domain.AnalysisResponse {
+	lines := errorLines(log)
+	a.logger.Info("no rule matched and AI unavailable, returning best-effort result")
+
+	return &domain.AnalysisResponse{
+		Success: true,
+		Result: &domain.AnalysisResult{
+			ErrorType:        heuristicErrorType,
+			Severity:         domain.SeverityMedium,
+			RootCause:        heuristicRootCause(len(lines)),
+			SuggestedActions: heuristicActions,
+			PreventionTips:   heuristicTips,
+			Evidence:         lines,
+		},
+		Source:      "heuristic",
+		ProcessedAt: time.Now(),
+		Metadata:    metadata,
+	}
+}
+
+// heuristicRootCause explains a best-effort result.
+func heuristicRootCause(errorLines int) string {
+	const cause = "The failure could not be classified: no rule matched and AI analysis is unavailable."
+	if errorLines == 0 {
+		return cause + " The log contains no recognizable error lines; check the end of the log and the job's exit status."
+	}
+	return cause + " The lines reporting errors are listed as evidence."
+}
+
+// errorLines returns the distinct lines of log that look like errors, in
+// order and at most maxHeuristicLines.
+func errorLines(log string) []string {
+	var lines []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] || !errorLinePattern.MatchString(line) {
+			continue
+		}
+		seen[line] = true
+		lines = append(lines, shortenLine(line))
+		if len(lines) == maxHeuristicLines {
+			break
+		}
+	}
+	return lines
+}
+
+// shortenLine cuts line to maxHeuristicLineLength bytes.
+func shortenLine(line string) string {
+	if len(line) <= maxHeuristicLineLength {
+		return line
+	}
+	cut := maxHeuristicLineLength
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "…"
+}
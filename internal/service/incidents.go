@@ -0,0 +1,80 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/store"
+	"go.uber.org/zap"
+)
+
+// similarIncidents embeds the sanitized log and returns the stored analyses
+// of the most similar indexed logs. The vector is also returned so the new
+// analysis can be indexed once stored. Embedding failures are logged and
+// only cost the similar incidents.
+func (a *Analyzer) similarIncidents(ctx context.Context, sanitizedLog string) ([]float32, []domain.SimilarIncident) {
+	if a.embedder == nil || a.incidents == nil || a.store == nil || a.rulesOnly {
+		return nil, nil
+	}
+
+	start := time.Now()
+	defer explainerFrom(ctx).stage("similar_incidents", start)
+
+	vector, err := a.embedder.Embed(ctx, sanitizedLog)
+	if err != nil {
+		a.loggerFor(ctx).Warn("failed to embed log, skipping similar incidents", zap.Error(err))
+		return nil, nil
+	}
+
+	var similar []domain.SimilarIncident
+	for _, hit := range a.incidents.Search(vector, a.incidentCount, a.incidentMinScore) {
+		record, err := a.store.GetAnalysis(ctx, hit.ID)
+		if errors.Is(err, store.ErrNotFound) {
+			// Evicted from the store
+			a.incidents.Remove(hit.ID)
+			continue
+		}
+		if err != nil {
+			a.loggerFor(ctx).Warn("failed to load similar incident", zap.String("analysis_id", hit.ID), zap.Error(err))
+			continue
+		}
+		if record.Stale || record.Result == nil {
+			continue
+		}
+		similar = append(similar, a.similarIncident(ctx, record, hit.Score))
+	}
+	return vector, similar
+}
+
+// similarIncident describes a stored analysis and its feedback.
+func (a *Analyzer) similarIncident(ctx context.Context, record *domain.AnalysisRecord, score float64) domain.SimilarIncident {
+	incident := domain.SimilarIncident{
+		AnalysisID: record.ID,
+		Link:       a.incidentLinkBase + "/api/v1/analyses/" + record.ID,
+		Similarity: score,
+		OccurredAt: record.CreatedAt,
+		ErrorType:  record.Result.ErrorType,
+		RootCause:  record.Result.RootCause,
+		Resolution: record.Result.SuggestedActions,
+	}
+
+	feedback, err := a.store.ListFeedback(ctx, record.ID)
+	if err != nil {
+		a.loggerFor(ctx).Warn("failed to load incident feedback", zap.String("analysis_id", record.ID), zap.Error(err))
+		return incident
+	}
+	incident.Confirmed = len(feedback) > 0
+	for _, f := range feedback {
+		if !f.Helpful {
+			incident.Confirmed = false
+			continue
+		}
+		if f.Comment != "" {
+			incident.Notes = append(incident.Notes, f.Comment)
+		}
+	}
+	return incident
+}
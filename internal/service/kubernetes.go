@@ -0,0 +1,27 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/kubernetes"
+)
+
+// AnalyzeKubernetes analyzes req, built by kubernetes.Request.AnalysisRequest,
+// like Analyze. A successful result without evidence gets the facts of pod
+// that explain common failures (see kubernetes.Evidence), such as the
+// memory limit of an OOMKilled container.
+func (a *Analyzer) AnalyzeKubernetes(ctx context.Context, req *domain.AnalysisRequest, pod *kubernetes.Pod) (*domain.AnalysisResponse, error) {
+	response, err := a.Analyze(ctx, req)
+	if err != nil || !response.Success || response.Result == nil || len(response.Result.Evidence) > 0 {
+		return response, err
+	}
+	if evidence := kubernetes.Evidence(pod); len(evidence) > 0 {
+		// Rule and cached results are shared between analyses
+		result := *response.Result
+		result.Evidence = evidence
+		response.Result = &result
+	}
+	return response, nil
+}
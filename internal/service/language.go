@@ -0,0 +1,47 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+)
+
+// maxLanguageLength bounds the language request field.
+const maxLanguageLength = 35
+
+// withLanguage validates the requested output language, falling back to
+// defaultLanguage, and attaches it to ctx for the AI client.
+func withLanguage(ctx context.Context, requested, defaultLanguage string) (context.Context, error) {
+	language := requested
+	if language == "" {
+		language = defaultLanguage
+	}
+	if language == "" {
+		return ctx, nil
+	}
+	if !validLanguage(language) {
+		return ctx, fmt.Errorf("%w: invalid language %q", domain.ErrInvalidRequest, language)
+	}
+	return ai.WithLanguage(ctx, language), nil
+}
+
+// validLanguage accepts language names and tags such as "Vietnamese",
+// "ja" or "pt-BR". It rejects anything that could smuggle instructions
+// into the prompt.
+func validLanguage(language string) bool {
+	if len(language) > maxLanguageLength {
+		return false
+	}
+	for _, r := range language {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r == '-', r == '_', r == ' ':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,288 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// ConcurrencyLimiter bounds the number of in-flight upstream AI requests.
+// Callers beyond the limit wait in a bounded queue for at most maxWait;
+// when the queue is full they fail fast instead of piling up.
+//
+// Waiting callers are served with weighted fair queuing across tenants
+// (taken from the request context), so one tenant's burst cannot starve the
+// others: each tenant receives slots in proportion to its weight.
+//
+// A nil *ConcurrencyLimiter imposes no limit.
+type ConcurrencyLimiter struct {
+	maxInFlight int
+	maxQueued   int
+	maxWait     time.Duration
+	weights     map[string]float64
+
+	mu       sync.Mutex
+	inFlight int
+	queued   int
+	virtual  float64
+	tenants  map[string]*tenantQueue
+	rejected uint64
+	timedOut uint64
+}
+
+// tenantQueue holds one tenant's waiters and metrics.
+type tenantQueue struct {
+	waiters  []*waiter
+	lastTag  float64
+	inFlight int
+
+	grants    uint64
+	totalWait time.Duration
+	maxWait   time.Duration
+}
+
+// waiter is a caller blocked in Acquire.
+type waiter struct {
+	tenant   string
+	tag      float64
+	enqueued time.Time
+	ready    chan struct{}
+	granted  bool
+}
+
+// LimiterStats reports limiter occupancy and rejections.
+type LimiterStats struct {
+	InFlight     int                           `json:"in_flight"`
+	MaxInFlight  int                           `json:"max_in_flight"`
+	Queued       int                           `json:"queued"`
+	MaxQueued    int                           `json:"max_queued"`
+	RejectedFull uint64                        `json:"rejected_queue_full"`
+	RejectedWait uint64                        `json:"rejected_wait_timeout"`
+	Tenants      map[string]TenantLimiterStats `json:"tenants"`
+}
+
+// TenantLimiterStats reports a tenant's share of the limiter.
+type TenantLimiterStats struct {
+	Weight      float64       `json:"weight"`
+	InFlight    int           `json:"in_flight"`
+	Queued      int           `json:"queued"`
+	QueuedTotal uint64        `json:"queued_total"`
+	AverageWait time.Duration `json:"average_wait_ns"`
+	MaxWait     time.Duration `json:"max_wait_ns"`
+}
+
+// NewConcurrencyLimiter allows maxConcurrent requests in flight and up to
+// queueSize waiting callers, each waiting at most maxWait. weights assigns
+// tenants a relative share of slots; unlisted tenants have weight 1.
+func NewConcurrencyLimiter(maxConcurrent, queueSize int, maxWait time.Duration, weights map[string]float64) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		maxInFlight: maxConcurrent,
+		maxQueued:   queueSize,
+		maxWait:     maxWait,
+		weights:     weights,
+		tenants:     make(map[string]*tenantQueue),
+	}
+}
+
+// Acquire reserves an in-flight slot for the tenant in ctx. The returned
+// release func must be called when the request completes.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	tenant := domain.TenantFromContext(ctx)
+
+	l.mu.Lock()
+	if l.inFlight < l.maxInFlight && l.queued == 0 {
+		l.grant(tenant)
+		l.mu.Unlock()
+		return l.releaseFunc(tenant), nil
+	}
+	if l.queued >= l.maxQueued {
+		l.rejected++
+		l.mu.Unlock()
+		return nil, domain.WrapError("ai_queue", domain.ErrAIQueueFull, true)
+	}
+	w := l.enqueue(tenant)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.maxWait)
+	defer timer.Stop()
+
+	var waitErr error
+	select {
+	case <-w.ready:
+		return l.releaseFunc(tenant), nil
+	case <-timer.C:
+		waitErr = domain.WrapError("ai_queue_wait", domain.ErrAIQueueFull, true)
+	case <-ctx.Done():
+		waitErr = domain.WrapError("ai_queue_wait", ctx.Err(), false)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.granted {
+		// Granted concurrently with the timeout; keep the slot.
+		return l.releaseFunc(tenant), nil
+	}
+	l.dequeue(w)
+	if ctx.Err() == nil {
+		l.timedOut++
+	}
+	return nil, waitErr
+}
+
+// TryAcquire reserves a slot only if one is immediately available.
+// It is used for best-effort background work such as shadow evaluation.
+func (l *ConcurrencyLimiter) TryAcquire() (release func(), ok bool) {
+	if l == nil {
+		return func() {}, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight >= l.maxInFlight || l.queued > 0 {
+		return nil, false
+	}
+	l.grant(domain.DefaultTenant)
+	return l.releaseFunc(domain.DefaultTenant), true
+}
+
+// Stats returns the current limiter occupancy.
+func (l *ConcurrencyLimiter) Stats() LimiterStats {
+	if l == nil {
+		return LimiterStats{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := LimiterStats{
+		InFlight:     l.inFlight,
+		MaxInFlight:  l.maxInFlight,
+		Queued:       l.queued,
+		MaxQueued:    l.maxQueued,
+		RejectedFull: l.rejected,
+		RejectedWait: l.timedOut,
+		Tenants:      make(map[string]TenantLimiterStats, len(l.tenants)),
+	}
+	for name, tq := range l.tenants {
+		ts := TenantLimiterStats{
+			Weight:      l.weight(name),
+			InFlight:    tq.inFlight,
+			Queued:      len(tq.waiters),
+			QueuedTotal: tq.grants,
+			MaxWait:     tq.maxWait,
+		}
+		if tq.grants > 0 {
+			ts.AverageWait = tq.totalWait / time.Duration(tq.grants)
+		}
+		stats.Tenants[name] = ts
+	}
+	return stats
+}
+
+// tenant returns the state for name, creating it. Caller holds mu.
+func (l *ConcurrencyLimiter) tenant(name string) *tenantQueue {
+	tq, ok := l.tenants[name]
+	if !ok {
+		tq = &tenantQueue{}
+		l.tenants[name] = tq
+	}
+	return tq
+}
+
+func (l *ConcurrencyLimiter) weight(tenant string) float64 {
+	if w, ok := l.weights[tenant]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// grant takes a slot for tenant. Caller holds mu.
+func (l *ConcurrencyLimiter) grant(tenant string) {
+	l.inFlight++
+	l.tenant(tenant).inFlight++
+}
+
+// enqueue adds a waiter tagged with its virtual finish time: a tenant's
+// consecutive requests are spaced 1/weight apart, so heavier tenants are
+// dequeued proportionally more often. Caller holds mu.
+func (l *ConcurrencyLimiter) enqueue(tenant string) *waiter {
+	tq := l.tenant(tenant)
+	start := l.virtual
+	if tq.lastTag > start {
+		start = tq.lastTag
+	}
+	w := &waiter{
+		tenant:   tenant,
+		tag:      start + 1/l.weight(tenant),
+		enqueued: time.Now(),
+		ready:    make(chan struct{}),
+	}
+	tq.lastTag = w.tag
+	tq.waiters = append(tq.waiters, w)
+	l.queued++
+	return w
+}
+
+// dequeue removes an abandoned waiter. Caller holds mu.
+func (l *ConcurrencyLimiter) dequeue(w *waiter) {
+	tq := l.tenants[w.tenant]
+	for i, candidate := range tq.waiters {
+		if candidate == w {
+			tq.waiters = append(tq.waiters[:i], tq.waiters[i+1:]...)
+			l.queued--
+			return
+		}
+	}
+}
+
+func (l *ConcurrencyLimiter) releaseFunc(tenant string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.inFlight--
+			l.tenants[tenant].inFlight--
+			l.dispatch()
+		})
+	}
+}
+
+// dispatch hands free slots to the waiters with the smallest virtual finish
+// tags. Caller holds mu.
+func (l *ConcurrencyLimiter) dispatch() {
+	for l.inFlight < l.maxInFlight && l.queued > 0 {
+		var next *tenantQueue
+		for _, tq := range l.tenants {
+			if len(tq.waiters) == 0 {
+				continue
+			}
+			if next == nil || tq.waiters[0].tag < next.waiters[0].tag {
+				next = tq
+			}
+		}
+
+		w := next.waiters[0]
+		next.waiters = next.waiters[1:]
+		l.queued--
+		l.virtual = w.tag
+
+		waited := time.Since(w.enqueued)
+		next.grants++
+		next.totalWait += waited
+		if waited > next.maxWait {
+			next.maxWait = waited
+		}
+
+		l.grant(w.tenant)
+		w.granted = true
+		close(w.ready)
+	}
+}
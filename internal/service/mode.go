@@ -0,0 +1,31 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// validateMode checks the requested analysis mode. An empty mode is the
+// general analysis.
+func validateMode(mode domain.AnalysisMode) error {
+	if mode == "" || mode.IsValid() {
+		return nil
+	}
+	return fmt.Errorf("%w: invalid mode %q (want iac, k8s, docker or ci)", domain.ErrInvalidRequest, mode)
+}
+
+// withResourceEvidence returns result with the resource addresses extracted
+// from the log as evidence, for IaC analyses whose result names none. result
+// itself is never modified because rule results are shared.
+func withResourceEvidence(result *domain.AnalysisResult, extracted map[string]string) *domain.AnalysisResult {
+	resources := extracted[domain.ExtractedResources]
+	if result == nil || len(result.Evidence) > 0 || resources == "" {
+		return result
+	}
+	withEvidence := *result
+	withEvidence.Evidence = strings.Split(resources, ", ")
+	return &withEvidence
+}
@@ -0,0 +1,194 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ai-devops/internal/classifier"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/pkg/sanitizer"
+	"go.uber.org/zap"
+)
+
+// Built-in stages of the analysis pipeline, in the order they run.
+const (
+	// StageSanitize masks secrets in the log and extracts failure details.
+	StageSanitize = "sanitize"
+
+	// StageClassify matches rules and the classifier. A confident match
+	// answers the request; weaker matches become hints for the AI.
+	StageClassify = "classify"
+
+	// StageEnrich builds the prompt log: request metadata, failure
+	// details and rule hints.
+	StageEnrich = "enrich"
+
+	// StageAnalyze answers the request from the cache or the AI, falling
+	// back to rules when the AI fails.
+	StageAnalyze = "analyze"
+
+	// StagePostProcess applies the detail level and command safety,
+	// attaches similar incidents and runbooks, and runs the
+	// post-processors (see PostProcessor).
+	StagePostProcess = "post_process"
+)
+
+// builtinStages lists the built-in stage names in order.
+var builtinStages = []string{StageSanitize, StageClassify, StageEnrich, StageAnalyze, StagePostProcess}
+
+// Analysis is the state of one request as it moves through the pipeline.
+// Stages read and update it.
+type Analysis struct {
+	// Request is the validated request.
+	Request *domain.AnalysisRequest
+
+	// Log is the sanitized log with the details extracted from it; set by
+	// sanitize. Classify adds its rule matches.
+	Log *domain.PreprocessedLog
+
+	// Hints are the rule matches below the confidence threshold; set by
+	// classify and passed to the AI by enrich.
+	Hints []domain.RuleMatch
+
+	// PromptLog is what the AI analyzes; set by enrich.
+	PromptLog string
+
+	// Response answers the request. Once a stage sets it, classify,
+	// enrich and analyze do nothing, so a custom stage can answer a
+	// request without the AI. Custom stages always run.
+	Response *domain.AnalysisResponse
+
+	// raw is the unsanitized log; only sanitize sees it.
+	raw          string
+	started      time.Time
+	contextLines int
+	stats        sanitizer.SanitizationStats
+	placeholders sanitizer.Placeholders
+
+	// confident is the rule match the AI explains in hybrid mode.
+	confident *domain.RuleMatch
+
+	// decision is the classifier's, and promptDomain and ruleIDs shape
+	// the AI request.
+	decision     *classifier.Decision
+	promptDomain string
+	ruleIDs      []string
+
+	// vector is the embedding of the sanitized log, indexed once stored.
+	vector []float32
+}
+
+// Stage is a step of the analysis pipeline. An error ends the analysis
+// with a failed response carrying the error's code.
+type Stage interface {
+	Name() string
+	Run(ctx context.Context, analysis *Analysis) error
+}
+
+// NewStage returns a Stage running run.
+func NewStage(name string, run func(ctx context.Context, analysis *Analysis) error) Stage {
+	return stageFunc{name: name, run: run}
+}
+
+// stageFunc adapts a function to Stage.
+type stageFunc struct {
+	name string
+	run  func(ctx context.Context, analysis *Analysis) error
+}
+
+// Name implements Stage.
+func (s stageFunc) Name() string { return s.name }
+
+// Run implements Stage.
+func (s stageFunc) Run(ctx context.Context, analysis *Analysis) error { return s.run(ctx, analysis) }
+
+// CustomStage inserts Stage into the pipeline after the built-in stage
+// named After. Stages after the same built-in stage run in order.
+type CustomStage struct {
+	After string
+	Stage Stage
+}
+
+// ValidateCustomStages checks that every custom stage follows a built-in
+// stage.
+func ValidateCustomStages(stages []CustomStage) error {
+	for _, custom := range stages {
+		if custom.Stage == nil {
+			return fmt.Errorf("%w: custom stage after %q is nil", domain.ErrInvalidConfig, custom.After)
+		}
+		if !isBuiltinStage(custom.After) {
+			return fmt.Errorf("%w: stage %q follows unknown stage %q (must be one of %v)",
+				domain.ErrInvalidConfig, custom.Stage.Name(), custom.After, builtinStages)
+		}
+	}
+	return nil
+}
+
+// isBuiltinStage reports whether name is a built-in stage.
+func isBuiltinStage(name string) bool {
+	for _, builtin := range builtinStages {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
+
+// newPipeline returns the built-in stages of a with the custom stages
+// inserted, or an error if a custom stage follows an unknown stage; see
+// ValidateCustomStages.
+func (a *Analyzer) newPipeline(custom []CustomStage) ([]Stage, error) {
+	if err := ValidateCustomStages(custom); err != nil {
+		return nil, err
+	}
+	builtins := map[string]Stage{
+		StageSanitize:    NewStage(StageSanitize, a.sanitizeStage),
+		StageClassify:    NewStage(StageClassify, a.classifyStage),
+		StageEnrich:      NewStage(StageEnrich, a.enrichStage),
+		StageAnalyze:     NewStage(StageAnalyze, a.analyzeStage),
+		StagePostProcess: NewStage(StagePostProcess, a.postProcessStage),
+	}
+
+	var stages []Stage
+	for _, name := range builtinStages {
+		stages = append(stages, builtins[name])
+		for _, c := range custom {
+			if c.After == name {
+				stages = append(stages, c.Stage)
+			}
+		}
+	}
+	return stages, nil
+}
+
+// Stages returns the names of the pipeline's stages in order.
+func (a *Analyzer) Stages() []string {
+	names := make([]string, len(a.stages))
+	for i, stage := range a.stages {
+		names[i] = stage.Name()
+	}
+	return names
+}
+
+// runPipeline runs the stages on analysis until one fails. Custom stages
+// are timed in the explanation; built-in stages time their own steps.
+func (a *Analyzer) runPipeline(ctx context.Context, analysis *Analysis) error {
+	exp := explainerFrom(ctx)
+	for _, stage := range a.stages {
+		start := time.Now()
+		if err := stage.Run(ctx, analysis); err != nil {
+			a.loggerFor(ctx).Warn("analysis stopped", zap.String("stage", stage.Name()), zap.Error(err))
+			return err
+		}
+		if !isBuiltinStage(stage.Name()) {
+			exp.stage(stage.Name(), start)
+		}
+	}
+	if analysis.Response == nil {
+		return errors.New("no pipeline stage produced a response")
+	}
+	return nil
+}
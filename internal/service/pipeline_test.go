@@ -0,0 +1,131 @@
+// Package service provides unit tests for the analysis pipeline stages.
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/pkg/sanitizer"
+	"go.uber.org/zap"
+)
+
+func TestAnalyzer_Stages(t *testing.T) {
+	logger := zap.NewNop()
+	noop := NewStage("audit", func(context.Context, *Analysis) error { return nil })
+	a := newTestAnalyzer(t, unusedClient{t}, rules.NewEngine(nil, 0.8, logger), sanitizer.New(10000), AnalyzerConfig{
+		CustomStages: []CustomStage{
+			{After: StageAnalyze, Stage: noop},
+			{After: StageSanitize, Stage: NewStage("tag", func(context.Context, *Analysis) error { return nil })},
+		},
+	}, logger)
+
+	want := []string{StageSanitize, "tag", StageClassify, StageEnrich, StageAnalyze, "audit", StagePostProcess}
+	if got := a.Stages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stages() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateCustomStages(t *testing.T) {
+	noop := NewStage("noop", func(context.Context, *Analysis) error { return nil })
+	tests := []struct {
+		name    string
+		stages  []CustomStage
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"after built-in", []CustomStage{{After: StageEnrich, Stage: noop}}, false},
+		{"after unknown", []CustomStage{{After: "parse", Stage: noop}}, true},
+		{"after custom", []CustomStage{{After: "noop", Stage: noop}}, true},
+		{"nil stage", []CustomStage{{After: StageEnrich}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCustomStages(tt.stages)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateCustomStages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidConfig) {
+				t.Errorf("error %v should wrap ErrInvalidConfig", err)
+			}
+
+			_, err = NewAnalyzer(nil, rules.NewEngine(nil, 0.8, zap.NewNop()), sanitizer.New(10000),
+				AnalyzerConfig{CustomStages: tt.stages}, zap.NewNop())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAnalyzer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_CustomStages(t *testing.T) {
+	logger := zap.NewNop()
+	answer := NewStage("known_failures", func(ctx context.Context, analysis *Analysis) error {
+		if !strings.Contains(analysis.Log.Sanitized, "KNOWN-42") {
+			return nil
+		}
+		analysis.Response = &domain.AnalysisResponse{
+			Success: true,
+			Source:  "known_failures",
+			Result:  &domain.AnalysisResult{ErrorType: "known_failure", Severity: domain.SeverityLow},
+		}
+		return nil
+	})
+	reject := NewStage("reject_drafts", func(ctx context.Context, analysis *Analysis) error {
+		if strings.Contains(analysis.Log.Sanitized, "DRAFT") {
+			return domain.ErrInvalidRequest
+		}
+		return nil
+	})
+	var prompt string
+	inspect := NewStage("inspect", func(ctx context.Context, analysis *Analysis) error {
+		if analysis.Response != nil {
+			return nil
+		}
+		prompt = analysis.PromptLog
+		analysis.Response = &domain.AnalysisResponse{Success: true, Source: "inspect"}
+		return nil
+	})
+	a := newTestAnalyzer(t, unusedClient{t}, rules.NewEngine(nil, 0.8, logger), sanitizer.New(10000), AnalyzerConfig{
+		CustomStages: []CustomStage{
+			{After: StageSanitize, Stage: reject},
+			{After: StageClassify, Stage: answer},
+			{After: StageEnrich, Stage: inspect},
+		},
+	}, logger)
+
+	tests := []struct {
+		name       string
+		log        string
+		wantSource string
+		wantCode   domain.ErrorCode
+	}{
+		{"answered by a custom stage", "job failed: KNOWN-42", "known_failures", ""},
+		{"failed by a custom stage", "DRAFT build", "", domain.CodeInvalidRequest},
+		{"answered after enrich", "segfault in worker password=hunter2", "inspect", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := a.Analyze(context.Background(), &domain.AnalysisRequest{Log: tt.log})
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if resp.Source != tt.wantSource {
+				t.Errorf("Source = %q, want %q", resp.Source, tt.wantSource)
+			}
+			if tt.wantCode != "" && (resp.Error == nil || resp.Error.Code != tt.wantCode) {
+				t.Errorf("Error = %+v, want code %s", resp.Error, tt.wantCode)
+			}
+		})
+	}
+
+	if !strings.Contains(prompt, "segfault in worker") || strings.Contains(prompt, "hunter2") {
+		t.Errorf("PromptLog = %q, want the sanitized log", prompt)
+	}
+}
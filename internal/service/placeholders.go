@@ -0,0 +1,42 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/pkg/sanitizer"
+)
+
+// restorePlaceholders returns a copy of result with sanitizer placeholders in
+// its free-text fields replaced by the original values. result itself may be
+// a shared rule or cached result and is never modified.
+func restorePlaceholders(result *domain.AnalysisResult, placeholders sanitizer.Placeholders) *domain.AnalysisResult {
+	if result == nil || len(placeholders) == 0 {
+		return result
+	}
+
+	restored := *result
+	restored.RootCause = placeholders.Restore(result.RootCause)
+	restored.Explanation = placeholders.Restore(result.Explanation)
+	restored.SuggestedActions = restoreAll(result.SuggestedActions, placeholders)
+	restored.PreventionTips = restoreAll(result.PreventionTips, placeholders)
+	if result.Commands != nil {
+		restored.Commands = make([]domain.Command, len(result.Commands))
+		for i, c := range result.Commands {
+			c.Command = placeholders.Restore(c.Command)
+			c.Description = placeholders.Restore(c.Description)
+			restored.Commands[i] = c
+		}
+	}
+	return &restored
+}
+
+func restoreAll(items []string, placeholders sanitizer.Placeholders) []string {
+	if items == nil {
+		return nil
+	}
+	restored := make([]string, len(items))
+	for i, item := range items {
+		restored[i] = placeholders.Restore(item)
+	}
+	return restored
+}
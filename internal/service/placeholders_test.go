@@ -0,0 +1,62 @@
+// Package service provides unit tests for reversible sanitization.
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/pkg/sanitizer"
+	"go.uber.org/zap"
+)
+
+// echoHostClient records the log it receives and suggests an action and a
+// command that refer to the first host placeholder in it.
+type echoHostClient struct {
+	analyzeOnly
+	seen string
+}
+
+func (c *echoHostClient) Analyze(_ context.Context, log string) (*domain.AnalysisResult, error) {
+	c.seen = log
+	host := regexp.MustCompile(`HOST_\d+`).FindString(log)
+	return &domain.AnalysisResult{
+		ErrorType:        "database_unreachable",
+		Severity:         domain.SeverityHigh,
+		RootCause:        "The database at " + host + " refused connections.",
+		SuggestedActions: []string{"Check that " + host + " is listening"},
+		Commands:         []domain.Command{{Command: "curl -v telnet://" + host}},
+	}, nil
+}
+
+func (c *echoHostClient) HealthCheck(context.Context) error { return nil }
+
+func TestAnalyzer_ReversibleSanitize(t *testing.T) {
+	client := &echoHostClient{}
+	logger := zap.NewNop()
+	a := newTestAnalyzer(t, client, rules.NewEngine(nil, 0.8, logger), sanitizer.New(10000),
+		AnalyzerConfig{ReversibleSanitize: true}, logger)
+
+	resp, err := a.Analyze(context.Background(), &domain.AnalysisRequest{
+		Log: "dial tcp 10.20.0.7:5432: connection reset; password=hunter2secret",
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("Analyze() = %+v, %v", resp, err)
+	}
+
+	if strings.Contains(client.seen, "10.20.0.7") || strings.Contains(client.seen, "hunter2secret") {
+		t.Errorf("AI saw unmasked values:\n%s", client.seen)
+	}
+	if want := "Check that 10.20.0.7:5432 is listening"; resp.Result.SuggestedActions[0] != want {
+		t.Errorf("action = %q, want %q", resp.Result.SuggestedActions[0], want)
+	}
+	if want := "curl -v telnet://10.20.0.7:5432"; resp.Result.Commands[0].Command != want {
+		t.Errorf("command = %q, want %q", resp.Result.Commands[0].Command, want)
+	}
+	if !strings.Contains(resp.Result.RootCause, "10.20.0.7:5432") {
+		t.Errorf("root cause not restored: %q", resp.Result.RootCause)
+	}
+}
@@ -0,0 +1,201 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Built-in post-processors.
+const (
+	// PostProcessTaxonomy maps the result's error type onto the canonical
+	// taxonomy (see domain.NormalizeErrorType), as AI results already are.
+	PostProcessTaxonomy = "taxonomy"
+
+	// PostProcessSeverityPolicy overrides the result's severity according
+	// to AnalyzerConfig.SeverityPolicy.
+	PostProcessSeverityPolicy = "severity_policy"
+
+	// PostProcessStore persists the analysis and indexes it for
+	// similar-incident retrieval, setting the response ID.
+	PostProcessStore = "store"
+
+	// PostProcessNotify sends the notification, escalation and ticket for
+	// the analysis.
+	PostProcessNotify = "notify"
+)
+
+// DefaultPostProcessOrder is the order of the built-in post-processors
+// when AnalyzerConfig.PostProcessOrder is empty. Notifications come after
+// the store so they can link the stored analysis.
+var DefaultPostProcessOrder = []string{PostProcessTaxonomy, PostProcessSeverityPolicy, PostProcessStore, PostProcessNotify}
+
+// PostProcessor adjusts or acts on a response after the pipeline produced
+// it, before it is returned; see AnalyzerConfig.PostProcessors. Errors are
+// logged and never fail the analysis.
+type PostProcessor interface {
+	Name() string
+	Process(ctx context.Context, analysis *Analysis) error
+}
+
+// NewPostProcessor returns a PostProcessor running process.
+func NewPostProcessor(name string, process func(ctx context.Context, analysis *Analysis) error) PostProcessor {
+	return postProcessorFunc{name: name, process: process}
+}
+
+// postProcessorFunc adapts a function to PostProcessor.
+type postProcessorFunc struct {
+	name    string
+	process func(ctx context.Context, analysis *Analysis) error
+}
+
+// Name implements PostProcessor.
+func (p postProcessorFunc) Name() string { return p.name }
+
+// Process implements PostProcessor.
+func (p postProcessorFunc) Process(ctx context.Context, analysis *Analysis) error {
+	return p.process(ctx, analysis)
+}
+
+// isBuiltinPostProcessor reports whether name is a built-in post-processor.
+func isBuiltinPostProcessor(name string) bool {
+	for _, builtin := range DefaultPostProcessOrder {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePostProcessors checks that custom post-processors have unique
+// names that do not shadow the built-ins, and that order names each
+// post-processor at most once.
+func ValidatePostProcessors(order []string, custom []PostProcessor) error {
+	names := make(map[string]bool, len(custom))
+	for _, p := range custom {
+		if p == nil {
+			return fmt.Errorf("%w: custom post-processor is nil", domain.ErrInvalidConfig)
+		}
+		if isBuiltinPostProcessor(p.Name()) || names[p.Name()] {
+			return fmt.Errorf("%w: duplicate post-processor %q", domain.ErrInvalidConfig, p.Name())
+		}
+		names[p.Name()] = true
+	}
+
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		if !isBuiltinPostProcessor(name) && !names[name] {
+			return fmt.Errorf("%w: unknown post-processor %q (built-in: %v)", domain.ErrInvalidConfig, name, DefaultPostProcessOrder)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: post-processor %q is listed twice", domain.ErrInvalidConfig, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// newPostProcessors returns the post-processors of a in the order they
+// run, or the error of an invalid configuration; see ValidatePostProcessors.
+func (a *Analyzer) newPostProcessors(order []string, custom []PostProcessor) ([]PostProcessor, error) {
+	if err := ValidatePostProcessors(order, custom); err != nil {
+		return nil, err
+	}
+	byName := map[string]PostProcessor{
+		PostProcessTaxonomy:       NewPostProcessor(PostProcessTaxonomy, normalizeTaxonomy),
+		PostProcessSeverityPolicy: NewPostProcessor(PostProcessSeverityPolicy, a.severityPolicyProcessor),
+		PostProcessStore:          NewPostProcessor(PostProcessStore, a.storeProcessor),
+		PostProcessNotify:         NewPostProcessor(PostProcessNotify, a.notifyProcessor),
+	}
+	for _, p := range custom {
+		byName[p.Name()] = p
+	}
+
+	if len(order) == 0 {
+		order = append([]string(nil), DefaultPostProcessOrder...)
+		for _, p := range custom {
+			order = append(order, p.Name())
+		}
+	}
+	processors := make([]PostProcessor, len(order))
+	for i, name := range order {
+		processors[i] = byName[name]
+	}
+	return processors, nil
+}
+
+// PostProcessors returns the names of the post-processors in the order
+// they run.
+func (a *Analyzer) PostProcessors() []string {
+	names := make([]string, len(a.postProcessors))
+	for i, p := range a.postProcessors {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// runPostProcessors runs the post-processors on analysis. Custom
+// post-processors are timed in the explanation.
+func (a *Analyzer) runPostProcessors(ctx context.Context, analysis *Analysis) {
+	exp := explainerFrom(ctx)
+	for _, p := range a.postProcessors {
+		start := time.Now()
+		if err := p.Process(ctx, analysis); err != nil {
+			a.loggerFor(ctx).Warn("post-processor failed", zap.String("post_processor", p.Name()), zap.Error(err))
+		}
+		if !isBuiltinPostProcessor(p.Name()) {
+			exp.stage(p.Name(), start)
+		}
+	}
+}
+
+// normalizeTaxonomy maps the result's error type onto the taxonomy.
+func normalizeTaxonomy(ctx context.Context, analysis *Analysis) error {
+	response := analysis.Response
+	if response.Result == nil {
+		return nil
+	}
+	errorType, _ := domain.NormalizeErrorType(response.Result.ErrorType)
+	if errorType == "" || errorType == response.Result.ErrorType {
+		return nil
+	}
+	// Rule results are shared between analyses
+	result := *response.Result
+	result.ErrorType = errorType
+	response.Result = &result
+	return nil
+}
+
+// severityPolicyProcessor applies the severity policy.
+func (a *Analyzer) severityPolicyProcessor(ctx context.Context, analysis *Analysis) error {
+	a.applySeverityPolicy(analysis.Log, analysis.Request.Metadata, analysis.Response)
+	return nil
+}
+
+// storeProcessor persists the analysis and indexes it for similar-incident
+// retrieval.
+func (a *Analyzer) storeProcessor(ctx context.Context, analysis *Analysis) error {
+	response := analysis.Response
+	persistStart := time.Now()
+	a.persist(ctx, analysis.Log.Sanitized, response)
+	if a.store != nil {
+		explainerFrom(ctx).stage("store", persistStart)
+	}
+	if analysis.vector != nil && response.ID != "" {
+		a.incidents.Add(response.ID, analysis.vector)
+	}
+	return nil
+}
+
+// notifyProcessor sends the notification, escalation and ticket.
+func (a *Analyzer) notifyProcessor(ctx context.Context, analysis *Analysis) error {
+	sanitizedLog, response := analysis.Log.Sanitized, analysis.Response
+	a.notify(sanitizedLog, response)
+	a.escalate(sanitizedLog, response)
+	a.fileTicket(ctx, sanitizedLog, analysis.Request.Ticket, response)
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package service provides unit tests for the post-processors.
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/pkg/sanitizer"
+	"go.uber.org/zap"
+)
+
+func TestValidatePostProcessors(t *testing.T) {
+	noop := NewPostProcessor("audit", func(context.Context, *Analysis) error { return nil })
+	tests := []struct {
+		name    string
+		order   []string
+		custom  []PostProcessor
+		wantErr bool
+	}{
+		{"defaults", nil, nil, false},
+		{"reordered built-ins", []string{PostProcessSeverityPolicy, PostProcessTaxonomy}, nil, false},
+		{"custom in order", []string{PostProcessTaxonomy, "audit"}, []PostProcessor{noop}, false},
+		{"unknown name", []string{"enrich"}, nil, true},
+		{"listed twice", []string{PostProcessStore, PostProcessStore}, nil, true},
+		{"shadows built-in", nil, []PostProcessor{NewPostProcessor(PostProcessNotify, nil)}, true},
+		{"duplicate custom", nil, []PostProcessor{noop, noop}, true},
+		{"nil custom", nil, []PostProcessor{nil}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePostProcessors(tt.order, tt.custom)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidatePostProcessors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidConfig) {
+				t.Errorf("error %v should wrap ErrInvalidConfig", err)
+			}
+
+			_, err = NewAnalyzer(nil, rules.NewEngine(nil, 0.8, zap.NewNop()), sanitizer.New(10000),
+				AnalyzerConfig{PostProcessOrder: tt.order, PostProcessors: tt.custom}, zap.NewNop())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAnalyzer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_PostProcessors(t *testing.T) {
+	logger := zap.NewNop()
+	oom := &rules.Rule{
+		ID:         "oom",
+		Keywords:   []string{"OOMKilled"},
+		Confidence: 0.95,
+		Result:     &domain.AnalysisResult{ErrorType: "OOMKilled", Severity: domain.SeverityHigh},
+	}
+	var seen string
+	audit := NewPostProcessor("audit", func(ctx context.Context, analysis *Analysis) error {
+		seen = analysis.Response.Result.ErrorType
+		return errors.New("audit log unavailable")
+	})
+
+	tests := []struct {
+		name          string
+		order         []string
+		wantOrder     []string
+		wantErrorType string
+	}{
+		{
+			name:          "default order",
+			wantOrder:     []string{PostProcessTaxonomy, PostProcessSeverityPolicy, PostProcessStore, PostProcessNotify, "audit"},
+			wantErrorType: "out_of_memory",
+		},
+		{
+			name:          "audit before taxonomy",
+			order:         []string{"audit", PostProcessTaxonomy},
+			wantOrder:     []string{"audit", PostProcessTaxonomy},
+			wantErrorType: "OOMKilled",
+		},
+		{
+			name:          "taxonomy left out",
+			order:         []string{PostProcessStore, "audit"},
+			wantOrder:     []string{PostProcessStore, "audit"},
+			wantErrorType: "OOMKilled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAnalyzer(t, unusedClient{t}, rules.NewEngine([]*rules.Rule{oom}, 0.8, logger), sanitizer.New(10000),
+				AnalyzerConfig{EnableRules: true, PostProcessors: []PostProcessor{audit}, PostProcessOrder: tt.order}, logger)
+			if got := a.PostProcessors(); !reflect.DeepEqual(got, tt.wantOrder) {
+				t.Errorf("PostProcessors() = %v, want %v", got, tt.wantOrder)
+			}
+
+			seen = ""
+			resp, err := a.Analyze(context.Background(), &domain.AnalysisRequest{Log: "container OOMKilled"})
+			if err != nil || !resp.Success {
+				t.Fatalf("Analyze() = %+v, %v", resp, err)
+			}
+			if seen != tt.wantErrorType {
+				t.Errorf("audit saw error type %q, want %q", seen, tt.wantErrorType)
+			}
+		})
+	}
+
+	if oom.Result.ErrorType != "OOMKilled" {
+		t.Errorf("rule result changed to %q", oom.Result.ErrorType)
+	}
+}
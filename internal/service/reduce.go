@@ -0,0 +1,141 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// reductionLevel describes one progressively more aggressive log reduction
+// applied after a provider rejects a prompt as too long.
+type reductionLevel struct {
+	// contextLines is the number of lines kept around each error line.
+	contextLines int
+
+	// maxFraction caps the reduced log relative to the original size.
+	maxFraction float64
+}
+
+// reductionLevels are tried in order while the provider keeps reporting a
+// context-length error.
+var reductionLevels = []reductionLevel{
+	{contextLines: 10, maxFraction: 0.5},
+	{contextLines: 3, maxFraction: 0.2},
+}
+
+// errorLinePattern matches lines likely to describe the failure.
+var errorLinePattern = regexp.MustCompile(`(?i)\b(error|err|fail(ed|ure)?|fatal|panic|exception|traceback|denied|refused|killed|timeout|timed out)\b`)
+
+// digitsPattern normalizes numbers when comparing lines for repetition.
+var digitsPattern = regexp.MustCompile(`\d+`)
+
+// analyzeWithRecovery calls the AI and, if the provider rejects the prompt
+// as exceeding its context window, retries with increasingly reduced logs.
+// The returned reduction is nil when the original log was used.
+func analyzeWithRecovery(ctx context.Context, limiter *ConcurrencyLimiter, client ai.Client, log string, logger *zap.Logger) (*domain.AnalysisResult, *domain.LogReduction, error) {
+	result, err := analyzeWithLimit(ctx, limiter, client, log)
+	if err == nil || !errors.Is(err, domain.ErrContextLengthExceeded) {
+		return result, nil, err
+	}
+
+	for i, level := range reductionLevels {
+		reduced := reduceLog(log, level)
+		reduction := &domain.LogReduction{
+			Level:         i + 1,
+			OriginalBytes: len(log),
+			ReducedBytes:  len(reduced),
+			ContextLines:  level.contextLines,
+		}
+		logger.Warn("context length exceeded, retrying with reduced log",
+			zap.Int("level", reduction.Level),
+			zap.Int("original_bytes", reduction.OriginalBytes),
+			zap.Int("reduced_bytes", reduction.ReducedBytes),
+		)
+
+		result, err = analyzeWithLimit(ctx, limiter, client, reduced)
+		if err == nil {
+			return result, reduction, nil
+		}
+		if !errors.Is(err, domain.ErrContextLengthExceeded) {
+			return nil, nil, err
+		}
+	}
+
+	return nil, nil, err
+}
+
+// reduceLog shrinks a log for a smaller context window: consecutive repeated
+// lines are collapsed, only windows around error lines (and the log tail)
+// are kept, and the result is capped at level.maxFraction of the original.
+func reduceLog(log string, level reductionLevel) string {
+	lines := collapseRepeats(strings.Split(log, "\n"))
+
+	keep := make([]bool, len(lines))
+	mark := func(center int) {
+		for i := center - level.contextLines; i <= center+level.contextLines; i++ {
+			if i >= 0 && i < len(lines) {
+				keep[i] = true
+			}
+		}
+	}
+	for i, line := range lines {
+		if errorLinePattern.MatchString(line) {
+			mark(i)
+		}
+	}
+	// The end of a log usually holds the final failure; always keep it.
+	for i := len(lines) - 1 - level.contextLines; i < len(lines); i++ {
+		if i >= 0 {
+			keep[i] = true
+		}
+	}
+
+	var b strings.Builder
+	omitted := 0
+	for i, line := range lines {
+		if !keep[i] {
+			omitted++
+			continue
+		}
+		if omitted > 0 {
+			fmt.Fprintf(&b, "... [%d lines omitted] ...\n", omitted)
+			omitted = 0
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	reduced := strings.TrimSuffix(b.String(), "\n")
+
+	// Keep the tail if the windows alone are still too large.
+	limit := int(float64(len(log)) * level.maxFraction)
+	if len(reduced) > limit {
+		reduced = "... [truncated] ...\n" + reduced[len(reduced)-limit:]
+	}
+	return reduced
+}
+
+// collapseRepeats replaces runs of lines that differ only in numbers
+// (timestamps, counters) with the first line and a repeat count.
+func collapseRepeats(lines []string) []string {
+	var out []string
+	for i := 0; i < len(lines); {
+		key := digitsPattern.ReplaceAllString(lines[i], "#")
+		j := i + 1
+		for j < len(lines) && digitsPattern.ReplaceAllString(lines[j], "#") == key {
+			j++
+		}
+		out = append(out, lines[i])
+		if n := j - i - 1; n > 0 {
+			out = append(out, fmt.Sprintf("... [previous line repeated %d more times] ...", n))
+		}
+		i = j
+	}
+	return out
+}
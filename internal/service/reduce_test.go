@@ -0,0 +1,92 @@
+// Package service provides unit tests for context-length recovery.
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// lengthLimitedClient rejects logs longer than maxLen with a context-length error.
+type lengthLimitedClient struct {
+	analyzeOnly
+	maxLen int
+	calls  int
+}
+
+func (c *lengthLimitedClient) Analyze(_ context.Context, log string) (*domain.AnalysisResult, error) {
+	c.calls++
+	if len(log) > c.maxLen {
+		return nil, domain.WrapError("context_length_exceeded", &domain.ProviderError{
+			Provider: "openai",
+			Kind:     domain.ErrContextLengthExceeded,
+		}, false)
+	}
+	return &domain.AnalysisResult{ErrorType: "oom", Severity: domain.SeverityHigh}, nil
+}
+
+func (c *lengthLimitedClient) HealthCheck(context.Context) error { return nil }
+
+func testLog() string {
+	var b strings.Builder
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&b, "step %d: downloading layer sha256:%d\n", i, i*7)
+	}
+	b.WriteString("INFO compiling module a\n")
+	b.WriteString("FATAL: container killed: out of memory\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&b, "cleanup task %d done in %dms\n", i, i)
+	}
+	return b.String()
+}
+
+func TestReduceLog(t *testing.T) {
+	log := testLog()
+	reduced := reduceLog(log, reductionLevels[1])
+
+	if len(reduced) >= len(log)/4 {
+		t.Errorf("reduced size %d not much smaller than original %d", len(reduced), len(log))
+	}
+	if !strings.Contains(reduced, "FATAL: container killed: out of memory") {
+		t.Error("reduced log lost the error line")
+	}
+	if !strings.Contains(reduced, "repeated") {
+		t.Error("expected repeated lines to be collapsed")
+	}
+}
+
+func TestAnalyzeWithRecovery(t *testing.T) {
+	log := testLog()
+
+	tests := []struct {
+		name          string
+		maxLen        int
+		wantErr       bool
+		wantReduction bool
+	}{
+		{"fits without reduction", len(log), false, false},
+		{"recovers with reduction", len(log) / 3, false, true},
+		{"gives up when nothing fits", 10, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &lengthLimitedClient{maxLen: tt.maxLen}
+			result, reduction, err := analyzeWithRecovery(context.Background(), nil, client, log, zap.NewNop())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && result == nil {
+				t.Fatal("expected result")
+			}
+			if (reduction != nil) != tt.wantReduction {
+				t.Errorf("reduction = %+v, want present=%v", reduction, tt.wantReduction)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/knowledge"
+)
+
+// runbooks returns the organization runbooks for response's result,
+// matched by its error type and tags: the taxonomy category and
+// subcategory of the error type and the tags of the rules that produced it.
+func (a *Analyzer) runbooks(ctx context.Context, response *domain.AnalysisResponse) []domain.Runbook {
+	if a.knowledge == nil || response.Result == nil {
+		return nil
+	}
+
+	start := time.Now()
+	defer explainerFrom(ctx).stage("runbooks", start)
+
+	q := knowledge.Query{ErrorType: response.Result.ErrorType}
+	if info, ok := domain.LookupErrorType(response.Result.ErrorType); ok {
+		q.Tags = append(q.Tags, info.Category, info.Subcategory)
+	}
+	ruleIDs := make(map[string]bool)
+	// Rule sources are "rules:<id>" and "rules_degraded:<id>"
+	if kind, id, ok := strings.Cut(response.Source, ":"); ok && strings.HasPrefix(kind, "rules") {
+		ruleIDs[id] = true
+	}
+	if response.Metadata != nil {
+		for _, id := range response.Metadata.RuleIDs {
+			ruleIDs[id] = true
+		}
+	}
+	if ruleEngine := a.rulesFor(ctx); len(ruleIDs) > 0 && ruleEngine != nil {
+		for _, rule := range ruleEngine.Rules() {
+			if ruleIDs[rule.ID] {
+				q.Tags = append(q.Tags, rule.Tags...)
+			}
+		}
+	}
+	return a.knowledge.Find(ctx, q)
+}
@@ -0,0 +1,33 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/safety"
+	"go.uber.org/zap"
+)
+
+// applySafety classifies the suggested actions and commands of response's
+// result and, if block is set, removes the destructive ones. The result is
+// copied because rule and cached results are shared between requests.
+func applySafety(response *domain.AnalysisResponse, block bool, logger *zap.Logger) {
+	if response.Result == nil {
+		return
+	}
+	response.Result = safety.Annotate(response.Result)
+	if !block {
+		return
+	}
+
+	withheld := safety.WithholdDestructive(response.Result)
+	if withheld == 0 {
+		return
+	}
+	logger.Info("destructive remediation withheld by policy",
+		zap.Int("withheld", withheld),
+	)
+	if response.Metadata == nil {
+		response.Metadata = &domain.ResponseMetadata{}
+	}
+	response.Metadata.WithheldDestructive = withheld
+}
@@ -0,0 +1,35 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// applySeverityPolicy sets the severity of response's result from the first
+// matching severity override. The result is copied because rule and cached
+// results are shared between requests.
+func (a *Analyzer) applySeverityPolicy(pre *domain.PreprocessedLog, meta *domain.LogMetadata, response *domain.AnalysisResponse) {
+	if a.severityPolicy == nil || response.Result == nil {
+		return
+	}
+	severity, name, ok := a.severityPolicy.Apply(pre, meta, response.Result, response.Source)
+	if !ok || severity == response.Result.Severity {
+		return
+	}
+
+	a.logger.Debug("severity overridden by policy",
+		zap.String("override", name),
+		zap.String("from", string(response.Result.Severity)),
+		zap.String("to", string(severity)),
+	)
+	if response.Metadata == nil {
+		response.Metadata = &domain.ResponseMetadata{}
+	}
+	response.Metadata.SeverityOverride = name
+	response.Metadata.OriginalSeverity = response.Result.Severity
+
+	result := *response.Result
+	result.Severity = severity
+	response.Result = &result
+}
@@ -0,0 +1,92 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// shadowEvalTimeout bounds a background shadow AI evaluation.
+const shadowEvalTimeout = 60 * time.Second
+
+// maybeShadowEvaluate samples rule-based results and re-analyzes them with the
+// AI in the background. The outcome is only used to measure agreement; the
+// caller always receives the rule result.
+func (a *Analyzer) maybeShadowEvaluate(log string, match *domain.RuleMatch) {
+	if a.rulesOnly || a.shadowSampleRate <= 0 || rand.Float64() >= a.shadowSampleRate || a.meter.Exceeded() {
+		return
+	}
+
+	// Shadow evaluation never queues behind live traffic.
+	release, ok := a.limiter.TryAcquire()
+	if !ok {
+		return
+	}
+
+	go func() {
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shadowEvalTimeout)
+		defer cancel()
+
+		aiResult, err := a.aiClient.Analyze(ctx, log)
+		if err != nil {
+			a.loggerFor(ctx).Debug("shadow evaluation failed",
+				zap.String("rule_id", match.RuleID),
+				zap.Error(err),
+			)
+			return
+		}
+		a.meter.Record(aiResult.Usage)
+
+		agreed := resultsAgree(match.Result, aiResult)
+		a.loggerFor(ctx).Info("shadow evaluation completed",
+			zap.String("rule_id", match.RuleID),
+			zap.String("rule_error_type", match.Result.ErrorType),
+			zap.String("ai_error_type", aiResult.ErrorType),
+			zap.Bool("agreed", agreed),
+		)
+
+		if a.thresholdCtl != nil {
+			a.thresholdCtl.Record(agreed)
+		}
+	}()
+}
+
+// resultsAgree reports whether a rule result and an AI result describe the
+// same problem. Error types agree when they are equal or share a significant
+// word (e.g. "docker_permission_denied" and "permission_denied"); severity
+// must also match.
+func resultsAgree(rule, ai *domain.AnalysisResult) bool {
+	if rule == nil || ai == nil || rule.Severity != ai.Severity {
+		return false
+	}
+
+	ruleType := strings.ToLower(rule.ErrorType)
+	aiType := strings.ToLower(ai.ErrorType)
+	if ruleType == aiType {
+		return true
+	}
+
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(ruleType, isTypeSeparator) {
+		if len(w) > 3 {
+			words[w] = true
+		}
+	}
+	for _, w := range strings.FieldsFunc(aiType, isTypeSeparator) {
+		if words[w] {
+			return true
+		}
+	}
+	return false
+}
+
+func isTypeSeparator(r rune) bool {
+	return r == '_' || r == '-' || r == ' ' || r == '.'
+}
@@ -0,0 +1,94 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/internal/usage"
+	"github.com/ai-devops/pkg/sanitizer"
+	"go.uber.org/zap"
+)
+
+// TenantPolicy overrides the analyzer's dependencies for one tenant. Nil
+// fields keep the analyzer's own.
+type TenantPolicy struct {
+	// AIClient analyzes the tenant's logs with its provider and model.
+	AIClient ai.Client
+
+	// RuleEngine holds the tenant's rule set and confidence threshold.
+	RuleEngine *rules.Engine
+
+	// Sanitizer masks the tenant's logs with its patterns.
+	Sanitizer *sanitizer.Sanitizer
+}
+
+// tenantPolicy returns the policy of the tenant in ctx and whether the
+// tenant has one.
+func (a *Analyzer) tenantPolicy(ctx context.Context) (TenantPolicy, bool) {
+	policy, ok := a.tenants[domain.TenantFromContext(ctx)]
+	return policy, ok
+}
+
+// clientFor returns the AI client of the tenant in ctx.
+func (a *Analyzer) clientFor(ctx context.Context) ai.Client {
+	if policy, _ := a.tenantPolicy(ctx); policy.AIClient != nil {
+		return policy.AIClient
+	}
+	return a.aiClient
+}
+
+// rulesFor returns the rule engine of the tenant in ctx.
+func (a *Analyzer) rulesFor(ctx context.Context) *rules.Engine {
+	if policy, _ := a.tenantPolicy(ctx); policy.RuleEngine != nil {
+		return policy.RuleEngine
+	}
+	return a.ruleEngine
+}
+
+// sanitizerFor returns the sanitizer of the tenant in ctx.
+func (a *Analyzer) sanitizerFor(ctx context.Context) *sanitizer.Sanitizer {
+	if policy, _ := a.tenantPolicy(ctx); policy.Sanitizer != nil {
+		return policy.Sanitizer
+	}
+	return a.sanitizer
+}
+
+// checkTenantQuota returns the failed response when the quota of the
+// tenant in ctx is exhausted and blocks further analyses, and nil
+// otherwise.
+func (a *Analyzer) checkTenantQuota(ctx context.Context) *domain.AnalysisResponse {
+	tenant := domain.TenantFromContext(ctx)
+	if exceeded, block := a.tenantMeter.Exceeded(tenant); exceeded && block {
+		a.loggerFor(ctx).Warn("tenant quota exceeded, rejecting analysis", zap.String("tenant", tenant))
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.ErrorDetailFor(domain.ErrTenantQuotaExceeded),
+			ProcessedAt: time.Now(),
+		}
+	}
+	return nil
+}
+
+// tenantQuotaExceeded reports whether the tenant in ctx has exhausted its
+// quota; its analyses then no longer call the AI.
+func (a *Analyzer) tenantQuotaExceeded(ctx context.Context) bool {
+	exceeded, _ := a.tenantMeter.Exceeded(domain.TenantFromContext(ctx))
+	return exceeded
+}
+
+// recordTenantAI charges an AI call to tenant, with the usage and cost in
+// metadata when the provider reported usage.
+func recordTenantAI(meter *usage.TenantMeter, tenant string, metadata *domain.ResponseMetadata) {
+	var (
+		tokens *domain.TokenUsage
+		cost   float64
+	)
+	if metadata != nil {
+		tokens, cost = metadata.Usage, metadata.EstimatedCostUSD
+	}
+	meter.RecordAI(tenant, tokens, cost)
+}
@@ -0,0 +1,202 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/capture"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/logger"
+	"github.com/ai-devops/internal/terraform"
+	"github.com/ai-devops/internal/usage"
+	"github.com/ai-devops/pkg/sanitizer"
+	"go.uber.org/zap"
+)
+
+// errNoTerraformErrors indicates a Terraform stream had no error diagnostics.
+var errNoTerraformErrors = errors.New("terraform output contains no error diagnostics")
+
+// TerraformAnalyzer analyzes Terraform plan/apply JSON streams.
+// It reconstructs the diagnostics, analyzes them with a Terraform-specialized
+// AI client and reports the offending resource addresses as evidence.
+type TerraformAnalyzer struct {
+	aiClient  ai.Client
+	sanitizer *sanitizer.Sanitizer
+	meter     *usage.Meter
+	tenants   *usage.TenantMeter
+	capture   *capture.Recorder
+	activity  *ai.Activity
+	limiter   *ConcurrencyLimiter
+	language  string
+	block     bool
+	logger    *zap.Logger
+}
+
+// TerraformAnalyzerConfig contains optional collaborators for the
+// TerraformAnalyzer. They are usually shared with the Analyzer.
+type TerraformAnalyzerConfig struct {
+	// Meter records AI token usage and enforces the token budget.
+	Meter *usage.Meter
+
+	// TenantMeter records analyses and token spend per tenant. Without
+	// rules to fall back on, tenants over their quota are always rejected.
+	TenantMeter *usage.TenantMeter
+
+	// Limiter bounds concurrent upstream AI requests.
+	Limiter *ConcurrencyLimiter
+
+	// Capture keeps sampled and rejected AI prompts and responses.
+	Capture *capture.Recorder
+
+	// Activity records successful AI calls for health reporting.
+	Activity *ai.Activity
+
+	// DefaultLanguage is the output language when the request sets none.
+	DefaultLanguage string
+
+	// BlockDestructive removes destructive suggested actions and commands
+	// from results (see AnalyzerConfig.BlockDestructive).
+	BlockDestructive bool
+}
+
+// NewTerraformAnalyzer creates a new TerraformAnalyzer.
+// The aiClient should be configured with a Terraform prompt builder.
+func NewTerraformAnalyzer(
+	aiClient ai.Client,
+	sanitizer *sanitizer.Sanitizer,
+	config TerraformAnalyzerConfig,
+	logger *zap.Logger,
+) *TerraformAnalyzer {
+	return &TerraformAnalyzer{
+		aiClient:  aiClient,
+		sanitizer: sanitizer,
+		meter:     config.Meter,
+		tenants:   config.TenantMeter,
+		capture:   config.Capture,
+		activity:  config.Activity,
+		limiter:   config.Limiter,
+		language:  config.DefaultLanguage,
+		block:     config.BlockDestructive,
+		logger:    logger.Named("terraform_analyzer"),
+	}
+}
+
+// Analyze parses the Terraform JSON stream in req.Log and analyzes its errors.
+func (a *TerraformAnalyzer) Analyze(ctx context.Context, req *domain.AnalysisRequest) (*domain.AnalysisResponse, error) {
+	startTime := time.Now()
+
+	if a.sanitizer.IsEmpty(req.Log) {
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.ErrorDetailFor(domain.ErrEmptyLog),
+			ProcessedAt: time.Now(),
+		}, nil
+	}
+
+	ctx, err := withLanguage(ctx, req.Language, a.language)
+	if err == nil {
+		ctx, err = withDetail(ctx, req.Detail)
+	}
+	if err != nil {
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.ErrorDetailFor(err),
+			ProcessedAt: time.Now(),
+		}, nil
+	}
+
+	report, err := terraform.Parse(req.Log)
+	if err != nil {
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInvalidRequest, err.Error()),
+			ProcessedAt: time.Now(),
+		}, nil
+	}
+
+	if !report.HasErrors() {
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.NewErrorDetail(domain.CodeInvalidRequest, errNoTerraformErrors.Error()),
+			ProcessedAt: time.Now(),
+		}, nil
+	}
+
+	sanitizedLog, _ := a.sanitizer.Sanitize(report.Render())
+	addresses := report.Addresses()
+
+	a.loggerFor(ctx).Debug("terraform diagnostics reconstructed",
+		zap.Int("diagnostics", len(report.Diagnostics)),
+		zap.Int("failed_resources", len(report.FailedResources)),
+		zap.Strings("addresses", addresses),
+	)
+
+	if a.meter.Exceeded() {
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.ErrorDetailFor(domain.ErrBudgetExceeded),
+			ProcessedAt: time.Now(),
+			Metadata:    &domain.ResponseMetadata{Degraded: true},
+		}, nil
+	}
+
+	tenant := domain.TenantFromContext(ctx)
+	if exceeded, _ := a.tenants.Exceeded(tenant); exceeded {
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.ErrorDetailFor(domain.ErrTenantQuotaExceeded),
+			ProcessedAt: time.Now(),
+		}, nil
+	}
+
+	aiStart := time.Now()
+	aiCtx, captured := a.capture.Start(ctx)
+	result, reduction, err := analyzeWithRecovery(aiCtx, a.limiter, a.aiClient, sanitizedLog, a.loggerFor(ctx))
+	domain.OutcomeFromContext(ctx).RecordAILatency(time.Since(aiStart))
+	if err := captured.Finish(); err != nil {
+		a.loggerFor(ctx).Warn("failed to save AI capture", zap.Error(err))
+	}
+	if err != nil {
+		a.loggerFor(ctx).Error("terraform AI analysis failed",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(startTime)),
+		)
+		return &domain.AnalysisResponse{
+			Success:     false,
+			Error:       domain.ErrorDetailFor(err),
+			ProcessedAt: time.Now(),
+		}, nil
+	}
+
+	a.activity.RecordSuccess()
+	result.Evidence = addresses
+
+	usageMeta := usageMetadata(a.meter, result, a.loggerFor(ctx))
+	recordTenantAI(a.tenants, tenant, usageMeta)
+	a.tenants.RecordAnalysis(tenant)
+	metadata := withReduction(usageMeta, reduction)
+
+	a.loggerFor(ctx).Info("terraform analysis completed",
+		zap.String("error_type", result.ErrorType),
+		zap.String("severity", string(result.Severity)),
+		zap.Duration("duration", time.Since(startTime)),
+	)
+
+	response := &domain.AnalysisResponse{
+		Success:     true,
+		Result:      result.ForDetail(req.Detail),
+		Source:      "ai:terraform",
+		ProcessedAt: time.Now(),
+		Metadata:    metadata,
+	}
+	applySafety(response, a.block, a.logger)
+	return response, nil
+}
+
+// loggerFor returns the analyzer's logger with the request ID in ctx.
+func (a *TerraformAnalyzer) loggerFor(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, a.logger)
+}
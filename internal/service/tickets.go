@@ -0,0 +1,47 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ai-devops/internal/cache"
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ticketTimeout bounds filing an issue tracker ticket.
+const ticketTimeout = 15 * time.Second
+
+// fileTicket opens or updates the issue tracker ticket for a successful
+// analysis. Requested tickets are filed before responding so the response
+// can link them; automatic ones are filed in the background like
+// notifications.
+func (a *Analyzer) fileTicket(ctx context.Context, sanitizedLog string, requested *bool, response *domain.AnalysisResponse) {
+	if !response.Success || response.Result == nil || !a.tickets.Wants(response.Result.Severity, requested) {
+		return
+	}
+	fingerprint := cache.Fingerprint(sanitizedLog)
+
+	if requested != nil {
+		ctx, cancel := context.WithTimeout(ctx, ticketTimeout)
+		defer cancel()
+		ticket, err := a.tickets.File(ctx, fingerprint, response)
+		if err != nil {
+			a.loggerFor(ctx).Warn("failed to file ticket", zap.Error(err))
+			return
+		}
+		response.Ticket = ticket
+		return
+	}
+
+	// The caller keeps changing response (restored placeholders, explain)
+	snapshot := *response
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), ticketTimeout)
+		defer cancel()
+		if _, err := a.tickets.File(ctx, fingerprint, &snapshot); err != nil {
+			a.loggerFor(ctx).Warn("failed to file ticket", zap.Error(err))
+		}
+	}()
+}
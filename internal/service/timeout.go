@@ -0,0 +1,28 @@
+// Package service contains the business logic layer.
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// withTimeout bounds ctx by the request's timeout in milliseconds, capped by
+// max when max is positive. A zero timeout leaves ctx unchanged.
+func withTimeout(ctx context.Context, timeoutMS int, max time.Duration) (context.Context, context.CancelFunc, error) {
+	if timeoutMS < 0 {
+		return ctx, func() {}, fmt.Errorf("%w: timeout_ms must not be negative", domain.ErrInvalidRequest)
+	}
+	if timeoutMS == 0 {
+		return ctx, func() {}, nil
+	}
+
+	timeout := time.Duration(timeoutMS) * time.Millisecond
+	if max > 0 && timeout > max {
+		timeout = max
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
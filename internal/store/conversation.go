@@ -0,0 +1,99 @@
+// Package store defines persistence for analyses and feedback.
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// ConversationStore persists troubleshooting conversations for a limited
+// time. Implementations must be safe for concurrent use.
+type ConversationStore interface {
+	// SaveConversation stores a conversation, assigning ID and CreatedAt if
+	// empty, and extends its expiry by the store's TTL.
+	SaveConversation(ctx context.Context, conversation *domain.Conversation) error
+
+	// GetConversation returns the conversation with id, or ErrNotFound if
+	// it does not exist or has expired.
+	GetConversation(ctx context.Context, id string) (*domain.Conversation, error)
+
+	// DeleteConversation removes a conversation. Deleting a missing
+	// conversation is not an error.
+	DeleteConversation(ctx context.Context, id string) error
+}
+
+// MemoryConversationStore is a thread-safe in-memory ConversationStore.
+// Expired conversations are dropped on the next save.
+type MemoryConversationStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu            sync.Mutex
+	conversations map[string]*domain.Conversation
+}
+
+// NewMemoryConversationStore creates an in-memory conversation store that
+// keeps conversations for ttl after their last save. ttl <= 0 keeps them
+// until deleted.
+func NewMemoryConversationStore(ttl time.Duration) *MemoryConversationStore {
+	return &MemoryConversationStore{
+		ttl:           ttl,
+		now:           time.Now,
+		conversations: make(map[string]*domain.Conversation),
+	}
+}
+
+// SaveConversation implements ConversationStore.
+func (s *MemoryConversationStore) SaveConversation(_ context.Context, conversation *domain.Conversation) error {
+	if conversation.ID == "" {
+		id, err := NewID()
+		if err != nil {
+			return err
+		}
+		conversation.ID = id
+	}
+	now := s.now()
+	if conversation.CreatedAt.IsZero() {
+		conversation.CreatedAt = now
+	}
+	conversation.UpdatedAt = now
+	if s.ttl > 0 {
+		conversation.ExpiresAt = now.Add(s.ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, c := range s.conversations {
+		if c.Expired(now) {
+			delete(s.conversations, id)
+		}
+	}
+	s.conversations[conversation.ID] = conversation
+	return nil
+}
+
+// GetConversation implements ConversationStore.
+func (s *MemoryConversationStore) GetConversation(_ context.Context, id string) (*domain.Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation, ok := s.conversations[id]
+	if !ok || conversation.Expired(s.now()) {
+		return nil, fmt.Errorf("%w: conversation %s", ErrNotFound, id)
+	}
+	return conversation, nil
+}
+
+// DeleteConversation implements ConversationStore.
+func (s *MemoryConversationStore) DeleteConversation(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.conversations, id)
+	return nil
+}
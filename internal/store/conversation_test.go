@@ -0,0 +1,64 @@
+// Package store provides unit tests for the in-memory conversation store.
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestMemoryConversationStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryConversationStore(time.Hour)
+	s.now = func() time.Time { return now }
+
+	conversation := &domain.Conversation{}
+	conversation.AddAnalysis("npm ERR! code ERESOLVE", &domain.AnalysisResult{ErrorType: "dependency_conflict"})
+	conversation.AddMessage(domain.ChatRoleUser, "Which package pulls in react 17?")
+	if err := s.SaveConversation(ctx, conversation); err != nil {
+		t.Fatalf("SaveConversation() error: %v", err)
+	}
+	if conversation.ID == "" {
+		t.Fatal("expected ID to be assigned")
+	}
+	if want := now.Add(time.Hour); !conversation.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", conversation.ExpiresAt, want)
+	}
+
+	// Saving again extends the expiry
+	now = now.Add(50 * time.Minute)
+	if err := s.SaveConversation(ctx, conversation); err != nil {
+		t.Fatalf("SaveConversation() error: %v", err)
+	}
+	now = now.Add(50 * time.Minute)
+	got, err := s.GetConversation(ctx, conversation.ID)
+	if err != nil {
+		t.Fatalf("GetConversation() error: %v", err)
+	}
+	if len(got.Logs) != 1 || len(got.Results) != 1 || len(got.Messages) != 1 {
+		t.Errorf("GetConversation() = %+v, want one log, result and message", got)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := s.GetConversation(ctx, conversation.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetConversation(expired) error = %v, want ErrNotFound", err)
+	}
+
+	other := &domain.Conversation{}
+	if err := s.SaveConversation(ctx, other); err != nil {
+		t.Fatalf("SaveConversation() error: %v", err)
+	}
+	if len(s.conversations) != 1 {
+		t.Errorf("store holds %d conversations, want expired ones dropped", len(s.conversations))
+	}
+	if err := s.DeleteConversation(ctx, other.ID); err != nil {
+		t.Fatalf("DeleteConversation() error: %v", err)
+	}
+	if _, err := s.GetConversation(ctx, other.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetConversation(deleted) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package store defines persistence for analyses and feedback.
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// MemoryStore is a thread-safe in-memory Store. It keeps at most maxRecords
+// analyses, dropping the oldest (and their feedback) beyond that.
+type MemoryStore struct {
+	maxRecords int
+
+	mu       sync.RWMutex
+	order    []string
+	analyses map[string]*domain.AnalysisRecord
+	feedback map[string][]*domain.Feedback
+}
+
+// NewMemoryStore creates an in-memory store. maxRecords <= 0 means unbounded.
+func NewMemoryStore(maxRecords int) *MemoryStore {
+	return &MemoryStore{
+		maxRecords: maxRecords,
+		analyses:   make(map[string]*domain.AnalysisRecord),
+		feedback:   make(map[string][]*domain.Feedback),
+	}
+}
+
+// SaveAnalysis implements Store.
+func (s *MemoryStore) SaveAnalysis(_ context.Context, record *domain.AnalysisRecord) error {
+	if record.ID == "" {
+		id, err := NewID()
+		if err != nil {
+			return err
+		}
+		record.ID = id
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.analyses[record.ID]; !exists {
+		s.order = append(s.order, record.ID)
+	}
+	s.analyses[record.ID] = record
+
+	if s.maxRecords > 0 && len(s.order) > s.maxRecords {
+		oldest := s.order[0]
+		s.order = s.order[1:]
+		delete(s.analyses, oldest)
+		delete(s.feedback, oldest)
+	}
+
+	return nil
+}
+
+// GetAnalysis implements Store.
+func (s *MemoryStore) GetAnalysis(_ context.Context, id string) (*domain.AnalysisRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.analyses[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: analysis %s", ErrNotFound, id)
+	}
+	return record, nil
+}
+
+// ListAnalyses implements Store.
+func (s *MemoryStore) ListAnalyses(_ context.Context, opts ListOptions) ([]*domain.AnalysisRecord, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []*domain.AnalysisRecord
+	skipped := 0
+	for i := len(s.order) - 1; i >= 0 && len(records) < limit; i-- {
+		record := s.analyses[s.order[i]]
+		if opts.ErrorType != "" && (record.Result == nil || record.Result.ErrorType != opts.ErrorType) {
+			continue
+		}
+		if !opts.Since.IsZero() && record.CreatedAt.Before(opts.Since) {
+			continue
+		}
+		if skipped < opts.Offset {
+			skipped++
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// SaveFeedback implements Store.
+func (s *MemoryStore) SaveFeedback(_ context.Context, feedback *domain.Feedback) error {
+	if feedback.CreatedAt.IsZero() {
+		feedback.CreatedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.analyses[feedback.AnalysisID]; !ok {
+		return fmt.Errorf("%w: analysis %s", ErrNotFound, feedback.AnalysisID)
+	}
+	s.feedback[feedback.AnalysisID] = append(s.feedback[feedback.AnalysisID], feedback)
+	return nil
+}
+
+// ListFeedback implements Store.
+func (s *MemoryStore) ListFeedback(_ context.Context, analysisID string) ([]*domain.Feedback, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.feedback[analysisID]
+	feedback := make([]*domain.Feedback, len(entries))
+	copy(feedback, entries)
+	return feedback, nil
+}
+
+// MarkStale implements Store. Matching records are replaced by stale copies
+// so records already handed out are never mutated.
+func (s *MemoryStore) MarkStale(_ context.Context, filter StaleFilter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	marked := 0
+	for id, record := range s.analyses {
+		if record.Stale || !filter.Matches(record) {
+			continue
+		}
+		stale := *record
+		stale.Stale = true
+		s.analyses[id] = &stale
+		marked++
+	}
+	return marked, nil
+}
+
+// Stats implements Store.
+func (s *MemoryStore) Stats(_ context.Context) (Stats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := Stats{
+		Analyses:         len(s.analyses),
+		AnalysesBySource: make(map[string]int),
+	}
+	for _, record := range s.analyses {
+		stats.AnalysesBySource[record.Source]++
+	}
+	for _, entries := range s.feedback {
+		for _, f := range entries {
+			stats.Feedback++
+			if f.Helpful {
+				stats.HelpfulFeedback++
+			}
+		}
+	}
+	return stats, nil
+}
+
+// NewID returns a random 128-bit hex identifier.
+func NewID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,106 @@
+// Package store provides unit tests for the in-memory store.
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(2)
+
+	var ids []string
+	for _, errorType := range []string{"a", "b", "c"} {
+		record := &domain.AnalysisRecord{
+			Source: "ai",
+			Result: &domain.AnalysisResult{ErrorType: errorType},
+		}
+		if err := s.SaveAnalysis(ctx, record); err != nil {
+			t.Fatalf("SaveAnalysis() error: %v", err)
+		}
+		if record.ID == "" {
+			t.Fatal("expected ID to be assigned")
+		}
+		ids = append(ids, record.ID)
+	}
+
+	// Oldest record is evicted beyond maxRecords.
+	if _, err := s.GetAnalysis(ctx, ids[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAnalysis(oldest) error = %v, want ErrNotFound", err)
+	}
+
+	records, err := s.ListAnalyses(ctx, ListOptions{})
+	if err != nil {
+		t.Fatalf("ListAnalyses() error: %v", err)
+	}
+	if len(records) != 2 || records[0].Result.ErrorType != "c" {
+		t.Errorf("ListAnalyses() should return newest first, got %d records", len(records))
+	}
+
+	filtered, _ := s.ListAnalyses(ctx, ListOptions{ErrorType: "b"})
+	if len(filtered) != 1 || filtered[0].ID != ids[1] {
+		t.Errorf("ListAnalyses(ErrorType=b) returned %d records", len(filtered))
+	}
+
+	if err := s.SaveFeedback(ctx, &domain.Feedback{AnalysisID: ids[2], Helpful: true}); err != nil {
+		t.Errorf("SaveFeedback() error: %v", err)
+	}
+	if err := s.SaveFeedback(ctx, &domain.Feedback{AnalysisID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SaveFeedback(missing) error = %v, want ErrNotFound", err)
+	}
+
+	stats, _ := s.Stats(ctx)
+	if stats.Analyses != 2 || stats.Feedback != 1 || stats.HelpfulFeedback != 1 || stats.AnalysesBySource["ai"] != 2 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestMemoryStore_MarkStale(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(0)
+
+	records := map[string]*domain.AnalysisRecord{
+		"rule":   {Source: "rules:oom"},
+		"hybrid": {Source: "hybrid:oom", Metadata: &domain.ResponseMetadata{PromptVersion: "v1", RuleIDs: []string{"oom"}}},
+		"hinted": {Source: "ai", Metadata: &domain.ResponseMetadata{PromptVersion: "v2", RuleIDs: []string{"oom"}}},
+		"ai_v1":  {Source: "ai", Metadata: &domain.ResponseMetadata{PromptVersion: "v1"}},
+		"other":  {Source: "rules:disk_full"},
+	}
+	for _, record := range records {
+		if err := s.SaveAnalysis(ctx, record); err != nil {
+			t.Fatalf("SaveAnalysis() error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter StaleFilter
+		want   int
+	}{
+		{"by rule", StaleFilter{RuleID: "oom"}, 3},
+		{"by prompt, already stale skipped", StaleFilter{PromptVersion: "v1"}, 1},
+		{"no match", StaleFilter{RuleID: "missing"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := s.MarkStale(ctx, tt.filter)
+			if err != nil || n != tt.want {
+				t.Errorf("MarkStale() = %d, %v; want %d", n, err, tt.want)
+			}
+		})
+	}
+
+	if got, _ := s.GetAnalysis(ctx, records["other"].ID); got.Stale {
+		t.Error("unrelated record should not be stale")
+	}
+	if got, _ := s.GetAnalysis(ctx, records["ai_v1"].ID); !got.Stale {
+		t.Error("record with prompt v1 should be stale")
+	}
+	if records["rule"].Stale {
+		t.Error("MarkStale must not mutate records already handed out")
+	}
+}
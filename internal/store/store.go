@@ -0,0 +1,101 @@
+// Package store defines persistence for analyses and feedback.
+// Feature code depends only on the Store interface; backends (in-memory,
+// SQL, search engines) implement it without leaking their drivers.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// ErrNotFound indicates the requested record does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// ListOptions filters and paginates ListAnalyses. Results are newest first.
+type ListOptions struct {
+	// Limit caps the number of results. Zero uses DefaultListLimit.
+	Limit int
+
+	// Offset skips the first results.
+	Offset int
+
+	// ErrorType, if set, only returns analyses with this error type.
+	ErrorType string
+
+	// Since, if set, only returns analyses created at or after this time.
+	Since time.Time
+}
+
+// StaleFilter selects records for MarkStale. Records matching either set
+// field are marked.
+type StaleFilter struct {
+	// RuleID matches records produced, merged or hinted by this rule.
+	RuleID string
+
+	// PromptVersion matches AI records produced with this prompt version.
+	PromptVersion string
+}
+
+// Matches reports whether record is selected by the filter.
+func (f StaleFilter) Matches(record *domain.AnalysisRecord) bool {
+	return (f.RuleID != "" && record.DependsOnRule(f.RuleID)) ||
+		(f.PromptVersion != "" && record.UsesPrompt(f.PromptVersion))
+}
+
+// DefaultListLimit is the page size used when ListOptions.Limit is zero.
+const DefaultListLimit = 50
+
+// Stats summarizes stored records.
+type Stats struct {
+	Analyses         int            `json:"analyses"`
+	Feedback         int            `json:"feedback"`
+	HelpfulFeedback  int            `json:"helpful_feedback"`
+	AnalysesBySource map[string]int `json:"analyses_by_source"`
+}
+
+// Store persists analyses and feedback. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// SaveAnalysis stores a record, assigning ID and CreatedAt if empty.
+	SaveAnalysis(ctx context.Context, record *domain.AnalysisRecord) error
+
+	// GetAnalysis returns the record with id or ErrNotFound.
+	GetAnalysis(ctx context.Context, id string) (*domain.AnalysisRecord, error)
+
+	// ListAnalyses returns records matching opts, newest first.
+	ListAnalyses(ctx context.Context, opts ListOptions) ([]*domain.AnalysisRecord, error)
+
+	// SaveFeedback stores feedback for an existing analysis or returns ErrNotFound.
+	SaveFeedback(ctx context.Context, feedback *domain.Feedback) error
+
+	// ListFeedback returns the feedback for an analysis, oldest first.
+	ListFeedback(ctx context.Context, analysisID string) ([]*domain.Feedback, error)
+
+	// MarkStale flags the records matching filter as stale and returns how
+	// many were newly marked.
+	MarkStale(ctx context.Context, filter StaleFilter) (int, error)
+
+	// Stats summarizes the stored records.
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// Accepted reports whether an analysis has helpful and no unhelpful feedback.
+func Accepted(ctx context.Context, s Store, analysisID string) (bool, error) {
+	feedback, err := s.ListFeedback(ctx, analysisID)
+	if err != nil {
+		return false, fmt.Errorf("list feedback: %w", err)
+	}
+
+	helpful := false
+	for _, f := range feedback {
+		if !f.Helpful {
+			return false, nil
+		}
+		helpful = true
+	}
+	return helpful, nil
+}
@@ -0,0 +1,81 @@
+// Package store defines persistence for analyses and feedback.
+package store
+
+import (
+	"context"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// TenantStore isolates history per tenant: every operation goes to the
+// store of the tenant in the request context, so tenants never see each
+// other's analyses. Tenants without a store of their own share the one of
+// domain.DefaultTenant.
+type TenantStore struct {
+	stores map[string]Store
+}
+
+// NewTenantStore creates a store with a separate backing store, created by
+// newStore, for each of tenants and for domain.DefaultTenant.
+func NewTenantStore(tenants []string, newStore func(tenant string) Store) *TenantStore {
+	stores := make(map[string]Store, len(tenants)+1)
+	stores[domain.DefaultTenant] = newStore(domain.DefaultTenant)
+	for _, tenant := range tenants {
+		if _, ok := stores[tenant]; !ok {
+			stores[tenant] = newStore(tenant)
+		}
+	}
+	return &TenantStore{stores: stores}
+}
+
+// For returns the store of the tenant in ctx.
+func (s *TenantStore) For(ctx context.Context) Store {
+	if store, ok := s.stores[domain.TenantFromContext(ctx)]; ok {
+		return store
+	}
+	return s.stores[domain.DefaultTenant]
+}
+
+// SaveAnalysis implements Store.
+func (s *TenantStore) SaveAnalysis(ctx context.Context, record *domain.AnalysisRecord) error {
+	return s.For(ctx).SaveAnalysis(ctx, record)
+}
+
+// GetAnalysis implements Store.
+func (s *TenantStore) GetAnalysis(ctx context.Context, id string) (*domain.AnalysisRecord, error) {
+	return s.For(ctx).GetAnalysis(ctx, id)
+}
+
+// ListAnalyses implements Store.
+func (s *TenantStore) ListAnalyses(ctx context.Context, opts ListOptions) ([]*domain.AnalysisRecord, error) {
+	return s.For(ctx).ListAnalyses(ctx, opts)
+}
+
+// SaveFeedback implements Store.
+func (s *TenantStore) SaveFeedback(ctx context.Context, feedback *domain.Feedback) error {
+	return s.For(ctx).SaveFeedback(ctx, feedback)
+}
+
+// ListFeedback implements Store.
+func (s *TenantStore) ListFeedback(ctx context.Context, analysisID string) ([]*domain.Feedback, error) {
+	return s.For(ctx).ListFeedback(ctx, analysisID)
+}
+
+// MarkStale implements Store. Rules and prompts are shared, so records are
+// marked in every tenant's store.
+func (s *TenantStore) MarkStale(ctx context.Context, filter StaleFilter) (int, error) {
+	total := 0
+	for _, store := range s.stores {
+		n, err := store.MarkStale(ctx, filter)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Stats implements Store.
+func (s *TenantStore) Stats(ctx context.Context) (Stats, error) {
+	return s.For(ctx).Stats(ctx)
+}
@@ -0,0 +1,33 @@
+// Package store provides unit tests for per-tenant store isolation.
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestTenantStoreIsolation(t *testing.T) {
+	s := NewTenantStore([]string{"payments"}, func(string) Store { return NewMemoryStore(10) })
+	payments := domain.WithTenant(context.Background(), "payments")
+	other := domain.WithTenant(context.Background(), "search")
+
+	record := &domain.AnalysisRecord{Source: "ai", Result: &domain.AnalysisResult{ErrorType: "a"}}
+	if err := s.SaveAnalysis(payments, record); err != nil {
+		t.Fatalf("SaveAnalysis() error: %v", err)
+	}
+
+	if _, err := s.GetAnalysis(payments, record.ID); err != nil {
+		t.Errorf("GetAnalysis(own tenant) error: %v", err)
+	}
+	if _, err := s.GetAnalysis(other, record.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAnalysis(other tenant) error = %v, want ErrNotFound", err)
+	}
+
+	// Unknown tenants share the default tenant's store
+	if records, _ := s.ListAnalyses(context.Background(), ListOptions{}); len(records) != 0 {
+		t.Errorf("default tenant sees %d records, want 0", len(records))
+	}
+}
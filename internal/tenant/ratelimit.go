@@ -0,0 +1,71 @@
+// Package tenant identifies the teams sharing a deployment and holds their
+// policies: AI provider and model, rule set, masking, rate limit, quota
+// and history isolation.
+package tenant
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter admits requests per tenant with token buckets refilling at
+// each tenant's sustained rate. Tenants without a limit are always
+// admitted.
+type RateLimiter struct {
+	mu      sync.Mutex
+	limits  map[string]RateLimit
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// bucket holds a tenant's remaining burst.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter enforcing limits by tenant ID.
+func NewRateLimiter(limits map[string]RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limits:  limits,
+		buckets: make(map[string]*bucket, len(limits)),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from tenant's bucket. When it is empty it returns
+// false and how long until the next token.
+func (l *RateLimiter) Allow(tenant string) (bool, time.Duration) {
+	limit, ok := l.limits[tenant]
+	if !ok || limit.RequestsPerMinute <= 0 {
+		return true, 0
+	}
+	burst := float64(limit.burst())
+	rate := float64(limit.RequestsPerMinute) / 60
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[tenant]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[tenant] = b
+	}
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// burst returns the configured burst or its default.
+func (r RateLimit) burst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return max(1, r.RequestsPerMinute/10)
+}
@@ -0,0 +1,299 @@
+// Package tenant identifies the teams sharing a deployment and holds their
+// policies: AI provider and model, rule set, masking, rate limit, quota
+// and history isolation.
+package tenant
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/pkg/sanitizer"
+)
+
+// Errors returned by Registry.Resolve.
+var (
+	// ErrUnauthorized reports a request that must but did not present one
+	// of its tenant's API keys.
+	ErrUnauthorized = errors.New("tenant API key required")
+
+	// ErrForbidden reports an X-Tenant-ID header naming a tenant other than
+	// the one the API key belongs to.
+	ErrForbidden = errors.New("API key does not belong to the requested tenant")
+)
+
+// maxIDLength bounds tenant IDs.
+const maxIDLength = 64
+
+// Config lists the tenants of a deployment.
+type Config struct {
+	// Tenants are the known tenants.
+	Tenants []Tenant `json:"tenants"`
+
+	// RequireAPIKey rejects requests that do not present a tenant API key.
+	// Otherwise they are attributed to the tenant named by X-Tenant-ID, or
+	// domain.DefaultTenant, unless that tenant has API keys.
+	RequireAPIKey bool `json:"require_api_key"`
+}
+
+// Tenant is one team's configuration. Unset sections keep the service
+// defaults.
+type Tenant struct {
+	// ID is the tenant name, as sent in X-Tenant-ID.
+	ID string `json:"id"`
+
+	// APIKeys identify the tenant in the X-API-Key header. A tenant with
+	// keys cannot be selected with X-Tenant-ID alone.
+	APIKeys []string `json:"api_keys,omitempty"`
+
+	// AI overrides the provider and model.
+	AI *AIConfig `json:"ai,omitempty"`
+
+	// Rules selects the tenant's rule set.
+	Rules *RulesConfig `json:"rules,omitempty"`
+
+	// Sanitizer replaces the masking configuration (SANITIZER_CONFIG_PATH).
+	Sanitizer *sanitizer.Config `json:"sanitizer,omitempty"`
+
+	// RateLimit bounds the tenant's API requests.
+	RateLimit RateLimit `json:"rate_limit"`
+
+	// Quota bounds the tenant's monthly analyses and AI tokens.
+	Quota *Quota `json:"quota,omitempty"`
+}
+
+// AIConfig overrides the AI settings of a tenant.
+type AIConfig struct {
+	// Provider is "openai" or "gemini".
+	Provider string `json:"provider,omitempty"`
+
+	// Model is the model name.
+	Model string `json:"model,omitempty"`
+
+	// BaseURL is the OpenAI-compatible API base URL.
+	BaseURL string `json:"base_url,omitempty"`
+
+	// APIKeyEnv names the environment variable holding the provider API
+	// key, so keys stay out of the tenants file.
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+}
+
+// RulesConfig selects a tenant's rules.
+type RulesConfig struct {
+	// EnabledCategories and DisabledCategories filter the built-in rules
+	// as RULE_CATEGORIES_ENABLED and RULE_CATEGORIES_DISABLED do.
+	EnabledCategories  []string `json:"enabled_categories,omitempty"`
+	DisabledCategories []string `json:"disabled_categories,omitempty"`
+
+	// ConfidenceThreshold overrides RULE_CONFIDENCE_THRESHOLD when set.
+	ConfidenceThreshold float64 `json:"confidence_threshold,omitempty"`
+}
+
+// RateLimit bounds a tenant's request rate. Zero disables the limit.
+type RateLimit struct {
+	// RequestsPerMinute is the sustained rate.
+	RequestsPerMinute int `json:"requests_per_minute"`
+
+	// Burst is how many requests may arrive at once; it defaults to a
+	// tenth of RequestsPerMinute, at least 1.
+	Burst int `json:"burst"`
+}
+
+// Behaviors of a tenant with an exhausted quota.
+const (
+	// QuotaRulesOnly answers from rules only, as when the service token
+	// budget is exhausted.
+	QuotaRulesOnly = "rules_only"
+
+	// QuotaBlock rejects analyses with TENANT_QUOTA_EXCEEDED.
+	QuotaBlock = "block"
+)
+
+// Quota bounds a tenant's usage per calendar month (UTC). Zero disables a
+// limit.
+type Quota struct {
+	// MonthlyAnalyses bounds the number of analyses.
+	MonthlyAnalyses int `json:"monthly_analyses"`
+
+	// MonthlyTokens bounds the AI tokens spent.
+	MonthlyTokens int `json:"monthly_tokens"`
+
+	// OnExhausted is QuotaRulesOnly (the default) or QuotaBlock.
+	OnExhausted string `json:"on_exhausted,omitempty"`
+}
+
+// LoadConfig reads a JSON tenants file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tenants config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse tenants config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks tenant IDs, keys and limits.
+func (c *Config) Validate() error {
+	ids := make(map[string]bool, len(c.Tenants))
+	keys := make(map[string]string)
+	for _, t := range c.Tenants {
+		if !ValidID(t.ID) {
+			return fmt.Errorf("%w: invalid tenant id %q", domain.ErrInvalidConfig, t.ID)
+		}
+		if ids[t.ID] {
+			return fmt.Errorf("%w: duplicate tenant %q", domain.ErrInvalidConfig, t.ID)
+		}
+		ids[t.ID] = true
+
+		for _, key := range t.APIKeys {
+			if key == "" {
+				return fmt.Errorf("%w: tenant %q has an empty API key", domain.ErrInvalidConfig, t.ID)
+			}
+			if owner, ok := keys[key]; ok {
+				return fmt.Errorf("%w: tenants %q and %q share an API key", domain.ErrInvalidConfig, owner, t.ID)
+			}
+			keys[key] = t.ID
+		}
+		if t.AI != nil && t.AI.Provider != "" && t.AI.Provider != "openai" && t.AI.Provider != "gemini" {
+			return fmt.Errorf("%w: tenant %q: AI provider must be openai or gemini", domain.ErrInvalidConfig, t.ID)
+		}
+		if t.Rules != nil && (t.Rules.ConfidenceThreshold < 0 || t.Rules.ConfidenceThreshold > 1) {
+			return fmt.Errorf("%w: tenant %q: rule confidence threshold must be between 0 and 1", domain.ErrInvalidConfig, t.ID)
+		}
+		if t.RateLimit.RequestsPerMinute < 0 || t.RateLimit.Burst < 0 {
+			return fmt.Errorf("%w: tenant %q: rate limit must not be negative", domain.ErrInvalidConfig, t.ID)
+		}
+		if q := t.Quota; q != nil {
+			if q.MonthlyAnalyses < 0 || q.MonthlyTokens < 0 {
+				return fmt.Errorf("%w: tenant %q: quota must not be negative", domain.ErrInvalidConfig, t.ID)
+			}
+			if q.OnExhausted != "" && q.OnExhausted != QuotaRulesOnly && q.OnExhausted != QuotaBlock {
+				return fmt.Errorf("%w: tenant %q: quota on_exhausted must be rules_only or block", domain.ErrInvalidConfig, t.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidID reports whether id is a usable tenant ID: 1 to 64 characters
+// from [A-Za-z0-9._-].
+func ValidID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Registry resolves requests to tenants and enforces their rate limits.
+//
+// A nil *Registry knows no tenants: requests keep the tenant named by
+// X-Tenant-ID.
+type Registry struct {
+	tenants    map[string]*Tenant
+	keys       map[[sha256.Size]byte]string
+	requireKey bool
+	limiter    *RateLimiter
+}
+
+// NewRegistry validates cfg and indexes its tenants and keys.
+func NewRegistry(cfg *Config) (*Registry, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Registry{
+		tenants:    make(map[string]*Tenant, len(cfg.Tenants)),
+		keys:       make(map[[sha256.Size]byte]string),
+		requireKey: cfg.RequireAPIKey,
+	}
+	limits := make(map[string]RateLimit)
+	for i := range cfg.Tenants {
+		t := &cfg.Tenants[i]
+		r.tenants[t.ID] = t
+		for _, key := range t.APIKeys {
+			r.keys[sha256.Sum256([]byte(key))] = t.ID
+		}
+		if t.RateLimit.RequestsPerMinute > 0 {
+			limits[t.ID] = t.RateLimit
+		}
+	}
+	r.limiter = NewRateLimiter(limits)
+	return r, nil
+}
+
+// Get returns the tenant with id, or nil if it is not configured.
+func (r *Registry) Get(id string) *Tenant {
+	if r == nil {
+		return nil
+	}
+	return r.tenants[id]
+}
+
+// Tenants returns the configured tenants.
+func (r *Registry) Tenants() []*Tenant {
+	if r == nil {
+		return nil
+	}
+	tenants := make([]*Tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		tenants = append(tenants, t)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants
+}
+
+// Resolve returns the tenant of a request from its X-API-Key and
+// X-Tenant-ID headers. Keys are looked up by hash, so lookup time does not
+// depend on how much of a key matches. Unknown keys are ignored, since the
+// header also carries keys for other purposes.
+func (r *Registry) Resolve(apiKey, header string) (string, error) {
+	if r != nil && apiKey != "" {
+		if id, ok := r.keys[sha256.Sum256([]byte(apiKey))]; ok {
+			if header != "" && header != id {
+				return "", ErrForbidden
+			}
+			return id, nil
+		}
+	}
+
+	id := header
+	if id == "" {
+		id = domain.DefaultTenant
+	}
+	if r == nil {
+		return id, nil
+	}
+	if r.requireKey {
+		return "", ErrUnauthorized
+	}
+	if t := r.tenants[id]; t != nil && len(t.APIKeys) > 0 {
+		return "", ErrUnauthorized
+	}
+	return id, nil
+}
+
+// Allow reports whether tenant may make another request now and, if not,
+// how long until it may.
+func (r *Registry) Allow(tenant string) (bool, time.Duration) {
+	if r == nil {
+		return true, 0
+	}
+	return r.limiter.Allow(tenant)
+}
@@ -0,0 +1,122 @@
+// Package tenant provides unit tests for tenant resolution, configuration
+// and rate limiting.
+package tenant
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestRegistryResolve(t *testing.T) {
+	r, err := NewRegistry(&Config{Tenants: []Tenant{
+		{ID: "payments", APIKeys: []string{"pay-key"}},
+		{ID: "search"},
+	}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		header  string
+		want    string
+		wantErr error
+	}{
+		{name: "no headers", want: domain.DefaultTenant},
+		{name: "key", apiKey: "pay-key", want: "payments"},
+		{name: "key and matching header", apiKey: "pay-key", header: "payments", want: "payments"},
+		{name: "key and other header", apiKey: "pay-key", header: "search", wantErr: ErrForbidden},
+		{name: "header of tenant without keys", header: "search", want: "search"},
+		{name: "header of tenant with keys", header: "payments", wantErr: ErrUnauthorized},
+		{name: "unknown key", apiKey: "other", header: "search", want: "search"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(tt.apiKey, tt.header)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryRequireAPIKey(t *testing.T) {
+	r, err := NewRegistry(&Config{
+		Tenants:       []Tenant{{ID: "payments", APIKeys: []string{"pay-key"}}},
+		RequireAPIKey: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error: %v", err)
+	}
+
+	if _, err := r.Resolve("", ""); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Resolve() without key error = %v, want ErrUnauthorized", err)
+	}
+	if got, err := r.Resolve("pay-key", ""); err != nil || got != "payments" {
+		t.Errorf("Resolve() = %q, %v, want payments", got, err)
+	}
+}
+
+func TestNilRegistry(t *testing.T) {
+	var r *Registry
+	if got, err := r.Resolve("key", "team"); err != nil || got != "team" {
+		t.Errorf("Resolve() = %q, %v, want team", got, err)
+	}
+	if ok, _ := r.Allow("team"); !ok {
+		t.Error("Allow() = false, want true")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "invalid id", cfg: Config{Tenants: []Tenant{{ID: "a b"}}}},
+		{name: "duplicate id", cfg: Config{Tenants: []Tenant{{ID: "a"}, {ID: "a"}}}},
+		{name: "shared key", cfg: Config{Tenants: []Tenant{{ID: "a", APIKeys: []string{"k"}}, {ID: "b", APIKeys: []string{"k"}}}}},
+		{name: "unknown provider", cfg: Config{Tenants: []Tenant{{ID: "a", AI: &AIConfig{Provider: "other"}}}}},
+		{name: "negative rate", cfg: Config{Tenants: []Tenant{{ID: "a", RateLimit: RateLimit{RequestsPerMinute: -1}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); !errors.Is(err, domain.ErrInvalidConfig) {
+				t.Errorf("Validate() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(map[string]RateLimit{"a": {RequestsPerMinute: 60, Burst: 2}})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d rejected within burst", i+1)
+		}
+	}
+	ok, retryAfter := l.Allow("a")
+	if ok {
+		t.Fatal("request over burst admitted")
+	}
+	if retryAfter != time.Second {
+		t.Errorf("retryAfter = %v, want 1s", retryAfter)
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("request rejected after refill")
+	}
+	if ok, _ := l.Allow("unlimited"); !ok {
+		t.Error("tenant without limit rejected")
+	}
+}
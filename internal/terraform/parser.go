@@ -0,0 +1,241 @@
+// Package terraform parses Terraform's machine-readable JSON output.
+// Both `terraform plan -json` and `terraform apply -json` emit a stream of
+// newline-delimited JSON messages; this package reconstructs the error
+// diagnostics and failed resources from that stream.
+package terraform
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoMessages indicates the input contained no Terraform JSON messages.
+var ErrNoMessages = errors.New("no terraform JSON messages found")
+
+// message is a single line of Terraform's JSON UI output.
+type message struct {
+	Level      string          `json:"@level"`
+	Message    string          `json:"@message"`
+	Type       string          `json:"type"`
+	Diagnostic *diagnosticJSON `json:"diagnostic,omitempty"`
+	Hook       *hookJSON       `json:"hook,omitempty"`
+}
+
+type diagnosticJSON struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+	Address  string `json:"address"`
+	Range    *struct {
+		Filename string `json:"filename"`
+		Start    struct {
+			Line int `json:"line"`
+		} `json:"start"`
+	} `json:"range,omitempty"`
+	Snippet *struct {
+		Context string `json:"context"`
+		Code    string `json:"code"`
+	} `json:"snippet,omitempty"`
+}
+
+type hookJSON struct {
+	Resource struct {
+		Addr string `json:"addr"`
+	} `json:"resource"`
+	Action string `json:"action"`
+}
+
+// Diagnostic is a reconstructed Terraform diagnostic.
+type Diagnostic struct {
+	// Severity is "error" or "warning".
+	Severity string
+
+	// Summary is the one-line diagnostic summary.
+	Summary string
+
+	// Detail is the extended diagnostic text.
+	Detail string
+
+	// Address is the resource address the diagnostic refers to, if any.
+	Address string
+
+	// Filename and Line locate the offending configuration.
+	Filename string
+	Line     int
+
+	// Snippet is the configuration code the diagnostic points at.
+	Snippet string
+}
+
+// FailedResource is a resource whose apply operation errored.
+type FailedResource struct {
+	// Address is the resource address (e.g. module.vpc.aws_subnet.private[0]).
+	Address string
+
+	// Action is the attempted action (create, update, delete, ...).
+	Action string
+}
+
+// Report is the error-relevant content of a Terraform JSON stream.
+type Report struct {
+	// Diagnostics are all error and warning diagnostics in stream order.
+	Diagnostics []Diagnostic
+
+	// FailedResources are resources reported by apply_errored messages.
+	FailedResources []FailedResource
+
+	// ChangeSummary is the human-readable change summary, if present.
+	ChangeSummary string
+}
+
+// Parse reads a Terraform JSON stream. Lines that are not JSON objects are
+// skipped so that output mixed with shell noise can still be parsed.
+func Parse(stream string) (*Report, error) {
+	report := &Report{}
+	found := false
+
+	scanner := bufio.NewScanner(strings.NewReader(stream))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var msg message
+		if err := json.Unmarshal([]byte(line), &msg); err != nil || msg.Type == "" {
+			continue
+		}
+		found = true
+
+		switch msg.Type {
+		case "diagnostic":
+			if msg.Diagnostic != nil {
+				report.Diagnostics = append(report.Diagnostics, toDiagnostic(msg.Diagnostic))
+			}
+		case "apply_errored":
+			if msg.Hook != nil {
+				report.FailedResources = append(report.FailedResources, FailedResource{
+					Address: msg.Hook.Resource.Addr,
+					Action:  msg.Hook.Action,
+				})
+			}
+		case "change_summary":
+			report.ChangeSummary = msg.Message
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read terraform stream: %w", err)
+	}
+	if !found {
+		return nil, ErrNoMessages
+	}
+
+	return report, nil
+}
+
+func toDiagnostic(d *diagnosticJSON) Diagnostic {
+	diag := Diagnostic{
+		Severity: d.Severity,
+		Summary:  d.Summary,
+		Detail:   d.Detail,
+		Address:  d.Address,
+	}
+	if d.Range != nil {
+		diag.Filename = d.Range.Filename
+		diag.Line = d.Range.Start.Line
+	}
+	if d.Snippet != nil {
+		diag.Snippet = strings.TrimSpace(d.Snippet.Code)
+	}
+	return diag
+}
+
+// HasErrors reports whether the stream contains any error diagnostics or
+// failed resources.
+func (r *Report) HasErrors() bool {
+	if len(r.FailedResources) > 0 {
+		return true
+	}
+	for _, d := range r.Diagnostics {
+		if d.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// Addresses returns the unique resource addresses involved in errors,
+// in the order they first appear.
+func (r *Report) Addresses() []string {
+	seen := make(map[string]bool)
+	var addrs []string
+
+	add := func(addr string) {
+		if addr == "" || seen[addr] {
+			return
+		}
+		seen[addr] = true
+		addrs = append(addrs, addr)
+	}
+
+	for _, d := range r.Diagnostics {
+		if d.Severity == "error" {
+			add(d.Address)
+		}
+	}
+	for _, fr := range r.FailedResources {
+		add(fr.Address)
+	}
+
+	return addrs
+}
+
+// severityLabel capitalizes a diagnostic severity for display.
+func severityLabel(severity string) string {
+	if severity == "" {
+		return "Error"
+	}
+	return strings.ToUpper(severity[:1]) + severity[1:]
+}
+
+// Render reconstructs a plain-text view of the diagnostics, similar to
+// Terraform's human-readable output, suitable for analysis.
+func (r *Report) Render() string {
+	var b strings.Builder
+
+	for _, d := range r.Diagnostics {
+		fmt.Fprintf(&b, "%s: %s\n", severityLabel(d.Severity), d.Summary)
+		if d.Filename != "" {
+			fmt.Fprintf(&b, "  on %s line %d", d.Filename, d.Line)
+			if d.Address != "" {
+				fmt.Fprintf(&b, ", in %s", d.Address)
+			}
+			b.WriteString(":\n")
+		} else if d.Address != "" {
+			fmt.Fprintf(&b, "  with %s\n", d.Address)
+		}
+		if d.Snippet != "" {
+			fmt.Fprintf(&b, "  %s\n", d.Snippet)
+		}
+		if d.Detail != "" {
+			fmt.Fprintf(&b, "\n%s\n", d.Detail)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, fr := range r.FailedResources {
+		fmt.Fprintf(&b, "Failed to %s resource: %s\n", fr.Action, fr.Address)
+	}
+
+	if r.ChangeSummary != "" {
+		fmt.Fprintf(&b, "\n%s\n", r.ChangeSummary)
+	}
+
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,61 @@
+// Package terraform provides unit tests for the JSON stream parser.
+package terraform
+
+import (
+	"strings"
+	"testing"
+)
+
+const applyStream = `{"@level":"info","@message":"Terraform 1.6.0","type":"version","terraform":"1.6.0","ui":"1.2"}
+{"@level":"info","@message":"aws_s3_bucket.logs: Creating...","type":"apply_start","hook":{"resource":{"addr":"aws_s3_bucket.logs"},"action":"create"}}
+{"@level":"error","@message":"aws_s3_bucket.logs: Creation errored after 2s","type":"apply_errored","hook":{"resource":{"addr":"aws_s3_bucket.logs"},"action":"create"}}
+{"@level":"error","@message":"Error: creating S3 Bucket","type":"diagnostic","diagnostic":{"severity":"error","summary":"creating S3 Bucket (logs): BucketAlreadyExists","detail":"","address":"aws_s3_bucket.logs","range":{"filename":"main.tf","start":{"line":12}},"snippet":{"context":"resource \"aws_s3_bucket\" \"logs\"","code":"resource \"aws_s3_bucket\" \"logs\" {"}}}
+{"@level":"warn","@message":"Warning: Deprecated attribute","type":"diagnostic","diagnostic":{"severity":"warning","summary":"Deprecated attribute","detail":"acl is deprecated","address":"aws_s3_bucket.assets"}}
+`
+
+func TestParse(t *testing.T) {
+	report, err := Parse(applyStream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Diagnostics) != 2 {
+		t.Errorf("diagnostics = %d, want 2", len(report.Diagnostics))
+	}
+	if len(report.FailedResources) != 1 {
+		t.Errorf("failed resources = %d, want 1", len(report.FailedResources))
+	}
+	if !report.HasErrors() {
+		t.Error("expected HasErrors() to be true")
+	}
+
+	addrs := report.Addresses()
+	if len(addrs) != 1 || addrs[0] != "aws_s3_bucket.logs" {
+		t.Errorf("Addresses() = %v, want [aws_s3_bucket.logs]", addrs)
+	}
+
+	rendered := report.Render()
+	for _, want := range []string{"Error: creating S3 Bucket", "on main.tf line 12", "Failed to create resource: aws_s3_bucket.logs"} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("Render() missing %q:\n%s", want, rendered)
+		}
+	}
+}
+
+func TestParse_NoMessages(t *testing.T) {
+	if _, err := Parse("plain text\nnot json"); err != ErrNoMessages {
+		t.Errorf("Parse() error = %v, want ErrNoMessages", err)
+	}
+}
+
+func TestParse_WarningsOnly(t *testing.T) {
+	stream := `{"@level":"warn","@message":"Warning","type":"diagnostic","diagnostic":{"severity":"warning","summary":"Deprecated"}}`
+
+	report, err := Parse(stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.HasErrors() {
+		t.Error("expected HasErrors() to be false for warnings only")
+	}
+}
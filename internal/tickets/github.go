@@ -0,0 +1,140 @@
+// Package tickets opens issue tracker tickets (Jira, GitHub issues) for
+// analyzed failures, one open ticket per log fingerprint.
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// DefaultGitHubURL is the GitHub REST API base URL.
+const DefaultGitHubURL = "https://api.github.com"
+
+// GitHubTracker files tickets as issues of a GitHub repository.
+type GitHubTracker struct {
+	baseURL    string
+	token      string
+	repo       string
+	httpClient *http.Client
+}
+
+// NewGitHubTracker creates a tracker for repo ("owner/name") authenticating
+// with a token allowed to write issues. baseURL is usually DefaultGitHubURL
+// (GitHub Enterprise: "https://github.example.com/api/v3").
+func NewGitHubTracker(baseURL, token, repo string, httpClient *http.Client) *GitHubTracker {
+	return &GitHubTracker{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		repo:       repo,
+		httpClient: httpClient,
+	}
+}
+
+// githubIssue is the part of an issue the tracker reads.
+type githubIssue struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Name implements Tracker.
+func (t *GitHubTracker) Name() string { return "github" }
+
+// FindOpen implements Tracker by listing open issues with label.
+func (t *GitHubTracker) FindOpen(ctx context.Context, label string) (*domain.Ticket, error) {
+	params := url.Values{}
+	params.Set("state", "open")
+	params.Set("labels", label)
+	params.Set("per_page", "1")
+
+	var issues []githubIssue
+	if err := t.call(ctx, http.MethodGet, "/repos/"+t.repo+"/issues?"+params.Encode(), nil, &issues); err != nil {
+		return nil, fmt.Errorf("github list issues: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	return t.ticket(issues[0]), nil
+}
+
+// Create implements Tracker.
+func (t *GitHubTracker) Create(ctx context.Context, issue *Issue) (*domain.Ticket, error) {
+	var created githubIssue
+	err := t.call(ctx, http.MethodPost, "/repos/"+t.repo+"/issues", map[string]any{
+		"title":  issue.Title,
+		"body":   issue.Body,
+		"labels": issue.Labels,
+	}, &created)
+	if err != nil {
+		return nil, fmt.Errorf("github create issue: %w", err)
+	}
+	return t.ticket(created), nil
+}
+
+// Comment implements Tracker. key is "owner/name#number".
+func (t *GitHubTracker) Comment(ctx context.Context, key, body string) error {
+	_, number, ok := strings.Cut(key, "#")
+	if !ok {
+		return fmt.Errorf("invalid github issue key %q", key)
+	}
+	if err := t.call(ctx, http.MethodPost, "/repos/"+t.repo+"/issues/"+number+"/comments", map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("github comment: %w", err)
+	}
+	return nil
+}
+
+func (t *GitHubTracker) ticket(issue githubIssue) *domain.Ticket {
+	return &domain.Ticket{
+		Tracker: t.Name(),
+		Key:     t.repo + "#" + strconv.Itoa(issue.Number),
+		URL:     issue.HTMLURL,
+	}
+}
+
+// call sends payload, if set, as JSON and decodes the response into out,
+// if set.
+func (t *GitHubTracker) call(ctx context.Context, method, path string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package tickets opens issue tracker tickets (Jira, GitHub issues) for
+// analyzed failures, one open ticket per log fingerprint.
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// DefaultJiraIssueType is the issue type of new Jira tickets.
+const DefaultJiraIssueType = "Bug"
+
+// JiraTracker files tickets in a Jira project with the REST API v2.
+type JiraTracker struct {
+	baseURL    string
+	email      string
+	token      string
+	project    string
+	issueType  string
+	httpClient *http.Client
+}
+
+// NewJiraTracker creates a tracker for the project with key project on the
+// site at baseURL (e.g. "https://example.atlassian.net"). Requests
+// authenticate with email and an API token; without an email the token is
+// sent as a bearer token (Jira Data Center personal access tokens).
+func NewJiraTracker(baseURL, email, token, project, issueType string, httpClient *http.Client) *JiraTracker {
+	if issueType == "" {
+		issueType = DefaultJiraIssueType
+	}
+	return &JiraTracker{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		email:      email,
+		token:      token,
+		project:    project,
+		issueType:  issueType,
+		httpClient: httpClient,
+	}
+}
+
+// Name implements Tracker.
+func (t *JiraTracker) Name() string { return "jira" }
+
+// FindOpen implements Tracker with a JQL search for unresolved issues
+// carrying label.
+func (t *JiraTracker) FindOpen(ctx context.Context, label string) (*domain.Ticket, error) {
+	jql := fmt.Sprintf("project = %s AND labels = %s AND statusCategory != Done ORDER BY created DESC",
+		strconv.Quote(t.project), strconv.Quote(label))
+	var result struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	err := t.call(ctx, http.MethodPost, "/rest/api/2/search", map[string]any{
+		"jql":        jql,
+		"maxResults": 1,
+		"fields":     []string{"key"},
+	}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("jira search: %w", err)
+	}
+	if len(result.Issues) == 0 {
+		return nil, nil
+	}
+	return t.ticket(result.Issues[0].Key), nil
+}
+
+// Create implements Tracker.
+func (t *JiraTracker) Create(ctx context.Context, issue *Issue) (*domain.Ticket, error) {
+	var result struct {
+		Key string `json:"key"`
+	}
+	err := t.call(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": t.project},
+			"issuetype":   map[string]string{"name": t.issueType},
+			"summary":     issue.Title,
+			"description": issue.Body,
+			"labels":      issue.Labels,
+		},
+	}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("jira create issue: %w", err)
+	}
+	return t.ticket(result.Key), nil
+}
+
+// Comment implements Tracker.
+func (t *JiraTracker) Comment(ctx context.Context, key, body string) error {
+	if err := t.call(ctx, http.MethodPost, "/rest/api/2/issue/"+key+"/comment", map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("jira comment: %w", err)
+	}
+	return nil
+}
+
+func (t *JiraTracker) ticket(key string) *domain.Ticket {
+	return &domain.Ticket{Tracker: t.Name(), Key: key, URL: t.baseURL + "/browse/" + key}
+}
+
+// call sends payload as JSON and decodes the response into out, if set.
+func (t *JiraTracker) call(ctx context.Context, method, path string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if t.email != "" {
+		req.SetBasicAuth(t.email, t.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// Package tickets opens issue tracker tickets (Jira, GitHub issues) for
+// analyzed failures, one open ticket per log fingerprint.
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/render"
+	"go.uber.org/zap"
+)
+
+// fingerprintLabelPrefix prefixes the label that ties a ticket to the log
+// fingerprint it was opened for.
+const fingerprintLabelPrefix = "ai-devops-"
+
+// fingerprintLabelLength is how many fingerprint characters the label keeps.
+const fingerprintLabelLength = 16
+
+// maxTitleLength bounds the root cause quoted in a ticket title.
+const maxTitleLength = 120
+
+// knownTicketTTL is how long a filed ticket is reused without searching the
+// tracker again, which also covers trackers whose search index lags.
+const knownTicketTTL = 10 * time.Minute
+
+// maxKnownTickets bounds the filed-ticket cache.
+const maxKnownTickets = 1024
+
+// maxErrorBody bounds how much of an error response is reported.
+const maxErrorBody = 512
+
+// Issue is a ticket to open.
+type Issue struct {
+	// Title and Body (markdown) describe the failure.
+	Title string
+	Body  string
+
+	// Labels include the fingerprint label used to find the ticket again.
+	Labels []string
+}
+
+// Tracker opens and updates tickets in an issue tracker.
+type Tracker interface {
+	// Name identifies the tracker in tickets and logs.
+	Name() string
+
+	// FindOpen returns the open ticket carrying label, or nil if none.
+	FindOpen(ctx context.Context, label string) (*domain.Ticket, error)
+
+	// Create opens a ticket for issue.
+	Create(ctx context.Context, issue *Issue) (*domain.Ticket, error)
+
+	// Comment adds a markdown comment to the ticket with key.
+	Comment(ctx context.Context, key, body string) error
+}
+
+// knownTicket is a ticket filed recently.
+type knownTicket struct {
+	ticket  domain.Ticket
+	expires time.Time
+}
+
+// Filer files tickets for analyses: the first analysis of a log fingerprint
+// opens a ticket with the full report, and later ones comment on it while it
+// is open.
+//
+// A nil *Filer files nothing.
+type Filer struct {
+	tracker     Tracker
+	auto        bool
+	minSeverity domain.Severity
+	labels      []string
+	logger      *zap.Logger
+	now         func() time.Time
+
+	// mu is held across tracker calls so concurrent analyses of the same
+	// failure do not open duplicate tickets.
+	mu    sync.Mutex
+	known map[string]knownTicket
+}
+
+// NewFiler creates a Filer. With auto set, analyses at or above minSeverity
+// are filed without being requested. labels are added to every new ticket.
+func NewFiler(tracker Tracker, auto bool, minSeverity domain.Severity, labels []string, logger *zap.Logger) *Filer {
+	return &Filer{
+		tracker:     tracker,
+		auto:        auto,
+		minSeverity: minSeverity,
+		labels:      labels,
+		logger:      logger.Named("tickets"),
+		now:         time.Now,
+		known:       make(map[string]knownTicket),
+	}
+}
+
+// Wants reports whether a successful analysis with severity should be
+// filed. requested is the request's ticket flag: true files it whatever the
+// severity, false never does, and nil leaves it to the automatic policy.
+func (f *Filer) Wants(severity domain.Severity, requested *bool) bool {
+	if f == nil {
+		return false
+	}
+	if requested != nil {
+		return *requested
+	}
+	return f.auto && severity.Rank() >= f.minSeverity.Rank()
+}
+
+// File opens a ticket for the analysis of the log with fingerprint, or
+// comments on the open ticket already filed for it.
+func (f *Filer) File(ctx context.Context, fingerprint string, response *domain.AnalysisResponse) (*domain.Ticket, error) {
+	if f == nil || response == nil || response.Result == nil {
+		return nil, nil
+	}
+	label := FingerprintLabel(fingerprint)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	existing, err := f.lookup(ctx, label, now)
+	if err != nil {
+		return nil, domain.WrapError("ticket_search", err, true)
+	}
+	if existing != nil {
+		if err := f.tracker.Comment(ctx, existing.Key, recurrence(response, now)); err != nil {
+			return nil, domain.WrapError("ticket_comment", err, true)
+		}
+		f.logger.Debug("ticket updated", zap.String("key", existing.Key))
+		ticket := *existing
+		ticket.Created = false
+		return &ticket, nil
+	}
+
+	issue, err := newIssue(response, append([]string{label}, f.labels...))
+	if err != nil {
+		return nil, err
+	}
+	ticket, err := f.tracker.Create(ctx, issue)
+	if err != nil {
+		return nil, domain.WrapError("ticket_create", err, true)
+	}
+	ticket.Created = true
+	f.remember(label, *ticket, now)
+	f.logger.Info("ticket opened",
+		zap.String("key", ticket.Key),
+		zap.String("error_type", response.Result.ErrorType),
+	)
+	return ticket, nil
+}
+
+// lookup returns the open ticket for label, from the cache or the tracker.
+// Caller holds mu.
+func (f *Filer) lookup(ctx context.Context, label string, now time.Time) (*domain.Ticket, error) {
+	if known, ok := f.known[label]; ok && now.Before(known.expires) {
+		return &known.ticket, nil
+	}
+	ticket, err := f.tracker.FindOpen(ctx, label)
+	if err != nil || ticket == nil {
+		return nil, err
+	}
+	f.remember(label, *ticket, now)
+	return ticket, nil
+}
+
+// remember caches a ticket for label. Caller holds mu.
+func (f *Filer) remember(label string, ticket domain.Ticket, now time.Time) {
+	if len(f.known) >= maxKnownTickets {
+		for key, known := range f.known {
+			if !now.Before(known.expires) {
+				delete(f.known, key)
+			}
+		}
+		if len(f.known) >= maxKnownTickets {
+			f.known = make(map[string]knownTicket)
+		}
+	}
+	f.known[label] = knownTicket{ticket: ticket, expires: now.Add(knownTicketTTL)}
+}
+
+// FingerprintLabel returns the ticket label for a log fingerprint.
+func FingerprintLabel(fingerprint string) string {
+	if len(fingerprint) > fingerprintLabelLength {
+		fingerprint = fingerprint[:fingerprintLabelLength]
+	}
+	return fingerprintLabelPrefix + fingerprint
+}
+
+// newIssue builds the ticket for an analysis: a title naming the severity,
+// error type and root cause, and the markdown report as body.
+func newIssue(response *domain.AnalysisResponse, labels []string) (*Issue, error) {
+	result := response.Result
+	rootCause := strings.Join(strings.Fields(result.RootCause), " ")
+	if len(rootCause) > maxTitleLength {
+		rootCause = strings.TrimSpace(rootCause[:maxTitleLength]) + "..."
+	}
+
+	var body bytes.Buffer
+	if err := render.Markdown(&body, response); err != nil {
+		return nil, fmt.Errorf("render ticket body: %w", err)
+	}
+	if response.ID != "" {
+		fmt.Fprintf(&body, "\nAnalysis ID: `%s`\n", response.ID)
+	}
+
+	return &Issue{
+		Title:  fmt.Sprintf("[%s] %s: %s", result.Severity, result.ErrorType, rootCause),
+		Body:   body.String(),
+		Labels: labels,
+	}, nil
+}
+
+// recurrence is the comment added when a failure occurs again.
+func recurrence(response *domain.AnalysisResponse, now time.Time) string {
+	comment := fmt.Sprintf("Occurred again at %s (%s, source `%s`).",
+		now.UTC().Format(time.RFC3339), response.Result.Severity, response.Source)
+	if response.ID != "" {
+		comment += fmt.Sprintf(" Analysis ID: `%s`.", response.ID)
+	}
+	return comment
+}
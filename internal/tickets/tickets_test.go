@@ -0,0 +1,261 @@
+// Package tickets provides unit tests for ticket filing and the trackers.
+package tickets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"go.uber.org/zap"
+)
+
+// fakeTracker records created tickets and comments.
+type fakeTracker struct {
+	open     map[string]*domain.Ticket
+	created  []*Issue
+	comments map[string][]string
+	searches int
+}
+
+func newFakeTracker() *fakeTracker {
+	return &fakeTracker{open: make(map[string]*domain.Ticket), comments: make(map[string][]string)}
+}
+
+func (f *fakeTracker) Name() string { return "fake" }
+
+func (f *fakeTracker) FindOpen(_ context.Context, label string) (*domain.Ticket, error) {
+	f.searches++
+	return f.open[label], nil
+}
+
+func (f *fakeTracker) Create(_ context.Context, issue *Issue) (*domain.Ticket, error) {
+	f.created = append(f.created, issue)
+	return &domain.Ticket{Tracker: "fake", Key: "T-1", URL: "https://tracker/T-1"}, nil
+}
+
+func (f *fakeTracker) Comment(_ context.Context, key, body string) error {
+	f.comments[key] = append(f.comments[key], body)
+	return nil
+}
+
+func highResponse() *domain.AnalysisResponse {
+	return &domain.AnalysisResponse{
+		ID:      "a1",
+		Success: true,
+		Source:  "rules:oom",
+		Result: &domain.AnalysisResult{
+			ErrorType: "oom_killed",
+			Severity:  domain.SeverityHigh,
+			RootCause: "The container exceeded its memory limit.",
+		},
+	}
+}
+
+func TestFiler_Wants(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name      string
+		auto      bool
+		severity  domain.Severity
+		requested *bool
+		want      bool
+	}{
+		{"auto high", true, domain.SeverityHigh, nil, true},
+		{"auto below threshold", true, domain.SeverityMedium, nil, false},
+		{"auto off", false, domain.SeverityHigh, nil, false},
+		{"requested below threshold", false, domain.SeverityLow, &yes, true},
+		{"suppressed", true, domain.SeverityHigh, &no, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFiler(newFakeTracker(), tt.auto, domain.SeverityHigh, nil, zap.NewNop())
+			if got := f.Wants(tt.severity, tt.requested); got != tt.want {
+				t.Errorf("Wants() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var nilFiler *Filer
+	if nilFiler.Wants(domain.SeverityHigh, &yes) {
+		t.Error("nil Filer wants tickets")
+	}
+}
+
+func TestFiler_CreatesThenComments(t *testing.T) {
+	tracker := newFakeTracker()
+	f := NewFiler(tracker, true, domain.SeverityHigh, []string{"ci"}, zap.NewNop())
+	ctx := context.Background()
+
+	ticket, err := f.File(ctx, "0123456789abcdef0123", highResponse())
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if !ticket.Created || ticket.Key != "T-1" {
+		t.Errorf("first File() = %+v, want created T-1", ticket)
+	}
+	if len(tracker.created) != 1 {
+		t.Fatalf("created %d tickets, want 1", len(tracker.created))
+	}
+	issue := tracker.created[0]
+	if issue.Title != "[High] oom_killed: The container exceeded its memory limit." {
+		t.Errorf("Title = %q", issue.Title)
+	}
+	if !strings.Contains(issue.Body, "The container exceeded its memory limit.") || !strings.Contains(issue.Body, "Analysis ID: `a1`") {
+		t.Errorf("Body missing report:\n%s", issue.Body)
+	}
+	wantLabels := []string{"ai-devops-0123456789abcdef", "ci"}
+	if strings.Join(issue.Labels, ",") != strings.Join(wantLabels, ",") {
+		t.Errorf("Labels = %v, want %v", issue.Labels, wantLabels)
+	}
+
+	ticket, err = f.File(ctx, "0123456789abcdef0123", highResponse())
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if ticket.Created || ticket.Key != "T-1" {
+		t.Errorf("second File() = %+v, want updated T-1", ticket)
+	}
+	if len(tracker.created) != 1 || len(tracker.comments["T-1"]) != 1 {
+		t.Errorf("created=%d comments=%d, want 1 and 1", len(tracker.created), len(tracker.comments["T-1"]))
+	}
+	if tracker.searches != 1 {
+		t.Errorf("searched %d times, want 1 (recent ticket reused)", tracker.searches)
+	}
+}
+
+func TestFiler_CommentsOnExistingTicket(t *testing.T) {
+	tracker := newFakeTracker()
+	tracker.open[FingerprintLabel("fp")] = &domain.Ticket{Tracker: "fake", Key: "T-9", URL: "https://tracker/T-9"}
+	f := NewFiler(tracker, true, domain.SeverityHigh, nil, zap.NewNop())
+	f.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+
+	ticket, err := f.File(context.Background(), "fp", highResponse())
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if ticket.Created || ticket.Key != "T-9" {
+		t.Errorf("File() = %+v, want existing T-9", ticket)
+	}
+	want := "Occurred again at 2024-01-01T12:00:00Z (High, source `rules:oom`). Analysis ID: `a1`."
+	if got := tracker.comments["T-9"]; len(got) != 1 || got[0] != want {
+		t.Errorf("comments = %q, want %q", got, want)
+	}
+}
+
+func TestJiraTracker(t *testing.T) {
+	var searchJQL, createdSummary, comment string
+	var createdLabels []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "ops@example.com" || token != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/rest/api/2/search":
+			searchJQL, _ = body["jql"].(string)
+			w.Write([]byte(`{"issues": []}`))
+		case "/rest/api/2/issue":
+			fields := body["fields"].(map[string]any)
+			createdSummary, _ = fields["summary"].(string)
+			for _, label := range fields["labels"].([]any) {
+				createdLabels = append(createdLabels, label.(string))
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "10001", "key": "OPS-42"}`))
+		case "/rest/api/2/issue/OPS-42/comment":
+			comment, _ = body["body"].(string)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tracker := NewJiraTracker(server.URL+"/", "ops@example.com", "secret", "OPS", "", server.Client())
+	ctx := context.Background()
+
+	ticket, err := tracker.FindOpen(ctx, "ai-devops-abc")
+	if err != nil || ticket != nil {
+		t.Fatalf("FindOpen() = %+v, %v, want nil", ticket, err)
+	}
+	wantJQL := `project = "OPS" AND labels = "ai-devops-abc" AND statusCategory != Done ORDER BY created DESC`
+	if searchJQL != wantJQL {
+		t.Errorf("jql = %q, want %q", searchJQL, wantJQL)
+	}
+
+	ticket, err = tracker.Create(ctx, &Issue{Title: "[High] oom", Body: "report", Labels: []string{"ai-devops-abc"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if ticket.Key != "OPS-42" || ticket.URL != server.URL+"/browse/OPS-42" || ticket.Tracker != "jira" {
+		t.Errorf("Create() = %+v", ticket)
+	}
+	if createdSummary != "[High] oom" || len(createdLabels) != 1 {
+		t.Errorf("summary = %q, labels = %v", createdSummary, createdLabels)
+	}
+
+	if err := tracker.Comment(ctx, "OPS-42", "again"); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+	if comment != "again" {
+		t.Errorf("comment = %q, want %q", comment, "again")
+	}
+}
+
+func TestGitHubTracker(t *testing.T) {
+	var comment string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/api/issues":
+			if r.URL.Query().Get("labels") != "ai-devops-abc" || r.URL.Query().Get("state") != "open" {
+				t.Errorf("query = %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`[{"number": 17, "html_url": "https://github.com/acme/api/issues/17"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/api/issues/17/comments":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			comment = body["body"]
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tracker := NewGitHubTracker(server.URL, "secret", "acme/api", server.Client())
+	ctx := context.Background()
+
+	ticket, err := tracker.FindOpen(ctx, "ai-devops-abc")
+	if err != nil {
+		t.Fatalf("FindOpen() error = %v", err)
+	}
+	want := domain.Ticket{Tracker: "github", Key: "acme/api#17", URL: "https://github.com/acme/api/issues/17"}
+	if ticket == nil || *ticket != want {
+		t.Fatalf("FindOpen() = %+v, want %+v", ticket, want)
+	}
+
+	if err := tracker.Comment(ctx, ticket.Key, "again"); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+	if comment != "again" {
+		t.Errorf("comment = %q, want %q", comment, "again")
+	}
+
+	if _, err := tracker.Create(ctx, &Issue{Title: "x"}); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Create() error = %v, want status 404", err)
+	}
+}
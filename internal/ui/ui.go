@@ -0,0 +1,11 @@
+// Package ui embeds the built-in web UI: a single page where a developer
+// pastes a log, picks the detail level and reads the analysis with the
+// evidence lines highlighted. The page calls the public analyze API.
+package ui
+
+import _ "embed"
+
+// Index is the single-page UI, with its styles and script inlined.
+//
+//go:embed index.html
+var Index []byte
@@ -0,0 +1,15 @@
+// Package ui provides unit tests for the embedded web UI.
+package ui
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	for _, want := range []string{"<!DOCTYPE html>", "/api/v1/analyze", `id="detail"`} {
+		if !bytes.Contains(Index, []byte(want)) {
+			t.Errorf("Index does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package usage tracks AI token consumption and enforces token budgets.
+package usage
+
+import (
+	"sync"
+	"time"
+)
+
+// Budget enforces daily and monthly token limits. A zero limit disables
+// that period's check. Periods are calendar days and months in UTC.
+//
+// A nil *Budget is valid and behaves as an unlimited budget.
+type Budget struct {
+	dailyLimit   int
+	monthlyLimit int
+	now          func() time.Time
+
+	mu          sync.Mutex
+	day         string
+	month       string
+	dailyUsed   int
+	monthlyUsed int
+}
+
+// Snapshot is a point-in-time view of budget consumption.
+type Snapshot struct {
+	DailyUsed    int `json:"daily_used"`
+	DailyLimit   int `json:"daily_limit"`
+	MonthlyUsed  int `json:"monthly_used"`
+	MonthlyLimit int `json:"monthly_limit"`
+}
+
+// NewBudget creates a token budget with the given limits.
+func NewBudget(dailyLimit, monthlyLimit int) *Budget {
+	return &Budget{
+		dailyLimit:   dailyLimit,
+		monthlyLimit: monthlyLimit,
+		now:          time.Now,
+	}
+}
+
+// Exceeded reports whether either budget period has been exhausted.
+func (b *Budget) Exceeded() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+
+	if b.dailyLimit > 0 && b.dailyUsed >= b.dailyLimit {
+		return true
+	}
+	return b.monthlyLimit > 0 && b.monthlyUsed >= b.monthlyLimit
+}
+
+// Record adds consumed tokens to the current periods.
+func (b *Budget) Record(tokens int) {
+	if b == nil || tokens <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+
+	b.dailyUsed += tokens
+	b.monthlyUsed += tokens
+}
+
+// Snapshot returns the current consumption and limits.
+func (b *Budget) Snapshot() Snapshot {
+	if b == nil {
+		return Snapshot{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+
+	return Snapshot{
+		DailyUsed:    b.dailyUsed,
+		DailyLimit:   b.dailyLimit,
+		MonthlyUsed:  b.monthlyUsed,
+		MonthlyLimit: b.monthlyLimit,
+	}
+}
+
+// rollover resets counters when the day or month changes. Caller holds mu.
+func (b *Budget) rollover() {
+	now := b.now().UTC()
+	day := now.Format("2006-01-02")
+	month := now.Format("2006-01")
+
+	if day != b.day {
+		b.day = day
+		b.dailyUsed = 0
+	}
+	if month != b.month {
+		b.month = month
+		b.monthlyUsed = 0
+	}
+}
@@ -0,0 +1,98 @@
+// Package usage provides unit tests for token budgets and pricing.
+package usage
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestBudget_Exceeded(t *testing.T) {
+	current := time.Date(2024, 3, 31, 23, 0, 0, 0, time.UTC)
+	b := NewBudget(100, 150)
+	b.now = func() time.Time { return current }
+
+	b.Record(60)
+	if b.Exceeded() {
+		t.Fatal("budget should not be exceeded after 60 tokens")
+	}
+
+	b.Record(40)
+	if !b.Exceeded() {
+		t.Fatal("daily budget should be exceeded after 100 tokens")
+	}
+
+	// Next day (and month): both counters reset.
+	current = current.Add(2 * time.Hour)
+	if b.Exceeded() {
+		t.Fatal("budget should reset on a new day")
+	}
+
+	b.Record(90)
+	current = current.Add(24 * time.Hour)
+	b.Record(70)
+	if !b.Exceeded() {
+		t.Fatal("monthly budget should be exceeded after 160 tokens")
+	}
+
+	snap := b.Snapshot()
+	if snap.DailyUsed != 70 || snap.MonthlyUsed != 160 {
+		t.Errorf("Snapshot() = %+v, want daily 70, monthly 160", snap)
+	}
+}
+
+func TestBudget_Nil(t *testing.T) {
+	var b *Budget
+	b.Record(1000)
+	if b.Exceeded() {
+		t.Error("nil budget should never be exceeded")
+	}
+}
+
+func TestPricing_Estimate(t *testing.T) {
+	pricing := DefaultPricing()
+	u := &domain.TokenUsage{PromptTokens: 1_000_000, CompletionTokens: 500_000, TotalTokens: 1_500_000}
+
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{model: "gpt-4o-mini", want: 0.15 + 0.30},
+		{model: "gpt-4o-mini-2024-07-18", want: 0.15 + 0.30},
+		{model: "gpt-4o", want: 2.50 + 5.00},
+		{model: "unknown-model", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := pricing.Estimate(tt.model, u); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Estimate(%s) = %v, want %v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeter_Record(t *testing.T) {
+	meter := NewMeter(NewBudget(0, 0), DefaultPricing(), "gpt-4o")
+	cheap := domain.TokenUsage{Model: "gpt-4o-mini", PromptTokens: 1_000_000, TotalTokens: 1_000_000}
+	strong := domain.TokenUsage{Model: "gpt-4o", PromptTokens: 1_000_000, TotalTokens: 1_000_000}
+
+	tests := []struct {
+		name string
+		u    *domain.TokenUsage
+		want float64
+	}{
+		{"model of the call", &cheap, 0.15},
+		{"default model", &domain.TokenUsage{PromptTokens: 1_000_000, TotalTokens: 1_000_000}, 2.50},
+		{"each call at its model", &domain.TokenUsage{PromptTokens: 2_000_000, TotalTokens: 2_000_000, Calls: []domain.TokenUsage{cheap, strong}}, 2.65},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := meter.Record(tt.u); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Record() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Package usage tracks AI token consumption and enforces token budgets.
+package usage
+
+import (
+	"github.com/ai-devops/internal/domain"
+)
+
+// Meter records AI token usage against a budget and prices it.
+//
+// A nil *Meter is valid: it never reports the budget as exceeded and
+// estimates every call at zero cost.
+type Meter struct {
+	budget  *Budget
+	pricing Pricing
+	model   string
+}
+
+// NewMeter creates a meter. Usage is priced at the model that served the
+// call; model prices usage that does not name one. budget may be nil for
+// unlimited usage.
+func NewMeter(budget *Budget, pricing Pricing, model string) *Meter {
+	return &Meter{
+		budget:  budget,
+		pricing: pricing,
+		model:   model,
+	}
+}
+
+// Exceeded reports whether the token budget has been exhausted.
+func (m *Meter) Exceeded() bool {
+	if m == nil {
+		return false
+	}
+	return m.budget.Exceeded()
+}
+
+// Record charges u against the budget and returns its estimated cost in USD.
+func (m *Meter) Record(u *domain.TokenUsage) float64 {
+	if m == nil || u == nil {
+		return 0
+	}
+	m.budget.Record(u.TotalTokens)
+	return m.estimate(u)
+}
+
+// estimate prices u at the model that served it, or each of its calls at
+// their own.
+func (m *Meter) estimate(u *domain.TokenUsage) float64 {
+	if len(u.Calls) > 0 {
+		var cost float64
+		for i := range u.Calls {
+			cost += m.estimate(&u.Calls[i])
+		}
+		return cost
+	}
+	model := u.Model
+	if model == "" {
+		model = m.model
+	}
+	return m.pricing.Estimate(model, u)
+}
+
+// Budget returns the underlying budget, which may be nil.
+func (m *Meter) Budget() *Budget {
+	if m == nil {
+		return nil
+	}
+	return m.budget
+}
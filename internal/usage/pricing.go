@@ -0,0 +1,66 @@
+// Package usage tracks AI token consumption and enforces token budgets.
+package usage
+
+import (
+	"strings"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// ModelPrice is the list price of a model in USD per million tokens.
+type ModelPrice struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// Pricing maps model names to prices. Lookups match the longest model-name
+// prefix so dated variants (e.g. "gpt-4o-mini-2024-07-18") use the base price.
+type Pricing map[string]ModelPrice
+
+// DefaultPricing returns list prices for the supported models.
+// Prices change; override them with AI_PRICE_INPUT_PER_MTOK and
+// AI_PRICE_OUTPUT_PER_MTOK when they drift.
+func DefaultPricing() Pricing {
+	return Pricing{
+		"gpt-4o":           {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+		"gpt-4o-mini":      {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		"gpt-4-turbo":      {InputPerMillion: 10.00, OutputPerMillion: 30.00},
+		"gpt-3.5-turbo":    {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+		"gemini-2.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+		"gemini-2.5-flash": {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+		"gemini-2.0-flash": {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+		"gemini-1.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 5.00},
+		"gemini-1.5-flash": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+		"gemini-1.0-pro":   {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+	}
+}
+
+// Lookup returns the price for model using longest-prefix matching.
+func (p Pricing) Lookup(model string) (ModelPrice, bool) {
+	model = strings.ToLower(model)
+
+	var best string
+	for name := range p {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return p[best], true
+}
+
+// Estimate returns the estimated cost in USD of u for model.
+// Unknown models are estimated at zero.
+func (p Pricing) Estimate(model string, u *domain.TokenUsage) float64 {
+	if u == nil {
+		return 0
+	}
+	price, ok := p.Lookup(model)
+	if !ok {
+		return 0
+	}
+	return (float64(u.PromptTokens)*price.InputPerMillion +
+		float64(u.CompletionTokens)*price.OutputPerMillion) / 1_000_000
+}
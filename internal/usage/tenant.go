@@ -0,0 +1,159 @@
+// Package usage tracks AI token consumption and enforces token budgets.
+package usage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Quota bounds a tenant's usage per calendar month (UTC). A zero limit
+// disables that check.
+type Quota struct {
+	// MonthlyAnalyses bounds the analyses of the tenant.
+	MonthlyAnalyses int `json:"monthly_analyses"`
+
+	// MonthlyTokens bounds the AI tokens spent on the tenant's analyses.
+	MonthlyTokens int `json:"monthly_tokens"`
+
+	// Block rejects the tenant's analyses once the quota is exhausted
+	// instead of answering them from rules only.
+	Block bool `json:"block"`
+}
+
+// TenantUsage is a tenant's consumption in the current month.
+type TenantUsage struct {
+	Tenant string `json:"tenant"`
+	Month  string `json:"month"`
+
+	// Analyses counts the tenant's analyses, AIAnalyses those that called
+	// the AI.
+	Analyses   int `json:"analyses"`
+	AIAnalyses int `json:"ai_analyses"`
+
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+
+	// Quota is the tenant's quota, if it has one, and QuotaExceeded
+	// whether it is exhausted.
+	Quota         *Quota `json:"quota,omitempty"`
+	QuotaExceeded bool   `json:"quota_exceeded"`
+}
+
+// TenantMeter records analyses and token spend per tenant and enforces
+// their monthly quotas. Counters reset when the month changes.
+//
+// A nil *TenantMeter is valid: it records nothing and no quota is ever
+// exceeded.
+type TenantMeter struct {
+	quotas map[string]Quota
+	now    func() time.Time
+
+	mu    sync.Mutex
+	month string
+	usage map[string]*TenantUsage
+}
+
+// NewTenantMeter creates a meter enforcing quotas by tenant ID.
+func NewTenantMeter(quotas map[string]Quota) *TenantMeter {
+	return &TenantMeter{
+		quotas: quotas,
+		now:    time.Now,
+		usage:  make(map[string]*TenantUsage),
+	}
+}
+
+// RecordAnalysis counts an analysis of tenant.
+func (m *TenantMeter) RecordAnalysis(tenant string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tenant(tenant).Analyses++
+}
+
+// RecordAI charges an AI call's usage and estimated cost to tenant. u may
+// be nil when the provider did not report usage.
+func (m *TenantMeter) RecordAI(tenant string, u *domain.TokenUsage, cost float64) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.tenant(tenant)
+	t.AIAnalyses++
+	if u != nil {
+		t.PromptTokens += u.PromptTokens
+		t.CompletionTokens += u.CompletionTokens
+		t.TotalTokens += u.TotalTokens
+	}
+	t.EstimatedCostUSD += cost
+}
+
+// Exceeded reports whether tenant has exhausted its quota and, if so,
+// whether its analyses are to be blocked.
+func (m *TenantMeter) Exceeded(tenant string) (exceeded, block bool) {
+	if m == nil {
+		return false, false
+	}
+	quota, ok := m.quotas[tenant]
+	if !ok {
+		return false, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return quota.exceededBy(m.tenant(tenant)), quota.Block
+}
+
+// Report returns the usage of every tenant seen this month and of every
+// tenant with a quota, ordered by tenant.
+func (m *TenantMeter) Report() []TenantUsage {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for tenant := range m.quotas {
+		m.tenant(tenant)
+	}
+
+	report := make([]TenantUsage, 0, len(m.usage))
+	for tenant, t := range m.usage {
+		entry := *t
+		if quota, ok := m.quotas[tenant]; ok {
+			entry.Quota = &quota
+			entry.QuotaExceeded = quota.exceededBy(t)
+		}
+		report = append(report, entry)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Tenant < report[j].Tenant })
+	return report
+}
+
+// tenant returns the current month's counters of tenant, resetting all
+// counters when the month changes. Caller holds mu.
+func (m *TenantMeter) tenant(tenant string) *TenantUsage {
+	if month := m.now().UTC().Format("2006-01"); month != m.month {
+		m.month = month
+		m.usage = make(map[string]*TenantUsage)
+	}
+	t, ok := m.usage[tenant]
+	if !ok {
+		t = &TenantUsage{Tenant: tenant, Month: m.month}
+		m.usage[tenant] = t
+	}
+	return t
+}
+
+// exceededBy reports whether usage exhausts q.
+func (q Quota) exceededBy(usage *TenantUsage) bool {
+	if q.MonthlyAnalyses > 0 && usage.Analyses >= q.MonthlyAnalyses {
+		return true
+	}
+	return q.MonthlyTokens > 0 && usage.TotalTokens >= q.MonthlyTokens
+}
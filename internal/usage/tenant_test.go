@@ -0,0 +1,60 @@
+// Package usage provides unit tests for per-tenant usage metering and quotas.
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func TestTenantMeter(t *testing.T) {
+	current := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	m := NewTenantMeter(map[string]Quota{
+		"payments": {MonthlyTokens: 100, Block: true},
+		"search":   {MonthlyAnalyses: 2},
+	})
+	m.now = func() time.Time { return current }
+
+	m.RecordAnalysis("payments")
+	m.RecordAI("payments", &domain.TokenUsage{PromptTokens: 80, CompletionTokens: 40, TotalTokens: 120}, 0.5)
+	m.RecordAnalysis("search")
+
+	if exceeded, block := m.Exceeded("payments"); !exceeded || !block {
+		t.Errorf("Exceeded(payments) = %v, %v, want true, true", exceeded, block)
+	}
+	if exceeded, _ := m.Exceeded("search"); exceeded {
+		t.Error("Exceeded(search) = true after 1 of 2 analyses")
+	}
+	m.RecordAnalysis("search")
+	if exceeded, block := m.Exceeded("search"); !exceeded || block {
+		t.Errorf("Exceeded(search) = %v, %v, want true, false", exceeded, block)
+	}
+	if exceeded, _ := m.Exceeded("other"); exceeded {
+		t.Error("tenant without quota exceeded")
+	}
+
+	report := m.Report()
+	if len(report) != 2 || report[0].Tenant != "payments" || report[0].TotalTokens != 120 ||
+		report[0].AIAnalyses != 1 || report[0].EstimatedCostUSD != 0.5 || !report[0].QuotaExceeded {
+		t.Fatalf("Report() = %+v", report)
+	}
+
+	// A new month resets the counters
+	current = current.Add(24 * time.Hour)
+	if exceeded, _ := m.Exceeded("payments"); exceeded {
+		t.Error("quota still exceeded in a new month")
+	}
+	if report := m.Report(); report[0].TotalTokens != 0 || report[0].Month != "2024-04" {
+		t.Errorf("Report() after rollover = %+v", report[0])
+	}
+}
+
+func TestTenantMeter_Nil(t *testing.T) {
+	var m *TenantMeter
+	m.RecordAnalysis("a")
+	m.RecordAI("a", &domain.TokenUsage{TotalTokens: 10}, 1)
+	if exceeded, _ := m.Exceeded("a"); exceeded {
+		t.Error("nil meter should never be exceeded")
+	}
+}
@@ -0,0 +1,131 @@
+// Package vectorindex provides an in-memory nearest-neighbour index over
+// embedding vectors, used to find past incidents similar to a new log.
+package vectorindex
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Hit is an indexed ID and its cosine similarity to a query.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// entry is an indexed vector, normalized to unit length.
+type entry struct {
+	id     string
+	vector []float32
+}
+
+// Index is a bounded set of vectors searched exhaustively by cosine
+// similarity. It is safe for concurrent use.
+type Index struct {
+	mu         sync.RWMutex
+	entries    []entry
+	maxEntries int
+}
+
+// New creates an index holding up to maxEntries vectors; the oldest are
+// dropped first. Zero means no bound.
+func New(maxEntries int) *Index {
+	return &Index{maxEntries: maxEntries}
+}
+
+// Add indexes vector under id, replacing an existing vector with the same
+// id. Zero vectors are ignored.
+func (x *Index) Add(id string, vector []float32) {
+	normalized := normalize(vector)
+	if normalized == nil {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.removeLocked(id)
+	x.entries = append(x.entries, entry{id: id, vector: normalized})
+	if x.maxEntries > 0 && len(x.entries) > x.maxEntries {
+		x.entries = x.entries[len(x.entries)-x.maxEntries:]
+	}
+}
+
+// Remove drops the vector indexed under id.
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.removeLocked(id)
+}
+
+// Len returns the number of indexed vectors.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.entries)
+}
+
+// Search returns up to k indexed IDs whose similarity to vector is at least
+// minScore, most similar first. Vectors of a different dimension are
+// skipped.
+func (x *Index) Search(vector []float32, k int, minScore float64) []Hit {
+	query := normalize(vector)
+	if query == nil || k <= 0 {
+		return nil
+	}
+
+	x.mu.RLock()
+	var hits []Hit
+	for _, e := range x.entries {
+		if len(e.vector) != len(query) {
+			continue
+		}
+		if score := dot(query, e.vector); score >= minScore {
+			hits = append(hits, Hit{ID: e.id, Score: score})
+		}
+	}
+	x.mu.RUnlock()
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+// removeLocked drops the entry with id. x.mu must be held.
+func (x *Index) removeLocked(id string) {
+	for i, e := range x.entries {
+		if e.id == id {
+			x.entries = append(x.entries[:i], x.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// normalize returns a unit-length copy of v, or nil for a zero vector.
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, f := range v {
+		sum += float64(f) * float64(f)
+	}
+	if sum == 0 {
+		return nil
+	}
+	norm := math.Sqrt(sum)
+	out := make([]float32, len(v))
+	for i, f := range v {
+		out[i] = float32(float64(f) / norm)
+	}
+	return out
+}
+
+// dot returns the dot product of two vectors of equal length.
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
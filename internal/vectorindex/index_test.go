@@ -0,0 +1,67 @@
+// Package vectorindex provides unit tests for the vector index.
+package vectorindex
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIndex_Search(t *testing.T) {
+	x := New(0)
+	x.Add("a", []float32{1, 0, 0})
+	x.Add("b", []float32{2, 2, 0})
+	x.Add("c", []float32{0, 0, 3})
+	x.Add("zero", []float32{0, 0, 0})
+	x.Add("short", []float32{1, 0})
+
+	tests := []struct {
+		name     string
+		query    []float32
+		k        int
+		minScore float64
+		want     []string
+	}{
+		{"most similar first", []float32{1, 0.2, 0}, 3, 0.5, []string{"a", "b"}},
+		{"k bounds results", []float32{1, 1, 1}, 1, 0, []string{"b"}},
+		{"threshold", []float32{0, 1, 0}, 3, 0.9, nil},
+		{"zero query", []float32{0, 0, 0}, 3, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := x.Search(tt.query, tt.k, tt.minScore)
+			if len(hits) != len(tt.want) {
+				t.Fatalf("Search() = %+v, want %v", hits, tt.want)
+			}
+			for i, hit := range hits {
+				if hit.ID != tt.want[i] {
+					t.Fatalf("Search() = %+v, want %v", hits, tt.want)
+				}
+			}
+		})
+	}
+
+	if hits := x.Search([]float32{1, 0, 0}, 1, 0); math.Abs(hits[0].Score-1) > 1e-6 {
+		t.Errorf("identical direction score = %v, want 1", hits[0].Score)
+	}
+}
+
+func TestIndex_AddRemove(t *testing.T) {
+	x := New(2)
+	x.Add("a", []float32{1, 0})
+	x.Add("b", []float32{0, 1})
+	x.Add("a", []float32{1, 1})
+	if x.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2 after replacing a", x.Len())
+	}
+
+	x.Add("c", []float32{1, 0})
+	if hits := x.Search([]float32{0, 1}, 3, 0.99); len(hits) != 0 {
+		t.Errorf("oldest entry b should be dropped, got %+v", hits)
+	}
+
+	x.Remove("c")
+	if x.Len() != 1 {
+		t.Errorf("Len() = %d, want 1 after Remove", x.Len())
+	}
+}
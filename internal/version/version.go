@@ -0,0 +1,41 @@
+// Package version reports the build of the running binary.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version and Commit are set at build time:
+//
+//	go build -ldflags "-X github.com/ai-devops/internal/version.Version=v1.2.0 -X github.com/ai-devops/internal/version.Commit=$(git rev-parse HEAD)"
+//
+// When Commit is not set, the VCS revision Go stamps into binaries built
+// from a repository is used.
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// Build describes the running binary.
+type Build struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build of the running binary. Commit is empty when it is
+// unknown.
+func Get() Build {
+	build := Build{Version: Version, Commit: Commit, GoVersion: runtime.Version()}
+	if build.Commit == "" {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" {
+					build.Commit = setting.Value
+				}
+			}
+		}
+	}
+	return build
+}
@@ -0,0 +1,69 @@
+// Package webhook verifies the authenticity of inbound integration webhooks.
+package webhook
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReplayGuard rejects webhook deliveries seen within a time window and
+// timestamps outside of it. It is safe for concurrent use.
+type ReplayGuard struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+	// order holds the seen IDs oldest first, so that expired ones are
+	// removed without scanning the map.
+	order []delivery
+}
+
+// delivery is a seen delivery ID with the time it was accepted.
+type delivery struct {
+	id string
+	at time.Time
+}
+
+// NewReplayGuard creates a guard remembering deliveries for window.
+func NewReplayGuard(window time.Duration) *ReplayGuard {
+	return &ReplayGuard{
+		window: window,
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Check records a delivery ID, returning ErrReplayed if it was already seen.
+func (g *ReplayGuard) Check(id string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	expired := 0
+	for expired < len(g.order) && now.Sub(g.order[expired].at) > g.window {
+		delete(g.seen, g.order[expired].id)
+		expired++
+	}
+	g.order = g.order[expired:]
+
+	if _, ok := g.seen[id]; ok {
+		return fmt.Errorf("%w: %s", ErrReplayed, id)
+	}
+	g.seen[id] = now
+	g.order = append(g.order, delivery{id: id, at: now})
+	return nil
+}
+
+// CheckTimestamp returns ErrStale if ts is further than the window from now.
+func (g *ReplayGuard) CheckTimestamp(ts time.Time) error {
+	skew := g.now().Sub(ts)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > g.window {
+		return fmt.Errorf("%w: skew %s", ErrStale, skew.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// Package webhook verifies the authenticity of inbound integration webhooks
+// (GitHub, GitLab, Sentry, ArgoCD). It supports HMAC body signatures and
+// shared-token headers, with replay protection based on delivery IDs and
+// signed timestamps.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+)
+
+// Rejection reasons. Verification errors wrap one of these.
+var (
+	// ErrMissingSignature indicates the signature or token header is absent.
+	ErrMissingSignature = errors.New("missing webhook signature")
+
+	// ErrInvalidSignature indicates the signature or token does not match.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+
+	// ErrReplayed indicates the delivery ID has already been accepted.
+	ErrReplayed = errors.New("webhook delivery replayed")
+
+	// ErrStale indicates the signed timestamp is outside the allowed window.
+	ErrStale = errors.New("webhook timestamp outside allowed window")
+
+	// ErrNotConfigured indicates no secret is configured for the integration.
+	ErrNotConfigured = errors.New("webhook integration not configured")
+)
+
+// Verifier checks that a webhook request was sent by the integration.
+type Verifier interface {
+	// Verify checks the request headers and raw body.
+	Verify(header http.Header, body []byte) error
+}
+
+// HMACVerifier verifies a hex-encoded HMAC of the request body.
+type HMACVerifier struct {
+	// Header carries the signature (e.g. X-Hub-Signature-256).
+	Header string
+
+	// Prefix is stripped from the header value before decoding (e.g. "sha256=").
+	Prefix string
+
+	// Secret is the shared signing secret.
+	Secret []byte
+
+	// Hash constructs the HMAC hash; defaults to SHA-256.
+	Hash func() hash.Hash
+}
+
+// Verify implements Verifier.
+func (v *HMACVerifier) Verify(header http.Header, body []byte) error {
+	value := header.Get(v.Header)
+	if value == "" {
+		return fmt.Errorf("%w: %s header not set", ErrMissingSignature, v.Header)
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(value, v.Prefix))
+	if err != nil {
+		return fmt.Errorf("%w: malformed %s header", ErrInvalidSignature, v.Header)
+	}
+
+	newHash := v.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	mac := hmac.New(newHash, v.Secret)
+	mac.Write(body)
+
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("%w: %s mismatch", ErrInvalidSignature, v.Header)
+	}
+	return nil
+}
+
+// TokenVerifier compares a shared token header in constant time.
+type TokenVerifier struct {
+	// Header carries the token (e.g. X-Gitlab-Token).
+	Header string
+
+	// Prefix is stripped from the header value (e.g. "Bearer ").
+	Prefix string
+
+	// Token is the expected shared token.
+	Token string
+}
+
+// Verify implements Verifier.
+func (v *TokenVerifier) Verify(header http.Header, _ []byte) error {
+	value := header.Get(v.Header)
+	if value == "" {
+		return fmt.Errorf("%w: %s header not set", ErrMissingSignature, v.Header)
+	}
+
+	got := strings.TrimPrefix(value, v.Prefix)
+	if subtle.ConstantTimeCompare([]byte(got), []byte(v.Token)) != 1 {
+		return fmt.Errorf("%w: %s mismatch", ErrInvalidSignature, v.Header)
+	}
+	return nil
+}
+
+// Integration describes how one webhook source is authenticated.
+type Integration struct {
+	// Name identifies the integration in logs (e.g. "github").
+	Name string
+
+	// Verifier authenticates the request.
+	Verifier Verifier
+
+	// DeliveryHeader carries a unique delivery ID used for replay protection.
+	DeliveryHeader string
+
+	// TimestampHeader carries a Unix timestamp, if the source signs one
+	// together with the body. An unsigned timestamp proves nothing, as a
+	// replay can set it to the current time.
+	TimestampHeader string
+}
+
+// Verify authenticates the request and rejects replays through guard.
+// guard may be nil to disable replay protection.
+func (i *Integration) Verify(header http.Header, body []byte, guard *ReplayGuard) error {
+	if i.Verifier == nil {
+		return fmt.Errorf("%w: %s", ErrNotConfigured, i.Name)
+	}
+
+	if err := i.Verifier.Verify(header, body); err != nil {
+		return err
+	}
+
+	if guard == nil {
+		return nil
+	}
+
+	if i.TimestampHeader != "" {
+		ts, err := strconv.ParseInt(header.Get(i.TimestampHeader), 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: malformed %s header", ErrStale, i.TimestampHeader)
+		}
+		if err := guard.CheckTimestamp(time.Unix(ts, 0)); err != nil {
+			return err
+		}
+	}
+
+	if i.DeliveryHeader != "" {
+		if id := header.Get(i.DeliveryHeader); id != "" {
+			return guard.Check(i.Name + ":" + id)
+		}
+	}
+
+	return nil
+}
+
+// GitHub returns the integration for GitHub webhooks (X-Hub-Signature-256).
+func GitHub(secret string) *Integration {
+	return &Integration{
+		Name:           "github",
+		Verifier:       &HMACVerifier{Header: "X-Hub-Signature-256", Prefix: "sha256=", Secret: []byte(secret)},
+		DeliveryHeader: "X-GitHub-Delivery",
+	}
+}
+
+// GitLab returns the integration for GitLab webhooks (X-Gitlab-Token).
+func GitLab(token string) *Integration {
+	return &Integration{
+		Name:           "gitlab",
+		Verifier:       &TokenVerifier{Header: "X-Gitlab-Token", Token: token},
+		DeliveryHeader: "X-Gitlab-Event-UUID",
+	}
+}
+
+// Sentry returns the integration for Sentry webhooks (Sentry-Hook-Signature).
+// Sentry signs neither its Sentry-Hook-Timestamp nor its Request-ID header,
+// so deliveries are told apart by their signature, which covers the body.
+func Sentry(secret string) *Integration {
+	return &Integration{
+		Name:           "sentry",
+		Verifier:       &HMACVerifier{Header: "Sentry-Hook-Signature", Secret: []byte(secret)},
+		DeliveryHeader: "Sentry-Hook-Signature",
+	}
+}
+
+// ArgoCD returns the integration for Argo CD notification webhooks, which are
+// configured to send a bearer token in the Authorization header.
+func ArgoCD(token string) *Integration {
+	return &Integration{
+		Name:     "argocd",
+		Verifier: &TokenVerifier{Header: "Authorization", Prefix: "Bearer ", Token: token},
+	}
+}
+
+// Configured returns the integrations that have a secret or token set in cfg,
+// keyed by integration name.
+func Configured(cfg config.WebhookConfig) map[string]*Integration {
+	integrations := make(map[string]*Integration)
+	if cfg.GitHubSecret != "" {
+		integrations["github"] = GitHub(cfg.GitHubSecret)
+	}
+	if cfg.GitLabToken != "" {
+		integrations["gitlab"] = GitLab(cfg.GitLabToken)
+	}
+	if cfg.SentrySecret != "" {
+		integrations["sentry"] = Sentry(cfg.SentrySecret)
+	}
+	if cfg.ArgoCDToken != "" {
+		integrations["argocd"] = ArgoCD(cfg.ArgoCDToken)
+	}
+	return integrations
+}
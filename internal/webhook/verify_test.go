@@ -0,0 +1,166 @@
+// Package webhook provides unit tests for webhook verification.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestIntegration_Verify(t *testing.T) {
+	body := []byte(`{"action":"completed"}`)
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		integration *Integration
+		header      http.Header
+		wantErr     error
+	}{
+		{
+			name:        "github valid signature",
+			integration: GitHub("s3cret"),
+			header: http.Header{
+				"X-Hub-Signature-256": {"sha256=" + sign("s3cret", body)},
+				"X-Github-Delivery":   {"d-1"},
+			},
+		},
+		{
+			name:        "github wrong secret",
+			integration: GitHub("s3cret"),
+			header:      http.Header{"X-Hub-Signature-256": {"sha256=" + sign("other", body)}},
+			wantErr:     ErrInvalidSignature,
+		},
+		{
+			name:        "github missing signature",
+			integration: GitHub("s3cret"),
+			header:      http.Header{},
+			wantErr:     ErrMissingSignature,
+		},
+		{
+			name:        "gitlab token",
+			integration: GitLab("tok"),
+			header:      http.Header{"X-Gitlab-Token": {"tok"}},
+		},
+		{
+			name:        "argocd wrong token",
+			integration: ArgoCD("tok"),
+			header:      http.Header{"Authorization": {"Bearer nope"}},
+			wantErr:     ErrInvalidSignature,
+		},
+		{
+			name:        "sentry ignores unsigned timestamp",
+			integration: Sentry("s3cret"),
+			header: http.Header{
+				"Sentry-Hook-Signature": {sign("s3cret", body)},
+				"Sentry-Hook-Timestamp": {strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)},
+			},
+		},
+		{
+			name:        "stale timestamp",
+			integration: &Integration{Name: "signed", Verifier: &TokenVerifier{Header: "X-Token", Token: "tok"}, TimestampHeader: "X-Timestamp"},
+			header: http.Header{
+				"X-Token":     {"tok"},
+				"X-Timestamp": {strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)},
+			},
+			wantErr: ErrStale,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.integration.Verify(tt.header, body, NewReplayGuard(5*time.Minute))
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIntegration_Replay(t *testing.T) {
+	body := []byte(`{}`)
+
+	tests := []struct {
+		name        string
+		integration *Integration
+		first       http.Header
+		replay      http.Header
+	}{
+		{
+			name:        "github delivery id",
+			integration: GitHub("s3cret"),
+			first: http.Header{
+				"X-Hub-Signature-256": {"sha256=" + sign("s3cret", body)},
+				"X-Github-Delivery":   {"d-1"},
+			},
+			replay: http.Header{
+				"X-Hub-Signature-256": {"sha256=" + sign("s3cret", body)},
+				"X-Github-Delivery":   {"d-1"},
+			},
+		},
+		{
+			name:        "sentry with a new request id",
+			integration: Sentry("s3cret"),
+			first: http.Header{
+				"Sentry-Hook-Signature": {sign("s3cret", body)},
+				"Request-Id":            {"r-1"},
+			},
+			replay: http.Header{
+				"Sentry-Hook-Signature": {sign("s3cret", body)},
+				"Request-Id":            {"r-2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := NewReplayGuard(5 * time.Minute)
+			if err := tt.integration.Verify(tt.first, body, guard); err != nil {
+				t.Fatalf("first delivery rejected: %v", err)
+			}
+			if err := tt.integration.Verify(tt.replay, body, guard); !errors.Is(err, ErrReplayed) {
+				t.Errorf("error = %v, want ErrReplayed", err)
+			}
+		})
+	}
+}
+
+func TestReplayGuard_Expiry(t *testing.T) {
+	now := time.Now()
+	guard := NewReplayGuard(time.Minute)
+	guard.now = func() time.Time { return now }
+
+	if err := guard.Check("a"); err != nil {
+		t.Fatalf("Check(a) error = %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	if err := guard.Check("b"); err != nil {
+		t.Fatalf("Check(b) error = %v", err)
+	}
+
+	// a has expired, b is still within the window
+	now = now.Add(45 * time.Second)
+	if err := guard.Check("a"); err != nil {
+		t.Errorf("Check(a) after the window error = %v", err)
+	}
+	if err := guard.Check("b"); !errors.Is(err, ErrReplayed) {
+		t.Errorf("Check(b) error = %v, want ErrReplayed", err)
+	}
+	if len(guard.seen) != 2 || len(guard.order) != 2 {
+		t.Errorf("guard holds %d ids, %d queued, want 2", len(guard.seen), len(guard.order))
+	}
+}
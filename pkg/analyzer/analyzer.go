@@ -0,0 +1,240 @@
+// Package analyzer embeds the log analysis pipeline (sanitization, rule-based
+// pre-classification and AI analysis) in other Go programs.
+//
+// It is the library counterpart of the HTTP service:
+//
+//	a, err := analyzer.New(
+//		analyzer.WithOpenAI(os.Getenv("AI_API_KEY"), "gpt-4o-mini"),
+//		analyzer.WithCache(8<<20),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	resp, err := a.AnalyzeLog(ctx, buildOutput)
+//
+// Results, requests and rules are the same types the service uses, so JSON
+// produced by either is interchangeable.
+package analyzer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/cache"
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+	"github.com/ai-devops/internal/service"
+	"github.com/ai-devops/pkg/sanitizer"
+)
+
+// Types shared with the HTTP service.
+type (
+	// Request is an analysis request.
+	Request = domain.AnalysisRequest
+
+	// Response is the outcome of an analysis.
+	Response = domain.AnalysisResponse
+
+	// Result is the structured analysis of a log.
+	Result = domain.AnalysisResult
+
+	// Command is a shell command suggested by a result.
+	Command = domain.Command
+
+	// Severity is the impact level of a result.
+	Severity = domain.Severity
+
+	// DetailLevel controls how much an analysis says.
+	DetailLevel = domain.DetailLevel
+
+	// AnalysisMode selects a specialist analysis.
+	AnalysisMode = domain.AnalysisMode
+
+	// LogMetadata describes where a log came from.
+	LogMetadata = domain.LogMetadata
+
+	// LogSection is a named log from one component of an incident.
+	LogSection = domain.LogSection
+
+	// Rule is a rule-based pre-classification rule.
+	Rule = rules.Rule
+
+	// Condition is a compiled rule condition; see CompileCondition.
+	Condition = rules.Condition
+
+	// ErrorCode identifies why an analysis failed (Response.Error.Code).
+	ErrorCode = domain.ErrorCode
+
+	// RuleMatch is a rule that matched a log.
+	RuleMatch = domain.RuleMatch
+
+	// Conversation is a troubleshooting session passed to Client.Chat.
+	Conversation = domain.Conversation
+
+	// ChatReply is the answer of Client.Chat.
+	ChatReply = domain.ChatReply
+
+	// Client analyzes logs with an AI provider. Implement it to plug in a
+	// provider the package does not support.
+	Client = ai.Client
+
+	// Stage is a step of the analysis pipeline; see WithStage.
+	Stage = service.Stage
+
+	// Analysis is the state of a request passed to each Stage and
+	// PostProcessor.
+	Analysis = service.Analysis
+
+	// PostProcessor acts on a response before it is returned; see
+	// WithPostProcessor.
+	PostProcessor = service.PostProcessor
+)
+
+// Severity and detail levels, and analysis modes.
+const (
+	SeverityLow    = domain.SeverityLow
+	SeverityMedium = domain.SeverityMedium
+	SeverityHigh   = domain.SeverityHigh
+
+	DetailBrief    = domain.DetailBrief
+	DetailStandard = domain.DetailStandard
+	DetailDeep     = domain.DetailDeep
+
+	ModeIaC        = domain.AnalysisModeIaC
+	ModeKubernetes = domain.AnalysisModeKubernetes
+	ModeDocker     = domain.AnalysisModeDocker
+	ModeCI         = domain.AnalysisModeCI
+)
+
+// Built-in pipeline stages, in the order they run. See WithStage.
+const (
+	StageSanitize    = service.StageSanitize
+	StageClassify    = service.StageClassify
+	StageEnrich      = service.StageEnrich
+	StageAnalyze     = service.StageAnalyze
+	StagePostProcess = service.StagePostProcess
+)
+
+// Built-in post-processors, in their default order. See
+// WithPostProcessOrder.
+const (
+	PostProcessTaxonomy       = service.PostProcessTaxonomy
+	PostProcessSeverityPolicy = service.PostProcessSeverityPolicy
+	PostProcessStore          = service.PostProcessStore
+	PostProcessNotify         = service.PostProcessNotify
+)
+
+// ErrInvalidConfig is returned by New for invalid options.
+var ErrInvalidConfig = domain.ErrInvalidConfig
+
+// DefaultRules returns the built-in rule set.
+func DefaultRules() []*Rule {
+	return rules.DefaultRules()
+}
+
+// CompileCondition compiles a CEL-style rule condition such as
+// `metadata.runner_os == "windows" && log.contains("EPERM")`.
+func CompileCondition(source string) (*Condition, error) {
+	return rules.CompileCondition(source)
+}
+
+// NewStage returns a Stage running run.
+func NewStage(name string, run func(ctx context.Context, analysis *Analysis) error) Stage {
+	return service.NewStage(name, run)
+}
+
+// NewPostProcessor returns a PostProcessor running process.
+func NewPostProcessor(name string, process func(ctx context.Context, analysis *Analysis) error) PostProcessor {
+	return service.NewPostProcessor(name, process)
+}
+
+// Analyzer runs the analysis pipeline. It is safe for concurrent use.
+type Analyzer struct {
+	pipeline  *service.Analyzer
+	engine    *rules.Engine
+	sanitizer *sanitizer.Sanitizer
+}
+
+// New creates an Analyzer. An AI provider must be chosen with WithOpenAI,
+// WithGemini, WithAIClient or WithMockAI.
+func New(opts ...Option) (*Analyzer, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	client := o.client
+	if client == nil {
+		var err error
+		client, err = o.newClient()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	engine := rules.NewEngine(o.rules, o.ruleThreshold, o.logger)
+	logSanitizer := sanitizer.New(o.maxLogSize)
+
+	var resultCache cache.Cache
+	if o.cacheMaxBytes > 0 {
+		resultCache = cache.NewLRU(o.cacheMaxBytes)
+	}
+
+	pipeline, err := service.NewAnalyzer(client, engine, logSanitizer, service.AnalyzerConfig{
+		EnableRules:      o.enableRules,
+		HybridMerge:      o.hybridMerge,
+		Cache:            resultCache,
+		DefaultLanguage:  o.language,
+		CustomStages:     o.stages,
+		PostProcessors:   o.postProcessors,
+		PostProcessOrder: o.postProcessOrder,
+	}, o.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Analyzer{pipeline: pipeline, engine: engine, sanitizer: logSanitizer}, nil
+}
+
+// Analyze runs the full pipeline on req. Invalid requests and AI failures
+// are reported in the response; the error is reserved for internal failures.
+func (a *Analyzer) Analyze(ctx context.Context, req *Request) (*Response, error) {
+	return a.pipeline.Analyze(ctx, req)
+}
+
+// AnalyzeLog analyzes a single log with default request settings.
+func (a *Analyzer) AnalyzeLog(ctx context.Context, log string) (*Response, error) {
+	return a.pipeline.Analyze(ctx, &Request{Log: log})
+}
+
+// MatchRules sanitizes log and returns the matching rules, best first,
+// without calling the AI. meta may be nil.
+func (a *Analyzer) MatchRules(log string, meta *LogMetadata) []RuleMatch {
+	sanitized, _ := a.sanitizer.Sanitize(log)
+	return a.engine.AnalyzeWithMetadata(sanitized, meta)
+}
+
+// newClient builds the client for the configured provider.
+func (o *options) newClient() (Client, error) {
+	if o.mock {
+		return ai.NewMockClient(o.logger), nil
+	}
+
+	prompter, err := ai.NewDefaultPromptBuilder()
+	if err != nil {
+		return nil, fmt.Errorf("create prompt builder: %w", err)
+	}
+	validator := ai.NewDefaultValidator().WithReferenceDomains(o.ai.ReferenceDomains...)
+
+	switch o.ai.Provider {
+	case config.AIProviderGemini:
+		return ai.NewGeminiClient(&o.ai, prompter, validator, o.logger), nil
+	default:
+		return ai.NewOpenAIClient(&o.ai, prompter, validator, o.logger), nil
+	}
+}
@@ -0,0 +1,152 @@
+// Package analyzer provides unit tests for the library facade.
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+type stubClient struct {
+	calls int
+	err   error
+}
+
+func (c *stubClient) Analyze(ctx context.Context, log string) (*Result, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &Result{
+		ErrorType:        "stub_error",
+		Severity:         SeverityLow,
+		RootCause:        "stub",
+		SuggestedActions: []string{"retry"},
+	}, nil
+}
+
+func (c *stubClient) Chat(ctx context.Context, conversation *Conversation, message string) (*ChatReply, error) {
+	return &ChatReply{Content: "stub"}, nil
+}
+
+func (c *stubClient) HealthCheck(ctx context.Context) error { return nil }
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{"no provider", nil, true},
+		{"openai without key", []Option{WithOpenAI("", "gpt-4o-mini")}, true},
+		{"openai", []Option{WithOpenAI("key", "gpt-4o-mini")}, false},
+		{"gemini", []Option{WithGemini("key", "gemini-2.0-flash")}, false},
+		{"mock", []Option{WithMockAI()}, false},
+		{"custom client", []Option{WithAIClient(&stubClient{})}, false},
+		{"bad threshold", []Option{WithMockAI(), WithRuleThreshold(1.5)}, true},
+		{"bad log size", []Option{WithMockAI(), WithMaxLogSize(0)}, true},
+		{"stage", []Option{WithMockAI(), WithStage(StageClassify, NewStage("noop", func(context.Context, *Analysis) error { return nil }))}, false},
+		{"post-processor order", []Option{WithMockAI(), WithPostProcessOrder(PostProcessSeverityPolicy, PostProcessTaxonomy)}, false},
+		{"unknown post-processor", []Option{WithMockAI(), WithPostProcessOrder("audit")}, true},
+		{"stage after unknown stage", []Option{WithMockAI(), WithStage("parse", NewStage("noop", func(context.Context, *Analysis) error { return nil }))}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("error %v should wrap ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func flakyRule() *Rule {
+	return &Rule{
+		ID:         "custom_flaky_test",
+		Patterns:   []*regexp.Regexp{regexp.MustCompile(`FLAKY`)},
+		Confidence: 0.95,
+		Result: &Result{
+			ErrorType:        "flaky_test",
+			Severity:         SeverityMedium,
+			RootCause:        "Known flaky test",
+			SuggestedActions: []string{"Re-run the job"},
+		},
+	}
+}
+
+func TestAnalyzer_RulesThenClient(t *testing.T) {
+	client := &stubClient{}
+	custom := flakyRule()
+
+	a, err := New(WithAIClient(client), WithRules(append(DefaultRules(), custom)...))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	resp, err := a.AnalyzeLog(context.Background(), "--- FAIL: TestCheckout FLAKY")
+	if err != nil || !resp.Success {
+		t.Fatalf("AnalyzeLog() = %+v, %v", resp, err)
+	}
+	if resp.Source != "rules:custom_flaky_test" || client.calls != 0 {
+		t.Errorf("expected custom rule result, got source %q with %d AI calls", resp.Source, client.calls)
+	}
+
+	resp, err = a.Analyze(context.Background(), &Request{Log: "something unusual happened", Detail: DetailBrief})
+	if err != nil || !resp.Success {
+		t.Fatalf("Analyze() = %+v, %v", resp, err)
+	}
+	if resp.Source != "ai" || client.calls != 1 {
+		t.Errorf("expected AI result, got source %q with %d AI calls", resp.Source, client.calls)
+	}
+
+	if matches := a.MatchRules("FLAKY", nil); len(matches) != 1 || matches[0].RuleID != custom.ID {
+		t.Errorf("MatchRules() = %+v", matches)
+	}
+
+	resp, _ = a.AnalyzeLog(context.Background(), "   ")
+	if resp.Success || resp.Error.Code != domain.CodeEmptyLog {
+		t.Errorf("empty log response = %+v", resp)
+	}
+}
+
+func TestAnalyzer_HybridMerge(t *testing.T) {
+	tests := []struct {
+		name       string
+		client     *stubClient
+		wantSource string
+		wantCause  string
+	}{
+		{"merged", &stubClient{}, "hybrid:custom_flaky_test", "stub"},
+		{"AI failure keeps rule result", &stubClient{err: domain.ErrAIUnavailable}, "rules:custom_flaky_test", "Known flaky test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New(WithAIClient(tt.client), WithRules(flakyRule()), WithHybridMerge())
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			resp, err := a.AnalyzeLog(context.Background(), "--- FAIL: TestCheckout FLAKY")
+			if err != nil || !resp.Success {
+				t.Fatalf("AnalyzeLog() = %+v, %v", resp, err)
+			}
+			if resp.Source != tt.wantSource {
+				t.Errorf("Source = %q, want %q", resp.Source, tt.wantSource)
+			}
+			if resp.Result.ErrorType != "flaky_test" || resp.Result.Severity != SeverityMedium {
+				t.Errorf("classification = %s/%s, want the rule's", resp.Result.ErrorType, resp.Result.Severity)
+			}
+			if resp.Result.RootCause != tt.wantCause {
+				t.Errorf("RootCause = %q, want %q", resp.Result.RootCause, tt.wantCause)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Package analyzer embeds the log analysis pipeline in other Go programs.
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-devops/internal/config"
+	"github.com/ai-devops/internal/service"
+	"go.uber.org/zap"
+)
+
+// Default option values, matching the service defaults.
+const (
+	defaultMaxLogSize    = 50000
+	defaultRuleThreshold = 0.8
+	defaultTimeout       = 30 * time.Second
+	defaultMaxTokens     = 1024
+	defaultMaxRetries    = 2
+	defaultTemperature   = 0.1
+)
+
+// Option configures an Analyzer.
+type Option func(*options)
+
+type options struct {
+	ai     config.AIConfig
+	client Client
+	mock   bool
+
+	rules         []*Rule
+	enableRules   bool
+	hybridMerge   bool
+	ruleThreshold float64
+
+	maxLogSize    int
+	cacheMaxBytes int
+	language      string
+	stages        []service.CustomStage
+
+	postProcessors   []PostProcessor
+	postProcessOrder []string
+	logger           *zap.Logger
+}
+
+func defaultOptions() *options {
+	return &options{
+		ai: config.AIConfig{
+			Timeout:          defaultTimeout,
+			MaxTokens:        defaultMaxTokens,
+			MaxRetries:       defaultMaxRetries,
+			Temperature:      defaultTemperature,
+			StructuredOutput: true,
+		},
+		rules:         DefaultRules(),
+		enableRules:   true,
+		ruleThreshold: defaultRuleThreshold,
+		maxLogSize:    defaultMaxLogSize,
+		logger:        zap.NewNop(),
+	}
+}
+
+// WithOpenAI uses the OpenAI API (or a compatible backend, see WithBaseURL).
+func WithOpenAI(apiKey, model string) Option {
+	return func(o *options) {
+		o.ai.Provider = config.AIProviderOpenAI
+		o.ai.APIKey = apiKey
+		o.ai.Model = model
+		if o.ai.BaseURL == "" {
+			o.ai.BaseURL = "https://api.openai.com/v1"
+		}
+	}
+}
+
+// WithGemini uses the Google Gemini API.
+func WithGemini(apiKey, model string) Option {
+	return func(o *options) {
+		o.ai.Provider = config.AIProviderGemini
+		o.ai.APIKey = apiKey
+		o.ai.Model = model
+		if o.ai.BaseURL == "" {
+			o.ai.BaseURL = "https://generativelanguage.googleapis.com"
+		}
+	}
+}
+
+// WithBaseURL overrides the provider's API base URL, e.g. for Azure OpenAI
+// or a proxy.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) { o.ai.BaseURL = baseURL }
+}
+
+// WithAIClient uses a custom AI client instead of a built-in provider.
+func WithAIClient(client Client) Option {
+	return func(o *options) { o.client = client }
+}
+
+// WithMockAI uses simulated AI responses, for tests and offline use.
+func WithMockAI() Option {
+	return func(o *options) { o.mock = true }
+}
+
+// WithTimeout sets the maximum time to wait for an AI response.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.ai.Timeout = timeout }
+}
+
+// WithMaxTokens sets the maximum tokens of an AI response.
+func WithMaxTokens(maxTokens int) Option {
+	return func(o *options) { o.ai.MaxTokens = maxTokens }
+}
+
+// WithMaxRetries sets the number of retries on transient AI failures.
+func WithMaxRetries(retries int) Option {
+	return func(o *options) { o.ai.MaxRetries = retries }
+}
+
+// WithStructuredOutput controls whether the provider is asked to enforce the
+// result JSON schema. Disable it for backends that do not support it.
+func WithStructuredOutput(enabled bool) Option {
+	return func(o *options) { o.ai.StructuredOutput = enabled }
+}
+
+// WithRules replaces the built-in rules. Append to DefaultRules() to extend
+// them instead.
+func WithRules(rules ...*Rule) Option {
+	return func(o *options) { o.rules = rules }
+}
+
+// WithoutRules disables rule-based pre-classification; every log goes to
+// the AI.
+func WithoutRules() Option {
+	return func(o *options) { o.enableRules = false }
+}
+
+// WithHybridMerge sends confident rule matches to the AI as well and merges
+// the results: error_type and severity from the rule, root cause and
+// actions from the AI.
+func WithHybridMerge() Option {
+	return func(o *options) { o.hybridMerge = true }
+}
+
+// WithRuleThreshold sets the minimum rule confidence (0.0-1.0) for a rule
+// result to be used without the AI.
+func WithRuleThreshold(threshold float64) Option {
+	return func(o *options) { o.ruleThreshold = threshold }
+}
+
+// WithMaxLogSize sets the log size in bytes above which logs are truncated.
+func WithMaxLogSize(size int) Option {
+	return func(o *options) { o.maxLogSize = size }
+}
+
+// WithCache caches AI results by log fingerprint in up to maxBytes of memory.
+func WithCache(maxBytes int) Option {
+	return func(o *options) { o.cacheMaxBytes = maxBytes }
+}
+
+// WithLanguage sets the default language of the analysis text.
+func WithLanguage(language string) Option {
+	return func(o *options) { o.language = language }
+}
+
+// WithStage inserts stage into the pipeline after the built-in stage named
+// after (StageSanitize, StageClassify, StageEnrich, StageAnalyze or
+// StagePostProcess). Stages after the same built-in stage run in the order
+// they are given. A stage that sets Analysis.Response before StageAnalyze
+// answers the request without the AI; one that returns an error fails the
+// analysis.
+func WithStage(after string, stage Stage) Option {
+	return func(o *options) {
+		o.stages = append(o.stages, service.CustomStage{After: after, Stage: stage})
+	}
+}
+
+// WithPostProcessor adds a post-processor, run after a response is shaped
+// for the caller and before it is returned. Unless WithPostProcessOrder
+// places them, post-processors run after the built-ins in the order they
+// are given. Their errors are logged and never fail the analysis.
+func WithPostProcessor(p PostProcessor) Option {
+	return func(o *options) { o.postProcessors = append(o.postProcessors, p) }
+}
+
+// WithPostProcessOrder sets the order of the built-in (PostProcessTaxonomy,
+// PostProcessSeverityPolicy, PostProcessStore, PostProcessNotify) and
+// custom post-processors by name. Post-processors left out do not run.
+func WithPostProcessOrder(names ...string) Option {
+	return func(o *options) { o.postProcessOrder = names }
+}
+
+// WithLogger sets the logger. The default discards all output.
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// validate checks the options for consistency.
+func (o *options) validate() error {
+	if o.client == nil && !o.mock {
+		if o.ai.Provider == "" {
+			return fmt.Errorf("%w: no AI provider (use WithOpenAI, WithGemini, WithAIClient or WithMockAI)", ErrInvalidConfig)
+		}
+		if o.ai.APIKey == "" {
+			return fmt.Errorf("%w: AI API key is required", ErrInvalidConfig)
+		}
+		if o.ai.Model == "" {
+			return fmt.Errorf("%w: AI model is required", ErrInvalidConfig)
+		}
+	}
+	if o.ai.Timeout <= 0 {
+		return fmt.Errorf("%w: timeout must be positive", ErrInvalidConfig)
+	}
+	if o.ai.MaxTokens <= 0 {
+		return fmt.Errorf("%w: max tokens must be positive", ErrInvalidConfig)
+	}
+	if o.ai.MaxRetries < 0 {
+		return fmt.Errorf("%w: max retries must be non-negative", ErrInvalidConfig)
+	}
+	if o.ruleThreshold < 0 || o.ruleThreshold > 1 {
+		return fmt.Errorf("%w: rule threshold must be between 0 and 1", ErrInvalidConfig)
+	}
+	if o.maxLogSize <= 0 {
+		return fmt.Errorf("%w: max log size must be positive", ErrInvalidConfig)
+	}
+	if o.cacheMaxBytes < 0 {
+		return fmt.Errorf("%w: cache size must be non-negative", ErrInvalidConfig)
+	}
+	if err := service.ValidateCustomStages(o.stages); err != nil {
+		return err
+	}
+	if err := service.ValidatePostProcessors(o.postProcessOrder, o.postProcessors); err != nil {
+		return err
+	}
+	if o.logger == nil {
+		o.logger = zap.NewNop()
+	}
+	return nil
+}
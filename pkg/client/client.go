@@ -0,0 +1,388 @@
+// Package client is a Go client for the log analysis HTTP service.
+//
+// It is the remote counterpart of pkg/analyzer:
+//
+//	c, err := client.New("http://ai-devops:8080", client.WithAPIKey(key))
+//	if err != nil {
+//		return err
+//	}
+//	resp, err := c.Analyze(ctx, &client.Request{Log: buildOutput})
+//
+// Requests carry an X-Request-ID that is reused across retries, so the
+// service logs one ID per logical call. Failed analyses are returned as
+// *APIError with the service's error code.
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// Types shared with the HTTP service.
+type (
+	// Request is an analysis request.
+	Request = domain.AnalysisRequest
+
+	// Response is the outcome of an analysis.
+	Response = domain.AnalysisResponse
+
+	// Result is the structured analysis of a log.
+	Result = domain.AnalysisResult
+
+	// LogMetadata describes where a log came from.
+	LogMetadata = domain.LogMetadata
+
+	// ErrorCode identifies why an analysis failed.
+	ErrorCode = domain.ErrorCode
+)
+
+// maxErrorBody bounds how much of an unparseable error body is kept.
+const maxErrorBody = 4096
+
+// Client calls the analysis service. It is safe for concurrent use.
+type Client struct {
+	baseURL      *url.URL
+	httpClient   *http.Client
+	timeout      time.Duration
+	maxRetries   int
+	backoff      time.Duration
+	apiKey       string
+	tenant       string
+	concurrency  int
+	newRequestID func() string
+	gzipMinBytes int
+}
+
+// New creates a Client for the service at baseURL, e.g.
+// "http://localhost:8080".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("client: base URL %q must be an absolute http(s) URL", baseURL)
+	}
+
+	c := &Client{
+		baseURL:      u,
+		timeout:      defaultTimeout,
+		maxRetries:   defaultMaxRetries,
+		backoff:      defaultBackoff,
+		concurrency:  defaultConcurrency,
+		newRequestID: randomRequestID,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.maxRetries < 0 {
+		return nil, fmt.Errorf("client: retries must not be negative, got %d", c.maxRetries)
+	}
+	if c.concurrency < 1 {
+		return nil, fmt.Errorf("client: concurrency must be at least 1, got %d", c.concurrency)
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: c.timeout}
+	}
+	return c, nil
+}
+
+// APIError is a non-2xx response from the service.
+type APIError struct {
+	// StatusCode is the HTTP status.
+	StatusCode int
+
+	// Code is the service's error code, empty if the body had none.
+	Code ErrorCode
+
+	// Message describes the failure.
+	Message string
+
+	// RequestID is the X-Request-ID of the call.
+	RequestID string
+
+	// Response is the decoded analysis response, if the body was one.
+	Response *Response
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("analysis service: %d %s: %s (request %s)", e.StatusCode, e.Code, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("analysis service: %d: %s (request %s)", e.StatusCode, e.Message, e.RequestID)
+}
+
+// Temporary reports whether retrying the call may succeed.
+func (e *APIError) Temporary() bool {
+	return retryableStatus(e.StatusCode)
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose calls send id as X-Request-ID, e.g.
+// to propagate the ID of the caller's own request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// Analyze analyzes a log. Failed analyses are returned as *APIError.
+func (c *Client) Analyze(ctx context.Context, req *Request) (*Response, error) {
+	if req == nil {
+		return nil, errors.New("client: nil request")
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("client: encoding request: %w", err)
+	}
+
+	var resp Response
+	if err := c.do(ctx, http.MethodPost, "/api/v1/analyze", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AnalyzeLog analyzes log with default request options.
+func (c *Client) AnalyzeLog(ctx context.Context, log string) (*Response, error) {
+	return c.Analyze(ctx, &Request{Log: log})
+}
+
+// BatchResult is the outcome of one request of AnalyzeBatch.
+type BatchResult struct {
+	Response *Response
+	Err      error
+}
+
+// AnalyzeBatch analyzes reqs concurrently (see WithConcurrency) and returns
+// their results in the same order. A failed request does not stop the
+// others; canceling ctx fails the requests not yet sent.
+func (c *Client) AnalyzeBatch(ctx context.Context, reqs []*Request) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+
+	for i, req := range reqs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, req *Request) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i].Response, results[i].Err = c.Analyze(ctx, req)
+		}(i, req)
+	}
+	wg.Wait()
+	return results
+}
+
+// Health is the liveness status of the service.
+type Health struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
+// Health checks that the service is up.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var health Health
+	if err := c.do(ctx, http.MethodGet, "/health", nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// do sends the request, retrying transient failures, and decodes a 2xx body
+// into out.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out any) error {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	if requestID == "" {
+		requestID = c.newRequestID()
+	}
+	endpoint := c.baseURL.JoinPath(path).String()
+
+	var encoding string
+	if c.gzipMinBytes > 0 && len(body) >= c.gzipMinBytes {
+		compressed, err := gzipBody(body)
+		if err != nil {
+			return fmt.Errorf("client: compressing request: %w", err)
+		}
+		body, encoding = compressed, "gzip"
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.attempt(ctx, method, endpoint, requestID, body, encoding, out)
+		if err == nil {
+			return nil
+		}
+		if attempt >= c.maxRetries || !retryable(ctx, err) {
+			return err
+		}
+
+		delay := backoff
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		backoff = min(backoff*2, defaultMaxBackoff)
+	}
+}
+
+// gzipBody compresses a request body.
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// attempt sends one request with the body's Content-Encoding, if any. It
+// returns the server's Retry-After delay, if any, alongside the error.
+func (c *Client) attempt(ctx context.Context, method, endpoint, requestID string, body []byte, encoding string, out any) (time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return 0, fmt.Errorf("client: building request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Request-ID", requestID)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.tenant != "" {
+		req.Header.Set("X-Tenant-ID", c.tenant)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("client: %s %s: %w", method, endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("client: reading response: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return 0, fmt.Errorf("client: decoding response: %w", err)
+		}
+		return 0, nil
+	}
+	return parseRetryAfter(resp.Header.Get("Retry-After")), newAPIError(resp.StatusCode, requestID, data)
+}
+
+func newAPIError(status int, requestID string, data []byte) *APIError {
+	apiErr := &APIError{StatusCode: status, RequestID: requestID}
+
+	var resp Response
+	if json.Unmarshal(data, &resp) == nil && resp.Error != nil {
+		apiErr.Code = resp.Error.Code
+		apiErr.Message = resp.Error.Message
+		apiErr.Response = &resp
+		return apiErr
+	}
+
+	// Endpoints outside the analysis API answer with {"error": "..."}.
+	var plain struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &plain) == nil && plain.Error != "" {
+		apiErr.Message = plain.Error
+		return apiErr
+	}
+
+	if len(data) > maxErrorBody {
+		data = data[:maxErrorBody]
+	}
+	apiErr.Message = strings.TrimSpace(string(data))
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(status)
+	}
+	return apiErr
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	// Transport errors; decoding errors of a 2xx body are not retried.
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+func randomRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
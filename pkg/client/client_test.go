@@ -0,0 +1,254 @@
+// Package client provides unit tests for the service client.
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]Option{WithBackoff(time.Millisecond)}, opts...)
+	c, err := New(server.URL, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "localhost:8080", "ftp://host", "http://"} {
+		if _, err := New(baseURL); err == nil {
+			t.Errorf("New(%q) expected error", baseURL)
+		}
+	}
+}
+
+func TestClient_Analyze(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/analyze" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("X-API-Key") != "secret" || r.Header.Get("X-Tenant-ID") != "team-a" {
+			t.Errorf("missing auth headers: %v", r.Header)
+		}
+		var req domain.AnalysisRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Log != "npm ERR!" {
+			t.Errorf("unexpected body %+v, err %v", req, err)
+		}
+		writeJSON(w, http.StatusOK, domain.AnalysisResponse{
+			Success: true,
+			Result:  &domain.AnalysisResult{ErrorType: "dependency_error"},
+		})
+	}, WithAPIKey("secret"), WithTenant("team-a"))
+
+	resp, err := c.AnalyzeLog(context.Background(), "npm ERR!")
+	if err != nil {
+		t.Fatalf("AnalyzeLog() error = %v", err)
+	}
+	if !resp.Success || resp.Result.ErrorType != "dependency_error" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestClient_Gzip(t *testing.T) {
+	tests := []struct {
+		name         string
+		log          string
+		wantEncoding string
+	}{
+		{"small body sent as is", "npm ERR!", ""},
+		{"large body compressed", strings.Repeat("npm ERR! code ERESOLVE\n", 100), "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+					t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+				}
+				body := io.Reader(r.Body)
+				if tt.wantEncoding == "gzip" {
+					zr, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Fatalf("gzip.NewReader() error = %v", err)
+					}
+					body = zr
+				}
+				var req domain.AnalysisRequest
+				if err := json.NewDecoder(body).Decode(&req); err != nil || req.Log != tt.log {
+					t.Errorf("unexpected body %+v, err %v", req, err)
+				}
+				writeJSON(w, http.StatusOK, domain.AnalysisResponse{Success: true})
+			}, WithGzip(1024))
+
+			if _, err := c.AnalyzeLog(context.Background(), tt.log); err != nil {
+				t.Fatalf("AnalyzeLog() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		retries      int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{"recovers from 503", http.StatusServiceUnavailable, 2, 2, false},
+		{"gives up after retries", http.StatusBadGateway, 1, 2, true},
+		{"no retry on 400", http.StatusBadRequest, 2, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			var ids []string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				ids = append(ids, r.Header.Get("X-Request-ID"))
+				if attempts.Add(1) == 1 || tt.wantErr {
+					writeJSON(w, tt.status, domain.AnalysisResponse{
+						Error: domain.NewErrorDetail(domain.CodeAIUnavailable, "upstream down"),
+					})
+					return
+				}
+				writeJSON(w, http.StatusOK, domain.AnalysisResponse{Success: true})
+			}, WithRetries(tt.retries))
+
+			_, err := c.AnalyzeLog(context.Background(), "log")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			for _, id := range ids {
+				if id == "" || id != ids[0] {
+					t.Errorf("request IDs %v should be one non-empty ID", ids)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, domain.AnalysisResponse{
+			Error: domain.NewErrorDetail(domain.CodeLogTooLarge, "log exceeds 50000 bytes"),
+		})
+	})
+
+	ctx := WithRequestID(context.Background(), "build-42")
+	_, err := c.AnalyzeLog(ctx, "log")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if apiErr.Code != domain.CodeLogTooLarge || apiErr.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+	if apiErr.RequestID != "build-42" {
+		t.Errorf("RequestID = %q, want build-42", apiErr.RequestID)
+	}
+	if apiErr.Temporary() {
+		t.Error("413 should not be temporary")
+	}
+}
+
+func TestClient_AnalyzeBatch(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		var req domain.AnalysisRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Log == "bad" {
+			writeJSON(w, http.StatusBadRequest, domain.AnalysisResponse{
+				Error: domain.NewErrorDetail(domain.CodeEmptyLog, "bad log"),
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, domain.AnalysisResponse{
+			Success: true,
+			Result:  &domain.AnalysisResult{RootCause: req.Log},
+		})
+	}, WithConcurrency(2))
+
+	reqs := []*Request{{Log: "a"}, {Log: "bad"}, {Log: "c"}, {Log: "d"}, {Log: "e"}}
+	results := c.AnalyzeBatch(context.Background(), reqs)
+
+	if len(results) != len(reqs) {
+		t.Fatalf("got %d results, want %d", len(results), len(reqs))
+	}
+	for i, res := range results {
+		if reqs[i].Log == "bad" {
+			if res.Err == nil {
+				t.Errorf("result %d: expected error", i)
+			}
+			continue
+		}
+		if res.Err != nil || res.Response.Result.RootCause != reqs[i].Log {
+			t.Errorf("result %d out of order or failed: %+v", i, res)
+		}
+	}
+	if peak.Load() > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", peak.Load())
+	}
+}
+
+func TestClient_Health(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "healthy", "time": "2024-01-02T03:04:05Z"})
+	})
+
+	health, err := c.Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if health.Status != "healthy" || health.Time.Year() != 2024 {
+		t.Errorf("unexpected health %+v", health)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("3"); got != 3*time.Second {
+		t.Errorf("parseRetryAfter(3) = %v", got)
+	}
+	if got := parseRetryAfter("soon"); got != 0 {
+		t.Errorf("parseRetryAfter(soon) = %v", got)
+	}
+}
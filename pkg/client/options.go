@@ -0,0 +1,89 @@
+// Package client is a Go client for the log analysis HTTP service.
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// Default option values.
+const (
+	defaultTimeout     = 60 * time.Second
+	defaultMaxRetries  = 2
+	defaultBackoff     = 500 * time.Millisecond
+	defaultMaxBackoff  = 10 * time.Second
+	defaultConcurrency = 4
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests with hc instead of a client created from
+// WithTimeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout bounds each HTTP attempt (default 60s). Retries get their own
+// timeout; bound the whole call with the context.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithRetries sets how many times a failed request is retried (default 2).
+// Only network errors, 429 and 502-504 responses are retried.
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		c.maxRetries = n
+	}
+}
+
+// WithBackoff sets the delay before the first retry (default 500ms). The
+// delay doubles with each retry up to 10s; a Retry-After header overrides it.
+func WithBackoff(d time.Duration) Option {
+	return func(c *Client) {
+		c.backoff = d
+	}
+}
+
+// WithAPIKey sends key in the X-API-Key header.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithTenant sends id in the X-Tenant-ID header.
+func WithTenant(id string) Option {
+	return func(c *Client) {
+		c.tenant = id
+	}
+}
+
+// WithConcurrency bounds how many requests AnalyzeBatch sends at once
+// (default 4).
+func WithConcurrency(n int) Option {
+	return func(c *Client) {
+		c.concurrency = n
+	}
+}
+
+// WithGzip compresses request bodies of at least minBytes with gzip. CI
+// logs typically shrink 10-20x.
+func WithGzip(minBytes int) Option {
+	return func(c *Client) {
+		c.gzipMinBytes = minBytes
+	}
+}
+
+// WithRequestIDFunc generates the X-Request-ID of calls whose context has
+// none (see WithRequestID). The default is a random hex string.
+func WithRequestIDFunc(fn func() string) Option {
+	return func(c *Client) {
+		c.newRequestID = fn
+	}
+}
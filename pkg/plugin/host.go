@@ -0,0 +1,232 @@
+// Package plugin runs rule providers and AI clients as separate
+// executables.
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+)
+
+// closeTimeout bounds the wait for a plugin to exit after its stdin is
+// closed; it is killed afterwards.
+const closeTimeout = 5 * time.Second
+
+// Config controls how a plugin is launched.
+type Config struct {
+	// Path is the plugin executable; Args are its arguments.
+	Path string
+	Args []string
+
+	// StartTimeout bounds the wait for the handshake line.
+	StartTimeout time.Duration
+
+	// Stderr, if set, receives the plugin's standard error.
+	Stderr io.Writer
+}
+
+// Host is a running plugin. It is safe for concurrent use.
+type Host struct {
+	info   InfoReply
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	rpc    *rpc.Client
+	exited chan struct{}
+}
+
+// Launch starts the plugin at cfg.Path and connects to it.
+func Launch(cfg Config) (*Host, error) {
+	if cfg.StartTimeout <= 0 {
+		return nil, fmt.Errorf("%w: plugin start timeout must be positive", domain.ErrInvalidConfig)
+	}
+
+	cmd := exec.Command(cfg.Path, cfg.Args...)
+	cmd.Env = append(os.Environ(), MagicCookieKey+"="+MagicCookieValue)
+	cmd.Stderr = cfg.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start plugin %s: %w", cfg.Path, err)
+	}
+	h := &Host{cmd: cmd, stdin: stdin, exited: make(chan struct{})}
+	go func() {
+		_ = cmd.Wait()
+		close(h.exited)
+	}()
+
+	network, addr, err := h.handshake(stdout, cfg.StartTimeout)
+	if err == nil {
+		h.rpc, err = rpc.Dial(network, addr)
+	}
+	if err == nil {
+		err = h.rpc.Call("Plugin.Info", struct{}{}, &h.info)
+	}
+	if err == nil && h.info.ProtocolVersion != ProtocolVersion {
+		err = fmt.Errorf("plugin speaks protocol %d, want %d", h.info.ProtocolVersion, ProtocolVersion)
+	}
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("plugin %s: %w", cfg.Path, err)
+	}
+	return h, nil
+}
+
+// handshake reads the plugin's handshake line and returns the address it
+// listens on.
+func (h *Host) handshake(stdout io.Reader, timeout time.Duration) (network, addr string, err error) {
+	lines := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(stdout).ReadString('\n')
+		lines <- line
+		// Keep draining so a chatty plugin never blocks on stdout
+		_, _ = io.Copy(io.Discard, stdout)
+	}()
+
+	var line string
+	select {
+	case line = <-lines:
+	case <-h.exited:
+		return "", "", errors.New("plugin exited before the handshake")
+	case <-time.After(timeout):
+		return "", "", fmt.Errorf("no handshake within %s", timeout)
+	}
+
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 5 {
+		return "", "", fmt.Errorf("invalid handshake %q", strings.TrimSpace(line))
+	}
+	if parts[0] != strconv.Itoa(coreProtocolVersion) || parts[4] != rpcProtocol {
+		return "", "", fmt.Errorf("unsupported handshake %q", strings.TrimSpace(line))
+	}
+	if parts[1] != strconv.Itoa(ProtocolVersion) {
+		return "", "", fmt.Errorf("plugin speaks protocol %s, want %d", parts[1], ProtocolVersion)
+	}
+	return parts[2], parts[3], nil
+}
+
+// Name returns the plugin's name.
+func (h *Host) Name() string {
+	return h.info.Name
+}
+
+// Rules compiles the plugin's rules.
+func (h *Host) Rules() ([]*rules.Rule, error) {
+	compiled := make([]*rules.Rule, 0, len(h.info.Rules))
+	for i := range h.info.Rules {
+		definition := &h.info.Rules[i]
+		if definition.ID == "" {
+			return nil, fmt.Errorf("plugin %s: rule %d has no id", h.info.Name, i+1)
+		}
+		rule, err := definition.Compile()
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: rule %q: %w", h.info.Name, definition.ID, err)
+		}
+		compiled = append(compiled, rule)
+	}
+	return compiled, nil
+}
+
+// Client returns the plugin's AI client, or nil if it has none.
+func (h *Host) Client() Client {
+	if !h.info.HasClient {
+		return nil
+	}
+	return &hostClient{host: h}
+}
+
+// Close closes the plugin's stdin, asking it to exit, and kills it if it
+// has not exited within a few seconds.
+func (h *Host) Close() error {
+	if h.rpc != nil {
+		h.rpc.Close()
+	}
+	h.stdin.Close()
+	select {
+	case <-h.exited:
+		return nil
+	case <-time.After(closeTimeout):
+		return h.cmd.Process.Kill()
+	}
+}
+
+// call calls method and waits for the reply or the end of ctx.
+func (h *Host) call(ctx context.Context, method string, args, reply any) error {
+	call := h.rpc.Go(method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		if call.Error != nil {
+			return fmt.Errorf("%w: plugin %s: %v", domain.ErrAIUnavailable, h.info.Name, call.Error)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hostClient is the ai.Client of a plugin.
+type hostClient struct {
+	host *Host
+}
+
+// deadline returns the deadline of ctx for the plugin, or the zero time.
+func deadline(ctx context.Context) time.Time {
+	d, _ := ctx.Deadline()
+	return d
+}
+
+// Analyze implements ai.Client.
+func (c *hostClient) Analyze(ctx context.Context, log string) (*Result, error) {
+	var reply AnalyzeReply
+	if err := c.host.call(ctx, "Plugin.Analyze", AnalyzeArgs{Log: log, Deadline: deadline(ctx)}, &reply); err != nil {
+		return nil, err
+	}
+	if err := reply.Error.toError(c.host.info.Name); err != nil {
+		return nil, err
+	}
+	if reply.Result == nil {
+		return nil, fmt.Errorf("%w: plugin %s returned no result", domain.ErrInvalidAIResponse, c.host.info.Name)
+	}
+	return reply.Result, nil
+}
+
+// Chat implements ai.Client.
+func (c *hostClient) Chat(ctx context.Context, conversation *Conversation, message string) (*ChatReply, error) {
+	var reply ChatResult
+	args := ChatArgs{Conversation: conversation, Message: message, Deadline: deadline(ctx)}
+	if err := c.host.call(ctx, "Plugin.Chat", args, &reply); err != nil {
+		return nil, err
+	}
+	if err := reply.Error.toError(c.host.info.Name); err != nil {
+		return nil, err
+	}
+	if reply.Reply == nil {
+		return nil, fmt.Errorf("%w: plugin %s returned no reply", domain.ErrInvalidAIResponse, c.host.info.Name)
+	}
+	return reply.Reply, nil
+}
+
+// HealthCheck implements ai.Client.
+func (c *hostClient) HealthCheck(ctx context.Context) error {
+	var reply HealthReply
+	if err := c.host.call(ctx, "Plugin.HealthCheck", HealthArgs{Deadline: deadline(ctx)}, &reply); err != nil {
+		return err
+	}
+	return reply.Error.toError(c.host.info.Name)
+}
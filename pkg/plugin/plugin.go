@@ -0,0 +1,129 @@
+// Package plugin runs rule providers and AI clients as separate
+// executables, so organizations can add proprietary detectors without
+// building them into the service.
+//
+// A plugin is a program that calls Serve:
+//
+//	func main() {
+//		plugin.Serve(&plugin.Plugin{
+//			Name:   "acme",
+//			Rules:  acmeRules,  // optional
+//			Client: acmeClient, // optional
+//		})
+//	}
+//
+// The service starts each plugin with Launch. Like hashicorp/go-plugin's
+// net/rpc protocol, the plugin checks a magic cookie in its environment,
+// listens on a private socket and prints a handshake line on stdout,
+// "1|<ProtocolVersion>|<network>|<address>|netrpc"; the host then calls
+// it over net/rpc. A plugin exits when its stdin is closed.
+package plugin
+
+import (
+	"time"
+
+	"github.com/ai-devops/internal/ai"
+	"github.com/ai-devops/internal/domain"
+	"github.com/ai-devops/internal/rules"
+)
+
+// ProtocolVersion is the version of the RPC protocol. The host refuses
+// plugins built against another version.
+const ProtocolVersion = 1
+
+// MagicCookieKey and MagicCookieValue are set in the environment of
+// launched plugins, so a plugin run by hand explains itself instead of
+// waiting for a host.
+const (
+	MagicCookieKey   = "AI_DEVOPS_PLUGIN"
+	MagicCookieValue = "b8f3c1e4d2a9470f9c6e5a1d3f7b2e80"
+)
+
+// coreProtocolVersion is the first field of the handshake line.
+const coreProtocolVersion = 1
+
+// rpcProtocol is the last field of the handshake line.
+const rpcProtocol = "netrpc"
+
+// Types shared with the service.
+type (
+	// RuleDefinition is the JSON form of a rule; see rules.Definition.
+	RuleDefinition = rules.Definition
+
+	// Client analyzes logs; see ai.Client.
+	Client = ai.Client
+
+	// Result is the structured analysis of a log.
+	Result = domain.AnalysisResult
+
+	// Conversation is a troubleshooting session passed to Client.Chat.
+	Conversation = domain.Conversation
+
+	// ChatReply is the answer of Client.Chat.
+	ChatReply = domain.ChatReply
+)
+
+// Plugin is what a plugin provides. Rules and Client are optional, but a
+// plugin should provide at least one of them.
+type Plugin struct {
+	// Name identifies the plugin in logs and configuration (AI_PLUGIN).
+	Name string
+
+	// Rules are added to the service's rules.
+	Rules []RuleDefinition
+
+	// Client, if set, can replace the service's AI provider.
+	Client Client
+}
+
+// The RPC messages below are exported only because net/rpc requires it.
+
+// InfoReply describes a plugin to the host.
+type InfoReply struct {
+	Name            string
+	ProtocolVersion int
+	Rules           []RuleDefinition
+	HasClient       bool
+}
+
+// CallError is an error returned by the plugin's client. Code is restored
+// to the matching domain error on the host (see domain.ErrorFor).
+type CallError struct {
+	Code    domain.ErrorCode
+	Message string
+}
+
+// AnalyzeArgs is a Client.Analyze call. A zero Deadline means none.
+type AnalyzeArgs struct {
+	Log      string
+	Deadline time.Time
+}
+
+// AnalyzeReply is the answer to AnalyzeArgs.
+type AnalyzeReply struct {
+	Result *Result
+	Error  *CallError
+}
+
+// ChatArgs is a Client.Chat call.
+type ChatArgs struct {
+	Conversation *Conversation
+	Message      string
+	Deadline     time.Time
+}
+
+// ChatResult is the answer to ChatArgs.
+type ChatResult struct {
+	Reply *ChatReply
+	Error *CallError
+}
+
+// HealthArgs is a Client.HealthCheck call.
+type HealthArgs struct {
+	Deadline time.Time
+}
+
+// HealthReply is the answer to HealthArgs.
+type HealthReply struct {
+	Error *CallError
+}
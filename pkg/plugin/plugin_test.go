@@ -0,0 +1,133 @@
+// Package plugin provides unit tests for launching and calling plugins.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// helperEnv makes the test binary serve helperPlugin instead of running
+// the tests.
+const helperEnv = "AI_DEVOPS_PLUGIN_TEST_HELPER"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(helperEnv); mode != "" {
+		p := helperPlugin()
+		if mode == "rules" {
+			p.Client = nil
+		}
+		Serve(p)
+	}
+	os.Exit(m.Run())
+}
+
+// helperClient answers every log it is not told to fail.
+type helperClient struct{}
+
+func (helperClient) Analyze(ctx context.Context, log string) (*Result, error) {
+	switch log {
+	case "rate limited":
+		return nil, domain.ErrRateLimited
+	case "slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &Result{
+		ErrorType:        "acme_license_expired",
+		Severity:         domain.SeverityHigh,
+		RootCause:        "The ACME license expired",
+		SuggestedActions: []string{"Renew the license"},
+	}, nil
+}
+
+func (helperClient) Chat(ctx context.Context, conversation *Conversation, message string) (*ChatReply, error) {
+	return &ChatReply{Content: "echo: " + message}, nil
+}
+
+func (helperClient) HealthCheck(ctx context.Context) error { return nil }
+
+func helperPlugin() *Plugin {
+	return &Plugin{
+		Name: "acme",
+		Rules: []RuleDefinition{{
+			ID:         "acme_license",
+			Keywords:   []string{"ACME-LICENSE"},
+			Confidence: 0.9,
+			Result:     &Result{ErrorType: "acme_license_expired", Severity: domain.SeverityHigh},
+		}},
+		Client: helperClient{},
+	}
+}
+
+func launchHelper(t *testing.T, mode string) *Host {
+	t.Helper()
+	t.Setenv(helperEnv, mode)
+	h, err := Launch(Config{Path: os.Args[0], StartTimeout: 10 * time.Second, Stderr: os.Stderr})
+	if err != nil {
+		t.Fatalf("Launch() error = %v", err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestLaunch(t *testing.T) {
+	h := launchHelper(t, "full")
+	if h.Name() != "acme" {
+		t.Errorf("Name() = %q, want acme", h.Name())
+	}
+
+	ruleSet, err := h.Rules()
+	if err != nil || len(ruleSet) != 1 || ruleSet[0].ID != "acme_license" {
+		t.Fatalf("Rules() = %v, %v", ruleSet, err)
+	}
+
+	client := h.Client()
+	if client == nil {
+		t.Fatal("Client() = nil, want the plugin's client")
+	}
+	result, err := client.Analyze(context.Background(), "ACME-LICENSE expired")
+	if err != nil || result.ErrorType != "acme_license_expired" {
+		t.Fatalf("Analyze() = %+v, %v", result, err)
+	}
+	reply, err := client.Chat(context.Background(), &Conversation{}, "hi")
+	if err != nil || reply.Content != "echo: hi" {
+		t.Fatalf("Chat() = %+v, %v", reply, err)
+	}
+	if err := client.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() error = %v", err)
+	}
+}
+
+func TestHostClient_Errors(t *testing.T) {
+	client := launchHelper(t, "full").Client()
+
+	_, err := client.Analyze(context.Background(), "rate limited")
+	if !errors.Is(err, domain.ErrRateLimited) {
+		t.Errorf("Analyze() error = %v, want ErrRateLimited", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Analyze(ctx, "slow")
+	if domain.CodeFor(err) != domain.CodeAITimeout {
+		t.Errorf("Analyze() error = %v, want code %s", err, domain.CodeAITimeout)
+	}
+}
+
+func TestLaunch_RulesOnly(t *testing.T) {
+	if client := launchHelper(t, "rules").Client(); client != nil {
+		t.Errorf("Client() = %v, want nil for a plugin without a client", client)
+	}
+}
+
+func TestLaunch_NotAPlugin(t *testing.T) {
+	_, err := Launch(Config{Path: "/bin/true", StartTimeout: 5 * time.Second})
+	if err == nil {
+		t.Fatal("Launch() error = nil, want a failed handshake")
+	}
+}
@@ -0,0 +1,163 @@
+// Package plugin runs rule providers and AI clients as separate
+// executables.
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/ai-devops/internal/domain"
+)
+
+// errNoClient is returned to hosts calling the client of a plugin without
+// one.
+var errNoClient = fmt.Errorf("%w: plugin provides no AI client", domain.ErrAIUnavailable)
+
+// Serve serves p to the host that launched the program and exits when the
+// host closes stdin. Run by hand, it prints why it cannot and exits with
+// status 1.
+func Serve(p *Plugin) {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		fmt.Fprintln(os.Stderr, "This program is an ai-devops plugin. It is started by the service (PLUGINS), not by hand.")
+		os.Exit(1)
+	}
+	if err := serve(p, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "plugin %s: %v\n", p.Name, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// serve writes the handshake line to out and serves p until in reaches
+// EOF.
+func serve(p *Plugin, in io.Reader, out io.Writer) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Plugin", &rpcServer{plugin: p}); err != nil {
+		return err
+	}
+
+	listener, cleanup, err := listen()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	defer listener.Close()
+
+	addr := listener.Addr()
+	if _, err := fmt.Fprintf(out, "%d|%d|%s|%s|%s\n", coreProtocolVersion, ProtocolVersion, addr.Network(), addr.String(), rpcProtocol); err != nil {
+		return fmt.Errorf("write handshake: %w", err)
+	}
+
+	go server.Accept(listener)
+	// The host closes stdin, or exits, when it is done with the plugin
+	_, _ = io.Copy(io.Discard, in)
+	return nil
+}
+
+// listen listens on a Unix socket in a private directory, or on a loopback
+// TCP port where Unix sockets are unavailable. cleanup removes the socket.
+func listen() (net.Listener, func(), error) {
+	if runtime.GOOS != "windows" {
+		dir, err := os.MkdirTemp("", "ai-devops-plugin-")
+		if err == nil {
+			listener, err := net.Listen("unix", filepath.Join(dir, "plugin.sock"))
+			if err == nil {
+				return listener, func() { os.RemoveAll(dir) }, nil
+			}
+			os.RemoveAll(dir)
+		}
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, fmt.Errorf("listen: %w", err)
+	}
+	return listener, func() {}, nil
+}
+
+// rpcServer exposes a Plugin over net/rpc.
+type rpcServer struct {
+	plugin *Plugin
+}
+
+// Info describes the plugin.
+func (s *rpcServer) Info(_ struct{}, reply *InfoReply) error {
+	*reply = InfoReply{
+		Name:            s.plugin.Name,
+		ProtocolVersion: ProtocolVersion,
+		Rules:           s.plugin.Rules,
+		HasClient:       s.plugin.Client != nil,
+	}
+	return nil
+}
+
+// Analyze calls the plugin's Client.Analyze.
+func (s *rpcServer) Analyze(args AnalyzeArgs, reply *AnalyzeReply) error {
+	if s.plugin.Client == nil {
+		reply.Error = newCallError(errNoClient)
+		return nil
+	}
+	ctx, cancel := withDeadline(args.Deadline)
+	defer cancel()
+	result, err := s.plugin.Client.Analyze(ctx, args.Log)
+	reply.Result, reply.Error = result, newCallError(err)
+	return nil
+}
+
+// Chat calls the plugin's Client.Chat.
+func (s *rpcServer) Chat(args ChatArgs, reply *ChatResult) error {
+	if s.plugin.Client == nil {
+		reply.Error = newCallError(errNoClient)
+		return nil
+	}
+	ctx, cancel := withDeadline(args.Deadline)
+	defer cancel()
+	answer, err := s.plugin.Client.Chat(ctx, args.Conversation, args.Message)
+	reply.Reply, reply.Error = answer, newCallError(err)
+	return nil
+}
+
+// HealthCheck calls the plugin's Client.HealthCheck.
+func (s *rpcServer) HealthCheck(args HealthArgs, reply *HealthReply) error {
+	if s.plugin.Client == nil {
+		reply.Error = newCallError(errNoClient)
+		return nil
+	}
+	ctx, cancel := withDeadline(args.Deadline)
+	defer cancel()
+	reply.Error = newCallError(s.plugin.Client.HealthCheck(ctx))
+	return nil
+}
+
+// withDeadline returns a context ending at the host's deadline, if any.
+func withDeadline(deadline time.Time) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), deadline)
+}
+
+// newCallError returns err for the host, or nil.
+func newCallError(err error) *CallError {
+	if err == nil {
+		return nil
+	}
+	return &CallError{Code: domain.CodeFor(err), Message: err.Error()}
+}
+
+// toError restores the error a plugin returned.
+func (e *CallError) toError(plugin string) error {
+	if e == nil {
+		return nil
+	}
+	if sentinel := domain.ErrorFor(e.Code); sentinel != nil {
+		return fmt.Errorf("%w: plugin %s: %s", sentinel, plugin, e.Message)
+	}
+	return fmt.Errorf("plugin %s: %s", plugin, e.Message)
+}
@@ -0,0 +1,87 @@
+// Package sanitizer provides log sanitization and secret masking.
+package sanitizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// Config customizes masking beyond the built-in patterns.
+type Config struct {
+	// Patterns are additional regular expressions to mask.
+	Patterns []string `json:"patterns"`
+
+	// Allowlist are regular expressions for values that must not be masked
+	// even though a pattern matches them, e.g. `@corp\.example\.com$` for
+	// internal e-mail addresses or `sk-test-` for known test keys. They are
+	// matched against the masked text, which for key=value patterns
+	// includes the key.
+	Allowlist []string `json:"allowlist"`
+
+	// DisableDefaultPatterns drops the built-in patterns so only Patterns
+	// are masked.
+	DisableDefaultPatterns bool `json:"disable_default_patterns"`
+
+	// MaskStrategies renders the values of a category with its own
+	// strategy, e.g. {"api_key": "full", "email": "hash"}.
+	MaskStrategies map[Category]string `json:"mask_strategies"`
+}
+
+// LoadConfig reads a JSON sanitizer configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read sanitizer config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse sanitizer config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// NewFromConfig creates a Sanitizer with the default patterns (unless
+// disabled), cfg's extra patterns and its allowlist.
+func NewFromConfig(maxSize int, cfg *Config) (*Sanitizer, error) {
+	var patterns []*regexp.Regexp
+	if !cfg.DisableDefaultPatterns {
+		patterns = DefaultPatterns()
+	}
+
+	extra, err := compileAll("pattern", cfg.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	allow, err := compileAll("allowlist pattern", cfg.Allowlist)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewWithPatterns(maxSize, append(patterns, extra...)).WithAllowlist(allow...)
+	for category, name := range cfg.MaskStrategies {
+		if !knownCategory(category) {
+			return nil, fmt.Errorf("invalid sanitizer mask strategy: unknown category %q", category)
+		}
+		strategy, err := ParseMaskStrategy(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sanitizer mask strategy for %s: %w", category, err)
+		}
+		s = s.WithCategoryMaskStrategy(category, strategy)
+	}
+	return s, nil
+}
+
+func compileAll(kind string, exprs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sanitizer %s %q: %w", kind, expr, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
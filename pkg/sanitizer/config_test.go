@@ -0,0 +1,62 @@
+// Package sanitizer provides unit tests for sanitizer configuration.
+package sanitizer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sanitizer.json")
+	config := `{
+		"patterns": ["ACME-[0-9]{6}"],
+		"allowlist": ["@corp\\.example\\.com$", "sk-test-"]
+	}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	s, err := NewFromConfig(10000, cfg)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		masked   string
+		retained string
+	}{
+		{"custom pattern", "license ACME-123456 rejected", "ACME-123456", ""},
+		{"allowlisted email", "owner: build-bot@corp.example.com", "", "build-bot@corp.example.com"},
+		{"other email still masked", "owner: someone@gmail.com", "someone@gmail.com", ""},
+		{"allowlisted test key", "api_key=sk-test-abcdefghijklmnopqrstuv", "", "sk-test-abcdefghijklmnopqrstuv"},
+		{"default patterns kept", "password=hunter2hunter2", "hunter2hunter2", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := s.Sanitize(tt.input)
+			if tt.masked != "" && strings.Contains(got, tt.masked) {
+				t.Errorf("Sanitize(%q) = %q, expected %q masked", tt.input, got, tt.masked)
+			}
+			if tt.retained != "" && !strings.Contains(got, tt.retained) {
+				t.Errorf("Sanitize(%q) = %q, expected %q retained", tt.input, got, tt.retained)
+			}
+		})
+	}
+
+	if _, stats := s.SanitizeWithStats("owner: build-bot@corp.example.com"); stats.SecretsFound != 0 {
+		t.Errorf("allowlisted values should not count as secrets, got %d", stats.SecretsFound)
+	}
+
+	if _, err := NewFromConfig(10000, &Config{Patterns: []string{"("}}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
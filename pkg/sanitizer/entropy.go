@@ -0,0 +1,126 @@
+// Package sanitizer provides log sanitization and secret masking.
+package sanitizer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// EntropyConfig tunes the detection of random-looking tokens that no
+// pattern knows, such as bespoke API keys.
+type EntropyConfig struct {
+	// MinLength is the shortest token checked.
+	MinLength int
+
+	// Threshold is the Shannon entropy, in bits per character, above which
+	// a base64-like token is masked. Random base64 approaches 6.
+	Threshold float64
+
+	// HexThreshold is the threshold for hexadecimal tokens, which carry at
+	// most 4 bits per character.
+	HexThreshold float64
+}
+
+// DefaultEntropyConfig returns thresholds that catch random tokens of 20
+// characters or more while sparing identifiers and words.
+func DefaultEntropyConfig() EntropyConfig {
+	return EntropyConfig{MinLength: 20, Threshold: 4.0, HexThreshold: 3.0}
+}
+
+// entropyDetector masks high-entropy tokens.
+type entropyDetector struct {
+	config EntropyConfig
+	tokens *regexp.Regexp
+}
+
+// hexToken matches tokens made of hexadecimal digits only.
+var hexToken = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// digestPrefixes precede content digests, which are random but not secret.
+var digestPrefixes = []string{"sha1:", "sha256:", "sha512:"}
+
+// WithEntropyDetection returns a copy of s that also masks tokens whose
+// Shannon entropy exceeds the thresholds of cfg, after the patterns ran.
+// Content digests ("sha256:...") are kept. Commit SHAs are hexadecimal and random,
+// so they are masked unless allowlisted.
+func (s *Sanitizer) WithEntropyDetection(cfg EntropyConfig) *Sanitizer {
+	copied := *s
+	copied.entropy = &entropyDetector{
+		config: cfg,
+		tokens: regexp.MustCompile(fmt.Sprintf(`[A-Za-z0-9+/_-]{%d,}`, cfg.MinLength)),
+	}
+	return &copied
+}
+
+// replace replaces the high-entropy tokens of log with replace.
+func (d *entropyDetector) replace(log string, replace func(category Category, match string) string) string {
+	var b strings.Builder
+	last := 0
+	changed := false
+	for _, loc := range d.tokens.FindAllStringIndex(log, -1) {
+		token := log[loc[0]:loc[1]]
+		if !d.random(token) || hasDigestPrefix(log[:loc[0]]) {
+			continue
+		}
+		replaced := replace(CategoryHighEntropy, token)
+		if replaced == token {
+			continue
+		}
+		b.WriteString(log[last:loc[0]])
+		b.WriteString(replaced)
+		last = loc[1]
+		changed = true
+	}
+	if !changed {
+		return log
+	}
+	b.WriteString(log[last:])
+	return b.String()
+}
+
+// random reports whether token looks randomly generated. Hexadecimal
+// tokens must mix letters and digits; others must mix upper and lower case
+// letters and digits, which spares lowercase identifiers such as pod names
+// and paths.
+func (d *entropyDetector) random(token string) bool {
+	if !strings.ContainsAny(token, "0123456789") {
+		return false
+	}
+	if hexToken.MatchString(token) {
+		return strings.IndexFunc(token, unicode.IsLetter) >= 0 && ShannonEntropy(token) > d.config.HexThreshold
+	}
+	return strings.IndexFunc(token, unicode.IsUpper) >= 0 && strings.IndexFunc(token, unicode.IsLower) >= 0 &&
+		ShannonEntropy(token) > d.config.Threshold
+}
+
+// ShannonEntropy returns the Shannon entropy of s in bits per byte.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		if n == 0 {
+			continue
+		}
+		p := float64(n) / float64(len(s))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func hasDigestPrefix(before string) bool {
+	for _, prefix := range digestPrefixes {
+		if strings.HasSuffix(before, prefix) {
+			return true
+		}
+	}
+	return false
+}
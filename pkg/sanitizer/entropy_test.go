@@ -0,0 +1,74 @@
+// Package sanitizer provides unit tests for entropy-based secret detection.
+package sanitizer
+
+import (
+	"math"
+	"regexp"
+	"testing"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"", 0},
+		{"aaaa", 0},
+		{"abab", 1},
+		{"0123456789abcdef", 4},
+	}
+
+	for _, tt := range tests {
+		if got := ShannonEntropy(tt.input); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ShannonEntropy(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizer_WithEntropyDetection(t *testing.T) {
+	s := New(10000).WithEntropyDetection(DefaultEntropyConfig())
+
+	tests := []struct {
+		name   string
+		input  string
+		masked bool
+	}{
+		{"random base64", "X-Acme-Auth Xk9pQ2mZ7vR4tL8wN3bY6cF1hJ5sD0gA rejected", true},
+		{"random hex", "using key e83c5163316f89bfbde7d9ab23ca2e25604af290", true},
+		{"pod name", "pod my-service-deployment-5d8f7c9b4-x2kqz OOMKilled", false},
+		{"path", "/usr/lib/x86_64-linux-gnu/libssl.so.1.1 not found", false},
+		{"image digest", "pull nginx@sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1", false},
+		{"short token", "id Xk9pQ2mZ7vR4", false},
+		{"version", "ubuntu-22.04-x86_64-20240101", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, stats := s.SanitizeWithStats(tt.input)
+			if masked := got != tt.input; masked != tt.masked {
+				t.Errorf("SanitizeWithStats(%q) = %q, masked = %v, want %v", tt.input, got, masked, tt.masked)
+			}
+			if stats.Has(CategoryHighEntropy) != tt.masked {
+				t.Errorf("Categories = %v", stats.Categories)
+			}
+		})
+	}
+
+	if got, _ := New(10000).Sanitize(tests[0].input); got != tests[0].input {
+		t.Errorf("Sanitize() without entropy detection = %q", got)
+	}
+}
+
+func TestSanitizer_WithEntropyDetection_AllowlistAndPlaceholders(t *testing.T) {
+	commit := regexp.MustCompile(`^[0-9a-f]{40}$`)
+	s := New(10000).WithEntropyDetection(DefaultEntropyConfig()).WithAllowlist(commit)
+
+	if got, _ := s.Sanitize("checkout e83c5163316f89bfbde7d9ab23ca2e25604af290"); got != "checkout e83c5163316f89bfbde7d9ab23ca2e25604af290" {
+		t.Errorf("allowlisted commit SHA masked: %q", got)
+	}
+
+	got, placeholders, _ := s.SanitizeReversible("token Xk9pQ2mZ7vR4tL8wN3bY6cF1hJ5sD0gA expired")
+	if got != "token SECRET_1 expired" || placeholders["SECRET_1"] != "Xk9pQ2mZ7vR4tL8wN3bY6cF1hJ5sD0gA" {
+		t.Errorf("SanitizeReversible() = %q, %v", got, placeholders)
+	}
+}
@@ -0,0 +1,104 @@
+// Package sanitizer provides log sanitization and secret masking.
+package sanitizer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Placeholders maps each placeholder inserted by SanitizeReversible (e.g.
+// SECRET_1) to the value it replaced. It must stay local: it holds the
+// secrets the placeholders hide.
+type Placeholders map[string]string
+
+// placeholderPattern matches placeholders produced by SanitizeReversible.
+var placeholderPattern = regexp.MustCompile(`\b(?:SECRET|EMAIL|HOST)_\d+\b`)
+
+// placeholderKinds classify a masked value; anything else is a SECRET.
+var placeholderKinds = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"EMAIL", regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)},
+	{"HOST", regexp.MustCompile(`^(?:\d{1,3}\.){3}\d{1,3}:\d{4,5}$`)},
+}
+
+// Restore replaces the placeholders in text with their original values.
+// Unknown placeholders are left as they are.
+func (p Placeholders) Restore(text string) string {
+	if len(p) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if value, ok := p[placeholder]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// SanitizeReversible is like Sanitize but replaces each masked value with a
+// stable placeholder (SECRET_1, EMAIL_2, HOST_3, ...), reusing the same
+// placeholder for repeated values, and returns the mapping so results that
+// mention them can be restored locally. For key=value matches only the value
+// is replaced.
+func (s *Sanitizer) SanitizeReversible(log string) (string, Placeholders, SanitizationStats) {
+	stats := SanitizationStats{
+		OriginalSize: len(log),
+		Truncated:    len(log) > s.maxSize,
+		Categories:   make(map[Category]int),
+	}
+
+	log = truncate(strings.TrimSpace(s.preprocess(log)), s.maxSize)
+
+	placeholders := make(Placeholders)
+	byValue := make(map[string]string)
+	log = s.replaceEach(log, func(category Category, match string) string {
+		if s.allowed(match) || placeholderPattern.MatchString(match) {
+			return match
+		}
+		stats.SecretsFound++
+		stats.Categories[category]++
+
+		prefix, value, suffix := splitMatch(match)
+		placeholder, ok := byValue[value]
+		if !ok {
+			placeholder = fmt.Sprintf("%s_%d", placeholderKind(value), len(placeholders)+1)
+			byValue[value] = placeholder
+			placeholders[placeholder] = value
+		}
+		return prefix + placeholder + suffix
+	})
+
+	stats.SanitizedSize = len(log)
+	return log, placeholders, stats
+}
+
+// splitMatch separates a key=value or key: value match into the key part to
+// keep, the value to replace and any trailing whitespace. E-mail addresses
+// and host:port pairs are replaced whole.
+func splitMatch(match string) (prefix, value, suffix string) {
+	value = match
+	if placeholderKind(match) != "SECRET" {
+		return "", match, ""
+	}
+	if idx := strings.IndexAny(match, ":="); idx != -1 && idx < len(match)-1 {
+		rest := match[idx+1:]
+		trimmed := strings.TrimLeft(rest, " \t")
+		prefix = match[:idx+1] + rest[:len(rest)-len(trimmed)]
+		value = trimmed
+	}
+	trimmed := strings.TrimRight(value, " \t\r\n")
+	return prefix, trimmed, value[len(trimmed):]
+}
+
+// placeholderKind names the kind of value a placeholder stands for.
+func placeholderKind(value string) string {
+	for _, k := range placeholderKinds {
+		if k.pattern.MatchString(value) {
+			return k.kind
+		}
+	}
+	return "SECRET"
+}
@@ -0,0 +1,68 @@
+// Package sanitizer provides unit tests for reversible sanitization.
+package sanitizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizer_SanitizeReversible(t *testing.T) {
+	s := New(10000)
+
+	log := "connect 10.0.4.17:5432 failed: password=hunter2secret\n" +
+		"notified ops@example.com\n" +
+		"retry 10.0.4.17:5432 with password=hunter2secret"
+	sanitized, placeholders, stats := s.SanitizeReversible(log)
+
+	for _, secret := range []string{"hunter2secret", "10.0.4.17", "ops@example.com"} {
+		if strings.Contains(sanitized, secret) {
+			t.Errorf("sanitized log leaks %q:\n%s", secret, sanitized)
+		}
+	}
+	if stats.SecretsFound != 5 {
+		t.Errorf("SecretsFound = %d, want 5", stats.SecretsFound)
+	}
+	if len(placeholders) != 3 {
+		t.Fatalf("placeholders = %v, want 3 distinct values", placeholders)
+	}
+
+	// Repeated values reuse the same placeholder and keys stay readable.
+	for placeholder, value := range placeholders {
+		if strings.Count(sanitized, placeholder) != strings.Count(log, value) {
+			t.Errorf("%s (%q) used %d times, want %d", placeholder, value,
+				strings.Count(sanitized, placeholder), strings.Count(log, value))
+		}
+	}
+	if !strings.Contains(sanitized, "password=SECRET_") {
+		t.Errorf("key should be kept before the placeholder:\n%s", sanitized)
+	}
+	if !strings.Contains(sanitized, "connect HOST_") || !strings.Contains(sanitized, "notified EMAIL_") {
+		t.Errorf("hosts and e-mails should get their own kinds:\n%s", sanitized)
+	}
+
+	if restored := placeholders.Restore(sanitized); restored != log {
+		t.Errorf("Restore() =\n%s\nwant\n%s", restored, log)
+	}
+}
+
+func TestPlaceholders_Restore(t *testing.T) {
+	p := Placeholders{"HOST_1": "db1:5432", "SECRET_10": "s3cr3t"}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"known placeholders", "ssh HOST_1 using SECRET_10", "ssh db1:5432 using s3cr3t"},
+		{"unknown placeholder kept", "rotate SECRET_1", "rotate SECRET_1"},
+		{"no placeholders", "restart the service", "restart the service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Restore(tt.text); got != tt.want {
+				t.Errorf("Restore() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Package sanitizer provides log sanitization and secret masking.
+package sanitizer
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// requiredLiterals returns lowercase literals of which at least one occurs
+// in every match of re, or nil when no such set could be derived. Patterns
+// whose literals are all absent from a log cannot match and are skipped, so
+// most of the built-in patterns never scan a typical log.
+func requiredLiterals(re *regexp.Regexp) []string {
+	tree, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return literalsOf(tree)
+}
+
+// literalsOf derives the required literals of a parsed expression.
+func literalsOf(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		literal := string(re.Rune)
+		if !isASCII(literal) {
+			// Case folding outside ASCII does not agree with strings.ToLower.
+			return nil
+		}
+		return []string{strings.ToLower(literal)}
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return literalsOf(re.Sub[0])
+
+	case syntax.OpRepeat:
+		if re.Min < 1 {
+			return nil
+		}
+		return literalsOf(re.Sub[0])
+
+	case syntax.OpConcat:
+		// Any operand's literals are required; keep the most selective.
+		var best []string
+		for _, sub := range re.Sub {
+			if literals := literalsOf(sub); literals != nil && shortest(literals) > shortest(best) {
+				best = literals
+			}
+		}
+		return best
+
+	case syntax.OpAlternate:
+		var union []string
+		for _, sub := range re.Sub {
+			literals := literalsOf(sub)
+			if literals == nil {
+				return nil
+			}
+			union = append(union, literals...)
+		}
+		return union
+	}
+	return nil
+}
+
+// shortest returns the length of the shortest literal, 0 for none.
+func shortest(literals []string) int {
+	if len(literals) == 0 {
+		return 0
+	}
+	n := len(literals[0])
+	for _, literal := range literals[1:] {
+		n = min(n, len(literal))
+	}
+	return n
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// mayMatch reports whether a log, lowered by foldLog, contains one of
+// literals. Patterns without literals may always match.
+func mayMatch(lower string, literals []string) bool {
+	if literals == nil {
+		return true
+	}
+	for _, literal := range literals {
+		if strings.Contains(lower, literal) {
+			return true
+		}
+	}
+	return false
+}
+
+// foldLog lowercases log for mayMatch. The long s is folded too, since
+// (?i)s matches it but strings.ToLower keeps it.
+func foldLog(log string) string {
+	return strings.ReplaceAll(strings.ToLower(log), "ſ", "s")
+}
+
+// patternLiterals returns the required literals of each pattern.
+func patternLiterals(patterns []*regexp.Regexp) [][]string {
+	literals := make([][]string, len(patterns))
+	for i, pattern := range patterns {
+		literals[i] = requiredLiterals(pattern)
+	}
+	return literals
+}
+
+// replaceEach replaces the matches of each pattern in turn with replace,
+// skipping patterns that cannot match. replace returns the match itself to
+// keep it.
+func (s *Sanitizer) replaceEach(log string, replace func(category Category, match string) string) string {
+	lower := foldLog(log)
+	for i, pattern := range s.patterns {
+		if !mayMatch(lower, s.literals[i]) {
+			continue
+		}
+		category := categoryOf(pattern)
+		changed := false
+		log = pattern.ReplaceAllStringFunc(log, func(match string) string {
+			replaced := replace(category, match)
+			changed = changed || replaced != match
+			return replaced
+		})
+		if changed {
+			// Replacements can join text into new literals.
+			lower = foldLog(log)
+		}
+	}
+	if s.entropy != nil {
+		log = s.entropy.replace(log, replace)
+	}
+	return log
+}
@@ -0,0 +1,45 @@
+// Package sanitizer provides log sanitization and secret masking.
+package sanitizer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ansiPattern matches ANSI escape sequences: CSI (colors, cursor movement),
+// OSC (window titles, hyperlinks) and two-byte escapes.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// timestampPattern matches a timestamp at the start of a line: ISO 8601 /
+// RFC 3339 (as prefixed by GitHub Actions and most loggers), bracketed
+// clock times and syslog dates, with the whitespace that follows.
+var timestampPattern = regexp.MustCompile(`(?m)^(?:\[?\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?\]?|\[\d{2}:\d{2}:\d{2}(?:[.,]\d+)?\]|[A-Z][a-z]{2} +\d{1,2} \d{2}:\d{2}:\d{2})[ \t]+`)
+
+// Preprocess removes terminal noise that wastes tokens and defeats rule
+// patterns: ANSI escape sequences are stripped, carriage-return progress
+// updates are collapsed to their final state and leading per-line timestamps
+// are dropped, so the same failure looks the same on every run.
+func Preprocess(log string) string {
+	log = ansiPattern.ReplaceAllString(log, "")
+	log = strings.ReplaceAll(log, "\r\n", "\n")
+	if strings.Contains(log, "\r") {
+		lines := strings.Split(log, "\n")
+		for i, line := range lines {
+			lines[i] = lastSegment(line)
+		}
+		log = strings.Join(lines, "\n")
+	}
+	return timestampPattern.ReplaceAllString(log, "")
+}
+
+// lastSegment returns what a terminal would show for a line redrawn with
+// carriage returns: the last non-empty segment.
+func lastSegment(line string) string {
+	segments := strings.Split(line, "\r")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if strings.TrimSpace(segments[i]) != "" {
+			return segments[i]
+		}
+	}
+	return ""
+}
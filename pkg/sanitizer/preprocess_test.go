@@ -0,0 +1,62 @@
+// Package sanitizer provides unit tests for log preprocessing.
+package sanitizer
+
+import "testing"
+
+func TestPreprocess(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want string
+	}{
+		{
+			name: "ANSI colors",
+			log:  "\x1b[32m✓ lint\x1b[0m\n\x1b[1;31mERROR\x1b[0m: build failed",
+			want: "✓ lint\nERROR: build failed",
+		},
+		{
+			name: "OSC hyperlink",
+			log:  "see \x1b]8;;https://example.com\x07docs\x1b]8;;\x07",
+			want: "see docs",
+		},
+		{
+			name: "progress spinner",
+			log:  "Downloading 10%\rDownloading 55%\rDownloading 100%\r\nnpm ERR! code E404",
+			want: "Downloading 100%\nnpm ERR! code E404",
+		},
+		{
+			name: "GitHub Actions timestamps",
+			log:  "2024-01-15T10:23:45.1234567Z Run make\n2024-01-15T10:23:46.0000000Z make: *** [all] Error 2",
+			want: "Run make\nmake: *** [all] Error 2",
+		},
+		{
+			name: "bracketed clock and syslog",
+			log:  "[10:23:45] Starting 'build'...\nJan  5 10:23:45 host dockerd: failed",
+			want: "Starting 'build'...\nhost dockerd: failed",
+		},
+		{
+			name: "timestamps inside a line are kept",
+			log:  "token expired at 2024-01-15T10:23:45Z",
+			want: "token expired at 2024-01-15T10:23:45Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Preprocess(tt.log); got != tt.want {
+				t.Errorf("Preprocess() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizer_WithoutPreprocessing(t *testing.T) {
+	log := "\x1b[31mError\x1b[0m"
+
+	if got, _ := New(100).Sanitize(log); got != "Error" {
+		t.Errorf("Sanitize() = %q, want ANSI codes stripped", got)
+	}
+	if got, _ := New(100).WithoutPreprocessing().Sanitize(log); got != log {
+		t.Errorf("Sanitize() without preprocessing = %q, want %q", got, log)
+	}
+}
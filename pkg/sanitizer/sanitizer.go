@@ -8,106 +8,239 @@ import (
 
 // Sanitizer handles log preprocessing and secret masking.
 type Sanitizer struct {
-	patterns []*regexp.Regexp
-	maxSize  int
+	patterns  []*regexp.Regexp
+	allowlist []*regexp.Regexp
+	maxSize   int
+
+	// literals holds the required literals of each pattern (see
+	// requiredLiterals).
+	literals [][]string
+
+	// rawInput disables Preprocess, for callers that need the log verbatim.
+	rawInput bool
+
+	// entropy, if set, masks high-entropy tokens no pattern matched.
+	entropy *entropyDetector
+
+	// strategy and categoryStrategies select how masked values are
+	// rendered (see MaskStrategy); hashKey keys MaskHash.
+	strategy           MaskStrategy
+	categoryStrategies map[Category]MaskStrategy
+	hashKey            []byte
+}
+
+// Category classifies a kind of sensitive data found in a log.
+type Category string
+
+// Categories of sensitive data.
+const (
+	CategoryAPIKey     Category = "api_key"
+	CategoryPassword   Category = "password"
+	CategoryEmail      Category = "email"
+	CategoryIP         Category = "ip"
+	CategoryJWT        Category = "jwt"
+	CategoryPrivateKey Category = "private_key"
+
+	// CategoryHighEntropy counts random-looking tokens found by entropy
+	// detection (see WithEntropyDetection).
+	CategoryHighEntropy Category = "high_entropy"
+
+	// CategoryCustom counts matches of patterns that are not built in.
+	CategoryCustom Category = "custom"
+)
+
+// categorizedPattern is a built-in pattern with the kind of data it finds.
+type categorizedPattern struct {
+	category Category
+	pattern  *regexp.Regexp
 }
 
 // Pattern definitions for common secrets and sensitive data.
-var defaultPatterns = []*regexp.Regexp{
+var builtinPatterns = []categorizedPattern{
 	// API Keys (generic patterns)
-	regexp.MustCompile(`(?i)(api[_-]?key|apikey)\s*[:=]\s*['"]?([a-zA-Z0-9_\-]{20,})['"]?`),
-	regexp.MustCompile(`(?i)(secret[_-]?key|secretkey)\s*[:=]\s*['"]?([a-zA-Z0-9_\-]{20,})['"]?`),
-	regexp.MustCompile(`(?i)(access[_-]?key|accesskey)\s*[:=]\s*['"]?([a-zA-Z0-9_\-]{16,})['"]?`),
+	{CategoryAPIKey, regexp.MustCompile(`(?i)(api[_-]?key|apikey)\s*[:=]\s*['"]?([a-zA-Z0-9_\-]{20,})['"]?`)},
+	{CategoryAPIKey, regexp.MustCompile(`(?i)(secret[_-]?key|secretkey)\s*[:=]\s*['"]?([a-zA-Z0-9_\-]{20,})['"]?`)},
+	{CategoryAPIKey, regexp.MustCompile(`(?i)(access[_-]?key|accesskey)\s*[:=]\s*['"]?([a-zA-Z0-9_\-]{16,})['"]?`)},
 
 	// Authentication tokens
-	regexp.MustCompile(`(?i)(bearer\s+)[a-zA-Z0-9_\-\.]+`),
-	regexp.MustCompile(`(?i)(authorization:\s*)[a-zA-Z0-9_\-\.\s]+`),
-	regexp.MustCompile(`(?i)(token|auth[_-]?token)\s*[:=]\s*['"]?([a-zA-Z0-9_\-\.]{20,})['"]?`),
+	{CategoryAPIKey, regexp.MustCompile(`(?i)(bearer\s+)[a-zA-Z0-9_\-\.]+`)},
+	{CategoryAPIKey, regexp.MustCompile(`(?i)(authorization:\s*)[a-zA-Z0-9_\-\.\s]+`)},
+	{CategoryAPIKey, regexp.MustCompile(`(?i)(token|auth[_-]?token)\s*[:=]\s*['"]?([a-zA-Z0-9_\-\.]{20,})['"]?`)},
 
 	// Passwords
-	regexp.MustCompile(`(?i)(password|passwd|pwd)\s*[:=]\s*['"]?([^\s'"]{4,})['"]?`),
+	{CategoryPassword, regexp.MustCompile(`(?i)(password|passwd|pwd)\s*[:=]\s*['"]?([^\s'"]{4,})['"]?`)},
 
 	// AWS credentials
-	regexp.MustCompile(`(?i)AKIA[0-9A-Z]{16}`),
-	regexp.MustCompile(`(?i)(aws[_-]?secret[_-]?access[_-]?key)\s*[:=]\s*['"]?([a-zA-Z0-9/+=]{40})['"]?`),
+	{CategoryAPIKey, regexp.MustCompile(`(?i)AKIA[0-9A-Z]{16}`)},
+	{CategoryAPIKey, regexp.MustCompile(`(?i)(aws[_-]?secret[_-]?access[_-]?key)\s*[:=]\s*['"]?([a-zA-Z0-9/+=]{40})['"]?`)},
 
 	// Private keys
-	regexp.MustCompile(`-----BEGIN\s+(RSA|DSA|EC|OPENSSH)?\s*PRIVATE KEY-----`),
-	regexp.MustCompile(`-----BEGIN\s+PGP\s+PRIVATE\s+KEY\s+BLOCK-----`),
+	{CategoryPrivateKey, regexp.MustCompile(`-----BEGIN\s+(RSA|DSA|EC|OPENSSH)?\s*PRIVATE KEY-----`)},
+	{CategoryPrivateKey, regexp.MustCompile(`-----BEGIN\s+PGP\s+PRIVATE\s+KEY\s+BLOCK-----`)},
 
-	// Database connection strings
-	regexp.MustCompile(`(?i)(mongodb|mysql|postgres|postgresql|redis):\/\/[^@]+@[^\s]+`),
-	regexp.MustCompile(`(?i)(connection[_-]?string)\s*[:=]\s*['"]?([^\s'"]+)['"]?`),
+	// Database connection strings (credentials in the URL)
+	{CategoryPassword, regexp.MustCompile(`(?i)(mongodb|mysql|postgres|postgresql|redis):\/\/[^@]+@[^\s]+`)},
+	{CategoryPassword, regexp.MustCompile(`(?i)(connection[_-]?string)\s*[:=]\s*['"]?([^\s'"]+)['"]?`)},
 
 	// GitHub tokens
-	regexp.MustCompile(`ghp_[a-zA-Z0-9]{36}`),
-	regexp.MustCompile(`gho_[a-zA-Z0-9]{36}`),
-	regexp.MustCompile(`ghu_[a-zA-Z0-9]{36}`),
-	regexp.MustCompile(`ghs_[a-zA-Z0-9]{36}`),
-	regexp.MustCompile(`ghr_[a-zA-Z0-9]{36}`),
+	{CategoryAPIKey, regexp.MustCompile(`ghp_[a-zA-Z0-9]{36}`)},
+	{CategoryAPIKey, regexp.MustCompile(`gho_[a-zA-Z0-9]{36}`)},
+	{CategoryAPIKey, regexp.MustCompile(`ghu_[a-zA-Z0-9]{36}`)},
+	{CategoryAPIKey, regexp.MustCompile(`ghs_[a-zA-Z0-9]{36}`)},
+	{CategoryAPIKey, regexp.MustCompile(`ghr_[a-zA-Z0-9]{36}`)},
 
 	// JWT tokens
-	regexp.MustCompile(`eyJ[a-zA-Z0-9_-]*\.eyJ[a-zA-Z0-9_-]*\.[a-zA-Z0-9_-]*`),
+	{CategoryJWT, regexp.MustCompile(`eyJ[a-zA-Z0-9_-]*\.eyJ[a-zA-Z0-9_-]*\.[a-zA-Z0-9_-]*`)},
 
 	// Slack tokens
-	regexp.MustCompile(`xox[baprs]-[0-9a-zA-Z-]+`),
+	{CategoryAPIKey, regexp.MustCompile(`xox[baprs]-[0-9a-zA-Z-]+`)},
 
 	// Generic high-entropy strings that look like secrets
-	regexp.MustCompile(`(?i)(secret|private|credential)\s*[:=]\s*['"]?([a-zA-Z0-9_\-]{16,})['"]?`),
+	{CategoryAPIKey, regexp.MustCompile(`(?i)(secret|private|credential)\s*[:=]\s*['"]?([a-zA-Z0-9_\-]{16,})['"]?`)},
 
 	// IP addresses with ports (might be internal infrastructure)
-	regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}:\d{4,5}\b`),
+	{CategoryIP, regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}:\d{4,5}\b`)},
 
 	// Email addresses (PII)
-	regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`),
+	{CategoryEmail, regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)},
+}
+
+// defaultPatterns are the built-in patterns in masking order.
+var defaultPatterns = func() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(builtinPatterns))
+	for i, p := range builtinPatterns {
+		patterns[i] = p.pattern
+	}
+	return patterns
+}()
+
+// defaultLiterals are the required literals of defaultPatterns.
+var defaultLiterals = patternLiterals(defaultPatterns)
+
+// builtinCategories maps each built-in pattern to its category.
+var builtinCategories = func() map[*regexp.Regexp]Category {
+	categories := make(map[*regexp.Regexp]Category, len(builtinPatterns))
+	for _, p := range builtinPatterns {
+		categories[p.pattern] = p.category
+	}
+	return categories
+}()
+
+// knownCategory reports whether category is one of the Category constants.
+func knownCategory(category Category) bool {
+	switch category {
+	case CategoryAPIKey, CategoryPassword, CategoryEmail, CategoryIP, CategoryJWT,
+		CategoryPrivateKey, CategoryHighEntropy, CategoryCustom:
+		return true
+	}
+	return false
+}
+
+// categoryOf returns the category of a pattern: its built-in category, or
+// CategoryCustom for patterns added by callers.
+func categoryOf(pattern *regexp.Regexp) Category {
+	if category, ok := builtinCategories[pattern]; ok {
+		return category
+	}
+	return CategoryCustom
 }
 
 // New creates a new Sanitizer with default patterns.
 func New(maxSize int) *Sanitizer {
 	return &Sanitizer{
 		patterns: defaultPatterns,
+		literals: defaultLiterals,
 		maxSize:  maxSize,
 	}
 }
 
+// DefaultPatterns returns a copy of the built-in secret and PII patterns, for
+// extending with NewWithPatterns.
+func DefaultPatterns() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(defaultPatterns))
+	copy(patterns, defaultPatterns)
+	return patterns
+}
+
 // NewWithPatterns creates a Sanitizer with custom patterns.
 func NewWithPatterns(maxSize int, patterns []*regexp.Regexp) *Sanitizer {
 	return &Sanitizer{
 		patterns: patterns,
+		literals: patternLiterals(patterns),
 		maxSize:  maxSize,
 	}
 }
 
+// WithAllowlist returns a copy of s that leaves a match unmasked when any of
+// allow matches within it, e.g. internal e-mail domains or known test keys.
+func (s *Sanitizer) WithAllowlist(allow ...*regexp.Regexp) *Sanitizer {
+	copied := *s
+	copied.allowlist = append(append([]*regexp.Regexp(nil), s.allowlist...), allow...)
+	return &copied
+}
+
+// WithoutPreprocessing returns a copy of s that masks and truncates logs
+// without first stripping ANSI codes, progress redraws and timestamps.
+func (s *Sanitizer) WithoutPreprocessing() *Sanitizer {
+	copied := *s
+	copied.rawInput = true
+	return &copied
+}
+
+// preprocess applies Preprocess unless it is disabled.
+func (s *Sanitizer) preprocess(log string) string {
+	if s.rawInput {
+		return log
+	}
+	return Preprocess(log)
+}
+
+// allowed reports whether a matched value is on the allowlist.
+func (s *Sanitizer) allowed(match string) bool {
+	for _, allow := range s.allowlist {
+		if allow.MatchString(match) {
+			return true
+		}
+	}
+	return false
+}
+
 // Sanitize processes the log, masking secrets and enforcing size limits.
+// Oversized logs keep their start, tail and error regions (see truncate).
 func (s *Sanitizer) Sanitize(log string) (string, error) {
-	// Trim whitespace
-	log = strings.TrimSpace(log)
+	// Strip terminal noise and trim whitespace
+	log = strings.TrimSpace(s.preprocess(log))
 
-	// Enforce size limit
-	if len(log) > s.maxSize {
-		log = log[:s.maxSize]
-	}
+	// Enforce size limit, keeping the error region
+	log = truncate(log, s.maxSize)
 
 	// Mask secrets
-	sanitized := s.maskSecrets(log)
+	sanitized := s.maskSecrets(log, nil)
 
 	return sanitized, nil
 }
 
-// maskSecrets replaces sensitive patterns with masked versions.
-func (s *Sanitizer) maskSecrets(log string) string {
-	result := log
-
-	for _, pattern := range s.patterns {
-		result = pattern.ReplaceAllStringFunc(result, func(match string) string {
-			return maskValue(match)
-		})
-	}
+// Mask masks secrets in text without preprocessing or truncating it, for
+// text that is not a raw log, such as prompts and AI responses.
+func (s *Sanitizer) Mask(text string) string {
+	return s.maskSecrets(text, nil)
+}
 
-	return result
+// maskSecrets replaces sensitive patterns with masked versions and counts
+// the masked values per category in found, if non-nil.
+func (s *Sanitizer) maskSecrets(log string, found map[Category]int) string {
+	return s.replaceEach(log, func(category Category, match string) string {
+		if s.allowed(match) {
+			return match
+		}
+		if found != nil {
+			found[category]++
+		}
+		return s.mask(category, match)
+	})
 }
 
-// maskValue creates a masked version of a matched secret.
+// maskValue creates a masked version of a matched secret (MaskPartial).
 func maskValue(match string) string {
 	// Preserve some context while masking the actual value
 	if len(match) <= 8 {
@@ -139,12 +272,20 @@ func (s *Sanitizer) IsTooLarge(log string) bool {
 	return len(log) > s.maxSize
 }
 
-// GetStats returns statistics about the sanitization.
+// SanitizationStats describes what sanitization changed.
 type SanitizationStats struct {
 	OriginalSize  int
 	SanitizedSize int
 	Truncated     bool
 	SecretsFound  int
+
+	// Categories counts the masked values per kind of sensitive data.
+	Categories map[Category]int
+}
+
+// Has reports whether sensitive data of category was found.
+func (st SanitizationStats) Has(category Category) bool {
+	return st.Categories[category] > 0
 }
 
 // SanitizeWithStats performs sanitization and returns statistics.
@@ -152,15 +293,14 @@ func (s *Sanitizer) SanitizeWithStats(log string) (string, SanitizationStats) {
 	stats := SanitizationStats{
 		OriginalSize: len(log),
 		Truncated:    len(log) > s.maxSize,
+		Categories:   make(map[Category]int),
 	}
 
-	// Count secrets before masking
-	for _, pattern := range s.patterns {
-		matches := pattern.FindAllString(log, -1)
-		stats.SecretsFound += len(matches)
+	log = truncate(strings.TrimSpace(s.preprocess(log)), s.maxSize)
+	sanitized := s.maskSecrets(log, stats.Categories)
+	for _, n := range stats.Categories {
+		stats.SecretsFound += n
 	}
-
-	sanitized, _ := s.Sanitize(log)
 	stats.SanitizedSize = len(sanitized)
 
 	return sanitized, stats
@@ -0,0 +1,128 @@
+// Package sanitizer provides log sanitization and secret masking.
+package sanitizer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// streamWindow is how many times the size limit SanitizeReader buffers
+// before truncating what it has read so far.
+const streamWindow = 2
+
+// streamLine is a masked line buffered by SanitizeReader.
+type streamLine struct {
+	text string
+
+	// omittedBefore counts the lines dropped before this one.
+	omittedBefore int
+}
+
+// SanitizeReader is SanitizeWithStats for logs read from r. The log is
+// preprocessed, masked and truncated line by line, so memory stays within a
+// few times the size limit however large the log is; lines longer than the
+// limit keep their end. Unlike SanitizeWithStats, patterns cannot match
+// across lines.
+func (s *Sanitizer) SanitizeReader(r io.Reader) (string, SanitizationStats, error) {
+	stats := SanitizationStats{Categories: make(map[Category]int)}
+	reader := bufio.NewReader(r)
+
+	var lines []streamLine
+	size, omitted := 0, 0
+	for {
+		line, n, err := readLine(reader, s.maxSize)
+		stats.OriginalSize += n
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", stats, err
+		}
+
+		if line != "" {
+			text := s.maskSecrets(s.preprocess(line), stats.Categories)
+			// Leading blank lines are trimmed, as by Sanitize.
+			if len(lines) > 0 || strings.TrimSpace(text) != "" {
+				lines = append(lines, streamLine{text: text, omittedBefore: omitted})
+				size += len(text)
+				omitted = 0
+			}
+		}
+		if size > streamWindow*s.maxSize {
+			lines, size = compactLines(lines, s.maxSize)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	for _, n := range stats.Categories {
+		stats.SecretsFound += n
+	}
+	stats.Truncated = stats.OriginalSize > s.maxSize
+
+	if size > s.maxSize {
+		lines, _ = compactLines(lines, s.maxSize)
+	}
+	texts := make([]string, len(lines))
+	keep := make([]bool, len(lines))
+	omittedBefore := make([]int, len(lines))
+	for i, line := range lines {
+		texts[i], keep[i], omittedBefore[i] = line.text, true, line.omittedBefore
+	}
+	sanitized := strings.TrimRightFunc(joinKept(texts, keep, omittedBefore, s.maxSize), unicode.IsSpace)
+	stats.SanitizedSize = len(sanitized)
+	return sanitized, stats, nil
+}
+
+// compactLines drops the lines truncate would drop from lines, recording
+// them in the omitted counts of the lines kept, and returns the size kept.
+func compactLines(lines []streamLine, maxSize int) ([]streamLine, int) {
+	if len(lines) == 0 {
+		return lines, 0
+	}
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	keep, size := selectLines(texts, maxSize)
+	if last := len(keep) - 1; !keep[last] {
+		// The lines read next follow the last one.
+		keep[last] = true
+		size += len(texts[last])
+	}
+
+	compacted := lines[:0]
+	omitted := 0
+	for i, line := range lines {
+		omitted += line.omittedBefore
+		if !keep[i] {
+			omitted++
+			continue
+		}
+		compacted = append(compacted, streamLine{text: line.text, omittedBefore: omitted})
+		omitted = 0
+	}
+	return compacted, size
+}
+
+// readLine reads the next line from r, including its newline, and returns
+// at most its last maxSize bytes together with the number of bytes read.
+func readLine(r *bufio.Reader, maxSize int) (string, int, error) {
+	var b strings.Builder
+	n := 0
+	for {
+		chunk, err := r.ReadSlice('\n')
+		n += len(chunk)
+		if b.Len()+len(chunk) > maxSize {
+			tail := b.String() + string(chunk)
+			b.Reset()
+			b.WriteString(tail[max(0, len(tail)-maxSize):])
+		} else {
+			b.Write(chunk)
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return b.String(), n, err
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package sanitizer provides unit tests for streaming sanitization.
+package sanitizer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSanitizer_SanitizeReader_SmallLog(t *testing.T) {
+	s := New(10000)
+	log := "\n\n\x1b[31mError:\x1b[0m login failed password=hunter2hunter2\r\n2024-01-01T12:00:00Z notify ops@acme.io\n  \n"
+
+	got, stats, err := s.SanitizeReader(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("SanitizeReader() error = %v", err)
+	}
+	want, wantStats := s.SanitizeWithStats(log)
+	if got != want {
+		t.Errorf("SanitizeReader() = %q, want %q", got, want)
+	}
+	if stats.SecretsFound != wantStats.SecretsFound || stats.OriginalSize != len(log) || stats.Truncated {
+		t.Errorf("stats = %+v, want %+v", stats, wantStats)
+	}
+}
+
+var omittedMarker = regexp.MustCompile(`\.\.\. \[(\d+) lines omitted\] \.\.\.`)
+
+func TestSanitizer_SanitizeReader_LargeLog(t *testing.T) {
+	const maxSize = 5000
+	const total = 50000
+	var b strings.Builder
+	for i := 0; i < total; i++ {
+		switch i {
+		case 100:
+			b.WriteString("export password=hunter2hunter2\n")
+		case total - 200:
+			b.WriteString("Error: connection refused\n")
+		default:
+			fmt.Fprintf(&b, "step %d: downloading layer\n", i)
+		}
+	}
+	log := b.String()
+
+	got, stats, err := New(maxSize).SanitizeReader(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("SanitizeReader() error = %v", err)
+	}
+	if len(got) > maxSize {
+		t.Errorf("len = %d, want <= %d", len(got), maxSize)
+	}
+	for _, want := range []string{"step 0: downloading layer", "Error: connection refused", fmt.Sprintf("step %d: downloading layer", total-1)} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q", want)
+		}
+	}
+	if !stats.Truncated || stats.OriginalSize != len(log) || !stats.Has(CategoryPassword) {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// Every line is either kept or counted by a marker.
+	lines := 0
+	for _, line := range strings.Split(got, "\n") {
+		if m := omittedMarker.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			lines += n
+		} else {
+			lines++
+		}
+	}
+	if lines != total {
+		t.Errorf("kept and omitted lines = %d, want %d", lines, total)
+	}
+}
+
+func TestSanitizer_SanitizeReader_LongLine(t *testing.T) {
+	log := strings.Repeat("x", 20000) + "FATAL: out of memory"
+
+	got, _, err := New(1000).SanitizeReader(iotest.OneByteReader(strings.NewReader(log)))
+	if err != nil {
+		t.Fatalf("SanitizeReader() error = %v", err)
+	}
+	if len(got) != 1000 || !strings.HasSuffix(got, "FATAL: out of memory") {
+		t.Errorf("SanitizeReader() = %d bytes ending %q", len(got), got[max(0, len(got)-30):])
+	}
+}
+
+func TestSanitizer_SanitizeReader_ReadError(t *testing.T) {
+	failing := io.MultiReader(strings.NewReader("line\n"), iotest.ErrReader(errors.New("connection reset")))
+
+	if _, _, err := New(1000).SanitizeReader(failing); err == nil || err.Error() != "connection reset" {
+		t.Errorf("SanitizeReader() error = %v, want connection reset", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Package sanitizer provides log sanitization and secret masking.
+package sanitizer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Shares of the size budget reserved for the start and the end of an
+// oversized log; the rest goes to the regions around error lines.
+const (
+	truncateHeadShare = 0.1
+	truncateTailShare = 0.3
+)
+
+// Lines kept around each error line, before and after. Stack frames that
+// follow the window extend it.
+const (
+	errorContextBefore = 3
+	errorContextAfter  = 5
+)
+
+// maxStackFrames bounds how far a stack trace extends an error region.
+const maxStackFrames = 50
+
+// errorLinePattern matches lines likely to describe the failure.
+var errorLinePattern = regexp.MustCompile(`(?i)\b(error|fatal|panic|exception|traceback|caused by|fail(ed|ure)?)\b`)
+
+// stackFramePattern matches stack trace frames from common runtimes: Java
+// and JavaScript ("at ..."), Python ("File ..."), Go (goroutine headers and
+// indented file:line) and "... N more" continuations.
+var stackFramePattern = regexp.MustCompile(`^\s+(at\s|File\s"|\.\.\.\s\d+\smore)|^\s+\S+\.\w+:\d+|^goroutine\s\d+|^\S+\(.*\)$`)
+
+// IsStackFrame reports whether line looks like a stack trace frame from a
+// common runtime (Java, JavaScript, Python, Go).
+func IsStackFrame(line string) bool {
+	return stackFramePattern.MatchString(strings.TrimRight(line, "\r\n"))
+}
+
+// truncate shrinks log to at most maxSize bytes. Rather than keeping only the
+// head, it keeps the first lines, the tail and the regions around error
+// lines (latest first, including their stack traces), replacing the gaps
+// with "... [N lines omitted] ..." markers.
+func truncate(log string, maxSize int) string {
+	if len(log) <= maxSize {
+		return log
+	}
+
+	lines := strings.SplitAfter(log, "\n")
+	keep, size := selectLines(lines, maxSize)
+	if size == 0 {
+		// A single huge line: keep its end, where the failure usually is.
+		return log[len(log)-maxSize:]
+	}
+	return joinKept(lines, keep, nil, maxSize)
+}
+
+// selectLines chooses the lines truncate keeps and returns their total size.
+func selectLines(lines []string, maxSize int) ([]bool, int) {
+	keep := make([]bool, len(lines))
+	// Reserve room for the omission markers.
+	budget := maxSize - maxSize/20
+	size := 0
+	take := func(i, limit int) bool {
+		if keep[i] {
+			return true
+		}
+		if size+len(lines[i]) > limit {
+			return false
+		}
+		keep[i] = true
+		size += len(lines[i])
+		return true
+	}
+
+	headLimit := int(float64(budget) * truncateHeadShare)
+	for i := 0; i < len(lines); i++ {
+		if !take(i, headLimit) {
+			break
+		}
+	}
+	tailLimit := size + int(float64(budget)*truncateTailShare)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if !take(i, tailLimit) {
+			break
+		}
+	}
+
+	// The last failure is usually the one that matters, so error regions
+	// are added from the end of the log backwards until the budget is spent.
+	for i := len(lines) - 1; i >= 0 && size < budget; i-- {
+		if !errorLinePattern.MatchString(lines[i]) {
+			continue
+		}
+		start := max(0, i-errorContextBefore)
+		end := min(len(lines), i+errorContextAfter+1)
+		for frames := 0; end < len(lines) && frames < maxStackFrames && IsStackFrame(lines[end]); frames++ {
+			end++
+		}
+		for j := start; j < end; j++ {
+			take(j, budget)
+		}
+	}
+	return keep, size
+}
+
+// joinKept joins the kept lines, marking each gap with the number of lines
+// omitted. omittedBefore, if set, counts lines already omitted before each
+// line, e.g. by an earlier pass. The result is cut to maxSize bytes.
+func joinKept(lines []string, keep []bool, omittedBefore []int, maxSize int) string {
+	var b strings.Builder
+	omitted := 0
+	for i, line := range lines {
+		if omittedBefore != nil {
+			omitted += omittedBefore[i]
+		}
+		if !keep[i] {
+			omitted++
+			continue
+		}
+		if omitted > 0 {
+			fmt.Fprintf(&b, "... [%d lines omitted] ...\n", omitted)
+			omitted = 0
+		}
+		b.WriteString(line)
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "... [%d lines omitted] ...", omitted)
+	}
+
+	truncated := b.String()
+	if len(truncated) > maxSize {
+		// Too many markers: drop from the start, keeping the tail intact.
+		truncated = truncated[len(truncated)-maxSize:]
+	}
+	return truncated
+}
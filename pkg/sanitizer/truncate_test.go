@@ -0,0 +1,74 @@
+// Package sanitizer provides unit tests for error-aware truncation.
+package sanitizer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// gradleLog builds a large build log whose failure sits in the middle,
+// followed by a long summary.
+func gradleLog() string {
+	var b strings.Builder
+	b.WriteString("Starting a Gradle Daemon\n")
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, "> Task :module%d:compileJava UP-TO-DATE\n", i)
+	}
+	b.WriteString("> Task :app:test FAILED\n")
+	b.WriteString("java.lang.IllegalStateException: datasource not configured\n")
+	b.WriteString("    at com.example.app.Config.load(Config.java:42)\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&b, "    at com.example.app.Layer%d.call(Layer%d.java:%d)\n", i, i, i+10)
+	}
+	b.WriteString("    ... 12 more\n")
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, "> Task :module%d:javadoc SKIPPED\n", i)
+	}
+	b.WriteString("BUILD FAILED in 4m 12s\n")
+	b.WriteString("47 actionable tasks: 3 executed, 44 up-to-date")
+	return b.String()
+}
+
+func TestTruncate_KeepsErrorRegion(t *testing.T) {
+	log := gradleLog()
+	const maxSize = 8000
+	got := truncate(log, maxSize)
+
+	if len(got) > maxSize {
+		t.Fatalf("len = %d, want <= %d", len(got), maxSize)
+	}
+	for _, want := range []string{
+		"Starting a Gradle Daemon",
+		"> Task :app:test FAILED",
+		"java.lang.IllegalStateException: datasource not configured",
+		"at com.example.app.Layer19.call",
+		"... 12 more",
+		"47 actionable tasks",
+		"lines omitted] ...",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("truncated log missing %q", want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name    string
+		log     string
+		maxSize int
+		want    string
+	}{
+		{"fits", "short log", 100, "short log"},
+		{"single long line keeps the end", strings.Repeat("a", 40) + "FATAL", 10, "aaaaaFATAL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.log, tt.maxSize); got != tt.want {
+				t.Errorf("truncate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}